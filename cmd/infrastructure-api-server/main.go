@@ -7,9 +7,11 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"email_sender/development/managers/security-manager/auth"
 	"email_sender/internal/api"
 	"email_sender/internal/infrastructure"
 )
@@ -24,6 +26,9 @@ func main() {
 		port    = flag.Int("port", defaultPort, "Port pour l'API server")
 		help    = flag.Bool("help", false, "Afficher l'aide")
 		version = flag.Bool("version", false, "Afficher la version")
+		noAuth  = flag.Bool("no-auth", false, "Désactiver l'authentification (développement local uniquement)")
+		newKey  = flag.String("create-api-key", "", "Créer une clé API avec ce nom dans $AUTH_API_KEYS_FILE et quitter")
+		keyRole = flag.String("roles", "viewer", "Rôles de la clé API créée, séparés par des virgules")
 	)
 	flag.Parse()
 
@@ -37,6 +42,11 @@ func main() {
 		return
 	}
 
+	if *newKey != "" {
+		createAPIKey(*newKey, strings.Split(*keyRole, ","))
+		return
+	}
+
 	log.Println("🚀 Starting Smart Infrastructure API Server...")
 
 	// Initialiser le SmartInfrastructureManager
@@ -48,6 +58,15 @@ func main() {
 	// Créer le handler API
 	apiHandler := api.NewInfrastructureAPIHandler(orchestrator)
 
+	// Authentification des endpoints par clés API et/ou JWT (variables AUTH_*)
+	if !*noAuth {
+		authMiddleware, err := auth.MiddlewareFromEnv(api.InfrastructureAuthPolicy())
+		if err != nil {
+			log.Fatalf("❌ Failed to configure authentication: %v", err)
+		}
+		apiHandler.SetAuthMiddleware(authMiddleware)
+	}
+
 	// Démarrer le serveur dans une goroutine
 	serverErrors := make(chan error, 1)
 	go func() {
@@ -78,6 +97,25 @@ func main() {
 	}
 }
 
+// createAPIKey génère une clé API et l'affiche une seule fois
+func createAPIKey(name string, roles []string) {
+	path := os.Getenv(auth.EnvAPIKeysFile)
+	if path == "" {
+		log.Fatalf("❌ %s must point to the API keys file", auth.EnvAPIKeysFile)
+	}
+
+	store, err := auth.NewAPIKeyStore(path)
+	if err != nil {
+		log.Fatalf("❌ Failed to open API keys: %v", err)
+	}
+	value, key, err := store.Generate(name, roles, nil, 0, 0)
+	if err != nil {
+		log.Fatalf("❌ Failed to create API key: %v", err)
+	}
+
+	fmt.Printf("API key %s (%s) created with roles %v:\n%s\n", key.ID, name, roles, value)
+}
+
 func printHelp() {
 	fmt.Printf(`Smart Infrastructure API Server %s
 
@@ -92,6 +130,16 @@ OPTIONS:
     -port <int>     Port pour l'API server (défaut: %d)
     -help           Afficher cette aide
     -version        Afficher la version
+    -no-auth        Désactiver l'authentification (développement local uniquement)
+    -create-api-key <nom>  Créer une clé API et quitter
+    -roles <rôles>  Rôles de la clé créée (défaut: viewer)
+
+AUTHENTIFICATION:
+    Les endpoints exigent une clé API (en-tête X-API-Key) ou un JWT (Authorization: Bearer).
+    AUTH_API_KEYS_FILE    Fichier des clés API
+    AUTH_JWT_SECRET       Secret HS256, ou AUTH_JWT_PUBLIC_KEY pour EdDSA
+    AUTH_POLICY_FILE      Politique YAML remplaçant les rôles par défaut
+    Rôles par défaut : viewer (lecture), operator (pilotage), admin (tout)
 
 ENDPOINTS DISPONIBLES:
 
@@ -121,7 +169,7 @@ EXEMPLES:
     %s -port 9090
 
     # Tester les endpoints avec curl
-    curl http://localhost:8080/api/v1/infrastructure/status
+    curl -H "X-API-Key: $API_KEY" http://localhost:8080/api/v1/infrastructure/status
     curl -X POST http://localhost:8080/api/v1/monitoring/start
     curl -X POST http://localhost:8080/api/v1/auto-healing/enable
 
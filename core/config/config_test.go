@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigYAML(t *testing.T) {
@@ -19,16 +22,46 @@ func TestLoadConfigYAML(t *testing.T) {
 }
 
 func TestHotReloadConfig(t *testing.T) {
-	content := []byte("env: reload\nsettings:\n  key: value\n")
-	tmp := "test_reload.yaml"
-	_ = os.WriteFile(tmp, content, 0o644)
-	defer func() { _, _ = os.ReadFile(tmp) }() // Correctly ignore two return values
-	h := &HotReloadConfig{Path: tmp}
-	// Note: h.WatchAndReload() likely starts a goroutine.
-	// Proper testing of this might require more advanced techniques
-	// to ensure the reload logic is triggered and verified.
-	// For now, we're just checking if it runs without immediate error.
-	h.WatchAndReload()
-	// Attempt to clean up the temp file
-	_ = os.Remove(tmp)
+	tmp := filepath.Join(t.TempDir(), "test_reload.yaml")
+	_ = os.WriteFile(tmp, []byte("env: reload\nsettings:\n  key: value\n"), 0o644)
+
+	reloaded := make(chan *AppConfig, 1)
+	rejected := make(chan error, 1)
+	h := &HotReloadConfig{
+		Path: tmp,
+		Validate: func(cfg *AppConfig) error {
+			if cfg.Env == "" {
+				return errors.New("env is required")
+			}
+			return nil
+		},
+		OnReload: func(cfg *AppConfig) { reloaded <- cfg },
+		OnError:  func(err error) { rejected <- err },
+	}
+	if err := h.WatchAndReload(); err != nil {
+		t.Fatalf("WatchAndReload failed: %v", err)
+	}
+	defer h.Stop()
+	<-reloaded
+
+	_ = os.WriteFile(tmp, []byte("env: updated\n"), 0o644)
+	select {
+	case cfg := <-reloaded:
+		if cfg.Env != "updated" || h.Get().Env != "updated" {
+			t.Errorf("expected updated config, got %+v", cfg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	// Invalid content is reported and the previous config stays active
+	_ = os.WriteFile(tmp, []byte("env: [unclosed\n"), 0o644)
+	select {
+	case <-rejected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("parse error was not reported")
+	}
+	if h.Get().Env != "updated" || h.LastError() == nil {
+		t.Errorf("invalid file must not replace the config: %+v, %v", h.Get(), h.LastError())
+	}
 }
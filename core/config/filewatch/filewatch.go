@@ -0,0 +1,118 @@
+// Package filewatch reloads configuration files when they change on disk.
+// Editors and deployment tools emit a burst of events for a single save, often
+// by replacing the file atomically, so the directories of the files are
+// watched and one callback runs per burst.
+package filewatch
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce groups the burst of events emitted for a single save.
+const DefaultDebounce = 100 * time.Millisecond
+
+// Options configures a Watcher.
+type Options struct {
+	// Match reports whether an event path concerns a watched file; by
+	// default only the paths given to Watch match.
+	Match    func(path string) bool
+	OnChange func()      // Called once per burst of events on a matching file
+	OnError  func(error) // Optional, receives the fsnotify errors
+	Debounce time.Duration
+}
+
+// Watcher calls Options.OnChange after the files it watches change.
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	stop    sync.Once
+	wg      sync.WaitGroup
+}
+
+// Watch watches the directories of paths until Close.
+func Watch(paths []string, opts Options) (*Watcher, error) {
+	if opts.OnChange == nil {
+		return nil, fmt.Errorf("filewatch: OnChange is required")
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Match == nil {
+		targets := make(map[string]bool, len(paths))
+		for _, path := range paths {
+			targets[filepath.Clean(path)] = true
+		}
+		opts.Match = func(path string) bool { return targets[filepath.Clean(path)] }
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	w := &Watcher{watcher: watcher, done: make(chan struct{})}
+	w.wg.Add(1)
+	go w.loop(opts)
+	return w, nil
+}
+
+// Close stops the watch and waits for a running OnChange to return.
+func (w *Watcher) Close() error {
+	var err error
+	w.stop.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+		w.wg.Wait()
+	})
+	return err
+}
+
+// loop calls OnChange after each burst of events on a matching file.
+func (w *Watcher) loop(opts Options) {
+	defer w.wg.Done()
+
+	timer := time.NewTimer(opts.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 || !opts.Match(event.Name) {
+				continue
+			}
+			timer.Reset(opts.Debounce)
+		case <-timer.C:
+			opts.OnChange()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchDebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	w, err := Watch([]string{path}, Options{
+		OnChange: func() { changes.Add(1) },
+		Debounce: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer w.Close()
+
+	// Other files of the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("b: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if n := changes.Load(); n != 0 {
+		t.Fatalf("changes = %d after writing another file, want 0", n)
+	}
+
+	// A burst of writes triggers a single change
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(path, []byte("a: 2\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for changes.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("changes = %d after a burst of writes, want 1", n)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
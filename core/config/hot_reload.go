@@ -1,37 +1,94 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"sync"
-	"time"
+
+	"email_sender/core/config/filewatch"
 
 	"gopkg.in/yaml.v3"
 )
 
 // HotReloadConfig supports hot-reload without restart.
+// The file is watched with fsnotify; a new version is only swapped in once it
+// parses and passes Validate, otherwise the previous config stays active and
+// the error is reported through OnError and LastError.
 type HotReloadConfig struct {
 	Path   string
 	Config *AppConfig
-	mu     sync.RWMutex
+
+	Validate func(*AppConfig) error // Optional, runs before the swap
+	OnReload func(*AppConfig)       // Optional, called after a successful swap
+	OnError  func(error)            // Optional, called when a reload is rejected
+
+	reloadMu sync.Mutex // Serializes reloads so an older read never wins
+	mu       sync.RWMutex
+	lastErr  error
+	watcher  *filewatch.Watcher
 }
 
-func (h *HotReloadConfig) WatchAndReload() {
-	go func() {
-		for {
-			time.Sleep(1 * time.Second)
-			data, err := os.ReadFile(h.Path) // Changed to os.ReadFile
-			if err == nil {
-				var cfg AppConfig
-				if err := yaml.Unmarshal(data, &cfg); err == nil {
-					h.mu.Lock()
-					h.Config = &cfg
-					h.mu.Unlock()
-				}
-				// It might be useful to log errors from yaml.Unmarshal here
+// WatchAndReload loads the file and reloads it on every change until Stop.
+func (h *HotReloadConfig) WatchAndReload() error {
+	if err := h.Reload(); err != nil {
+		return err
+	}
+
+	watcher, err := filewatch.Watch([]string{h.Path}, filewatch.Options{
+		OnChange: func() { h.Reload() },
+		OnError: func(err error) {
+			if h.OnError != nil {
+				h.OnError(fmt.Errorf("watcher error: %w", err))
 			}
-			// And also log errors from os.ReadFile
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.watcher = watcher
+	h.mu.Unlock()
+	return nil
+}
+
+// Reload reads, parses and validates the file, then swaps it in.
+func (h *HotReloadConfig) Reload() error {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+
+	cfg, err := h.load()
+	h.mu.Lock()
+	h.lastErr = err
+	if err == nil {
+		h.Config = cfg
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		if h.OnError != nil {
+			h.OnError(err)
 		}
-	}() // Added () to invoke the goroutine
+		return err
+	}
+	if h.OnReload != nil {
+		h.OnReload(cfg)
+	}
+	return nil
+}
+
+// Stop ends the watch started by WatchAndReload.
+func (h *HotReloadConfig) Stop() error {
+	h.mu.Lock()
+	watcher := h.watcher
+	h.watcher = nil
+	h.mu.Unlock()
+
+	if watcher == nil {
+		return nil
+	}
+	// Outside the lock: Close waits for a running reload
+	return watcher.Close()
 }
 
 func (h *HotReloadConfig) Get() *AppConfig {
@@ -39,3 +96,28 @@ func (h *HotReloadConfig) Get() *AppConfig {
 	defer h.mu.RUnlock()
 	return h.Config
 }
+
+// LastError returns the error of the last reload attempt, nil if it succeeded.
+func (h *HotReloadConfig) LastError() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastErr
+}
+
+// load reads and validates the file without touching the active config.
+func (h *HotReloadConfig) load() (*AppConfig, error) {
+	data, err := os.ReadFile(h.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", h.Path, err)
+	}
+	var cfg AppConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", h.Path, err)
+	}
+	if h.Validate != nil {
+		if err := h.Validate(&cfg); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", h.Path, err)
+		}
+	}
+	return &cfg, nil
+}
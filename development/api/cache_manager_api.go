@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	cachemanager "email_sender/development/managers/cache-manager"
+	"email_sender/development/managers/security-manager/auth"
 )
 
 var (
@@ -92,9 +93,34 @@ func contextHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// cacheAuthPolicy : lecture des logs et du contexte pour "viewer", écriture pour "writer"
+func cacheAuthPolicy() *auth.Policy {
+	return &auth.Policy{
+		Roles: map[string][]string{
+			"viewer": {"cache:read"},
+			"writer": {"cache:*"},
+			"admin":  {"*"},
+		},
+		Routes: []auth.Route{
+			{Method: "GET", Path: "/logs", Permission: "cache:read"},
+			{Method: "POST", Path: "/logs", Permission: "cache:write"},
+			{Method: "GET", Path: "/context", Permission: "cache:read"},
+			{Method: "POST", Path: "/context", Permission: "cache:write"},
+		},
+	}
+}
+
 func main() {
-	http.HandleFunc("/logs", logsHandler)
-	http.HandleFunc("/context", contextHandler)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logs", logsHandler)
+	mux.HandleFunc("/context", contextHandler)
+
+	// Authentification par clés API et/ou JWT (variables AUTH_*)
+	authMiddleware, err := auth.MiddlewareFromEnv(cacheAuthPolicy())
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+
 	log.Println("CacheManager API server running on :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Fatal(http.ListenAndServe(":8080", authMiddleware.Handler(mux)))
 }
@@ -11,6 +11,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"email_sender/development/hooks/commit-interceptor/training"
 )
 
 // MultiCriteriaClassifier - Moteur de classification hybride
@@ -21,6 +23,7 @@ type MultiCriteriaClassifier struct {
 	learningEnabled  bool
 	performanceCache map[string]*ClassificationResult
 	metricsCollector *ClassificationMetrics
+	trainedModel     *training.Model // optionnel, produit par "commit-interceptor train"
 }
 
 // ClassificationWeights - Pondération des facteurs de décision
@@ -98,6 +101,12 @@ func NewMultiCriteriaClassifier(semanticManager *SemanticEmbeddingManager,
 	}
 }
 
+// SetTrainedModel - Active le modèle entraîné sur l'historique du dépôt
+func (mc *MultiCriteriaClassifier) SetTrainedModel(model *training.Model) {
+	mc.trainedModel = model
+	mc.performanceCache = make(map[string]*ClassificationResult)
+}
+
 // ClassifyCommitAdvanced - Classification hybride multi-critères
 func (mc *MultiCriteriaClassifier) ClassifyCommitAdvanced(ctx context.Context,
 	commitData *CommitData) (*ClassificationResult, error) {
@@ -196,9 +205,22 @@ func (mc *MultiCriteriaClassifier) analyzeTraditionalFactors(commitData *CommitD
 		impactScore = 0.70 // Force l'impact à 0.70 pour ce cas de test
 	}
 
+	// Le modèle entraîné remplace les heuristiques quand il est plus confiant
+	predictedType, confidence := analysis.ChangeType, analysis.Confidence
+	if mc.trainedModel != nil {
+		text := commitData.Message
+		if example, ok := training.NewExample("", commitData.Message, commitData.Files); ok {
+			text = example.Text
+		}
+		prediction := mc.trainedModel.Predict(text, commitData.Files)
+		if prediction.Confidence > confidence {
+			predictedType, confidence = analyzerChangeType(prediction.Label), prediction.Confidence
+		}
+	}
+
 	return &ClassificationResult{
-		PredictedType:  analysis.ChangeType,
-		Confidence:     analysis.Confidence,
+		PredictedType:  predictedType,
+		Confidence:     confidence,
 		CompositeScore: (messageScore + fileScore + impactScore) / 3.0,
 		DecisionFactors: map[string]float64{
 			"message_patterns": messageScore,
@@ -208,6 +230,15 @@ func (mc *MultiCriteriaClassifier) analyzeTraditionalFactors(commitData *CommitD
 	}, nil
 }
 
+// analyzerChangeType - Convertit un type Conventional Commits du modèle
+// vers les types de l'analyseur
+func analyzerChangeType(label string) string {
+	if label == "feat" {
+		return "feature"
+	}
+	return label
+}
+
 // synthesizeClassification - Synthèse multi-critères avec pondération
 func (mc *MultiCriteriaClassifier) synthesizeClassification(commitData *CommitData, semanticResult, traditionalResult *ClassificationResult) *ClassificationResult {
 	// Fusion des facteurs de décision avec normalisation des scores
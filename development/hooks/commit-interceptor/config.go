@@ -16,6 +16,7 @@ type Config struct {
     NotificationsEnabled bool               `json:"notifications_enabled"`
     Webhooks             WebhookConfig      `json:"webhooks"`
     Logging              LoggingConfig      `json:"logging"`
+    Classifier           ClassifierConfig   `json:"classifier"`
     TestMode             bool               `json:"test_mode"` // Nouvelle option pour mode test
 }
 
@@ -68,6 +69,11 @@ type LoggingConfig struct {
     OutputFile string `json:"output_file"`
 }
 
+// ClassifierConfig contains the trained commit classifier settings
+type ClassifierConfig struct {
+    ModelPath string `json:"model_path"` // written by "commit-interceptor train"
+}
+
 // LoadConfig loads configuration from file or environment
 func LoadConfig() *Config {
     config := getDefaultConfig()
@@ -149,6 +155,9 @@ func getDefaultConfig() *Config {
             Format:     "json",
             OutputFile: "",
         },
+        Classifier: ClassifierConfig{
+            ModelPath: "config/commit-classifier-model.json",
+        },
         TestMode: false, // Mode test désactivé par défaut
     }
 }
@@ -228,6 +237,11 @@ func loadConfigFromEnv(config *Config) {
         config.Logging.OutputFile = logFile
     }
     
+    // Classifier configuration
+    if modelPath := os.Getenv("COMMIT_CLASSIFIER_MODEL"); modelPath != "" {
+        config.Classifier.ModelPath = modelPath
+    }
+    
     // Mode test
     if testMode := os.Getenv("TEST_MODE"); testMode == "true" {
         config.TestMode = true
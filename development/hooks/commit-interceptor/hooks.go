@@ -0,0 +1,368 @@
+// development/hooks/commit-interceptor/hooks.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"email_sender/development/hooks/commit-interceptor/policy"
+	"email_sender/development/hooks/commit-interceptor/training"
+)
+
+// Hook modes run by the binary when installed as a local git hook
+const (
+	HookPreCommit  = "pre-commit"
+	HookCommitMsg  = "commit-msg"
+	HookPreReceive = "pre-receive"
+)
+
+// defaultPolicyFile is looked up at the top of the repository
+const defaultPolicyFile = ".commit-policy.json"
+
+// HookRunner evaluates commits against the policy from inside git hooks
+type HookRunner struct {
+	engine  *policy.Engine
+	stdout  io.Writer
+	stderr  io.Writer
+	jsonOut bool
+}
+
+// HookResult is the machine-readable output of a hook (--format json)
+type HookResult struct {
+	Hook    string           `json:"hook"`
+	Allowed bool             `json:"allowed"`
+	Reports []*policy.Report `json:"reports"`
+}
+
+// runCLI runs the command line modes: "commit-interceptor train" and the
+// hooks, called as "commit-interceptor hook <mode> [args]" or installed (or
+// symlinked) as .git/hooks/<mode>. It returns false for the server mode.
+func runCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, bool) {
+	if len(args) >= 2 && args[1] == "train" {
+		return runTrain(args[2:], stdout, stderr), true
+	}
+
+	mode, hookArgs := "", []string(nil)
+	switch base := strings.TrimSuffix(filepath.Base(args[0]), ".exe"); base {
+	case HookPreCommit, HookCommitMsg, HookPreReceive:
+		mode, hookArgs = base, args[1:]
+	default:
+		if len(args) < 3 || args[1] != "hook" {
+			return 0, false
+		}
+		mode, hookArgs = args[2], args[3:]
+	}
+
+	flags := flag.NewFlagSet(mode, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	policyPath := flags.String("policy", os.Getenv("COMMIT_POLICY_FILE"), "policy file (default: <repository>/"+defaultPolicyFile+")")
+	format := flags.String("format", "text", "output format: text or json")
+	noSuggest := flags.Bool("no-suggest", false, "do not ask the classifier for a commit type")
+	if err := flags.Parse(hookArgs); err != nil {
+		return 2, true
+	}
+
+	runner, err := NewHookRunner(*policyPath, !*noSuggest, stdout, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "commit-interceptor: %v\n", err)
+		return 2, true
+	}
+	runner.jsonOut = *format == "json"
+
+	var result *HookResult
+	switch mode {
+	case HookPreCommit:
+		result, err = runner.PreCommit()
+	case HookCommitMsg:
+		if flags.NArg() != 1 {
+			fmt.Fprintln(stderr, "commit-interceptor: commit-msg expects the message file")
+			return 2, true
+		}
+		result, err = runner.CommitMsg(flags.Arg(0))
+	case HookPreReceive:
+		result, err = runner.PreReceive(stdin)
+	default:
+		fmt.Fprintf(stderr, "commit-interceptor: unknown hook %q (pre-commit, commit-msg, pre-receive)\n", mode)
+		return 2, true
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "commit-interceptor: %s failed: %v\n", mode, err)
+		return 2, true
+	}
+
+	runner.print(result)
+	if !result.Allowed {
+		return 1, true
+	}
+	return 0, true
+}
+
+// NewHookRunner loads the policy; without a policy file the default policy
+// (Conventional Commits and secret detection) applies
+func NewHookRunner(policyPath string, suggest bool, stdout, stderr io.Writer) (*HookRunner, error) {
+	runner := &HookRunner{stdout: stdout, stderr: stderr}
+
+	if policyPath == "" {
+		if top, err := runner.git("rev-parse", "--show-toplevel"); err == nil {
+			candidate := filepath.Join(strings.TrimSpace(top), defaultPolicyFile)
+			if _, err := os.Stat(candidate); err == nil {
+				policyPath = candidate
+			}
+		}
+	}
+
+	rules := policy.Default()
+	if policyPath != "" {
+		loaded, err := policy.Load(policyPath)
+		if err != nil {
+			return nil, err
+		}
+		rules = loaded
+	}
+
+	var suggester policy.Suggester
+	if suggest {
+		suggester = newClassifierSuggester(LoadConfig())
+	}
+	engine, err := policy.NewEngine(rules, suggester)
+	if err != nil {
+		return nil, err
+	}
+	runner.engine = engine
+	return runner, nil
+}
+
+// PreCommit checks the staged hunks of the current branch
+func (h *HookRunner) PreCommit() (*HookResult, error) {
+	changes, err := h.stagedChanges()
+	if err != nil {
+		return nil, err
+	}
+	branch, _ := h.git("symbolic-ref", "--quiet", "--short", "HEAD")
+
+	report := h.engine.Evaluate(policy.Input{Branch: strings.TrimSpace(branch), Changes: changes})
+	return newHookResult(HookPreCommit, report), nil
+}
+
+// CommitMsg checks the message file written by git; the staged files only
+// feed the suggested commit type
+func (h *HookRunner) CommitMsg(messageFile string) (*HookResult, error) {
+	data, err := os.ReadFile(messageFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	var files []string
+	if changes, err := h.stagedChanges(); err == nil {
+		for _, change := range changes {
+			files = append(files, change.Path)
+		}
+	}
+
+	report := h.engine.Evaluate(policy.Input{Message: policy.CleanMessage(string(data)), Files: files})
+	return newHookResult(HookCommitMsg, report), nil
+}
+
+// PreReceive checks every new commit of the pushed branches, read from the
+// "<old> <new> <ref>" lines git writes on stdin
+func (h *HookRunner) PreReceive(stdin io.Reader) (*HookResult, error) {
+	var reports []*policy.Report
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		oldRev, newRev, ref := fields[0], fields[1], fields[2]
+		if !strings.HasPrefix(ref, "refs/heads/") || isZeroRev(newRev) {
+			continue // tags and branch deletions
+		}
+		branch := strings.TrimPrefix(ref, "refs/heads/")
+
+		// A new branch brings the commits no existing ref has
+		revList := []string{"rev-list", "--reverse", oldRev + ".." + newRev}
+		if isZeroRev(oldRev) {
+			revList = []string{"rev-list", "--reverse", newRev, "--not", "--all"}
+		}
+		output, err := h.git(revList...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range strings.Fields(output) {
+			report, err := h.evaluateCommit(branch, commit)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, report)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pushed refs: %w", err)
+	}
+
+	result := &HookResult{Hook: HookPreReceive, Allowed: true, Reports: reports}
+	for _, report := range reports {
+		result.Allowed = result.Allowed && report.Allowed
+	}
+	return result, nil
+}
+
+func (h *HookRunner) evaluateCommit(branch, commit string) (*policy.Report, error) {
+	message, err := h.git("log", "-1", "--format=%B", commit)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := h.git("-c", "core.quotePath=false", "diff-tree", "-p", "-U0", "-M",
+		"--no-color", "--no-ext-diff", "--no-commit-id", "--root", "-r", commit)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := policy.ParseUnifiedDiff(strings.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []policy.Change{}
+	}
+
+	return h.engine.Evaluate(policy.Input{
+		Commit:  commit,
+		Branch:  branch,
+		Message: strings.TrimSpace(message),
+		Changes: changes,
+	}), nil
+}
+
+// stagedChanges parses the staged hunks; the result is never nil so that the
+// content rules always run
+func (h *HookRunner) stagedChanges() ([]policy.Change, error) {
+	diff, err := h.git("-c", "core.quotePath=false", "diff", "--cached", "-U0", "-M", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := policy.ParseUnifiedDiff(strings.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []policy.Change{}
+	}
+	return changes, nil
+}
+
+func (h *HookRunner) print(result *HookResult) {
+	if h.jsonOut {
+		encoder := json.NewEncoder(h.stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(result)
+		return
+	}
+	if result.Allowed {
+		return
+	}
+
+	fmt.Fprintf(h.stderr, "commit-interceptor: %s rejected by the commit policy\n", result.Hook)
+	for _, report := range result.Reports {
+		for _, violation := range report.Violations {
+			location := ""
+			if violation.Commit != "" {
+				location = shortHash(violation.Commit) + " "
+			}
+			if violation.Path != "" {
+				location += violation.Path
+				if violation.Line > 0 {
+					location += fmt.Sprintf(":%d", violation.Line)
+				}
+				location += " "
+			}
+			fmt.Fprintf(h.stderr, "  [%s] %s%s\n", violation.Rule, location, violation.Message)
+		}
+		if report.Suggestion != nil {
+			fmt.Fprintf(h.stderr, "  suggested header: %s (type %s, confidence %.2f)\n",
+				report.Suggestion.Header, report.Suggestion.Type, report.Suggestion.Confidence)
+		}
+	}
+}
+
+// git runs git in the repository the hook was called from (git sets the
+// working directory and GIT_DIR for hooks)
+func (h *HookRunner) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func newHookResult(hook string, report *policy.Report) *HookResult {
+	return &HookResult{Hook: hook, Allowed: report.Allowed, Reports: []*policy.Report{report}}
+}
+
+func isZeroRev(rev string) bool {
+	return strings.Trim(rev, "0") == ""
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+// classifierSuggester proposes the commit type of a rejected message. The
+// model trained on the repository history ("commit-interceptor train") is
+// used when there is one, the semantic embedding manager otherwise.
+type classifierSuggester struct {
+	model    *training.Model
+	semantic *SemanticEmbeddingManager
+}
+
+func newClassifierSuggester(config *Config) *classifierSuggester {
+	return &classifierSuggester{
+		model:    loadTrainedModel(config),
+		semantic: NewSemanticEmbeddingManager(config),
+	}
+}
+
+// SuggestType implements policy.Suggester
+func (s *classifierSuggester) SuggestType(message string, files []string) (string, float64, error) {
+	if s.model != nil {
+		prediction := predictCommitType(s.model, message, files)
+		return prediction.Label, prediction.Confidence, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	commitContext, err := s.semantic.CreateCommitContext(ctx, &CommitData{
+		Message:   message,
+		Files:     files,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The semantic manager speaks in branch categories, hooks in Conventional Commits
+	commitType := commitContext.PredictedType
+	if commitType == "feature" {
+		commitType = "feat"
+	}
+	return commitType, commitContext.Confidence, nil
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+
+	"email_sender/development/hooks/commit-interceptor/training"
 )
 
 type CommitInterceptor struct {
@@ -19,17 +21,24 @@ type CommitInterceptor struct {
 	analyzer         *CommitAnalyzer
 	router           *BranchRouter
 	config           *Config
+	model            *training.Model // nil without a trained model
 }
 
 // NewCommitInterceptor creates a new commit interceptor instance
 func NewCommitInterceptor() *CommitInterceptor {
 	config := LoadConfig()
 
+	model := loadTrainedModel(config)
+	if model != nil {
+		log.Printf("Commit classifier model loaded: %d commits, types %v", model.Examples, model.Labels)
+	}
+
 	return &CommitInterceptor{
 		branchingManager: NewBranchingManager(config),
 		analyzer:         NewCommitAnalyzer(config),
 		router:           NewBranchRouter(config),
 		config:           config,
+		model:            model,
 	}
 }
 
@@ -58,6 +67,8 @@ func (ci *CommitInterceptor) HandlePreCommit(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	ci.applyTrainedModel(commitData, analysis)
+
 	// Route the commit to appropriate branch
 	routingDecision, err := ci.router.RouteCommit(analysis)
 	if err != nil {
@@ -103,6 +114,23 @@ func (ci *CommitInterceptor) HandlePostCommit(w http.ResponseWriter, r *http.Req
 	w.Write([]byte("Post-commit processed"))
 }
 
+// applyTrainedModel replaces the change type found by the analyzer with the
+// prediction of the trained model when the model is more confident
+func (ci *CommitInterceptor) applyTrainedModel(commitData *CommitData, analysis *CommitAnalysis) {
+	if ci.model == nil {
+		return
+	}
+	prediction := predictCommitType(ci.model, commitData.Message, commitData.Files)
+	if prediction.Confidence <= analysis.Confidence {
+		return
+	}
+	// The model speaks in Conventional Commits, the router in branch categories
+	analysis.ChangeType, analysis.Confidence = prediction.Label, prediction.Confidence
+	if prediction.Label == "feat" {
+		analysis.ChangeType = "feature"
+	}
+}
+
 // parseCommitData extracts commit information from HTTP request
 func (ci *CommitInterceptor) parseCommitData(r *http.Request) (*CommitData, error) {
 	// Implementation for parsing Git webhook payload
@@ -160,6 +188,11 @@ func (ci *CommitInterceptor) collectMetrics() []byte {
 }
 
 func main() {
+	// Hook modes (pre-commit, commit-msg, pre-receive) and "train"
+	if code, handled := runCLI(os.Args, os.Stdin, os.Stdout, os.Stderr); handled {
+		os.Exit(code)
+	}
+
 	log.Println("Starting Commit Interceptor...")
 
 	// Initialize interceptor
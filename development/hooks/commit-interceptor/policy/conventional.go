@@ -0,0 +1,166 @@
+// development/hooks/commit-interceptor/policy/conventional.go
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit is a commit message parsed with the Conventional
+// Commits 1.0 grammar:
+//
+//	<type>[(<scope>)][!]: <description>
+//
+//	[body]
+//
+//	[footer(s)]
+type ConventionalCommit struct {
+	Type        string    `json:"type"`
+	Scope       string    `json:"scope,omitempty"`
+	Breaking    bool      `json:"breaking"`
+	Description string    `json:"description"`
+	Body        string    `json:"body,omitempty"`
+	Trailers    []Trailer `json:"trailers,omitempty"`
+}
+
+// Trailer is a footer line such as "Signed-off-by: Jane <jane@example.com>"
+// or "Refs #42"
+type Trailer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+var (
+	headerPattern  = regexp.MustCompile(`^([a-zA-Z]+)(\(([^()\r\n]*)\))?(!)?: (.*)$`)
+	trailerPattern = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z][A-Za-z0-9-]*)(: | #)(.+)$`)
+)
+
+// scissorsLine marks the end of the message in "git commit -v" templates
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// CleanMessage strips what git itself strips from a commit message file:
+// comment lines, the verbose diff after the scissors line, and surrounding
+// blank lines
+func CleanMessage(raw string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if line == scissorsLine {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// ParseConventional parses a cleaned commit message. The error describes the
+// first grammar rule the message breaks.
+func ParseConventional(message string) (*ConventionalCommit, error) {
+	lines := strings.Split(message, "\n")
+	header := lines[0]
+
+	match := headerPattern.FindStringSubmatch(header)
+	if match == nil {
+		return nil, fmt.Errorf("header %q does not match <type>[(scope)][!]: <description>", header)
+	}
+	if match[2] != "" && strings.TrimSpace(match[3]) == "" {
+		return nil, fmt.Errorf("scope cannot be empty")
+	}
+	if strings.TrimSpace(match[5]) == "" {
+		return nil, fmt.Errorf("description cannot be empty")
+	}
+	if len(lines) > 1 && lines[1] != "" {
+		return nil, fmt.Errorf("header must be followed by a blank line")
+	}
+
+	commit := &ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[3],
+		Breaking:    match[4] == "!",
+		Description: strings.TrimSpace(match[5]),
+	}
+
+	// The footers are the last paragraph when every line of it is a trailer
+	// (continuation lines start with a space)
+	paragraphs := splitParagraphs(lines[1:])
+	if len(paragraphs) > 0 {
+		if trailers, ok := parseTrailers(paragraphs[len(paragraphs)-1]); ok {
+			commit.Trailers = trailers
+			paragraphs = paragraphs[:len(paragraphs)-1]
+		}
+	}
+	var body []string
+	for _, paragraph := range paragraphs {
+		body = append(body, strings.Join(paragraph, "\n"))
+	}
+	commit.Body = strings.Join(body, "\n\n")
+
+	for _, trailer := range commit.Trailers {
+		if trailer.Token == "BREAKING CHANGE" || trailer.Token == "BREAKING-CHANGE" {
+			commit.Breaking = true
+		}
+	}
+	return commit, nil
+}
+
+// ParseTrailers returns the trailers of any commit message, conventional or not
+func ParseTrailers(message string) []Trailer {
+	paragraphs := splitParagraphs(strings.Split(message, "\n")[1:])
+	if len(paragraphs) == 0 {
+		return nil
+	}
+	trailers, _ := parseTrailers(paragraphs[len(paragraphs)-1])
+	return trailers
+}
+
+// HasTrailer reports whether a trailer with token (case-insensitive) exists
+func (c *ConventionalCommit) HasTrailer(token string) bool {
+	return hasTrailer(c.Trailers, token)
+}
+
+func hasTrailer(trailers []Trailer, token string) bool {
+	for _, trailer := range trailers {
+		if strings.EqualFold(trailer.Token, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitParagraphs(lines []string) [][]string {
+	var paragraphs [][]string
+	var current []string
+	for _, line := range lines {
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+func parseTrailers(paragraph []string) ([]Trailer, bool) {
+	var trailers []Trailer
+	for _, line := range paragraph {
+		if strings.HasPrefix(line, " ") && len(trailers) > 0 {
+			trailers[len(trailers)-1].Value += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		match := trailerPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, false
+		}
+		trailers = append(trailers, Trailer{Token: match[1], Value: strings.TrimSpace(match[3])})
+	}
+	return trailers, len(trailers) > 0
+}
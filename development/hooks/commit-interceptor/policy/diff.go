@@ -0,0 +1,146 @@
+// development/hooks/commit-interceptor/policy/diff.go
+package policy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Change is one file of a diff
+type Change struct {
+	Path       string      `json:"path"`
+	OldPath    string      `json:"old_path,omitempty"`
+	Status     string      `json:"status"` // added, modified, deleted, renamed
+	Binary     bool        `json:"binary,omitempty"`
+	Added      int         `json:"added"`
+	Deleted    int         `json:"deleted"`
+	AddedLines []AddedLine `json:"-"`
+}
+
+// AddedLine is a line added by a hunk, numbered in the new file
+type AddedLine struct {
+	Number int
+	Text   string
+}
+
+// ParseUnifiedDiff reads the output of "git diff" (any context size, no
+// color), e.g. "git diff --cached -U0 --no-color" for the staged hunks
+func ParseUnifiedDiff(r io.Reader) ([]Change, error) {
+	var changes []Change
+	var current *Change
+	newLine := 0
+
+	flush := func() {
+		if current != nil {
+			changes = append(changes, *current)
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &Change{Status: "modified"}
+			if oldPath, newPath, ok := splitDiffHeader(strings.TrimPrefix(line, "diff --git ")); ok {
+				current.OldPath, current.Path = oldPath, newPath
+			}
+		case current == nil:
+			// Preamble (e.g. "git show" commit header)
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case strings.HasPrefix(line, "rename to "):
+			current.Status = "renamed"
+			current.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "rename from "):
+			current.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			current.Binary = true
+		case strings.HasPrefix(line, "--- "):
+			if path := strings.TrimPrefix(line, "--- "); path != "/dev/null" {
+				current.OldPath = trimPrefixDir(unquotePath(path), "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path != "/dev/null" {
+				current.Path = trimPrefixDir(unquotePath(path), "b/")
+			} else {
+				current.Path = current.OldPath
+			}
+		case strings.HasPrefix(line, "@@"):
+			start, err := hunkNewStart(line)
+			if err != nil {
+				return nil, err
+			}
+			newLine = start
+		case strings.HasPrefix(line, "+"):
+			current.Added++
+			current.AddedLines = append(current.AddedLines, AddedLine{Number: newLine, Text: line[1:]})
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			current.Deleted++
+		case strings.HasPrefix(line, " "):
+			newLine++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	flush()
+
+	for i := range changes {
+		if changes[i].OldPath == changes[i].Path {
+			changes[i].OldPath = ""
+		}
+	}
+	return changes, nil
+}
+
+// hunkNewStart reads the new file start line of "@@ -a,b +c,d @@"
+func hunkNewStart(line string) (int, error) {
+	fields := strings.Fields(line)
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "+") {
+			start := strings.SplitN(field[1:], ",", 2)[0]
+			n, err := strconv.Atoi(start)
+			if err != nil {
+				return 0, fmt.Errorf("invalid hunk header %q", line)
+			}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid hunk header %q", line)
+}
+
+// splitDiffHeader splits "a/old b/new" when the paths are unambiguous; the
+// ---/+++ lines refine it for text diffs
+func splitDiffHeader(header string) (string, string, bool) {
+	if strings.HasPrefix(header, `"`) {
+		return "", "", false
+	}
+	if i := strings.Index(header, " b/"); i >= 0 && strings.HasPrefix(header, "a/") {
+		return header[2:i], header[i+3:], true
+	}
+	return "", "", false
+}
+
+func unquotePath(path string) string {
+	path = strings.TrimSuffix(path, "\t")
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
+
+func trimPrefixDir(path, prefix string) string {
+	return strings.TrimPrefix(path, prefix)
+}
@@ -0,0 +1,411 @@
+// development/hooks/commit-interceptor/policy/policy.go
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rule identifiers reported in violations; they are stable so that CI jobs
+// and editors can react to specific rejections
+const (
+	RuleMessageEmpty        = "message.empty"
+	RuleMessageConventional = "message.conventional"
+	RuleMessageType         = "message.type"
+	RuleMessageScope        = "message.scope"
+	RuleMessageHeaderLength = "message.header_length"
+	RuleMessageTrailer      = "message.trailer"
+	RuleForbiddenPath       = "paths.forbidden"
+	RuleDiffLines           = "diff.max_lines"
+	RuleDiffFiles           = "diff.max_files"
+	RuleSecret              = "secrets.detected"
+)
+
+// DefaultTypes are the commit types accepted when the policy lists none
+var DefaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// Policy is the declarative policy file (JSON, like branching-auto.json)
+type Policy struct {
+	Message  MessagePolicy  `json:"message"`
+	Branches []BranchPolicy `json:"branches"`
+	Secrets  SecretPolicy   `json:"secrets"`
+}
+
+// MessagePolicy constrains commit messages
+type MessagePolicy struct {
+	Conventional     bool     `json:"conventional"`
+	Types            []string `json:"types"`
+	Scopes           []string `json:"scopes"` // empty accepts any scope
+	RequireScope     bool     `json:"require_scope"`
+	MaxHeaderLength  int      `json:"max_header_length"`
+	RequiredTrailers []string `json:"required_trailers"` // e.g. "Signed-off-by"
+	// ExemptPatterns are regular expressions of headers that skip the
+	// message rules (merge, revert and fixup commits by default)
+	ExemptPatterns []string `json:"exempt_patterns"`
+}
+
+// BranchPolicy applies to the branches matching Branch ("main", "release/*", "*")
+type BranchPolicy struct {
+	Branch         string   `json:"branch"`
+	ForbiddenPaths []string `json:"forbidden_paths"` // globs, "**" crosses directories
+	MaxDiffLines   int      `json:"max_diff_lines"`  // added + deleted lines, 0 = unlimited
+	MaxFiles       int      `json:"max_files"`
+}
+
+// SecretPolicy configures the secret detection on added lines
+type SecretPolicy struct {
+	Enabled    bool            `json:"enabled"`
+	Patterns   []SecretPattern `json:"patterns"`    // in addition to DefaultSecretPatterns
+	AllowPaths []string        `json:"allow_paths"` // e.g. "testdata/**"
+}
+
+// Default returns the policy used without a policy file: Conventional
+// Commits and secret detection
+func Default() *Policy {
+	return &Policy{
+		Message: MessagePolicy{
+			Conventional:    true,
+			MaxHeaderLength: 100,
+		},
+		Secrets: SecretPolicy{Enabled: true},
+	}
+}
+
+// Load reads a policy file; missing fields keep their zero value
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Violation is a machine-readable rejection reason
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Commit  string `json:"commit,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// Suggestion is the commit type proposed when the message is rejected
+type Suggestion struct {
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence"`
+	Header     string  `json:"header"`
+}
+
+// Report is the result of an evaluation
+type Report struct {
+	Allowed    bool        `json:"allowed"`
+	Violations []Violation `json:"violations"`
+	Suggestion *Suggestion `json:"suggestion,omitempty"`
+}
+
+// Input is what a hook knows about a commit. An empty Message skips the
+// message rules (pre-commit), nil Changes skip the content rules (commit-msg).
+type Input struct {
+	Commit  string
+	Branch  string
+	Message string
+	Changes []Change
+	// Files help the suggestion when Changes are not checked
+	Files []string
+}
+
+// Suggester proposes a commit type from a message and its files; the
+// interceptor plugs its classifier here
+type Suggester interface {
+	SuggestType(message string, files []string) (string, float64, error)
+}
+
+// Engine evaluates inputs against a policy
+type Engine struct {
+	policy    *Policy
+	suggester Suggester
+	exempt    []*regexp.Regexp
+	secrets   []compiledSecret
+}
+
+// NewEngine compiles the policy; suggester may be nil
+func NewEngine(policy *Policy, suggester Suggester) (*Engine, error) {
+	if policy == nil {
+		policy = Default()
+	}
+
+	exemptPatterns := policy.Message.ExemptPatterns
+	if exemptPatterns == nil {
+		exemptPatterns = []string{`^Merge `, `^Revert "`, `^(fixup|squash|amend)! `}
+	}
+	engine := &Engine{policy: policy, suggester: suggester}
+	for _, pattern := range exemptPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exempt pattern %q: %w", pattern, err)
+		}
+		engine.exempt = append(engine.exempt, re)
+	}
+
+	if policy.Secrets.Enabled {
+		patterns := append(append([]SecretPattern(nil), DefaultSecretPatterns...), policy.Secrets.Patterns...)
+		secrets, err := compileSecrets(patterns)
+		if err != nil {
+			return nil, err
+		}
+		engine.secrets = secrets
+	}
+
+	for _, branch := range policy.Branches {
+		if branch.Branch == "" {
+			return nil, fmt.Errorf("branch policy without branch pattern")
+		}
+	}
+	return engine, nil
+}
+
+// Evaluate checks an input against every rule
+func (e *Engine) Evaluate(input Input) *Report {
+	report := &Report{Violations: []Violation{}}
+
+	messageRejected := false
+	if input.Message != "" || input.Changes == nil {
+		for _, violation := range e.checkMessage(input.Message) {
+			violation.Commit = input.Commit
+			report.Violations = append(report.Violations, violation)
+			messageRejected = true
+		}
+	}
+	for _, violation := range e.checkChanges(input.Branch, input.Changes) {
+		violation.Commit = input.Commit
+		report.Violations = append(report.Violations, violation)
+	}
+
+	if messageRejected && e.suggester != nil {
+		report.Suggestion = e.suggest(input)
+	}
+	report.Allowed = len(report.Violations) == 0
+	return report
+}
+
+func (e *Engine) checkMessage(message string) []Violation {
+	rules := e.policy.Message
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return []Violation{{Rule: RuleMessageEmpty, Message: "commit message is empty"}}
+	}
+
+	header := strings.SplitN(message, "\n", 2)[0]
+	for _, re := range e.exempt {
+		if re.MatchString(header) {
+			return nil
+		}
+	}
+
+	var violations []Violation
+	if rules.MaxHeaderLength > 0 && len([]rune(header)) > rules.MaxHeaderLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMessageHeaderLength,
+			Message: fmt.Sprintf("header is %d characters long, the maximum is %d", len([]rune(header)), rules.MaxHeaderLength),
+		})
+	}
+
+	trailers := ParseTrailers(message)
+	if rules.Conventional {
+		commit, err := ParseConventional(message)
+		if err != nil {
+			violations = append(violations, Violation{Rule: RuleMessageConventional, Message: err.Error()})
+		} else {
+			trailers = commit.Trailers
+			violations = append(violations, e.checkTypeAndScope(commit)...)
+		}
+	}
+
+	for _, token := range rules.RequiredTrailers {
+		if !hasTrailer(trailers, token) {
+			violations = append(violations, Violation{
+				Rule:    RuleMessageTrailer,
+				Message: fmt.Sprintf("missing required trailer %q", token),
+			})
+		}
+	}
+	return violations
+}
+
+func (e *Engine) checkTypeAndScope(commit *ConventionalCommit) []Violation {
+	rules := e.policy.Message
+	types := rules.Types
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+
+	var violations []Violation
+	if !containsFold(types, commit.Type) {
+		violations = append(violations, Violation{
+			Rule:    RuleMessageType,
+			Message: fmt.Sprintf("type %q is not one of %s", commit.Type, strings.Join(types, ", ")),
+		})
+	}
+	if commit.Scope == "" && rules.RequireScope {
+		violations = append(violations, Violation{Rule: RuleMessageScope, Message: "a scope is required"})
+	}
+	if commit.Scope != "" && len(rules.Scopes) > 0 && !containsFold(rules.Scopes, commit.Scope) {
+		violations = append(violations, Violation{
+			Rule:    RuleMessageScope,
+			Message: fmt.Sprintf("scope %q is not one of %s", commit.Scope, strings.Join(rules.Scopes, ", ")),
+		})
+	}
+	return violations
+}
+
+func (e *Engine) checkChanges(branch string, changes []Change) []Violation {
+	var violations []Violation
+
+	for _, branchPolicy := range e.policy.Branches {
+		if !MatchPath(branchPolicy.Branch, branch) {
+			continue
+		}
+
+		lines := 0
+		for _, change := range changes {
+			lines += change.Added + change.Deleted
+			for _, pattern := range branchPolicy.ForbiddenPaths {
+				if change.Status != "deleted" && MatchPath(pattern, change.Path) {
+					violations = append(violations, Violation{
+						Rule:    RuleForbiddenPath,
+						Message: fmt.Sprintf("matches %q, forbidden on %s", pattern, branch),
+						Path:    change.Path,
+					})
+					break
+				}
+			}
+		}
+
+		if branchPolicy.MaxFiles > 0 && len(changes) > branchPolicy.MaxFiles {
+			violations = append(violations, Violation{
+				Rule:    RuleDiffFiles,
+				Message: fmt.Sprintf("%d files changed, the maximum on %s is %d", len(changes), branch, branchPolicy.MaxFiles),
+			})
+		}
+		if branchPolicy.MaxDiffLines > 0 && lines > branchPolicy.MaxDiffLines {
+			violations = append(violations, Violation{
+				Rule:    RuleDiffLines,
+				Message: fmt.Sprintf("%d lines changed, the maximum on %s is %d", lines, branch, branchPolicy.MaxDiffLines),
+			})
+		}
+	}
+
+	if len(e.secrets) > 0 {
+		for _, change := range changes {
+			if e.secretsAllowed(change.Path) {
+				continue
+			}
+			for _, match := range scanSecrets(change, e.secrets) {
+				violations = append(violations, Violation{
+					Rule:    RuleSecret,
+					Message: fmt.Sprintf("possible %s added (add %q on the line if this is a false positive)", match.Name, AllowSecretMarker),
+					Path:    change.Path,
+					Line:    match.Line,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func (e *Engine) secretsAllowed(path string) bool {
+	for _, pattern := range e.policy.Secrets.AllowPaths {
+		if MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest asks the classifier for a type and rewrites the header with it
+func (e *Engine) suggest(input Input) *Suggestion {
+	files := append([]string(nil), input.Files...)
+	for _, change := range input.Changes {
+		files = append(files, change.Path)
+	}
+	message := strings.TrimSpace(input.Message)
+
+	commitType, confidence, err := e.suggester.SuggestType(message, files)
+	if err != nil || commitType == "" {
+		return nil
+	}
+
+	description := strings.SplitN(message, "\n", 2)[0]
+	scope := ""
+	if commit, err := ParseConventional(message); err == nil {
+		description, scope = commit.Description, commit.Scope
+	} else if match := headerPattern.FindStringSubmatch(description); match != nil {
+		description = strings.TrimSpace(match[5])
+	}
+	header := commitType
+	if scope != "" {
+		header += "(" + scope + ")"
+	}
+	if description == "" {
+		description = "<description>"
+	}
+
+	return &Suggestion{Type: commitType, Confidence: confidence, Header: header + ": " + description}
+}
+
+// MatchPath matches a slash-separated path against a glob where "*" and
+// "?" stay within a path segment, "**" matches any number of segments and a
+// trailing "/" matches everything below a directory
+func MatchPath(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	re, err := regexp.Compile(globToRegexp(pattern))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(path)
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
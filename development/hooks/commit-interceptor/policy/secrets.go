@@ -0,0 +1,104 @@
+// development/hooks/commit-interceptor/policy/secrets.go
+package policy
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// AllowSecretMarker on a line silences secret detection for that line
+const AllowSecretMarker = "commit-policy:allow-secret"
+
+// SecretPattern is a named regular expression matching a credential
+type SecretPattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// MinEntropy rejects low-entropy matches (placeholders such as
+	// "changeme-changeme") when set; it applies to the last capture group,
+	// or to the whole match without groups
+	MinEntropy float64 `json:"min_entropy,omitempty"`
+}
+
+// DefaultSecretPatterns are always checked when secret detection is enabled
+var DefaultSecretPatterns = []SecretPattern{
+	{Name: "private-key", Pattern: `-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`},
+	{Name: "aws-access-key", Pattern: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "github-token", Pattern: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`},
+	{Name: "gitlab-token", Pattern: `\bglpat-[A-Za-z0-9_-]{20,}\b`},
+	{Name: "slack-token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}\b`},
+	{Name: "google-api-key", Pattern: `\bAIza[0-9A-Za-z_-]{35}\b`},
+	{Name: "stripe-key", Pattern: `\b(sk|rk)_live_[0-9A-Za-z]{20,}\b`},
+	{
+		Name:       "generic-secret",
+		Pattern:    `(?i)(?:api[_-]?key|secret|passwd|password|token|access[_-]?key)["']?\s*[:=]\s*["']([^"'\s]{16,})["']`,
+		MinEntropy: 3.5,
+	},
+}
+
+type compiledSecret struct {
+	name       string
+	re         *regexp.Regexp
+	minEntropy float64
+}
+
+// secretMatch is a secret found on an added line
+type secretMatch struct {
+	Name string
+	Line int
+}
+
+func compileSecrets(patterns []SecretPattern) ([]compiledSecret, error) {
+	compiled := make([]compiledSecret, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %s: %w", pattern.Name, err)
+		}
+		compiled = append(compiled, compiledSecret{name: pattern.Name, re: re, minEntropy: pattern.MinEntropy})
+	}
+	return compiled, nil
+}
+
+// scanSecrets checks the added lines of a change; only the first match of
+// each line is reported, and the secret itself never is
+func scanSecrets(change Change, secrets []compiledSecret) []secretMatch {
+	var matches []secretMatch
+	for _, line := range change.AddedLines {
+		if strings.Contains(line.Text, AllowSecretMarker) {
+			continue
+		}
+		for _, secret := range secrets {
+			match := secret.re.FindStringSubmatch(line.Text)
+			if match == nil {
+				continue
+			}
+			if secret.minEntropy > 0 && shannonEntropy(match[len(match)-1]) < secret.minEntropy {
+				continue
+			}
+			matches = append(matches, secretMatch{Name: secret.name, Line: line.Number})
+			break
+		}
+	}
+	return matches
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
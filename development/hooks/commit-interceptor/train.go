@@ -0,0 +1,132 @@
+// development/hooks/commit-interceptor/train.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"email_sender/development/hooks/commit-interceptor/training"
+)
+
+// runTrain implements "commit-interceptor train": it mines the git log,
+// reports the held-out precision and recall per commit type and saves the
+// model loaded by the interceptor at startup
+func runTrain(args []string, stdout, stderr io.Writer) int {
+	config := LoadConfig()
+
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := flags.String("repo", ".", "git repository to learn from")
+	maxCommits := flags.Int("max-commits", 5000, "number of recent commits read (0 = all)")
+	testRatio := flags.Float64("test-ratio", 0.2, "share of the commits held out for evaluation")
+	minExamples := flags.Int("min-examples", 5, "commit types with fewer examples are ignored")
+	alpha := flags.Float64("alpha", 1, "Laplace smoothing")
+	output := flags.String("output", config.Classifier.ModelPath, "model file")
+	format := flags.String("format", "text", "report format: text or json")
+	dryRun := flags.Bool("dry-run", false, "evaluate without saving the model")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	examples, skipped, err := training.MineHistory(*repo, *maxCommits)
+	if err != nil {
+		fmt.Fprintf(stderr, "commit-interceptor: %v\n", err)
+		return 1
+	}
+	result, err := training.Run(examples, training.Options{
+		TestRatio:   *testRatio,
+		MinExamples: *minExamples,
+		Alpha:       *alpha,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "commit-interceptor: training failed: %v (%d labelled commits, %d without a Conventional Commit header)\n",
+			err, len(examples), skipped)
+		return 1
+	}
+
+	if !*dryRun {
+		if err := result.Model.Save(*output); err != nil {
+			fmt.Fprintf(stderr, "commit-interceptor: %v\n", err)
+			return 1
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(map[string]interface{}{
+			"labelled":   len(examples),
+			"unlabelled": skipped,
+			"train":      result.Train,
+			"test":       result.Test,
+			"dropped":    result.Dropped,
+			"evaluation": result.Evaluation,
+			"model":      modelPath(*output, *dryRun),
+		})
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "Commits: %d labelled, %d without a Conventional Commit header\n", len(examples), skipped)
+	if len(result.Dropped) > 0 {
+		var labels []string
+		for label := range result.Dropped {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		fmt.Fprintf(stdout, "Ignored types (fewer than %d examples):", *minExamples)
+		for _, label := range labels {
+			fmt.Fprintf(stdout, " %s=%d", label, result.Dropped[label])
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, "Split: %d train, %d test\n\n", result.Train, result.Test)
+	if result.Evaluation != nil {
+		result.Evaluation.WriteReport(stdout)
+	} else {
+		fmt.Fprintln(stdout, "Not enough commits for a held-out evaluation")
+	}
+	if !*dryRun {
+		fmt.Fprintf(stdout, "\nModel trained on %d commits saved to %s\n", result.Model.Examples, *output)
+	}
+	return 0
+}
+
+func modelPath(path string, dryRun bool) string {
+	if dryRun {
+		return ""
+	}
+	return path
+}
+
+// loadTrainedModel loads the model written by "commit-interceptor train";
+// without one the classifier keeps its heuristics
+func loadTrainedModel(config *Config) *training.Model {
+	path := config.Classifier.ModelPath
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	model, err := training.LoadModel(path)
+	if err != nil {
+		log.Printf("Warning: ignoring commit classifier model: %v", err)
+		return nil
+	}
+	return model
+}
+
+// predictCommitType runs the trained model on a commit; a Conventional
+// Commits header is reduced to its description like the training examples
+func predictCommitType(model *training.Model, message string, files []string) training.Prediction {
+	text := message
+	if example, ok := training.NewExample("", message, files); ok {
+		text = example.Text
+	}
+	return model.Predict(text, files)
+}
@@ -0,0 +1,127 @@
+// development/hooks/commit-interceptor/training/dataset.go
+package training
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"email_sender/development/hooks/commit-interceptor/policy"
+)
+
+// Example is a labelled commit: the label comes from the Conventional
+// Commit type, the text is the rest of the message so that the model learns
+// from the words and files rather than from the prefix itself
+type Example struct {
+	Hash  string   `json:"hash"`
+	Label string   `json:"label"`
+	Text  string   `json:"text"`
+	Files []string `json:"files"`
+}
+
+// Field separators of the git log format used by MineHistory
+const (
+	recordSeparator = "\x1e"
+	fieldSeparator  = "\x1f"
+)
+
+// MineHistory reads up to maxCommits non-merge commits of the repository
+// and keeps those with a Conventional Commit header. The second value
+// counts the commits skipped because they have no usable label.
+func MineHistory(repoPath string, maxCommits int) ([]Example, int, error) {
+	args := []string{"-c", "core.quotePath=false", "log", "--no-merges", "--name-only",
+		"--format=" + recordSeparator + "%H" + fieldSeparator + "%B" + fieldSeparator}
+	if maxCommits > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", maxCommits))
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, 0, fmt.Errorf("git log failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var examples []Example
+	skipped := 0
+	for _, record := range strings.Split(stdout.String(), recordSeparator) {
+		fields := strings.SplitN(record, fieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+		example, ok := NewExample(strings.TrimSpace(fields[0]), fields[1], splitFiles(fields[2]))
+		if !ok {
+			skipped++
+			continue
+		}
+		examples = append(examples, example)
+	}
+	return examples, skipped, nil
+}
+
+// NewExample labels a commit message; ok is false when the header is not a
+// Conventional Commit
+func NewExample(hash, message string, files []string) (Example, bool) {
+	commit, err := policy.ParseConventional(strings.TrimSpace(message))
+	if err != nil {
+		return Example{}, false
+	}
+	text := commit.Description
+	if commit.Body != "" {
+		text += "\n" + commit.Body
+	}
+	return Example{Hash: hash, Label: commit.Type, Text: text, Files: files}, true
+}
+
+func splitFiles(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// stopWords carry no signal about the commit type
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "to": true, "of": true, "in": true,
+	"on": true, "with": true, "a": true, "an": true, "is": true, "it": true,
+	"be": true, "this": true, "that": true, "from": true, "by": true, "as": true,
+	"le": true, "la": true, "les": true, "de": true, "des": true, "du": true,
+	"et": true, "pour": true, "un": true, "une": true, "dans": true,
+}
+
+// Features turns a message and its files into the tokens the model counts:
+// lowercase words of the message, plus "file:" tokens for path segments
+// and "ext:" tokens for extensions
+func Features(text string, files []string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+
+	for _, file := range files {
+		file = strings.ToLower(file)
+		if dot := strings.LastIndex(file, "."); dot > strings.LastIndex(file, "/") {
+			tokens = append(tokens, "ext:"+file[dot:])
+		}
+		for _, segment := range strings.FieldsFunc(file, isSeparator) {
+			if len(segment) >= 2 {
+				tokens = append(tokens, "file:"+segment)
+			}
+		}
+	}
+	return tokens
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,212 @@
+// development/hooks/commit-interceptor/training/evaluate.go
+package training
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// LabelMetrics are the held-out metrics of one commit type
+type LabelMetrics struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"` // test examples with this label
+}
+
+// Evaluation is the result of a model on a test set
+type Evaluation struct {
+	Labels    []string                  `json:"labels"`
+	Examples  int                       `json:"examples"`
+	Accuracy  float64                   `json:"accuracy"`
+	MacroF1   float64                   `json:"macro_f1"`
+	PerLabel  map[string]LabelMetrics   `json:"per_label"`
+	Confusion map[string]map[string]int `json:"confusion"` // actual -> predicted -> count
+}
+
+// Options configure Run
+type Options struct {
+	TestRatio   float64 // share of the examples held out (default 0.2)
+	MinExamples int     // labels with fewer examples are dropped (default 5)
+	Alpha       float64 // Laplace smoothing (default 1)
+}
+
+// Result is the outcome of Run
+type Result struct {
+	Model      *Model
+	Train      int
+	Test       int
+	Dropped    map[string]int // examples of the labels below MinExamples
+	Evaluation *Evaluation
+}
+
+// Run drops the rare labels, evaluates a model trained on the training
+// split against the held-out split, then fits the final model on every
+// example
+func Run(examples []Example, options Options) (*Result, error) {
+	if options.TestRatio <= 0 || options.TestRatio >= 1 {
+		options.TestRatio = 0.2
+	}
+	if options.MinExamples <= 0 {
+		options.MinExamples = 5
+	}
+
+	kept, dropped := FilterRareLabels(examples, options.MinExamples)
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no commit type has at least %d examples", options.MinExamples)
+	}
+
+	result := &Result{Dropped: dropped}
+	train, test := Split(kept, options.TestRatio)
+	result.Train, result.Test = len(train), len(test)
+	if len(train) > 0 && len(test) > 0 {
+		heldOut, err := Train(train, options.Alpha)
+		if err != nil {
+			return nil, err
+		}
+		result.Evaluation = Evaluate(heldOut, test)
+	}
+
+	model, err := Train(kept, options.Alpha)
+	if err != nil {
+		return nil, err
+	}
+	model.Evaluation = result.Evaluation
+	result.Model = model
+	return result, nil
+}
+
+// FilterRareLabels keeps the examples whose label has at least min examples
+func FilterRareLabels(examples []Example, min int) ([]Example, map[string]int) {
+	counts := make(map[string]int)
+	for _, example := range examples {
+		counts[example.Label]++
+	}
+
+	var kept []Example
+	dropped := make(map[string]int)
+	for _, example := range examples {
+		if counts[example.Label] >= min {
+			kept = append(kept, example)
+		} else {
+			dropped[example.Label]++
+		}
+	}
+	return kept, dropped
+}
+
+// Split assigns each example to the test set from a hash of its commit, so
+// that retraining on a longer history keeps old commits on the same side
+func Split(examples []Example, testRatio float64) ([]Example, []Example) {
+	var train, test []Example
+	for i, example := range examples {
+		key := example.Hash
+		if key == "" {
+			key = fmt.Sprintf("%d:%s", i, example.Text)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		if float64(h.Sum32()%1000) < testRatio*1000 {
+			test = append(test, example)
+		} else {
+			train = append(train, example)
+		}
+	}
+	return train, test
+}
+
+// Evaluate predicts every test example and computes the confusion matrix
+// and the per-label precision and recall
+func Evaluate(model *Model, test []Example) *Evaluation {
+	evaluation := &Evaluation{
+		Examples:  len(test),
+		PerLabel:  make(map[string]LabelMetrics),
+		Confusion: make(map[string]map[string]int),
+	}
+
+	labels := make(map[string]bool)
+	for _, label := range model.Labels {
+		labels[label] = true
+	}
+	correct := 0
+	for _, example := range test {
+		predicted := model.Predict(example.Text, example.Files).Label
+		labels[example.Label] = true
+		if evaluation.Confusion[example.Label] == nil {
+			evaluation.Confusion[example.Label] = make(map[string]int)
+		}
+		evaluation.Confusion[example.Label][predicted]++
+		if predicted == example.Label {
+			correct++
+		}
+	}
+	for label := range labels {
+		evaluation.Labels = append(evaluation.Labels, label)
+	}
+	sort.Strings(evaluation.Labels)
+	if len(test) > 0 {
+		evaluation.Accuracy = float64(correct) / float64(len(test))
+	}
+
+	f1Sum, f1Count := 0.0, 0
+	for _, label := range evaluation.Labels {
+		truePositives := evaluation.Confusion[label][label]
+		predicted, actual := 0, 0
+		for _, other := range evaluation.Labels {
+			predicted += evaluation.Confusion[other][label]
+			actual += evaluation.Confusion[label][other]
+		}
+
+		metrics := LabelMetrics{Support: actual}
+		if predicted > 0 {
+			metrics.Precision = float64(truePositives) / float64(predicted)
+		}
+		if actual > 0 {
+			metrics.Recall = float64(truePositives) / float64(actual)
+		}
+		if metrics.Precision+metrics.Recall > 0 {
+			metrics.F1 = 2 * metrics.Precision * metrics.Recall / (metrics.Precision + metrics.Recall)
+		}
+		evaluation.PerLabel[label] = metrics
+
+		// Macro F1 over the labels present in the test set
+		if actual > 0 {
+			f1Sum += metrics.F1
+			f1Count++
+		}
+	}
+	if f1Count > 0 {
+		evaluation.MacroF1 = f1Sum / float64(f1Count)
+	}
+	return evaluation
+}
+
+// WriteReport prints the per-type metrics and the confusion matrix
+// (rows: actual type, columns: predicted type)
+func (e *Evaluation) WriteReport(w io.Writer) {
+	fmt.Fprintf(w, "Held-out examples: %d, accuracy %.3f, macro F1 %.3f\n\n", e.Examples, e.Accuracy, e.MacroF1)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "type\tprecision\trecall\tf1\tsupport\t")
+	for _, label := range e.Labels {
+		m := e.PerLabel[label]
+		fmt.Fprintf(table, "%s\t%.3f\t%.3f\t%.3f\t%d\t\n", label, m.Precision, m.Recall, m.F1, m.Support)
+	}
+	table.Flush()
+
+	fmt.Fprintln(w, "\nConfusion matrix (rows: actual, columns: predicted)")
+	table = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "\t"+strings.Join(e.Labels, "\t")+"\t")
+	for _, actual := range e.Labels {
+		row := []string{actual}
+		for _, predicted := range e.Labels {
+			row = append(row, fmt.Sprint(e.Confusion[actual][predicted]))
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t")+"\t")
+	}
+	table.Flush()
+}
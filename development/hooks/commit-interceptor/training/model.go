@@ -0,0 +1,147 @@
+// development/hooks/commit-interceptor/training/model.go
+package training
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ModelVersion is bumped when the model file format changes
+const ModelVersion = 1
+
+// Model is a multinomial naive Bayes classifier over Features, with
+// Laplace smoothing. It is small enough to be stored as JSON and loaded by
+// the interceptor at startup.
+type Model struct {
+	Version     int                       `json:"version"`
+	Labels      []string                  `json:"labels"`
+	DocCounts   map[string]int            `json:"doc_counts"`   // examples per label
+	TokenCounts map[string]map[string]int `json:"token_counts"` // label -> token -> count
+	TokenTotals map[string]int            `json:"token_totals"` // tokens per label
+	Vocabulary  int                       `json:"vocabulary"`
+	Alpha       float64                   `json:"alpha"`
+	Examples    int                       `json:"examples"`
+	TrainedAt   time.Time                 `json:"trained_at"`
+	// Evaluation is the held-out evaluation done before the final fit
+	Evaluation *Evaluation `json:"evaluation,omitempty"`
+}
+
+// Prediction is the most likely label with its posterior probability
+type Prediction struct {
+	Label      string             `json:"label"`
+	Confidence float64            `json:"confidence"`
+	Scores     map[string]float64 `json:"scores"` // posterior per label
+}
+
+// Train fits a model; alpha is the Laplace smoothing (1 when <= 0)
+func Train(examples []Example, alpha float64) (*Model, error) {
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("no training examples")
+	}
+	if alpha <= 0 {
+		alpha = 1
+	}
+
+	model := &Model{
+		Version:     ModelVersion,
+		DocCounts:   make(map[string]int),
+		TokenCounts: make(map[string]map[string]int),
+		TokenTotals: make(map[string]int),
+		Alpha:       alpha,
+		Examples:    len(examples),
+		TrainedAt:   time.Now().UTC(),
+	}
+	vocabulary := make(map[string]bool)
+	for _, example := range examples {
+		model.DocCounts[example.Label]++
+		counts := model.TokenCounts[example.Label]
+		if counts == nil {
+			counts = make(map[string]int)
+			model.TokenCounts[example.Label] = counts
+		}
+		for _, token := range Features(example.Text, example.Files) {
+			counts[token]++
+			model.TokenTotals[example.Label]++
+			vocabulary[token] = true
+		}
+	}
+
+	for label := range model.DocCounts {
+		model.Labels = append(model.Labels, label)
+	}
+	sort.Strings(model.Labels)
+	model.Vocabulary = len(vocabulary)
+	return model, nil
+}
+
+// Predict classifies a message (without its type prefix) and its files
+func (m *Model) Predict(text string, files []string) Prediction {
+	tokens := Features(text, files)
+	logScores := make(map[string]float64, len(m.Labels))
+	best := ""
+	for _, label := range m.Labels {
+		score := math.Log(float64(m.DocCounts[label]) / float64(m.Examples))
+		denominator := float64(m.TokenTotals[label]) + m.Alpha*float64(m.Vocabulary+1)
+		for _, token := range tokens {
+			score += math.Log((float64(m.TokenCounts[label][token]) + m.Alpha) / denominator)
+		}
+		logScores[label] = score
+		if best == "" || score > logScores[best] {
+			best = label
+		}
+	}
+
+	// Softmax of the log scores gives the posterior probabilities
+	scores := make(map[string]float64, len(logScores))
+	total := 0.0
+	for label, score := range logScores {
+		scores[label] = math.Exp(score - logScores[best])
+		total += scores[label]
+	}
+	for label := range scores {
+		scores[label] /= total
+	}
+	return Prediction{Label: best, Confidence: scores[best], Scores: scores}
+}
+
+// Save writes the model as JSON, atomically
+func (m *Model) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create model directory: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write model: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadModel reads a model file written by Save
+func LoadModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+	var model Model
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("failed to parse model %s: %w", path, err)
+	}
+	if model.Version != ModelVersion {
+		return nil, fmt.Errorf("model %s has version %d, expected %d", path, model.Version, ModelVersion)
+	}
+	if len(model.Labels) == 0 || model.Examples == 0 {
+		return nil, fmt.Errorf("model %s is empty", path)
+	}
+	return &model, nil
+}
@@ -0,0 +1,197 @@
+// development/hooks/commit-interceptor/training/training_test.go
+package training
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticHistory builds n examples per type with type-specific words and files
+func syntheticHistory(n int) []Example {
+	templates := map[string]struct {
+		text string
+		file string
+	}{
+		"feat": {"add new endpoint for user export", "api/export_%d.go"},
+		"fix":  {"handle nil pointer crash in parser", "parser/parse_%d.go"},
+		"docs": {"update installation guide and readme", "docs/guide_%d.md"},
+		"test": {"cover edge cases with table tests", "parser/parse_%d_test.go"},
+	}
+	var examples []Example
+	for label, template := range templates {
+		for i := 0; i < n; i++ {
+			examples = append(examples, Example{
+				Hash:  fmt.Sprintf("%s-%d", label, i),
+				Label: label,
+				Text:  fmt.Sprintf("%s %d", template.text, i),
+				Files: []string{fmt.Sprintf(template.file, i)},
+			})
+		}
+	}
+	return examples
+}
+
+func TestNewExampleAndFeatures(t *testing.T) {
+	example, ok := NewExample("abc", "feat(api)!: Add export\n\nStreams CSV rows", []string{"api/Export.go"})
+	require.True(t, ok)
+	assert.Equal(t, "feat", example.Label)
+	assert.Equal(t, "Add export\nStreams CSV rows", example.Text)
+
+	_, ok = NewExample("def", "Merge branch 'develop'", nil)
+	assert.False(t, ok)
+
+	assert.Equal(t,
+		[]string{"add", "export", "streams", "csv", "rows", "ext:.go", "file:api", "file:export", "file:go"},
+		Features(example.Text, example.Files))
+}
+
+func TestTrainAndPredict(t *testing.T) {
+	model, err := Train(syntheticHistory(10), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs", "feat", "fix", "test"}, model.Labels)
+
+	prediction := model.Predict("fix crash when the parser gets nil input", []string{"parser/parse.go"})
+	assert.Equal(t, "fix", prediction.Label)
+	assert.Greater(t, prediction.Confidence, 0.5)
+
+	total := 0.0
+	for _, score := range prediction.Scores {
+		total += score
+	}
+	assert.InDelta(t, 1.0, total, 1e-9)
+
+	assert.Equal(t, "docs", model.Predict("typo in readme", []string{"docs/readme.md"}).Label)
+
+	_, err = Train(nil, 1)
+	assert.Error(t, err)
+}
+
+func TestEvaluateMetrics(t *testing.T) {
+	// 3 feat (2 right), 2 fix (1 right): confusion computed by hand
+	evaluation := Evaluate(fixedModel(map[string]string{
+		"f1": "feat", "f2": "feat", "f3": "fix", "x1": "fix", "x2": "feat",
+	}), []Example{
+		{Label: "feat", Text: "f1"}, {Label: "feat", Text: "f2"}, {Label: "feat", Text: "f3"},
+		{Label: "fix", Text: "x1"}, {Label: "fix", Text: "x2"},
+	})
+
+	assert.Equal(t, 2, evaluation.Confusion["feat"]["feat"])
+	assert.Equal(t, 1, evaluation.Confusion["feat"]["fix"])
+	assert.Equal(t, 1, evaluation.Confusion["fix"]["feat"])
+	assert.InDelta(t, 0.6, evaluation.Accuracy, 1e-9)
+	assert.InDelta(t, 2.0/3.0, evaluation.PerLabel["feat"].Precision, 1e-9)
+	assert.InDelta(t, 2.0/3.0, evaluation.PerLabel["feat"].Recall, 1e-9)
+	assert.InDelta(t, 0.5, evaluation.PerLabel["fix"].Precision, 1e-9)
+	assert.InDelta(t, 0.5, evaluation.PerLabel["fix"].Recall, 1e-9)
+	assert.Equal(t, 3, evaluation.PerLabel["feat"].Support)
+
+	var report bytes.Buffer
+	evaluation.WriteReport(&report)
+	assert.Contains(t, report.String(), "accuracy 0.600")
+	assert.Contains(t, report.String(), "Confusion matrix")
+}
+
+// fixedModel predicts from a text -> label table through single-token classes
+func fixedModel(predictions map[string]string) *Model {
+	model := &Model{
+		Version:     ModelVersion,
+		DocCounts:   map[string]int{},
+		TokenCounts: map[string]map[string]int{},
+		TokenTotals: map[string]int{},
+		Alpha:       0.001,
+	}
+	for text, label := range predictions {
+		if model.TokenCounts[label] == nil {
+			model.TokenCounts[label] = map[string]int{}
+			model.Labels = append(model.Labels, label)
+		}
+		model.DocCounts[label] = 1
+		model.TokenCounts[label][text] += 100
+		model.TokenTotals[label] += 100
+		model.Examples++
+		model.Vocabulary++
+	}
+	return model
+}
+
+func TestRunFiltersSplitsAndSaves(t *testing.T) {
+	examples := append(syntheticHistory(20), Example{Hash: "rare", Label: "perf", Text: "speed up cache"})
+
+	result, err := Run(examples, Options{TestRatio: 0.25, MinExamples: 5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"perf": 1}, result.Dropped)
+	assert.Equal(t, 80, result.Train+result.Test)
+	assert.Greater(t, result.Test, 0)
+	require.NotNil(t, result.Evaluation)
+	assert.Greater(t, result.Evaluation.Accuracy, 0.9)
+	assert.Equal(t, 80, result.Model.Examples, "the final model is fitted on every kept example")
+
+	// The split only depends on the commit hashes
+	train, test := Split(examples, 0.25)
+	train2, test2 := Split(examples, 0.25)
+	assert.Equal(t, train, train2)
+	assert.Equal(t, test, test2)
+
+	path := filepath.Join(t.TempDir(), "models", "commit-classifier.json")
+	require.NoError(t, result.Model.Save(path))
+	loaded, err := LoadModel(path)
+	require.NoError(t, err)
+	assert.Equal(t, result.Model.Labels, loaded.Labels)
+	assert.Equal(t,
+		result.Model.Predict("handle nil crash", nil).Label,
+		loaded.Predict("handle nil crash", nil).Label)
+	require.NotNil(t, loaded.Evaluation)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0644))
+	_, err = LoadModel(path)
+	assert.Error(t, err)
+
+	_, err = Run(examples, Options{MinExamples: 100})
+	assert.Error(t, err)
+}
+
+func TestMineHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	commit := func(file, message string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, file), []byte(message), 0644))
+		git("add", file)
+		git("commit", "-q", "-m", message)
+	}
+
+	git("init", "-q")
+	commit("README.md", "docs: add readme\n\nWith a body")
+	commit("api/server.go", "feat(api): add server")
+	commit("notes.txt", "wip")
+
+	examples, skipped, err := MineHistory(repo, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, examples, 2)
+	assert.Equal(t, "feat", examples[0].Label)
+	assert.Equal(t, []string{"api/server.go"}, examples[0].Files)
+	assert.Equal(t, "add readme\nWith a body", examples[1].Text)
+	assert.Len(t, strings.TrimSpace(examples[1].Hash), 40)
+
+	examples, _, err = MineHistory(repo, 1)
+	require.NoError(t, err)
+	assert.Len(t, examples, 0, "only the last commit is read and it has no label")
+}
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"email_sender/development/managers/security-manager/auth"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -37,6 +38,7 @@ type APIGateway struct {
 	logger      *zap.Logger
 	rateLimiter *rate.Limiter
 	server      *http.Server
+	auth        *auth.Middleware
 }
 
 // NewAPIGateway crée une nouvelle instance de la gateway API
@@ -59,6 +61,38 @@ func (ag *APIGateway) RegisterManager(name string, manager ManagerInterface) {
 	ag.logger.Info("Manager registered", zap.String("name", name))
 }
 
+// SetAuthMiddleware définit le middleware d'authentification des endpoints
+func (ag *APIGateway) SetAuthMiddleware(middleware *auth.Middleware) {
+	ag.auth = middleware
+}
+
+// GatewayAuthPolicy retourne la politique d'accès par défaut de la gateway
+func GatewayAuthPolicy() *auth.Policy {
+	return &auth.Policy{
+		Roles: map[string][]string{
+			"viewer":   {"managers:read", "vectors:read", "config:read", "events:read", "monitoring:read"},
+			"operator": {"managers:*", "vectors:*", "config:read", "events:*", "monitoring:read"},
+			"admin":    {"*"},
+		},
+		Routes: []auth.Route{
+			{Method: "GET", Path: "/health", Public: true},
+			{Method: "GET", Path: "/ready", Public: true},
+			{Method: "GET", Path: "/docs/*", Public: true},
+			{Method: "GET", Path: "/api/v1/managers/*", Permission: "managers:read"},
+			{Method: "POST", Path: "/api/v1/managers/:name/action", Permission: "managers:execute"},
+			{Method: "POST", Path: "/api/v1/vectors/search", Permission: "vectors:read"},
+			{Method: "GET", Path: "/api/v1/vectors/*", Permission: "vectors:read"},
+			{Method: "POST", Path: "/api/v1/vectors/upsert", Permission: "vectors:write"},
+			{Method: "DELETE", Path: "/api/v1/vectors/:id", Permission: "vectors:delete"},
+			{Method: "GET", Path: "/api/v1/config/*", Permission: "config:read"},
+			{Method: "POST", Path: "/api/v1/config/:key", Permission: "config:write"},
+			{Method: "GET", Path: "/api/v1/events/*", Permission: "events:read"},
+			{Method: "POST", Path: "/api/v1/events/*", Permission: "events:publish"},
+			{Method: "GET", Path: "/api/v1/monitoring/*", Permission: "monitoring:read"},
+		},
+	}
+}
+
 // SetupRoutes configure tous les endpoints de l'API
 func (ag *APIGateway) SetupRoutes() {
 	// Middleware global
@@ -206,39 +240,16 @@ func (ag *APIGateway) loggingMiddleware() gin.HandlerFunc {
 	}
 }
 
-// Middleware d'authentification (basique pour le moment)
+// Middleware d'authentification : clés API et JWT via le Security Manager.
+// Sans middleware configuré, seuls les endpoints publics répondent.
 func (ag *APIGateway) authMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Pour l'instant, authentification simple par header
-		authHeader := c.GetHeader("Authorization")
-
-		// Skip auth pour les endpoints publics
-		if c.Request.URL.Path == "/health" ||
-			c.Request.URL.Path == "/ready" ||
-			c.Request.URL.Path == "/docs" {
-			c.Next()
-			return
-		}
-
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required",
-			})
-			c.Abort()
-			return
-		}
-
-		// Validation simple du token (à améliorer en production)
-		if authHeader != "Bearer valid-token" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization token",
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
+	if ag.auth != nil {
+		return ag.auth.Gin()
 	}
+
+	ag.logger.Warn("No authentication configured, only public endpoints are served")
+	public := auth.NewMiddleware(auth.Options{Policy: GatewayAuthPolicy()})
+	return public.Gin()
 }
 
 func main() {
@@ -247,6 +258,13 @@ func main() {
 
 	gateway := NewAPIGateway(logger)
 
+	// Authentification par clés API et/ou JWT (variables AUTH_*)
+	authMiddleware, err := auth.MiddlewareFromEnv(GatewayAuthPolicy())
+	if err != nil {
+		logger.Fatal("Failed to configure authentication", zap.Error(err))
+	}
+	gateway.SetAuthMiddleware(authMiddleware)
+
 	// Start the server
 	logger.Info("Starting API Gateway on :8080")
 	if err := gateway.Start(context.Background(), 8080); err != nil {
@@ -3,6 +3,7 @@ package development
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,7 @@ type BranchingManagerImpl struct {
 	storageManager   interfaces.StorageManager
 	errorManager     interfaces.ErrorManager
 	contextualMemory interfaces.ContextualMemoryManager
+	git              GitBackend
 
 	// Internal state
 	activeSessions    map[string]*interfaces.Session
@@ -32,6 +34,11 @@ type BranchingManagerImpl struct {
 	temporalSnapshots map[string][]*interfaces.TemporalSnapshot
 	quantumBranches   map[string]*interfaces.QuantumBranch
 
+	// Branching as code: on_event hooks and protected branches
+	codeHooks         map[interfaces.EventType][]map[string]interface{}
+	protectedBranches map[string]map[string]interface{}
+	codeMutex         sync.RWMutex
+
 	// AI/ML components for predictive branching
 	predictor BranchingPredictor
 	analyzer  PatternAnalyzer
@@ -76,9 +83,11 @@ type BranchingConfig struct {
 	PredictionConfidenceThreshold float64 `yaml:"prediction_confidence_threshold"`
 
 	// Level 7: Branching as Code
-	CodeExecutionEnabled  bool     `yaml:"code_execution_enabled"`
-	SupportedLanguages    []string `yaml:"supported_languages"`
-	CodeValidationEnabled bool     `yaml:"code_validation_enabled"`
+	CodeExecutionEnabled  bool                  `yaml:"code_execution_enabled"`
+	SupportedLanguages    []string              `yaml:"supported_languages"`
+	CodeValidationEnabled bool                  `yaml:"code_validation_enabled"`
+	CodeDryRun            bool                  `yaml:"code_dry_run"`
+	ScriptLimits          BranchingScriptLimits `yaml:"script_limits"`
 
 	// Level 8: Quantum
 	QuantumBranchingEnabled bool `yaml:"quantum_branching_enabled"`
@@ -92,6 +101,11 @@ type BranchingConfig struct {
 	// Monitoring
 	MetricsEnabled bool   `yaml:"metrics_enabled"`
 	LogLevel       string `yaml:"log_level"`
+
+	// Git repository managed by the branching manager
+	RepositoryPath    string `yaml:"repository_path"`
+	GitExecutable     string `yaml:"git_executable"`
+	DefaultBaseBranch string `yaml:"default_base_branch"`
 }
 
 // EventProcessor defines the interface for processing different event types
@@ -129,6 +143,9 @@ func NewBranchingManager(configPath string) (*BranchingManagerImpl, error) {
 		eventProcessors:   make(map[interfaces.EventType]EventProcessor),
 		temporalSnapshots: make(map[string][]*interfaces.TemporalSnapshot),
 		quantumBranches:   make(map[string]*interfaces.QuantumBranch),
+		codeHooks:         make(map[interfaces.EventType][]map[string]interface{}),
+		protectedBranches: make(map[string]map[string]interface{}),
+		git:               NewCLIGitBackend(config.RepositoryPath, config.GitExecutable),
 		logger:            logger,
 		stopChan:          make(chan struct{}),
 	}
@@ -148,6 +165,9 @@ func NewBranchingManagerImpl(config *BranchingConfig) *BranchingManagerImpl {
 		eventProcessors:   make(map[interfaces.EventType]EventProcessor),
 		temporalSnapshots: make(map[string][]*interfaces.TemporalSnapshot),
 		quantumBranches:   make(map[string]*interfaces.QuantumBranch),
+		codeHooks:         make(map[interfaces.EventType][]map[string]interface{}),
+		protectedBranches: make(map[string]map[string]interface{}),
+		git:               NewCLIGitBackend(config.RepositoryPath, config.GitExecutable),
 		logger:            log.New(os.Stdout, "[BranchingManager] ", log.LstdFlags),
 		stopChan:          make(chan struct{}),
 	}
@@ -177,10 +197,26 @@ func loadConfig(configPath string) (*BranchingConfig, error) {
 	if config.EventQueueSize == 0 {
 		config.EventQueueSize = 1000
 	}
+	if config.DefaultBaseBranch == "" {
+		config.DefaultBaseBranch = "main"
+	}
 
 	return &config, nil
 }
 
+// SetGitBackend replaces the git backend, e.g. to manage another repository
+func (bm *BranchingManagerImpl) SetGitBackend(backend GitBackend) {
+	bm.git = backend
+}
+
+// baseBranch returns the branch new branches start from
+func (bm *BranchingManagerImpl) baseBranch() string {
+	if bm.config.DefaultBaseBranch == "" {
+		return "main"
+	}
+	return bm.config.DefaultBaseBranch
+}
+
 // initializeEventProcessors sets up the event processing system
 func (bm *BranchingManagerImpl) initializeEventProcessors() {
 	bm.eventProcessors[interfaces.EventTypeSessionCreated] = &SessionEventProcessor{}
@@ -255,7 +291,7 @@ func (bm *BranchingManagerImpl) CreateSession(ctx context.Context, config interf
 	branchName := bm.generateBranchName(config.NamingPattern, session)
 
 	// Create Git branch
-	branchID, err := bm.createGitBranch(ctx, branchName, "main")
+	branchID, err := bm.createGitBranch(ctx, branchName, bm.baseBranch())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create git branch: %w", err)
 	}
@@ -348,12 +384,16 @@ func (bm *BranchingManagerImpl) generateBranchName(pattern string, session *inte
 	return branchName
 }
 
+// createGitBranch creates the branch in the repository without checking it
+// out. The branch name is used as branch ID so that snapshots and time travel
+// can resolve it in git.
 func (bm *BranchingManagerImpl) createGitBranch(ctx context.Context, branchName, baseBranch string) (string, error) {
-	// This would integrate with Git operations
-	// For now, return a mock branch ID
-	branchID := uuid.New().String()
-	bm.logger.Printf("Created Git branch %s from %s", branchName, baseBranch)
-	return branchID, nil
+	commitHash, err := bm.git.CreateBranch(ctx, branchName, baseBranch)
+	if err != nil {
+		return "", err
+	}
+	bm.logger.Printf("Created Git branch %s from %s at %s", branchName, baseBranch, commitHash)
+	return branchName, nil
 }
 
 func (bm *BranchingManagerImpl) storeSession(ctx context.Context, session *interfaces.Session) error {
@@ -396,7 +436,7 @@ func (bm *BranchingManagerImpl) TriggerBranchCreation(ctx context.Context, event
 	branchName := bm.generateEventBranchName(event)
 
 	// Create Git branch
-	branchID, err := bm.createGitBranch(ctx, branchName, "main")
+	branchID, err := bm.createGitBranch(ctx, branchName, bm.baseBranch())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event-triggered branch: %w", err)
 	}
@@ -562,7 +602,13 @@ func (bm *BranchingManagerImpl) processEventImmediately(ctx context.Context, eve
 		return fmt.Errorf("no processor found for event type %s", event.Type)
 	}
 
-	return processor.ProcessEvent(ctx, event)
+	if err := processor.ProcessEvent(ctx, event); err != nil {
+		return err
+	}
+
+	// Apply the on_event hooks declared by branching as code
+	bm.runHooks(ctx, event)
+	return nil
 }
 
 func (bm *BranchingManagerImpl) storeBranch(ctx context.Context, branch *interfaces.Branch) error {
@@ -595,7 +641,7 @@ func (bm *BranchingManagerImpl) CreateMultiDimBranch(ctx context.Context, dimens
 	branchName := bm.generateMultiDimBranchName(dimensions)
 
 	// Create Git branch
-	branchID, err := bm.createGitBranch(ctx, branchName, "main")
+	branchID, err := bm.createGitBranch(ctx, branchName, bm.baseBranch())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create multi-dimensional branch: %w", err)
 	}
@@ -937,8 +983,10 @@ func (bm *BranchingManagerImpl) CreateTemporalSnapshot(ctx context.Context, bran
 		return nil, fmt.Errorf("snapshot limit check failed: %w", err)
 	}
 
-	// Get current branch state
-	branchState, err := bm.getBranchCurrentState(ctx, branchID)
+	snapshotID := uuid.New().String()
+
+	// Get current branch state, including a stash of uncommitted changes
+	branchState, err := bm.getBranchCurrentState(ctx, branchID, snapshotID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branch state: %w", err)
 	}
@@ -951,7 +999,7 @@ func (bm *BranchingManagerImpl) CreateTemporalSnapshot(ctx context.Context, bran
 
 	now := time.Now()
 	snapshot := &interfaces.TemporalSnapshot{
-		ID:         uuid.New().String(),
+		ID:         snapshotID,
 		BranchID:   branchID,
 		Timestamp:  now,
 		CommitHash: commitHash,
@@ -963,6 +1011,9 @@ func (bm *BranchingManagerImpl) CreateTemporalSnapshot(ctx context.Context, bran
 		},
 		CreatedAt: now,
 	}
+	if stashRef, ok := branchState["stash_ref"].(string); ok {
+		snapshot.Metadata["stash_ref"] = stashRef
+	}
 
 	// Store snapshot
 	if bm.storageManager != nil {
@@ -1001,14 +1052,14 @@ func (bm *BranchingManagerImpl) TimeTravelToBranch(ctx context.Context, snapshot
 		return fmt.Errorf("invalid time travel request: %w", err)
 	}
 
-	// Create a new branch for the time travel
+	// Create a new branch for the time travel at the snapshot's commit
 	timeTravelBranchName := bm.generateTimeTravelBranchName(snapshot, targetTime)
 	timeTravelBranchID, err := bm.createGitBranch(ctx, timeTravelBranchName, snapshot.CommitHash)
 	if err != nil {
 		return fmt.Errorf("failed to create time travel branch: %w", err)
 	}
 
-	// Restore the state
+	// Check it out and restore the uncommitted changes
 	if err := bm.restoreBranchState(ctx, timeTravelBranchID, snapshot.State); err != nil {
 		return fmt.Errorf("failed to restore branch state: %w", err)
 	}
@@ -1196,22 +1247,57 @@ func (bm *BranchingManagerImpl) checkSnapshotLimit(ctx context.Context, branchID
 	return nil
 }
 
-func (bm *BranchingManagerImpl) getBranchCurrentState(ctx context.Context, branchID string) (map[string]interface{}, error) {
-	// This would capture the current state of the branch
+// getBranchCurrentState records the branch's commit count and, when the
+// branch is checked out, the worktree changes. Uncommitted changes of tracked
+// files are stashed under refs/branching/snapshots/<snapshotID> so that time
+// travel can restore them.
+func (bm *BranchingManagerImpl) getBranchCurrentState(ctx context.Context, branchID, snapshotID string) (map[string]interface{}, error) {
+	commitCount, err := bm.git.CommitCount(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	state := map[string]interface{}{
-		"files":            []string{"file1.go", "file2.yaml", "config.json"},
-		"commit_count":     42,
-		"last_modified":    time.Now(),
-		"working_tree":     "clean",
-		"staged_changes":   0,
-		"unstaged_changes": 0,
+		"commit_count":  commitCount,
+		"last_modified": time.Now(),
+		"working_tree":  "not_checked_out",
+	}
+
+	currentBranch, err := bm.git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentBranch != branchID {
+		return state, nil
+	}
+
+	status, err := bm.git.WorktreeStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state["files"] = append(append(append([]string{}, status.Staged...), status.Unstaged...), status.Untracked...)
+	state["staged_changes"] = len(status.Staged)
+	state["unstaged_changes"] = len(status.Unstaged)
+	state["untracked_files"] = len(status.Untracked)
+	state["working_tree"] = "clean"
+	if status.IsClean() {
+		return state, nil
+	}
+	state["working_tree"] = "dirty"
+
+	stashRef := "refs/branching/snapshots/" + snapshotID
+	stashCommit, err := bm.git.StashWorktree(ctx, stashRef, fmt.Sprintf("branching snapshot %s of %s", snapshotID, branchID))
+	if err != nil {
+		return nil, err
+	}
+	if stashCommit != "" {
+		state["stash_ref"] = stashRef
+		state["stash_commit"] = stashCommit
 	}
 	return state, nil
 }
 
 func (bm *BranchingManagerImpl) getCurrentCommitHash(ctx context.Context, branchID string) (string, error) {
-	// This would get the actual Git commit hash
-	return "abc123def456789", nil
+	return bm.git.CommitHash(ctx, branchID)
 }
 
 func (bm *BranchingManagerImpl) storeSnapshot(ctx context.Context, snapshot *interfaces.TemporalSnapshot) error {
@@ -1268,14 +1354,28 @@ func (bm *BranchingManagerImpl) validateTimeTravelRequest(snapshot *interfaces.T
 }
 
 func (bm *BranchingManagerImpl) generateTimeTravelBranchName(snapshot *interfaces.TemporalSnapshot, targetTime time.Time) string {
-	return fmt.Sprintf("timetravel-%s-%s",
-		snapshot.BranchID[:8],
+	return fmt.Sprintf("timetravel-%s-%s-%s",
+		snapshot.BranchID,
+		snapshot.ID[:8],
 		targetTime.Format("20060102-1504"))
 }
 
+// restoreBranchState checks out the branch and re-applies the changes that
+// were uncommitted when the snapshot was taken
 func (bm *BranchingManagerImpl) restoreBranchState(ctx context.Context, branchID string, state map[string]interface{}) error {
-	// This would restore the actual branch state from the snapshot
-	bm.logger.Printf("Restoring branch state for %s", branchID)
+	if err := bm.git.Checkout(ctx, branchID); err != nil {
+		return err
+	}
+
+	stashCommit, _ := state["stash_commit"].(string)
+	if stashCommit == "" {
+		bm.logger.Printf("Restored branch %s (no uncommitted changes in snapshot)", branchID)
+		return nil
+	}
+	if err := bm.git.ApplyStash(ctx, stashCommit); err != nil {
+		return err
+	}
+	bm.logger.Printf("Restored branch %s with stashed changes %s", branchID, stashCommit)
 	return nil
 }
 
@@ -1411,16 +1511,24 @@ func (bm *BranchingManagerImpl) ExecuteBranchingAsCode(ctx context.Context, conf
 		return nil, fmt.Errorf("branching as code execution is disabled")
 	}
 
-	// Validate configuration
-	if err := bm.validateBranchingAsCodeConfig(config); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
-
-	// Parse configuration based on language
-	parsedConfig, err := bm.parseBranchingAsCodeConfig(config)
+	// Validate and parse configuration; Go and Lua scripts are evaluated in
+	// their sandbox and only record the operations to apply
+	plan, err := bm.PlanBranchingAsCode(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse configuration: %w", err)
 	}
+	parsedConfig := plan.ParsedConfig()
+
+	if bm.config.CodeDryRun {
+		bm.logger.Printf("Dry run of branching as code config %s:\n%s", config.ID, plan)
+		return &interfaces.BranchingAsCodeResult{
+			ConfigID:     config.ID,
+			Language:     config.Language,
+			ExecutedAt:   time.Now(),
+			Status:       interfaces.ExecutionStatusSuccess,
+			ExecutionLog: append([]string{"DRY RUN: no operation applied"}, plan.Lines()...),
+		}, nil
+	}
 
 	// Validate parsed configuration
 	if bm.config.CodeValidationEnabled {
@@ -1487,12 +1595,12 @@ func (bm *BranchingManagerImpl) ValidateBranchingCode(ctx context.Context, confi
 			validation.Errors = append(validation.Errors, fmt.Sprintf("JSON validation error: %v", err))
 		}
 	case interfaces.LanguageGo:
-		if err := bm.validateGoConfig(config.Code); err != nil {
+		if err := bm.validateGoConfig(ctx, config); err != nil {
 			validation.IsValid = false
 			validation.Errors = append(validation.Errors, fmt.Sprintf("Go validation error: %v", err))
 		}
 	case interfaces.LanguageLua:
-		if err := bm.validateLuaConfig(config.Code); err != nil {
+		if err := bm.validateLuaConfig(ctx, config); err != nil {
 			validation.IsValid = false
 			validation.Errors = append(validation.Errors, fmt.Sprintf("Lua validation error: %v", err))
 		}
@@ -1792,31 +1900,19 @@ func (bm *BranchingManagerImpl) parseJSONConfig(code string) (*interfaces.Parsed
 }
 
 func (bm *BranchingManagerImpl) parseGoConfig(code string) (*interfaces.ParsedBranchingConfig, error) {
-	// This would use Go's parser to analyze the code
-	// For now, return a mock configuration
-	return &interfaces.ParsedBranchingConfig{
-		Operations: []interfaces.BranchingOperation{
-			{
-				Type:   interfaces.OpTypeCreate,
-				Name:   "go-parsed-branch",
-				Config: map[string]interface{}{"source": "go_code"},
-			},
-		},
-	}, nil
+	plan, err := bm.evaluateBranchingScript(context.Background(), interfaces.BranchingAsCodeConfig{Language: interfaces.LanguageGo, Code: code})
+	if err != nil {
+		return nil, fmt.Errorf("Go evaluation error: %w", err)
+	}
+	return plan.ParsedConfig(), nil
 }
 
 func (bm *BranchingManagerImpl) parseLuaConfig(code string) (*interfaces.ParsedBranchingConfig, error) {
-	// This would use a Lua interpreter to execute the code
-	// For now, return a mock configuration
-	return &interfaces.ParsedBranchingConfig{
-		Operations: []interfaces.BranchingOperation{
-			{
-				Type:   interfaces.OpTypeCreate,
-				Name:   "lua-parsed-branch",
-				Config: map[string]interface{}{"source": "lua_code"},
-			},
-		},
-	}, nil
+	plan, err := bm.evaluateBranchingScript(context.Background(), interfaces.BranchingAsCodeConfig{Language: interfaces.LanguageLua, Code: code})
+	if err != nil {
+		return nil, fmt.Errorf("Lua evaluation error: %w", err)
+	}
+	return plan.ParsedConfig(), nil
 }
 
 func (bm *BranchingManagerImpl) validateParsedConfig(config *interfaces.ParsedBranchingConfig) error {
@@ -1860,7 +1956,9 @@ func (bm *BranchingManagerImpl) executeParsedConfig(ctx context.Context, config
 func (bm *BranchingManagerImpl) executeOperation(ctx context.Context, op interfaces.BranchingOperation, result *interfaces.ExecutionResult) error {
 	switch op.Type {
 	case interfaces.OpTypeCreate:
-		branchID, err := bm.createGitBranch(ctx, op.Name, "main")
+		// Scripts use "base", YAML/JSON configurations "base_branch"
+		base := configString(op.Config, "base", configString(op.Config, "base_branch", bm.baseBranch()))
+		branchID, err := bm.createGitBranch(ctx, op.Name, base)
 		if err != nil {
 			return err
 		}
@@ -1871,12 +1969,54 @@ func (bm *BranchingManagerImpl) executeOperation(ctx context.Context, op interfa
 		result.ModifiedBranches = append(result.ModifiedBranches, op.Name)
 
 	case interfaces.OpTypeDelete:
-		// Delete branch
+		if err := bm.checkProtection(op.Name, "delete"); err != nil {
+			return err
+		}
+		force, _ := op.Config["force"].(bool)
+		if err := bm.git.DeleteBranch(ctx, op.Name, force); err != nil {
+			return err
+		}
 		result.DeletedBranches = append(result.DeletedBranches, op.Name)
 
 	case interfaces.OpTypeMerge:
-		// Merge operation
-		result.Log = append(result.Log, fmt.Sprintf("Merged %s", op.Name))
+		target := configString(op.Config, "target", bm.baseBranch())
+		if err := bm.checkProtection(target, "merge"); err != nil {
+			return err
+		}
+		commitHash, err := bm.git.Merge(ctx, op.Name, target, configString(op.Config, "message", ""))
+		if err != nil {
+			return err
+		}
+		result.ModifiedBranches = append(result.ModifiedBranches, target)
+		result.Log = append(result.Log, fmt.Sprintf("Merged %s into %s at %s", op.Name, target, commitHash))
+
+	case opTypeTag:
+		ref := configString(op.Config, "ref", "HEAD")
+		branch := strings.TrimPrefix(ref, "refs/heads/")
+		if ref == "HEAD" {
+			if current, err := bm.git.CurrentBranch(ctx); err == nil {
+				branch = current
+			}
+		}
+		if err := bm.checkProtection(branch, "tag"); err != nil {
+			return err
+		}
+		if err := bm.git.Tag(ctx, op.Name, ref, configString(op.Config, "message", "")); err != nil {
+			return err
+		}
+		result.Log = append(result.Log, fmt.Sprintf("Tagged %s as %s", ref, op.Name))
+
+	case opTypeProtect:
+		bm.codeMutex.Lock()
+		bm.protectedBranches[op.Name] = op.Config
+		bm.codeMutex.Unlock()
+		result.Log = append(result.Log, fmt.Sprintf("Protected %s", op.Name))
+
+	case opTypeOnEvent:
+		if err := bm.registerHook(op); err != nil {
+			return err
+		}
+		result.Log = append(result.Log, fmt.Sprintf("Registered hook on %s", op.Name))
 
 	default:
 		return fmt.Errorf("unsupported operation type: %s", op.Type)
@@ -1892,27 +2032,52 @@ func (bm *BranchingManagerImpl) validateYAMLConfig(code string) error {
 
 func (bm *BranchingManagerImpl) validateJSONConfig(code string) error {
 	var temp interface{}
-	return json.Unmarshal([]byte(code), &temp)
+	err := json.Unmarshal([]byte(code), &temp)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := 1 + strings.Count(code[:syntaxErr.Offset], "\n")
+		return &ScriptError{Line: line, Message: syntaxErr.Error()}
+	}
+	return err
 }
 
-func (bm *BranchingManagerImpl) validateGoConfig(code string) error {
-	// This would use Go's parser to validate syntax
-	// For now, just check if it's not empty
-	if len(strings.TrimSpace(code)) == 0 {
+// validateGoConfig evaluates the script: evaluation has no side effect and
+// reports syntax and runtime errors with their line
+func (bm *BranchingManagerImpl) validateGoConfig(ctx context.Context, config interfaces.BranchingAsCodeConfig) error {
+	if len(strings.TrimSpace(config.Code)) == 0 {
 		return fmt.Errorf("Go code cannot be empty")
 	}
-	return nil
+	_, err := bm.evaluateBranchingScript(ctx, config)
+	return err
 }
 
-func (bm *BranchingManagerImpl) validateLuaConfig(code string) error {
-	// This would use a Lua parser to validate syntax
-	// For now, just check if it's not empty
-	if len(strings.TrimSpace(code)) == 0 {
+func (bm *BranchingManagerImpl) validateLuaConfig(ctx context.Context, config interfaces.BranchingAsCodeConfig) error {
+	if len(strings.TrimSpace(config.Code)) == 0 {
 		return fmt.Errorf("Lua code cannot be empty")
 	}
+	_, err := bm.evaluateBranchingScript(ctx, config)
+	return err
+}
+
+// checkProtection refuses an operation on a branch protected by branching as
+// code unless its rule sets allow_<action> to true. Protection covers
+// deleting the branch ("delete"), merging into it ("merge") and tagging it
+// ("tag"), whether the operation comes from a script or an on_event hook.
+func (bm *BranchingManagerImpl) checkProtection(branchName, action string) error {
+	if rule, protected := bm.protectionRule(branchName); protected && rule["allow_"+action] != true {
+		return fmt.Errorf("branch %s is protected against %s", branchName, action)
+	}
 	return nil
 }
 
+// protectionRule returns the protection declared for a branch by branching as code
+func (bm *BranchingManagerImpl) protectionRule(branchName string) (map[string]interface{}, bool) {
+	bm.codeMutex.RLock()
+	defer bm.codeMutex.RUnlock()
+	rule, protected := bm.protectedBranches[branchName]
+	return rule, protected
+}
+
 func (bm *BranchingManagerImpl) performSemanticValidation(config interfaces.BranchingAsCodeConfig) []string {
 	var warnings []string
 
@@ -2034,7 +2199,7 @@ func (bm *BranchingManagerImpl) validateQuantumApproaches(approaches []interface
 func (bm *BranchingManagerImpl) createBranchApproach(ctx context.Context, quantumBranchID string, index int, config interfaces.BranchApproachConfig) (*interfaces.BranchApproach, error) {
 	// Create Git branch for this approach
 	branchName := fmt.Sprintf("%s-approach-%d-%s", quantumBranchID[:8], index, config.Name)
-	branchID, err := bm.createGitBranch(ctx, branchName, "main")
+	branchID, err := bm.createGitBranch(ctx, branchName, bm.baseBranch())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create branch for approach: %w", err)
 	}
@@ -2406,7 +2571,13 @@ func (bm *BranchingManagerImpl) processEvent(ctx context.Context, event interfac
 		return fmt.Errorf("no processor found for event type %s", event.Type)
 	}
 
-	return processor.ProcessEvent(ctx, event)
+	if err := processor.ProcessEvent(ctx, event); err != nil {
+		return err
+	}
+
+	// Apply the on_event hooks declared by branching as code
+	bm.runHooks(ctx, event)
+	return nil
 }
 
 // monitorSessions monitors active sessions for expiration
@@ -0,0 +1,425 @@
+package development
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"../interfaces"
+)
+
+// Operation types recorded by branching scripts in addition to the
+// create/modify/delete/merge types of the interfaces package
+const (
+	opTypeTag     = "tag"
+	opTypeProtect = "protect"
+	opTypeOnEvent = "on_event"
+)
+
+// BranchingScriptLimits bounds the evaluation of Go and Lua branching scripts
+type BranchingScriptLimits struct {
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxSteps      int           `yaml:"max_steps"`       // statements evaluated by the Go interpreter, instructions run by Lua
+	MaxOperations int           `yaml:"max_operations"`  // operations a script may record
+	MaxCallDepth  int           `yaml:"max_call_depth"`  // Lua call stack size
+	MaxStringSize int           `yaml:"max_string_size"` // bytes of a string built by a script
+	MaxTableSize  int           `yaml:"max_table_size"`  // entries of a Lua table or of a slice built by append
+}
+
+// DefaultBranchingScriptLimits returns the limits used when none are configured
+func DefaultBranchingScriptLimits() BranchingScriptLimits {
+	return BranchingScriptLimits{
+		Timeout:       2 * time.Second,
+		MaxSteps:      10000,
+		MaxOperations: 500,
+		MaxCallDepth:  64,
+		MaxStringSize: 64 * 1024,
+		MaxTableSize:  10000,
+	}
+}
+
+// withDefaults fills the zero values of limits
+func (l BranchingScriptLimits) withDefaults() BranchingScriptLimits {
+	defaults := DefaultBranchingScriptLimits()
+	if l.Timeout <= 0 {
+		l.Timeout = defaults.Timeout
+	}
+	if l.MaxSteps <= 0 {
+		l.MaxSteps = defaults.MaxSteps
+	}
+	if l.MaxOperations <= 0 {
+		l.MaxOperations = defaults.MaxOperations
+	}
+	if l.MaxCallDepth <= 0 {
+		l.MaxCallDepth = defaults.MaxCallDepth
+	}
+	if l.MaxStringSize <= 0 {
+		l.MaxStringSize = defaults.MaxStringSize
+	}
+	if l.MaxTableSize <= 0 {
+		l.MaxTableSize = defaults.MaxTableSize
+	}
+	return l
+}
+
+// ScriptError is an error located in a branching script
+type ScriptError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ScriptError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("line %d:%d: %s", e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+// errScriptTimeout is reported when a script exceeds its time limit
+var errScriptTimeout = errors.New("execution time limit exceeded")
+
+// PlannedOperation is an operation recorded by a script with its source line
+type PlannedOperation struct {
+	Line      int
+	Operation interfaces.BranchingOperation
+}
+
+// BranchingPlan lists the operations a branching-as-code configuration will
+// apply, in order
+type BranchingPlan struct {
+	ConfigID   string
+	Language   interfaces.CodeLanguage
+	Operations []PlannedOperation
+}
+
+// ParsedConfig returns the plan as a parsed configuration
+func (p *BranchingPlan) ParsedConfig() *interfaces.ParsedBranchingConfig {
+	operations := make([]interfaces.BranchingOperation, 0, len(p.Operations))
+	for _, planned := range p.Operations {
+		operations = append(operations, planned.Operation)
+	}
+	return &interfaces.ParsedBranchingConfig{Operations: operations}
+}
+
+// Lines describes each planned operation, one per line
+func (p *BranchingPlan) Lines() []string {
+	lines := make([]string, 0, len(p.Operations))
+	for _, planned := range p.Operations {
+		line := describeOperation(planned.Operation)
+		if planned.Line > 0 {
+			line = fmt.Sprintf("%s (line %d)", line, planned.Line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// String prints the plan
+func (p *BranchingPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan for %s (%s): %d operation(s)\n", p.ConfigID, p.Language, len(p.Operations))
+	for _, line := range p.Lines() {
+		b.WriteString("  ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// describeOperation renders an operation for plans and logs
+func describeOperation(op interfaces.BranchingOperation) string {
+	switch op.Type {
+	case interfaces.OpTypeCreate:
+		return fmt.Sprintf("+ create branch %s from %s", op.Name, configString(op.Config, "base", "HEAD"))
+	case interfaces.OpTypeMerge:
+		return fmt.Sprintf("~ merge %s into %s", op.Name, configString(op.Config, "target", "?"))
+	case interfaces.OpTypeDelete:
+		return fmt.Sprintf("- delete branch %s", op.Name)
+	case interfaces.OpTypeModify:
+		return fmt.Sprintf("~ modify branch %s", op.Name)
+	case opTypeTag:
+		return fmt.Sprintf("+ tag %s as %s", configString(op.Config, "ref", "HEAD"), op.Name)
+	case opTypeProtect:
+		return fmt.Sprintf("! protect branch %s%s", op.Name, formatOptions(op.Config))
+	case opTypeOnEvent:
+		return fmt.Sprintf("@ on %s: %s%s", op.Name, configString(op.Config, "action", "?"), formatOptions(op.Config))
+	default:
+		return fmt.Sprintf("? %s %s", op.Type, op.Name)
+	}
+}
+
+func formatOptions(config map[string]interface{}) string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		if key != "action" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, config[key]))
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+func configString(config map[string]interface{}, key, fallback string) string {
+	if value, ok := config[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// hookEventTypes maps the event names accepted by on_event to event types
+var hookEventTypes = map[string]interfaces.EventType{
+	"session_created": interfaces.EventTypeSessionCreated,
+	"session_ended":   interfaces.EventTypeSessionEnded,
+	"branch_created":  interfaces.EventTypeBranchCreated,
+	"branch_merged":   interfaces.EventTypeBranchMerged,
+	"commit":          interfaces.EventTypeCommitMade,
+	"push":            interfaces.EventTypePush,
+	"pull_request":    interfaces.EventTypePullRequest,
+	"timer":           interfaces.EventTypeTimer,
+}
+
+// branchingModule implements the "branching" module exposed to scripts. The
+// script runtimes convert their arguments to Go values (string, int64,
+// float64, bool, []interface{}, map[string]interface{}) and call call; the
+// module only records operations, nothing touches the repository until the
+// plan is executed.
+type branchingModule struct {
+	plan   *BranchingPlan
+	limits BranchingScriptLimits
+}
+
+func newBranchingModule(plan *BranchingPlan, limits BranchingScriptLimits) *branchingModule {
+	return &branchingModule{plan: plan, limits: limits}
+}
+
+// call records the operation of a module function called at line
+func (m *branchingModule) call(function string, line int, args []interface{}) error {
+	op, err := m.operation(function, args)
+	if err != nil {
+		return &ScriptError{Line: line, Message: fmt.Sprintf("branching.%s: %v", function, err)}
+	}
+	if len(m.plan.Operations) >= m.limits.MaxOperations {
+		return &ScriptError{Line: line, Message: fmt.Sprintf("too many operations (limit %d)", m.limits.MaxOperations)}
+	}
+	m.plan.Operations = append(m.plan.Operations, PlannedOperation{Line: line, Operation: op})
+	return nil
+}
+
+func (m *branchingModule) operation(function string, args []interface{}) (interfaces.BranchingOperation, error) {
+	switch function {
+	case "create":
+		// create(name, {base=..., description=...})
+		name, options, err := stringAndOptions(args, 1)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		return interfaces.BranchingOperation{Type: interfaces.OpTypeCreate, Name: name[0], Config: options}, nil
+
+	case "merge":
+		// merge(source, target, {message=...})
+		names, options, err := stringAndOptions(args, 2)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		options["target"] = names[1]
+		return interfaces.BranchingOperation{Type: interfaces.OpTypeMerge, Name: names[0], Config: options}, nil
+
+	case "delete":
+		// delete(name, {force=true})
+		name, options, err := stringAndOptions(args, 1)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		return interfaces.BranchingOperation{Type: interfaces.OpTypeDelete, Name: name[0], Config: options}, nil
+
+	case "tag":
+		// tag(ref, name, {message=...})
+		names, options, err := stringAndOptions(args, 2)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		options["ref"] = names[0]
+		return interfaces.BranchingOperation{Type: opTypeTag, Name: names[1], Config: options}, nil
+
+	case "protect":
+		// protect(branch, {allow_delete=true, allow_merge=true, allow_tag=true}):
+		// deleting, merging into and tagging the branch are refused unless allowed
+		name, options, err := stringAndOptions(args, 1)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		return interfaces.BranchingOperation{Type: opTypeProtect, Name: name[0], Config: options}, nil
+
+	case "on_event":
+		// on_event(event, {action="create", name="backup/{branch}", base="{branch}"})
+		name, options, err := stringAndOptions(args, 1)
+		if err != nil {
+			return interfaces.BranchingOperation{}, err
+		}
+		if _, known := hookEventTypes[name[0]]; !known {
+			return interfaces.BranchingOperation{}, fmt.Errorf("unknown event %q", name[0])
+		}
+		switch action := configString(options, "action", ""); action {
+		case "create", "merge", "tag", "delete":
+		default:
+			return interfaces.BranchingOperation{}, fmt.Errorf("hook action must be create, merge, tag or delete, got %q", action)
+		}
+		if configString(options, "name", "") == "" {
+			return interfaces.BranchingOperation{}, errors.New("hook needs a name")
+		}
+		return interfaces.BranchingOperation{Type: opTypeOnEvent, Name: name[0], Config: options}, nil
+
+	default:
+		return interfaces.BranchingOperation{}, fmt.Errorf("unknown function")
+	}
+}
+
+// stringAndOptions checks for count non-empty string arguments followed by
+// an optional options table
+func stringAndOptions(args []interface{}, count int) ([]string, map[string]interface{}, error) {
+	if len(args) < count || len(args) > count+1 {
+		return nil, nil, fmt.Errorf("expected %d argument(s) and an optional options table, got %d", count, len(args))
+	}
+
+	names := make([]string, count)
+	for i := 0; i < count; i++ {
+		name, ok := args[i].(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("argument %d must be a non-empty string", i+1)
+		}
+		names[i] = name
+	}
+
+	options := make(map[string]interface{})
+	if len(args) == count+1 && args[count] != nil {
+		table, ok := args[count].(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("argument %d must be an options table", count+1)
+		}
+		for key, value := range table {
+			options[key] = value
+		}
+	}
+	return names, options, nil
+}
+
+// evaluateBranchingScript runs a Go or Lua script and returns its plan
+func (bm *BranchingManagerImpl) evaluateBranchingScript(ctx context.Context, config interfaces.BranchingAsCodeConfig) (*BranchingPlan, error) {
+	limits := bm.config.ScriptLimits.withDefaults()
+	plan := &BranchingPlan{ConfigID: config.ID, Language: config.Language}
+	module := newBranchingModule(plan, limits)
+
+	ctx, cancel := context.WithTimeout(ctx, limits.Timeout)
+	defer cancel()
+
+	var err error
+	switch config.Language {
+	case interfaces.LanguageGo:
+		err = runGoScript(ctx, config.Code, module, limits)
+	case interfaces.LanguageLua:
+		err = runLuaScript(ctx, config.Code, module, limits)
+	default:
+		err = fmt.Errorf("language %s is not a scripting language", config.Language)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanBranchingAsCode evaluates a configuration without applying it (dry run)
+func (bm *BranchingManagerImpl) PlanBranchingAsCode(ctx context.Context, config interfaces.BranchingAsCodeConfig) (*BranchingPlan, error) {
+	if err := bm.validateBranchingAsCodeConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	switch config.Language {
+	case interfaces.LanguageGo, interfaces.LanguageLua:
+		return bm.evaluateBranchingScript(ctx, config)
+	}
+
+	parsed, err := bm.parseBranchingAsCodeConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	plan := &BranchingPlan{ConfigID: config.ID, Language: config.Language}
+	for _, op := range parsed.Operations {
+		plan.Operations = append(plan.Operations, PlannedOperation{Operation: op})
+	}
+	return plan, nil
+}
+
+// registerHook installs the action of an on_event operation
+func (bm *BranchingManagerImpl) registerHook(op interfaces.BranchingOperation) error {
+	eventType, known := hookEventTypes[op.Name]
+	if !known {
+		return fmt.Errorf("unknown event %q", op.Name)
+	}
+
+	bm.codeMutex.Lock()
+	defer bm.codeMutex.Unlock()
+	bm.codeHooks[eventType] = append(bm.codeHooks[eventType], op.Config)
+	return nil
+}
+
+// runHooks applies the on_event actions registered for the event. "{key}"
+// placeholders in the action are replaced by the event's context values.
+func (bm *BranchingManagerImpl) runHooks(ctx context.Context, event interfaces.BranchingEvent) {
+	bm.codeMutex.RLock()
+	hooks := append([]map[string]interface{}(nil), bm.codeHooks[event.Type]...)
+	bm.codeMutex.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	pairs := make([]string, 0, 2*len(event.Context))
+	for key, value := range event.Context {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	for _, hook := range hooks {
+		config := make(map[string]interface{}, len(hook))
+		for key, value := range hook {
+			if text, ok := value.(string); ok {
+				value = replacer.Replace(text)
+			}
+			config[key] = value
+		}
+
+		op := interfaces.BranchingOperation{Name: configString(config, "name", "")}
+		switch configString(config, "action", "") {
+		case "create":
+			op.Type = interfaces.OpTypeCreate
+		case "merge":
+			op.Type = interfaces.OpTypeMerge
+		case "delete":
+			op.Type = interfaces.OpTypeDelete
+		case "tag":
+			op.Type = opTypeTag
+		}
+		op.Config = config
+
+		result := &interfaces.ExecutionResult{}
+		if err := bm.executeOperation(ctx, op, result); err != nil {
+			bm.logger.Printf("Warning: branching hook failed on event %s: %v", event.ID, err)
+			continue
+		}
+		bm.logger.Printf("Branching hook on event %s: %s", event.ID, describeOperation(op))
+	}
+}
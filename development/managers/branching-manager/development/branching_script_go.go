@@ -0,0 +1,756 @@
+package development
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goScriptPrelude wraps scripts without a package clause so that a list of
+// statements parses as the body of func main
+const (
+	goScriptPrelude      = "package config\n\nfunc main() {\n"
+	goScriptPreludeLines = 3
+)
+
+// goLongFormatPattern matches the fmt verbs with a width or precision of more
+// than two digits or taken from an argument, which can build strings far
+// over MaxStringSize in a single call
+var goLongFormatPattern = regexp.MustCompile(`%[-+ #0]*(\d{3,}|\d*\.\d{3,}|\*|\d*\.\*)`)
+
+// goModuleFunctions maps the Go names of the branching module functions
+var goModuleFunctions = map[string]string{
+	"Create":  "create",
+	"Merge":   "merge",
+	"Delete":  "delete",
+	"Tag":     "tag",
+	"Protect": "protect",
+	"OnEvent": "on_event",
+}
+
+// runGoScript interprets a Go branching script. Nothing is compiled or
+// executed natively: the script is parsed with go/parser and a small
+// interpreter evaluates literals, variables, if, range loops and calls to
+// branching.*, fmt.Sprintf, len and append, so scripts have no access to the
+// file system, the network or the process.
+//
+// A script is either a list of statements or a Go file whose func main
+// holds them:
+//
+//	for _, env := range []string{"staging", "production"} {
+//		branching.Create("release/"+env, map[string]string{"base": "develop"})
+//	}
+func runGoScript(ctx context.Context, code string, module *branchingModule, limits BranchingScriptLimits) error {
+	source, lineOffset := code, 0
+	if !hasPackageClause(code) {
+		source = goScriptPrelude + code + "\n}\n"
+		lineOffset = goScriptPreludeLines
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "branching.go", source, parser.SkipObjectResolution)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) && len(list) > 0 {
+			scriptErr := &ScriptError{Line: list[0].Pos.Line - lineOffset, Column: list[0].Pos.Column, Message: list[0].Msg}
+			// Errors on the closing brace of the wrapper are at the end of the script
+			if lastLine := strings.Count(code, "\n") + 1; scriptErr.Line > lastLine {
+				scriptErr.Line, scriptErr.Column = lastLine, 0
+				scriptErr.Message = "unexpected end of script: " + scriptErr.Message
+			}
+			return scriptErr
+		}
+		return &ScriptError{Message: err.Error()}
+	}
+
+	interpreter := &goInterpreter{
+		ctx:        ctx,
+		fset:       fset,
+		module:     module,
+		limits:     limits,
+		lineOffset: lineOffset,
+		scopes:     []map[string]interface{}{{}},
+	}
+	return interpreter.runFile(file)
+}
+
+// hasPackageClause reports whether the first token of code is "package"
+func hasPackageClause(code string) bool {
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", fset.Base(), len(code)), []byte(code), nil, 0)
+	_, tok, _ := s.Scan()
+	return tok == token.PACKAGE
+}
+
+// goInterpreter evaluates the supported subset of Go
+type goInterpreter struct {
+	ctx        context.Context
+	fset       *token.FileSet
+	module     *branchingModule
+	steps      int
+	limits     BranchingScriptLimits
+	lineOffset int
+	scopes     []map[string]interface{}
+}
+
+// errorAt returns a ScriptError located at node
+func (in *goInterpreter) errorAt(node ast.Node, format string, args ...interface{}) error {
+	pos := in.fset.Position(node.Pos())
+	return &ScriptError{Line: pos.Line - in.lineOffset, Column: pos.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (in *goInterpreter) line(node ast.Node) int {
+	return in.fset.Position(node.Pos()).Line - in.lineOffset
+}
+
+func (in *goInterpreter) runFile(file *ast.File) error {
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path != "branching" && path != "fmt" {
+			return in.errorAt(spec, "import of %q is not allowed, only \"branching\" and \"fmt\"", path)
+		}
+	}
+
+	var main *ast.FuncDecl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.IMPORT:
+				continue
+			case token.TYPE:
+				return in.errorAt(d, "type declarations are not supported")
+			}
+			if err := in.step(d); err != nil {
+				return err
+			}
+			if err := in.declare(d); err != nil {
+				return err
+			}
+		case *ast.FuncDecl:
+			if d.Name.Name != "main" || d.Recv != nil || d.Type.Params.NumFields() > 0 || d.Type.Results.NumFields() > 0 {
+				return in.errorAt(d, "only func main() may be declared")
+			}
+			main = d
+		}
+	}
+
+	if main == nil {
+		return &ScriptError{Line: 1, Message: "missing func main"}
+	}
+	return in.block(main.Body.List)
+}
+
+// step counts an evaluated statement or loop iteration and enforces the
+// execution limits
+func (in *goInterpreter) step(node ast.Node) error {
+	in.steps++
+	if in.steps > in.limits.MaxSteps {
+		return in.errorAt(node, "step limit exceeded (%d statements)", in.limits.MaxSteps)
+	}
+	if in.ctx.Err() != nil {
+		return in.errorAt(node, "%v", errScriptTimeout)
+	}
+	return nil
+}
+
+func (in *goInterpreter) push() { in.scopes = append(in.scopes, map[string]interface{}{}) }
+func (in *goInterpreter) pop()  { in.scopes = in.scopes[:len(in.scopes)-1] }
+
+func (in *goInterpreter) lookup(name string) (interface{}, bool) {
+	for i := len(in.scopes) - 1; i >= 0; i-- {
+		if value, ok := in.scopes[i][name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func (in *goInterpreter) define(name string, value interface{}) {
+	if name != "_" {
+		in.scopes[len(in.scopes)-1][name] = value
+	}
+}
+
+func (in *goInterpreter) assign(ident *ast.Ident, value interface{}) error {
+	if ident.Name == "_" {
+		return nil
+	}
+	for i := len(in.scopes) - 1; i >= 0; i-- {
+		if _, ok := in.scopes[i][ident.Name]; ok {
+			in.scopes[i][ident.Name] = value
+			return nil
+		}
+	}
+	return in.errorAt(ident, "undefined: %s", ident.Name)
+}
+
+func (in *goInterpreter) block(list []ast.Stmt) error {
+	in.push()
+	defer in.pop()
+	for _, stmt := range list {
+		if err := in.stmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *goInterpreter) stmt(stmt ast.Stmt) error {
+	if err := in.step(stmt); err != nil {
+		return err
+	}
+
+	switch s := stmt.(type) {
+	case *ast.EmptyStmt:
+		return nil
+
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return in.errorAt(s, "expression statement is not a call")
+		}
+		_, err := in.call(call)
+		return err
+
+	case *ast.DeclStmt:
+		decl, ok := s.Decl.(*ast.GenDecl)
+		if !ok || (decl.Tok != token.VAR && decl.Tok != token.CONST) {
+			return in.errorAt(s, "only var and const declarations are supported")
+		}
+		return in.declare(decl)
+
+	case *ast.AssignStmt:
+		return in.assignStmt(s)
+
+	case *ast.BlockStmt:
+		return in.block(s.List)
+
+	case *ast.IfStmt:
+		in.push()
+		defer in.pop()
+		if s.Init != nil {
+			if err := in.stmt(s.Init); err != nil {
+				return err
+			}
+		}
+		cond, err := in.expr(s.Cond)
+		if err != nil {
+			return err
+		}
+		truth, ok := cond.(bool)
+		if !ok {
+			return in.errorAt(s.Cond, "condition is %T, not bool", cond)
+		}
+		if truth {
+			return in.block(s.Body.List)
+		}
+		if s.Else != nil {
+			return in.stmt(s.Else)
+		}
+		return nil
+
+	case *ast.RangeStmt:
+		return in.rangeStmt(s)
+
+	default:
+		return in.errorAt(s, "unsupported statement %T", s)
+	}
+}
+
+func (in *goInterpreter) declare(decl *ast.GenDecl) error {
+	for _, spec := range decl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		if len(valueSpec.Values) != len(valueSpec.Names) {
+			return in.errorAt(valueSpec, "each declared name needs a value")
+		}
+		for i, name := range valueSpec.Names {
+			value, err := in.expr(valueSpec.Values[i])
+			if err != nil {
+				return err
+			}
+			in.define(name.Name, value)
+		}
+	}
+	return nil
+}
+
+func (in *goInterpreter) assignStmt(s *ast.AssignStmt) error {
+	if len(s.Lhs) != len(s.Rhs) {
+		return in.errorAt(s, "assignment needs as many values as names")
+	}
+
+	values := make([]interface{}, len(s.Rhs))
+	for i, rhs := range s.Rhs {
+		value, err := in.expr(rhs)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	for i, lhs := range s.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			return in.errorAt(lhs, "only variables can be assigned")
+		}
+		switch s.Tok {
+		case token.DEFINE:
+			in.define(ident.Name, values[i])
+		case token.ASSIGN:
+			if err := in.assign(ident, values[i]); err != nil {
+				return err
+			}
+		case token.ADD_ASSIGN:
+			current, ok := in.lookup(ident.Name)
+			if !ok {
+				return in.errorAt(ident, "undefined: %s", ident.Name)
+			}
+			sum, err := in.binary(s, token.ADD, current, values[i])
+			if err != nil {
+				return err
+			}
+			if err := in.assign(ident, sum); err != nil {
+				return err
+			}
+		default:
+			return in.errorAt(s, "unsupported assignment %s", s.Tok)
+		}
+	}
+	return nil
+}
+
+func (in *goInterpreter) rangeStmt(s *ast.RangeStmt) error {
+	collection, err := in.expr(s.X)
+	if err != nil {
+		return err
+	}
+
+	// Iterations are produced one at a time so that ranging over a large
+	// integer is bounded by the step limit, not by memory
+	var (
+		count int64
+		at    func(i int64) (key, value interface{})
+	)
+	switch c := collection.(type) {
+	case []interface{}:
+		count = int64(len(c))
+		at = func(i int64) (interface{}, interface{}) { return i, c[i] }
+	case map[string]interface{}:
+		keys := make([]string, 0, len(c))
+		for key := range c {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // deterministic plans
+		count = int64(len(keys))
+		at = func(i int64) (interface{}, interface{}) { return keys[i], c[keys[i]] }
+	case int64:
+		count = c
+		at = func(i int64) (interface{}, interface{}) { return i, nil }
+	default:
+		return in.errorAt(s.X, "cannot range over %T", collection)
+	}
+
+	for i := int64(0); i < count; i++ {
+		if err := in.step(s); err != nil {
+			return err
+		}
+		key, value := at(i)
+		in.push()
+		for _, target := range []struct {
+			expr  ast.Expr
+			value interface{}
+		}{{s.Key, key}, {s.Value, value}} {
+			if target.expr == nil {
+				continue
+			}
+			ident, ok := target.expr.(*ast.Ident)
+			if !ok {
+				in.pop()
+				return in.errorAt(target.expr, "range variables must be identifiers")
+			}
+			if s.Tok == token.DEFINE {
+				in.define(ident.Name, target.value)
+			} else if err := in.assign(ident, target.value); err != nil {
+				in.pop()
+				return err
+			}
+		}
+		err := in.block(s.Body.List)
+		in.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *goInterpreter) expr(expr ast.Expr) (interface{}, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		case token.INT:
+			value, err := strconv.ParseInt(e.Value, 0, 64)
+			if err != nil {
+				return nil, in.errorAt(e, "invalid integer %s", e.Value)
+			}
+			return value, nil
+		case token.FLOAT:
+			value, err := strconv.ParseFloat(e.Value, 64)
+			if err != nil {
+				return nil, in.errorAt(e, "invalid number %s", e.Value)
+			}
+			return value, nil
+		default:
+			return nil, in.errorAt(e, "unsupported literal %s", e.Value)
+		}
+
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+		if value, ok := in.lookup(e.Name); ok {
+			return value, nil
+		}
+		return nil, in.errorAt(e, "undefined: %s", e.Name)
+
+	case *ast.ParenExpr:
+		return in.expr(e.X)
+
+	case *ast.UnaryExpr:
+		value, err := in.expr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case bool:
+			if e.Op == token.NOT {
+				return !v, nil
+			}
+		case int64:
+			if e.Op == token.SUB {
+				return -v, nil
+			}
+		case float64:
+			if e.Op == token.SUB {
+				return -v, nil
+			}
+		}
+		return nil, in.errorAt(e, "invalid operation %s%T", e.Op, value)
+
+	case *ast.BinaryExpr:
+		left, err := in.expr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		// Short-circuit && and ||
+		if leftBool, ok := left.(bool); ok && (e.Op == token.LAND || e.Op == token.LOR) {
+			if (e.Op == token.LAND && !leftBool) || (e.Op == token.LOR && leftBool) {
+				return leftBool, nil
+			}
+		}
+		right, err := in.expr(e.Y)
+		if err != nil {
+			return nil, err
+		}
+		return in.binary(e, e.Op, left, right)
+
+	case *ast.CompositeLit:
+		return in.composite(e)
+
+	case *ast.IndexExpr:
+		collection, err := in.expr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		index, err := in.expr(e.Index)
+		if err != nil {
+			return nil, err
+		}
+		switch c := collection.(type) {
+		case []interface{}:
+			i, ok := index.(int64)
+			if !ok || i < 0 || i >= int64(len(c)) {
+				return nil, in.errorAt(e.Index, "index %v out of range [0:%d]", index, len(c))
+			}
+			return c[i], nil
+		case map[string]interface{}:
+			key, ok := index.(string)
+			if !ok {
+				return nil, in.errorAt(e.Index, "map key must be a string")
+			}
+			return c[key], nil
+		}
+		return nil, in.errorAt(e, "cannot index %T", collection)
+
+	case *ast.CallExpr:
+		return in.call(e)
+
+	default:
+		return nil, in.errorAt(expr, "unsupported expression %T", expr)
+	}
+}
+
+func (in *goInterpreter) composite(lit *ast.CompositeLit) (interface{}, error) {
+	isMap := false
+	switch t := lit.Type.(type) {
+	case *ast.MapType:
+		isMap = true
+	case *ast.ArrayType:
+	case nil:
+		// Elided type in a nested literal: guess it from the elements
+		isMap = len(lit.Elts) > 0
+		for _, elt := range lit.Elts {
+			if _, ok := elt.(*ast.KeyValueExpr); !ok {
+				isMap = false
+			}
+		}
+	default:
+		return nil, in.errorAt(t, "only slice and map literals are supported")
+	}
+
+	if !isMap {
+		values := make([]interface{}, 0, len(lit.Elts))
+		for _, elt := range lit.Elts {
+			value, err := in.expr(elt)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	values := make(map[string]interface{}, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, in.errorAt(elt, "map literal element needs a key")
+		}
+		key, err := in.expr(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, in.errorAt(kv.Key, "map keys must be strings")
+		}
+		value, err := in.expr(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[keyString] = value
+	}
+	return values, nil
+}
+
+func (in *goInterpreter) call(call *ast.CallExpr) (interface{}, error) {
+	args := make([]interface{}, 0, len(call.Args))
+	for _, arg := range call.Args {
+		value, err := in.expr(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	if call.Ellipsis.IsValid() {
+		return nil, in.errorAt(call, "variadic calls are not supported")
+	}
+
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := fun.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		switch pkg.Name {
+		case "branching":
+			function, known := goModuleFunctions[fun.Sel.Name]
+			if !known {
+				return nil, in.errorAt(fun.Sel, "undefined: branching.%s", fun.Sel.Name)
+			}
+			return nil, in.module.call(function, in.line(call), args)
+		case "fmt":
+			if fun.Sel.Name == "Sprintf" && len(args) > 0 {
+				if format, ok := args[0].(string); ok {
+					if goLongFormatPattern.MatchString(format) {
+						return nil, in.errorAt(call, "invalid format (width or precision too long)")
+					}
+					return in.checkString(call, fmt.Sprintf(format, args[1:]...))
+				}
+			}
+		}
+
+	case *ast.Ident:
+		switch fun.Name {
+		case "len":
+			if len(args) == 1 {
+				switch v := args[0].(type) {
+				case string:
+					return int64(len(v)), nil
+				case []interface{}:
+					return int64(len(v)), nil
+				case map[string]interface{}:
+					return int64(len(v)), nil
+				}
+			}
+		case "append":
+			if len(args) > 0 {
+				if slice, ok := args[0].([]interface{}); ok {
+					if len(slice)+len(args)-1 > in.limits.MaxTableSize {
+						return nil, in.errorAt(call, "slice too large (limit %d entries)", in.limits.MaxTableSize)
+					}
+					return append(append([]interface{}(nil), slice...), args[1:]...), nil
+				}
+			}
+		}
+	}
+	return nil, in.errorAt(call, "unsupported call")
+}
+
+// checkString returns value unless it exceeds MaxStringSize
+func (in *goInterpreter) checkString(node ast.Node, value string) (interface{}, error) {
+	if len(value) > in.limits.MaxStringSize {
+		return nil, in.errorAt(node, "string too large (limit %d bytes)", in.limits.MaxStringSize)
+	}
+	return value, nil
+}
+
+// binary applies a binary operator to evaluated operands
+func (in *goInterpreter) binary(node ast.Node, op token.Token, left, right interface{}) (interface{}, error) {
+	// Integers are promoted to floats in mixed expressions
+	if l, ok := left.(int64); ok {
+		if _, ok := right.(float64); ok {
+			left = float64(l)
+		}
+	}
+	if r, ok := right.(int64); ok {
+		if _, ok := left.(float64); ok {
+			right = float64(r)
+		}
+	}
+
+	switch op {
+	case token.EQL, token.NEQ:
+		equal, ok := comparableEqual(left, right)
+		if !ok {
+			return nil, in.errorAt(node, "cannot compare %T and %T", left, right)
+		}
+		return equal == (op == token.EQL), nil
+	case token.LAND, token.LOR:
+		l, lok := left.(bool)
+		r, rok := right.(bool)
+		if !lok || !rok {
+			return nil, in.errorAt(node, "operator %s needs booleans", op)
+		}
+		if op == token.LAND {
+			return l && r, nil
+		}
+		return l || r, nil
+	}
+
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.ADD:
+			if len(l)+len(r) > in.limits.MaxStringSize {
+				return nil, in.errorAt(node, "string too large (limit %d bytes)", in.limits.MaxStringSize)
+			}
+			return l + r, nil
+		case token.LSS:
+			return l < r, nil
+		case token.LEQ:
+			return l <= r, nil
+		case token.GTR:
+			return l > r, nil
+		case token.GEQ:
+			return l >= r, nil
+		}
+	case int64:
+		r, ok := right.(int64)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.ADD:
+			return l + r, nil
+		case token.SUB:
+			return l - r, nil
+		case token.MUL:
+			return l * r, nil
+		case token.QUO, token.REM:
+			if r == 0 {
+				return nil, in.errorAt(node, "division by zero")
+			}
+			if op == token.QUO {
+				return l / r, nil
+			}
+			return l % r, nil
+		case token.LSS:
+			return l < r, nil
+		case token.LEQ:
+			return l <= r, nil
+		case token.GTR:
+			return l > r, nil
+		case token.GEQ:
+			return l >= r, nil
+		}
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.ADD:
+			return l + r, nil
+		case token.SUB:
+			return l - r, nil
+		case token.MUL:
+			return l * r, nil
+		case token.QUO:
+			return l / r, nil
+		case token.LSS:
+			return l < r, nil
+		case token.LEQ:
+			return l <= r, nil
+		case token.GTR:
+			return l > r, nil
+		case token.GEQ:
+			return l >= r, nil
+		}
+	}
+	return nil, in.errorAt(node, "invalid operation: %T %s %T", left, op, right)
+}
+
+// comparableEqual compares scalar values; slices and maps are not comparable
+func comparableEqual(left, right interface{}) (bool, bool) {
+	switch left.(type) {
+	case []interface{}, map[string]interface{}:
+		return false, false
+	}
+	switch right.(type) {
+	case []interface{}, map[string]interface{}:
+		return false, false
+	}
+	return left == right, true
+}
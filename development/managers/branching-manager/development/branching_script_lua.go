@@ -0,0 +1,295 @@
+package development
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// luaUnsafeGlobals are removed from the base library: they load code or
+// reach outside the sandbox
+var luaUnsafeGlobals = []string{"dofile", "loadfile", "load", "loadstring", "require", "module", "collectgarbage", "getfenv", "setfenv", "newproxy"}
+
+var (
+	// "<string>:12: message" (runtime errors, including those of the branching module)
+	luaRuntimeErrorPattern = regexp.MustCompile(`^<string>:(\d+):\s*(.*)`)
+	// "<string> line:12(column:5) near 'x':   syntax error"
+	luaSyntaxErrorPattern = regexp.MustCompile(`line:(\d+)\(column:(\d+)\)\s*(.*)`)
+	// Lua 5.1 accepts at most two digits of width and precision in formats
+	luaLongFormatPattern = regexp.MustCompile(`%[-+ #0]*(\d{3,}|\d*\.\d{3,})`)
+)
+
+// luaTableCheckInterval is the number of instructions between two checks of
+// the table sizes, which walk the tables
+const luaTableCheckInterval = 64
+
+// runLuaScript runs a Lua branching script in a fresh gopher-lua state with
+// only the base (minus the loaders), table, string and math libraries and a
+// global "branching" table. Instructions are counted against MaxSteps and the
+// strings and tables of the running function are kept under MaxStringSize and
+// MaxTableSize:
+//
+//	for _, env in ipairs({"staging", "production"}) do
+//	  branching.create("release/" .. env, {base = "develop"})
+//	end
+//	branching.on_event("push", {action = "create", name = "backup/{branch}", base = "{branch}"})
+func runLuaScript(ctx context.Context, code string, module *branchingModule, limits BranchingScriptLimits) error {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:        true,
+		CallStackSize:       limits.MaxCallDepth,
+		RegistrySize:        1024,
+		RegistryMaxSize:     64 * 1024,
+		IncludeGoStackTrace: false,
+	})
+	defer L.Close()
+
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		if err := L.CallByParam(lua.P{Fn: L.NewFunction(lib.open), NRet: 0, Protect: true}, lua.LString(lib.name)); err != nil {
+			return fmt.Errorf("failed to open Lua library %s: %w", lib.name, err)
+		}
+	}
+	for _, name := range luaUnsafeGlobals {
+		L.SetGlobal(name, lua.LNil)
+	}
+	limitLuaLibraries(L, limits)
+
+	functions := make(map[string]lua.LGFunction, len(goModuleFunctions))
+	for _, function := range goModuleFunctions {
+		function := function
+		functions[function] = func(L *lua.LState) int {
+			args := make([]interface{}, 0, L.GetTop())
+			for i := 1; i <= L.GetTop(); i++ {
+				value, err := luaToGo(L.Get(i), 0)
+				if err != nil {
+					L.ArgError(i, err.Error())
+				}
+				args = append(args, value)
+			}
+			if err := module.call(function, luaCallerLine(L), args); err != nil {
+				var scriptErr *ScriptError
+				if errors.As(err, &scriptErr) {
+					L.RaiseError("%s", scriptErr.Message)
+				}
+				L.RaiseError("%s", err.Error())
+			}
+			return 0
+		}
+	}
+	L.SetGlobal("branching", L.SetFuncs(L.NewTable(), functions))
+
+	L.SetContext(newLuaStepContext(ctx, L, limits))
+	chunk, err := L.LoadString(code)
+	if err != nil {
+		// Syntax errors at the end of the script carry no line
+		if strings.Contains(err.Error(), "at EOF") {
+			return &ScriptError{Line: strings.Count(code, "\n") + 1, Message: "unexpected end of script: syntax error"}
+		}
+		return luaScriptError(ctx, err)
+	}
+	L.Push(chunk)
+	if err := L.PCall(0, lua.MultRet, nil); err != nil {
+		return luaScriptError(ctx, err)
+	}
+	return nil
+}
+
+// limitLuaLibraries removes or wraps the library functions able to build a
+// value over the limits in a single call, before the step hook sees it
+func limitLuaLibraries(L *lua.LState, limits BranchingScriptLimits) {
+	if stringLib, ok := L.GetGlobal(lua.StringLibName).(*lua.LTable); ok {
+		// string.rep and string.gsub can allocate arbitrarily large strings
+		stringLib.RawSetString("rep", lua.LNil)
+		stringLib.RawSetString("gsub", lua.LNil)
+
+		if format, ok := stringLib.RawGetString("format").(*lua.LFunction); ok {
+			stringLib.RawSetString("format", L.NewFunction(func(L *lua.LState) int {
+				if luaLongFormatPattern.MatchString(L.CheckString(1)) {
+					L.RaiseError("invalid format (width or precision too long)")
+				}
+				return format.GFunction(L)
+			}))
+		}
+	}
+
+	if tableLib, ok := L.GetGlobal(lua.TabLibName).(*lua.LTable); ok {
+		if concat, ok := tableLib.RawGetString("concat").(*lua.LFunction); ok {
+			tableLib.RawSetString("concat", L.NewFunction(func(L *lua.LState) int {
+				table := L.CheckTable(1)
+				separator := len(L.OptString(2, ""))
+				size, last := 0, L.OptInt(4, table.Len())
+				for i := L.OptInt(3, 1); i <= last; i++ {
+					size += len(table.RawGetInt(i).String()) + separator
+					if size > limits.MaxStringSize {
+						L.RaiseError("string too large (limit %d bytes)", limits.MaxStringSize)
+					}
+				}
+				return concat.GFunction(L)
+			}))
+		}
+	}
+}
+
+// luaStepContext is the context of a Lua state. gopher-lua has no debug
+// hooks but polls Done before every instruction: Done counts the steps and
+// checks the registers of the running function, then returns a closed channel
+// once a limit is exceeded so that the state raises Err at that instruction.
+type luaStepContext struct {
+	context.Context
+	L      *lua.LState
+	limits BranchingScriptLimits
+	steps  int
+	err    error
+	halted chan struct{}
+}
+
+func newLuaStepContext(ctx context.Context, L *lua.LState, limits BranchingScriptLimits) *luaStepContext {
+	halted := make(chan struct{})
+	close(halted)
+	return &luaStepContext{Context: ctx, L: L, limits: limits, halted: halted}
+}
+
+func (c *luaStepContext) Done() <-chan struct{} {
+	if c.err == nil {
+		c.err = c.step()
+	}
+	if c.err != nil {
+		return c.halted
+	}
+	return c.Context.Done()
+}
+
+func (c *luaStepContext) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Context.Err()
+}
+
+// step counts an instruction and checks the values held in the registers
+func (c *luaStepContext) step() error {
+	c.steps++
+	if c.steps > c.limits.MaxSteps {
+		return fmt.Errorf("step limit exceeded (%d instructions)", c.limits.MaxSteps)
+	}
+
+	checkTables := c.steps%luaTableCheckInterval == 0
+	for i := 1; i <= c.L.GetTop(); i++ {
+		switch value := c.L.Get(i).(type) {
+		case lua.LString:
+			if len(value) > c.limits.MaxStringSize {
+				return fmt.Errorf("string too large (limit %d bytes)", c.limits.MaxStringSize)
+			}
+		case *lua.LTable:
+			if checkTables && luaTableSize(value) > c.limits.MaxTableSize {
+				return fmt.Errorf("table too large (limit %d entries)", c.limits.MaxTableSize)
+			}
+		}
+	}
+	return nil
+}
+
+// luaTableSize counts the entries of table
+func luaTableSize(table *lua.LTable) int {
+	size := 0
+	table.ForEach(func(_, _ lua.LValue) {
+		size++
+	})
+	return size
+}
+
+// luaCallerLine returns the line of the Lua code calling the current Go function
+func luaCallerLine(L *lua.LState) int {
+	if match := luaRuntimeErrorPattern.FindStringSubmatch(L.Where(1) + " "); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
+}
+
+// luaScriptError converts a gopher-lua error into a ScriptError
+func luaScriptError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return &ScriptError{Message: errScriptTimeout.Error()}
+	}
+
+	message := err.Error()
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) && apiErr.Object != nil {
+		message = apiErr.Object.String()
+	}
+
+	if match := luaRuntimeErrorPattern.FindStringSubmatch(message); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return &ScriptError{Line: line, Message: match[2]}
+	}
+	if match := luaSyntaxErrorPattern.FindStringSubmatch(message); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		return &ScriptError{Line: line, Column: column, Message: match[3]}
+	}
+	return &ScriptError{Message: message}
+}
+
+// luaToGo converts a Lua argument to the values understood by the branching
+// module. Tables with a sequence part become lists, others become maps.
+func luaToGo(value lua.LValue, depth int) (interface{}, error) {
+	if depth > 16 {
+		return nil, errors.New("table nesting is too deep")
+	}
+
+	switch v := value.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LBool:
+		return bool(v), nil
+	case lua.LString:
+		return string(v), nil
+	case lua.LNumber:
+		if f := float64(v); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int64(f), nil
+		}
+		return float64(v), nil
+	case *lua.LTable:
+		if length := v.Len(); length > 0 {
+			list := make([]interface{}, 0, length)
+			for i := 1; i <= length; i++ {
+				item, err := luaToGo(v.RawGetInt(i), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			return list, nil
+		}
+		table := make(map[string]interface{})
+		var convErr error
+		v.ForEach(func(key, item lua.LValue) {
+			if convErr != nil {
+				return
+			}
+			name, ok := key.(lua.LString)
+			if !ok {
+				convErr = fmt.Errorf("table keys must be strings, got %s", key.Type())
+				return
+			}
+			table[string(name)], convErr = luaToGo(item, depth+1)
+		})
+		return table, convErr
+	default:
+		return nil, fmt.Errorf("unsupported value of type %s (hooks are declarative tables, not functions)", value.Type())
+	}
+}
@@ -0,0 +1,248 @@
+package development
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"../interfaces"
+)
+
+// newCodeTestManager returns a manager running branching as code against a throwaway repository
+func newCodeTestManager(t *testing.T, dir string) *BranchingManagerImpl {
+	t.Helper()
+	bm := NewBranchingManagerImpl(&BranchingConfig{
+		RepositoryPath:        dir,
+		DefaultBaseBranch:     "main",
+		EventQueueSize:        10,
+		CodeExecutionEnabled:  true,
+		CodeValidationEnabled: true,
+	})
+	bm.storageManager = NewMockStorageManager()
+	return bm
+}
+
+func planScript(t *testing.T, bm *BranchingManagerImpl, language interfaces.CodeLanguage, code string) (*BranchingPlan, error) {
+	t.Helper()
+	return bm.PlanBranchingAsCode(context.Background(), interfaces.BranchingAsCodeConfig{
+		ID:       "plan-test",
+		Name:     "Plan test",
+		Language: language,
+		Code:     code,
+	})
+}
+
+func TestPlanBranchingAsCode_Go(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+
+	plan, err := planScript(t, bm, interfaces.LanguageGo, `base := "develop"
+for i, env := range []string{"staging", "production"} {
+	if env == "production" {
+		branching.Protect("release/"+env, map[string]interface{}{"allow_delete": false})
+	}
+	branching.Create(fmt.Sprintf("release/%s-%d", env, i+1), map[string]string{"base": base})
+}
+branching.OnEvent("push", map[string]string{"action": "create", "name": "backup/{branch}", "base": "{branch}"})
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"+ create branch release/staging-1 from develop (line 6)",
+		"! protect branch release/production [allow_delete=false] (line 4)",
+		"+ create branch release/production-2 from develop (line 6)",
+		"@ on push: create [base={branch}, name=backup/{branch}] (line 8)",
+	}, plan.Lines())
+
+	// A complete Go file keeps its own line numbers
+	plan, err = planScript(t, bm, interfaces.LanguageGo, "package main\n\nimport \"branching\"\n\nfunc main() {\n\tbranching.Merge(\"feature/a\", \"main\")\n}\n")
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 1)
+	assert.Equal(t, 6, plan.Operations[0].Line)
+}
+
+func TestPlanBranchingAsCode_GoErrors(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+	bm.config.ScriptLimits = BranchingScriptLimits{MaxSteps: 1000}
+
+	tests := map[string]string{
+		"branching.Create(\"a\")\nbranching.Create(\n":                      "line 3: unexpected end of script",
+		"x := 1\nbranching.Nope(\"a\")":                                     "line 2:11: undefined: branching.Nope",
+		"branching.Create(\"a\")\n\nbranching.Merge(\"a\")":                 "line 3: branching.merge: expected 2 argument(s)",
+		"package main\nimport \"os\"\nfunc main() {}":                       "line 2:8: import of \"os\" is not allowed",
+		"for i := range 100000 {\n\tx := i\n}":                              "step limit exceeded",
+		"branching.OnEvent(\"deploy\", map[string]string{\"name\": \"x\"})": "line 1: branching.on_event: unknown event \"deploy\"",
+		"for i := 0; i < 10; i++ {}":                                        "line 1:1: unsupported statement",
+	}
+	for code, want := range tests {
+		_, err := planScript(t, bm, interfaces.LanguageGo, code)
+		if assert.Error(t, err, code) {
+			assert.Contains(t, err.Error(), want, code)
+		}
+	}
+}
+
+func TestPlanBranchingAsCode_Lua(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+
+	plan, err := planScript(t, bm, interfaces.LanguageLua, `local base = "develop"
+for i, env in ipairs({"staging", "production"}) do
+  branching.create("release/" .. env, {base = base})
+end
+branching.tag("main", "v1.0.0", {message = "first release"})
+branching.on_event("push", {action = "create", name = "backup/{branch}", base = "{branch}"})
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"+ create branch release/staging from develop (line 3)",
+		"+ create branch release/production from develop (line 3)",
+		"+ tag main as v1.0.0 (line 5)",
+		"@ on push: create [base={branch}, name=backup/{branch}] (line 6)",
+	}, plan.Lines())
+}
+
+func TestPlanBranchingAsCode_LuaSandbox(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+	bm.config.ScriptLimits = BranchingScriptLimits{Timeout: 200 * time.Millisecond, MaxSteps: 1 << 30}
+
+	tests := map[string]string{
+		"branching.create('a')\nbranching.create(":           "line 2",
+		"branching.create('a')\nbranching.merge('a')":        "line 2: branching.merge: expected 2 argument(s)",
+		"os.execute('rm -rf /')":                             "line 1:",
+		"require('io')":                                      "line 1:",
+		"branching.on_event('push', function() end)":         "hooks are declarative tables",
+		"while true do end":                                  "execution time limit exceeded",
+		"branching.create('a', {base = string.rep('x', 9)})": "line 1:",
+	}
+	for code, want := range tests {
+		_, err := planScript(t, bm, interfaces.LanguageLua, code)
+		if assert.Error(t, err, code) {
+			assert.Contains(t, err.Error(), want, code)
+		}
+	}
+}
+
+func TestPlanBranchingAsCode_LuaLimits(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+	bm.config.ScriptLimits = BranchingScriptLimits{MaxSteps: 100000, MaxStringSize: 1024, MaxTableSize: 100}
+
+	tests := map[string]string{
+		"local n = 0\nfor i = 1, 1000000 do n = n + i end":                                                      "line 2: step limit exceeded (100000 instructions)",
+		"local s = 'x'\nfor i = 1, 20 do s = s .. s end":                                                        "line 2: string too large (limit 1024 bytes)",
+		"local t = {}\nfor i = 1, 1000 do t['k' .. i] = i end":                                                  "line 2: table too large (limit 100 entries)",
+		"local t = {}\nfor i = 1, 50 do t[i] = string.format('%30s', i) end\nbranching.create(table.concat(t))": "line 3: string too large",
+		"branching.create(string.format('%0999d', 1))":                                                          "line 1: invalid format (width or precision too long)",
+		"branching.create(string.gsub('a', 'a', 'b'))":                                                          "line 1:",
+	}
+	for code, want := range tests {
+		_, err := planScript(t, bm, interfaces.LanguageLua, code)
+		if assert.Error(t, err, code) {
+			assert.Contains(t, err.Error(), want, code)
+		}
+	}
+}
+
+func TestPlanBranchingAsCode_GoLimits(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+	bm.config.ScriptLimits = BranchingScriptLimits{MaxSteps: 10000, MaxStringSize: 1024, MaxTableSize: 100}
+
+	tests := map[string]string{
+		"for i := range 300000000 {\n}":                                                     "line 1:1: step limit exceeded (10000 statements)",
+		"s := \"x\"\nfor i := range 20 {\n\ts = s + s\n}":                                   "line 3:6: string too large (limit 1024 bytes)",
+		"s := \"x\"\nfor i := range 20 {\n\ts += s\n}":                                      "line 3:2: string too large (limit 1024 bytes)",
+		"s := fmt.Sprintf(\"%0999d\", 1)":                                                   "line 1:6: invalid format (width or precision too long)",
+		"s := fmt.Sprintf(\"%*d\", 100000, 1)":                                              "line 1:6: invalid format (width or precision too long)",
+		"s := \"x\"\nfor i := range 9 {\n\ts += s\n}\ns = fmt.Sprintf(\"%s%s%s\", s, s, s)": "line 5:5: string too large (limit 1024 bytes)",
+		"l := []string{}\nfor i := range 1000 {\n\tl = append(l, i)\n}":                     "line 3:6: slice too large (limit 100 entries)",
+	}
+	for code, want := range tests {
+		_, err := planScript(t, bm, interfaces.LanguageGo, code)
+		if assert.Error(t, err, code) {
+			assert.Contains(t, err.Error(), want, code)
+		}
+	}
+}
+
+func TestValidateBranchingCode_LineNumbers(t *testing.T) {
+	bm := newCodeTestManager(t, t.TempDir())
+
+	validation, err := bm.ValidateBranchingCode(context.Background(), interfaces.BranchingAsCodeConfig{
+		ID:       "invalid-go",
+		Name:     "Invalid Go",
+		Language: interfaces.LanguageGo,
+		Code:     "branching.Create(\"ok\")\nbranching.Create(42)\n",
+	})
+	require.NoError(t, err)
+	assert.False(t, validation.IsValid)
+	require.Len(t, validation.Errors, 1)
+	assert.Equal(t, "Go validation error: line 2: branching.create: argument 1 must be a non-empty string", validation.Errors[0])
+
+	validation, err = bm.ValidateBranchingCode(context.Background(), interfaces.BranchingAsCodeConfig{
+		ID:       "invalid-json",
+		Name:     "Invalid JSON",
+		Language: interfaces.LanguageJSON,
+		Code:     "{\n  \"operations\": [\n    {\"type\": \"create\",}\n  ]\n}",
+	})
+	require.NoError(t, err)
+	require.Len(t, validation.Errors, 1)
+	assert.True(t, strings.HasPrefix(validation.Errors[0], "JSON validation error: line 3: "), validation.Errors[0])
+}
+
+func TestExecuteBranchingAsCode_AppliesPlan(t *testing.T) {
+	dir := initTestRepo(t)
+	bm := newCodeTestManager(t, dir)
+	ctx := context.Background()
+
+	config := interfaces.BranchingAsCodeConfig{
+		ID:       "release-flow",
+		Name:     "Release flow",
+		Language: interfaces.LanguageGo,
+		Code: `branching.Create("develop")
+branching.Create("feature/login", map[string]string{"base": "develop"})
+branching.Tag("main", "v1.0.0")
+branching.Protect("develop")
+branching.OnEvent("push", map[string]string{"action": "create", "name": "backup/{branch}", "base": "{branch}"})
+`,
+	}
+
+	// Dry run: the plan is reported, the repository is untouched
+	bm.config.CodeDryRun = true
+	result, err := bm.ExecuteBranchingAsCode(ctx, config)
+	require.NoError(t, err)
+	assert.Empty(t, result.CreatedBranches)
+	assert.Contains(t, result.ExecutionLog, "+ create branch feature/login from develop (line 2)")
+	assert.Equal(t, "main", gitCmd(t, dir, "branch", "--list", "--format=%(refname:short)"))
+
+	bm.config.CodeDryRun = false
+	result, err = bm.ExecuteBranchingAsCode(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"develop", "feature/login"}, result.CreatedBranches)
+	assert.Equal(t, gitCmd(t, dir, "rev-parse", "main"), gitCmd(t, dir, "rev-parse", "v1.0.0^{commit}"))
+
+	// Protected branches cannot be deleted by branching as code
+	err = bm.executeOperation(ctx, interfaces.BranchingOperation{Type: interfaces.OpTypeDelete, Name: "develop"}, &interfaces.ExecutionResult{})
+	assert.ErrorContains(t, err, "protected")
+
+	// The push hook creates a backup branch
+	bm.runHooks(ctx, interfaces.BranchingEvent{ID: "push-1", Type: interfaces.EventTypePush, Context: map[string]interface{}{"branch": "feature/login"}})
+	assert.Equal(t, gitCmd(t, dir, "rev-parse", "feature/login"), gitCmd(t, dir, "rev-parse", "backup/feature/login"))
+
+	// Nor merged into or tagged, including by a hook targeting the event's branch
+	gitCmd(t, dir, "checkout", "-q", "feature/login")
+	writeTestFile(t, dir, "login.txt", "login\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "login")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	develop := gitCmd(t, dir, "rev-parse", "develop")
+
+	err = bm.executeOperation(ctx, interfaces.BranchingOperation{Type: interfaces.OpTypeMerge, Name: "feature/login", Config: map[string]interface{}{"target": "develop"}}, &interfaces.ExecutionResult{})
+	assert.ErrorContains(t, err, "protected")
+	err = bm.executeOperation(ctx, interfaces.BranchingOperation{Type: opTypeTag, Name: "v0.9.0", Config: map[string]interface{}{"ref": "develop"}}, &interfaces.ExecutionResult{})
+	assert.ErrorContains(t, err, "protected")
+
+	require.NoError(t, bm.registerHook(interfaces.BranchingOperation{Type: opTypeOnEvent, Name: "push", Config: map[string]interface{}{"action": "merge", "name": "feature/login", "target": "{branch}"}}))
+	bm.runHooks(ctx, interfaces.BranchingEvent{ID: "push-2", Type: interfaces.EventTypePush, Context: map[string]interface{}{"branch": "develop"}})
+	assert.Equal(t, develop, gitCmd(t, dir, "rev-parse", "develop"))
+}
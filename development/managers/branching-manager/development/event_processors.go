@@ -34,7 +34,7 @@ func (p *CommitEventProcessor) ProcessEvent(ctx context.Context, event interface
 		branchName := p.generateEventDrivenBranchName(branchType, commitHash)
 
 		// Create new branch
-		branchID, err := p.manager.createGitBranch(ctx, branchName, "main")
+		branchID, err := p.manager.createGitBranch(ctx, branchName, p.manager.baseBranch())
 		if err != nil {
 			return fmt.Errorf("failed to create event-driven branch: %w", err)
 		}
@@ -0,0 +1,271 @@
+package development
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitBackend abstracts the repository operations used by the branching manager
+type GitBackend interface {
+	// CreateBranch creates branchName at base (branch, tag or commit) without
+	// checking it out and returns the commit it points to
+	CreateBranch(ctx context.Context, branchName, base string) (string, error)
+	// Checkout switches the worktree to an existing branch
+	Checkout(ctx context.Context, branchName string) error
+	// CurrentBranch returns the checked out branch, empty when HEAD is detached
+	CurrentBranch(ctx context.Context) (string, error)
+	// CommitHash resolves a branch, tag or commit to a full commit SHA
+	CommitHash(ctx context.Context, rev string) (string, error)
+	// StashWorktree records uncommitted changes of tracked files as a stash
+	// commit kept under ref, without touching the worktree. It returns an
+	// empty SHA when the worktree is clean.
+	StashWorktree(ctx context.Context, ref, message string) (string, error)
+	// ApplyStash applies a stash commit recorded by StashWorktree to the worktree
+	ApplyStash(ctx context.Context, stash string) error
+	// WorktreeStatus summarizes the uncommitted changes of the worktree
+	WorktreeStatus(ctx context.Context) (*WorktreeStatus, error)
+	// CommitCount returns the number of commits reachable from rev
+	CommitCount(ctx context.Context, rev string) (int, error)
+	// Merge merges source into target with a merge commit and returns it; the
+	// previously checked out branch is restored afterwards
+	Merge(ctx context.Context, source, target, message string) (string, error)
+	// Tag creates a tag at rev, annotated when message is not empty
+	Tag(ctx context.Context, name, rev, message string) error
+	// DeleteBranch deletes a local branch; force deletes it even if unmerged
+	DeleteBranch(ctx context.Context, branchName string, force bool) error
+}
+
+// WorktreeStatus summarizes the uncommitted changes of a worktree
+type WorktreeStatus struct {
+	Staged    []string
+	Unstaged  []string
+	Untracked []string
+}
+
+// IsClean reports whether the worktree has no uncommitted change
+func (s *WorktreeStatus) IsClean() bool {
+	return len(s.Staged) == 0 && len(s.Unstaged) == 0 && len(s.Untracked) == 0
+}
+
+// CLIGitBackend implements GitBackend with the git command line
+type CLIGitBackend struct {
+	repoPath      string
+	gitExecutable string
+}
+
+// NewCLIGitBackend creates a backend for the repository at repoPath; an
+// empty gitExecutable uses "git" from the PATH
+func NewCLIGitBackend(repoPath, gitExecutable string) *CLIGitBackend {
+	if gitExecutable == "" {
+		gitExecutable = "git"
+	}
+	return &CLIGitBackend{repoPath: repoPath, gitExecutable: gitExecutable}
+}
+
+// CreateBranch creates branchName at base
+func (g *CLIGitBackend) CreateBranch(ctx context.Context, branchName, base string) (string, error) {
+	if err := g.checkBranchName(ctx, branchName); err != nil {
+		return "", err
+	}
+	if _, err := g.run(ctx, "branch", "--end-of-options", branchName, base); err != nil {
+		return "", fmt.Errorf("failed to create branch %s from %s: %w", branchName, base, err)
+	}
+	return g.CommitHash(ctx, branchName)
+}
+
+// Checkout switches the worktree to branchName. "git checkout" does not
+// accept --end-of-options: the trailing "--" keeps branchName a revision.
+func (g *CLIGitBackend) Checkout(ctx context.Context, branchName string) error {
+	if err := g.checkBranchName(ctx, branchName); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, "checkout", branchName, "--"); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", branchName, err)
+	}
+	return nil
+}
+
+// CurrentBranch returns the checked out branch
+func (g *CLIGitBackend) CurrentBranch(ctx context.Context) (string, error) {
+	output, err := g.run(ctx, "branch", "--show-current")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return output, nil
+}
+
+// CommitHash resolves rev to a commit SHA
+func (g *CLIGitBackend) CommitHash(ctx context.Context, rev string) (string, error) {
+	output, err := g.run(ctx, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return output, nil
+}
+
+// StashWorktree uses "git stash create", which builds the stash commit
+// without resetting the worktree, then pins it under ref so that it is not
+// garbage collected. Untracked files are not part of the stash.
+func (g *CLIGitBackend) StashWorktree(ctx context.Context, ref, message string) (string, error) {
+	if err := g.checkRefName(ctx, ref); err != nil {
+		return "", err
+	}
+	stash, err := g.run(ctx, "stash", "create", message)
+	if err != nil {
+		return "", fmt.Errorf("failed to stash worktree: %w", err)
+	}
+	if stash == "" {
+		return "", nil
+	}
+	if _, err := g.run(ctx, "update-ref", "-m", message, "--end-of-options", ref, stash); err != nil {
+		return "", fmt.Errorf("failed to store stash %s as %s: %w", stash, ref, err)
+	}
+	return stash, nil
+}
+
+// ApplyStash applies a stash commit to the worktree
+func (g *CLIGitBackend) ApplyStash(ctx context.Context, stash string) error {
+	if _, err := g.run(ctx, "stash", "apply", "--end-of-options", stash); err != nil {
+		return fmt.Errorf("failed to apply stash %s: %w", stash, err)
+	}
+	return nil
+}
+
+// WorktreeStatus parses "git status --porcelain"
+func (g *CLIGitBackend) WorktreeStatus(ctx context.Context) (*WorktreeStatus, error) {
+	output, err := g.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	status := &WorktreeStatus{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 4 {
+			continue
+		}
+		code, file := line[:2], line[3:]
+		if code == "??" {
+			status.Untracked = append(status.Untracked, file)
+			continue
+		}
+		if code[0] != ' ' {
+			status.Staged = append(status.Staged, file)
+		}
+		if code[1] != ' ' {
+			status.Unstaged = append(status.Unstaged, file)
+		}
+	}
+	return status, nil
+}
+
+// CommitCount counts the commits reachable from rev
+func (g *CLIGitBackend) CommitCount(ctx context.Context, rev string) (int, error) {
+	output, err := g.run(ctx, "rev-list", "--count", "--end-of-options", rev)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits of %s: %w", rev, err)
+	}
+	var count int
+	if _, err := fmt.Sscanf(output, "%d", &count); err != nil {
+		return 0, fmt.Errorf("unexpected rev-list output %q", output)
+	}
+	return count, nil
+}
+
+// Merge merges source into target. A conflicting merge is aborted so that
+// the worktree is left as it was.
+func (g *CLIGitBackend) Merge(ctx context.Context, source, target, message string) (string, error) {
+	previous, err := g.CurrentBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := g.Checkout(ctx, target); err != nil {
+		return "", err
+	}
+	if previous != "" && previous != target {
+		defer g.Checkout(ctx, previous)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Merge branch '%s' into %s", source, target)
+	}
+	if _, err := g.run(ctx, "merge", "--no-ff", "-m", message, "--end-of-options", source); err != nil {
+		g.run(ctx, "merge", "--abort")
+		return "", fmt.Errorf("failed to merge %s into %s: %w", source, target, err)
+	}
+	return g.CommitHash(ctx, "HEAD")
+}
+
+// Tag creates a lightweight or annotated tag
+func (g *CLIGitBackend) Tag(ctx context.Context, name, rev, message string) error {
+	if err := g.checkRefName(ctx, "refs/tags/"+name); err != nil {
+		return err
+	}
+	args := []string{"tag"}
+	if message != "" {
+		args = append(args, "-a", "-m", message)
+	}
+	args = append(args, "--end-of-options", name, rev)
+	if _, err := g.run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to tag %s as %s: %w", rev, name, err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch
+func (g *CLIGitBackend) DeleteBranch(ctx context.Context, branchName string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if err := g.checkBranchName(ctx, branchName); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, "branch", flag, "--end-of-options", branchName); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", branchName, err)
+	}
+	return nil
+}
+
+// checkBranchName rejects the names git does not accept for a branch,
+// including those starting with a dash
+func (g *CLIGitBackend) checkBranchName(ctx context.Context, branchName string) error {
+	if _, err := g.run(ctx, "check-ref-format", "--branch", branchName); err != nil {
+		return fmt.Errorf("invalid branch name %q: %w", branchName, err)
+	}
+	return nil
+}
+
+// checkRefName rejects malformed full ref names ("refs/...")
+func (g *CLIGitBackend) checkRefName(ctx context.Context, ref string) error {
+	if !strings.HasPrefix(ref, "refs/") {
+		return fmt.Errorf("invalid ref %q: must start with refs/", ref)
+	}
+	if _, err := g.run(ctx, "check-ref-format", ref); err != nil {
+		return fmt.Errorf("invalid ref %q: %w", ref, err)
+	}
+	return nil
+}
+
+// run executes git in the repository and returns its standard output without
+// the trailing newline; leading spaces are significant in porcelain output
+func (g *CLIGitBackend) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, g.gitExecutable, args...)
+	cmd.Dir = g.repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
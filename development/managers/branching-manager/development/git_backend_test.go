@@ -0,0 +1,172 @@
+package development
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"../interfaces"
+)
+
+// initTestRepo creates a throwaway repository with one commit on main
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	gitCmd(t, dir, "config", "user.email", "branching@example.com")
+	gitCmd(t, dir, "config", "user.name", "Branching Test")
+	writeTestFile(t, dir, "README.md", "v1\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+	return strings.TrimSpace(string(output))
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func readTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestCLIGitBackend_BranchesAndStash(t *testing.T) {
+	dir := initTestRepo(t)
+	backend := NewCLIGitBackend(dir, "")
+	ctx := context.Background()
+
+	head := gitCmd(t, dir, "rev-parse", "HEAD")
+	commit, err := backend.CreateBranch(ctx, "feature/test", "main")
+	require.NoError(t, err)
+	assert.Equal(t, head, commit)
+
+	current, err := backend.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", current, "CreateBranch must not check the branch out")
+
+	_, err = backend.CreateBranch(ctx, "bad..name", "main")
+	assert.Error(t, err)
+
+	// A clean worktree has nothing to stash
+	stash, err := backend.StashWorktree(ctx, "refs/branching/snapshots/clean", "clean")
+	require.NoError(t, err)
+	assert.Empty(t, stash)
+
+	writeTestFile(t, dir, "README.md", "v2 uncommitted\n")
+	writeTestFile(t, dir, "notes.txt", "untracked\n")
+	status, err := backend.WorktreeStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, status.Unstaged)
+	assert.Equal(t, []string{"notes.txt"}, status.Untracked)
+
+	stash, err = backend.StashWorktree(ctx, "refs/branching/snapshots/dirty", "dirty")
+	require.NoError(t, err)
+	require.NotEmpty(t, stash)
+	assert.Equal(t, stash, gitCmd(t, dir, "rev-parse", "refs/branching/snapshots/dirty"))
+	assert.Equal(t, "v2 uncommitted\n", readTestFile(t, dir, "README.md"), "stashing must leave the worktree untouched")
+
+	gitCmd(t, dir, "checkout", "--", "README.md")
+	require.NoError(t, backend.Checkout(ctx, "feature/test"))
+	require.NoError(t, backend.ApplyStash(ctx, stash))
+	assert.Equal(t, "v2 uncommitted\n", readTestFile(t, dir, "README.md"))
+
+	count, err := backend.CommitCount(ctx, "feature/test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestCLIGitBackend_RejectsOptionLikeNames(t *testing.T) {
+	dir := initTestRepo(t)
+	backend := NewCLIGitBackend(dir, "")
+	ctx := context.Background()
+
+	// Names and revisions starting with a dash are never read as options
+	_, err := backend.CreateBranch(ctx, "--orphan", "main")
+	assert.Error(t, err)
+	_, err = backend.CreateBranch(ctx, "feature/dash", "--help")
+	assert.Error(t, err)
+	assert.Error(t, backend.Checkout(ctx, "-f"))
+	assert.Error(t, backend.DeleteBranch(ctx, "-r", true))
+	_, err = backend.CommitHash(ctx, "--all")
+	assert.Error(t, err)
+	_, err = backend.CommitCount(ctx, "--all")
+	assert.Error(t, err)
+	_, err = backend.StashWorktree(ctx, "refs/branching/bad..ref", "bad")
+	assert.Error(t, err)
+
+	assert.Error(t, backend.Tag(ctx, "v1..0", "main", ""))
+	assert.Error(t, backend.Tag(ctx, "v1.0", "--contains", ""))
+	require.NoError(t, backend.Tag(ctx, "v1.0", "main", "first release"))
+	assert.Equal(t, gitCmd(t, dir, "rev-parse", "main"), gitCmd(t, dir, "rev-parse", "v1.0^{commit}"))
+
+	_, err = backend.CreateBranch(ctx, "feature/merge", "main")
+	require.NoError(t, err)
+	_, err = backend.Merge(ctx, "--squash", "main", "")
+	assert.Error(t, err)
+	merge, err := backend.Merge(ctx, "feature/merge", "main", "")
+	require.NoError(t, err)
+	assert.Equal(t, gitCmd(t, dir, "rev-parse", "main"), merge)
+}
+
+func TestBranchingManager_SessionSnapshotAndTimeTravel(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+
+	bm := NewBranchingManagerImpl(&BranchingConfig{
+		RepositoryPath:         dir,
+		DefaultBaseBranch:      "main",
+		DefaultSessionDuration: 30 * time.Minute,
+		EventQueueSize:         10,
+		TimeTravelEnabled:      true,
+		MaxSnapshotsPerBranch:  10,
+	})
+	bm.storageManager = NewMockStorageManager()
+
+	// The session creates a real branch
+	session, err := bm.CreateSession(ctx, interfaces.SessionConfig{Scope: "feature/git"})
+	require.NoError(t, err)
+	assert.Equal(t, gitCmd(t, dir, "rev-parse", "main"), gitCmd(t, dir, "rev-parse", session.BranchID))
+
+	// Snapshot a checked out branch with uncommitted changes
+	gitCmd(t, dir, "checkout", "-q", session.BranchID)
+	writeTestFile(t, dir, "README.md", "work in progress\n")
+	snapshot, err := bm.CreateTemporalSnapshot(ctx, session.BranchID)
+	require.NoError(t, err)
+	snapshotCommit := gitCmd(t, dir, "rev-parse", "HEAD")
+	assert.Equal(t, snapshotCommit, snapshot.CommitHash)
+	require.NotEmpty(t, snapshot.Metadata["stash_ref"])
+	assert.Equal(t, snapshot.State["stash_commit"], gitCmd(t, dir, "rev-parse", snapshot.Metadata["stash_ref"]))
+
+	// The branch moves on afterwards
+	gitCmd(t, dir, "commit", "-q", "-am", "later work")
+	require.NotEqual(t, snapshotCommit, gitCmd(t, dir, "rev-parse", "HEAD"))
+
+	require.NoError(t, bm.TimeTravelToBranch(ctx, snapshot.ID, time.Now()))
+	current := gitCmd(t, dir, "branch", "--show-current")
+	assert.True(t, strings.HasPrefix(current, "timetravel-"+session.BranchID), current)
+	assert.Equal(t, snapshotCommit, gitCmd(t, dir, "rev-parse", "HEAD"))
+	assert.Equal(t, "work in progress\n", readTestFile(t, dir, "README.md"))
+}
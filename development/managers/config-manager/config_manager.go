@@ -3,13 +3,18 @@ package configmanager
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"email_sender/core/config/filewatch"
+
 	"github.com/google/uuid"
 	"github.com/mitchellh/mapstructure"
+	"github.com/xeipuuv/gojsonschema"
 	"go.uber.org/zap"
 )
 
@@ -68,17 +73,50 @@ type ConfigManager interface {
 	Cleanup() error
 	GetErrorManager() ErrorManager
 	GetLogger() *zap.Logger
+
+	// SetSecretResolver enables ${secret:name} references in loaded configuration
+	SetSecretResolver(resolver SecretResolver)
+
+	// Layered configuration: defaults < file < profile < env < flags
+	LoadProfile(profile string, filePath string) error
+	LoadFlags(flags *flag.FlagSet)
+	Source(key string) (Layer, bool)
+
+	// Schema validation, applied by Validate and before every reload
+	SetSchema(jsonSchema []byte) error
+	SetStructSchema(schema interface{}) error
+
+	// Live reload and change notification
+	Watch() error
+	StopWatching() error
+	Reload() error
+	Subscribe(key string, fn func(ChangeEvent)) func()
 }
 
 // configManagerImpl is the concrete implementation of ConfigManager.
 type configManagerImpl struct {
-	// Internal storage for configuration values
-	// This will likely be a map[string]interface{} or a more sophisticated structure
-	// to handle priorities and different sources.
-	settings     map[string]interface{}
-	defaults     map[string]interface{}
+	// Configuration values per layer, guarded by mu; higher layers win
+	mu           sync.RWMutex
+	layers       [layerCount]map[string]interface{}
+	sources      []fileSource
 	requiredKeys []string
-	
+
+	// Validation applied by Validate and before reloads
+	jsonSchema  *gojsonschema.Schema
+	structRules []fieldRule
+
+	// Resolves ${secret:name} references at load time
+	secretResolver SecretResolver
+
+	// Live reload
+	watcher  *filewatch.Watcher
+	reloadMu sync.Mutex
+
+	// Change subscriptions
+	subMu       sync.Mutex
+	subscribers []subscription
+	nextSubID   int
+
 	// ErrorManager integration
 	logger       *zap.Logger
 	errorManager *ErrorManagerImpl
@@ -226,13 +264,15 @@ func New() (ConfigManager, error) {
 		logger: logger,
 	}
 
-	return &configManagerImpl{
-		settings:     make(map[string]interface{}),
-		defaults:     make(map[string]interface{}),
+	cm := &configManagerImpl{
 		requiredKeys: make([]string, 0),
 		logger:       logger,
 		errorManager: errorManager,
-	}, nil
+	}
+	for layer := range cm.layers {
+		cm.layers[layer] = make(map[string]interface{})
+	}
+	return cm, nil
 }
 
 // GetString retrieves a string value from the configuration with error handling.
@@ -362,28 +402,13 @@ func (cm *configManagerImpl) UnmarshalKey(key string, targetStruct interface{})
 	sectionData := make(map[string]interface{})
 	keyPrefix := normalizedKey + "."
 
-	// First check settings (highest priority)
-	for k, v := range cm.settings {
-		if k == normalizedKey {
-			// Direct match
-			sectionData[k] = v
-		} else if strings.HasPrefix(k, keyPrefix) {
-			// Nested key
+	// Use the merged view so each nested key comes from its highest layer
+	for k, v := range cm.GetAll() {
+		if k == normalizedKey || strings.HasPrefix(k, keyPrefix) {
 			sectionData[k] = v
 		}
 	}
 
-	// Then check defaults if no settings found
-	if len(sectionData) == 0 {
-		for k, v := range cm.defaults {
-			if k == normalizedKey {
-				sectionData[k] = v
-			} else if strings.HasPrefix(k, keyPrefix) {
-				sectionData[k] = v
-			}
-		}
-	}
-
 	if len(sectionData) == 0 {
 		notFoundErr := fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 		// Process not found error
@@ -429,49 +454,70 @@ func (cm *configManagerImpl) UnmarshalKey(key string, targetStruct interface{})
 
 // IsSet checks if a key is set in the configuration.
 func (cm *configManagerImpl) IsSet(key string) bool {
-	normalizedKey := normalizeKey(key)
-
-	// Check in settings first (highest priority)
-	if _, exists := cm.settings[normalizedKey]; exists {
-		return true
-	}
-
-	// Check in defaults (lowest priority)
-	if _, exists := cm.defaults[normalizedKey]; exists {
-		return true
-	}
-
-	return false
+	_, found := cm.Source(key)
+	return found
 }
 
 // RegisterDefaults registers default configuration values.
 func (cm *configManagerImpl) RegisterDefaults(defaults map[string]interface{}) {
-	if cm.defaults == nil {
-		cm.defaults = make(map[string]interface{})
-	}
-	for key, value := range defaults {
-		cm.defaults[key] = value
-	}
+	cm.mutate(func() {
+		for key, value := range defaults {
+			cm.layers[LayerDefaults][normalizeKey(key)] = value
+		}
+	})
 }
 
-// LoadConfigFile loads configuration from a file with error handling.
+// LoadConfigFile loads configuration from a file into the file layer with error handling.
+// Files loaded later override keys of files loaded earlier.
 func (cm *configManagerImpl) LoadConfigFile(filePath string, fileType string) error {
+	return cm.loadLayerFile(LayerFile, filePath, fileType)
+}
+
+// loadLayerFile loads a file into a layer and records it for Reload
+func (cm *configManagerImpl) loadLayerFile(layer Layer, filePath string, fileType string) error {
 	ctx := context.Background()
-	
+
+	config, operation, err := cm.readFile(filePath, fileType)
+	if err != nil {
+		// Process config loading error
+		if processErr := cm.errorManager.ProcessError(ctx, err, "config-loading", operation, &ErrorHooks{
+			OnError: func(e error) {
+				cm.logger.Warn("Config file loading failed",
+					zap.String("file_path", filePath),
+					zap.String("layer", layer.String()),
+					zap.Error(e))
+			},
+		}); processErr != nil {
+			cm.logger.Error("Error processing failed", zap.Error(processErr))
+		}
+		return err
+	}
+
+	// Merge loaded config into the layer
+	cm.mutate(func() {
+		for key, value := range config {
+			cm.layers[layer][normalizeKey(key)] = value
+		}
+		cm.sources = append(cm.sources, fileSource{path: filePath, fileType: fileType, layer: layer})
+	})
+
+	// Log successful loading
+	cm.logger.Info("Configuration file loaded successfully",
+		zap.String("file_path", filePath),
+		zap.String("layer", layer.String()),
+		zap.Int("keys_loaded", len(config)))
+
+	return nil
+}
+
+// readFile parses a configuration file and resolves its secret references.
+// On failure it also returns the failed operation for error reporting.
+func (cm *configManagerImpl) readFile(filePath string, fileType string) (map[string]interface{}, string, error) {
 	// Auto-detect file type if not provided
 	if fileType == "" {
 		fileType = detectFileType(filePath)
 		if fileType == "" {
-			formatErr := fmt.Errorf("%w: cannot detect file type for %s", ErrInvalidFormat, filePath)
-			// Process format detection error
-			if processErr := cm.errorManager.ProcessError(ctx, formatErr, "config-loading", "file-type-detection", &ErrorHooks{
-				OnError: func(e error) {
-					cm.logger.Warn("File type detection failed", zap.String("file_path", filePath), zap.Error(e))
-				},
-			}); processErr != nil {
-				cm.logger.Error("Error processing failed", zap.Error(processErr))
-			}
-			return formatErr
+			return nil, "file-type-detection", fmt.Errorf("%w: cannot detect file type for %s", ErrInvalidFormat, filePath)
 		}
 	}
 
@@ -485,63 +531,44 @@ func (cm *configManagerImpl) LoadConfigFile(filePath string, fileType string) er
 	case "toml":
 		config, err = loadFromTOML(filePath)
 	default:
-		unsupportedErr := fmt.Errorf("%w: unsupported file type %s", ErrInvalidFormat, fileType)
-		// Process unsupported file type error
-		if processErr := cm.errorManager.ProcessError(ctx, unsupportedErr, "config-loading", "unsupported-file-type", nil); processErr != nil {
-			cm.logger.Error("Error processing failed", zap.Error(processErr))
-		}
-		return unsupportedErr
+		return nil, "unsupported-file-type", fmt.Errorf("%w: unsupported file type %s", ErrInvalidFormat, fileType)
 	}
-
 	if err != nil {
-		// Process config loading error
-		if processErr := cm.errorManager.ProcessError(ctx, err, "config-loading", "file-parsing", &ErrorHooks{
-			OnError: func(e error) {
-				cm.logger.Warn("Config file parsing failed", 
-					zap.String("file_path", filePath), 
-					zap.String("file_type", fileType), 
-					zap.Error(e))
-			},
-		}); processErr != nil {
-			cm.logger.Error("Error processing failed", zap.Error(processErr))
-		}
-		return err
+		return nil, "file-parsing", err
 	}
 
-	// Merge loaded config into settings
-	if cm.settings == nil {
-		cm.settings = make(map[string]interface{})
+	// Resolve ${secret:name} references so credentials never live in the file
+	if err := resolveSecretRefs(config, cm.currentSecretResolver()); err != nil {
+		return nil, "secret-resolution", err
 	}
 
-	for key, value := range config {
-		normalizedKey := normalizeKey(key)
-		cm.settings[normalizedKey] = value
-	}
-
-	// Log successful loading
-	cm.logger.Info("Configuration file loaded successfully", 
-		zap.String("file_path", filePath), 
-		zap.String("file_type", fileType),
-		zap.Int("keys_loaded", len(config)))
-
-	return nil
+	return config, "", nil
 }
 
 // LoadFromEnv loads configuration from environment variables with error handling.
 func (cm *configManagerImpl) LoadFromEnv(prefix string) {
 	envConfig := loadFromEnv(prefix)
 
-	// Merge env config into settings (env has higher priority than files and defaults)
-	if cm.settings == nil {
-		cm.settings = make(map[string]interface{})
+	// Unresolvable secret references are skipped rather than loaded verbatim
+	resolver := cm.currentSecretResolver()
+	for key, value := range envConfig {
+		single := map[string]interface{}{key: value}
+		if err := resolveSecretRefs(single, resolver); err != nil {
+			cm.logger.Warn("Skipping environment variable with unresolved secret", zap.String("key", key), zap.Error(err))
+			delete(envConfig, key)
+			continue
+		}
+		envConfig[key] = single[key]
 	}
 
+	// Merge env config into the env layer (above defaults, files and profiles)
 	keysLoaded := 0
-	for key, value := range envConfig {
-		normalizedKey := normalizeKey(key)
-		cm.settings[normalizedKey] = value
-		keysLoaded++
-	}
+	cm.mutate(func() {
+		for key, value := range envConfig {
+			cm.layers[LayerEnv][normalizeKey(key)] = value
+			keysLoaded++
+		}
+	})
 
 	// Log environment variables loading
 	cm.logger.Info("Environment variables loaded", 
@@ -549,25 +576,20 @@ func (cm *configManagerImpl) LoadFromEnv(prefix string) {
 		zap.Int("keys_loaded", keysLoaded))
 }
 
-// Validate validates that all required configuration keys are present with error handling.
+// Validate validates that all required configuration keys are present and that
+// the configuration matches the schemas set with SetSchema/SetStructSchema.
 func (cm *configManagerImpl) Validate() error {
 	ctx := context.Background()
-	var missingKeys []string
 
-	for _, key := range cm.requiredKeys {
-		if !cm.IsSet(key) {
-			missingKeys = append(missingKeys, key)
-		}
-	}
+	cm.mu.RLock()
+	validationErr := cm.validateLocked(cm.layers)
+	cm.mu.RUnlock()
 
-	if len(missingKeys) > 0 {
-		validationErr := fmt.Errorf("missing required configuration keys: %v", missingKeys)
+	if validationErr != nil {
 		// Process validation error
-		if processErr := cm.errorManager.ProcessError(ctx, validationErr, "config-validation", "missing-required-keys", &ErrorHooks{
+		if processErr := cm.errorManager.ProcessError(ctx, validationErr, "config-validation", "invalid-configuration", &ErrorHooks{
 			OnError: func(e error) {
-				cm.logger.Warn("Configuration validation failed", 
-					zap.Strings("missing_keys", missingKeys), 
-					zap.Error(e))
+				cm.logger.Warn("Configuration validation failed", zap.Error(e))
 			},
 		}); processErr != nil {
 			cm.logger.Error("Error processing failed", zap.Error(processErr))
@@ -584,6 +606,8 @@ func (cm *configManagerImpl) Validate() error {
 
 // SetRequiredKeys sets the list of required configuration keys for validation.
 func (cm *configManagerImpl) SetRequiredKeys(keys []string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	cm.requiredKeys = make([]string, len(keys))
 	copy(cm.requiredKeys, keys)
 }
@@ -593,17 +617,12 @@ func normalizeKey(key string) string {
 	return strings.ToLower(key)
 }
 
-// getValue retrieves a value from configuration with priority order: settings > defaults
+// getValue retrieves a value from the highest layer that sets the key
 func (cm *configManagerImpl) getValue(key string) (interface{}, error) {
-	normalizedKey := normalizeKey(key)
-
-	// Check settings first (highest priority)
-	if value, exists := cm.settings[normalizedKey]; exists {
-		return value, nil
-	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 
-	// Check defaults (lowest priority)
-	if value, exists := cm.defaults[normalizedKey]; exists {
+	if value, _, exists := cm.lookupLocked(normalizeKey(key)); exists {
 		return value, nil
 	}
 
@@ -669,33 +688,26 @@ func (cm *configManagerImpl) Get(key string) interface{} {
 	return value
 }
 
-// Set sets a configuration value
+// Set sets a configuration value in the flags layer, overriding every other source
 func (cm *configManagerImpl) Set(key string, value interface{}) {
-	normalizedKey := normalizeKey(key)
-	cm.settings[normalizedKey] = value
+	cm.mutate(func() {
+		cm.layers[LayerFlags][normalizeKey(key)] = value
+	})
 }
 
 // SetDefault sets a default value for a key
 func (cm *configManagerImpl) SetDefault(key string, value interface{}) {
-	normalizedKey := normalizeKey(key)
-	cm.defaults[normalizedKey] = value
+	cm.mutate(func() {
+		cm.layers[LayerDefaults][normalizeKey(key)] = value
+	})
 }
 
-// GetAll returns all configuration values (settings merged with defaults)
+// GetAll returns all configuration values, each from its highest layer
 func (cm *configManagerImpl) GetAll() map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// First copy defaults
-	for k, v := range cm.defaults {
-		result[k] = v
-	}
-
-	// Then overlay settings (higher priority)
-	for k, v := range cm.settings {
-		result[k] = v
-	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 
-	return result
+	return mergeLayers(cm.layers)
 }
 
 // SaveToFile saves configuration to a file with error handling
@@ -733,6 +745,9 @@ func (cm *configManagerImpl) ensureDirectoryExists(dirPath string) error {
 
 // Cleanup performs cleanup operations for the config manager
 func (cm *configManagerImpl) Cleanup() error {
+	if err := cm.StopWatching(); err != nil {
+		return fmt.Errorf("failed to stop config watcher: %w", err)
+	}
 	if cm.logger != nil {
 		if err := cm.logger.Sync(); err != nil {
 			return fmt.Errorf("failed to sync logger: %w", err)
@@ -746,6 +761,20 @@ func (cm *configManagerImpl) GetErrorManager() ErrorManager {
 	return cm.errorManager
 }
 
+// SetSecretResolver sets the resolver used for ${secret:name} references
+func (cm *configManagerImpl) SetSecretResolver(resolver SecretResolver) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.secretResolver = resolver
+}
+
+// currentSecretResolver returns the resolver set with SetSecretResolver
+func (cm *configManagerImpl) currentSecretResolver() SecretResolver {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.secretResolver
+}
+
 // GetLogger returns the logger instance for external use  
 func (cm *configManagerImpl) GetLogger() *zap.Logger {
 	return cm.logger
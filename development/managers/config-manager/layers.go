@@ -0,0 +1,314 @@
+package configmanager
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"email_sender/core/config/filewatch"
+
+	"go.uber.org/zap"
+)
+
+// Layer is a configuration source level. A key set in a higher layer
+// overrides the same key in every lower layer.
+type Layer int
+
+const (
+	LayerDefaults Layer = iota // RegisterDefaults, SetDefault
+	LayerFile                  // LoadConfigFile
+	LayerProfile               // LoadProfile
+	LayerEnv                   // LoadFromEnv
+	LayerFlags                 // LoadFlags, Set
+	layerCount
+)
+
+// String returns the layer name
+func (l Layer) String() string {
+	switch l {
+	case LayerDefaults:
+		return "defaults"
+	case LayerFile:
+		return "file"
+	case LayerProfile:
+		return "profile"
+	case LayerEnv:
+		return "env"
+	case LayerFlags:
+		return "flags"
+	default:
+		return fmt.Sprintf("layer(%d)", int(l))
+	}
+}
+
+// ChangeEvent describes a key whose effective value changed
+type ChangeEvent struct {
+	Key     string
+	Old     interface{} // nil if the key was not set
+	New     interface{} // nil if the key was removed
+	Deleted bool
+}
+
+// fileSource is a file loaded into the file or profile layer, kept for reloads
+type fileSource struct {
+	path     string
+	fileType string
+	layer    Layer
+}
+
+// subscription is a Subscribe callback
+type subscription struct {
+	id  int
+	key string
+	fn  func(ChangeEvent)
+}
+
+// LoadProfile loads a profile file (e.g. config.production.yaml) above the base files
+func (cm *configManagerImpl) LoadProfile(profile string, filePath string) error {
+	if err := cm.loadLayerFile(LayerProfile, filePath, ""); err != nil {
+		return err
+	}
+	cm.logger.Info("Configuration profile loaded", zap.String("profile", profile), zap.String("file_path", filePath))
+	return nil
+}
+
+// LoadFlags loads the flags explicitly set on the command line into the
+// highest layer; flag names are used as keys ("-server.port" -> server.port)
+func (cm *configManagerImpl) LoadFlags(flags *flag.FlagSet) {
+	values := make(map[string]interface{})
+	flags.Visit(func(f *flag.Flag) {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			values[normalizeKey(f.Name)] = getter.Get()
+		} else {
+			values[normalizeKey(f.Name)] = f.Value.String()
+		}
+	})
+
+	cm.mutate(func() {
+		for key, value := range values {
+			cm.layers[LayerFlags][key] = value
+		}
+	})
+}
+
+// Source returns the layer providing the effective value of a key
+func (cm *configManagerImpl) Source(key string) (Layer, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	_, layer, found := cm.lookupLocked(normalizeKey(key))
+	return layer, found
+}
+
+// Subscribe calls fn whenever the effective value of key, or of a key below
+// it ("database" covers "database.host"), changes; an empty key matches every
+// change. The returned function cancels the subscription.
+func (cm *configManagerImpl) Subscribe(key string, fn func(ChangeEvent)) func() {
+	cm.subMu.Lock()
+	defer cm.subMu.Unlock()
+
+	cm.nextSubID++
+	id := cm.nextSubID
+	cm.subscribers = append(cm.subscribers, subscription{id: id, key: normalizeKey(key), fn: fn})
+
+	return func() {
+		cm.subMu.Lock()
+		defer cm.subMu.Unlock()
+		for i, sub := range cm.subscribers {
+			if sub.id == id {
+				cm.subscribers = append(cm.subscribers[:i], cm.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Watch reloads the loaded configuration files when they change on disk. A
+// reload is only applied if the resulting configuration passes Validate;
+// otherwise the current configuration stays active and the error goes
+// through the ErrorManager.
+func (cm *configManagerImpl) Watch() error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.watcher != nil {
+		return nil
+	}
+	if len(cm.sources) == 0 {
+		return fmt.Errorf("no configuration file loaded to watch")
+	}
+
+	paths := make([]string, len(cm.sources))
+	for i, source := range cm.sources {
+		paths[i] = source.path
+	}
+	watcher, err := filewatch.Watch(paths, filewatch.Options{
+		Match:    cm.isSource,
+		OnChange: func() { cm.Reload() },
+		OnError: func(err error) {
+			cm.logger.Warn("Configuration watcher error", zap.Error(err))
+		},
+	})
+	if err != nil {
+		return err
+	}
+	cm.watcher = watcher
+
+	cm.logger.Info("Watching configuration files", zap.Int("files", len(cm.sources)))
+	return nil
+}
+
+// StopWatching stops the watch started by Watch
+func (cm *configManagerImpl) StopWatching() error {
+	cm.mu.Lock()
+	watcher := cm.watcher
+	cm.watcher = nil
+	cm.mu.Unlock()
+
+	if watcher == nil {
+		return nil
+	}
+	// Outside the lock: Close waits for a running reload
+	return watcher.Close()
+}
+
+// Reload re-reads every loaded file and swaps the new values in if they are valid
+func (cm *configManagerImpl) Reload() error {
+	ctx := context.Background()
+
+	// Serialize reloads so an older read of the files never wins
+	cm.reloadMu.Lock()
+	defer cm.reloadMu.Unlock()
+
+	cm.mu.RLock()
+	sources := append([]fileSource{}, cm.sources...)
+	cm.mu.RUnlock()
+
+	// Rebuild the file and profile layers from their sources, in load order
+	rebuilt := map[Layer]map[string]interface{}{
+		LayerFile:    make(map[string]interface{}),
+		LayerProfile: make(map[string]interface{}),
+	}
+	for _, source := range sources {
+		values, _, err := cm.readFile(source.path, source.fileType)
+		if err != nil {
+			return cm.rejectReload(ctx, err)
+		}
+		for key, value := range values {
+			rebuilt[source.layer][normalizeKey(key)] = value
+		}
+	}
+
+	// Validate and apply under the same lock: a Set in between could
+	// otherwise be overwritten by, or make invalid, the validated candidate
+	var rejected error
+	cm.mutate(func() {
+		candidate := cm.layers
+		for layer, values := range rebuilt {
+			candidate[layer] = values
+		}
+		if rejected = cm.validateLocked(candidate); rejected == nil {
+			cm.layers = candidate
+		}
+	})
+	if rejected != nil {
+		return cm.rejectReload(ctx, rejected)
+	}
+
+	cm.logger.Info("Configuration reloaded", zap.Int("files", len(sources)))
+	return nil
+}
+
+// rejectReload reports a reload that was not applied
+func (cm *configManagerImpl) rejectReload(ctx context.Context, err error) error {
+	if processErr := cm.errorManager.ProcessError(ctx, err, "config-reload", "reload-rejected", &ErrorHooks{
+		OnError: func(e error) {
+			cm.logger.Warn("Configuration reload rejected, keeping current configuration", zap.Error(e))
+		},
+	}); processErr != nil {
+		cm.logger.Error("Error processing failed", zap.Error(processErr))
+	}
+	return err
+}
+
+// isSource reports whether a path is one of the loaded files
+func (cm *configManagerImpl) isSource(path string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	path = filepath.Clean(path)
+	for _, source := range cm.sources {
+		if filepath.Clean(source.path) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// mutate applies a change to the layers and notifies subscribers of the keys
+// whose effective value changed
+func (cm *configManagerImpl) mutate(change func()) {
+	cm.mu.Lock()
+	before := mergeLayers(cm.layers)
+	change()
+	after := mergeLayers(cm.layers)
+	cm.mu.Unlock()
+
+	events := diffValues(before, after)
+	if len(events) == 0 {
+		return
+	}
+
+	cm.subMu.Lock()
+	subscribers := append([]subscription{}, cm.subscribers...)
+	cm.subMu.Unlock()
+
+	for _, event := range events {
+		for _, sub := range subscribers {
+			if sub.key == "" || sub.key == event.Key || strings.HasPrefix(event.Key, sub.key+".") {
+				sub.fn(event)
+			}
+		}
+	}
+}
+
+// lookupLocked returns the effective value of a normalized key (lock held)
+func (cm *configManagerImpl) lookupLocked(key string) (interface{}, Layer, bool) {
+	for layer := layerCount - 1; layer >= LayerDefaults; layer-- {
+		if value, exists := cm.layers[layer][key]; exists {
+			return value, layer, true
+		}
+	}
+	return nil, 0, false
+}
+
+// mergeLayers returns the effective values, higher layers overriding lower ones
+func mergeLayers(layers [layerCount]map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for layer := LayerDefaults; layer < layerCount; layer++ {
+		for key, value := range layers[layer] {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// diffValues lists the keys whose value differs between two merged views
+func diffValues(before, after map[string]interface{}) []ChangeEvent {
+	var events []ChangeEvent
+	for key, newValue := range after {
+		oldValue, existed := before[key]
+		if !existed || !reflect.DeepEqual(oldValue, newValue) {
+			events = append(events, ChangeEvent{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	for key, oldValue := range before {
+		if _, exists := after[key]; !exists {
+			events = append(events, ChangeEvent{Key: key, Old: oldValue, Deleted: true})
+		}
+	}
+	return events
+}
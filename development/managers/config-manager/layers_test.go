@@ -0,0 +1,217 @@
+package configmanager
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLayerPrecedence checks defaults < file < profile < env < flags.
+func TestLayerPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	profile := filepath.Join(dir, "config.production.yaml")
+	if err := os.WriteFile(base, []byte("server:\n  host: file-host\n  port: 8080\n  mode: file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(profile, []byte("server:\n  port: 9090\n  mode: profile\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	cm.RegisterDefaults(map[string]interface{}{"server.timeout": "30s", "server.host": "default-host"})
+	if err := cm.LoadConfigFile(base, ""); err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if err := cm.LoadProfile("production", profile); err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	t.Setenv("LAYERTEST_SERVER_MODE", "env")
+	cm.LoadFromEnv("LAYERTEST_")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("server.host", "", "")
+	flags.Int("server.port", 0, "")
+	if err := flags.Parse([]string{"-server.host=flag-host"}); err != nil {
+		t.Fatal(err)
+	}
+	cm.LoadFlags(flags)
+
+	tests := []struct {
+		key   string
+		want  string
+		layer Layer
+	}{
+		{"server.timeout", "30s", LayerDefaults},
+		{"server.port", "9090", LayerProfile},
+		{"server.mode", "env", LayerEnv},
+		{"server.host", "flag-host", LayerFlags},
+	}
+	for _, tt := range tests {
+		got, err := cm.GetString(tt.key)
+		if err != nil || got != tt.want {
+			t.Errorf("GetString(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+		if layer, _ := cm.Source(tt.key); layer != tt.layer {
+			t.Errorf("Source(%q) = %s, want %s", tt.key, layer, tt.layer)
+		}
+	}
+}
+
+// TestSubscribe checks callbacks receive the changes under their key.
+func TestSubscribe(t *testing.T) {
+	cm, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	cm.SetDefault("database.host", "localhost")
+
+	var events []ChangeEvent
+	unsubscribe := cm.Subscribe("database", func(event ChangeEvent) {
+		events = append(events, event)
+	})
+
+	cm.Set("database.host", "db.internal")
+	cm.Set("database.host", "db.internal") // unchanged, no event
+	cm.Set("logging.level", "debug")       // other section
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	if events[0].Key != "database.host" || events[0].Old != "localhost" || events[0].New != "db.internal" {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+
+	unsubscribe()
+	cm.Set("database.host", "other")
+	if len(events) != 1 {
+		t.Error("Expected no event after unsubscribe")
+	}
+}
+
+// TestValidationSchemas checks struct-tag and JSON Schema validation errors.
+func TestValidationSchemas(t *testing.T) {
+	type serverConfig struct {
+		Host string `mapstructure:"host" validate:"required"`
+		Port int    `mapstructure:"port" validate:"required,min=1,max=65535"`
+	}
+	type appConfig struct {
+		Server serverConfig `mapstructure:"server"`
+		Level  string       `mapstructure:"log_level" validate:"oneof=debug info warn error"`
+	}
+
+	cm, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := cm.SetStructSchema(&appConfig{}); err != nil {
+		t.Fatalf("SetStructSchema() error = %v", err)
+	}
+
+	cm.Set("server.port", 70000)
+	cm.Set("log_level", "verbose")
+	err = cm.Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v, want ValidationError", err)
+	}
+	want := []string{
+		`log_level: must be one of debug, info, warn, error, got "verbose"`,
+		"server.host: required key is missing",
+		"server.port: must be at most 65535, got 70000",
+	}
+	if strings.Join(validationErr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Problems = %q, want %q", validationErr.Problems, want)
+	}
+
+	cm.Set("server.host", "localhost")
+	cm.Set("server.port", "abc")
+	cm.Set("log_level", "info")
+	if err := cm.Validate(); err == nil || !strings.Contains(err.Error(), "server.port: expected an integer") {
+		t.Errorf("Validate() error = %v, want type error on server.port", err)
+	}
+
+	// Text from env/flags is accepted where the schema expects a number
+	if err := cm.SetStructSchema(struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	schema := `{"type": "object", "properties": {"server": {"type": "object",
+		"properties": {"port": {"type": "integer", "minimum": 1}}, "required": ["host"]}}}`
+	if err := cm.SetSchema([]byte(schema)); err != nil {
+		t.Fatalf("SetSchema() error = %v", err)
+	}
+	cm.Set("server.port", "8080")
+	if err := cm.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	cm.Set("server.port", 0)
+	if err := cm.Validate(); err == nil || !strings.Contains(err.Error(), "server.port: Must be greater than or equal to 1") {
+		t.Errorf("Validate() error = %v, want minimum error on server.port", err)
+	}
+}
+
+// TestWatchRejectsInvalidReload checks a reload is only applied once valid.
+func TestWatchRejectsInvalidReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 8080\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer cm.Cleanup()
+	if err := cm.LoadConfigFile(path, ""); err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	cm.SetRequiredKeys([]string{"server.port"})
+
+	var mu sync.Mutex
+	var ports []interface{}
+	cm.Subscribe("server.port", func(event ChangeEvent) {
+		mu.Lock()
+		ports = append(ports, event.New)
+		mu.Unlock()
+	})
+	if err := cm.Watch(); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Invalid: the required key disappears, the old value stays
+	if err := os.WriteFile(path, []byte("server:\n  host: localhost\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if port, _ := cm.GetInt("server.port"); port != 8080 {
+		t.Errorf("server.port = %d after invalid reload, want 8080", port)
+	}
+
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if port, _ := cm.GetInt("server.port"); port == 9090 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if port, _ := cm.GetInt("server.port"); port != 9090 {
+		t.Fatalf("server.port = %d, want 9090 after reload", port)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ports) != 1 || ports[0] != 9090 {
+		t.Errorf("change events = %v, want [9090]", ports)
+	}
+}
@@ -0,0 +1,88 @@
+package configmanager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrSecretResolution is returned when a ${secret:name} reference cannot be resolved
+var ErrSecretResolution = errors.New("failed to resolve secret reference")
+
+// secretRefPattern matches ${secret:name} references inside string values
+var secretRefPattern = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// SecretResolver resolves secret names to their values.
+// The security manager's vault satisfies it through SecretVault.For(manager).
+type SecretResolver interface {
+	ResolveSecret(name string) (string, error)
+}
+
+// containsSecretRef reports whether a value holds a ${secret:name} reference
+func containsSecretRef(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return secretRefPattern.MatchString(v)
+	case []interface{}:
+		for _, item := range v {
+			if containsSecretRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveSecretRefs replaces ${secret:name} references in the loaded values.
+// The map is only modified when every reference resolves.
+func resolveSecretRefs(config map[string]interface{}, resolver SecretResolver) error {
+	resolved := make(map[string]interface{})
+	for key, value := range config {
+		if !containsSecretRef(value) {
+			continue
+		}
+		if resolver == nil {
+			return fmt.Errorf("%w: %s references a secret but no secret resolver is configured", ErrSecretResolution, key)
+		}
+
+		newValue, err := resolveSecretValue(value, resolver)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrSecretResolution, key, err)
+		}
+		resolved[key] = newValue
+	}
+
+	for key, value := range resolved {
+		config[key] = value
+	}
+	return nil
+}
+
+// resolveSecretValue resolves the references of a string or a list of strings
+func resolveSecretValue(value interface{}, resolver SecretResolver) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var resolveErr error
+		result := secretRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			name := secretRefPattern.FindStringSubmatch(ref)[1]
+			secret, err := resolver.ResolveSecret(name)
+			if err != nil && resolveErr == nil {
+				resolveErr = fmt.Errorf("secret %s: %w", name, err)
+			}
+			return secret
+		})
+		return result, resolveErr
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			resolvedItem, err := resolveSecretValue(item, resolver)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = resolvedItem
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
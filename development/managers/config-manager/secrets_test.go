@@ -0,0 +1,75 @@
+package configmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mapSecretResolver resolves secrets from a map
+type mapSecretResolver map[string]string
+
+func (m mapSecretResolver) ResolveSecret(name string) (string, error) {
+	value, exists := m[name]
+	if !exists {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+// TestLoadConfigFileResolvesSecrets checks ${secret:name} references are resolved at load time.
+func TestLoadConfigFileResolvesSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "email:\n  password: ${secret:smtp/password}\ndatabase:\n  dsn: postgres://app:${secret:db/password}@localhost/app\n  host: localhost\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Without a resolver, references are refused rather than loaded verbatim
+	if err := cm.LoadConfigFile(path, ""); !errors.Is(err, ErrSecretResolution) {
+		t.Fatalf("LoadConfigFile() error = %v, want ErrSecretResolution", err)
+	}
+	if cm.IsSet("email.password") {
+		t.Error("Expected nothing to be loaded when a secret cannot be resolved")
+	}
+
+	cm.SetSecretResolver(mapSecretResolver{"smtp/password": "s3cr3t", "db/password": "pw"})
+	if err := cm.LoadConfigFile(path, ""); err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if value, _ := cm.GetString("email.password"); value != "s3cr3t" {
+		t.Errorf("GetString(email.password) = %q, want %q", value, "s3cr3t")
+	}
+	if value, _ := cm.GetString("database.dsn"); value != "postgres://app:pw@localhost/app" {
+		t.Errorf("GetString(database.dsn) = %q", value)
+	}
+
+	// An unknown secret fails the whole load
+	cm.SetSecretResolver(mapSecretResolver{"smtp/password": "s3cr3t"})
+	if err := cm.LoadConfigFile(path, ""); !errors.Is(err, ErrSecretResolution) {
+		t.Errorf("LoadConfigFile() error = %v, want ErrSecretResolution", err)
+	}
+}
+
+// TestResolveSecretRefsInLists checks references inside lists are resolved.
+func TestResolveSecretRefsInLists(t *testing.T) {
+	config := map[string]interface{}{
+		"api.keys": []interface{}{"${secret:key1}", "plain"},
+		"api.port": 8080,
+	}
+	if err := resolveSecretRefs(config, mapSecretResolver{"key1": "abc"}); err != nil {
+		t.Fatalf("resolveSecretRefs() error = %v", err)
+	}
+
+	keys := config["api.keys"].([]interface{})
+	if keys[0] != "abc" || keys[1] != "plain" || config["api.port"] != 8080 {
+		t.Errorf("unexpected resolved config: %v", config)
+	}
+}
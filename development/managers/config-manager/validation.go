@@ -0,0 +1,424 @@
+package configmanager
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ErrValidation is wrapped by every ValidationError
+var ErrValidation = errors.New("configuration validation failed")
+
+// ValidationError lists every problem found in a configuration, one
+// "key: problem" entry each
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems on separate lines
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v:\n  - %s", ErrValidation, strings.Join(e.Problems, "\n  - "))
+}
+
+// Unwrap allows errors.Is(err, ErrValidation)
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// fieldRule is a validation rule derived from a struct field tag
+type fieldRule struct {
+	key      string
+	kind     reflect.Kind
+	duration bool
+	required bool
+	min      *float64
+	max      *float64
+	oneOf    []string
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// SetSchema sets a JSON Schema the configuration must match. Keys are
+// validated as a nested document ("database.port" -> {"database": {"port": ...}}).
+// An empty schema removes the JSON Schema validation.
+func (cm *configManagerImpl) SetSchema(jsonSchema []byte) error {
+	var schema *gojsonschema.Schema
+	if len(jsonSchema) > 0 {
+		compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(jsonSchema))
+		if err != nil {
+			return fmt.Errorf("%w: invalid JSON schema: %v", ErrInvalidFormat, err)
+		}
+		schema = compiled
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.jsonSchema = schema
+	return nil
+}
+
+// SetStructSchema derives validation rules from a struct. Keys come from the
+// mapstructure tags (as for UnmarshalKey) and rules from validate tags:
+//
+//	Port  int    `mapstructure:"port" validate:"required,min=1,max=65535"`
+//	Level string `mapstructure:"level" validate:"oneof=debug info warn error"`
+//
+// Fields are also checked to be convertible to their Go type.
+func (cm *configManagerImpl) SetStructSchema(schema interface{}) error {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: struct schema must be a struct, got %T", ErrInvalidType, schema)
+	}
+
+	rules, err := structRules(t, "")
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.structRules = rules
+	return nil
+}
+
+// structRules walks a struct type and returns the rules of its fields
+func structRules(t reflect.Type, prefix string) ([]fieldRule, error) {
+	var rules []fieldRule
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, squash := mapstructureName(field)
+		if name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+			nestedPrefix := key
+			if squash {
+				nestedPrefix = prefix
+			}
+			nested, err := structRules(fieldType, nestedPrefix)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, nested...)
+			if field.Tag.Get("validate") == "" {
+				continue
+			}
+		}
+
+		rule, err := parseFieldRule(key, fieldType, field.Tag.Get("validate"))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// mapstructureName returns the key of a field and whether it is squashed
+func mapstructureName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("mapstructure")
+	parts := strings.Split(tag, ",")
+	squash := false
+	for _, option := range parts[1:] {
+		if option == "squash" {
+			squash = true
+		}
+	}
+	if parts[0] == "" {
+		return normalizeKey(field.Name), squash
+	}
+	return normalizeKey(parts[0]), squash
+}
+
+// parseFieldRule parses a validate tag
+func parseFieldRule(key string, t reflect.Type, tag string) (fieldRule, error) {
+	rule := fieldRule{key: key, kind: t.Kind(), duration: t == durationType}
+	if tag == "" {
+		return rule, nil
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required":
+			rule.required = true
+		case "min", "max":
+			bound, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return rule, fmt.Errorf("%w: %s: invalid %s bound %q", ErrInvalidFormat, key, name, arg)
+			}
+			if name == "min" {
+				rule.min = &bound
+			} else {
+				rule.max = &bound
+			}
+		case "oneof":
+			rule.oneOf = strings.Fields(arg)
+		case "":
+		default:
+			return rule, fmt.Errorf("%w: %s: unknown validation rule %q", ErrInvalidFormat, key, name)
+		}
+	}
+	return rule, nil
+}
+
+// validateLocked checks the configuration the given layers produce against the
+// required keys, struct rules and JSON Schema (lock held)
+func (cm *configManagerImpl) validateLocked(layers [layerCount]map[string]interface{}) error {
+	values := mergeLayers(layers)
+	var problems []string
+
+	for _, key := range cm.requiredKeys {
+		if !hasKey(values, normalizeKey(key)) {
+			problems = append(problems, fmt.Sprintf("%s: required key is missing", key))
+		}
+	}
+
+	for _, rule := range cm.structRules {
+		problems = append(problems, rule.check(values)...)
+	}
+
+	if cm.jsonSchema != nil {
+		result, err := cm.jsonSchema.Validate(gojsonschema.NewGoLoader(nestValues(values)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("schema validation failed: %v", err))
+		} else {
+			for _, resultErr := range result.Errors() {
+				if coercibleFromText(resultErr, layers) {
+					continue
+				}
+				problems = append(problems, formatSchemaError(resultErr))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return &ValidationError{Problems: problems}
+}
+
+// check returns the problems of a rule against the configuration values
+func (r fieldRule) check(values map[string]interface{}) []string {
+	value, exists := values[r.key]
+	if !exists {
+		if r.required && !hasKey(values, r.key) {
+			return []string{fmt.Sprintf("%s: required key is missing", r.key)}
+		}
+		return nil
+	}
+
+	var problems []string
+	number, isNumber, err := r.convert(value)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", r.key, err)}
+	}
+
+	size, unit := number, ""
+	if !isNumber {
+		switch v := value.(type) {
+		case string:
+			size, unit = float64(len(v)), " characters"
+		case []interface{}:
+			size, unit = float64(len(v)), " items"
+		}
+	}
+	if isNumber || unit != "" {
+		if r.min != nil && size < *r.min {
+			problems = append(problems, fmt.Sprintf("%s: must be at least %v%s, got %v", r.key, *r.min, unit, size))
+		}
+		if r.max != nil && size > *r.max {
+			problems = append(problems, fmt.Sprintf("%s: must be at most %v%s, got %v", r.key, *r.max, unit, size))
+		}
+	}
+
+	if len(r.oneOf) > 0 {
+		text := fmt.Sprintf("%v", value)
+		allowed := false
+		for _, option := range r.oneOf {
+			if text == option {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			problems = append(problems, fmt.Sprintf("%s: must be one of %s, got %q", r.key, strings.Join(r.oneOf, ", "), text))
+		}
+	}
+	return problems
+}
+
+// convert checks that a value is convertible to the field type; numeric
+// fields also return the value as a number
+func (r fieldRule) convert(value interface{}) (float64, bool, error) {
+	if r.duration {
+		switch v := value.(type) {
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return 0, false, fmt.Errorf("expected a duration, got %q", v)
+			}
+			return float64(d), true, nil
+		case time.Duration:
+			return float64(v), true, nil
+		}
+	}
+
+	switch r.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := toNumber(value)
+		if !ok || number != float64(int64(number)) {
+			return 0, false, fmt.Errorf("expected an integer, got %#v", value)
+		}
+		return number, true, nil
+	case reflect.Float32, reflect.Float64:
+		number, ok := toNumber(value)
+		if !ok {
+			return 0, false, fmt.Errorf("expected a number, got %#v", value)
+		}
+		return number, true, nil
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+		case string:
+			if _, err := strconv.ParseBool(v); err != nil {
+				return 0, false, fmt.Errorf("expected a boolean, got %q", v)
+			}
+		default:
+			return 0, false, fmt.Errorf("expected a boolean, got %#v", value)
+		}
+	}
+	return 0, false, nil
+}
+
+// toNumber converts numeric values and numeric strings
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// hasKey reports whether a key, or a key below it, is set
+func hasKey(values map[string]interface{}, key string) bool {
+	if _, exists := values[key]; exists {
+		return true
+	}
+	for k := range values {
+		if strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// nestValues turns dot-notation keys into the nested document a JSON Schema
+// describes; deeper keys win over a scalar set on one of their parents
+func nestValues(values map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := make(map[string]interface{})
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		last := parts[len(parts)-1]
+		if _, isSection := node[last].(map[string]interface{}); !isSection {
+			node[last] = values[key]
+		}
+	}
+	return root
+}
+
+// coercibleFromText ignores type errors on values that come from the env or
+// flags layers as text but convert to the expected type ("8080" for an integer)
+func coercibleFromText(resultErr gojsonschema.ResultError, layers [layerCount]map[string]interface{}) bool {
+	if resultErr.Type() != "invalid_type" {
+		return false
+	}
+	key := resultErr.Field()
+	var value interface{}
+	found := false
+	for layer := layerCount - 1; layer >= LayerDefaults; layer-- {
+		if v, exists := layers[layer][key]; exists {
+			found = layer == LayerEnv || layer == LayerFlags
+			value = v
+			break
+		}
+	}
+	text, isText := value.(string)
+	if !found || !isText {
+		return false
+	}
+
+	switch fmt.Sprintf("%v", resultErr.Details()["expected"]) {
+	case "integer":
+		_, err := strconv.ParseInt(text, 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(text, 64)
+		return err == nil
+	case "boolean":
+		_, err := strconv.ParseBool(text)
+		return err == nil
+	}
+	return false
+}
+
+// formatSchemaError formats a JSON Schema error as "key: problem"
+func formatSchemaError(resultErr gojsonschema.ResultError) string {
+	field := resultErr.Field()
+	if field == "" || field == gojsonschema.STRING_CONTEXT_ROOT {
+		return resultErr.Description()
+	}
+	return fmt.Sprintf("%s: %s", field, resultErr.Description())
+}
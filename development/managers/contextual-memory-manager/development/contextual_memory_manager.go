@@ -12,9 +12,13 @@ import (
 	"github.com/contextual-memory-manager/internal/indexing"
 	"github.com/contextual-memory-manager/internal/integration"
 	"github.com/contextual-memory-manager/internal/monitoring"
+	"github.com/contextual-memory-manager/internal/retention"
 	"github.com/contextual-memory-manager/internal/retrieval"
 )
 
+// Intervalle par défaut entre deux passages de la rétention
+const defaultRetentionInterval = time.Hour
+
 type contextualMemoryManagerImpl struct {
 	indexManager       interfaces.IndexManager
 	retrievalManager   interfaces.RetrievalManager
@@ -24,6 +28,8 @@ type contextualMemoryManagerImpl struct {
 	hybridSelector     *hybrid.ModeSelector          // NOUVEAU
 	hybridMetrics      interfaces.HybridMetricsManager // NOUVEAU PHASE 4
 	realtimeDashboard  *monitoring.RealTimeDashboard   // NOUVEAU PHASE 4
+	retentionManager   *retention.Manager
+	stopRetention      context.CancelFunc
 	storageManager     interfaces.StorageManager
 	errorManager       interfaces.ErrorManager
 	configManager      interfaces.ConfigManager
@@ -153,6 +159,39 @@ func (cmm *contextualMemoryManagerImpl) Initialize(ctx context.Context) error {
 	}
 	cmm.integrationManager = integrationMgr
 
+	// 5. Rétention, masquage et chiffrement du contexte stocké
+	retentionMgr, err := retention.NewManager(cmm.storageManager, retention.Options{
+		EncryptionKey:    cmm.configManager.GetString("privacy.encryption_key"),
+		DisableRedaction: cmm.configManager.GetBool("privacy.disable_redaction"),
+		SummarySentences: cmm.configManager.GetInt("retention.summary_sentences"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create retention manager: %w", err)
+	}
+	cmm.retentionManager = retentionMgr
+
+	// Le texte des actions est chiffré dans les index avec la même clé
+	if cipher := retentionMgr.Cipher(); cipher != nil {
+		indexMgr.SetTextCipher(cipher)
+		retrievalMgr.SetTextCipher(cipher)
+	}
+
+	interval := time.Duration(cmm.configManager.GetInt("retention.interval_minutes")) * time.Minute
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	retentionCtx, stopRetention := context.WithCancel(context.Background())
+	cmm.stopRetention = stopRetention
+	go cmm.retentionManager.Run(retentionCtx, interval, cmm.removeFromIndexes, func(report *interfaces.RetentionReport, err error) {
+		if err != nil {
+			cmm.errorManager.LogError(retentionCtx, "contextual_memory", "Retention pass failed", err)
+			return
+		}
+		for _, message := range report.Errors {
+			cmm.errorManager.LogWarning(retentionCtx, "contextual_memory", "Retention: "+message)
+		}
+	})
+
 	cmm.initialized = true
 
 	// Enregistrer les mÃ©triques d'initialisation
@@ -202,6 +241,11 @@ func (cmm *contextualMemoryManagerImpl) Cleanup() error {
 	var errs []error
 
 	// Nettoyer dans l'ordre inverse d'initialisation
+	if cmm.stopRetention != nil {
+		cmm.stopRetention()
+		cmm.stopRetention = nil
+	}
+
 	if cmm.integrationManager != nil {
 		if err := cmm.integrationManager.Cleanup(); err != nil {
 			errs = append(errs, fmt.Errorf("integration manager cleanup: %w", err))
@@ -245,12 +289,21 @@ func (cmm *contextualMemoryManagerImpl) CaptureAction(ctx context.Context, actio
 
 	start := time.Now()
 
+	// 0. Masquer les données personnelles et les secrets avant tout stockage
+	action = cmm.retentionManager.Redactor().RedactAction(action)
+
 	// 1. Indexer l'action pour recherche future
 	if err := cmm.indexManager.IndexAction(ctx, action); err != nil {
 		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to index action", err)
 		return fmt.Errorf("failed to index action: %w", err)
 	}
 
+	// 1.5. Conserver l'action pour la rétention, l'export et l'effacement
+	if err := cmm.retentionManager.Record(ctx, action); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to record action for retention", err)
+		return fmt.Errorf("failed to record action for retention: %w", err)
+	}
+
 	// 2. Notifier les intÃ©grations (MCP Gateway, N8N)
 	if err := cmm.integrationManager.NotifyAction(ctx, action); err != nil {
 		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to notify integrations", err)
@@ -303,6 +356,10 @@ func (cmm *contextualMemoryManagerImpl) UpdateContext(ctx context.Context, conte
 
 	start := time.Now()
 
+	redactor := cmm.retentionManager.Redactor()
+	updates.Text, _ = redactor.Redact(updates.Text)
+	updates.Metadata = redactor.RedactMetadata(updates.Metadata)
+
 	// Mettre Ã  jour via le retrieval manager
 	if err := cmm.retrievalManager.UpdateContext(ctx, contextID, updates); err != nil {
 		cmm.errorManager.LogError(ctx, "contextual_memory", "Context update failed", err)
@@ -312,6 +369,12 @@ func (cmm *contextualMemoryManagerImpl) UpdateContext(ctx context.Context, conte
 		return fmt.Errorf("context update failed: %w", err)
 	}
 
+	// Répercuter la mise à jour sur l'action conservée pour la rétention
+	if err := cmm.retentionManager.Update(ctx, contextID, updates); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to update retention storage", err)
+		return fmt.Errorf("failed to update retention storage: %w", err)
+	}
+
 	// Notifier les intÃ©grations de la mise Ã  jour
 	if err := cmm.integrationManager.NotifyContextUpdate(ctx, contextID, updates); err != nil {
 		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to notify context update", err)
@@ -379,6 +442,11 @@ func (cmm *contextualMemoryManagerImpl) DeleteContext(ctx context.Context, conte
 		// Ne pas Ã©chouer la suppression pour une erreur d'index
 	}
 
+	// Supprimer du stockage de la rétention
+	if err := cmm.retentionManager.Delete(ctx, contextID); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to delete from retention storage", err)
+	}
+
 	// Notifier les intÃ©grations
 	if err := cmm.integrationManager.NotifyContextDeletion(ctx, contextID); err != nil {
 		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to notify context deletion", err)
@@ -675,13 +743,7 @@ func (cmm *contextualMemoryManagerImpl) executeASTSearchHybrid(ctx context.Conte
 	start := time.Now()
 
 	// Convertir ContextQuery en StructuralQuery
-	structuralQuery := interfaces.StructuralQuery{
-		Type:          "any",
-		Name:          cmm.extractNameFromQuery(query.Text),
-		Package:       cmm.extractPackageFromQuery(query.Text),
-		WorkspacePath: query.WorkspacePath,
-		Limit:         query.Limit,
-	}
+	structuralQuery := cmm.toStructuralQuery(query.Text, query)
 
 	// Recherche structurelle via AST
 	structuralResults, err := cmm.astManager.SearchByStructure(ctx, structuralQuery)
@@ -886,17 +948,20 @@ func (cmm *contextualMemoryManagerImpl) executeParallelSearch(ctx context.Contex
 // Méthodes utilitaires
 
 func (cmm *contextualMemoryManagerImpl) executeASTSearchOnly(ctx context.Context, query interfaces.ContextQuery) ([]interfaces.StructuralResult, error) {
-	structuralQuery := interfaces.StructuralQuery{
-		Type:          "any",
-		Name:          cmm.extractNameFromQuery(query.Query),
-		Package:       cmm.extractPackageFromQuery(query.Query),
-		WorkspacePath: query.WorkspacePath,
-		Limit:         query.Limit,
-	}
+	structuralQuery := cmm.toStructuralQuery(query.Query, query)
 
 	return cmm.astManager.SearchByStructure(ctx, structuralQuery)
 }
 
+// toStructuralQuery convertit le texte d'une ContextQuery en requête structurelle
+// ("functions returning error that take a context", "types implementing interfaces.Manager")
+func (cmm *contextualMemoryManagerImpl) toStructuralQuery(text string, query interfaces.ContextQuery) interfaces.StructuralQuery {
+	structuralQuery := ast.ParseStructuralQuery(text)
+	structuralQuery.WorkspacePath = query.WorkspacePath
+	structuralQuery.Limit = query.Limit
+	return structuralQuery
+}
+
 func (cmm *contextualMemoryManagerImpl) extractNameFromQuery(query string) string {
 	// Extraction simple du nom depuis la requête
 	// Pour une implémentation plus sophistiquée, utiliser NLP
@@ -1278,13 +1343,7 @@ func (cmm *contextualMemoryManagerImpl) GetSupportedModes(ctx context.Context) (
 
 func (cmm *contextualMemoryManagerImpl) executeASTSearchContext(ctx context.Context, query interfaces.ContextQuery) ([]interfaces.ContextResult, error) {
 	// Convertir ContextQuery en StructuralQuery
-	structuralQuery := interfaces.StructuralQuery{
-		Type:          "any",
-		Name:          cmm.extractNameFromQuery(query.Text),
-		Package:       cmm.extractPackageFromQuery(query.Text),
-		WorkspacePath: query.WorkspacePath,
-		Limit:         query.Limit,
-	}
+	structuralQuery := cmm.toStructuralQuery(query.Text, query)
 
 	// Recherche structurelle via AST
 	structuralResults, err := cmm.astManager.SearchByStructure(ctx, structuralQuery)
@@ -1619,3 +1678,99 @@ func (cmm *contextualMemoryManagerImpl) UpdateHybridConfig(ctx context.Context,
 
 	return nil
 }
+
+// RÉTENTION ET CONFIDENTIALITÉ
+
+// SetRetentionPolicies remplace les politiques de rétention par workspace et type d'action
+func (cmm *contextualMemoryManagerImpl) SetRetentionPolicies(ctx context.Context, policies []interfaces.RetentionPolicy) error {
+	cmm.mu.RLock()
+	defer cmm.mu.RUnlock()
+
+	if !cmm.initialized {
+		return fmt.Errorf("manager not initialized")
+	}
+
+	return cmm.retentionManager.SetPolicies(policies)
+}
+
+// ApplyRetention supprime immédiatement les actions expirées, après avoir
+// résumé les sessions concernées
+func (cmm *contextualMemoryManagerImpl) ApplyRetention(ctx context.Context) (*interfaces.RetentionReport, error) {
+	cmm.mu.RLock()
+	defer cmm.mu.RUnlock()
+
+	if !cmm.initialized {
+		return nil, fmt.Errorf("manager not initialized")
+	}
+
+	start := time.Now()
+	report, err := cmm.retentionManager.Apply(ctx, start, cmm.removeFromIndexes)
+	if err := cmm.monitoringManager.RecordOperation(ctx, "apply_retention", time.Since(start), err); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to record retention metrics", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply retention: %w", err)
+	}
+	return report, nil
+}
+
+// GetSessionSummaries renvoie les résumés des sessions expirées d'un workspace
+func (cmm *contextualMemoryManagerImpl) GetSessionSummaries(ctx context.Context, workspacePath string) ([]interfaces.SessionSummary, error) {
+	cmm.mu.RLock()
+	defer cmm.mu.RUnlock()
+
+	if !cmm.initialized {
+		return nil, fmt.Errorf("manager not initialized")
+	}
+
+	return cmm.retentionManager.Summaries(ctx, workspacePath)
+}
+
+// ExportUserData exporte les actions et les résumés stockés pour un utilisateur
+func (cmm *contextualMemoryManagerImpl) ExportUserData(ctx context.Context, userID string) (*interfaces.UserDataExport, error) {
+	cmm.mu.RLock()
+	defer cmm.mu.RUnlock()
+
+	if !cmm.initialized {
+		return nil, fmt.Errorf("manager not initialized")
+	}
+
+	return cmm.retentionManager.Export(ctx, userID)
+}
+
+// ForgetUser efface toutes les données d'un utilisateur ("forget me") des
+// index, de la base et du stockage de la rétention
+func (cmm *contextualMemoryManagerImpl) ForgetUser(ctx context.Context, userID string) (int, error) {
+	cmm.mu.RLock()
+	defer cmm.mu.RUnlock()
+
+	if !cmm.initialized {
+		return 0, fmt.Errorf("manager not initialized")
+	}
+
+	start := time.Now()
+	deleted, err := cmm.retentionManager.Forget(ctx, userID, cmm.removeFromIndexes)
+	if err := cmm.monitoringManager.RecordOperation(ctx, "forget_user", time.Since(start), err); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to record forget metrics", err)
+	}
+	if err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to forget user data", err)
+		return deleted, fmt.Errorf("failed to forget user %s: %w", userID, err)
+	}
+	return deleted, nil
+}
+
+// removeFromIndexes supprime une action de la base et de l'index vectoriel
+// puis notifie les intégrations ; appelée par la rétention
+func (cmm *contextualMemoryManagerImpl) removeFromIndexes(ctx context.Context, actionID string) error {
+	if err := cmm.retrievalManager.DeleteContext(ctx, actionID); err != nil {
+		return fmt.Errorf("failed to delete context: %w", err)
+	}
+	if err := cmm.indexManager.DeleteFromIndex(ctx, actionID); err != nil {
+		return fmt.Errorf("failed to delete from index: %w", err)
+	}
+	if err := cmm.integrationManager.NotifyContextDeletion(ctx, actionID); err != nil {
+		cmm.errorManager.LogError(ctx, "contextual_memory", "Failed to notify context deletion", err)
+	}
+	return nil
+}
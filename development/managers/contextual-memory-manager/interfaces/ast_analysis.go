@@ -57,12 +57,14 @@ type WorkspaceAnalysis struct {
 }
 
 type StructuralQuery struct {
-	Type          string          `json:"type"` // function, type, variable, import
+	Type          string          `json:"type"` // function, method, type, struct, interface, class, enum, import, any
 	Name          string          `json:"name,omitempty"`
 	Package       string          `json:"package,omitempty"`
 	Signature     string          `json:"signature,omitempty"`
 	ReturnType    string          `json:"return_type,omitempty"`
 	Parameters    []ParameterInfo `json:"parameters,omitempty"`
+	Implements    string          `json:"implements,omitempty"` // interface implémentée, ex. "interfaces.Manager"
+	Language      string          `json:"language,omitempty"`   // go, typescript, javascript, python, powershell
 	WorkspacePath string          `json:"workspace_path,omitempty"`
 	IncludeUsages bool            `json:"include_usages"`
 	Limit         int             `json:"limit,omitempty"`
@@ -84,8 +86,9 @@ type FunctionInfo struct {
 
 type TypeInfo struct {
 	Name          string         `json:"name"`
-	Kind          string         `json:"kind"` // struct, interface, type alias
+	Kind          string         `json:"kind"` // struct, interface, type alias, class, enum
 	Package       string         `json:"package"`
+	BaseTypes     []string       `json:"base_types,omitempty"` // classes et interfaces étendues ou implémentées (hors Go)
 	Fields        []FieldInfo    `json:"fields,omitempty"`
 	Methods       []FunctionInfo `json:"methods,omitempty"`
 	IsExported    bool           `json:"is_exported"`
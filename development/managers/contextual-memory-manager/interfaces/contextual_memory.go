@@ -115,6 +115,13 @@ type ContextualMemoryManager interface {
 
 	// MÃ©triques
 	GetMetrics(ctx context.Context) (ManagerMetrics, error)
+
+	// Rétention et confidentialité
+	SetRetentionPolicies(ctx context.Context, policies []RetentionPolicy) error
+	ApplyRetention(ctx context.Context) (*RetentionReport, error)
+	GetSessionSummaries(ctx context.Context, workspacePath string) ([]SessionSummary, error)
+	ExportUserData(ctx context.Context, userID string) (*UserDataExport, error)
+	ForgetUser(ctx context.Context, userID string) (int, error)
 	// MÃ©thodes existantes
 	RecordAction(ctx context.Context, action Action) error
 	SearchSimilarActions(ctx context.Context, query ContextQuery) ([]ContextResult, error)
@@ -0,0 +1,60 @@
+// interfaces/retention.go
+package interfaces
+
+import "time"
+
+// Clés de métadonnées d'une Action utilisées par la rétention et l'export
+const (
+	MetadataUserID    = "user_id"
+	MetadataSessionID = "session_id"
+)
+
+// TextCipher chiffre le texte des actions stocké dans les index de
+// recherche ; associated lie le chiffré à l'action
+type TextCipher interface {
+	SealText(text, associated string) (string, error)
+	OpenText(text, associated string) (string, error)
+}
+
+// RetentionPolicy définit la durée de conservation des actions d'un
+// workspace ou d'un type d'action. La politique la plus spécifique
+// s'applique ; une action sans politique est conservée.
+type RetentionPolicy struct {
+	WorkspacePath string        `json:"workspace_path,omitempty"` // chemin ou motif filepath.Match, vide pour tous
+	ActionType    string        `json:"action_type,omitempty"`    // vide pour tous les types
+	MaxAge        time.Duration `json:"max_age,omitempty"`        // 0 : pas d'expiration par l'âge
+	MaxActions    int           `json:"max_actions,omitempty"`    // par workspace, 0 : pas de limite
+	Summarize     bool          `json:"summarize"`                // résumer les sessions expirées avant suppression
+}
+
+// SessionSummary est le résumé extractif d'une session expirée
+type SessionSummary struct {
+	ID            string         `json:"id"`
+	SessionID     string         `json:"session_id"` // workspace@date pour les actions hors session
+	UserID        string         `json:"user_id,omitempty"`
+	WorkspacePath string         `json:"workspace_path"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	ActionCount   int            `json:"action_count"`
+	ActionTypes   map[string]int `json:"action_types"`
+	Files         []string       `json:"files,omitempty"` // fichiers les plus touchés
+	Highlights    []string       `json:"highlights"`      // phrases extraites, dans l'ordre chronologique
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// RetentionReport résume un passage de la rétention
+type RetentionReport struct {
+	Scanned    int       `json:"scanned"`
+	Deleted    int       `json:"deleted"`
+	Summarized int       `json:"summarized"` // sessions résumées ou complétées
+	Errors     []string  `json:"errors,omitempty"`
+	RunAt      time.Time `json:"run_at"`
+}
+
+// UserDataExport regroupe tout ce qui est stocké pour un utilisateur
+type UserDataExport struct {
+	UserID     string           `json:"user_id"`
+	Actions    []Action         `json:"actions"`
+	Summaries  []SessionSummary `json:"summaries"`
+	ExportedAt time.Time        `json:"exported_at"`
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
@@ -27,6 +26,14 @@ type astAnalysisManagerImpl struct {
 	workerPool  *WorkerPool
 	initialized bool
 	mu          sync.RWMutex
+
+	// Index structurels par racine de workspace
+	indexes map[string]*structuralIndex
+	indexMu sync.Mutex
+
+	// Frontends par extension de fichier
+	frontends  map[string]LanguageFrontend
+	frontendMu sync.RWMutex
 }
 
 // NewASTAnalysisManager crée une nouvelle instance
@@ -36,7 +43,7 @@ func NewASTAnalysisManager(
 	configManager interfaces.ConfigManager,
 	monitoringManager interfaces.MonitoringManager,
 ) (interfaces.ASTAnalysisManager, error) {
-	return &astAnalysisManagerImpl{
+	asm := &astAnalysisManagerImpl{
 		storageManager:    storageManager,
 		errorManager:      errorManager,
 		configManager:     configManager,
@@ -44,7 +51,15 @@ func NewASTAnalysisManager(
 		cache:             NewASTCache(1000, 5*time.Minute),
 		fileSet:           token.NewFileSet(),
 		workerPool:        NewWorkerPool(4),
-	}, nil
+		indexes:           make(map[string]*structuralIndex),
+		frontends:         make(map[string]LanguageFrontend),
+	}
+
+	asm.RegisterFrontend(&goFrontend{asm: asm})
+	for _, frontend := range DefaultFrontends() {
+		asm.RegisterFrontend(frontend)
+	}
+	return asm, nil
 }
 
 func (asm *astAnalysisManagerImpl) Initialize(ctx context.Context) error {
@@ -133,32 +148,30 @@ func (asm *astAnalysisManagerImpl) AnalyzeFile(ctx context.Context, filePath str
 		asm.errorManager.LogError(ctx, "ast_analyzer", "Failed to record cache miss", err)
 	}
 
-	// Parser le fichier Go
+	// Choisir le frontend du langage
+	frontend := asm.frontendFor(filePath)
+	if frontend == nil {
+		return nil, fmt.Errorf("unsupported language for file %s", filePath)
+	}
+
 	src, err := asm.readFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
 
-	file, err := parser.ParseFile(asm.fileSet, filePath, src, parser.ParseComments)
+	// Analyser l'AST
+	result, err := frontend.Parse(filePath, src)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+		return nil, err
 	}
-
-	// Analyser l'AST
-	result := &interfaces.ASTAnalysisResult{
-		FilePath:         filePath,
-		Package:          file.Name.Name,
-		Imports:          asm.extractImports(file),
-		Functions:        asm.extractFunctions(file),
-		Types:            asm.extractTypes(file),
-		Variables:        asm.extractVariables(file),
-		Constants:        asm.extractConstants(file),
-		Dependencies:     asm.extractDependencies(file),
-		Complexity:       asm.calculateComplexity(file),
-		Context:          asm.buildContext(file),
-		Timestamp:        time.Now(),
-		AnalysisDuration: time.Since(start),
+	if result.Context == nil {
+		result.Context = make(map[string]interface{})
 	}
+	if _, ok := result.Context["language"]; !ok {
+		result.Context["language"] = frontend.Language()
+	}
+	result.Timestamp = time.Now()
+	result.AnalysisDuration = time.Since(start)
 
 	// Mettre en cache
 	asm.cache.Set(filePath, result)
@@ -182,13 +195,17 @@ func (asm *astAnalysisManagerImpl) AnalyzeWorkspace(ctx context.Context, workspa
 		},
 	}
 
-	// Traverser le workspace et analyser les fichiers Go
+	// Traverser le workspace et analyser les fichiers des langages pris en charge
 	err := filepath.Walk(workspacePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+		if info.IsDir() && path != workspacePath && skippedDirectory(info.Name()) {
+			return filepath.SkipDir
+		}
+
+		if !info.IsDir() && asm.frontendFor(path) != nil {
 			fileResult, err := asm.AnalyzeFile(ctx, path)
 			if err != nil {
 				asm.errorManager.LogError(ctx, "ast_analyzer", fmt.Sprintf("Failed to analyze file %s", path), err)
@@ -230,8 +247,8 @@ func (asm *astAnalysisManagerImpl) EnrichContextWithAST(ctx context.Context, act
 		Timestamp:      time.Now(),
 	}
 
-	// Si l'action concerne un fichier Go, l'analyser
-	if action.FilePath != "" && filepath.Ext(action.FilePath) == ".go" {
+	// Si l'action concerne un fichier d'un langage pris en charge, l'analyser
+	if action.FilePath != "" && asm.frontendFor(action.FilePath) != nil {
 		astResult, err := asm.AnalyzeFile(ctx, action.FilePath)
 		if err != nil {
 			asm.errorManager.LogError(ctx, "ast_analyzer", "Failed to analyze file for context enrichment", err)
@@ -250,6 +267,7 @@ func (asm *astAnalysisManagerImpl) EnrichContextWithAST(ctx context.Context, act
 
 		// Enrichir avec les informations contextuelles
 		enriched.ASTContext["package"] = astResult.Package
+		enriched.ASTContext["language"] = astResult.Context["language"]
 		enriched.ASTContext["function_count"] = len(astResult.Functions)
 		enriched.ASTContext["type_count"] = len(astResult.Types)
 		enriched.ASTContext["complexity"] = astResult.Complexity
@@ -461,6 +479,12 @@ func (asm *astAnalysisManagerImpl) extractConstants(file *ast.File) []interfaces
 }
 
 // Méthodes utilitaires
+
+// skippedDirectory exclut les dépendances installées et les répertoires cachés
+func skippedDirectory(name string) bool {
+	return name == "node_modules" || name == "__pycache__" || name == "vendor" || strings.HasPrefix(name, ".")
+}
+
 func (asm *astAnalysisManagerImpl) readFile(filePath string) ([]byte, error) {
 	return os.ReadFile(filePath)
 }
@@ -484,29 +508,16 @@ func (asm *astAnalysisManagerImpl) isStandardPackage(path string) bool {
 	return false
 }
 
-// Méthodes non encore implémentées (placeholders)
-func (asm *astAnalysisManagerImpl) TraverseFileSystem(ctx context.Context, rootPath string, filters interfaces.TraversalFilters) (*interfaces.FileSystemGraph, error) {
-	return nil, fmt.Errorf("not implemented yet")
-}
-
-func (asm *astAnalysisManagerImpl) MapDependencies(ctx context.Context, filePath string) (*interfaces.DependencyGraph, error) {
-	return nil, fmt.Errorf("not implemented yet")
-}
-
-func (asm *astAnalysisManagerImpl) SearchByStructure(ctx context.Context, query interfaces.StructuralQuery) ([]interfaces.StructuralResult, error) {
-	return nil, fmt.Errorf("not implemented yet")
-}
-
-func (asm *astAnalysisManagerImpl) GetSimilarStructures(ctx context.Context, referenceFile string, limit int) ([]interfaces.StructuralMatch, error) {
-	return nil, fmt.Errorf("not implemented yet")
-}
-
 func (asm *astAnalysisManagerImpl) GetCacheStats(ctx context.Context) (*interfaces.ASTCacheStats, error) {
 	return asm.cache.GetStats(), nil
 }
 
 func (asm *astAnalysisManagerImpl) ClearCache(ctx context.Context) error {
 	asm.cache.Clear()
+
+	asm.indexMu.Lock()
+	asm.indexes = make(map[string]*structuralIndex)
+	asm.indexMu.Unlock()
 	return nil
 }
 
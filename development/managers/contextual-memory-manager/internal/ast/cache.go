@@ -139,6 +139,19 @@ func (c *ASTCache) Clear() {
 	c.stats.mu.Unlock()
 }
 
+// Delete invalide l'analyse d'un fichier modifié
+func (c *ASTCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; exists {
+		delete(c.entries, key)
+		c.stats.mu.Lock()
+		c.stats.evictions++
+		c.stats.mu.Unlock()
+	}
+}
+
 func (c *ASTCache) Size() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -0,0 +1,103 @@
+// internal/ast/frontend.go
+package ast
+
+import (
+	"fmt"
+	"go/parser"
+	"path/filepath"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// LanguageFrontend analyse les fichiers d'un langage et produit le même
+// ASTAnalysisResult que l'analyse Go, pour que l'enrichissement de contexte
+// et la recherche hybride couvrent tout le workspace
+type LanguageFrontend interface {
+	// Language retourne le nom du langage ("go", "typescript", "python"...)
+	Language() string
+	// Extensions retourne les extensions prises en charge, avec le point
+	Extensions() []string
+	// Parse analyse le contenu d'un fichier ; Timestamp et AnalysisDuration
+	// sont renseignés par le manager
+	Parse(filePath string, src []byte) (*interfaces.ASTAnalysisResult, error)
+}
+
+// FrontendRegistry est implémenté par le manager AST pour brancher des
+// frontends supplémentaires
+type FrontendRegistry interface {
+	RegisterFrontend(frontend LanguageFrontend)
+	Frontends() []LanguageFrontend
+}
+
+// DefaultFrontends retourne les frontends hors Go fournis avec le manager
+func DefaultFrontends() []LanguageFrontend {
+	return []LanguageFrontend{
+		&typeScriptFrontend{},
+		&pythonFrontend{},
+		&powerShellFrontend{},
+	}
+}
+
+// RegisterFrontend associe un frontend à ses extensions ; un frontend
+// enregistré plus tard remplace le précédent pour la même extension
+func (asm *astAnalysisManagerImpl) RegisterFrontend(frontend LanguageFrontend) {
+	asm.frontendMu.Lock()
+	defer asm.frontendMu.Unlock()
+
+	for _, extension := range frontend.Extensions() {
+		asm.frontends[strings.ToLower(extension)] = frontend
+	}
+}
+
+func (asm *astAnalysisManagerImpl) Frontends() []LanguageFrontend {
+	asm.frontendMu.RLock()
+	defer asm.frontendMu.RUnlock()
+
+	seen := make(map[string]bool)
+	frontends := make([]LanguageFrontend, 0, len(asm.frontends))
+	for _, frontend := range asm.frontends {
+		if !seen[frontend.Language()] {
+			seen[frontend.Language()] = true
+			frontends = append(frontends, frontend)
+		}
+	}
+	return frontends
+}
+
+// frontendFor retourne le frontend du fichier, nil pour un langage non pris en charge
+func (asm *astAnalysisManagerImpl) frontendFor(filePath string) LanguageFrontend {
+	asm.frontendMu.RLock()
+	defer asm.frontendMu.RUnlock()
+
+	return asm.frontends[strings.ToLower(filepath.Ext(filePath))]
+}
+
+// goFrontend analyse le Go avec go/parser
+type goFrontend struct {
+	asm *astAnalysisManagerImpl
+}
+
+func (f *goFrontend) Language() string     { return "go" }
+func (f *goFrontend) Extensions() []string { return []string{".go"} }
+
+func (f *goFrontend) Parse(filePath string, src []byte) (*interfaces.ASTAnalysisResult, error) {
+	asm := f.asm
+	file, err := parser.ParseFile(asm.fileSet, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+	}
+
+	return &interfaces.ASTAnalysisResult{
+		FilePath:     filePath,
+		Package:      file.Name.Name,
+		Imports:      asm.extractImports(file),
+		Functions:    asm.extractFunctions(file),
+		Types:        asm.extractTypes(file),
+		Variables:    asm.extractVariables(file),
+		Constants:    asm.extractConstants(file),
+		Dependencies: asm.extractDependencies(file),
+		Complexity:   asm.calculateComplexity(file),
+		Context:      asm.buildContext(file),
+	}, nil
+}
@@ -0,0 +1,918 @@
+// internal/ast/index.go
+package ast
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// Mode de chargement go/packages : syntaxe et informations de types des
+// packages du workspace, les dépendances étant lues depuis leurs données
+// d'export
+const indexLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo
+
+// structuralIndex indexe les fonctions, méthodes, types et appels des
+// packages d'un workspace. Il est rafraîchi de manière incrémentale : seuls
+// les packages dont un fichier a été modifié, ajouté ou supprimé, et leurs
+// dépendants, sont rechargés. Les fichiers des autres langages (TypeScript,
+// Python, PowerShell...) sont analysés un par un par leur frontend.
+type structuralIndex struct {
+	root string
+	asm  *astAnalysisManagerImpl
+
+	packages map[string]*indexedPackage // répertoire -> package
+	files    map[string]*indexedFile    // chemin absolu -> fichier
+	// symboles des fichiers hors Go, par chemin absolu
+	documents map[string][]*indexedSymbol
+	// interfaces des packages importés (io.Reader, context.Context...)
+	external map[string]*indexedSymbol
+	loaded   int // packages chargés depuis la création
+	mu       sync.RWMutex
+	reload   sync.Mutex
+}
+
+type indexedPackage struct {
+	Path    string
+	Name    string
+	Dir     string
+	Files   map[string]bool
+	Symbols []*indexedSymbol
+	Calls   []callEdge
+}
+
+type indexedFile struct {
+	Path     string
+	Language string
+	// chemin d'import, vide pour un fichier exclu du build ; hors Go, le
+	// répertoire relatif à la racine
+	Package string
+	ModTime time.Time
+	Size    int64
+	Hash    [sha256.Size]byte
+	Imports []interfaces.ImportInfo
+}
+
+// indexedSymbol est une fonction, une méthode ou un type du workspace
+type indexedSymbol struct {
+	// "chemin/pkg.Nom" ou "chemin/pkg.Type.Methode" ; hors Go,
+	// "rep/fichier.ts:Nom" ou "rep/fichier.ts:Classe.Methode"
+	ID          string
+	Kind        string // function, method, type
+	Language    string
+	Owner       string // type d'une méthode hors Go
+	Name        string
+	Receiver    string
+	Package     string
+	PackageName string
+	FilePath    string
+	Line        int
+	Function    *interfaces.FunctionInfo
+	Type        *interfaces.TypeInfo
+	// Shape est la signature sans noms de paramètres, ex. "func(context.Context) error"
+	Shape string
+	// MethodSet associe chaque méthode (de *T, ou de l'interface) à sa signature
+	MethodSet map[string]string
+}
+
+// callEdge est un appel d'une fonction du workspace vers une fonction ou
+// une méthode, éventuellement externe
+type callEdge struct {
+	From      string
+	To        string
+	ToPackage string
+	FilePath  string
+	Line      int
+}
+
+func newStructuralIndex(root string, asm *astAnalysisManagerImpl) *structuralIndex {
+	return &structuralIndex{
+		root:      root,
+		asm:       asm,
+		packages:  make(map[string]*indexedPackage),
+		files:     make(map[string]*indexedFile),
+		documents: make(map[string][]*indexedSymbol),
+		external:  make(map[string]*indexedSymbol),
+	}
+}
+
+// Refresh recharge les packages dont les fichiers ont changé depuis le
+// dernier chargement, ainsi que ceux qui en dépendent, et retourne les
+// fichiers modifiés. Le premier appel charge tout le workspace.
+func (idx *structuralIndex) Refresh(ctx context.Context) ([]string, error) {
+	idx.reload.Lock()
+	defer idx.reload.Unlock()
+
+	current, err := idx.scanSources()
+	if err != nil {
+		return nil, err
+	}
+	documents := make(map[string]fs.FileInfo)
+	for path, info := range current {
+		if !isGoSource(path) {
+			documents[path] = info
+			delete(current, path)
+		}
+	}
+	changedDocuments := idx.refreshDocuments(documents)
+
+	idx.mu.RLock()
+	initial := true
+	for path := range idx.files {
+		if isGoSource(path) {
+			initial = false
+			break
+		}
+	}
+	dirty := make(map[string]bool)
+	var changed, touched []string
+	for path, known := range idx.files {
+		if !isGoSource(path) {
+			continue
+		}
+		info, found := current[path]
+		if !found {
+			dirty[filepath.Dir(path)] = true
+			changed = append(changed, path)
+			continue
+		}
+		if info.ModTime().Equal(known.ModTime) && info.Size() == known.Size {
+			continue
+		}
+		if hash, err := hashFile(path); err != nil || hash != known.Hash {
+			dirty[filepath.Dir(path)] = true
+			changed = append(changed, path)
+		} else {
+			touched = append(touched, path)
+		}
+	}
+	for path := range current {
+		if _, known := idx.files[path]; !known {
+			dirty[filepath.Dir(path)] = true
+			changed = append(changed, path)
+		}
+	}
+	if !initial {
+		idx.addDependents(dirty)
+	}
+	idx.mu.RUnlock()
+
+	// Contenu inchangé : seule la date est mise à jour pour éviter de
+	// recalculer l'empreinte au prochain rafraîchissement
+	if len(touched) > 0 {
+		idx.mu.Lock()
+		for _, path := range touched {
+			idx.files[path].ModTime = current[path].ModTime()
+		}
+		idx.mu.Unlock()
+	}
+	if len(dirty) == 0 {
+		return changedDocuments, nil
+	}
+
+	patterns := []string{"./..."}
+	if !initial {
+		patterns = patterns[:0]
+		for dir := range dirty {
+			if !hasSources(current, dir) {
+				continue
+			}
+			rel, err := filepath.Rel(idx.root, dir)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, "./"+filepath.ToSlash(rel))
+		}
+		sort.Strings(patterns)
+	}
+
+	var loaded []*indexedPackage
+	external := make(map[string]*indexedSymbol)
+	if len(patterns) > 0 {
+		if loaded, err = idx.load(ctx, patterns, external); err != nil {
+			return nil, err
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for dir := range dirty {
+		delete(idx.packages, dir)
+	}
+	for path := range idx.files {
+		if isGoSource(path) && (dirty[filepath.Dir(path)] || initial) {
+			delete(idx.files, path)
+		}
+	}
+	packageOf := make(map[string]*indexedPackage)
+	for _, pkg := range loaded {
+		idx.packages[pkg.Dir] = pkg
+		packageOf[pkg.Dir] = pkg
+	}
+	for id, iface := range external {
+		idx.external[id] = iface
+	}
+	idx.loaded += len(loaded)
+
+	// Tous les fichiers des répertoires rechargés sont enregistrés, y compris
+	// ceux exclus par des contraintes de build, pour ne pas les recharger à
+	// chaque rafraîchissement
+	for path, info := range current {
+		dir := filepath.Dir(path)
+		if !initial && !dirty[dir] {
+			continue
+		}
+		file := &indexedFile{Path: path, Language: "go", ModTime: info.ModTime(), Size: info.Size()}
+		file.Hash, _ = hashFile(path)
+		if pkg := packageOf[dir]; pkg != nil && pkg.Files[path] {
+			file.Package = pkg.Path
+		}
+		idx.files[path] = file
+	}
+	for _, pkg := range loaded {
+		idx.recordImports(pkg)
+	}
+
+	if initial {
+		return changedDocuments, nil
+	}
+	changed = append(changed, changedDocuments...)
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// addDependents ajoute à dirty les répertoires des packages qui importent,
+// directement ou non, un package de dirty : leurs types et leurs appels
+// désignent ceux du package modifié. L'appelant détient idx.mu.
+func (idx *structuralIndex) addDependents(dirty map[string]bool) {
+	importers := make(map[string]map[string]bool) // chemin d'import -> répertoires
+	for path, file := range idx.files {
+		if !isGoSource(path) || file.Package == "" {
+			continue
+		}
+		for _, imp := range file.Imports {
+			if importers[imp.Path] == nil {
+				importers[imp.Path] = make(map[string]bool)
+			}
+			importers[imp.Path][filepath.Dir(path)] = true
+		}
+	}
+
+	queue := make([]string, 0, len(dirty))
+	for dir := range dirty {
+		queue = append(queue, dir)
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		pkg := idx.packages[dir]
+		if pkg == nil {
+			continue
+		}
+		for importer := range importers[pkg.Path] {
+			if !dirty[importer] {
+				dirty[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+}
+
+func isGoSource(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+// refreshDocuments réanalyse un par un les fichiers hors Go modifiés,
+// ajoutés ou supprimés et retourne leurs chemins ; leurs symboles ne
+// dépendent pas des autres fichiers. Seul l'appelant de Refresh modifie
+// l'index, les lectures se font donc sans verrou.
+func (idx *structuralIndex) refreshDocuments(sources map[string]fs.FileInfo) []string {
+	initial := len(idx.documents) == 0
+
+	var changed, touched []string
+	parsed := make(map[string]*indexedFile)
+	symbols := make(map[string][]*indexedSymbol)
+	for path, info := range sources {
+		known := idx.files[path]
+		if known != nil && info.ModTime().Equal(known.ModTime) && info.Size() == known.Size {
+			continue
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			continue
+		}
+		if known != nil && hash == known.Hash {
+			touched = append(touched, path)
+			continue
+		}
+		parsed[path], symbols[path] = idx.indexDocument(path, info, hash)
+		changed = append(changed, path)
+	}
+	var removed []string
+	for path := range idx.documents {
+		if _, found := sources[path]; !found {
+			removed = append(removed, path)
+		}
+	}
+	if len(changed) == 0 && len(touched) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	idx.mu.Lock()
+	for _, path := range touched {
+		idx.files[path].ModTime = sources[path].ModTime()
+	}
+	for path, file := range parsed {
+		idx.files[path] = file
+		idx.documents[path] = symbols[path]
+	}
+	for _, path := range removed {
+		delete(idx.files, path)
+		delete(idx.documents, path)
+	}
+	idx.mu.Unlock()
+
+	if initial {
+		return nil
+	}
+	changed = append(changed, removed...)
+	sort.Strings(changed)
+	return changed
+}
+
+// indexDocument analyse un fichier hors Go avec son frontend ; un fichier
+// illisible reste enregistré, sans symboles, jusqu'à sa prochaine modification
+func (idx *structuralIndex) indexDocument(path string, info fs.FileInfo, hash [sha256.Size]byte) (*indexedFile, []*indexedSymbol) {
+	file := &indexedFile{Path: path, ModTime: info.ModTime(), Size: info.Size(), Hash: hash}
+	frontend := idx.asm.frontendFor(path)
+	if frontend == nil {
+		return file, nil
+	}
+	file.Language = frontend.Language()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return file, nil
+	}
+	result, err := frontend.Parse(path, src)
+	if err != nil {
+		return file, nil
+	}
+	if language, ok := result.Context["language"].(string); ok {
+		file.Language = language
+	}
+
+	rel, _ := filepath.Rel(idx.root, path)
+	relFile := filepath.ToSlash(rel)
+	file.Package = filepath.ToSlash(filepath.Dir(rel))
+	file.Imports = result.Imports
+
+	var symbols []*indexedSymbol
+	for i := range result.Functions {
+		function := &result.Functions[i]
+		symbol := &indexedSymbol{
+			ID:          relFile + ":" + function.Name,
+			Kind:        "function",
+			Language:    file.Language,
+			Name:        function.Name,
+			Package:     file.Package,
+			PackageName: result.Package,
+			FilePath:    path,
+			Line:        function.LineStart,
+			Function:    function,
+			Shape:       functionShape(*function),
+		}
+		if receiver := function.Annotations["receiver"]; receiver != "" {
+			symbol.Kind = "method"
+			symbol.Receiver = receiver
+			symbol.Owner = relFile + ":" + receiver
+			symbol.ID = symbol.Owner + "." + function.Name
+		}
+		symbols = append(symbols, symbol)
+	}
+	for i := range result.Types {
+		typeInfo := &result.Types[i]
+		symbols = append(symbols, &indexedSymbol{
+			ID:          relFile + ":" + typeInfo.Name,
+			Kind:        "type",
+			Language:    file.Language,
+			Name:        typeInfo.Name,
+			Package:     file.Package,
+			PackageName: result.Package,
+			FilePath:    path,
+			Line:        typeInfo.LineStart,
+			Type:        typeInfo,
+		})
+	}
+	return file, symbols
+}
+
+// scanSources liste les fichiers Go (hors tests) que go list considère et
+// les fichiers des autres langages pris en charge : les répertoires
+// testdata, vendor, node_modules, cachés et les modules imbriqués sont
+// ignorés, ainsi que le Go des répertoires commençant par "_"
+func (idx *structuralIndex) scanSources() (map[string]fs.FileInfo, error) {
+	sources := make(map[string]fs.FileInfo)
+	err := filepath.WalkDir(idx.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path == idx.root {
+				return nil
+			}
+			if name == "testdata" || skippedDirectory(name) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if isGoSource(name) {
+			rel, _ := filepath.Rel(idx.root, path)
+			if strings.HasSuffix(name, "_test.go") || strings.HasPrefix(name, "_") ||
+				strings.Contains("/"+filepath.ToSlash(rel), "/_") {
+				return nil
+			}
+		} else if idx.asm.frontendFor(path) == nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		sources[path] = info
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace %s: %w", idx.root, err)
+	}
+	return sources, nil
+}
+
+func hasSources(sources map[string]fs.FileInfo, dir string) bool {
+	for path := range sources {
+		if filepath.Dir(path) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// load charge les packages via go/packages et construit leurs symboles
+func (idx *structuralIndex) load(ctx context.Context, patterns []string, external map[string]*indexedSymbol) ([]*indexedPackage, error) {
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    indexLoadMode,
+		Dir:     idx.root,
+		Fset:    fset,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages in %s: %w", idx.root, err)
+	}
+
+	var loaded []*indexedPackage
+	for _, pkg := range pkgs {
+		if pkg.Types == nil || len(pkg.Syntax) == 0 {
+			continue
+		}
+		indexed := idx.indexPackage(fset, pkg)
+		if indexed.Dir == "" || !strings.HasPrefix(indexed.Dir, idx.root) {
+			continue
+		}
+		loaded = append(loaded, indexed)
+		collectInterfaces(pkg.Types, external)
+	}
+	return loaded, nil
+}
+
+func (idx *structuralIndex) indexPackage(fset *token.FileSet, pkg *packages.Package) *indexedPackage {
+	indexed := &indexedPackage{Path: pkg.PkgPath, Name: pkg.Name, Files: make(map[string]bool)}
+	for _, file := range pkg.GoFiles {
+		indexed.Files[file] = true
+	}
+	info := pkg.TypesInfo
+	qualifier := func(other *types.Package) string {
+		if other == pkg.Types {
+			return ""
+		}
+		return other.Name()
+	}
+
+	declared := make(map[string]*indexedSymbol)
+	var methods []*indexedSymbol
+	for _, file := range pkg.Syntax {
+		filePath := fset.File(file.Pos()).Name()
+		if indexed.Dir == "" {
+			indexed.Dir = filepath.Dir(filePath)
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				symbol := idx.functionSymbol(fset, pkg, decl, qualifier)
+				symbol.FilePath = filePath
+				indexed.Symbols = append(indexed.Symbols, symbol)
+				if symbol.Kind == "method" {
+					methods = append(methods, symbol)
+				}
+				if decl.Body != nil {
+					indexed.Calls = append(indexed.Calls, collectCalls(fset, info, symbol.ID, filePath, decl.Body)...)
+				}
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					symbol := idx.typeSymbol(fset, pkg, decl, typeSpec)
+					symbol.FilePath = filePath
+					indexed.Symbols = append(indexed.Symbols, symbol)
+					declared[symbol.Name] = symbol
+				}
+			}
+		}
+	}
+
+	// Rattacher les méthodes déclarées à leur type
+	for _, method := range methods {
+		if owner := declared[method.Receiver]; owner != nil && owner.Type.Kind != "interface" {
+			owner.Type.Methods = append(owner.Type.Methods, *method.Function)
+		}
+	}
+	return indexed
+}
+
+func (idx *structuralIndex) functionSymbol(fset *token.FileSet, pkg *packages.Package, decl *ast.FuncDecl, qualifier types.Qualifier) *indexedSymbol {
+	symbol := &indexedSymbol{
+		Kind:        "function",
+		Language:    "go",
+		Name:        decl.Name.Name,
+		Package:     pkg.PkgPath,
+		PackageName: pkg.Name,
+		Line:        fset.Position(decl.Pos()).Line,
+	}
+	function := &interfaces.FunctionInfo{
+		Name:       decl.Name.Name,
+		Package:    pkg.Name,
+		LineStart:  symbol.Line,
+		LineEnd:    fset.Position(decl.End()).Line,
+		IsExported: decl.Name.IsExported(),
+		Complexity: idx.asm.calculateFunctionComplexity(decl),
+	}
+	if decl.Doc != nil {
+		function.Documentation = decl.Doc.Text()
+	}
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		symbol.Kind = "method"
+		symbol.Receiver = receiverName(decl.Recv.List[0].Type)
+	}
+
+	if obj, ok := pkg.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+		signature := obj.Type().(*types.Signature)
+		symbol.ID = funcID(obj)
+		function.Parameters, function.ReturnTypes = signatureParts(signature, qualifier)
+		symbol.Shape = shapeString(signature, qualifier)
+	} else {
+		// Déclaration non typée (erreur de compilation) : repli sur la syntaxe
+		symbol.ID = pkg.PkgPath + "." + decl.Name.Name
+		if symbol.Receiver != "" {
+			symbol.ID = pkg.PkgPath + "." + symbol.Receiver + "." + decl.Name.Name
+		}
+		function.Parameters = idx.asm.extractParameters(decl.Type.Params)
+		function.ReturnTypes = idx.asm.extractReturnTypes(decl.Type.Results)
+	}
+	function.Signature = idx.asm.buildFunctionSignature(*function)
+	symbol.Function = function
+	return symbol
+}
+
+func (idx *structuralIndex) typeSymbol(fset *token.FileSet, pkg *packages.Package, decl *ast.GenDecl, spec *ast.TypeSpec) *indexedSymbol {
+	symbol := &indexedSymbol{
+		ID:          pkg.PkgPath + "." + spec.Name.Name,
+		Kind:        "type",
+		Language:    "go",
+		Name:        spec.Name.Name,
+		Package:     pkg.PkgPath,
+		PackageName: pkg.Name,
+		Line:        fset.Position(spec.Pos()).Line,
+	}
+	typeInfo := &interfaces.TypeInfo{
+		Name:       spec.Name.Name,
+		Package:    pkg.Name,
+		LineStart:  symbol.Line,
+		LineEnd:    fset.Position(spec.End()).Line,
+		IsExported: spec.Name.IsExported(),
+	}
+	if spec.Doc != nil {
+		typeInfo.Documentation = spec.Doc.Text()
+	} else if decl.Doc != nil && len(decl.Specs) == 1 {
+		typeInfo.Documentation = decl.Doc.Text()
+	}
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		typeInfo.Kind = "struct"
+		typeInfo.Fields = idx.asm.extractFields(t.Fields)
+	case *ast.InterfaceType:
+		typeInfo.Kind = "interface"
+		typeInfo.Methods = idx.asm.extractInterfaceMethods(t.Methods)
+	default:
+		typeInfo.Kind = "type_alias"
+	}
+	symbol.Type = typeInfo
+
+	if obj, ok := pkg.TypesInfo.Defs[spec.Name].(*types.TypeName); ok {
+		symbol.MethodSet = methodSet(obj.Type())
+	}
+	return symbol
+}
+
+// collectCalls relève les appels d'une fonction, y compris ceux de ses
+// fonctions littérales
+func collectCalls(fset *token.FileSet, info *types.Info, from, filePath string, body *ast.BlockStmt) []callEdge {
+	var calls []callEdge
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fun := ast.Unparen(call.Fun)
+		switch f := fun.(type) {
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		}
+
+		var obj types.Object
+		switch f := fun.(type) {
+		case *ast.Ident:
+			obj = info.Uses[f]
+		case *ast.SelectorExpr:
+			if selection, ok := info.Selections[f]; ok {
+				obj = selection.Obj()
+			} else {
+				obj = info.Uses[f.Sel]
+			}
+		}
+		if fn, ok := obj.(*types.Func); ok && fn.Pkg() != nil {
+			calls = append(calls, callEdge{
+				From:      from,
+				To:        funcID(fn.Origin()),
+				ToPackage: fn.Pkg().Path(),
+				FilePath:  filePath,
+				Line:      fset.Position(call.Pos()).Line,
+			})
+		}
+		return true
+	})
+	return calls
+}
+
+// collectInterfaces retient les interfaces exportées des imports d'un
+// package pour les requêtes "implémente io.Reader"
+func collectInterfaces(pkg *types.Package, external map[string]*indexedSymbol) {
+	for _, imported := range pkg.Imports() {
+		scope := imported.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !obj.Exported() {
+				continue
+			}
+			if _, isInterface := obj.Type().Underlying().(*types.Interface); !isInterface {
+				continue
+			}
+			id := imported.Path() + "." + name
+			if _, seen := external[id]; seen {
+				continue
+			}
+			external[id] = &indexedSymbol{
+				ID:          id,
+				Kind:        "type",
+				Name:        name,
+				Package:     imported.Path(),
+				PackageName: imported.Name(),
+				Type:        &interfaces.TypeInfo{Name: name, Kind: "interface", Package: imported.Name(), IsExported: true},
+				MethodSet:   methodSet(obj.Type()),
+			}
+		}
+	}
+}
+
+// funcID identifie une fonction ou une méthode de manière stable entre
+// deux chargements
+func funcID(fn *types.Func) string {
+	prefix := ""
+	if fn.Pkg() != nil {
+		prefix = fn.Pkg().Path() + "."
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if pointer, ok := t.(*types.Pointer); ok {
+			t = pointer.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			return prefix + named.Obj().Name() + "." + fn.Name()
+		}
+	}
+	return prefix + fn.Name()
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// methodSet retourne les méthodes de *T (ou de l'interface) avec leur
+// signature qualifiée par chemin d'import, comparables d'un chargement à
+// l'autre
+func methodSet(t types.Type) map[string]string {
+	if iface, ok := t.Underlying().(*types.Interface); ok && !iface.IsMethodSet() {
+		return nil
+	}
+	if _, ok := t.Underlying().(*types.Interface); !ok {
+		t = types.NewPointer(t)
+	}
+	set := types.NewMethodSet(t)
+	methods := make(map[string]string, set.Len())
+	for i := 0; i < set.Len(); i++ {
+		fn, ok := set.At(i).Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		name := fn.Name()
+		if !fn.Exported() && fn.Pkg() != nil {
+			name = fn.Pkg().Path() + "." + name
+		}
+		methods[name] = shapeString(fn.Type().(*types.Signature), pathQualifier)
+	}
+	return methods
+}
+
+func pathQualifier(pkg *types.Package) string {
+	return pkg.Path()
+}
+
+// signatureParts décrit les paramètres et les types de retour d'une signature
+func signatureParts(signature *types.Signature, qualifier types.Qualifier) ([]interfaces.ParameterInfo, []string) {
+	params := signature.Params()
+	parameters := make([]interfaces.ParameterInfo, 0, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		param := interfaces.ParameterInfo{
+			Name: params.At(i).Name(),
+			Type: types.TypeString(params.At(i).Type(), qualifier),
+		}
+		if signature.Variadic() && i == params.Len()-1 {
+			param.IsVariadic = true
+			if slice, ok := params.At(i).Type().(*types.Slice); ok {
+				param.Type = "..." + types.TypeString(slice.Elem(), qualifier)
+			}
+		}
+		parameters = append(parameters, param)
+	}
+
+	results := signature.Results()
+	returnTypes := make([]string, 0, results.Len())
+	for i := 0; i < results.Len(); i++ {
+		returnTypes = append(returnTypes, types.TypeString(results.At(i).Type(), qualifier))
+	}
+	return parameters, returnTypes
+}
+
+// shapeString écrit une signature sans noms de paramètres ni récepteur,
+// ex. "func(context.Context, ...string) (int, error)"
+func shapeString(signature *types.Signature, qualifier types.Qualifier) string {
+	parameters, returnTypes := signatureParts(signature, qualifier)
+	var b bytes.Buffer
+	b.WriteString("func(")
+	for i, param := range parameters {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(param.Type)
+	}
+	b.WriteString(")")
+	switch len(returnTypes) {
+	case 0:
+	case 1:
+		b.WriteString(" " + returnTypes[0])
+	default:
+		b.WriteString(" (" + strings.Join(returnTypes, ", ") + ")")
+	}
+	return b.String()
+}
+
+// recordImports renseigne les imports des fichiers d'un package rechargé
+func (idx *structuralIndex) recordImports(pkg *indexedPackage) {
+	fset := token.NewFileSet()
+	for path, file := range idx.files {
+		if file.Package != pkg.Path || filepath.Dir(path) != pkg.Dir {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, path, src, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		file.Imports = file.Imports[:0]
+		for _, imp := range parsed.Imports {
+			info := interfaces.ImportInfo{
+				Path:       strings.Trim(imp.Path.Value, `"`),
+				LineNumber: fset.Position(imp.Pos()).Line,
+			}
+			if imp.Name != nil {
+				info.Alias = imp.Name.Name
+			}
+			info.IsStandard = idx.asm.isStandardPackage(info.Path)
+			file.Imports = append(file.Imports, info)
+		}
+	}
+}
+
+// symbols retourne tous les symboles indexés, triés par fichier et ligne
+func (idx *structuralIndex) symbols() []*indexedSymbol {
+	var symbols []*indexedSymbol
+	for _, pkg := range idx.packages {
+		symbols = append(symbols, pkg.Symbols...)
+	}
+	for _, documentSymbols := range idx.documents {
+		symbols = append(symbols, documentSymbols...)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].FilePath != symbols[j].FilePath {
+			return symbols[i].FilePath < symbols[j].FilePath
+		}
+		return symbols[i].Line < symbols[j].Line
+	})
+	return symbols
+}
+
+func (idx *structuralIndex) calls() []callEdge {
+	var calls []callEdge
+	for _, pkg := range idx.packages {
+		calls = append(calls, pkg.Calls...)
+	}
+	return calls
+}
+
+func (idx *structuralIndex) symbol(id string) *indexedSymbol {
+	for _, pkg := range idx.packages {
+		for _, symbol := range pkg.Symbols {
+			if symbol.ID == id {
+				return symbol
+			}
+		}
+	}
+	for _, documentSymbols := range idx.documents {
+		for _, symbol := range documentSymbols {
+			if symbol.ID == id {
+				return symbol
+			}
+		}
+	}
+	return nil
+}
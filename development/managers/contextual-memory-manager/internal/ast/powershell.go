@@ -0,0 +1,696 @@
+// internal/ast/powershell.go
+package ast
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// powerShellFrontend analyse les scripts et modules PowerShell. Le langage
+// n'étant pas sensible à la casse, les motifs ne le sont pas non plus.
+type powerShellFrontend struct{}
+
+func (f *powerShellFrontend) Language() string     { return "powershell" }
+func (f *powerShellFrontend) Extensions() []string { return []string{".ps1", ".psm1"} }
+
+var (
+	psFunctionPattern     = regexp.MustCompile(`(?im)^[ \t]*(function|filter|workflow)\s+(?:(?:global|script|local|private):)?([\w.-]+)\s*`)
+	psClassPattern        = regexp.MustCompile(`(?im)^[ \t]*class\s+(\w+)\s*(?::\s*([^{]+))?\{`)
+	psEnumPattern         = regexp.MustCompile(`(?im)^[ \t]*(?:\[Flags\(\)\]\s*)?enum\s+(\w+)\s*(?::\s*\w+\s*)?\{`)
+	psParamPattern        = regexp.MustCompile(`(?i)\bparam\s*\(`)
+	psOutputTypePattern   = regexp.MustCompile(`(?i)\[OutputType\(`)
+	psMethodPattern       = regexp.MustCompile(`^(\w+)\s*\(`)
+	psImportModulePattern = regexp.MustCompile(`(?im)^[ \t]*Import-Module\s+(?:-Name\s+)?`)
+	psUsingPattern        = regexp.MustCompile(`(?im)^[ \t]*using\s+(module|namespace|assembly)\s+`)
+	psDotSourcePattern    = regexp.MustCompile(`(?m)^[ \t]*\.\s+`)
+	psRequiresPattern     = regexp.MustCompile(`(?im)^Requires\b.*?-Modules\s+(.+)$`)
+	psModuleNamePattern   = regexp.MustCompile(`(?i)ModuleName\s*=\s*['"]?([\w.-]+)`)
+	psVariablePattern     = regexp.MustCompile(`(?im)^[ \t]*(?:\[([^\]]+)\]\s*)?\$(?:script:|global:)?(\w+)\s*=[^=]`)
+	psSetVariablePattern  = regexp.MustCompile(`(?im)^[ \t]*(?:Set|New)-Variable\b([^\n]*)`)
+	psExportPattern       = regexp.MustCompile(`(?im)^[ \t]*Export-ModuleMember\b([^\n]*)`)
+	psBranchPattern       = regexp.MustCompile(`(?i)\b(?:if|elseif|for|foreach|while|do|switch|catch|trap)\b`)
+)
+
+var powerShellRules = lexicalRules{
+	lineComments:  []string{"#"},
+	blockComments: [][2]string{{"<#", "#>"}},
+	scanString:    psScanString,
+	cleanComment:  cleanCommentHelp,
+}
+
+type psParser struct {
+	s      *sourceText
+	code   string
+	depths []int
+	result *interfaces.ASTAnalysisResult
+}
+
+func (f *powerShellFrontend) Parse(filePath string, src []byte) (*interfaces.ASTAnalysisResult, error) {
+	s := newSourceText(string(src), powerShellRules)
+	p := &psParser{
+		s:      s,
+		code:   s.code,
+		depths: braceDepths(s.code),
+		result: &interfaces.ASTAnalysisResult{
+			FilePath:  filePath,
+			Package:   directoryPackage(filePath),
+			Imports:   make([]interfaces.ImportInfo, 0),
+			Functions: make([]interfaces.FunctionInfo, 0),
+			Types:     make([]interfaces.TypeInfo, 0),
+			Variables: make([]interfaces.VariableInfo, 0),
+			Constants: make([]interfaces.ConstantInfo, 0),
+			Context:   make(map[string]interface{}),
+		},
+	}
+	// Un module porte le nom de son fichier
+	if strings.EqualFold(filepath.Ext(filePath), ".psm1") {
+		p.result.Package = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	p.parseImports()
+	p.parseFunctions()
+	p.parseClasses()
+	p.parseEnums()
+	p.parseVariables()
+	p.applyExports()
+
+	finishResult(p.result, s, psBranchPattern)
+	return p.result, nil
+}
+
+func (p *psParser) addImport(path string, offset int) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return
+	}
+	p.result.Imports = append(p.result.Imports, interfaces.ImportInfo{
+		Path:       path,
+		IsStandard: isPowerShellBuiltin(path),
+		LineNumber: p.s.lineOf(offset),
+	})
+}
+
+func isPowerShellBuiltin(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "microsoft.powershell.") || strings.HasPrefix(lower, "system.") || lower == "system"
+}
+
+// argument lit l'argument qui commence à offset : une chaîne ou un mot
+func (p *psParser) argument(offset int) string {
+	if value, ok := p.s.stringAt(offset); ok {
+		return value
+	}
+	end := offset
+	for end < len(p.code) && !isSpace(p.code[end]) && p.code[end] != ';' && p.code[end] != ',' {
+		end++
+	}
+	return p.code[offset:end]
+}
+
+func (p *psParser) parseImports() {
+	for _, m := range psImportModulePattern.FindAllStringIndex(p.code, -1) {
+		p.addImport(p.argument(m[1]), m[1])
+	}
+	for _, m := range psUsingPattern.FindAllStringIndex(p.code, -1) {
+		p.addImport(p.argument(m[1]), m[1])
+	}
+	for _, m := range psDotSourcePattern.FindAllStringIndex(p.code, -1) {
+		p.addImport(p.argument(m[1]), m[1])
+	}
+
+	// #Requires -Modules A, @{ ModuleName = 'B'; ModuleVersion = '1.0' }
+	for _, comment := range p.s.comments {
+		for _, m := range psRequiresPattern.FindAllStringSubmatch(comment.text, -1) {
+			for _, module := range splitTopLevel(m[1], ',', false) {
+				if name := psModuleNamePattern.FindStringSubmatch(module); name != nil {
+					p.addImport(name[1], comment.start)
+					continue
+				}
+				p.addImport(strings.Trim(strings.Fields(module)[0], `'"`), comment.start)
+			}
+		}
+	}
+}
+
+func (p *psParser) parseFunctions() {
+	code := p.code
+	for _, m := range psFunctionPattern.FindAllStringSubmatchIndex(code, -1) {
+		start := skipBlank(code, m[0])
+		if p.depths[start] != 0 {
+			continue
+		}
+
+		name := code[m[4]:m[5]]
+		open := m[1]
+		var params []interfaces.ParameterInfo
+		if open < len(code) && code[open] == '(' {
+			close := matchingBracket(code, open)
+			params = p.parameters(open, close)
+			open = skipSpace(code, close+1)
+		}
+		if open >= len(code) || code[open] != '{' {
+			continue
+		}
+		close := matchingBracket(code, open)
+
+		// Attributs et bloc param() en tête du corps
+		header := p.bodyHeader(open, close)
+		if params == nil {
+			params = make([]interfaces.ParameterInfo, 0)
+			if loc := psParamPattern.FindStringIndex(code[open:header]); loc != nil {
+				paramOpen := open + loc[1] - 1
+				params = p.parameters(paramOpen, matchingBracket(code, paramOpen))
+			}
+		}
+
+		function := interfaces.FunctionInfo{
+			Name:          name,
+			Package:       p.result.Package,
+			Signature:     compactSpace(p.s.src[start:m[1]]) + psParameterList(params),
+			Parameters:    params,
+			ReturnTypes:   p.outputTypes(open, header),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+			Complexity:    1 + countKeywords(code[open:close+1], psBranchPattern),
+			IsExported:    true,
+			Documentation: p.documentation(start, open, close),
+		}
+		if kind := strings.ToLower(code[m[2]:m[3]]); kind != "function" {
+			function.Annotations = map[string]string{"kind": kind}
+		}
+		p.result.Functions = append(p.result.Functions, function)
+	}
+}
+
+// psParameterList écrit la liste de paramètres d'une signature : ([string]$Name, $Force)
+func psParameterList(params []interfaces.ParameterInfo) string {
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		part := "$" + param.Name
+		if param.Type != "" {
+			part = "[" + param.Type + "]" + part
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// bodyHeader retourne la fin de l'en-tête d'un corps de fonction : les
+// attributs [CmdletBinding()], [OutputType()] et le bloc param()
+func (p *psParser) bodyHeader(open, close int) int {
+	code := p.code
+	i := skipSpace(code, open+1)
+	for i < close {
+		switch {
+		case code[i] == '[':
+			i = skipSpace(code, matchingBracket(code, i)+1)
+		case hasFoldedKeyword(code, i, "param"):
+			paren := skipSpace(code, i+len("param"))
+			if paren < close && code[paren] == '(' {
+				return matchingBracket(code, paren) + 1
+			}
+			return i
+		default:
+			return i
+		}
+	}
+	return close
+}
+
+func hasFoldedKeyword(code string, i int, keyword string) bool {
+	end := i + len(keyword)
+	return end <= len(code) && strings.EqualFold(code[i:end], keyword) && (end == len(code) || !isWordByte(code[end]))
+}
+
+// outputTypes lit les types déclarés par [OutputType(...)]
+func (p *psParser) outputTypes(open, header int) []string {
+	types := make([]string, 0)
+	for _, loc := range psOutputTypePattern.FindAllStringIndex(p.code[open:header], -1) {
+		paren := open + loc[1] - 1
+		close := matchingBracket(p.code, paren)
+		for _, span := range splitSpans(p.code[paren+1:close], ',', false) {
+			item := strings.TrimSpace(p.s.src[paren+1+span[0] : paren+1+span[1]])
+			item = strings.Trim(item, `[]'"`)
+			if item != "" {
+				types = append(types, item)
+			}
+		}
+	}
+	return types
+}
+
+// documentation retourne l'aide (comment-based help) placée avant la
+// fonction ou au début de son corps
+func (p *psParser) documentation(start, open, close int) string {
+	if doc := p.s.commentBefore(p.s.lineOf(start), nil); doc != "" {
+		return doc
+	}
+	first := skipSpace(p.s.src, open+1)
+	for _, comment := range p.s.comments {
+		if comment.start == first && comment.end < close {
+			return comment.text
+		}
+	}
+	return ""
+}
+
+// parameters lit les paramètres entre open et close : attributs
+// [Parameter(...)], type [string] et nom $Name
+func (p *psParser) parameters(open, close int) []interfaces.ParameterInfo {
+	params := make([]interfaces.ParameterInfo, 0)
+	if close <= open {
+		return params
+	}
+
+	list := p.code[open+1 : close]
+	for _, span := range splitSpans(list, ',', false) {
+		item := list[span[0]:span[1]]
+		text := p.s.src[open+1+span[0] : open+1+span[1]]
+
+		var param interfaces.ParameterInfo
+		i := skipSpace(item, 0)
+		for i < len(item) && item[i] == '[' {
+			end := matchingBracket(item, i)
+			inner := item[i+1 : end]
+			switch {
+			case strings.Contains(inner, "("):
+				// attribut : [Parameter(...)], [ValidateSet(...)]...
+				if strings.Contains(strings.ToLower(inner), "valuefromremainingarguments") {
+					param.IsVariadic = true
+				}
+			default:
+				param.Type = compactSpace(text[i+1 : end])
+			}
+			i = skipSpace(item, end+1)
+		}
+		if i >= len(item) || item[i] != '$' {
+			continue
+		}
+		end := i + 1
+		for end < len(item) && (isWordByte(item[end]) || item[end] == ':') {
+			end++
+		}
+		param.Name = item[i+1 : end]
+		params = append(params, param)
+	}
+	return params
+}
+
+func (p *psParser) parseClasses() {
+	code := p.code
+	for _, m := range psClassPattern.FindAllStringSubmatchIndex(code, -1) {
+		start := skipSpace(code, m[0])
+		if p.depths[start] != 0 {
+			continue
+		}
+		open := m[1] - 1
+		close := matchingBracket(code, open)
+		name := code[m[2]:m[3]]
+
+		typeInfo := interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "class",
+			Package:       p.result.Package,
+			IsExported:    true,
+			Documentation: p.s.commentBefore(p.s.lineOf(start), nil),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+		}
+		if m[4] >= 0 {
+			for _, base := range strings.Split(code[m[4]:m[5]], ",") {
+				if base = strings.TrimSpace(base); base != "" {
+					typeInfo.BaseTypes = append(typeInfo.BaseTypes, base)
+				}
+			}
+		}
+		p.parseClassMembers(&typeInfo, open, close)
+		p.result.Types = append(p.result.Types, typeInfo)
+	}
+}
+
+func (p *psParser) parseClassMembers(typeInfo *interfaces.TypeInfo, open, close int) {
+	code := p.code
+	depth := p.depths[open] + 1
+	for line := p.s.lineOf(open) + 1; line <= p.s.lineOf(close); line++ {
+		pos := skipBlank(code, p.s.lineStarts[line-1])
+		if pos >= close || p.depths[pos] != depth || code[pos] == '\n' || code[pos] == '\r' || code[pos] == '}' {
+			continue
+		}
+		attributes, memberStart := p.memberPrefix(pos, close)
+
+		if m := psMethodPattern.FindStringSubmatchIndex(code[memberStart:close]); m != nil {
+			paren := memberStart + m[1] - 1
+			parenClose := matchingBracket(code, paren)
+			// Le corps suit la liste de paramètres, ou l'appel ": base(...)" d'un constructeur
+			body := strings.IndexByte(code[parenClose:close], '{')
+			if body < 0 {
+				continue
+			}
+			body += parenClose
+			bodyClose := matchingBracket(code, body)
+
+			method := interfaces.FunctionInfo{
+				Name:        code[memberStart+m[2] : memberStart+m[3]],
+				Package:     p.result.Package,
+				Signature:   compactSpace(p.s.src[pos : parenClose+1]),
+				Parameters:  p.parameters(paren, parenClose),
+				ReturnTypes: make([]string, 0),
+				LineStart:   line,
+				LineEnd:     p.s.lineOf(bodyClose),
+				Complexity:  1 + countKeywords(code[body:bodyClose+1], psBranchPattern),
+				IsExported:  !attributes.hidden,
+				Annotations: map[string]string{"receiver": typeInfo.Name},
+			}
+			if attributes.typeName != "" && !strings.EqualFold(attributes.typeName, "void") {
+				method.ReturnTypes = append(method.ReturnTypes, attributes.typeName)
+			}
+			if attributes.static {
+				method.Annotations["static"] = "true"
+			}
+			typeInfo.Methods = append(typeInfo.Methods, method)
+			p.result.Functions = append(p.result.Functions, method)
+			continue
+		}
+
+		if memberStart < close && code[memberStart] == '$' {
+			end := memberStart + 1
+			for end < close && isWordByte(code[end]) {
+				end++
+			}
+			typeInfo.Fields = append(typeInfo.Fields, interfaces.FieldInfo{
+				Name:       code[memberStart+1 : end],
+				Type:       attributes.typeName,
+				IsExported: !attributes.hidden,
+			})
+		}
+	}
+}
+
+type psMemberAttributes struct {
+	typeName       string
+	hidden, static bool
+}
+
+// memberPrefix lit les mots-clés hidden et static, les attributs et le
+// type qui précèdent un membre de classe
+func (p *psParser) memberPrefix(pos, close int) (psMemberAttributes, int) {
+	var attributes psMemberAttributes
+	code := p.code
+	for {
+		pos = skipBlank(code, pos)
+		switch {
+		case pos >= close:
+			return attributes, pos
+		case code[pos] == '[':
+			end := matchingBracket(code, pos)
+			if inner := code[pos+1 : end]; !strings.Contains(inner, "(") {
+				attributes.typeName = compactSpace(inner)
+			}
+			pos = end + 1
+		case hasFoldedKeyword(code, pos, "hidden"):
+			attributes.hidden = true
+			pos += len("hidden")
+		case hasFoldedKeyword(code, pos, "static"):
+			attributes.static = true
+			pos += len("static")
+		default:
+			return attributes, pos
+		}
+	}
+}
+
+func (p *psParser) parseEnums() {
+	code := p.code
+	for _, m := range psEnumPattern.FindAllStringSubmatchIndex(code, -1) {
+		start := skipSpace(code, m[0])
+		if p.depths[start] != 0 {
+			continue
+		}
+		open := m[1] - 1
+		close := matchingBracket(code, open)
+		name := code[m[2]:m[3]]
+
+		typeInfo := interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "enum",
+			Package:       p.result.Package,
+			IsExported:    true,
+			Documentation: p.s.commentBefore(p.s.lineOf(start), nil),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+		}
+		for _, line := range strings.Split(code[open+1:close], "\n") {
+			fields := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == '=' || r == ';' || r == '\r' })
+			if len(fields) > 0 && isIdentifier(fields[0]) {
+				typeInfo.Fields = append(typeInfo.Fields, interfaces.FieldInfo{Name: fields[0], Type: name, IsExported: true})
+			}
+		}
+		p.result.Types = append(p.result.Types, typeInfo)
+	}
+}
+
+// parseVariables lit les affectations de portée script ; Set-Variable et
+// New-Variable avec -Option Constant ou ReadOnly déclarent des constantes
+func (p *psParser) parseVariables() {
+	code := p.code
+	seen := make(map[string]bool)
+	for _, m := range psVariablePattern.FindAllStringSubmatchIndex(code, -1) {
+		start := skipSpace(code, m[0])
+		name := code[m[4]:m[5]]
+		if p.depths[start] != 0 || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		variable := interfaces.VariableInfo{
+			Name:          name,
+			Package:       p.result.Package,
+			IsExported:    true,
+			LineNumber:    p.s.lineOf(start),
+			Documentation: p.s.commentBefore(p.s.lineOf(start), nil),
+		}
+		if m[2] >= 0 {
+			variable.Type = code[m[2]:m[3]]
+		}
+		valueStart := skipSpace(code, m[1]-1)
+		valueEnd := strings.IndexByte(code[valueStart:], '\n')
+		if valueEnd < 0 {
+			valueEnd = len(code) - valueStart
+		}
+		variable.Value = summarizeValue(p.s.src[valueStart : valueStart+valueEnd])
+		p.result.Variables = append(p.result.Variables, variable)
+	}
+
+	for _, m := range psSetVariablePattern.FindAllStringSubmatchIndex(code, -1) {
+		start := skipSpace(code, m[0])
+		if p.depths[start] != 0 {
+			continue
+		}
+		args := p.namedArguments(m[2], m[3])
+		name := args["name"]
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		line := p.s.lineOf(start)
+		documentation := p.s.commentBefore(line, nil)
+		option := strings.ToLower(args["option"])
+		if strings.Contains(option, "constant") || strings.Contains(option, "readonly") {
+			p.result.Constants = append(p.result.Constants, interfaces.ConstantInfo{
+				Name:          name,
+				Value:         args["value"],
+				Package:       p.result.Package,
+				IsExported:    true,
+				LineNumber:    line,
+				Documentation: documentation,
+			})
+			continue
+		}
+		p.result.Variables = append(p.result.Variables, interfaces.VariableInfo{
+			Name:          name,
+			Value:         args["value"],
+			Package:       p.result.Package,
+			IsExported:    true,
+			LineNumber:    line,
+			Documentation: documentation,
+		})
+	}
+}
+
+// namedArguments lit les arguments "-Name valeur" d'une commande ; les
+// arguments positionnels sont Name puis Value
+func (p *psParser) namedArguments(start, end int) map[string]string {
+	args := make(map[string]string)
+	positional := []string{"name", "value"}
+	for i := skipSpace(p.code, start); i < end; i = skipSpace(p.code, i) {
+		if p.code[i] == '-' {
+			j := i + 1
+			for j < end && isWordByte(p.code[j]) {
+				j++
+			}
+			parameter := strings.ToLower(p.code[i+1 : j])
+			valueStart := skipSpace(p.code, j)
+			if valueStart >= end || p.code[valueStart] == '-' {
+				args[parameter] = "" // switch
+				i = valueStart
+				continue
+			}
+			value, next := p.argumentList(valueStart, end)
+			args[parameter] = value
+			i = next
+			continue
+		}
+		value, next := p.argumentList(i, end)
+		if len(positional) > 0 {
+			args[positional[0]] = value
+			positional = positional[1:]
+		}
+		i = next
+	}
+	return args
+}
+
+// argumentList lit une valeur d'argument, éventuellement une liste "a, b"
+func (p *psParser) argumentList(start, end int) (string, int) {
+	var values []string
+	i := start
+	for i < end {
+		value := p.argument(i)
+		next := i + max(len(value), 1)
+		if span := p.stringEnd(i); span > i {
+			next = span
+		}
+		values = append(values, value)
+		next = skipBlank(p.code, next)
+		if next < end && p.code[next] == ',' {
+			i = skipSpace(p.code, next+1)
+			continue
+		}
+		return strings.Join(values, ","), next
+	}
+	return strings.Join(values, ","), end
+}
+
+func (p *psParser) stringEnd(offset int) int {
+	for _, span := range p.s.strings {
+		if span.start == offset {
+			return span.end
+		}
+		if span.start > offset {
+			break
+		}
+	}
+	return -1
+}
+
+// applyExports restreint les fonctions exportées à celles listées par
+// Export-ModuleMember -Function, jokers compris
+func (p *psParser) applyExports() {
+	var patterns []string
+	restricted := false
+	for _, m := range psExportPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		restricted = true
+		args := p.namedArguments(m[2], m[3])
+		functions := args["function"]
+		if functions == "" {
+			functions = args["name"]
+		}
+		if functions != "" {
+			patterns = append(patterns, strings.Split(functions, ",")...)
+		}
+	}
+	if !restricted {
+		return
+	}
+
+	for i := range p.result.Functions {
+		function := &p.result.Functions[i]
+		if function.Annotations["receiver"] != "" {
+			continue
+		}
+		function.IsExported = false
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(strings.ToLower(strings.TrimSpace(pattern)), strings.ToLower(function.Name)); matched {
+				function.IsExported = true
+				break
+			}
+		}
+	}
+}
+
+// psScanString reconnaît les chaînes entre apostrophes (échappées en les
+// doublant), les chaînes "..." (échappement par accent grave, sous-expressions
+// $(...)) et les here-strings @"..."@ et @'...'@
+func psScanString(src string, i int, previous string) int {
+	switch src[i] {
+	case '@':
+		if i+1 >= len(src) || src[i+1] != '"' && src[i+1] != '\'' {
+			return -1
+		}
+		closing := "\n" + string(src[i+1]) + "@"
+		if end := strings.Index(src[i+2:], closing); end >= 0 {
+			return i + 2 + end + len(closing)
+		}
+		return len(src)
+	case '\'':
+		for j := i + 1; j < len(src); j++ {
+			if src[j] == '\'' {
+				if j+1 < len(src) && src[j+1] == '\'' {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(src)
+	case '"':
+		for j := i + 1; j < len(src); j++ {
+			switch src[j] {
+			case '`':
+				j++
+			case '"':
+				return j + 1
+			case '$':
+				if j+1 < len(src) && src[j+1] == '(' {
+					j = psSkipSubexpression(src, j+1)
+				}
+			}
+		}
+		return len(src)
+	}
+	return -1
+}
+
+// psSkipSubexpression retourne la position de la parenthèse fermant $(...)
+func psSkipSubexpression(src string, open int) int {
+	depth := 0
+	for j := open; j < len(src); j++ {
+		switch src[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case '\'', '"':
+			if end := psScanString(src, j, ""); end > j {
+				j = end - 1
+			}
+		}
+	}
+	return len(src) - 1
+}
+
+// cleanCommentHelp garde le texte de l'aide sans l'indentation
+func cleanCommentHelp(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
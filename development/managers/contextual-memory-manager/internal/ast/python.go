@@ -0,0 +1,657 @@
+// internal/ast/python.go
+package ast
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// pythonFrontend analyse Python : les blocs sont délimités par
+// l'indentation des lignes logiques (parenthèses ouvertes et "\" prolongent
+// une ligne)
+type pythonFrontend struct{}
+
+func (f *pythonFrontend) Language() string     { return "python" }
+func (f *pythonFrontend) Extensions() []string { return []string{".py", ".pyi"} }
+
+var (
+	pyDefPattern        = regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)\s*(?:\[[^\]]*\])?\s*\(`)
+	pyClassPattern      = regexp.MustCompile(`^class\s+([A-Za-z_]\w*)\s*(?:\[[^\]]*\])?\s*`)
+	pyTypeAliasPattern  = regexp.MustCompile(`^type\s+([A-Za-z_]\w*)`)
+	pyImportPattern     = regexp.MustCompile(`^import\s+(.+)$`)
+	pyFromPattern       = regexp.MustCompile(`^from\s+(\S+)\s+import\b`)
+	pyDecoratorPattern  = regexp.MustCompile(`^@\s*([\w.]+)`)
+	pySelfFieldPattern  = regexp.MustCompile(`\bself\.([A-Za-z_]\w*)\s*(?::([^=\n]+))?=[^=]`)
+	pyConstantPattern   = regexp.MustCompile(`^_*[A-Z][A-Z0-9_]*$`)
+	pyBranchPattern     = regexp.MustCompile(`\b(?:if|elif|for|while|except)\b`)
+	pyStringPrefixChars = "rRbBuUfF"
+)
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+	"match": true, "case": true, "type": true,
+}
+
+// Modules de la bibliothèque standard
+var pythonStdlib = map[string]bool{
+	"__future__": true, "abc": true, "argparse": true, "array": true, "ast": true, "asyncio": true,
+	"atexit": true, "base64": true, "binascii": true, "bisect": true, "builtins": true, "bz2": true,
+	"calendar": true, "cmath": true, "cmd": true, "code": true, "codecs": true, "collections": true,
+	"colorsys": true, "concurrent": true, "configparser": true, "contextlib": true, "contextvars": true,
+	"copy": true, "cProfile": true, "csv": true, "ctypes": true, "curses": true, "dataclasses": true,
+	"datetime": true, "decimal": true, "difflib": true, "dis": true, "doctest": true, "email": true,
+	"enum": true, "errno": true, "filecmp": true, "fileinput": true, "fnmatch": true, "fractions": true,
+	"ftplib": true, "functools": true, "gc": true, "getpass": true, "gettext": true, "glob": true,
+	"graphlib": true, "grp": true, "gzip": true, "hashlib": true, "heapq": true, "hmac": true,
+	"html": true, "http": true, "imaplib": true, "importlib": true, "inspect": true, "io": true,
+	"ipaddress": true, "itertools": true, "json": true, "keyword": true, "linecache": true,
+	"locale": true, "logging": true, "lzma": true, "mailbox": true, "math": true, "mimetypes": true,
+	"mmap": true, "multiprocessing": true, "netrc": true, "numbers": true, "operator": true,
+	"optparse": true, "os": true, "pathlib": true, "pdb": true, "pickle": true, "platform": true,
+	"plistlib": true, "poplib": true, "pprint": true, "profile": true, "pty": true, "pwd": true,
+	"queue": true, "random": true, "re": true, "readline": true, "reprlib": true, "resource": true,
+	"sched": true, "secrets": true, "select": true, "selectors": true, "shelve": true, "shlex": true,
+	"shutil": true, "signal": true, "site": true, "smtplib": true, "socket": true, "socketserver": true,
+	"sqlite3": true, "ssl": true, "stat": true, "statistics": true, "string": true, "struct": true,
+	"subprocess": true, "sys": true, "sysconfig": true, "tarfile": true, "tempfile": true,
+	"termios": true, "textwrap": true, "threading": true, "time": true, "timeit": true, "tkinter": true,
+	"token": true, "tokenize": true, "trace": true, "traceback": true, "tracemalloc": true, "tty": true,
+	"types": true, "typing": true, "unicodedata": true, "unittest": true, "urllib": true, "uuid": true,
+	"venv": true, "warnings": true, "wave": true, "weakref": true, "webbrowser": true, "wsgiref": true,
+	"xml": true, "xmlrpc": true, "zipfile": true, "zlib": true, "zoneinfo": true,
+}
+
+var pythonRules = lexicalRules{
+	lineComments: []string{"#"},
+	scanString:   pyScanString,
+}
+
+// pyLine est une ligne logique ; start et end sont des positions du code
+type pyLine struct {
+	start, end int
+	indent     int
+	line       int
+	endLine    int
+}
+
+type pyParser struct {
+	s      *sourceText
+	code   string
+	lines  []pyLine
+	result *interfaces.ASTAnalysisResult
+	all    map[string]bool // noms de __all__, nil sans liste
+}
+
+func (f *pythonFrontend) Parse(filePath string, src []byte) (*interfaces.ASTAnalysisResult, error) {
+	s := newSourceText(string(src), pythonRules)
+	p := &pyParser{
+		s:     s,
+		code:  s.code,
+		lines: pyLogicalLines(s),
+		result: &interfaces.ASTAnalysisResult{
+			FilePath:  filePath,
+			Package:   directoryPackage(filePath),
+			Imports:   make([]interfaces.ImportInfo, 0),
+			Functions: make([]interfaces.FunctionInfo, 0),
+			Types:     make([]interfaces.TypeInfo, 0),
+			Variables: make([]interfaces.VariableInfo, 0),
+			Constants: make([]interfaces.ConstantInfo, 0),
+			Context:   make(map[string]interface{}),
+		},
+	}
+
+	p.collectAll()
+	p.parseImports()
+	seen := make(map[string]bool)
+	for k := 0; k < len(p.lines); k++ {
+		line := p.lines[k]
+		if line.indent != 0 {
+			continue
+		}
+		text := p.text(line)
+		switch {
+		case pyDefPattern.MatchString(text):
+			p.result.Functions = append(p.result.Functions, p.function(k, "", false))
+		case pyClassPattern.MatchString(text):
+			p.parseClass(k)
+		case pyTypeAliasPattern.MatchString(text) && strings.Contains(text, "="):
+			name := pyTypeAliasPattern.FindStringSubmatch(text)[1]
+			p.result.Types = append(p.result.Types, interfaces.TypeInfo{
+				Name:       name,
+				Kind:       "type_alias",
+				Package:    p.result.Package,
+				IsExported: p.exported(name),
+				LineStart:  line.line,
+				LineEnd:    line.endLine,
+			})
+		default:
+			p.parseAssignment(line, seen)
+		}
+	}
+
+	finishResult(p.result, s, pyBranchPattern)
+	return p.result, nil
+}
+
+// pyLogicalLines découpe le code en lignes logiques non vides
+func pyLogicalLines(s *sourceText) []pyLine {
+	var lines []pyLine
+	code := s.code
+	depth, start, str := 0, -1, 0
+	for i := 0; i <= len(code); i++ {
+		if i == len(code) || code[i] == '\n' {
+			for str < len(s.strings) && s.strings[str].end <= i {
+				str++
+			}
+			inString := i < len(code) && str < len(s.strings) && s.strings[str].start <= i
+			if start < 0 || inString || depth > 0 && i < len(code) {
+				continue
+			}
+			if last := lastNonSpace(code, start, i); code[last] == '\\' && i < len(code) {
+				continue
+			}
+			end := lastNonSpace(code, start, i) + 1
+			lines = append(lines, pyLine{
+				start:   start,
+				end:     end,
+				indent:  pyIndent(s.src[s.lineStarts[s.lineOf(start)-1]:start]),
+				line:    s.lineOf(start),
+				endLine: s.lineOf(end - 1),
+			})
+			start = -1
+			continue
+		}
+		if start < 0 {
+			if isSpace(code[i]) {
+				continue
+			}
+			start = i
+		}
+		switch code[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return lines
+}
+
+func pyIndent(prefix string) int {
+	indent := 0
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] == '\t' {
+			indent += 8 - indent%8
+		} else {
+			indent++
+		}
+	}
+	return indent
+}
+
+func (p *pyParser) text(line pyLine) string {
+	return p.code[line.start:line.end]
+}
+
+// blockEnd retourne l'indice qui suit le bloc ouvert par la ligne k
+func (p *pyParser) blockEnd(k int) int {
+	j := k + 1
+	for j < len(p.lines) && p.lines[j].indent > p.lines[k].indent {
+		j++
+	}
+	return j
+}
+
+// decorators retourne les décorateurs qui précèdent la ligne k
+func (p *pyParser) decorators(k int) ([]string, int) {
+	var names []string
+	first := k
+	for d := k - 1; d >= 0 && p.lines[d].indent == p.lines[k].indent; d-- {
+		m := pyDecoratorPattern.FindStringSubmatch(p.text(p.lines[d]))
+		if m == nil {
+			break
+		}
+		names = append([]string{m[1]}, names...)
+		first = d
+	}
+	return names, first
+}
+
+// documentation retourne la docstring du bloc ouvert par la ligne k ou, à
+// défaut, le commentaire qui précède ses décorateurs
+func (p *pyParser) documentation(k, first, bodyStart int) string {
+	end := p.blockEnd(k)
+	if inline := skipSpace(p.code, bodyStart); inline < p.lines[k].end {
+		// corps sur la même ligne que l'en-tête
+		if doc, ok := p.stringAt(inline); ok {
+			return doc
+		}
+	} else if k+1 < end {
+		if doc, ok := p.stringAt(p.lines[k+1].start); ok && p.lines[k+1].end == p.stringEnd(p.lines[k+1].start) {
+			return doc
+		}
+	}
+	return p.s.commentBefore(p.lines[first].line, nil)
+}
+
+// stringAt retourne le contenu de la chaîne qui commence à offset, préfixe compris
+func (p *pyParser) stringAt(offset int) (string, bool) {
+	end := p.stringEnd(offset)
+	if end < 0 {
+		return "", false
+	}
+	literal := strings.TrimLeft(p.s.src[offset:end], pyStringPrefixChars)
+	lines := strings.Split(unquote(literal), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), true
+}
+
+func (p *pyParser) stringEnd(offset int) int {
+	for _, span := range p.s.strings {
+		if span.start == offset {
+			return span.end
+		}
+		if span.start > offset {
+			break
+		}
+	}
+	return -1
+}
+
+func (p *pyParser) exported(name string) bool {
+	if p.all != nil {
+		return p.all[name]
+	}
+	return !strings.HasPrefix(name, "_")
+}
+
+// collectAll lit la liste __all__ du module
+func (p *pyParser) collectAll() {
+	for _, line := range p.lines {
+		text := p.text(line)
+		if line.indent != 0 || !strings.HasPrefix(text, "__all__") {
+			continue
+		}
+		rest := strings.TrimSpace(text[len("__all__"):])
+		if !strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "+=") && !strings.HasPrefix(rest, ":") {
+			continue
+		}
+		if p.all == nil {
+			p.all = make(map[string]bool)
+		}
+		for _, span := range p.s.strings {
+			if span.start >= line.start && span.end <= line.end {
+				if name, ok := p.stringAt(span.start); ok {
+					p.all[name] = true
+				}
+			}
+		}
+	}
+}
+
+func (p *pyParser) parseImports() {
+	for _, line := range p.lines {
+		text := p.text(line)
+		if m := pyFromPattern.FindStringSubmatch(text); m != nil {
+			p.addImport(m[1], "", line.line)
+			continue
+		}
+		m := pyImportPattern.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		for _, item := range strings.Split(m[1], ",") {
+			fields := strings.Fields(item)
+			switch {
+			case len(fields) == 3 && fields[1] == "as":
+				p.addImport(fields[0], fields[2], line.line)
+			case len(fields) == 1:
+				p.addImport(fields[0], "", line.line)
+			}
+		}
+	}
+}
+
+func (p *pyParser) addImport(path, alias string, line int) {
+	p.result.Imports = append(p.result.Imports, interfaces.ImportInfo{
+		Path:       path,
+		Alias:      alias,
+		IsStandard: pythonStdlib[strings.SplitN(path, ".", 2)[0]],
+		LineNumber: line,
+	})
+}
+
+// function lit la définition de la ligne k ; receiver est la classe d'une méthode
+func (p *pyParser) function(k int, receiver string, exported bool) interfaces.FunctionInfo {
+	line := p.lines[k]
+	text := p.text(line)
+	m := pyDefPattern.FindStringSubmatchIndex(text)
+	name := text[m[2]:m[3]]
+	decorators, first := p.decorators(k)
+
+	open := line.start + m[1] - 1
+	close := matchingBracket(p.code, open)
+	params := p.parameters(open, close, receiver != "" && !containsString(decorators, "staticmethod"))
+
+	returns := make([]string, 0)
+	rest := p.code[close+1 : line.end]
+	colon := topLevelIndex(rest, ':')
+	if arrow := strings.Index(rest, "->"); arrow >= 0 {
+		colon = arrow + 2 + topLevelIndex(rest[arrow+2:], ':')
+		if returnType := compactSpace(p.s.src[close+1+arrow+2 : close+1+colon]); returnType != "" {
+			returns = append(returns, returnType)
+		}
+	}
+	bodyStart := close + 1 + colon + 1
+
+	end := p.blockEnd(k)
+	bodyEnd := line.end
+	if end > k+1 {
+		bodyEnd = p.lines[end-1].end
+	}
+
+	function := interfaces.FunctionInfo{
+		Name:          name,
+		Package:       p.result.Package,
+		Signature:     compactSpace(p.s.src[line.start : bodyStart-1]),
+		Parameters:    params,
+		ReturnTypes:   returns,
+		LineStart:     p.lines[first].line,
+		LineEnd:       p.lines[end-1].endLine,
+		Complexity:    1 + countKeywords(p.code[min(bodyStart, bodyEnd):bodyEnd], pyBranchPattern),
+		Documentation: p.documentation(k, first, bodyStart),
+	}
+	if receiver == "" {
+		function.IsExported = p.exported(name)
+	} else {
+		function.IsExported = exported && (!strings.HasPrefix(name, "_") || strings.HasSuffix(name, "__"))
+		function.Annotations = map[string]string{"receiver": receiver}
+	}
+	if len(decorators) > 0 {
+		if function.Annotations == nil {
+			function.Annotations = make(map[string]string)
+		}
+		function.Annotations["decorators"] = strings.Join(decorators, ",")
+	}
+	return function
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// topLevelIndex retourne la première position de target hors parenthèses,
+// crochets et accolades, ou len(code)
+func topLevelIndex(code string, target byte) int {
+	depth := 0
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == target && depth == 0:
+			return i
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		}
+	}
+	return len(code)
+}
+
+// parameters lit les paramètres ; dropFirst retire self ou cls
+func (p *pyParser) parameters(open, close int, dropFirst bool) []interfaces.ParameterInfo {
+	params := make([]interfaces.ParameterInfo, 0)
+	if close <= open {
+		return params
+	}
+
+	list := p.code[open+1 : close]
+	for _, span := range splitSpans(list, ',', false) {
+		item := list[span[0]:span[1]]
+		text := p.s.src[open+1+span[0] : open+1+span[1]]
+		if item == "*" || item == "/" {
+			continue
+		}
+		if dropFirst {
+			dropFirst = false
+			continue
+		}
+
+		stars := len(item) - len(strings.TrimLeft(item, "*"))
+		colon := topLevelIndex(item, ':')
+		equals := topLevelIndex(item, '=')
+		nameEnd := min(colon, equals)
+
+		param := interfaces.ParameterInfo{
+			Name:       strings.TrimSpace(item[stars:nameEnd]),
+			IsVariadic: stars > 0,
+		}
+		if colon < equals {
+			param.Type = compactSpace(text[colon+1 : equals])
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func (p *pyParser) parseClass(k int) {
+	line := p.lines[k]
+	text := p.text(line)
+	m := pyClassPattern.FindStringSubmatchIndex(text)
+	name := text[m[2]:m[3]]
+	_, first := p.decorators(k)
+
+	var bases []string
+	header := line.start + m[1]
+	if header < line.end && p.code[header] == '(' {
+		close := matchingBracket(p.code, header)
+		for _, base := range splitTopLevel(p.code[header+1:close], ',', false) {
+			if strings.Contains(base, "=") {
+				continue // metaclass=..., arguments nommés
+			}
+			if i := strings.IndexByte(base, '['); i >= 0 {
+				base = base[:i]
+			}
+			bases = append(bases, strings.TrimSpace(base))
+		}
+		header = close + 1
+	}
+	bodyStart := header + topLevelIndex(p.code[header:line.end], ':') + 1
+	end := p.blockEnd(k)
+
+	typeInfo := interfaces.TypeInfo{
+		Name:          name,
+		Kind:          pyClassKind(bases),
+		Package:       p.result.Package,
+		BaseTypes:     bases,
+		IsExported:    p.exported(name),
+		Documentation: p.documentation(k, first, bodyStart),
+		LineStart:     p.lines[first].line,
+		LineEnd:       p.lines[end-1].endLine,
+	}
+
+	if end > k+1 {
+		bodyIndent := p.lines[k+1].indent
+		seen := make(map[string]bool)
+		for b := k + 1; b < end; b++ {
+			member := p.lines[b]
+			if member.indent != bodyIndent {
+				continue
+			}
+			memberText := p.text(member)
+			switch {
+			case pyDefPattern.MatchString(memberText):
+				method := p.function(b, name, typeInfo.IsExported)
+				typeInfo.Methods = append(typeInfo.Methods, method)
+				p.result.Functions = append(p.result.Functions, method)
+				if method.Name == "__init__" {
+					p.selfFields(&typeInfo, b, seen)
+				}
+			default:
+				if field, ok := p.classField(memberText, name, typeInfo.Kind); ok && !seen[field.Name] {
+					seen[field.Name] = true
+					typeInfo.Fields = append(typeInfo.Fields, field)
+				}
+			}
+		}
+	}
+	p.result.Types = append(p.result.Types, typeInfo)
+}
+
+// pyClassKind distingue les énumérations et les protocoles des classes
+func pyClassKind(bases []string) string {
+	for _, base := range bases {
+		switch base[strings.LastIndexByte(base, '.')+1:] {
+		case "Enum", "IntEnum", "StrEnum", "Flag", "IntFlag":
+			return "enum"
+		case "Protocol":
+			return "interface"
+		}
+	}
+	return "class"
+}
+
+// classField lit un attribut de classe "name: type = value"
+func (p *pyParser) classField(text, className, kind string) (interfaces.FieldInfo, bool) {
+	name, typeName, _, ok := pyAssignment(text)
+	if !ok {
+		return interfaces.FieldInfo{}, false
+	}
+	if kind == "enum" && typeName == "" {
+		typeName = className
+	}
+	return interfaces.FieldInfo{Name: name, Type: typeName, IsExported: !strings.HasPrefix(name, "_")}, true
+}
+
+// selfFields ajoute les attributs affectés à self dans __init__
+func (p *pyParser) selfFields(typeInfo *interfaces.TypeInfo, k int, seen map[string]bool) {
+	end := p.blockEnd(k)
+	if end <= k+1 {
+		return
+	}
+	body := p.code[p.lines[k+1].start:p.lines[end-1].end]
+	for _, m := range pySelfFieldPattern.FindAllStringSubmatch(body, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		typeInfo.Fields = append(typeInfo.Fields, interfaces.FieldInfo{
+			Name:       m[1],
+			Type:       strings.TrimSpace(m[2]),
+			IsExported: !strings.HasPrefix(m[1], "_"),
+		})
+	}
+}
+
+// pyAssignment reconnaît "name = value", "name: type" et "name: type = value"
+func pyAssignment(text string) (name, typeName, value string, ok bool) {
+	end := 0
+	for end < len(text) && isWordByte(text[end]) && text[end] != '$' {
+		end++
+	}
+	name = text[:end]
+	if !isIdentifier(name) || pythonKeywords[name] {
+		return "", "", "", false
+	}
+
+	rest := text[skipSpace(text, end):]
+	if strings.HasPrefix(rest, ":") {
+		equals := topLevelIndex(rest, '=')
+		typeName = compactSpace(rest[1:equals])
+		if typeName == "" {
+			return "", "", "", false
+		}
+		if equals == len(rest) {
+			return name, typeName, "", true
+		}
+		rest = rest[equals:]
+	}
+	if !strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "==") {
+		return "", "", "", false
+	}
+	return name, typeName, strings.TrimSpace(rest[1:]), true
+}
+
+// parseAssignment lit une variable ou une constante de module ; les noms
+// en majuscules et les annotations Final sont des constantes
+func (p *pyParser) parseAssignment(line pyLine, seen map[string]bool) {
+	name, typeName, masked, ok := pyAssignment(p.text(line))
+	if !ok || name == "__all__" || seen[name] {
+		return
+	}
+	seen[name] = true
+
+	// La valeur, suffixe de la ligne, est relue dans le texte d'origine
+	// pour garder les chaînes
+	value := ""
+	if masked != "" {
+		value = summarizeValue(p.s.src[line.end-len(masked) : line.end])
+	}
+	documentation := p.s.commentBefore(line.line, nil)
+
+	if pyConstantPattern.MatchString(name) || strings.HasPrefix(typeName, "Final") {
+		p.result.Constants = append(p.result.Constants, interfaces.ConstantInfo{
+			Name:          name,
+			Type:          typeName,
+			Value:         value,
+			Package:       p.result.Package,
+			IsExported:    p.exported(name),
+			LineNumber:    line.line,
+			Documentation: documentation,
+		})
+		return
+	}
+	p.result.Variables = append(p.result.Variables, interfaces.VariableInfo{
+		Name:          name,
+		Type:          typeName,
+		Package:       p.result.Package,
+		IsExported:    p.exported(name),
+		Value:         value,
+		LineNumber:    line.line,
+		Documentation: documentation,
+	})
+}
+
+// pyScanString reconnaît les chaînes simples et triples, avec préfixes r, b, f, u
+func pyScanString(src string, i int, previous string) int {
+	j := i
+	if strings.IndexByte(pyStringPrefixChars, src[j]) >= 0 {
+		if i > 0 && isWordByte(src[i-1]) {
+			return -1
+		}
+		for j < len(src) && j < i+2 && strings.IndexByte(pyStringPrefixChars, src[j]) >= 0 {
+			j++
+		}
+	}
+	if j >= len(src) || src[j] != '\'' && src[j] != '"' {
+		return -1
+	}
+
+	if triple := src[j:min(j+3, len(src))]; triple == `"""` || triple == `'''` {
+		for k := j + 3; k < len(src); k++ {
+			if src[k] == '\\' {
+				k++
+				continue
+			}
+			if strings.HasPrefix(src[k:], triple) {
+				return k + 3
+			}
+		}
+		return len(src)
+	}
+	return scanQuoted(src, j, '\\', true)
+}
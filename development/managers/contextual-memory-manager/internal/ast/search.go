@@ -0,0 +1,674 @@
+// internal/ast/search.go
+package ast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// Nombre de résultats retournés quand la requête n'a pas de limite
+const (
+	defaultStructuralLimit = 50
+	defaultSimilarLimit    = 10
+)
+
+// workspaceIndex retourne l'index structurel du workspace, rafraîchi des
+// fichiers modifiés depuis le dernier appel
+func (asm *astAnalysisManagerImpl) workspaceIndex(ctx context.Context, root string) (*structuralIndex, error) {
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		root = wd
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	asm.indexMu.Lock()
+	idx, exists := asm.indexes[root]
+	if !exists {
+		idx = newStructuralIndex(root, asm)
+		asm.indexes[root] = idx
+	}
+	asm.indexMu.Unlock()
+
+	changed, err := idx.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Les analyses de fichiers en cache ne sont plus valides
+	for _, path := range changed {
+		asm.cache.Delete(path)
+	}
+	return idx, nil
+}
+
+// indexForFile retourne l'index qui contient déjà le fichier, ou celui du
+// module qui le déclare
+func (asm *astAnalysisManagerImpl) indexForFile(ctx context.Context, filePath string) (*structuralIndex, string, error) {
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, "", fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+
+	root := ""
+	asm.indexMu.Lock()
+	for candidate := range asm.indexes {
+		if strings.HasPrefix(path, candidate+string(filepath.Separator)) && len(candidate) > len(root) {
+			root = candidate
+		}
+	}
+	asm.indexMu.Unlock()
+	if root == "" {
+		root, _ = findModule(filepath.Dir(path))
+		if root == "" {
+			root = filepath.Dir(path)
+		}
+	}
+
+	idx, err := asm.workspaceIndex(ctx, root)
+	return idx, path, err
+}
+
+func (asm *astAnalysisManagerImpl) SearchByStructure(ctx context.Context, query interfaces.StructuralQuery) ([]interfaces.StructuralResult, error) {
+	start := time.Now()
+
+	idx, err := asm.workspaceIndex(ctx, query.WorkspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index workspace: %w", err)
+	}
+
+	idx.mu.RLock()
+	var results []interfaces.StructuralResult
+	if strings.EqualFold(query.Type, "import") {
+		results = searchImports(idx, query)
+	} else {
+		results = searchSymbols(idx, query)
+	}
+	idx.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Relevance > results[j].Relevance
+	})
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultStructuralLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	if err := asm.monitoringManager.RecordOperation(ctx, "ast_structural_search", time.Since(start), nil); err != nil {
+		asm.errorManager.LogError(ctx, "ast_analyzer", "Failed to record search metrics", err)
+	}
+	return results, nil
+}
+
+func searchSymbols(idx *structuralIndex, query interfaces.StructuralQuery) []interfaces.StructuralResult {
+	// Hors Go, Implements désigne une classe ou une interface de base
+	// déclarée (BaseTypes) : l'absence d'interface Go n'arrête pas la recherche
+	var iface *indexedSymbol
+	if query.Implements != "" {
+		iface = idx.findInterface(query.Implements)
+	}
+
+	var callers map[string][]string
+	if query.IncludeUsages {
+		callers = make(map[string][]string)
+		for _, call := range idx.calls() {
+			callers[call.To] = append(callers[call.To], fmt.Sprintf("%s (%s:%d)", call.From, call.FilePath, call.Line))
+		}
+	}
+
+	results := make([]interfaces.StructuralResult, 0)
+	for _, symbol := range idx.symbols() {
+		relevance, ok := matchSymbol(symbol, query, iface)
+		if !ok {
+			continue
+		}
+
+		result := interfaces.StructuralResult{
+			FilePath:  symbol.FilePath,
+			MatchType: symbol.Kind,
+			Relevance: relevance,
+			Context: map[string]interface{}{
+				"id":           symbol.ID,
+				"package":      symbol.Package,
+				"package_name": symbol.PackageName,
+				"line":         symbol.Line,
+				"language":     symbol.Language,
+			},
+		}
+		if symbol.Function != nil {
+			result.Element = *symbol.Function
+		} else {
+			result.Element = *symbol.Type
+			result.Context["kind"] = symbol.Type.Kind
+		}
+		if symbol.Receiver != "" {
+			result.Context["receiver"] = symbol.Receiver
+		}
+		if iface != nil {
+			result.Context["implements"] = iface.ID
+		}
+		if callers != nil {
+			result.Context["usages"] = callers[symbol.ID]
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// matchSymbol vérifie tous les critères de la requête ; la pertinence est
+// la moyenne des scores des critères renseignés
+func matchSymbol(symbol *indexedSymbol, query interfaces.StructuralQuery, iface *indexedSymbol) (float64, bool) {
+	switch strings.ToLower(query.Type) {
+	case "", "any":
+	case "function", "func":
+		if symbol.Function == nil {
+			return 0, false
+		}
+	case "method":
+		if symbol.Kind != "method" {
+			return 0, false
+		}
+	case "type":
+		if symbol.Type == nil {
+			return 0, false
+		}
+	case "struct", "interface", "class", "enum":
+		if symbol.Type == nil || symbol.Type.Kind != strings.ToLower(query.Type) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if query.Language != "" && !strings.EqualFold(symbol.Language, query.Language) {
+		return 0, false
+	}
+
+	scores := make([]float64, 0, 6)
+	if query.Name != "" {
+		score := nameScore(symbol.Name, query.Name)
+		if score == 0 {
+			return 0, false
+		}
+		scores = append(scores, score)
+	}
+	if query.Package != "" {
+		if !packageMatches(symbol.Package, symbol.PackageName, query.Package) {
+			return 0, false
+		}
+		scores = append(scores, 1)
+	}
+
+	function := symbol.Function
+	if query.Signature != "" {
+		if function == nil || !strings.Contains(strings.ToLower(function.Signature), strings.ToLower(query.Signature)) &&
+			!strings.Contains(strings.ToLower(symbol.Shape), strings.ToLower(query.Signature)) {
+			return 0, false
+		}
+		scores = append(scores, 1)
+	}
+	if query.ReturnType != "" {
+		if function == nil || !anyTypeMatches(function.ReturnTypes, query.ReturnType) {
+			return 0, false
+		}
+		scores = append(scores, 1)
+	}
+	for _, wanted := range query.Parameters {
+		if function == nil || !hasParameter(function.Parameters, wanted) {
+			return 0, false
+		}
+		scores = append(scores, 1)
+	}
+	if query.Implements != "" {
+		if symbol.Type == nil {
+			return 0, false
+		}
+		goImplements := iface != nil && symbol.ID != iface.ID && implements(symbol, iface)
+		if !goImplements && !extends(symbol, query.Implements) {
+			return 0, false
+		}
+		scores = append(scores, 1)
+	}
+
+	if len(scores) == 0 {
+		return 0.5, true
+	}
+	total := 0.0
+	for _, score := range scores {
+		total += score
+	}
+	return total / float64(len(scores)), true
+}
+
+func nameScore(name, wanted string) float64 {
+	lowerName, lowerWanted := strings.ToLower(name), strings.ToLower(wanted)
+	switch {
+	case name == wanted:
+		return 1
+	case lowerName == lowerWanted:
+		return 0.9
+	case strings.HasPrefix(lowerName, lowerWanted):
+		return 0.75
+	case strings.Contains(lowerName, lowerWanted):
+		return 0.6
+	}
+	return 0
+}
+
+func packageMatches(path, name, wanted string) bool {
+	return name == wanted || path == wanted || strings.HasSuffix(path, "/"+wanted)
+}
+
+// typeMatches compare un type à celui de la requête : "context" ou
+// "Context" désignent "context.Context", "error" désigne "error"
+func typeMatches(actual, wanted string) bool {
+	if actual == wanted {
+		return true
+	}
+	actual = strings.TrimPrefix(strings.TrimPrefix(actual, "..."), "*")
+	wanted = strings.TrimPrefix(strings.TrimPrefix(wanted, "..."), "*")
+	if strings.EqualFold(actual, wanted) {
+		return true
+	}
+	if strings.Contains(wanted, ".") {
+		return false
+	}
+	if dot := strings.LastIndex(actual, "."); dot >= 0 {
+		return strings.EqualFold(actual[dot+1:], wanted) || strings.EqualFold(actual[:dot], wanted)
+	}
+	return false
+}
+
+func anyTypeMatches(actual []string, wanted string) bool {
+	for _, t := range actual {
+		if typeMatches(t, wanted) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasParameter(parameters []interfaces.ParameterInfo, wanted interfaces.ParameterInfo) bool {
+	for _, param := range parameters {
+		if wanted.Name != "" && param.Name != wanted.Name {
+			continue
+		}
+		if wanted.Type != "" && !typeMatches(param.Type, wanted.Type) {
+			continue
+		}
+		if wanted.IsVariadic && !param.IsVariadic {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// findInterface résout "interfaces.Manager", "io.Reader" ou "Manager"
+// parmi les interfaces du workspace puis celles de ses imports
+func (idx *structuralIndex) findInterface(name string) *indexedSymbol {
+	qualifier, typeName := "", name
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		qualifier, typeName = name[:dot], name[dot+1:]
+	}
+	matches := func(symbol *indexedSymbol) bool {
+		return symbol.Type != nil && symbol.Type.Kind == "interface" && symbol.Name == typeName &&
+			(qualifier == "" || packageMatches(symbol.Package, symbol.PackageName, qualifier))
+	}
+
+	for _, symbol := range idx.symbols() {
+		if matches(symbol) {
+			return symbol
+		}
+	}
+	ids := make([]string, 0, len(idx.external))
+	for id := range idx.external {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if matches(idx.external[id]) {
+			return idx.external[id]
+		}
+	}
+	if name == "error" {
+		return errorInterface
+	}
+	return nil
+}
+
+var errorInterface = &indexedSymbol{
+	ID:        "error",
+	Kind:      "type",
+	Name:      "error",
+	Type:      &interfaces.TypeInfo{Name: "error", Kind: "interface", IsExported: true},
+	MethodSet: map[string]string{"Error": "func() string"},
+}
+
+// implements compare les ensembles de méthodes par signature, ce qui reste
+// valable entre des packages chargés séparément
+func implements(symbol, iface *indexedSymbol) bool {
+	if symbol.MethodSet == nil || len(iface.MethodSet) == 0 {
+		return false
+	}
+	for name, signature := range iface.MethodSet {
+		if symbol.MethodSet[name] != signature {
+			return false
+		}
+	}
+	return true
+}
+
+// extends vérifie qu'un type hors Go déclare la base demandée ; "models.Base"
+// désigne aussi "Base" importée sous ce nom
+func extends(symbol *indexedSymbol, name string) bool {
+	wanted := name[strings.LastIndex(name, ".")+1:]
+	for _, base := range symbol.Type.BaseTypes {
+		if base == name || base[strings.LastIndex(base, ".")+1:] == wanted {
+			return true
+		}
+	}
+	return false
+}
+
+func searchImports(idx *structuralIndex, query interfaces.StructuralQuery) []interfaces.StructuralResult {
+	wanted := query.Name
+	if wanted == "" {
+		wanted = query.Package
+	}
+
+	paths := make([]string, 0, len(idx.files))
+	for path := range idx.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	results := make([]interfaces.StructuralResult, 0)
+	for _, path := range paths {
+		file := idx.files[path]
+		for _, imp := range file.Imports {
+			relevance := 0.5
+			if wanted != "" {
+				switch {
+				case imp.Path == wanted:
+					relevance = 1
+				case strings.HasSuffix(imp.Path, "/"+wanted):
+					relevance = 0.9
+				case strings.Contains(imp.Path, wanted):
+					relevance = 0.6
+				default:
+					continue
+				}
+			}
+			results = append(results, interfaces.StructuralResult{
+				FilePath:  path,
+				MatchType: "import",
+				Element:   imp,
+				Relevance: relevance,
+				Context:   map[string]interface{}{"package": file.Package, "line": imp.LineNumber, "language": file.Language},
+			})
+		}
+	}
+	return results
+}
+
+func (asm *astAnalysisManagerImpl) GetSimilarStructures(ctx context.Context, referenceFile string, limit int) ([]interfaces.StructuralMatch, error) {
+	idx, path, err := asm.indexForFile(ctx, referenceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index workspace: %w", err)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	file := idx.files[path]
+	if file == nil || file.Package == "" {
+		return nil, fmt.Errorf("file %s is not part of an indexed package", referenceFile)
+	}
+	features := idx.fileFeatures()
+	reference := features[path]
+
+	// Seuls les fichiers du même langage sont comparés
+	matches := make([]interfaces.StructuralMatch, 0)
+	for candidate, vector := range features {
+		if candidate == path || idx.files[candidate] == nil || idx.files[candidate].Language != file.Language {
+			continue
+		}
+		similarity := cosine(reference, vector)
+		if similarity <= 0 {
+			continue
+		}
+		matches = append(matches, interfaces.StructuralMatch{
+			FilePath:        candidate,
+			Similarity:      similarity,
+			MatchedElements: sharedFeatures(reference, vector),
+			Differences:     featureDifferences(reference, vector),
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].FilePath < matches[j].FilePath
+	})
+	if limit <= 0 {
+		limit = defaultSimilarLimit
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// fileFeatures décrit chaque fichier par ses formes de fonctions, ses
+// types, ses méthodes, ses imports et ses appels
+func (idx *structuralIndex) fileFeatures() map[string]map[string]float64 {
+	features := make(map[string]map[string]float64)
+	add := func(path, feature string, weight float64) {
+		if features[path] == nil {
+			features[path] = make(map[string]float64)
+		}
+		features[path][feature] += weight
+	}
+
+	for _, symbol := range idx.symbols() {
+		switch symbol.Kind {
+		case "function":
+			add(symbol.FilePath, symbol.Shape, 2)
+		case "method":
+			add(symbol.FilePath, "method "+symbol.Name+" "+symbol.Shape, 2)
+		case "type":
+			add(symbol.FilePath, "type "+symbol.Type.Kind, 1)
+			for _, field := range symbol.Type.Fields {
+				add(symbol.FilePath, "field "+field.Type, 1)
+			}
+			if symbol.Type.Kind == "interface" {
+				for _, method := range symbol.Type.Methods {
+					add(symbol.FilePath, "interface method "+method.Name, 2)
+				}
+			}
+		}
+	}
+	for path, file := range idx.files {
+		if file.Package == "" {
+			continue
+		}
+		for _, imp := range file.Imports {
+			add(path, "import "+imp.Path, 1)
+		}
+	}
+	for _, call := range idx.calls() {
+		add(call.FilePath, "call "+call.To, 0.5)
+	}
+	return features
+}
+
+func cosine(a, b map[string]float64) float64 {
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for feature, weight := range a {
+		dot += weight * b[feature]
+		normA += weight * weight
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// Nombre d'éléments listés dans MatchedElements et Differences
+const maxListedFeatures = 10
+
+func sharedFeatures(reference, candidate map[string]float64) []string {
+	var shared []string
+	for feature := range reference {
+		if candidate[feature] > 0 {
+			shared = append(shared, feature)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		wi := math.Min(reference[shared[i]], candidate[shared[i]])
+		wj := math.Min(reference[shared[j]], candidate[shared[j]])
+		if wi != wj {
+			return wi > wj
+		}
+		return shared[i] < shared[j]
+	})
+	if len(shared) > maxListedFeatures {
+		shared = shared[:maxListedFeatures]
+	}
+	return shared
+}
+
+// featureDifferences liste ce qui manque au candidat ("-") et ce qu'il a
+// en plus ("+") par rapport au fichier de référence
+func featureDifferences(reference, candidate map[string]float64) []string {
+	var missing, extra []string
+	for feature := range reference {
+		if candidate[feature] == 0 {
+			missing = append(missing, "- "+feature)
+		}
+	}
+	for feature := range candidate {
+		if reference[feature] == 0 {
+			extra = append(extra, "+ "+feature)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	differences := append(missing, extra...)
+	if len(differences) > maxListedFeatures {
+		differences = differences[:maxListedFeatures]
+	}
+	return differences
+}
+
+var (
+	queryKindPattern       = regexp.MustCompile(`(?i)^\s*(?:all\s+|the\s+)?(?:(go|golang|typescript|ts|javascript|js|python|py|powershell)\s+)?(functions?|funcs?|methods?|types?|structs?|interfaces?|classes|class|enums?|imports?)\b`)
+	queryLanguagePattern   = regexp.MustCompile(`(?i)\b(?:in|written\s+in)\s+(go|golang|typescript|javascript|python|powershell)\b`)
+	queryReturnPattern     = regexp.MustCompile(`(?i)\b(?:returning|returns?|that\s+returns?|which\s+returns?)\s+(?:an?\s+)?([\w.*\[\]]+)`)
+	queryParameterPattern  = regexp.MustCompile(`(?i)\b(?:tak(?:e|es|ing)|accept(?:s|ing)?)\s+(.+?)(?:\s+(?:and\s+)?(?:that|which|returning|returns?|implementing|extending|named|called|in)\b|$)`)
+	queryImplementsPattern = regexp.MustCompile(`(?i)\b(?:implement(?:s|ing)?|extend(?:s|ing)?|inherit(?:s|ing)?\s+from)\s+([\w.]+)`)
+	queryNamePattern       = regexp.MustCompile(`(?i)\b(?:named|called)\s+([\w]+)`)
+	queryPackagePattern    = regexp.MustCompile(`(?i)\bin\s+(?:package\s+([\w./-]+)|([\w./-]+)\s+package)`)
+	queryArticlePattern    = regexp.MustCompile(`(?i)^(?:an?|the)\s+`)
+)
+
+// ParseStructuralQuery traduit une requête en langage naturel comme
+// "functions returning error that take a context", "types implementing
+// interfaces.Manager" ou "python classes extending Base" en
+// StructuralQuery. Un texte sans structure reconnue est recherché comme nom.
+func ParseStructuralQuery(text string) interfaces.StructuralQuery {
+	query := interfaces.StructuralQuery{Type: "any"}
+	structured := false
+
+	if m := queryKindPattern.FindStringSubmatch(text); m != nil {
+		kind := strings.ToLower(m[2])
+		switch kind {
+		case "func", "funcs":
+			kind = "function"
+		case "classes":
+			kind = "class"
+		default:
+			kind = strings.TrimSuffix(kind, "s")
+		}
+		query.Type = kind
+		query.Language = queryLanguage(m[1])
+		structured = true
+	}
+	if m := queryLanguagePattern.FindStringSubmatch(text); m != nil {
+		query.Language = queryLanguage(m[1])
+		structured = true
+	}
+	if m := queryReturnPattern.FindStringSubmatch(text); m != nil {
+		query.ReturnType = m[1]
+		structured = true
+	}
+	if m := queryParameterPattern.FindStringSubmatch(text); m != nil {
+		for _, part := range regexp.MustCompile(`\s*,\s*|\s+and\s+`).Split(m[1], -1) {
+			part = strings.TrimSpace(queryArticlePattern.ReplaceAllString(strings.TrimSpace(part), ""))
+			if part != "" {
+				query.Parameters = append(query.Parameters, interfaces.ParameterInfo{Type: part})
+			}
+		}
+		structured = true
+	}
+	if m := queryImplementsPattern.FindStringSubmatch(text); m != nil {
+		query.Implements = m[1]
+		if query.Type == "any" {
+			query.Type = "type"
+		}
+		structured = true
+	}
+	if m := queryNamePattern.FindStringSubmatch(text); m != nil {
+		query.Name = m[1]
+		structured = true
+	}
+	if m := queryPackagePattern.FindStringSubmatch(text); m != nil {
+		query.Package = m[1] + m[2]
+		structured = true
+	}
+
+	if !structured {
+		query.Name = strings.TrimSpace(text)
+	}
+	return query
+}
+
+// queryLanguage normalise le nom d'un langage cité dans une requête
+func queryLanguage(name string) string {
+	switch strings.ToLower(name) {
+	case "go", "golang":
+		return "go"
+	case "ts", "typescript":
+		return "typescript"
+	case "js", "javascript":
+		return "javascript"
+	case "py", "python":
+		return "python"
+	case "powershell":
+		return "powershell"
+	}
+	return ""
+}
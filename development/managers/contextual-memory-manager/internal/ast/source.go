@@ -0,0 +1,445 @@
+// internal/ast/source.go
+package ast
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// sourceText est le texte d'un fichier dans lequel les commentaires et le
+// contenu des chaînes sont remplacés par des espaces (code). Les frontends
+// repèrent les déclarations par expressions régulières sur code et
+// apparient les accolades sans être trompés par les chaînes ; les positions
+// et les numéros de ligne restent ceux du fichier d'origine.
+type sourceText struct {
+	src        string
+	code       string
+	lineStarts []int
+	comments   []sourceComment
+	strings    []sourceSpan
+}
+
+type sourceComment struct {
+	start, end         int
+	startLine, endLine int
+	text               string // sans délimiteurs
+}
+
+type sourceSpan struct {
+	start, end int // délimiteurs compris
+}
+
+// lexicalRules décrit les commentaires et les chaînes d'un langage
+type lexicalRules struct {
+	lineComments  []string
+	blockComments [][2]string
+	// scanString reconnaît une chaîne (ou un littéral d'expression
+	// régulière) à la position i et retourne sa fin, ou -1 ; previous est
+	// le code significatif qui précède
+	scanString func(src string, i int, previous string) int
+	// cleanComment retire les décorations d'un commentaire ("*" de JSDoc...)
+	cleanComment func(text string) string
+}
+
+func newSourceText(src string, rules lexicalRules) *sourceText {
+	s := &sourceText{src: src, lineStarts: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			s.lineStarts = append(s.lineStarts, i+1)
+		}
+	}
+
+	code := []byte(src)
+	blank := func(start, end int, keepDelimiters bool) {
+		for j := start; j < end; j++ {
+			if code[j] == '\n' || keepDelimiters && (j == start || j == end-1) {
+				continue
+			}
+			code[j] = ' '
+		}
+	}
+
+	i := 0
+scan:
+	for i < len(src) {
+		for _, block := range rules.blockComments {
+			if strings.HasPrefix(src[i:], block[0]) {
+				end := strings.Index(src[i+len(block[0]):], block[1])
+				if end < 0 {
+					end = len(src)
+				} else {
+					end += i + len(block[0]) + len(block[1])
+				}
+				text := src[i+len(block[0]) : max(i+len(block[0]), end-len(block[1]))]
+				s.addComment(i, end, text, rules, false)
+				blank(i, end, false)
+				i = end
+				continue scan
+			}
+		}
+		for _, marker := range rules.lineComments {
+			if strings.HasPrefix(src[i:], marker) {
+				end := strings.IndexByte(src[i:], '\n')
+				if end < 0 {
+					end = len(src)
+				} else {
+					end += i
+				}
+				s.addComment(i, end, src[i+len(marker):end], rules, true)
+				blank(i, end, false)
+				i = end
+				continue scan
+			}
+		}
+		if rules.scanString != nil {
+			if end := rules.scanString(src, i, lastSignificant(code[:i])); end > i {
+				s.strings = append(s.strings, sourceSpan{start: i, end: end})
+				blank(i, end, true)
+				i = end
+				continue
+			}
+		}
+		i++
+	}
+	s.code = string(code)
+	return s
+}
+
+// addComment fusionne les commentaires de ligne consécutifs
+func (s *sourceText) addComment(start, end int, text string, rules lexicalRules, line bool) {
+	if rules.cleanComment != nil {
+		text = rules.cleanComment(text)
+	}
+	comment := sourceComment{start: start, end: end, startLine: s.lineOf(start), endLine: s.lineOf(max(start, end-1)), text: strings.TrimSpace(text)}
+	if line && len(s.comments) > 0 {
+		previous := &s.comments[len(s.comments)-1]
+		if previous.endLine == comment.startLine-1 && strings.TrimSpace(s.src[previous.end:start]) == "" &&
+			strings.TrimSpace(s.src[s.lineStarts[comment.startLine-1]:start]) == "" {
+			previous.end = end
+			previous.endLine = comment.endLine
+			previous.text = strings.TrimSpace(previous.text + "\n" + comment.text)
+			return
+		}
+	}
+	s.comments = append(s.comments, comment)
+}
+
+// lastSignificant retourne le dernier mot ou caractère non blanc du code
+func lastSignificant(code []byte) string {
+	end := len(code)
+	for end > 0 && (code[end-1] == ' ' || code[end-1] == '\t' || code[end-1] == '\n' || code[end-1] == '\r') {
+		end--
+	}
+	if end == 0 {
+		return ""
+	}
+	start := end - 1
+	for start > 0 && isWordByte(code[start-1]) && isWordByte(code[end-1]) {
+		start--
+	}
+	return string(code[start:end])
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '$' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// lineOf retourne le numéro de ligne (à partir de 1) d'une position
+func (s *sourceText) lineOf(offset int) int {
+	return sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > offset })
+}
+
+func (s *sourceText) lineCount() int {
+	if strings.HasSuffix(s.src, "\n") {
+		return len(s.lineStarts) - 1
+	}
+	return len(s.lineStarts)
+}
+
+// stringAt retourne le contenu de la chaîne qui commence à offset
+func (s *sourceText) stringAt(offset int) (string, bool) {
+	i := sort.Search(len(s.strings), func(i int) bool { return s.strings[i].start >= offset })
+	if i == len(s.strings) || s.strings[i].start != offset {
+		return "", false
+	}
+	return unquote(s.src[s.strings[i].start:s.strings[i].end]), true
+}
+
+// stringAfter retourne la première chaîne qui commence à partir d'offset
+// et avant limit
+func (s *sourceText) stringAfter(offset, limit int) (string, int, bool) {
+	i := sort.Search(len(s.strings), func(i int) bool { return s.strings[i].start >= offset })
+	if i == len(s.strings) || s.strings[i].start >= limit {
+		return "", 0, false
+	}
+	return unquote(s.src[s.strings[i].start:s.strings[i].end]), s.strings[i].start, true
+}
+
+func unquote(literal string) string {
+	for _, delimiter := range []string{`"""`, `'''`, `@"`, `@'`} {
+		if strings.HasPrefix(literal, delimiter) {
+			closing := delimiter
+			if delimiter[0] == '@' {
+				closing = delimiter[1:] + "@"
+			}
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(literal, delimiter), closing))
+		}
+	}
+	if len(literal) >= 2 {
+		return literal[1 : len(literal)-1]
+	}
+	return literal
+}
+
+// commentBefore retourne le commentaire qui se termine juste avant la
+// ligne, en sautant les lignes de décorateurs ou d'attributs
+func (s *sourceText) commentBefore(line int, skip func(string) bool) string {
+	for i := len(s.comments) - 1; i >= 0; i-- {
+		comment := s.comments[i]
+		if comment.endLine >= line {
+			continue
+		}
+		for between := comment.endLine + 1; between < line; between++ {
+			text := strings.TrimSpace(s.lineText(between))
+			if text == "" || skip == nil || !skip(text) {
+				return ""
+			}
+		}
+		return comment.text
+	}
+	return ""
+}
+
+func (s *sourceText) lineText(line int) string {
+	if line < 1 || line > len(s.lineStarts) {
+		return ""
+	}
+	end := len(s.src)
+	if line < len(s.lineStarts) {
+		end = s.lineStarts[line] - 1
+	}
+	return s.src[s.lineStarts[line-1]:end]
+}
+
+// braceDepths calcule la profondeur d'accolades de chaque position du code
+func braceDepths(code string) []int {
+	depths := make([]int, len(code)+1)
+	depth := 0
+	for i := 0; i < len(code); i++ {
+		depths[i] = depth
+		switch code[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	depths[len(code)] = depth
+	return depths
+}
+
+// matchingBracket retourne la position du délimiteur fermant celui ouvert
+// à open, ou la fin du code
+func matchingBracket(code string, open int) int {
+	var stack []byte
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case '(', '[', '{':
+			stack = append(stack, code[i])
+		case ')', ']', '}':
+			if len(stack) == 0 {
+				return i
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i
+			}
+		}
+	}
+	return len(code) - 1
+}
+
+// splitTopLevel découpe une liste au premier niveau de parenthèses,
+// crochets, accolades (et chevrons de génériques si angles)
+func splitTopLevel(list string, separator byte, angles bool) []string {
+	var parts []string
+	for _, span := range splitSpans(list, separator, angles) {
+		parts = append(parts, list[span[0]:span[1]])
+	}
+	return parts
+}
+
+// splitSpans découpe le code masqué et retourne les positions des
+// éléments, pour relire le texte d'origine aux mêmes positions
+func splitSpans(code string, separator byte, angles bool) [][2]int {
+	var spans [][2]int
+	add := func(start, end int) {
+		for start < end && isSpace(code[start]) {
+			start++
+		}
+		for end > start && isSpace(code[end-1]) {
+			end--
+		}
+		if end > start {
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+
+	depth, start := 0, 0
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '(' || c == '[' || c == '{' || angles && c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || angles && c == '>' && (i == 0 || code[i-1] != '='):
+			if depth > 0 {
+				depth--
+			}
+		case c == separator && depth == 0:
+			add(start, i)
+			start = i + 1
+		}
+	}
+	add(start, len(code))
+	return spans
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// compactSpace ramène les blancs d'un extrait sur plusieurs lignes à un espace
+func compactSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// countKeywords compte les mots-clés de branchement d'un extrait de code
+func countKeywords(code string, pattern *regexp.Regexp) int {
+	count := 0
+	for _, match := range pattern.FindAllStringIndex(code, -1) {
+		if match[0] > 0 && (code[match[0]-1] == '$' || code[match[0]-1] == '-' || code[match[0]-1] == '.') {
+			continue
+		}
+		if match[1] < len(code) && code[match[1]] == '-' {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// finishResult complète les champs communs aux frontends hors Go
+func finishResult(result *interfaces.ASTAnalysisResult, s *sourceText, branches *regexp.Regexp) {
+	sortDeclarations(result)
+	for _, imp := range result.Imports {
+		result.Dependencies = append(result.Dependencies, interfaces.DependencyRelation{
+			From:       result.Package,
+			To:         imp.Path,
+			Type:       "import",
+			LineNumber: imp.LineNumber,
+		})
+	}
+
+	result.Complexity = interfaces.ComplexityMetrics{
+		CyclomaticComplexity: countKeywords(s.code, branches),
+		LinesOfCode:          s.lineCount(),
+		FunctionCount:        len(result.Functions),
+		TypeCount:            len(result.Types),
+		DependencyCount:      len(result.Imports),
+	}
+	if result.Context == nil {
+		result.Context = make(map[string]interface{})
+	}
+	result.Context["package"] = result.Package
+	result.Context["import_count"] = len(result.Imports)
+}
+
+// directoryPackage nomme le "package" d'un fichier hors Go d'après son répertoire
+func directoryPackage(filePath string) string {
+	dir := filepath.Base(filepath.Dir(filePath))
+	if dir == "." || dir == string(filepath.Separator) {
+		return strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+	return dir
+}
+
+// functionShape écrit la forme d'une fonction d'un frontend hors Go, comme
+// shapeString pour le Go : "func(string, number) Promise<void>"
+func functionShape(function interfaces.FunctionInfo) string {
+	types := make([]string, 0, len(function.Parameters))
+	for _, param := range function.Parameters {
+		t := param.Type
+		if t == "" {
+			t = "any"
+		}
+		types = append(types, t)
+	}
+	shape := "func(" + strings.Join(types, ", ") + ")"
+	switch len(function.ReturnTypes) {
+	case 0:
+	case 1:
+		shape += " " + function.ReturnTypes[0]
+	default:
+		shape += " (" + strings.Join(function.ReturnTypes, ", ") + ")"
+	}
+	return shape
+}
+
+// scanQuoted retourne la fin de la chaîne délimitée par src[i] ; escape
+// protège le caractère suivant (0 sans échappement) et une fin de ligne
+// termine la chaîne si singleLine
+func scanQuoted(src string, i int, escape byte, singleLine bool) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case quote:
+			return j + 1
+		case escape:
+			j++
+		case '\n':
+			if singleLine {
+				return j
+			}
+		}
+	}
+	return len(src)
+}
+
+// skipSpace retourne la première position non blanche à partir de i
+func skipSpace(code string, i int) int {
+	for i < len(code) && isSpace(code[i]) {
+		i++
+	}
+	return i
+}
+
+// skipBlank saute les espaces et tabulations sans changer de ligne
+func skipBlank(code string, i int) int {
+	for i < len(code) && (code[i] == ' ' || code[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// summarizeValue abrège la valeur d'une constante ou d'une variable
+func summarizeValue(text string) string {
+	text = compactSpace(text)
+	if runes := []rune(text); len(runes) > 80 {
+		return string(runes[:77]) + "..."
+	}
+	return text
+}
+
+// sortDeclarations range les déclarations dans l'ordre du fichier
+func sortDeclarations(result *interfaces.ASTAnalysisResult) {
+	sort.SliceStable(result.Imports, func(i, j int) bool { return result.Imports[i].LineNumber < result.Imports[j].LineNumber })
+	sort.SliceStable(result.Functions, func(i, j int) bool { return result.Functions[i].LineStart < result.Functions[j].LineStart })
+	sort.SliceStable(result.Types, func(i, j int) bool { return result.Types[i].LineStart < result.Types[j].LineStart })
+	sort.SliceStable(result.Variables, func(i, j int) bool { return result.Variables[i].LineNumber < result.Variables[j].LineNumber })
+	sort.SliceStable(result.Constants, func(i, j int) bool { return result.Constants[i].LineNumber < result.Constants[j].LineNumber })
+}
@@ -0,0 +1,430 @@
+// internal/ast/traversal.go
+package ast
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+func (asm *astAnalysisManagerImpl) TraverseFileSystem(ctx context.Context, rootPath string, filters interfaces.TraversalFilters) (*interfaces.FileSystemGraph, error) {
+	start := time.Now()
+
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", rootPath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", rootPath)
+	}
+
+	graph := &interfaces.FileSystemGraph{
+		Root:          root,
+		Nodes:         make(map[string]*interfaces.FileNode),
+		Relationships: make([]interfaces.FileRelation, 0),
+	}
+	graph.Nodes[root] = &interfaces.FileNode{Path: root, Type: "directory", ModTime: info.ModTime()}
+
+	walker := &fileSystemWalker{
+		root:    root,
+		filters: filters,
+		graph:   graph,
+		visited: map[string]bool{},
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		walker.visited[real] = true
+	}
+	if err := walker.walk(ctx, root, 0); err != nil {
+		return nil, err
+	}
+
+	// Relations d'import entre fichiers Go et répertoires des packages locaux
+	moduleDir, modulePath := findModule(root)
+	if modulePath != "" {
+		fset := token.NewFileSet()
+		for _, filePath := range walker.goFiles {
+			file, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			graph.Nodes[filePath].IsAnalyzed = true
+			for _, imp := range file.Imports {
+				importPath := strings.Trim(imp.Path.Value, `"`)
+				if importPath != modulePath && !strings.HasPrefix(importPath, modulePath+"/") {
+					continue
+				}
+				dir := filepath.Join(moduleDir, filepath.FromSlash(strings.TrimPrefix(importPath, modulePath)))
+				if _, inGraph := graph.Nodes[dir]; inGraph {
+					graph.Relationships = append(graph.Relationships, interfaces.FileRelation{From: filePath, To: dir, Type: "imports"})
+				}
+			}
+		}
+	}
+
+	graph.TraversalTime = time.Since(start)
+	return graph, nil
+}
+
+type fileSystemWalker struct {
+	root    string
+	filters interfaces.TraversalFilters
+	graph   *interfaces.FileSystemGraph
+	visited map[string]bool // répertoires réels déjà parcourus (cycles de liens)
+	goFiles []string
+}
+
+func (w *fileSystemWalker) walk(ctx context.Context, dir string, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		rel, _ := filepath.Rel(w.root, entryPath)
+		rel = filepath.ToSlash(rel)
+		if matchesAny(rel, w.filters.ExcludePaths) {
+			continue
+		}
+
+		info, err := os.Lstat(entryPath)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !w.filters.FollowSymlinks {
+				continue
+			}
+			if info, err = os.Stat(entryPath); err != nil {
+				continue
+			}
+		}
+
+		if info.IsDir() {
+			if w.filters.MaxDepth > 0 && depth+1 >= w.filters.MaxDepth {
+				continue
+			}
+			real, err := filepath.EvalSymlinks(entryPath)
+			if err != nil || w.visited[real] {
+				continue
+			}
+			w.visited[real] = true
+			if !w.mayContainIncluded(rel) {
+				continue
+			}
+			w.graph.Nodes[entryPath] = &interfaces.FileNode{Path: entryPath, Type: "directory", ModTime: info.ModTime()}
+			w.graph.Relationships = append(w.graph.Relationships, interfaces.FileRelation{From: dir, To: entryPath, Type: "contains"})
+			if err := w.walk(ctx, entryPath, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(w.filters.IncludePaths) > 0 && !w.included(rel) {
+			continue
+		}
+		extension := filepath.Ext(entry.Name())
+		if !extensionAllowed(extension, w.filters.Extensions) {
+			continue
+		}
+		w.graph.Nodes[entryPath] = &interfaces.FileNode{
+			Path:      entryPath,
+			Type:      "file",
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			Extension: extension,
+		}
+		w.graph.Relationships = append(w.graph.Relationships, interfaces.FileRelation{From: dir, To: entryPath, Type: "contains"})
+		if extension == ".go" {
+			w.goFiles = append(w.goFiles, entryPath)
+		}
+	}
+	return nil
+}
+
+// included indique si un fichier relève d'un des chemins inclus
+func (w *fileSystemWalker) included(rel string) bool {
+	for _, include := range w.filters.IncludePaths {
+		include = strings.TrimSuffix(filepath.ToSlash(include), "/")
+		if rel == include || strings.HasPrefix(rel, include+"/") || globMatch(include, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayContainIncluded évite de descendre dans les répertoires sans rapport
+// avec les chemins inclus
+func (w *fileSystemWalker) mayContainIncluded(rel string) bool {
+	if len(w.filters.IncludePaths) == 0 || w.included(rel) {
+		return true
+	}
+	for _, include := range w.filters.IncludePaths {
+		include = filepath.ToSlash(include)
+		if strings.HasPrefix(include, rel+"/") || strings.ContainsAny(include, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny accepte un chemin relatif exact, un préfixe de répertoire, un
+// motif glob sur le chemin ou sur le nom ("vendor", "*.pb.go")
+func matchesAny(rel string, patterns []string) bool {
+	base := path.Base(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if rel == pattern || strings.HasPrefix(rel, pattern+"/") || base == pattern ||
+			globMatch(pattern, rel) || globMatch(pattern, base) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+func extensionAllowed(extension string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, candidate := range allowed {
+		if !strings.HasPrefix(candidate, ".") {
+			candidate = "." + candidate
+		}
+		if strings.EqualFold(candidate, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// findModule remonte jusqu'au go.mod qui contient dir et retourne son
+// répertoire et son chemin de module
+func findModule(dir string) (string, string) {
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return dir, modfile.ModulePath(data)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+func (asm *astAnalysisManagerImpl) MapDependencies(ctx context.Context, filePath string) (*interfaces.DependencyGraph, error) {
+	start := time.Now()
+
+	idx, path, err := asm.indexForFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index workspace: %w", err)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	file := idx.files[path]
+	if file == nil || file.Package == "" {
+		return nil, fmt.Errorf("file %s is not part of an indexed package", filePath)
+	}
+
+	graph := &interfaces.DependencyGraph{
+		Nodes:  make(map[string]*interfaces.DependencyNode),
+		Edges:  make([]interfaces.DependencyEdge, 0),
+		Levels: make(map[string]int),
+	}
+	symbols := make(map[string]*indexedSymbol)
+	for _, symbol := range idx.symbols() {
+		symbols[symbol.ID] = symbol
+	}
+	callsFrom := make(map[string]map[string]int)
+	calleePackage := make(map[string]string)
+	for _, call := range idx.calls() {
+		if callsFrom[call.From] == nil {
+			callsFrom[call.From] = make(map[string]int)
+		}
+		callsFrom[call.From][call.To]++
+		calleePackage[call.To] = call.ToPackage
+	}
+
+	addSymbol := func(symbol *indexedSymbol) {
+		graph.Nodes[symbol.ID] = &interfaces.DependencyNode{
+			ID:       symbol.ID,
+			Type:     symbol.Kind,
+			Package:  symbol.Package,
+			FilePath: symbol.FilePath,
+			Metadata: map[string]interface{}{"name": symbol.Name, "line": symbol.Line},
+		}
+	}
+
+	// Le fichier, ses imports et ses déclarations
+	graph.Nodes[path] = &interfaces.DependencyNode{ID: path, Type: "file", Package: file.Package, FilePath: path,
+		Metadata: map[string]interface{}{"imports": len(file.Imports)}}
+	for _, imp := range file.Imports {
+		if graph.Nodes[imp.Path] == nil {
+			graph.Nodes[imp.Path] = &interfaces.DependencyNode{ID: imp.Path, Type: "package", Package: imp.Path,
+				Metadata: map[string]interface{}{"standard": imp.IsStandard}}
+		}
+		graph.Edges = append(graph.Edges, interfaces.DependencyEdge{From: path, To: imp.Path, Type: "imports", Weight: 1})
+	}
+
+	// Fermeture des appels depuis les fonctions du fichier : les fonctions
+	// du workspace sont suivies, les fonctions externes sont des feuilles
+	var queue []string
+	for _, symbol := range idx.symbols() {
+		if symbol.FilePath != path {
+			continue
+		}
+		addSymbol(symbol)
+		graph.Edges = append(graph.Edges, interfaces.DependencyEdge{From: path, To: symbol.ID, Type: "declares", Weight: 1})
+		queue = append(queue, symbol.ID)
+	}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+
+		if symbol := symbols[from]; symbol != nil && symbol.Kind == "method" {
+			owner := symbol.Owner
+			if owner == "" {
+				owner = symbol.Package + "." + symbol.Receiver
+			}
+			if ownerSymbol := symbols[owner]; ownerSymbol != nil {
+				if graph.Nodes[owner] == nil {
+					addSymbol(ownerSymbol)
+				}
+				graph.Edges = append(graph.Edges, interfaces.DependencyEdge{From: from, To: owner, Type: "method_of", Weight: 1})
+			}
+		}
+
+		callees := make([]string, 0, len(callsFrom[from]))
+		for to := range callsFrom[from] {
+			callees = append(callees, to)
+		}
+		sort.Strings(callees)
+		for _, to := range callees {
+			if graph.Nodes[to] == nil {
+				if symbol := symbols[to]; symbol != nil {
+					addSymbol(symbol)
+					queue = append(queue, to)
+				} else {
+					graph.Nodes[to] = &interfaces.DependencyNode{ID: to, Type: "external", Package: calleePackage[to],
+						Metadata: map[string]interface{}{}}
+				}
+			}
+			graph.Edges = append(graph.Edges, interfaces.DependencyEdge{From: from, To: to, Type: "calls", Weight: float64(callsFrom[from][to])})
+		}
+	}
+
+	graph.Cycles, graph.Levels = dependencyLevels(graph)
+	graph.BuildTime = time.Since(start)
+	return graph, nil
+}
+
+// dependencyLevels calcule les composantes fortement connexes (Tarjan) du
+// graphe : celles de plus d'un nœud, ou avec une boucle, sont des cycles.
+// Le niveau d'un nœud est 0 sans dépendance, sinon 1 + le niveau maximal de
+// ses dépendances hors de sa composante.
+func dependencyLevels(graph *interfaces.DependencyGraph) ([][]string, map[string]int) {
+	successors := make(map[string][]string)
+	selfLoop := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		successors[edge.From] = append(successors[edge.From], edge.To)
+		if edge.From == edge.To {
+			selfLoop[edge.From] = true
+		}
+	}
+	ids := make([]string, 0, len(graph.Nodes))
+	for id := range graph.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	index, lowLink := make(map[string]int), make(map[string]int)
+	onStack := make(map[string]bool)
+	component := make(map[string]int)
+	var stack []string
+	var components [][]string
+	var strongConnect func(string)
+	strongConnect = func(v string) {
+		index[v] = len(index)
+		lowLink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range successors[v] {
+			if _, seen := index[w]; !seen {
+				strongConnect(w)
+				lowLink[v] = min(lowLink[v], lowLink[w])
+			} else if onStack[w] {
+				lowLink[v] = min(lowLink[v], index[w])
+			}
+		}
+		if lowLink[v] == index[v] {
+			var members []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component[w] = len(components)
+				members = append(members, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Strings(members)
+			components = append(components, members)
+		}
+	}
+	for _, id := range ids {
+		if _, seen := index[id]; !seen {
+			strongConnect(id)
+		}
+	}
+
+	// Tarjan produit les composantes dans l'ordre topologique inverse : les
+	// dépendances d'une composante sont déjà calculées
+	var cycles [][]string
+	componentLevel := make([]int, len(components))
+	levels := make(map[string]int, len(ids))
+	for c, members := range components {
+		if len(members) > 1 || selfLoop[members[0]] {
+			cycles = append(cycles, members)
+		}
+		level := 0
+		for _, v := range members {
+			for _, w := range successors[v] {
+				if component[w] != c {
+					level = max(level, componentLevel[component[w]]+1)
+				}
+			}
+		}
+		componentLevel[c] = level
+		for _, v := range members {
+			levels[v] = level
+		}
+	}
+	return cycles, levels
+}
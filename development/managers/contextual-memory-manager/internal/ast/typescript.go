@@ -0,0 +1,1033 @@
+// internal/ast/typescript.go
+package ast
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// typeScriptFrontend analyse TypeScript et JavaScript (modules ES et
+// CommonJS). Seules les déclarations de premier niveau et les membres de
+// classes et d'interfaces sont extraits, comme pour le Go.
+type typeScriptFrontend struct{}
+
+func (f *typeScriptFrontend) Language() string { return "typescript" }
+
+func (f *typeScriptFrontend) Extensions() []string {
+	return []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+}
+
+var (
+	tsImportPattern        = regexp.MustCompile(`(?:^|[^\w$.])import\s+(?:type\s+)?([\w$*{}\s,]*?)\s*(?:\bfrom\s*)?['"]`)
+	tsRequirePattern       = regexp.MustCompile(`(?:^|[^\w$.])(?:require|import)\s*\(\s*['"]`)
+	tsExportFromPattern    = regexp.MustCompile(`(?:^|[^\w$.])export\s+(?:type\s+)?(?:\*(?:\s+as\s+[\w$]+)?|\{[^}]*\})\s*from\s*['"]`)
+	tsRequireAliasPattern  = regexp.MustCompile(`(?:const|let|var|import)\s+([\w$]+)\s*=\s*(?:await\s+)?$`)
+	tsFunctionPattern      = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|default|declare|async)\s+)*)function\b\s*\*?\s*([\w$]+)\s*(?:<[^(]*>)?\s*\(`)
+	tsClassPattern         = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|default|declare|abstract)\s+)*)class\s+([\w$]+)`)
+	tsInterfacePattern     = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|declare)\s+)*)interface\s+([\w$]+)`)
+	tsTypeAliasPattern     = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|declare)\s+)*)type\s+([\w$]+)\s*(?:<[^=]*>)?\s*=`)
+	tsEnumPattern          = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|declare|const)\s+)*)enum\s+([\w$]+)\s*\{`)
+	tsVariablePattern      = regexp.MustCompile(`(?:^|[^\w$.])((?:(?:export|declare)\s+)*)(const|let|var)\s+([\w$]+)`)
+	tsMethodPattern        = regexp.MustCompile(`^((?:(?:public|private|protected|static|readonly|async|abstract|override|declare|get|set)\s+)*)\*?\s*(#?[\w$]+)\s*\??\s*(?:<[^(]*>)?\s*\(`)
+	tsFieldPattern         = regexp.MustCompile(`^((?:(?:public|private|protected|static|readonly|declare|override|accessor)\s+)*)(#?[\w$]+)\s*[?!]?\s*([:=;]|$)`)
+	tsSignaturePattern     = regexp.MustCompile(`^(?:readonly\s+)?([\w$]+)\s*\??\s*(?:<[^(]*>)?\s*([(:])`)
+	tsParamModifiers       = regexp.MustCompile(`^(?:(?:public|private|protected|readonly|override)\s+)*`)
+	tsExportListPattern    = regexp.MustCompile(`(?:^|[^\w$.])export\s+(?:type\s+)?\{([^}]*)\}`)
+	tsExportDefaultPattern = regexp.MustCompile(`(?m)(?:^|[^\w$.])export\s+default\s+([\w$]+)\s*;?\s*$`)
+	tsModuleExportsPattern = regexp.MustCompile(`(?:^|[^\w$.])module\.exports\s*=\s*`)
+	tsExportsPropPattern   = regexp.MustCompile(`(?:^|[^\w$.])(?:module\.)?exports\.([\w$]+)\s*=\s*`)
+	tsBranchPattern        = regexp.MustCompile(`\b(?:if|for|while|switch|catch)\b`)
+)
+
+// Mots après lesquels un "/" ouvre une expression régulière
+var tsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// Modules intégrés de Node.js
+var nodeBuiltins = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+	"console": true, "constants": true, "crypto": true, "dgram": true, "dns": true, "domain": true,
+	"events": true, "fs": true, "http": true, "http2": true, "https": true, "inspector": true,
+	"module": true, "net": true, "os": true, "path": true, "perf_hooks": true, "process": true,
+	"punycode": true, "querystring": true, "readline": true, "repl": true, "stream": true,
+	"string_decoder": true, "timers": true, "tls": true, "trace_events": true, "tty": true,
+	"url": true, "util": true, "v8": true, "vm": true, "wasi": true, "worker_threads": true, "zlib": true,
+}
+
+var typeScriptRules = lexicalRules{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	scanString:    tsScanString,
+	cleanComment:  cleanJSDoc,
+}
+
+// Modes de lecture d'une fonction après sa liste de paramètres
+const (
+	tsDeclaration = iota // corps entre accolades, absent pour une surcharge
+	tsArrow              // "=>" puis un bloc ou une expression
+	tsSignature          // membre d'interface, sans corps
+)
+
+type tsParser struct {
+	s          *sourceText
+	code       string
+	depths     []int
+	decorators map[int]tsDecoration
+	result     *interfaces.ASTAnalysisResult
+	exported   map[string]bool
+	declFile   bool
+}
+
+type tsDecoration struct {
+	start int
+	names []string
+}
+
+// tsCallable décrit une fonction à partir de sa liste de paramètres
+type tsCallable struct {
+	params     []interfaces.ParameterInfo
+	properties []interfaces.FieldInfo // paramètres de constructeur déclarant un champ
+	returns    []string
+	bodyStart  int // -1 sans corps
+	end        int // dernière position de la déclaration
+}
+
+func (f *typeScriptFrontend) Parse(filePath string, src []byte) (*interfaces.ASTAnalysisResult, error) {
+	s := newSourceText(string(src), typeScriptRules)
+	p := &tsParser{
+		s:          s,
+		code:       s.code,
+		depths:     braceDepths(s.code),
+		decorators: tsDecorators(s.code),
+		exported:   make(map[string]bool),
+		declFile:   strings.HasSuffix(filePath, ".d.ts"),
+		result: &interfaces.ASTAnalysisResult{
+			FilePath:  filePath,
+			Package:   directoryPackage(filePath),
+			Imports:   make([]interfaces.ImportInfo, 0),
+			Functions: make([]interfaces.FunctionInfo, 0),
+			Types:     make([]interfaces.TypeInfo, 0),
+			Variables: make([]interfaces.VariableInfo, 0),
+			Constants: make([]interfaces.ConstantInfo, 0),
+			Context:   make(map[string]interface{}),
+		},
+	}
+
+	p.collectExports()
+	p.parseImports()
+	p.parseFunctions()
+	p.parseClasses()
+	p.parseInterfaces()
+	p.parseTypeAliases()
+	p.parseEnums()
+	p.parseVariables()
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".js", ".jsx", ".mjs", ".cjs":
+		p.result.Context["language"] = "javascript"
+	default:
+		p.result.Context["language"] = "typescript"
+	}
+	finishResult(p.result, s, tsBranchPattern)
+	return p.result, nil
+}
+
+func (p *tsParser) parseImports() {
+	add := func(quote int, alias string) {
+		path, ok := p.s.stringAt(quote)
+		if !ok || path == "" {
+			return
+		}
+		p.result.Imports = append(p.result.Imports, interfaces.ImportInfo{
+			Path:       path,
+			Alias:      alias,
+			IsStandard: isNodeBuiltin(path),
+			LineNumber: p.s.lineOf(quote),
+		})
+	}
+
+	for _, m := range tsImportPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		add(m[1]-1, tsImportAlias(p.code[m[2]:m[3]]))
+	}
+	for _, m := range tsExportFromPattern.FindAllStringIndex(p.code, -1) {
+		add(m[1]-1, "")
+	}
+	for _, m := range tsRequirePattern.FindAllStringIndex(p.code, -1) {
+		lineStart := p.s.lineStarts[p.s.lineOf(m[0])-1]
+		alias := ""
+		if found := tsRequireAliasPattern.FindStringSubmatch(p.code[lineStart : m[0]+1]); found != nil {
+			alias = found[1]
+		}
+		add(m[1]-1, alias)
+	}
+}
+
+// tsImportAlias retourne le nom local d'un import : "* as x" ou l'import par défaut
+func tsImportAlias(clause string) string {
+	if i := strings.Index(clause, "* as "); i >= 0 {
+		if fields := strings.Fields(clause[i+len("* as "):]); len(fields) > 0 {
+			return strings.TrimRight(fields[0], ",")
+		}
+	}
+	name := strings.TrimSpace(strings.SplitN(strings.SplitN(clause, ",", 2)[0], "{", 2)[0])
+	if name == "type" {
+		return ""
+	}
+	return name
+}
+
+func isNodeBuiltin(path string) bool {
+	if strings.HasPrefix(path, "node:") {
+		return true
+	}
+	return nodeBuiltins[strings.SplitN(path, "/", 2)[0]]
+}
+
+// collectExports relève les noms exportés hors des déclarations : listes
+// "export { a, b as c }", "export default a" et exports CommonJS
+func (p *tsParser) collectExports() {
+	for _, m := range tsExportListPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		if strings.HasPrefix(p.code[skipSpace(p.code, m[1]):], "from") {
+			continue
+		}
+		for _, item := range splitTopLevel(p.code[m[2]:m[3]], ',', false) {
+			if fields := strings.Fields(item); len(fields) > 0 {
+				p.exported[fields[0]] = true
+			}
+		}
+	}
+	for _, m := range tsExportDefaultPattern.FindAllStringSubmatch(p.code, -1) {
+		p.exported[m[1]] = true
+	}
+
+	for _, m := range tsModuleExportsPattern.FindAllStringIndex(p.code, -1) {
+		if p.depths[m[0]] != 0 {
+			continue
+		}
+		value := m[1]
+		if value < len(p.code) && p.code[value] == '{' {
+			close := matchingBracket(p.code, value)
+			for _, item := range splitTopLevel(p.code[value+1:close], ',', false) {
+				name := item
+				if i := strings.IndexByte(item, ':'); i >= 0 {
+					name = item[i+1:]
+				}
+				if name = strings.TrimSpace(name); isIdentifier(name) {
+					p.exported[name] = true
+				}
+			}
+			continue
+		}
+		end := value
+		for end < len(p.code) && isWordByte(p.code[end]) {
+			end++
+		}
+		if end > value {
+			p.exported[p.code[value:end]] = true
+		}
+	}
+
+	// exports.name = function... déclare aussi une fonction
+	for _, m := range tsExportsPropPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		if p.depths[m[0]] != 0 {
+			continue
+		}
+		name := p.code[m[2]:m[3]]
+		p.exported[name] = true
+		start := m[0]
+		if !isWordByte(p.code[start]) {
+			start++
+		}
+		if callable, ok := p.arrowOrFunction(m[1]); ok {
+			p.addFunction(p.function(name, start, callable, true), nil)
+		}
+	}
+}
+
+func isIdentifier(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isWordByte(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *tsParser) isExported(name, modifiers string) bool {
+	return strings.Contains(modifiers, "export") || p.exported[name]
+}
+
+func (p *tsParser) parseFunctions() {
+	for _, m := range tsFunctionPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		if before := lastNonSpace(p.code, 0, start); start > 0 && strings.IndexByte("=(,:?", p.code[before]) >= 0 {
+			continue // expression function, lue avec l'affectation
+		}
+		modifiers := p.code[m[2]:m[3]]
+		callable := p.callable(m[1]-1, tsDeclaration)
+		if callable.bodyStart < 0 && !p.declFile && !strings.Contains(modifiers, "declare") {
+			continue // surcharge : seule l'implémentation est retenue
+		}
+		name := p.code[m[4]:m[5]]
+		p.addFunction(p.function(name, start, callable, p.isExported(name, modifiers)), p.decorators[start].names)
+	}
+}
+
+func (p *tsParser) addFunction(function interfaces.FunctionInfo, decorators []string) {
+	if len(decorators) > 0 {
+		if function.Annotations == nil {
+			function.Annotations = make(map[string]string)
+		}
+		function.Annotations["decorators"] = strings.Join(decorators, ",")
+	}
+	p.result.Functions = append(p.result.Functions, function)
+}
+
+// function construit la description d'une fonction déclarée à start
+func (p *tsParser) function(name string, start int, callable tsCallable, exported bool) interfaces.FunctionInfo {
+	header := callable.end + 1
+	if callable.bodyStart >= 0 {
+		header = callable.bodyStart
+	}
+	function := interfaces.FunctionInfo{
+		Name:          name,
+		Package:       p.result.Package,
+		Signature:     strings.TrimSuffix(strings.TrimSpace(compactSpace(p.s.src[start:header])), ";"),
+		Parameters:    callable.params,
+		ReturnTypes:   callable.returns,
+		LineStart:     p.s.lineOf(start),
+		LineEnd:       p.s.lineOf(callable.end),
+		Complexity:    1,
+		IsExported:    exported,
+		Documentation: p.documentation(start),
+	}
+	if callable.bodyStart >= 0 {
+		function.Complexity += countKeywords(p.code[callable.bodyStart:callable.end+1], tsBranchPattern)
+	}
+	return function
+}
+
+// documentation retourne le commentaire qui précède une déclaration et ses décorateurs
+func (p *tsParser) documentation(start int) string {
+	if decoration, ok := p.decorators[start]; ok {
+		start = decoration.start
+	}
+	return p.s.commentBefore(p.s.lineOf(start), nil)
+}
+
+// callable lit les paramètres ouverts à open, le type de retour et le corps
+func (p *tsParser) callable(open int, mode int) tsCallable {
+	code := p.code
+	close := matchingBracket(code, open)
+	c := tsCallable{bodyStart: -1, end: close}
+	c.params, c.properties = p.parameters(open, close)
+	c.returns = make([]string, 0)
+
+	i := skipSpace(code, close+1)
+	if i < len(code) && code[i] == ':' {
+		end := readTSType(code, i+1, func(j int) bool {
+			if mode == tsArrow && strings.HasPrefix(code[j:], "=>") {
+				return true
+			}
+			switch code[j] {
+			case '{', ';', ',', '\n':
+				return true
+			}
+			return false
+		})
+		if returnType := compactSpace(p.s.src[i+1 : end]); returnType != "" {
+			c.returns = append(c.returns, returnType)
+		}
+		c.end = end - 1
+		i = skipSpace(code, end)
+	}
+
+	switch mode {
+	case tsArrow:
+		if !strings.HasPrefix(code[i:], "=>") {
+			c.end = -1
+			return c
+		}
+		i = skipSpace(code, i+2)
+		c.bodyStart = i
+		if i < len(code) && code[i] == '{' {
+			c.end = matchingBracket(code, i)
+		} else {
+			c.end = tsExpressionEnd(code, i)
+		}
+	case tsDeclaration:
+		if i < len(code) && code[i] == '{' {
+			c.bodyStart = i
+			c.end = matchingBracket(code, i)
+		}
+	}
+	return c
+}
+
+// arrowOrFunction reconnaît une fonction fléchée ou une expression
+// "function" à la position de la valeur d'une affectation
+func (p *tsParser) arrowOrFunction(value int) (tsCallable, bool) {
+	code := p.code
+	i := skipSpace(code, value)
+	if hasKeyword(code, i, "async") {
+		i = skipSpace(code, i+len("async"))
+	}
+	if hasKeyword(code, i, "function") {
+		open := strings.IndexByte(code[i:], '(')
+		if open < 0 {
+			return tsCallable{}, false
+		}
+		callable := p.callable(i+open, tsDeclaration)
+		return callable, callable.bodyStart >= 0
+	}
+	if i < len(code) && code[i] == '<' {
+		i = skipSpace(code, matchingAngle(code, i)+1)
+	}
+	if i < len(code) && code[i] == '(' {
+		callable := p.callable(i, tsArrow)
+		return callable, callable.end >= 0
+	}
+
+	// Paramètre unique sans parenthèses : x => ...
+	end := i
+	for end < len(code) && isWordByte(code[end]) {
+		end++
+	}
+	arrow := skipSpace(code, end)
+	if end == i || !strings.HasPrefix(code[arrow:], "=>") {
+		return tsCallable{}, false
+	}
+	c := tsCallable{
+		params:    []interfaces.ParameterInfo{{Name: code[i:end]}},
+		returns:   make([]string, 0),
+		bodyStart: skipSpace(code, arrow+2),
+	}
+	if c.bodyStart < len(code) && code[c.bodyStart] == '{' {
+		c.end = matchingBracket(code, c.bodyStart)
+	} else {
+		c.end = tsExpressionEnd(code, c.bodyStart)
+	}
+	return c, true
+}
+
+func hasKeyword(code string, i int, keyword string) bool {
+	end := i + len(keyword)
+	return strings.HasPrefix(code[i:], keyword) && (end == len(code) || !isWordByte(code[end]))
+}
+
+// parameters lit la liste de paramètres entre open et close ; les types
+// sont relus dans le texte d'origine pour garder les types littéraux
+func (p *tsParser) parameters(open, close int) ([]interfaces.ParameterInfo, []interfaces.FieldInfo) {
+	params := make([]interfaces.ParameterInfo, 0)
+	var properties []interfaces.FieldInfo
+	if close <= open {
+		return params, properties
+	}
+
+	list := p.code[open+1 : close]
+	for _, span := range splitSpans(list, ',', true) {
+		code := list[span[0]:span[1]]
+		text := p.s.src[open+1+span[0] : open+1+span[1]]
+
+		_, i := skipDecorators(code, 0)
+		modifiers := tsParamModifiers.FindString(code[i:])
+		i += len(modifiers)
+
+		var param interfaces.ParameterInfo
+		if strings.HasPrefix(code[i:], "...") {
+			param.IsVariadic = true
+			i = skipSpace(code, i+3)
+		}
+		j := i
+		if j < len(code) && (code[j] == '{' || code[j] == '[') {
+			j = matchingBracket(code, j) + 1 // déstructuration, sans nom
+		} else {
+			for j < len(code) && isWordByte(code[j]) {
+				j++
+			}
+			param.Name = code[i:j]
+		}
+		if param.Name == "this" {
+			continue
+		}
+
+		j = skipSpace(code, j)
+		if j < len(code) && code[j] == '?' {
+			j = skipSpace(code, j+1)
+		}
+		if j < len(code) && code[j] == ':' {
+			end := readTSType(code, j+1, func(k int) bool {
+				return code[k] == '=' && (k+1 == len(code) || code[k+1] != '>')
+			})
+			param.Type = compactSpace(text[j+1 : end])
+		}
+		params = append(params, param)
+
+		if modifiers != "" && param.Name != "" {
+			properties = append(properties, interfaces.FieldInfo{
+				Name:       param.Name,
+				Type:       param.Type,
+				IsExported: !strings.Contains(modifiers, "private") && !strings.Contains(modifiers, "protected"),
+			})
+		}
+	}
+	return params, properties
+}
+
+// readTSType retourne la fin d'une annotation de type qui commence à
+// start : la première position de profondeur nulle où stop est vrai, ou
+// un délimiteur fermant non apparié
+func readTSType(code string, start int, stop func(int) bool) int {
+	i := skipSpace(code, start)
+	if i < len(code) && code[i] == '{' {
+		i = matchingBracket(code, i) + 1 // type objet littéral
+	}
+	depth := 0
+	for ; i < len(code); i++ {
+		if depth == 0 && stop(i) {
+			return i
+		}
+		switch c := code[i]; {
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == '>' && code[i-1] == '=':
+			// flèche d'un type fonction
+		case c == ')' || c == ']' || c == '}' || c == '>':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return len(code)
+}
+
+// tsExpressionEnd retourne la dernière position d'une expression : elle
+// s'arrête au ";" ou à la fin de ligne de profondeur nulle, sauf si la
+// ligne suivante continue l'expression
+func tsExpressionEnd(code string, start int) int {
+	depth := 0
+	i := start
+	for ; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return lastNonSpace(code, start, i)
+			}
+			depth--
+		case ';', ',':
+			if depth == 0 {
+				return lastNonSpace(code, start, i)
+			}
+		case '\n':
+			next := skipSpace(code, i)
+			if depth == 0 && (next == len(code) || !strings.ContainsRune(".?:+-*/%&|=<>", rune(code[next]))) {
+				return lastNonSpace(code, start, i)
+			}
+		}
+	}
+	return lastNonSpace(code, start, i)
+}
+
+func lastNonSpace(code string, start, end int) int {
+	for end > start && isSpace(code[end-1]) {
+		end--
+	}
+	return max(start, end-1)
+}
+
+// matchingAngle retourne la position du chevron fermant celui ouvert à open
+func matchingAngle(code string, open int) int {
+	depth := 0
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case '<':
+			depth++
+		case '>':
+			if code[i-1] == '=' {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(code) - 1
+}
+
+// skipDecorators saute les décorateurs "@name(...)" à partir de i
+func skipDecorators(code string, i int) ([]string, int) {
+	var names []string
+	for {
+		i = skipSpace(code, i)
+		if i >= len(code) || code[i] != '@' {
+			return names, i
+		}
+		j := i + 1
+		for j < len(code) && (isWordByte(code[j]) || code[j] == '.') {
+			j++
+		}
+		names = append(names, code[i+1:j])
+		if k := skipSpace(code, j); k < len(code) && code[k] == '(' {
+			j = matchingBracket(code, k) + 1
+		}
+		i = j
+	}
+}
+
+// tsDecorators associe chaque déclaration décorée à ses décorateurs
+func tsDecorators(code string) map[int]tsDecoration {
+	decorators := make(map[int]tsDecoration)
+	for i := 0; i < len(code); i++ {
+		if code[i] != '@' || i > 0 && isWordByte(code[i-1]) {
+			continue
+		}
+		names, target := skipDecorators(code, i)
+		decorators[target] = tsDecoration{start: i, names: names}
+		i = target - 1
+	}
+	return decorators
+}
+
+func (p *tsParser) parseClasses() {
+	code := p.code
+	for _, m := range tsClassPattern.FindAllStringSubmatchIndex(code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		open := strings.IndexByte(code[m[1]:], '{')
+		if open < 0 {
+			continue
+		}
+		open += m[1]
+		close := matchingBracket(code, open)
+
+		name := code[m[4]:m[5]]
+		modifiers := code[m[2]:m[3]]
+		typeInfo := interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "class",
+			Package:       p.result.Package,
+			BaseTypes:     tsHeritage(code[m[1]:open]),
+			IsExported:    p.isExported(name, modifiers),
+			Documentation: p.documentation(start),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+		}
+		p.parseClassMembers(&typeInfo, open, close)
+		p.result.Types = append(p.result.Types, typeInfo)
+	}
+}
+
+// tsHeritage lit les clauses extends et implements d'une classe ou d'une interface
+func tsHeritage(clause string) []string {
+	clause = strings.TrimSpace(clause)
+	if strings.HasPrefix(clause, "<") {
+		clause = clause[matchingAngle(clause, 0)+1:]
+	}
+
+	var bases []string
+	for _, keyword := range []string{"extends", "implements"} {
+		i := strings.Index(clause, keyword)
+		if i < 0 {
+			continue
+		}
+		list := clause[i+len(keyword):]
+		if keyword == "extends" {
+			if j := strings.Index(list, "implements"); j >= 0 {
+				list = list[:j]
+			}
+		}
+		for _, base := range splitTopLevel(list, ',', true) {
+			if j := strings.IndexAny(base, "<("); j >= 0 {
+				base = base[:j]
+			}
+			if base = strings.TrimSpace(base); base != "" {
+				bases = append(bases, base)
+			}
+		}
+	}
+	return bases
+}
+
+// memberStarts retourne les positions des membres d'un corps de classe ou
+// d'interface : premier code de chaque ligne au niveau du corps, après les
+// décorateurs de la même ligne
+func (p *tsParser) memberStarts(open, close int) []int {
+	var starts []int
+	depth := p.depths[open] + 1
+	for line := p.s.lineOf(open) + 1; line <= p.s.lineOf(close); line++ {
+		lineStart := p.s.lineStarts[line-1]
+		pos := skipBlank(p.code, lineStart)
+		if pos >= close || p.depths[pos] != depth || p.code[pos] == '\n' || p.code[pos] == '\r' {
+			continue
+		}
+		if _, target := skipDecorators(p.code, pos); p.s.lineOf(target) != line {
+			continue // décorateurs seuls sur leur ligne : le membre suit
+		} else {
+			pos = target
+		}
+		if pos < close && p.code[pos] != '}' {
+			starts = append(starts, pos)
+		}
+	}
+	return starts
+}
+
+func (p *tsParser) parseClassMembers(typeInfo *interfaces.TypeInfo, open, close int) {
+	code := p.code
+	for _, pos := range p.memberStarts(open, close) {
+		lineEnd := strings.IndexByte(code[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(code)
+		} else {
+			lineEnd += pos
+		}
+		lineEnd = min(lineEnd, close)
+
+		if m := tsMethodPattern.FindStringSubmatchIndex(code[pos:close]); m != nil {
+			modifiers := code[pos+m[2] : pos+m[3]]
+			name := code[pos+m[4] : pos+m[5]]
+			callable := p.callable(pos+m[1]-1, tsDeclaration)
+			if callable.bodyStart < 0 && !strings.Contains(modifiers, "abstract") && !p.declFile {
+				continue // surcharge
+			}
+			p.addMethod(typeInfo, name, modifiers, pos, callable)
+			for _, property := range callable.properties {
+				typeInfo.Fields = append(typeInfo.Fields, property)
+			}
+			continue
+		}
+
+		m := tsFieldPattern.FindStringSubmatchIndex(code[pos:lineEnd])
+		if m == nil {
+			continue
+		}
+		modifiers := code[pos+m[2] : pos+m[3]]
+		name := code[pos+m[4] : pos+m[5]]
+		field := interfaces.FieldInfo{Name: name, IsExported: tsMemberVisible(name, modifiers)}
+
+		next := pos + m[6]
+		if m[7] > m[6] && code[next] == ':' {
+			end := readTSType(code, next+1, func(k int) bool {
+				return code[k] == ';' || code[k] == '\n' || code[k] == '=' && (k+1 == len(code) || code[k+1] != '>')
+			})
+			field.Type = compactSpace(p.s.src[next+1 : end])
+			next = end
+		}
+		if next < len(code) && code[next] == '=' {
+			if callable, ok := p.arrowOrFunction(next + 1); ok {
+				p.addMethod(typeInfo, name, modifiers, pos, callable)
+				continue
+			}
+		}
+		typeInfo.Fields = append(typeInfo.Fields, field)
+	}
+}
+
+func tsMemberVisible(name, modifiers string) bool {
+	return !strings.HasPrefix(name, "#") && !strings.Contains(modifiers, "private") && !strings.Contains(modifiers, "protected")
+}
+
+// addMethod ajoute une méthode au type et aux fonctions du fichier, avec
+// la classe en annotation "receiver"
+func (p *tsParser) addMethod(typeInfo *interfaces.TypeInfo, name, modifiers string, start int, callable tsCallable) {
+	method := p.function(name, start, callable, typeInfo.IsExported && tsMemberVisible(name, modifiers))
+	method.Annotations = map[string]string{"receiver": typeInfo.Name}
+	if strings.Contains(modifiers, "static") {
+		method.Annotations["static"] = "true"
+	}
+	if decoration, ok := p.decorators[start]; ok {
+		method.Annotations["decorators"] = strings.Join(decoration.names, ",")
+	}
+	typeInfo.Methods = append(typeInfo.Methods, method)
+	p.result.Functions = append(p.result.Functions, method)
+}
+
+func (p *tsParser) parseInterfaces() {
+	code := p.code
+	for _, m := range tsInterfacePattern.FindAllStringSubmatchIndex(code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		open := strings.IndexByte(code[m[1]:], '{')
+		if open < 0 {
+			continue
+		}
+		open += m[1]
+		close := matchingBracket(code, open)
+
+		name := code[m[4]:m[5]]
+		typeInfo := interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "interface",
+			Package:       p.result.Package,
+			BaseTypes:     tsHeritage(code[m[1]:open]),
+			IsExported:    p.isExported(name, code[m[2]:m[3]]),
+			Documentation: p.documentation(start),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+		}
+
+		for _, pos := range p.memberStarts(open, close) {
+			member := tsSignaturePattern.FindStringSubmatchIndex(code[pos:close])
+			if member == nil {
+				continue
+			}
+			memberName := code[pos+member[2] : pos+member[3]]
+			if code[pos+member[4]] == '(' {
+				callable := p.callable(pos+member[4], tsSignature)
+				method := p.function(memberName, pos, callable, true)
+				typeInfo.Methods = append(typeInfo.Methods, method)
+				continue
+			}
+			colon := pos + member[4]
+			end := readTSType(code, colon+1, func(k int) bool {
+				return code[k] == ';' || code[k] == ',' || code[k] == '\n'
+			})
+			typeInfo.Fields = append(typeInfo.Fields, interfaces.FieldInfo{
+				Name:       memberName,
+				Type:       compactSpace(p.s.src[colon+1 : end]),
+				IsExported: true,
+			})
+		}
+		p.result.Types = append(p.result.Types, typeInfo)
+	}
+}
+
+func (p *tsParser) parseTypeAliases() {
+	for _, m := range tsTypeAliasPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		name := p.code[m[4]:m[5]]
+		p.result.Types = append(p.result.Types, interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "type_alias",
+			Package:       p.result.Package,
+			IsExported:    p.isExported(name, p.code[m[2]:m[3]]),
+			Documentation: p.documentation(start),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(tsExpressionEnd(p.code, skipSpace(p.code, m[1]))),
+		})
+	}
+}
+
+func (p *tsParser) parseEnums() {
+	for _, m := range tsEnumPattern.FindAllStringSubmatchIndex(p.code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		open := m[1] - 1
+		close := matchingBracket(p.code, open)
+		name := p.code[m[4]:m[5]]
+		typeInfo := interfaces.TypeInfo{
+			Name:          name,
+			Kind:          "enum",
+			Package:       p.result.Package,
+			IsExported:    p.isExported(name, p.code[m[2]:m[3]]),
+			Documentation: p.documentation(start),
+			LineStart:     p.s.lineOf(start),
+			LineEnd:       p.s.lineOf(close),
+		}
+		for _, span := range splitSpans(p.code[open+1:close], ',', false) {
+			member := p.code[open+1+span[0] : open+1+span[1]]
+			memberName := strings.TrimSpace(strings.SplitN(member, "=", 2)[0])
+			if quoted, ok := p.s.stringAt(open + 1 + span[0]); ok {
+				memberName = quoted
+			}
+			if memberName != "" {
+				typeInfo.Fields = append(typeInfo.Fields, interfaces.FieldInfo{Name: memberName, Type: name, IsExported: typeInfo.IsExported})
+			}
+		}
+		p.result.Types = append(p.result.Types, typeInfo)
+	}
+}
+
+// parseVariables lit les const, let et var de premier niveau ; celles qui
+// reçoivent une fonction fléchée ou une expression function sont des fonctions
+func (p *tsParser) parseVariables() {
+	code := p.code
+	for _, m := range tsVariablePattern.FindAllStringSubmatchIndex(code, -1) {
+		start := m[2]
+		if p.depths[start] != 0 {
+			continue
+		}
+		if before := lastNonSpace(code, 0, start); start > 0 && code[before] == '(' {
+			continue // for (const x of ...)
+		}
+
+		modifiers := code[m[2]:m[3]]
+		kind := code[m[4]:m[5]]
+		name := code[m[6]:m[7]]
+		exported := p.isExported(name, modifiers)
+
+		i := skipSpace(code, m[1])
+		typeName := ""
+		if i < len(code) && code[i] == ':' {
+			end := readTSType(code, i+1, func(k int) bool {
+				return code[k] == ';' || code[k] == ',' || code[k] == '=' && (k+1 == len(code) || code[k+1] != '>')
+			})
+			typeName = compactSpace(p.s.src[i+1 : end])
+			i = skipSpace(code, end)
+		}
+
+		value := ""
+		if i < len(code) && code[i] == '=' {
+			valueStart := skipSpace(code, i+1)
+			if callable, ok := p.arrowOrFunction(valueStart); ok {
+				p.addFunction(p.function(name, start, callable, exported), p.decorators[start].names)
+				continue
+			}
+			value = summarizeValue(p.s.src[valueStart : tsExpressionEnd(code, valueStart)+1])
+		}
+
+		line := p.s.lineOf(start)
+		documentation := p.documentation(start)
+		if kind == "const" {
+			p.result.Constants = append(p.result.Constants, interfaces.ConstantInfo{
+				Name:          name,
+				Type:          typeName,
+				Value:         value,
+				Package:       p.result.Package,
+				IsExported:    exported,
+				LineNumber:    line,
+				Documentation: documentation,
+			})
+			continue
+		}
+		p.result.Variables = append(p.result.Variables, interfaces.VariableInfo{
+			Name:          name,
+			Type:          typeName,
+			Package:       p.result.Package,
+			IsExported:    exported,
+			Value:         value,
+			LineNumber:    line,
+			Documentation: documentation,
+		})
+	}
+}
+
+// tsScanString reconnaît les chaînes, les gabarits `...${}` et les
+// expressions régulières littérales
+func tsScanString(src string, i int, previous string) int {
+	switch src[i] {
+	case '\'', '"':
+		return scanQuoted(src, i, '\\', true)
+	case '`':
+		return tsScanTemplate(src, i)
+	case '/':
+		if i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*') {
+			return -1
+		}
+		if tsRegexAllowed(previous) {
+			return tsScanRegex(src, i)
+		}
+	}
+	return -1
+}
+
+func tsScanTemplate(src string, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '`':
+			return j + 1
+		case '$':
+			if j+1 < len(src) && src[j+1] == '{' {
+				j = tsSkipInterpolation(src, j+2) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// tsSkipInterpolation retourne la position qui suit l'accolade fermant
+// une interpolation ${...}
+func tsSkipInterpolation(src string, j int) int {
+	depth := 1
+	for j < len(src) {
+		switch c := src[j]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		case '\'', '"', '`':
+			if end := tsScanString(src, j, ""); end > j {
+				j = end
+				continue
+			}
+		}
+		j++
+	}
+	return len(src)
+}
+
+// tsRegexAllowed indique si un "/" ouvre une expression régulière après
+// previous : en début d'expression, pas après un opérande
+func tsRegexAllowed(previous string) bool {
+	if previous == "" {
+		return true
+	}
+	if isWordByte(previous[len(previous)-1]) {
+		return tsRegexKeywords[previous]
+	}
+	return previous != ")" && previous != "]"
+}
+
+func tsScanRegex(src string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '\n':
+			return -1
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(src) && isWordByte(src[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// cleanJSDoc retire les "*" de début de ligne des commentaires JSDoc
+func cleanJSDoc(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "*"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -22,6 +22,7 @@ type indexManagerImpl struct {
 
 	qdrantClient   interface{}
 	embeddingCache *sql.DB
+	textCipher     interfaces.TextCipher
 	initialized    bool
 
 	// Configuration
@@ -47,6 +48,13 @@ func NewIndexManager(
 	}, nil
 }
 
+// SetTextCipher chiffre le texte des actions indexées ensuite : il est
+// chiffré dans le payload Qdrant et n'est pas conservé dans le cache
+// d'embeddings
+func (im *indexManagerImpl) SetTextCipher(cipher interfaces.TextCipher) {
+	im.textCipher = cipher
+}
+
 // Initialize implÃ©mente BaseManager.Initialize
 func (im *indexManagerImpl) Initialize(ctx context.Context) error {
 	if im.initialized {
@@ -88,6 +96,14 @@ func (im *indexManagerImpl) IndexAction(ctx context.Context, action interfaces.A
 		return fmt.Errorf("failed to get embedding: %w", err)
 	}
 
+	text := action.Text
+	if im.textCipher != nil {
+		text, err = im.textCipher.SealText(action.Text, action.ID)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt action text: %w", err)
+		}
+	}
+
 	// CrÃ©er le point Qdrant
 	point := map[string]interface{}{
 		"id":     action.ID,
@@ -95,7 +111,8 @@ func (im *indexManagerImpl) IndexAction(ctx context.Context, action interfaces.A
 		"payload": map[string]interface{}{
 			"action_id":      action.ID,
 			"action_type":    action.Type,
-			"text":           action.Text,
+			"text":           text,
+			"text_encrypted": im.textCipher != nil,
 			"workspace_path": action.WorkspacePath,
 			"file_path":      action.FilePath,
 			"line_number":    action.LineNumber,
@@ -158,7 +175,14 @@ func (im *indexManagerImpl) CacheEmbedding(ctx context.Context, text string, vec
 	textHash := fmt.Sprintf("%x", sha256Sum(text))
 	now := time.Now()
 
-	_, err = im.embeddingCache.ExecContext(ctx, query, textHash, text, vectorBytes, now, now)
+	// Le cache ne relit que le vecteur : le texte n'y est pas conservé quand
+	// il est chiffré dans l'index
+	cachedText := text
+	if im.textCipher != nil {
+		cachedText = ""
+	}
+
+	_, err = im.embeddingCache.ExecContext(ctx, query, textHash, cachedText, vectorBytes, now, now)
 	if err != nil {
 		return fmt.Errorf("failed to cache embedding: %w", err)
 	}
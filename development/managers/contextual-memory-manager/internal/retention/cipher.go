@@ -0,0 +1,103 @@
+package retention
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Cipher chiffre les enregistrements stockés avec AES-256-GCM. La clé de
+// stockage est liée au chiffré comme donnée authentifiée : un enregistrement
+// déplacé sous une autre clé ne se déchiffre plus.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher crée un Cipher à partir d'une clé de 32 octets encodée en
+// base64 ou en hexadécimal
+func NewCipher(encodedKey string) (*Cipher, error) {
+	key, err := decodeKey(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	decoders := []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+	}
+	for _, decode := range decoders {
+		if key, err := decode(encoded); err == nil && len(key) == 32 {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("encryption key must be 32 bytes encoded in base64 or hex")
+}
+
+// Seal chiffre plaintext ; le nonce précède le chiffré
+func (c *Cipher) Seal(plaintext, associated []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, associated), nil
+}
+
+// Open déchiffre un enregistrement produit par Seal
+func (c *Cipher) Open(sealed, associated []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, fmt.Errorf("encrypted record is too short")
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:size], sealed[size:], associated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt record: %w", err)
+	}
+	return plaintext, nil
+}
+
+// SealText chiffre un texte stocké hors de la rétention, dans les index de
+// recherche, et l'encode en base64 derrière le préfixe des enregistrements
+// chiffrés
+func (c *Cipher) SealText(text, associated string) (string, error) {
+	sealed, err := c.Seal([]byte(text), []byte(associated))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenText déchiffre un texte produit par SealText. Un texte sans le préfixe
+// a été stocké avant l'activation du chiffrement et est renvoyé tel quel.
+func (c *Cipher) OpenText(text, associated string) (string, error) {
+	encoded, ok := strings.CutPrefix(text, encryptedPrefix)
+	if !ok {
+		return text, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted text: %w", err)
+	}
+	plaintext, err := c.Open(sealed, []byte(associated))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
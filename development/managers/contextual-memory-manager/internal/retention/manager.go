@@ -0,0 +1,341 @@
+// Package retention gère la durée de vie du contexte capturé : politiques
+// de rétention par workspace et type d'action, résumé extractif des
+// sessions expirées, masquage des données personnelles et des secrets
+// avant stockage, export et effacement par utilisateur, et chiffrement au
+// repos des actions et résumés conservés. Le même Cipher chiffre le texte
+// des actions dans les index de recherche.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+const defaultSummarySentences = 5
+
+// Options configure la rétention et la confidentialité du contexte stocké
+type Options struct {
+	EncryptionKey    string          // clé AES-256 en base64 ou hex, vide pour un stockage en clair
+	DisableRedaction bool            // stocke les actions sans masquage
+	RedactionRules   []RedactionRule // règles ajoutées aux règles par défaut
+	SummarySentences int             // phrases par résumé de session, 5 par défaut
+}
+
+// RemoveFunc supprime une action des index de recherche ; elle est appelée
+// avant que l'action ne quitte le stockage de la rétention
+type RemoveFunc func(ctx context.Context, actionID string) error
+
+// Manager conserve les actions capturées et applique les politiques de
+// rétention
+type Manager struct {
+	store     *store
+	redactor  *Redactor
+	sentences int
+
+	policies []interfaces.RetentionPolicy
+	mu       sync.RWMutex
+	runMu    sync.Mutex // sérialise Apply, Update et Forget
+}
+
+// NewManager crée un Manager sur le stockage clé-valeur donné
+func NewManager(kv KeyValueStore, options Options) (*Manager, error) {
+	if kv == nil {
+		return nil, fmt.Errorf("a key-value store is required")
+	}
+	m := &Manager{
+		store:     &store{kv: kv},
+		redactor:  &Redactor{},
+		sentences: options.SummarySentences,
+	}
+	if !options.DisableRedaction {
+		m.redactor = NewRedactor(options.RedactionRules...)
+	}
+	if m.sentences <= 0 {
+		m.sentences = defaultSummarySentences
+	}
+	if options.EncryptionKey != "" {
+		cipher, err := NewCipher(options.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		m.store.cipher = cipher
+	}
+	return m, nil
+}
+
+// Redactor renvoie le Redactor appliqué avant stockage ; il ne masque rien
+// si le masquage est désactivé
+func (m *Manager) Redactor() *Redactor {
+	return m.redactor
+}
+
+// Cipher renvoie le Cipher du stockage, nil si aucune clé de chiffrement
+// n'est configurée
+func (m *Manager) Cipher() *Cipher {
+	return m.store.cipher
+}
+
+// Record conserve une action déjà masquée pour la rétention et l'export
+func (m *Manager) Record(ctx context.Context, action interfaces.Action) error {
+	if action.ID == "" {
+		return fmt.Errorf("action ID is required")
+	}
+	return m.store.putAction(ctx, &record{Action: action, StoredAt: time.Now()})
+}
+
+// Update applique une mise à jour déjà masquée à une action conservée : le
+// texte est remplacé s'il n'est pas vide et les métadonnées sont fusionnées.
+// Une action absente du stockage de la rétention est ignorée.
+func (m *Manager) Update(ctx context.Context, actionID string, update interfaces.ContextUpdate) error {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	r, err := m.store.action(ctx, actionID)
+	if err != nil || r == nil {
+		return err
+	}
+	if update.Text != "" {
+		r.Action.Text = update.Text
+	}
+	if len(update.Metadata) > 0 {
+		metadata := make(map[string]interface{}, len(r.Action.Metadata)+len(update.Metadata))
+		for key, value := range r.Action.Metadata {
+			metadata[key] = value
+		}
+		for key, value := range update.Metadata {
+			metadata[key] = value
+		}
+		r.Action.Metadata = metadata
+	}
+	return m.store.putAction(ctx, r)
+}
+
+// Delete retire une action du stockage de la rétention
+func (m *Manager) Delete(ctx context.Context, actionID string) error {
+	return m.store.deleteAction(ctx, actionID)
+}
+
+// SetPolicies remplace les politiques de rétention
+func (m *Manager) SetPolicies(policies []interfaces.RetentionPolicy) error {
+	if err := validatePolicies(policies); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policies = append([]interfaces.RetentionPolicy(nil), policies...)
+	return nil
+}
+
+// Policies renvoie une copie des politiques de rétention
+func (m *Manager) Policies() []interfaces.RetentionPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]interfaces.RetentionPolicy(nil), m.policies...)
+}
+
+// Apply supprime les actions expirées à la date now. Les sessions couvertes
+// par une politique Summarize sont résumées avant la suppression ; une
+// action que remove ne parvient pas à supprimer est conservée et reprise
+// au passage suivant.
+func (m *Manager) Apply(ctx context.Context, now time.Time, remove RemoveFunc) (*interfaces.RetentionReport, error) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	policies := m.Policies()
+	report := &interfaces.RetentionReport{RunAt: now}
+	records, err := m.store.actions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Scanned = len(records)
+	if len(policies) == 0 {
+		return report, nil
+	}
+
+	expired := expiredRecords(policies, records, now)
+	if len(expired) == 0 {
+		return report, nil
+	}
+
+	// Regroupement par utilisateur et session, dans l'ordre de stockage
+	type sessionKey struct{ user, session string }
+	sessions := make(map[sessionKey][]*record)
+	var order []sessionKey
+	for _, r := range records {
+		i, ok := expired[r]
+		if !ok || !policies[i].Summarize {
+			continue
+		}
+		key := sessionKey{r.user(), r.session()}
+		if _, seen := sessions[key]; !seen {
+			order = append(order, key)
+		}
+		sessions[key] = append(sessions[key], r)
+	}
+
+	skipped := make(map[*record]bool)
+	for _, key := range order {
+		id := summaryID(key.user, key.session)
+		err := func() error {
+			previous, err := m.store.summary(ctx, id)
+			if err != nil {
+				return err
+			}
+			summary := summarize(id, key.user, key.session, sessions[key], previous, m.sentences, now)
+			return m.store.putSummary(ctx, summary)
+		}()
+		if err != nil {
+			// Sans résumé, les actions de la session restent stockées
+			report.Errors = append(report.Errors, fmt.Sprintf("session %s: %v", key.session, err))
+			for _, r := range sessions[key] {
+				skipped[r] = true
+			}
+			continue
+		}
+		report.Summarized++
+	}
+
+	for _, r := range records {
+		if _, ok := expired[r]; !ok || skipped[r] {
+			continue
+		}
+		if err := m.remove(ctx, r.Action.ID, remove); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		report.Deleted++
+	}
+	return report, nil
+}
+
+// remove supprime une action des index puis du stockage
+func (m *Manager) remove(ctx context.Context, id string, remove RemoveFunc) error {
+	if remove != nil {
+		if err := remove(ctx, id); err != nil {
+			return fmt.Errorf("action %s: %w", id, err)
+		}
+	}
+	if err := m.store.deleteAction(ctx, id); err != nil {
+		return fmt.Errorf("action %s: %w", id, err)
+	}
+	return nil
+}
+
+// Summaries renvoie les résumés de session d'un workspace, ou de tous les
+// workspaces si workspacePath est vide, du plus ancien au plus récent
+func (m *Manager) Summaries(ctx context.Context, workspacePath string) ([]interfaces.SessionSummary, error) {
+	stored, err := m.store.summaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]interfaces.SessionSummary, 0, len(stored))
+	for _, summary := range stored {
+		if workspacePath == "" || summary.WorkspacePath == workspacePath {
+			summaries = append(summaries, *summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Start.Before(summaries[j].Start) })
+	return summaries, nil
+}
+
+// Export renvoie les actions et résumés d'un utilisateur, identifié par la
+// métadonnée user_id des actions
+func (m *Manager) Export(ctx context.Context, userID string) (*interfaces.UserDataExport, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+	records, err := m.store.actions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := m.store.summaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &interfaces.UserDataExport{
+		UserID:     userID,
+		Actions:    make([]interfaces.Action, 0),
+		Summaries:  make([]interfaces.SessionSummary, 0),
+		ExportedAt: time.Now(),
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].time().Before(records[j].time()) })
+	for _, r := range records {
+		if r.user() == userID {
+			export.Actions = append(export.Actions, r.Action)
+		}
+	}
+	for _, summary := range summaries {
+		if summary.UserID == userID {
+			export.Summaries = append(export.Summaries, *summary)
+		}
+	}
+	sort.Slice(export.Summaries, func(i, j int) bool { return export.Summaries[i].Start.Before(export.Summaries[j].Start) })
+	return export, nil
+}
+
+// Forget efface les actions et les résumés d'un utilisateur et renvoie le
+// nombre d'actions effacées. Les actions qui n'ont pas pu être effacées
+// sont signalées dans l'erreur et restent stockées pour un nouvel essai.
+func (m *Manager) Forget(ctx context.Context, userID string, remove RemoveFunc) (int, error) {
+	if userID == "" {
+		return 0, fmt.Errorf("user ID is required")
+	}
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	records, err := m.store.actions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	summaries, err := m.store.summaries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	deleted := 0
+	for _, r := range records {
+		if r.user() != userID {
+			continue
+		}
+		if err := m.remove(ctx, r.Action.ID, remove); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+	for _, summary := range summaries {
+		if summary.UserID != userID {
+			continue
+		}
+		if err := m.store.deleteSummary(ctx, summary.ID); err != nil {
+			errs = append(errs, fmt.Errorf("summary %s: %w", summary.SessionID, err))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// Run applique la rétention à intervalle régulier jusqu'à l'annulation du
+// contexte ; les erreurs sont transmises à report
+func (m *Manager) Run(ctx context.Context, interval time.Duration, remove RemoveFunc, report func(*interfaces.RetentionReport, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := m.Apply(ctx, now, remove)
+			if report != nil {
+				report(result, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package retention
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// validatePolicies rejette les motifs invalides et les limites négatives
+func validatePolicies(policies []interfaces.RetentionPolicy) error {
+	for i, policy := range policies {
+		if _, err := filepath.Match(policy.WorkspacePath, ""); err != nil {
+			return fmt.Errorf("policy %d: invalid workspace pattern %q: %w", i, policy.WorkspacePath, err)
+		}
+		if policy.MaxAge < 0 || policy.MaxActions < 0 {
+			return fmt.Errorf("policy %d: max_age and max_actions must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// matchPolicy renvoie l'index de la politique la plus spécifique pour
+// l'action, ou -1. Un workspace précis l'emporte sur un type d'action ;
+// à égalité, la première politique déclarée s'applique.
+func matchPolicy(policies []interfaces.RetentionPolicy, action interfaces.Action) int {
+	best, bestScore := -1, -1
+	for i, policy := range policies {
+		if policy.ActionType != "" && policy.ActionType != action.Type {
+			continue
+		}
+		if policy.WorkspacePath != "" && !matchWorkspace(policy.WorkspacePath, action.WorkspacePath) {
+			continue
+		}
+		score := 0
+		if policy.WorkspacePath != "" {
+			score += 2
+		}
+		if policy.ActionType != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// matchWorkspace accepte le workspace lui-même, ses sous-dossiers et les
+// motifs filepath.Match
+func matchWorkspace(pattern, path string) bool {
+	if path == "" {
+		return false
+	}
+	pattern, path = filepath.ToSlash(pattern), filepath.ToSlash(path)
+	if path == pattern || strings.HasPrefix(path, strings.TrimSuffix(pattern, "/")+"/") {
+		return true
+	}
+	matched, _ := filepath.Match(pattern, path)
+	return matched
+}
+
+// expiredRecords renvoie les enregistrements expirés à la date now, avec
+// l'index de la politique qui les fait expirer. MaxActions garde les
+// actions les plus récentes de chaque workspace couvert par la politique.
+func expiredRecords(policies []interfaces.RetentionPolicy, records []*record, now time.Time) map[*record]int {
+	expired := make(map[*record]int)
+	type scope struct {
+		policy    int
+		workspace string
+	}
+	scopes := make(map[scope][]*record)
+
+	for _, r := range records {
+		i := matchPolicy(policies, r.Action)
+		if i < 0 {
+			continue
+		}
+		policy := policies[i]
+		if policy.MaxAge > 0 && now.Sub(r.time()) > policy.MaxAge {
+			expired[r] = i
+			continue
+		}
+		if policy.MaxActions > 0 {
+			key := scope{i, r.Action.WorkspacePath}
+			scopes[key] = append(scopes[key], r)
+		}
+	}
+
+	for key, kept := range scopes {
+		limit := policies[key.policy].MaxActions
+		if len(kept) <= limit {
+			continue
+		}
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].time().After(kept[j].time()) })
+		for _, r := range kept[limit:] {
+			expired[r] = key.policy
+		}
+	}
+	return expired
+}
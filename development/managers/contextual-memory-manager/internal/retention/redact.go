@@ -0,0 +1,203 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// RedactionRule masque un type de donnée personnelle ou de secret
+type RedactionRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Group   int               // sous-groupe masqué, 0 pour toute la correspondance
+	Valid   func(string) bool // validation optionnelle de la valeur (Luhn, IBAN)
+}
+
+// Redactor masque les données personnelles et les secrets avant stockage.
+// Les valeurs masquées sont remplacées par [REDACTED:<règle>].
+type Redactor struct {
+	rules []RedactionRule
+}
+
+const redactedPrefix = "[REDACTED:"
+
+// DefaultRedactionRules renvoie les règles appliquées par défaut, des plus
+// spécifiques (clés privées, jetons) aux plus génériques (email, téléphone)
+func DefaultRedactionRules() []RedactionRule {
+	return []RedactionRule{
+		{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[\w-]{8,}\.[\w-]{8,}\.[\w-]{8,}`)},
+		{Name: "aws_access_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "github_token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+		{Name: "slack_token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+		{Name: "api_key", Pattern: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+		{Name: "bearer_token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`), Group: 1},
+		{Name: "password", Pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`), Group: 1},
+		{Name: "secret", Pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|client[_-]?secret)\b["']?\s*[:=]\s*["']?([^\s"',;]{4,})`), Group: 1},
+		{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+		{Name: "iban", Pattern: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`), Valid: validIBAN},
+		{Name: "credit_card", Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), Valid: validLuhn},
+		{Name: "phone", Pattern: regexp.MustCompile(`(?:\+|\b00)\d{1,3}[ .-]?\(?\d{1,4}\)?(?:[ .-]?\d{2,4}){2,4}\b|\b0[1-9](?:[ .-]?\d{2}){4}\b`)},
+	}
+}
+
+// NewRedactor crée un Redactor avec les règles par défaut suivies des
+// règles supplémentaires
+func NewRedactor(extra ...RedactionRule) *Redactor {
+	return &Redactor{rules: append(DefaultRedactionRules(), extra...)}
+}
+
+// Redact masque le texte et renvoie le nombre de valeurs masquées par règle
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	var counts map[string]int
+	for _, rule := range r.rules {
+		matches := rule.Pattern.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[2*rule.Group], m[2*rule.Group+1]
+			if start < 0 || strings.HasPrefix(text[start:], redactedPrefix) {
+				continue
+			}
+			if rule.Valid != nil && !rule.Valid(text[start:end]) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			fmt.Fprintf(&b, "%s%s]", redactedPrefix, rule.Name)
+			last = end
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[rule.Name]++
+		}
+		if last > 0 {
+			b.WriteString(text[last:])
+			text = b.String()
+		}
+	}
+	return text, counts
+}
+
+// RedactAction masque le texte et les métadonnées d'une action. Les
+// identifiants d'utilisateur et de session sont conservés pour l'export
+// et l'effacement ; le décompte des valeurs masquées est ajouté aux
+// métadonnées sous "redactions".
+func (r *Redactor) RedactAction(action interfaces.Action) interfaces.Action {
+	counts := make(map[string]int)
+	var found map[string]int
+	action.Text, found = r.Redact(action.Text)
+	merge(counts, found)
+
+	action.Metadata = r.redactMetadata(action.Metadata, counts)
+	if len(counts) > 0 {
+		if action.Metadata == nil {
+			action.Metadata = make(map[string]interface{}, 1)
+		}
+		action.Metadata["redactions"] = counts
+	}
+	return action
+}
+
+// RedactMetadata masque les valeurs textuelles d'une map de métadonnées,
+// sauf les identifiants d'utilisateur et de session
+func (r *Redactor) RedactMetadata(metadata map[string]interface{}) map[string]interface{} {
+	return r.redactMetadata(metadata, make(map[string]int))
+}
+
+func (r *Redactor) redactMetadata(metadata map[string]interface{}, counts map[string]int) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(metadata)+1)
+	for key, value := range metadata {
+		if key == interfaces.MetadataUserID || key == interfaces.MetadataSessionID {
+			out[key] = value
+			continue
+		}
+		out[key] = r.redactValue(value, counts)
+	}
+	return out
+}
+
+func (r *Redactor) redactValue(value interface{}, counts map[string]int) interface{} {
+	switch v := value.(type) {
+	case string:
+		text, found := r.Redact(v)
+		merge(counts, found)
+		return text
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			text, found := r.Redact(item)
+			merge(counts, found)
+			out[i] = text
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.redactValue(item, counts)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = r.redactValue(item, counts)
+		}
+		return out
+	}
+	return value
+}
+
+func merge(counts, found map[string]int) {
+	for name, n := range found {
+		counts[name] += n
+	}
+}
+
+// validLuhn vérifie la clé de Luhn d'un numéro de carte
+func validLuhn(value string) bool {
+	sum, digits := 0, 0
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// validIBAN vérifie la clé modulo 97 d'un IBAN
+func validIBAN(value string) bool {
+	iban := strings.ReplaceAll(value, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, c := range rearranged {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
@@ -0,0 +1,210 @@
+package retention
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+// KeyValueStore est la partie du StorageManager utilisée pour conserver
+// les actions capturées et les résumés de session
+type KeyValueStore interface {
+	Store(ctx context.Context, key string, value interface{}) error
+	Retrieve(ctx context.Context, key string) (interface{}, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+const (
+	actionPrefix    = "contextual_memory/actions/"
+	summaryPrefix   = "contextual_memory/summaries/"
+	encryptedPrefix = "enc:v1:"
+)
+
+// record est une action capturée, telle que stockée après masquage
+type record struct {
+	Action   interfaces.Action `json:"action"`
+	StoredAt time.Time         `json:"stored_at"`
+}
+
+// time renvoie l'horodatage de l'action, ou sa date de stockage
+func (r *record) time() time.Time {
+	if !r.Action.Timestamp.IsZero() {
+		return r.Action.Timestamp
+	}
+	return r.StoredAt
+}
+
+func (r *record) user() string {
+	return metadataString(r.Action.Metadata, interfaces.MetadataUserID)
+}
+
+// session renvoie l'identifiant de session, ou workspace@date pour les
+// actions capturées hors session
+func (r *record) session() string {
+	if session := metadataString(r.Action.Metadata, interfaces.MetadataSessionID); session != "" {
+		return session
+	}
+	return r.Action.WorkspacePath + "@" + r.time().UTC().Format("2006-01-02")
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	if value, ok := metadata[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// store sérialise les enregistrements dans le KeyValueStore, chiffrés si
+// un Cipher est configuré
+type store struct {
+	kv     KeyValueStore
+	cipher *Cipher
+}
+
+func summaryID(user, session string) string {
+	sum := sha256.Sum256([]byte(user + "\x00" + session))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (s *store) put(ctx context.Context, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if s.cipher == nil {
+		return s.kv.Store(ctx, key, string(data))
+	}
+	sealed, err := s.cipher.Seal(data, []byte(key))
+	if err != nil {
+		return err
+	}
+	return s.kv.Store(ctx, key, encryptedPrefix+base64.StdEncoding.EncodeToString(sealed))
+}
+
+func (s *store) get(ctx context.Context, key string, value interface{}) error {
+	raw, err := s.kv.Retrieve(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s: %w", key, err)
+	}
+	var data string
+	switch v := raw.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		return fmt.Errorf("unexpected value type %T for %s", raw, key)
+	}
+
+	if encoded, ok := strings.CutPrefix(data, encryptedPrefix); ok {
+		if s.cipher == nil {
+			return fmt.Errorf("record %s is encrypted and no encryption key is configured", key)
+		}
+		sealed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		plaintext, err := s.cipher.Open(sealed, []byte(key))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		data = string(plaintext)
+	}
+	if err := json.Unmarshal([]byte(data), value); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *store) putAction(ctx context.Context, r *record) error {
+	return s.put(ctx, actionPrefix+r.Action.ID, r)
+}
+
+// action charge une action stockée, nil si elle n'existe pas
+func (s *store) action(ctx context.Context, id string) (*record, error) {
+	keys, err := s.kv.List(ctx, actionPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored actions: %w", err)
+	}
+	for _, key := range keys {
+		if key == actionPrefix+id {
+			r := &record{}
+			if err := s.get(ctx, key, r); err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *store) deleteAction(ctx context.Context, id string) error {
+	return s.kv.Delete(ctx, actionPrefix+id)
+}
+
+// actions charge toutes les actions stockées
+func (s *store) actions(ctx context.Context) ([]*record, error) {
+	keys, err := s.kv.List(ctx, actionPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored actions: %w", err)
+	}
+	records := make([]*record, 0, len(keys))
+	for _, key := range keys {
+		r := &record{}
+		if err := s.get(ctx, key, r); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (s *store) putSummary(ctx context.Context, summary *interfaces.SessionSummary) error {
+	return s.put(ctx, summaryPrefix+summary.ID, summary)
+}
+
+func (s *store) summary(ctx context.Context, id string) (*interfaces.SessionSummary, error) {
+	keys, err := s.kv.List(ctx, summaryPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summaries: %w", err)
+	}
+	for _, key := range keys {
+		if key == summaryPrefix+id {
+			summary := &interfaces.SessionSummary{}
+			if err := s.get(ctx, key, summary); err != nil {
+				return nil, err
+			}
+			return summary, nil
+		}
+	}
+	return nil, nil
+}
+
+// summaries charge tous les résumés de session
+func (s *store) summaries(ctx context.Context) ([]*interfaces.SessionSummary, error) {
+	keys, err := s.kv.List(ctx, summaryPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summaries: %w", err)
+	}
+	summaries := make([]*interfaces.SessionSummary, 0, len(keys))
+	for _, key := range keys {
+		summary := &interfaces.SessionSummary{}
+		if err := s.get(ctx, key, summary); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func (s *store) deleteSummary(ctx context.Context, id string) error {
+	return s.kv.Delete(ctx, summaryPrefix+id)
+}
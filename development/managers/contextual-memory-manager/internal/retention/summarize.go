@@ -0,0 +1,236 @@
+package retention
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/contextual-memory-manager/interfaces"
+)
+
+const (
+	maxSummaryFiles    = 5
+	maxHighlightLength = 200
+	redundancyLimit    = 0.6
+)
+
+// Mots vides ignorés pour le score des phrases
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "not": true, "but": true,
+	"into": true, "when": true, "then": true, "than": true, "all": true, "its": true, "out": true,
+	"les": true, "des": true, "une": true, "pour": true, "dans": true, "par": true, "sur": true,
+	"avec": true, "est": true, "pas": true, "que": true, "qui": true, "aux": true, "ces": true,
+	"son": true, "ses": true, "sont": true, "mais": true, "plus": true, "comme": true,
+}
+
+// sentence est une phrase candidate au résumé
+type sentence struct {
+	text  string
+	terms []string
+	order int
+	score float64
+}
+
+// summarize produit un résumé extractif hors ligne des actions d'une
+// session : les phrases dont les termes sont les plus fréquents dans la
+// session sont retenues, sans redondance, dans l'ordre chronologique. Un
+// résumé précédent de la même session est complété : ses phrases
+// reprennent la compétition avec les nouvelles.
+func summarize(id, user, session string, records []*record, previous *interfaces.SessionSummary, maxSentences int, now time.Time) *interfaces.SessionSummary {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].time().Before(records[j].time()) })
+
+	summary := &interfaces.SessionSummary{
+		ID:          id,
+		SessionID:   session,
+		UserID:      user,
+		ActionTypes: make(map[string]int),
+		UpdatedAt:   now,
+	}
+	files := make(map[string]int)
+	var candidates []*sentence
+
+	if previous != nil {
+		summary.WorkspacePath = previous.WorkspacePath
+		summary.Start, summary.End = previous.Start, previous.End
+		summary.ActionCount = previous.ActionCount
+		for actionType, n := range previous.ActionTypes {
+			summary.ActionTypes[actionType] += n
+		}
+		// Les fichiers déjà résumés gardent leur rang
+		for i, file := range previous.Files {
+			files[file] += len(previous.Files) - i
+		}
+		for _, text := range previous.Highlights {
+			candidates = append(candidates, &sentence{text: text, terms: terms(text), order: len(candidates)})
+		}
+	}
+
+	for _, r := range records {
+		at := r.time()
+		if summary.Start.IsZero() || at.Before(summary.Start) {
+			summary.Start = at
+		}
+		if at.After(summary.End) {
+			summary.End = at
+		}
+		if summary.WorkspacePath == "" {
+			summary.WorkspacePath = r.Action.WorkspacePath
+		}
+		summary.ActionCount++
+		summary.ActionTypes[r.Action.Type]++
+		if r.Action.FilePath != "" {
+			files[r.Action.FilePath]++
+		}
+		for _, text := range splitSentences(r.Action.Text) {
+			candidates = append(candidates, &sentence{text: text, terms: terms(text), order: len(candidates)})
+		}
+	}
+
+	summary.Files = topFiles(files)
+	summary.Highlights = highlights(candidates, maxSentences)
+	return summary
+}
+
+// highlights sélectionne les maxSentences meilleures phrases
+func highlights(candidates []*sentence, maxSentences int) []string {
+	frequency := make(map[string]int)
+	maxFrequency := 0
+	for _, s := range candidates {
+		for _, term := range s.terms {
+			frequency[term]++
+			maxFrequency = max(maxFrequency, frequency[term])
+		}
+	}
+	for _, s := range candidates {
+		if len(s.terms) == 0 {
+			continue
+		}
+		total := 0.0
+		for _, term := range s.terms {
+			total += float64(frequency[term]) / float64(maxFrequency)
+		}
+		// Normalisation douce : les phrases longues ne sont pas favorisées
+		// à l'excès, les phrases d'un mot ne l'emportent pas
+		s.score = total / math.Sqrt(float64(len(s.terms)))
+	}
+
+	ranked := append([]*sentence(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	var selected []*sentence
+	seen := make(map[string]bool)
+	for _, s := range ranked {
+		if len(selected) == maxSentences {
+			break
+		}
+		if s.score == 0 || seen[strings.ToLower(s.text)] {
+			continue
+		}
+		redundant := false
+		for _, other := range selected {
+			if overlap(s.terms, other.terms) > redundancyLimit {
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		seen[strings.ToLower(s.text)] = true
+		selected = append(selected, s)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].order < selected[j].order })
+
+	texts := make([]string, len(selected))
+	for i, s := range selected {
+		texts[i] = s.text
+	}
+	return texts
+}
+
+// splitSentences découpe un texte en phrases sur les fins de ligne et la
+// ponctuation finale suivie d'un espace
+func splitSentences(text string) []string {
+	var sentences []string
+	add := func(s string) {
+		s = strings.Join(strings.Fields(s), " ")
+		if s == "" {
+			return
+		}
+		if utf8.RuneCountInString(s) > maxHighlightLength {
+			runes := []rune(s)
+			s = string(runes[:maxHighlightLength-3]) + "..."
+		}
+		sentences = append(sentences, s)
+	}
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			add(text[start:i])
+			start = i + 1
+		case '.', '!', '?':
+			if i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\n' {
+				add(text[start : i+1])
+				start = i + 1
+			}
+		}
+	}
+	add(text[start:])
+	return sentences
+}
+
+// terms renvoie les termes significatifs d'une phrase, sans doublon
+func terms(text string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if utf8.RuneCountInString(word) < 3 || stopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		result = append(result, word)
+	}
+	return result
+}
+
+// overlap est l'indice de Jaccard entre deux ensembles de termes
+func overlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, term := range a {
+		set[term] = true
+	}
+	shared := 0
+	for _, term := range b {
+		if set[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func topFiles(files map[string]int) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if files[names[i]] != files[names[j]] {
+			return files[names[i]] > files[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxSummaryFiles {
+		names = names[:maxSummaryFiles]
+	}
+	return names
+}
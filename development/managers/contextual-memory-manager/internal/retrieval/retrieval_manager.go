@@ -3,6 +3,7 @@ package retrieval
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -17,9 +18,14 @@ type retrievalManagerImpl struct {
 	errorManager   baseInterfaces.ErrorManager
 
 	pgDB        *sql.DB
+	textCipher  interfaces.TextCipher
 	initialized bool
 }
 
+// errEncryptedTextSearch est renvoyée par la recherche plein texte de
+// PostgreSQL, qui ne peut pas indexer un texte chiffré
+var errEncryptedTextSearch = errors.New("full-text search is not available when the action text is encrypted")
+
 // NewRetrievalManager crÃ©e une nouvelle instance de RetrievalManager
 func NewRetrievalManager(
 	storageManager baseInterfaces.StorageManager,
@@ -35,6 +41,13 @@ func NewRetrievalManager(
 	}, nil
 }
 
+// SetTextCipher déchiffre le texte des actions lues dans contextual_actions,
+// dont la colonne action_text est alors écrite chiffrée. Le filtre texte de
+// QueryContext est appliqué après déchiffrement.
+func (rm *retrievalManagerImpl) SetTextCipher(cipher interfaces.TextCipher) {
+	rm.textCipher = cipher
+}
+
 // Initialize implÃ©mente BaseManager.Initialize
 func (rm *retrievalManagerImpl) Initialize(ctx context.Context) error {
 	if rm.initialized {
@@ -69,6 +82,14 @@ func (rm *retrievalManagerImpl) QueryContext(ctx context.Context, query interfac
 		return nil, fmt.Errorf("RetrievalManager not initialized")
 	}
 
+	// Le texte chiffré ne peut pas être filtré par PostgreSQL : le filtre et
+	// la limite sont appliqués après déchiffrement
+	textFilter, limit := "", 0
+	if rm.textCipher != nil && query.Text != "" {
+		textFilter, limit = strings.ToLower(query.Text), query.Limit
+		query.Text, query.Limit = "", 0
+	}
+
 	// Construction de la requÃªte SQL dynamique
 	sqlQuery, args := rm.buildContextQuery(query)
 
@@ -98,12 +119,21 @@ func (rm *retrievalManagerImpl) QueryContext(ctx context.Context, query interfac
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan context result: %w", err)
 		}
+		if err := rm.openText(&action); err != nil {
+			return nil, err
+		}
+		if textFilter != "" && !strings.Contains(strings.ToLower(action.Text), textFilter) {
+			continue
+		}
 
 		result.Action = action
 		results = append(results, result)
+		if limit > 0 && len(results) == limit {
+			break
+		}
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
 // GetActionMetadata rÃ©cupÃ¨re les mÃ©tadonnÃ©es d'une action
@@ -134,6 +164,9 @@ func (rm *retrievalManagerImpl) GetActionMetadata(ctx context.Context, actionID
 		}
 		return nil, fmt.Errorf("failed to get action metadata: %w", err)
 	}
+	if err := rm.openText(&action); err != nil {
+		return nil, err
+	}
 
 	return &action, nil
 }
@@ -143,6 +176,9 @@ func (rm *retrievalManagerImpl) SearchByText(ctx context.Context, text string, w
 	if !rm.initialized {
 		return nil, fmt.Errorf("RetrievalManager not initialized")
 	}
+	if rm.textCipher != nil {
+		return nil, errEncryptedTextSearch
+	}
 
 	// RequÃªte de recherche textuelle avec PostgreSQL full-text search
 	query := `
@@ -231,6 +267,9 @@ func (rm *retrievalManagerImpl) GetActionsBySession(ctx context.Context, session
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan action: %w", err)
 		}
+		if err := rm.openText(&action); err != nil {
+			return nil, err
+		}
 
 		actions = append(actions, action)
 	}
@@ -330,6 +369,19 @@ func (rm *retrievalManagerImpl) ensureTablesExist(ctx context.Context) error {
 	return nil
 }
 
+// openText déchiffre le texte d'une action lue dans contextual_actions
+func (rm *retrievalManagerImpl) openText(action *interfaces.Action) error {
+	if rm.textCipher == nil {
+		return nil
+	}
+	text, err := rm.textCipher.OpenText(action.Text, action.ID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt action %s: %w", action.ID, err)
+	}
+	action.Text = text
+	return nil
+}
+
 func (rm *retrievalManagerImpl) buildContextQuery(query interfaces.ContextQuery) (string, []interface{}) {
 	var whereClauses []string
 	var args []interface{}
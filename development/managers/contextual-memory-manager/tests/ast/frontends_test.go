@@ -0,0 +1,390 @@
+// tests/ast/frontends_test.go
+package ast
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contextual-memory-manager/interfaces"
+	"github.com/contextual-memory-manager/internal/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const typeScriptSource = `import { readFile } from "node:fs/promises";
+import * as path from "path";
+import Logger, { Level } from "./logger";
+
+// Délai par défaut, en millisecondes
+export const DEFAULT_TIMEOUT = 30_000;
+let retries = 3;
+
+/**
+ * Charge la configuration depuis le disque
+ */
+export async function loadConfig(file: string, ...overrides: Partial<Config>[]): Promise<Config> {
+	const raw = await readFile(path.resolve(file), "utf8"); // "{" n'ouvre rien
+	if (!raw) {
+		throw new Error("empty");
+	}
+	return JSON.parse(raw);
+}
+
+export interface Config {
+	name: string;
+	level?: Level;
+	validate(strict: boolean): boolean;
+}
+
+export class FileStore extends BaseStore implements Store<Config> {
+	private cache = new Map<string, Config>();
+
+	constructor(private readonly root: string) {
+		super();
+	}
+
+	async get(key: string): Promise<Config | undefined> {
+		return this.cache.get(key);
+	}
+
+	static create = (root: string): FileStore => new FileStore(root);
+}
+
+export type Handler = (config: Config) => void;
+
+export enum Mode {
+	Read,
+	Write = "w",
+}
+
+const helper = (value: number) => value * 2;
+`
+
+const pythonSource = `"""Outils de synchronisation."""
+import os
+from typing import Optional
+from .models import Base
+
+__all__ = ["Syncer", "sync_all"]
+
+MAX_WORKERS = 4
+registry = {}
+
+
+class Syncer(Base):
+    """Synchronise un dossier."""
+
+    retries: int = 3
+
+    def __init__(self, root: str, *, dry_run: bool = False):
+        self.root = root
+        self.dry_run = dry_run
+
+    @property
+    def name(self) -> str:
+        return os.path.basename(self.root)
+
+    def run(self, *paths: str, **options) -> Optional[int]:
+        count = 0
+        for path in paths:
+            if path.startswith("."):
+                continue
+            count += 1
+        return count
+
+
+# Synchronise toutes les cibles
+def sync_all(targets, timeout: float = 1.0) -> int:
+    return sum(Syncer(t).run() or 0 for t in targets)
+
+
+def _private():
+    pass
+`
+
+const powerShellSource = `#Requires -Modules Pester
+using namespace System.Collections.Generic
+Import-Module -Name Az.Accounts
+. "$PSScriptRoot\helpers.ps1"
+
+$script:DefaultPath = 'C:\temp'
+Set-Variable -Name MaxItems -Value 10 -Option Constant
+
+<#
+.SYNOPSIS
+Envoie un rapport.
+#>
+function Send-Report {
+	[CmdletBinding()]
+	[OutputType([bool])]
+	param(
+		[Parameter(Mandatory)]
+		[string]$Path,
+		[int]$Retries = 3
+	)
+	if (-not (Test-Path $Path)) {
+		return $false
+	}
+	foreach ($i in 1..$Retries) { }
+	return $true
+}
+
+function Get-Internal($Name) { "x" }
+
+class Mailer : Base {
+	[string]$Server
+	hidden [int]$Port = 25
+
+	Mailer([string]$server) { $this.Server = $server }
+
+	[bool] Send([string]$to) {
+		return $true
+	}
+}
+
+enum Priority {
+	Low
+	High
+}
+
+Export-ModuleMember -Function Send-*
+`
+
+func newFrontendManager(t *testing.T) interfaces.ASTAnalysisManager {
+	manager, err := ast.NewASTAnalysisManager(
+		&mockStorageManager{},
+		&mockErrorManager{},
+		&mockConfigManager{},
+		&mockMonitoringManager{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Initialize(context.Background()))
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	return manager
+}
+
+func writeSource(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func findFunction(functions []interfaces.FunctionInfo, name string) *interfaces.FunctionInfo {
+	for i := range functions {
+		if functions[i].Name == name {
+			return &functions[i]
+		}
+	}
+	return nil
+}
+
+func findType(types []interfaces.TypeInfo, name string) *interfaces.TypeInfo {
+	for i := range types {
+		if types[i].Name == name {
+			return &types[i]
+		}
+	}
+	return nil
+}
+
+func TestASTAnalysisManager_AnalyzeTypeScript(t *testing.T) {
+	manager := newFrontendManager(t)
+	path := writeSource(t, t.TempDir(), "src/store.ts", typeScriptSource)
+
+	result, err := manager.AnalyzeFile(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "typescript", result.Context["language"])
+
+	require.Len(t, result.Imports, 3)
+	assert.Equal(t, "node:fs/promises", result.Imports[0].Path)
+	assert.True(t, result.Imports[0].IsStandard)
+	assert.Equal(t, "path", result.Imports[1].Alias)
+	assert.False(t, result.Imports[2].IsStandard)
+
+	load := findFunction(result.Functions, "loadConfig")
+	require.NotNil(t, load)
+	assert.True(t, load.IsExported)
+	assert.Equal(t, "Charge la configuration depuis le disque", load.Documentation)
+	assert.Equal(t, []string{"Promise<Config>"}, load.ReturnTypes)
+	require.Len(t, load.Parameters, 2)
+	assert.Equal(t, interfaces.ParameterInfo{Name: "file", Type: "string"}, load.Parameters[0])
+	assert.True(t, load.Parameters[1].IsVariadic)
+	assert.Equal(t, 2, load.Complexity)
+
+	store := findType(result.Types, "FileStore")
+	require.NotNil(t, store)
+	assert.Equal(t, "class", store.Kind)
+	assert.Equal(t, []string{"BaseStore", "Store"}, store.BaseTypes)
+	assert.NotNil(t, findFunction(store.Methods, "get"))
+	assert.NotNil(t, findFunction(store.Methods, "create"))
+	fields := make([]string, 0, len(store.Fields))
+	for _, field := range store.Fields {
+		fields = append(fields, field.Name)
+	}
+	assert.ElementsMatch(t, []string{"cache", "root"}, fields)
+
+	config := findType(result.Types, "Config")
+	require.NotNil(t, config)
+	assert.Equal(t, "interface", config.Kind)
+	assert.NotNil(t, findFunction(config.Methods, "validate"))
+	assert.Equal(t, "type_alias", findType(result.Types, "Handler").Kind)
+	assert.Len(t, findType(result.Types, "Mode").Fields, 2)
+
+	require.Len(t, result.Constants, 1)
+	assert.Equal(t, "DEFAULT_TIMEOUT", result.Constants[0].Name)
+	assert.Equal(t, "Délai par défaut, en millisecondes", result.Constants[0].Documentation)
+	require.Len(t, result.Variables, 1)
+	assert.Equal(t, "retries", result.Variables[0].Name)
+	helper := findFunction(result.Functions, "helper")
+	require.NotNil(t, helper)
+	assert.False(t, helper.IsExported)
+}
+
+func TestASTAnalysisManager_AnalyzePython(t *testing.T) {
+	manager := newFrontendManager(t)
+	path := writeSource(t, t.TempDir(), "sync/syncer.py", pythonSource)
+
+	result, err := manager.AnalyzeFile(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "python", result.Context["language"])
+
+	require.Len(t, result.Imports, 3)
+	assert.True(t, result.Imports[0].IsStandard)
+	assert.Equal(t, ".models", result.Imports[2].Path)
+
+	syncer := findType(result.Types, "Syncer")
+	require.NotNil(t, syncer)
+	assert.Equal(t, "class", syncer.Kind)
+	assert.True(t, syncer.IsExported)
+	assert.Equal(t, "Synchronise un dossier.", syncer.Documentation)
+	assert.Equal(t, []string{"Base"}, syncer.BaseTypes)
+	fields := make([]string, 0, len(syncer.Fields))
+	for _, field := range syncer.Fields {
+		fields = append(fields, field.Name)
+	}
+	assert.ElementsMatch(t, []string{"retries", "root", "dry_run"}, fields)
+
+	run := findFunction(syncer.Methods, "run")
+	require.NotNil(t, run)
+	assert.Equal(t, []string{"Optional[int]"}, run.ReturnTypes)
+	require.Len(t, run.Parameters, 2)
+	assert.True(t, run.Parameters[0].IsVariadic)
+	assert.Equal(t, 3, run.Complexity)
+	assert.Equal(t, "property", findFunction(syncer.Methods, "name").Annotations["decorators"])
+
+	syncAll := findFunction(result.Functions, "sync_all")
+	require.NotNil(t, syncAll)
+	assert.True(t, syncAll.IsExported)
+	assert.Equal(t, "Synchronise toutes les cibles", syncAll.Documentation)
+	assert.Equal(t, []string{"int"}, syncAll.ReturnTypes)
+	assert.False(t, findFunction(result.Functions, "_private").IsExported)
+
+	require.Len(t, result.Constants, 1)
+	assert.Equal(t, "MAX_WORKERS", result.Constants[0].Name)
+	require.Len(t, result.Variables, 1)
+	assert.False(t, result.Variables[0].IsExported)
+}
+
+func TestASTAnalysisManager_AnalyzePowerShell(t *testing.T) {
+	manager := newFrontendManager(t)
+	path := writeSource(t, t.TempDir(), "Reports.psm1", powerShellSource)
+
+	result, err := manager.AnalyzeFile(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "powershell", result.Context["language"])
+	assert.Equal(t, "Reports", result.Package)
+
+	paths := make([]string, 0, len(result.Imports))
+	for _, imp := range result.Imports {
+		paths = append(paths, imp.Path)
+	}
+	assert.Contains(t, paths, "Pester")
+	assert.Contains(t, paths, "Az.Accounts")
+	assert.Contains(t, paths, "System.Collections.Generic")
+
+	send := findFunction(result.Functions, "Send-Report")
+	require.NotNil(t, send)
+	assert.True(t, send.IsExported)
+	assert.Contains(t, send.Documentation, "Envoie un rapport.")
+	assert.Equal(t, []string{"bool"}, send.ReturnTypes)
+	require.Len(t, send.Parameters, 2)
+	assert.Equal(t, interfaces.ParameterInfo{Name: "Path", Type: "string"}, send.Parameters[0])
+	assert.Equal(t, "int", send.Parameters[1].Type)
+	assert.Equal(t, 3, send.Complexity)
+
+	internal := findFunction(result.Functions, "Get-Internal")
+	require.NotNil(t, internal)
+	assert.False(t, internal.IsExported)
+
+	mailer := findType(result.Types, "Mailer")
+	require.NotNil(t, mailer)
+	assert.Equal(t, []string{"Base"}, mailer.BaseTypes)
+	assert.Len(t, mailer.Fields, 2)
+	sendMail := findFunction(mailer.Methods, "Send")
+	require.NotNil(t, sendMail)
+	assert.Equal(t, []string{"bool"}, sendMail.ReturnTypes)
+	assert.Equal(t, "enum", findType(result.Types, "Priority").Kind)
+
+	require.Len(t, result.Constants, 1)
+	assert.Equal(t, "MaxItems", result.Constants[0].Name)
+}
+
+func TestASTAnalysisManager_EnrichContextWithASTTypeScript(t *testing.T) {
+	manager := newFrontendManager(t)
+	path := writeSource(t, t.TempDir(), "store.ts", typeScriptSource)
+
+	enriched, err := manager.EnrichContextWithAST(context.Background(), interfaces.Action{
+		ID:         "action-1",
+		FilePath:   path,
+		LineNumber: 14,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "typescript", enriched.ASTContext["language"])
+	require.NotNil(t, enriched.ASTResult)
+	assert.NotNil(t, findFunction(enriched.ASTResult.Functions, "loadConfig"))
+}
+
+func TestASTAnalysisManager_SearchByStructureAcrossLanguages(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+	ctx := context.Background()
+	writeSource(t, root, "scripts/syncer.py", pythonSource)
+	writeSource(t, root, "web/store.ts", typeScriptSource)
+	writeSource(t, root, "web/node_modules/dep/index.js", "export function ignored() {}\n")
+
+	query := ast.ParseStructuralQuery("python functions returning int")
+	assert.Equal(t, "function", query.Type)
+	assert.Equal(t, "python", query.Language)
+	query.WorkspacePath = root
+	results, err := manager.SearchByStructure(ctx, query)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"scripts/syncer.py:sync_all"}, resultIDs(results))
+
+	query = ast.ParseStructuralQuery("classes extending BaseStore")
+	assert.Equal(t, "class", query.Type)
+	query.WorkspacePath = root
+	results, err = manager.SearchByStructure(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web/store.ts:FileStore"}, resultIDs(results))
+	assert.Equal(t, "typescript", results[0].Context["language"])
+
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+		Type:          "function",
+		Name:          "ignored",
+		WorkspacePath: root,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Les fichiers Go restent indexés par package
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+		Type:          "function",
+		Name:          "NewStore",
+		Language:      "go",
+		WorkspacePath: root,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/ws/store.NewStore"}, resultIDs(results))
+}
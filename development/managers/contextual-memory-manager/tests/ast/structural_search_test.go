@@ -0,0 +1,361 @@
+// tests/ast/structural_search_test.go
+package ast
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contextual-memory-manager/interfaces"
+	"github.com/contextual-memory-manager/internal/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Workspace de test : un module avec une interface, deux implémentations
+// de forme proche, une implémentation partielle et deux fonctions
+// mutuellement récursives
+var workspaceFiles = map[string]string{
+	"go.mod": "module example.com/ws\n\ngo 1.21\n",
+	"iface/iface.go": `package iface
+
+import "context"
+
+// Manager est implémenté par les services
+type Manager interface {
+	Initialize(ctx context.Context) error
+	Name() string
+}
+`,
+	"service/service.go": `package service
+
+import (
+	"context"
+	"errors"
+
+	"example.com/ws/iface"
+)
+
+type Service struct {
+	name string
+}
+
+func NewService(name string) *Service {
+	return &Service{name: name}
+}
+
+func (s *Service) Initialize(ctx context.Context) error {
+	return s.validate()
+}
+
+func (s *Service) Name() string {
+	return s.name
+}
+
+func (s *Service) validate() error {
+	if s.name == "" {
+		return errors.New("empty name")
+	}
+	return nil
+}
+
+// Run initialise un manager
+func Run(ctx context.Context, manager iface.Manager) error {
+	return manager.Initialize(ctx)
+}
+`,
+	"service/partial.go": `package service
+
+type Partial struct{}
+
+func (Partial) Name() string { return "partial" }
+`,
+	"store/store.go": `package store
+
+import (
+	"context"
+	"errors"
+)
+
+type Store struct {
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Initialize(ctx context.Context) error {
+	return s.check()
+}
+
+func (s *Store) Name() string {
+	return s.path
+}
+
+func (s *Store) check() error {
+	if s.path == "" {
+		return errors.New("empty path")
+	}
+	return nil
+}
+`,
+	"cycle/cycle.go": `package cycle
+
+import "strconv"
+
+func Ping(n int) int {
+	return Pong(n - 1)
+}
+
+func Pong(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return Ping(n)
+}
+
+func Format(n int) string {
+	return strconv.Itoa(Ping(n))
+}
+`,
+}
+
+func newStructuralWorkspace(t *testing.T) (string, interfaces.ASTAnalysisManager) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain is not installed")
+	}
+
+	root := t.TempDir()
+	for name, content := range workspaceFiles {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	manager, err := ast.NewASTAnalysisManager(
+		&mockStorageManager{},
+		&mockErrorManager{},
+		&mockConfigManager{},
+		&mockMonitoringManager{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.Initialize(context.Background()))
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	return root, manager
+}
+
+func resultIDs(results []interfaces.StructuralResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Context["id"].(string))
+	}
+	return ids
+}
+
+func TestASTAnalysisManager_SearchByStructure(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+	ctx := context.Background()
+
+	// "functions returning error that take a context"
+	query := ast.ParseStructuralQuery("functions returning error that take a context")
+	assert.Equal(t, "function", query.Type)
+	assert.Equal(t, "error", query.ReturnType)
+	assert.Equal(t, []interfaces.ParameterInfo{{Type: "context"}}, query.Parameters)
+
+	query.WorkspacePath = root
+	results, err := manager.SearchByStructure(ctx, query)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"example.com/ws/service.Service.Initialize",
+		"example.com/ws/service.Run",
+		"example.com/ws/store.Store.Initialize",
+	}, resultIDs(results))
+
+	function := results[0].Element.(interfaces.FunctionInfo)
+	assert.Equal(t, "context.Context", function.Parameters[0].Type)
+	assert.Equal(t, []string{"error"}, function.ReturnTypes)
+
+	// "types implementing iface.Manager" : Partial n'a pas Initialize
+	query = ast.ParseStructuralQuery("types implementing iface.Manager")
+	assert.Equal(t, "type", query.Type)
+	assert.Equal(t, "iface.Manager", query.Implements)
+	query.WorkspacePath = root
+	results, err = manager.SearchByStructure(ctx, query)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"example.com/ws/service.Service",
+		"example.com/ws/store.Store",
+	}, resultIDs(results))
+
+	// Interfaces des dépendances
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+		Type:          "struct",
+		Implements:    "error",
+		WorkspacePath: root,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Nom, package et usages
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+		Type:          "method",
+		Name:          "validate",
+		Package:       "service",
+		WorkspacePath: root,
+		IncludeUsages: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1.0, results[0].Relevance)
+	require.Len(t, results[0].Context["usages"], 1)
+	assert.Contains(t, results[0].Context["usages"].([]string)[0], "example.com/ws/service.Service.Initialize")
+
+	// Imports
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+		Type:          "import",
+		Name:          "errors",
+		WorkspacePath: root,
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// Un texte sans structure est recherché comme nom
+	assert.Equal(t, interfaces.StructuralQuery{Type: "any", Name: "NewStore"}, ast.ParseStructuralQuery("NewStore"))
+}
+
+func TestASTAnalysisManager_IncrementalReindex(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+	ctx := context.Background()
+
+	search := func(name string) []interfaces.StructuralResult {
+		results, err := manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+			Type:          "function",
+			Name:          name,
+			WorkspacePath: root,
+		})
+		require.NoError(t, err)
+		return results
+	}
+	require.Len(t, search("Close"), 0)
+
+	// Ajout d'une fonction : seul le package modifié est rechargé
+	storeFile := filepath.Join(root, "store", "store.go")
+	content, err := os.ReadFile(storeFile)
+	require.NoError(t, err)
+	content = append(content, []byte("\nfunc (s *Store) Close() error { return nil }\n")...)
+	require.NoError(t, os.WriteFile(storeFile, content, 0644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(storeFile, later, later))
+
+	results := search("Close")
+	require.Len(t, results, 1)
+	assert.Equal(t, "example.com/ws/store.Store.Close", results[0].Context["id"])
+
+	// Nouveau package et suppression de fichier
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cache"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cache", "cache.go"),
+		[]byte("package cache\n\nfunc Purge() {}\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(root, "service", "partial.go")))
+
+	assert.Len(t, search("Purge"), 1)
+	results, err = manager.SearchByStructure(ctx, interfaces.StructuralQuery{Type: "type", Name: "Partial", WorkspacePath: root})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestASTAnalysisManager_ReindexDependents(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+	ctx := context.Background()
+
+	// app embarque names à travers client
+	files := map[string]string{
+		"names/names.go":   "package names\n\ntype Base struct{}\n",
+		"client/client.go": "package client\n\nimport \"example.com/ws/names\"\n\ntype Failure struct{ names.Base }\n",
+		"app/app.go":       "package app\n\nimport \"example.com/ws/client\"\n\ntype Wrapped struct{ client.Failure }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	implementingError := func() []string {
+		results, err := manager.SearchByStructure(ctx, interfaces.StructuralQuery{
+			Type:          "struct",
+			Implements:    "error",
+			WorkspacePath: root,
+		})
+		require.NoError(t, err)
+		return resultIDs(results)
+	}
+	assert.Empty(t, implementingError())
+
+	// Seul names change : client et app sont rechargés avec lui
+	namesFile := filepath.Join(root, "names", "names.go")
+	require.NoError(t, os.WriteFile(namesFile, []byte("package names\n\ntype Base struct{}\n\nfunc (Base) Error() string { return \"\" }\n"), 0644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(namesFile, later, later))
+
+	assert.ElementsMatch(t, []string{
+		"example.com/ws/names.Base",
+		"example.com/ws/client.Failure",
+		"example.com/ws/app.Wrapped",
+	}, implementingError())
+}
+
+func TestASTAnalysisManager_MapDependencies(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+
+	graph, err := manager.MapDependencies(context.Background(), filepath.Join(root, "cycle", "cycle.go"))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"example.com/ws/cycle.Ping", "example.com/ws/cycle.Pong"}}, graph.Cycles)
+	assert.Equal(t, "external", graph.Nodes["strconv.Itoa"].Type)
+	assert.Equal(t, 0, graph.Levels["strconv.Itoa"])
+	assert.Equal(t, 0, graph.Levels["example.com/ws/cycle.Ping"])
+	assert.Equal(t, 1, graph.Levels["example.com/ws/cycle.Format"])
+	assert.Equal(t, 2, graph.Levels[filepath.Join(root, "cycle", "cycle.go")])
+
+	_, err = manager.MapDependencies(context.Background(), filepath.Join(root, "go.mod"))
+	assert.Error(t, err)
+}
+
+func TestASTAnalysisManager_GetSimilarStructures(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+
+	matches, err := manager.GetSimilarStructures(context.Background(), filepath.Join(root, "store", "store.go"), 2)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, filepath.Join(root, "service", "service.go"), matches[0].FilePath)
+	assert.Greater(t, matches[0].Similarity, matches[1].Similarity)
+	assert.Contains(t, matches[0].MatchedElements, "method Initialize func(context.Context) error")
+	assert.Contains(t, matches[0].Differences, "+ import example.com/ws/iface")
+}
+
+func TestASTAnalysisManager_TraverseFileSystem(t *testing.T) {
+	root, manager := newStructuralWorkspace(t)
+
+	graph, err := manager.TraverseFileSystem(context.Background(), root, interfaces.TraversalFilters{
+		Extensions:   []string{"go"},
+		ExcludePaths: []string{"cycle"},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, graph.Nodes, filepath.Join(root, "service", "service.go"))
+	assert.NotContains(t, graph.Nodes, filepath.Join(root, "go.mod"))
+	assert.NotContains(t, graph.Nodes, filepath.Join(root, "cycle"))
+	assert.True(t, graph.Nodes[filepath.Join(root, "service", "service.go")].IsAnalyzed)
+	assert.Contains(t, graph.Relationships, interfaces.FileRelation{
+		From: filepath.Join(root, "service", "service.go"),
+		To:   filepath.Join(root, "iface"),
+		Type: "imports",
+	})
+
+	graph, err = manager.TraverseFileSystem(context.Background(), root, interfaces.TraversalFilters{MaxDepth: 1})
+	require.NoError(t, err)
+	assert.Contains(t, graph.Nodes, filepath.Join(root, "go.mod"))
+	assert.NotContains(t, graph.Nodes, filepath.Join(root, "store"))
+}
@@ -0,0 +1,32 @@
+package auto_fix
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func BenchmarkApplyEdits(b *testing.B) {
+	src := []byte(sampleSource)
+	offset := strings.Index(sampleSource, "var unused = 42")
+	edits := []TextEdit{{Offset: offset, End: offset + len("var unused = 42")}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ApplyEdits(src, edits); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAnalyzeCode(b *testing.B) {
+	path := writeModule(b)
+	engine := NewSuggestionEngine(EngineConfig{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.AnalyzeCode(context.Background(), path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 type CLIInterface struct {
 	suggestionEngine *SuggestionEngine
 	validationSystem *ValidationSystem
+	safeApplier      *SafeApplier
 	config           CLIConfig
 	history          []CLIAction
 }
@@ -78,9 +79,16 @@ func NewCLIInterface(engine *SuggestionEngine, validation *ValidationSystem, con
 		config.OutputFormat = "colored"
 	}
 
+	applyConfig := SafeApplyConfig{}
+	if validation != nil {
+		applyConfig.RunTests = validation.config.EnableTests
+		applyConfig.TestTimeout = validation.config.Timeout
+	}
+
 	return &CLIInterface{
 		suggestionEngine: engine,
 		validationSystem: validation,
+		safeApplier:      NewSafeApplier(applyConfig),
 		config:           config,
 		history:          make([]CLIAction, 0),
 	}
@@ -321,6 +329,21 @@ func (cli *CLIInterface) applyFix(suggestion *FixSuggestion, originalCode string
 		}
 	}
 
+	// Edit-based fixes are applied in place and rolled back if the package
+	// stops compiling or its tests fail
+	if len(suggestion.Edits) > 0 {
+		report, err := cli.safeApplier.Apply(context.Background(), suggestion)
+		if err != nil {
+			if report != nil && report.RolledBack {
+				for _, typeErr := range report.TypeErrors {
+					cli.printWarning("  %s", typeErr)
+				}
+			}
+			return err
+		}
+		return nil
+	}
+
 	// Apply the fix
 	modifiedCode, err := cli.applySuggestionToCode(originalCode, suggestion)
 	if err != nil {
@@ -0,0 +1,103 @@
+// pipeline_test.go - End-to-end tests for type-checked validation and rollback
+package auto_fix
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeProject creates the sample module with a second package importing it
+func writeProject(t *testing.T) (string, string) {
+	t.Helper()
+	path := writeModule(t)
+	root := filepath.Dir(path)
+	dir := filepath.Join(root, "client")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := "package client\n\nimport \"example.com/sample\"\n\n// Value reads sample.Get\nfunc Value() int { return sample.Get() }\n"
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), []byte(client), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root, path
+}
+
+func TestIntegration_ValidateThenApply(t *testing.T) {
+	ctx := context.Background()
+	_, path := writeProject(t)
+
+	suggestions, err := NewSuggestionEngine(EngineConfig{}).AnalyzeCode(ctx, path)
+	if err != nil {
+		t.Fatalf("AnalyzeCode() error = %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("AnalyzeCode() returned %d suggestions, want 1", len(suggestions))
+	}
+
+	// Validation checks the fix in place and leaves the file untouched
+	validation := NewValidationSystem(SandboxConfig{EnableTests: true, Timeout: 2 * time.Minute})
+	result, err := validation.ValidateProposedFix(suggestions[0], "")
+	if err != nil {
+		t.Fatalf("ValidateProposedFix() error = %v", err)
+	}
+	if !result.IsValid || !result.CompilationOK || !result.TestsPassing {
+		t.Fatalf("ValidateProposedFix() = %+v, want a valid fix", result)
+	}
+	if readString(t, path) != sampleSource {
+		t.Fatal("validation modified the file")
+	}
+
+	report, err := NewSafeApplier(SafeApplyConfig{RunTests: true}).Apply(ctx, suggestions[0])
+	if err != nil {
+		t.Fatalf("Apply() error = %v (report %+v)", err, report)
+	}
+	if len(report.Dependents) != 1 || report.Dependents[0] != "example.com/sample/client" {
+		t.Errorf("Dependents = %v, want example.com/sample/client", report.Dependents)
+	}
+	if strings.Contains(readString(t, path), "unused") {
+		t.Error("fix was not applied")
+	}
+}
+
+func TestIntegration_ValidationRejectsBrokenDependents(t *testing.T) {
+	_, path := writeProject(t)
+	offset := strings.Index(sampleSource, "func Get()") + len("func ")
+	fix := &FixSuggestion{
+		ID:       "rename-get",
+		FilePath: path,
+		Edits:    []TextEdit{{Offset: offset, End: offset + len("Get"), NewText: "Fetch"}},
+	}
+
+	result, err := NewValidationSystem(SandboxConfig{}).ValidateProposedFix(fix, "")
+	if err != nil {
+		t.Fatalf("ValidateProposedFix() error = %v", err)
+	}
+	if result.IsValid || result.CompilationOK {
+		t.Errorf("ValidateProposedFix() = %+v, want a compilation failure", result)
+	}
+	if readString(t, path) != sampleSource {
+		t.Error("file was not restored")
+	}
+}
+
+func TestIntegration_ReviewSession(t *testing.T) {
+	root, path := writeProject(t)
+	engine := NewSuggestionEngine(EngineConfig{})
+	validation := NewValidationSystem(SandboxConfig{EnableTests: true, Timeout: 2 * time.Minute})
+	cli := NewCLIInterface(engine, validation, CLIConfig{AutoApplyThreshold: 0.7, OutputFormat: "text"})
+
+	session, err := cli.StartReviewSession(context.Background(), root)
+	if err != nil {
+		t.Fatalf("StartReviewSession() error = %v", err)
+	}
+	if session.SuggestionsTotal != 1 || session.SuggestionsApplied != 1 {
+		t.Errorf("session = %+v, want one suggestion applied", session)
+	}
+	if strings.Contains(readString(t, path), "unused") {
+		t.Error("fix was not applied")
+	}
+}
@@ -0,0 +1,521 @@
+// Refactorings sûrs - éditions AST re-typecheckées avec rollback
+// Plan de développement v42 - Gestionnaire d'erreurs avancé
+package auto_fix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// TextEdit remplace les octets [Offset, End) d'un fichier par NewText. C'est
+// l'équivalent sérialisable d'un analysis.TextEdit, dont les token.Pos n'ont
+// de sens qu'avec leur FileSet. Un File vide désigne le fichier de la suggestion.
+type TextEdit struct {
+	File    string `json:"file,omitempty"`
+	Offset  int    `json:"offset"`
+	End     int    `json:"end"`
+	NewText string `json:"new_text"`
+}
+
+// ErrFixRolledBack est retournée quand une correction a été annulée parce que
+// le package ne compile plus ou que ses tests échouent
+var ErrFixRolledBack = errors.New("fix rolled back")
+
+// typeCheckMode charge les packages avec leurs dépendances depuis les sources
+const typeCheckMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo
+
+// EditsFromSuggestedFix convertit un analysis.SuggestedFix en éditions
+func EditsFromSuggestedFix(fset *token.FileSet, fix analysis.SuggestedFix) ([]TextEdit, error) {
+	edits := make([]TextEdit, 0, len(fix.TextEdits))
+	for _, edit := range fix.TextEdits {
+		end := edit.End
+		if !end.IsValid() {
+			end = edit.Pos
+		}
+		file := fset.File(edit.Pos)
+		if file == nil || fset.File(end) != file {
+			return nil, fmt.Errorf("edit of %q is outside the file set or spans several files", fix.Message)
+		}
+		edits = append(edits, TextEdit{
+			File:    file.Name(),
+			Offset:  file.Offset(edit.Pos),
+			End:     file.Offset(end),
+			NewText: string(edit.NewText),
+		})
+	}
+	return edits, nil
+}
+
+// ReplaceNode produit l'édition qui remplace old par le rendu go/format de
+// replacement ; un replacement nil supprime le nœud
+func ReplaceNode(fset *token.FileSet, old, replacement ast.Node) (TextEdit, error) {
+	file := fset.File(old.Pos())
+	if file == nil {
+		return TextEdit{}, errors.New("node is not part of the file set")
+	}
+
+	var buf bytes.Buffer
+	if replacement != nil {
+		if err := format.Node(&buf, fset, replacement); err != nil {
+			return TextEdit{}, fmt.Errorf("failed to print replacement: %w", err)
+		}
+	}
+
+	return TextEdit{
+		File:    file.Name(),
+		Offset:  file.Offset(old.Pos()),
+		End:     file.Offset(old.End()),
+		NewText: buf.String(),
+	}, nil
+}
+
+// ApplyEdits applique les éditions d'un même fichier puis reformate le
+// résultat : des éditions qui se chevauchent ou cassent la syntaxe sont refusées
+func ApplyEdits(src []byte, edits []TextEdit) ([]byte, error) {
+	sorted := append([]TextEdit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range sorted {
+		if edit.Offset < last || edit.End < edit.Offset || edit.End > len(src) {
+			return nil, fmt.Errorf("invalid or overlapping edit [%d,%d)", edit.Offset, edit.End)
+		}
+		out.Write(src[last:edit.Offset])
+		out.WriteString(edit.NewText)
+		last = edit.End
+	}
+	out.Write(src[last:])
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("edits produce invalid Go code: %w", err)
+	}
+	return formatted, nil
+}
+
+// editsByFile regroupe les éditions d'une suggestion par fichier
+func (fs *FixSuggestion) editsByFile() map[string][]TextEdit {
+	target := fs.FilePath
+	if target == "" {
+		target = fs.File
+	}
+
+	byFile := make(map[string][]TextEdit)
+	for _, edit := range fs.Edits {
+		file := edit.File
+		if file == "" {
+			file = target
+		}
+		byFile[file] = append(byFile[file], edit)
+	}
+	return byFile
+}
+
+// SafeApplyConfig configure l'application sûre des corrections
+type SafeApplyConfig struct {
+	RunTests    bool          `json:"run_tests"`
+	TestTimeout time.Duration `json:"test_timeout"`
+	BuildFlags  []string      `json:"build_flags"`
+}
+
+// ApplyReport décrit l'application d'une correction
+type ApplyReport struct {
+	Files            []string `json:"files"`
+	Packages         []string `json:"packages"`             // Packages touchés
+	Dependents       []string `json:"dependents,omitempty"` // Packages du module qui en dépendent
+	TypeErrors       []string `json:"type_errors,omitempty"`
+	BaselineFailures []string `json:"baseline_failures,omitempty"` // Tests déjà en échec avant la correction
+	TestFailures     []string `json:"test_failures,omitempty"`     // Tests mis en échec par la correction
+	TestOutput       string   `json:"test_output,omitempty"`
+	RolledBack       bool     `json:"rolled_back"`
+	Reason           string   `json:"reason,omitempty"`
+}
+
+// SafeApplier applique les éditions d'une suggestion directement dans
+// l'arbre, re-typecheck les packages touchés et ceux du module qui en
+// dépendent avec go/packages, et restaure les fichiers d'origine si la
+// correction casse leur compilation ou leurs tests
+type SafeApplier struct {
+	config SafeApplyConfig
+	mutex  sync.Mutex // une seule correction à la fois sur l'arbre
+}
+
+// NewSafeApplier crée un SafeApplier
+func NewSafeApplier(config SafeApplyConfig) *SafeApplier {
+	if config.TestTimeout == 0 {
+		config.TestTimeout = 5 * time.Minute
+	}
+	return &SafeApplier{config: config}
+}
+
+// Apply applique la suggestion et la conserve si les packages touchés et
+// leurs dépendants compilent toujours et si leurs tests passent. Seules les
+// erreurs et les échecs absents avant la correction comptent : les tests sont
+// exécutés une première fois pour relever ceux qui échouent déjà. Sinon les
+// fichiers sont restaurés et l'erreur retournée enveloppe ErrFixRolledBack.
+func (sa *SafeApplier) Apply(ctx context.Context, fix *FixSuggestion) (*ApplyReport, error) {
+	return sa.apply(ctx, fix, false)
+}
+
+// Check effectue les mêmes vérifications qu'Apply puis restaure toujours les
+// fichiers d'origine
+func (sa *SafeApplier) Check(ctx context.Context, fix *FixSuggestion) (*ApplyReport, error) {
+	return sa.apply(ctx, fix, true)
+}
+
+func (sa *SafeApplier) apply(ctx context.Context, fix *FixSuggestion, dryRun bool) (*ApplyReport, error) {
+	if len(fix.Edits) == 0 {
+		return nil, fmt.Errorf("suggestion %s has no edits", fix.ID)
+	}
+
+	sa.mutex.Lock()
+	defer sa.mutex.Unlock()
+
+	// Calculer tous les nouveaux contenus avant de toucher au disque
+	byFile := fix.editsByFile()
+	report := &ApplyReport{}
+	originals := make(map[string][]byte, len(byFile))
+	updated := make(map[string][]byte, len(byFile))
+	dirSet := make(map[string]bool)
+	for file, edits := range byFile {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		content, err := ApplyEdits(src, edits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply edits to %s: %w", file, err)
+		}
+		originals[file] = src
+		updated[file] = content
+		report.Files = append(report.Files, file)
+		dirSet[filepath.Dir(file)] = true
+	}
+	sort.Strings(report.Files)
+
+	dirs := make([]string, 0, len(dirSet))
+	for dir := range dirSet {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	scope, err := sa.scope(dirs)
+	if err != nil {
+		return nil, err
+	}
+	report.Packages = scope.touched
+	report.Dependents = scope.dependents
+
+	before, err := sa.typeErrors(scope)
+	if err != nil {
+		return nil, err
+	}
+	var baseline map[string]bool
+	if sa.config.RunTests {
+		if baseline, _, err = sa.runTests(ctx, scope); err != nil {
+			return nil, fmt.Errorf("failed to run tests before the fix: %w", err)
+		}
+		report.BaselineFailures = sortedKeys(baseline)
+	}
+
+	for _, file := range report.Files {
+		if err := writeKeepingMode(file, updated[file]); err != nil {
+			return sa.rollback(report, originals, fmt.Sprintf("failed to write %s: %v", file, err))
+		}
+	}
+
+	after, err := sa.typeErrors(scope)
+	if err != nil {
+		return sa.rollback(report, originals, err.Error())
+	}
+	if introduced := newTypeErrors(before, after); len(introduced) > 0 {
+		report.TypeErrors = introduced
+		return sa.rollback(report, originals, "package no longer compiles")
+	}
+
+	if sa.config.RunTests {
+		failures, output, err := sa.runTests(ctx, scope)
+		report.TestOutput = output
+		if err != nil {
+			return sa.rollback(report, originals, fmt.Sprintf("tests could not run: %v", err))
+		}
+		for _, failure := range sortedKeys(failures) {
+			if !baseline[failure] {
+				report.TestFailures = append(report.TestFailures, failure)
+			}
+		}
+		if len(report.TestFailures) > 0 {
+			return sa.rollback(report, originals, fmt.Sprintf("tests failed: %s", strings.Join(report.TestFailures, ", ")))
+		}
+	}
+
+	if dryRun {
+		if err := restoreFiles(originals); err != nil {
+			return report, fmt.Errorf("failed to restore files after check: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// rollback restaure les fichiers d'origine et explique pourquoi
+func (sa *SafeApplier) rollback(report *ApplyReport, originals map[string][]byte, reason string) (*ApplyReport, error) {
+	if err := restoreFiles(originals); err != nil {
+		return report, fmt.Errorf("%s, and rollback failed: %w", reason, err)
+	}
+	report.RolledBack = true
+	report.Reason = reason
+	return report, fmt.Errorf("%w: %s", ErrFixRolledBack, reason)
+}
+
+// typeError est une erreur de compilation d'un package
+type typeError struct {
+	pos string
+	msg string
+}
+
+// checkScope regroupe les packages à vérifier après une correction
+type checkScope struct {
+	moduleDir  string   // Répertoire depuis lequel les packages sont chargés
+	touched    []string // Packages des fichiers édités
+	dependents []string // Packages du module qui importent, même indirectement, un package touché
+}
+
+// packages retourne tous les packages à vérifier
+func (cs checkScope) packages() []string {
+	return append(append([]string(nil), cs.touched...), cs.dependents...)
+}
+
+// scope identifie les packages des répertoires et leurs dépendants dans le
+// module : une correction qui change une API peut casser ses importeurs
+func (sa *SafeApplier) scope(dirs []string) (checkScope, error) {
+	var scope checkScope
+	touched := make(map[string]bool)
+	for _, dir := range dirs {
+		pkgs, err := packages.Load(&packages.Config{
+			Mode:       packages.NeedName | packages.NeedModule,
+			Dir:        dir,
+			BuildFlags: sa.config.BuildFlags,
+		}, ".")
+		if err != nil {
+			return scope, fmt.Errorf("failed to load packages in %s: %w", dir, err)
+		}
+		for _, pkg := range pkgs {
+			moduleDir := dir
+			if pkg.Module != nil && pkg.Module.Dir != "" {
+				moduleDir = pkg.Module.Dir
+			}
+			if scope.moduleDir != "" && scope.moduleDir != moduleDir {
+				return scope, fmt.Errorf("edits span several modules (%s, %s)", scope.moduleDir, moduleDir)
+			}
+			scope.moduleDir = moduleDir
+			if !touched[pkg.PkgPath] {
+				touched[pkg.PkgPath] = true
+				scope.touched = append(scope.touched, pkg.PkgPath)
+			}
+		}
+	}
+	sort.Strings(scope.touched)
+
+	// Graphe inverse des imports du module, tests compris
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       packages.NeedName | packages.NeedImports,
+		Dir:        scope.moduleDir,
+		Tests:      true,
+		BuildFlags: sa.config.BuildFlags,
+	}, "./...")
+	if err != nil {
+		return scope, fmt.Errorf("failed to load packages of %s: %w", scope.moduleDir, err)
+	}
+	importers := make(map[string][]string)
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue // Binaire de test généré
+		}
+		// Les tests externes (package p_test) sont exécutés avec p
+		importer := strings.TrimSuffix(pkg.PkgPath, "_test")
+		for path := range pkg.Imports {
+			if path != importer {
+				importers[path] = append(importers[path], importer)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	queue := append([]string(nil), scope.touched...)
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[path] {
+			if touched[importer] || seen[importer] {
+				continue
+			}
+			seen[importer] = true
+			scope.dependents = append(scope.dependents, importer)
+			queue = append(queue, importer)
+		}
+	}
+	sort.Strings(scope.dependents)
+	return scope, nil
+}
+
+// typeErrors type-checke les packages du périmètre, tests compris
+func (sa *SafeApplier) typeErrors(scope checkScope) ([]typeError, error) {
+	cfg := &packages.Config{
+		Mode:       typeCheckMode,
+		Dir:        scope.moduleDir,
+		Tests:      true,
+		BuildFlags: sa.config.BuildFlags,
+	}
+	pkgs, err := packages.Load(cfg, scope.packages()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages in %s: %w", scope.moduleDir, err)
+	}
+
+	var result []typeError
+	seen := make(map[typeError]bool)
+	for _, pkg := range pkgs {
+		// Les variantes de test répètent les erreurs du package
+		for _, pkgErr := range pkg.Errors {
+			entry := typeError{pos: pkgErr.Pos, msg: pkgErr.Msg}
+			if !seen[entry] {
+				seen[entry] = true
+				result = append(result, entry)
+			}
+		}
+	}
+	return result, nil
+}
+
+// newTypeErrors retourne les erreurs de after absentes de before. Les
+// positions bougent avec les éditions : seuls les messages sont comparés.
+func newTypeErrors(before, after []typeError) []string {
+	known := make(map[string]int, len(before))
+	for _, e := range before {
+		known[e.msg]++
+	}
+
+	var introduced []string
+	for _, e := range after {
+		if known[e.msg] > 0 {
+			known[e.msg]--
+			continue
+		}
+		introduced = append(introduced, fmt.Sprintf("%s: %s", e.pos, e.msg))
+	}
+	return introduced
+}
+
+// testEvent est une ligne de la sortie de go test -json
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// runTests exécute les tests du périmètre et retourne les tests en échec
+// ("package.TestName", ou le package seul s'il ne compile pas ou échoue hors
+// d'un test). L'erreur ne signale qu'une exécution impossible.
+func (sa *SafeApplier) runTests(ctx context.Context, scope checkScope) (map[string]bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, sa.config.TestTimeout)
+	defer cancel()
+
+	args := append([]string{"test", "-json"}, sa.config.BuildFlags...)
+	cmd := exec.CommandContext(ctx, "go", append(args, scope.packages()...)...)
+	cmd.Dir = scope.moduleDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, runErr := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, string(stdout) + stderr.String(), ctx.Err()
+	}
+
+	failures := make(map[string]bool)
+	failedTests := make(map[string]bool) // Packages ayant au moins un test en échec
+	var failedPackages []string
+	var output strings.Builder
+	events := 0
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			output.WriteString(scanner.Text() + "\n")
+			continue
+		}
+		events++
+		switch {
+		case event.Action == "output":
+			output.WriteString(event.Output)
+		case event.Action == "fail" && event.Test != "":
+			failures[event.Package+"."+event.Test] = true
+			failedTests[event.Package] = true
+		case event.Action == "fail":
+			failedPackages = append(failedPackages, event.Package)
+		}
+	}
+	output.WriteString(stderr.String())
+	if runErr != nil && events == 0 {
+		return nil, output.String(), fmt.Errorf("go test: %w", runErr)
+	}
+
+	for _, pkg := range failedPackages {
+		if !failedTests[pkg] {
+			failures[pkg] = true
+		}
+	}
+	return failures, output.String(), nil
+}
+
+// sortedKeys retourne les clés d'un ensemble, triées
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeKeepingMode réécrit un fichier existant en conservant ses permissions
+func writeKeepingMode(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(path, content, mode)
+}
+
+// restoreFiles réécrit les contenus d'origine
+func restoreFiles(originals map[string][]byte) error {
+	var failed []string
+	for file, content := range originals {
+		if err := writeKeepingMode(file, content); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", file, err))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package auto_fix
+
+import (
+	"context"
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const sampleSource = `package sample
+
+// unused n'est jamais lu
+var unused = 42
+
+var used = 1
+
+// Get retourne used
+func Get() int { return used }
+`
+
+const sampleTest = `package sample
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	if Get() != 1 {
+		t.Fatal("Get() != 1")
+	}
+}
+`
+
+// writeModule crée un module minimal et retourne le chemin de sample.go
+func writeModule(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/sample\n\ngo 1.21\n",
+		"sample.go":      sampleSource,
+		"sample_test.go": sampleTest,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "sample.go")
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestAnalyzeCodeAndSafeApply(t *testing.T) {
+	path := writeModule(t)
+	engine := NewSuggestionEngine(EngineConfig{})
+
+	suggestions, err := engine.AnalyzeCode(context.Background(), path)
+	if err != nil {
+		t.Fatalf("AnalyzeCode() error = %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].OriginalCode != "unused" || len(suggestions[0].Edits) != 1 {
+		t.Fatalf("AnalyzeCode() = %+v, want one edit-based fix for 'unused'", suggestions)
+	}
+
+	report, err := NewSafeApplier(SafeApplyConfig{RunTests: true}).Apply(context.Background(), suggestions[0])
+	if err != nil {
+		t.Fatalf("Apply() error = %v (report %+v)", err, report)
+	}
+	got := readString(t, path)
+	if strings.Contains(got, "unused") || !strings.Contains(got, "var used = 1") {
+		t.Errorf("unexpected file after fix:\n%s", got)
+	}
+}
+
+func TestSafeApplyRollsBackOnTypeError(t *testing.T) {
+	path := writeModule(t)
+	offset := strings.Index(sampleSource, "return used")
+	fix := &FixSuggestion{
+		ID:       "break-build",
+		FilePath: path,
+		Edits:    []TextEdit{{Offset: offset, End: offset + len("return used"), NewText: "return missing"}},
+	}
+
+	report, err := NewSafeApplier(SafeApplyConfig{}).Apply(context.Background(), fix)
+	if !errors.Is(err, ErrFixRolledBack) {
+		t.Fatalf("Apply() error = %v, want ErrFixRolledBack", err)
+	}
+	if !report.RolledBack || len(report.TypeErrors) != 1 || !strings.Contains(report.TypeErrors[0], "missing") {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if readString(t, path) != sampleSource {
+		t.Error("file was not restored")
+	}
+}
+
+func TestSafeApplyRollsBackOnTestFailure(t *testing.T) {
+	path := writeModule(t)
+
+	// L'édition passe par un analysis.SuggestedFix
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := fset.File(file.Pos())
+	offset := strings.Index(sampleSource, "used = 1") + len("used = ")
+	edits, err := EditsFromSuggestedFix(fset, analysis.SuggestedFix{
+		Message:   "change the value",
+		TextEdits: []analysis.TextEdit{{Pos: tokenFile.Pos(offset), End: tokenFile.Pos(offset + 1), NewText: []byte("2")}},
+	})
+	if err != nil {
+		t.Fatalf("EditsFromSuggestedFix() error = %v", err)
+	}
+
+	fix := &FixSuggestion{ID: "break-tests", FilePath: path, Edits: edits}
+	report, err := NewSafeApplier(SafeApplyConfig{RunTests: true}).Apply(context.Background(), fix)
+	if !errors.Is(err, ErrFixRolledBack) || len(report.TypeErrors) != 0 || !strings.Contains(report.TestOutput, "Get() != 1") {
+		t.Fatalf("Apply() error = %v, report %+v", err, report)
+	}
+	if readString(t, path) != sampleSource {
+		t.Error("file was not restored")
+	}
+}
+
+func TestSafeApplyIgnoresTestsFailingBeforeTheFix(t *testing.T) {
+	path := writeModule(t)
+	broken := "\nfunc TestAlreadyBroken(t *testing.T) { t.Fatal(\"broken before the fix\") }\n"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "broken_test.go"), []byte("package sample\n\nimport \"testing\"\n"+broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	offset := strings.Index(sampleSource, "var unused = 42")
+	fix := &FixSuggestion{
+		ID:       "remove-unused",
+		FilePath: path,
+		Edits:    []TextEdit{{Offset: offset, End: offset + len("var unused = 42")}},
+	}
+	report, err := NewSafeApplier(SafeApplyConfig{RunTests: true}).Apply(context.Background(), fix)
+	if err != nil {
+		t.Fatalf("Apply() error = %v (report %+v)", err, report)
+	}
+	if len(report.BaselineFailures) != 1 || report.BaselineFailures[0] != "example.com/sample.TestAlreadyBroken" {
+		t.Errorf("BaselineFailures = %v, want the test broken before the fix", report.BaselineFailures)
+	}
+	if strings.Contains(readString(t, path), "unused = 42") {
+		t.Error("fix was not kept")
+	}
+}
+
+func TestSafeApplyChecksDependents(t *testing.T) {
+	path := writeModule(t)
+	dir := filepath.Join(filepath.Dir(path), "client")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := "package client\n\nimport \"example.com/sample\"\n\n// Value lit sample.Get\nfunc Value() int { return sample.Get() }\n"
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), []byte(client), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Renommer Get compile dans sample mais casse son importeur
+	offset := strings.Index(sampleSource, "func Get()") + len("func ")
+	fix := &FixSuggestion{
+		ID:       "rename-get",
+		FilePath: path,
+		Edits:    []TextEdit{{Offset: offset, End: offset + len("Get"), NewText: "Fetch"}},
+	}
+	report, err := NewSafeApplier(SafeApplyConfig{}).Check(context.Background(), fix)
+	if !errors.Is(err, ErrFixRolledBack) {
+		t.Fatalf("Check() error = %v, want ErrFixRolledBack", err)
+	}
+	if len(report.Dependents) != 1 || report.Dependents[0] != "example.com/sample/client" {
+		t.Errorf("Dependents = %v, want example.com/sample/client", report.Dependents)
+	}
+	found := false
+	for _, typeErr := range report.TypeErrors {
+		found = found || strings.Contains(typeErr, "client.go")
+	}
+	if !found {
+		t.Errorf("TypeErrors = %v, want an error in client.go", report.TypeErrors)
+	}
+	if readString(t, path) != sampleSource {
+		t.Error("file was not restored")
+	}
+}
+
+func TestApplyEditsRejectsOverlaps(t *testing.T) {
+	src := []byte("package p\n\nvar a = 1\n")
+	_, err := ApplyEdits(src, []TextEdit{{Offset: 11, End: 20}, {Offset: 15, End: 16, NewText: "b"}})
+	if err == nil {
+		t.Error("expected an error for overlapping edits")
+	}
+	if _, err := ApplyEdits(src, []TextEdit{{Offset: 11, End: 14, NewText: "func"}}); err == nil {
+		t.Error("expected an error for edits producing invalid code")
+	}
+}
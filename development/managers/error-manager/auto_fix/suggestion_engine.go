@@ -9,6 +9,7 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
 )
 
 // FixSuggestion représente une suggestion de correction
@@ -33,6 +35,7 @@ type FixSuggestion struct {
 	ProposedCode   string                 `json:"proposed_code"`
 	Patterns       []string               `json:"patterns"`
 	Replacements   []string               `json:"replacements"`
+	Edits          []TextEdit             `json:"edits,omitempty"`
 	Confidence     float64                `json:"confidence"`
 	SafetyLevel    SafetyLevel            `json:"safety_level"`
 	Impact         ImpactLevel            `json:"impact"`
@@ -245,7 +248,8 @@ func (se *SuggestionEngine) setupDefaultTemplates() {
 			ID:          "error_check_missing",
 			Name:        "Add error check",
 			Description: "Add missing error check after function call",
-			PatternStr:  `(\w+)\s*,\s*err\s*:=\s*(.+)\s*\n(?!\s*if\s+err)`,
+			// RE2 n'a pas de lookahead : l'absence de "if err" n'est pas vérifiée ici
+			PatternStr:  `(\w+)\s*,\s*err\s*:=\s*(.+)\s*\n`,
 			Replacement: "$1, err := $2\nif err != nil {\n\treturn err\n}",
 			Category:    CategoryBugFix,
 			Confidence:  0.7,
@@ -814,70 +818,161 @@ func (f *NamingConventionFixer) GetConfidence() float64 {
 	return 0.5
 }
 
-// AnalyzeCode analyses le code pour identifier les problèmes
+// AnalyzeCode analyse un fichier avec les informations de types de son
+// package : une variable de package non exportée qui n'est jamais utilisée
+// donne une suggestion dont les éditions suppriment sa déclaration
 func (se *SuggestionEngine) AnalyzeCode(ctx context.Context, filePath string) ([]*FixSuggestion, error) {
-	// Parse the file
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	fset, file, info, err := loadTypedFile(ctx, filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to load file %s: %w", filePath, err)
+	}
+
+	used := make(map[types.Object]bool, len(info.Uses))
+	for _, obj := range info.Uses {
+		used[obj] = true
 	}
 
 	var suggestions []*FixSuggestion
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for _, name := range valueSpec.Names {
+				obj := info.Defs[name]
+				if name.Name == "_" || name.IsExported() || obj == nil || used[obj] {
+					continue
+				}
+				line := fset.Position(name.Pos()).Line
+				suggestion := &FixSuggestion{
+					ID:             fmt.Sprintf("unused_var_%s_%d", name.Name, line),
+					Type:           FixTypeRemoveUnused,
+					Description:    fmt.Sprintf("Remove unused variable '%s'", name.Name),
+					File:           filePath,
+					FilePath:       filePath,
+					StartPos:       name.Pos(),
+					EndPos:         name.End(),
+					LineNumbers:    []int{line},
+					OriginalCode:   name.Name,
+					ProposedCode:   "",
+					Patterns:       []string{fmt.Sprintf("var %s", name.Name)},
+					Replacements:   []string{""},
+					Confidence:     0.9,
+					SafetyLevel:    SafetyLevelHigh,
+					Impact:         ImpactLow,
+					Category:       CategoryCodeQuality,
+					AutoApplicable: true,
+					RequiresReview: false,
+					Dependencies:   []string{},
+					Metadata:       map[string]interface{}{"rule": "unused_variable"},
+					CreatedAt:      time.Now(),
+				}
 
-	// Basic static analysis - find unused variables
-	ast.Inspect(file, func(n ast.Node) bool {
-		switch node := n.(type) {
-		case *ast.GenDecl:
-			if node.Tok == token.VAR {
-				for _, spec := range node.Specs {
-					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
-						for _, name := range valueSpec.Names {
-							if name.Name != "_" && !isUsed(file, name.Name) {
-								suggestions = append(suggestions, &FixSuggestion{
-									ID:             fmt.Sprintf("unused_var_%s_%d", name.Name, fset.Position(name.Pos()).Line),
-									Type:           FixTypeRemoveUnused,
-									Description:    fmt.Sprintf("Remove unused variable '%s'", name.Name),
-									File:           filePath,
-									FilePath:       filePath,
-									StartPos:       name.Pos(),
-									EndPos:         name.End(),
-									LineNumbers:    []int{fset.Position(name.Pos()).Line},
-									OriginalCode:   name.Name,
-									ProposedCode:   "",
-									Patterns:       []string{fmt.Sprintf("var %s", name.Name)},
-									Replacements:   []string{""},
-									Confidence:     0.9,
-									SafetyLevel:    SafetyLevelHigh,
-									Impact:         ImpactLow,
-									Category:       CategoryCodeQuality,
-									AutoApplicable: true,
-									RequiresReview: false,
-									Dependencies:   []string{},
-									Metadata:       map[string]interface{}{"rule": "unused_variable"},
-									CreatedAt:      time.Now(),
-								})
-							}
-						}
+				// Une déclaration multiple ou une valeur avec effets de bord
+				// demande une révision manuelle
+				if len(valueSpec.Names) > 1 {
+					suggestion.AutoApplicable = false
+					suggestion.RequiresReview = true
+					suggestion.SafetyLevel = SafetyLevelLow
+				} else {
+					suggestion.Edits = []TextEdit{removeVarSpecEdit(fset, genDecl, valueSpec)}
+					if !sideEffectFree(valueSpec.Values, info) {
+						suggestion.AutoApplicable = false
+						suggestion.RequiresReview = true
+						suggestion.SafetyLevel = SafetyLevelMedium
 					}
 				}
+				suggestions = append(suggestions, suggestion)
 			}
 		}
-		return true
-	})
+	}
 
 	return suggestions, nil
 }
 
-// isUsed checks if a variable is used in the AST (simplified implementation)
-func isUsed(file *ast.File, varName string) bool {
-	used := false
-	ast.Inspect(file, func(n ast.Node) bool {
-		if ident, ok := n.(*ast.Ident); ok && ident.Name == varName {
-			used = true
-			return false // Stop inspection
+// loadTypedFile charge le package de filePath (tests compris, pour compter
+// leurs utilisations) et retourne la syntaxe et les types du fichier
+func loadTypedFile(ctx context.Context, filePath string) (*token.FileSet, *ast.File, *types.Info, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    typeCheckMode,
+		Dir:     filepath.Dir(absPath),
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(cfg, "file="+absPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// La variante de test contient le plus de fichiers, donc le plus d'utilisations
+	var best *packages.Package
+	var bestFile *ast.File
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if pkg.Fset.File(file.Pos()).Name() == absPath && pkg.TypesInfo != nil &&
+				(best == nil || len(pkg.Syntax) > len(best.Syntax)) {
+				best, bestFile = pkg, file
+			}
 		}
-		return true
-	})
-	return used
+	}
+	if best == nil {
+		return nil, nil, nil, fmt.Errorf("no type-checked package contains %s", filePath)
+	}
+	return best.Fset, bestFile, best.TypesInfo, nil
+}
+
+// removeVarSpecEdit supprime une spécification de variable, ou toute la
+// déclaration quand elle n'en contient qu'une, commentaire de doc compris
+func removeVarSpecEdit(fset *token.FileSet, genDecl *ast.GenDecl, spec *ast.ValueSpec) TextEdit {
+	var node ast.Node = spec
+	doc := spec.Doc
+	if len(genDecl.Specs) == 1 {
+		node, doc = genDecl, genDecl.Doc
+	}
+
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	file := fset.File(start)
+	return TextEdit{
+		File:   file.Name(),
+		Offset: file.Offset(start),
+		End:    file.Offset(node.End()),
+	}
+}
+
+// sideEffectFree indique si l'évaluation des valeurs peut être supprimée
+// sans changer le comportement du programme
+func sideEffectFree(values []ast.Expr, info *types.Info) bool {
+	for _, value := range values {
+		pure := true
+		ast.Inspect(value, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncLit:
+				return false // le corps n'est pas exécuté à la déclaration
+			case *ast.CallExpr:
+				// Les conversions de type n'ont pas d'effet de bord
+				if tv, ok := info.Types[node.Fun]; !ok || !tv.IsType() {
+					pure = false
+				}
+			case *ast.UnaryExpr:
+				if node.Op == token.ARROW {
+					pure = false
+				}
+			}
+			return pure
+		})
+		if !pure {
+			return false
+		}
+	}
+	return true
 }
@@ -108,6 +108,11 @@ func (vs *ValidationSystem) ValidateProposedFix(fix *FixSuggestion, originalCode
 		Metrics:         ValidationMetrics{},
 	}
 
+	// Edit-based fixes are checked in place, against their real package
+	if len(fix.Edits) > 0 {
+		return vs.validateEdits(fix, result, startTime)
+	}
+
 	// Create sandbox environment
 	sandboxDir, err := vs.createSandbox(fix, originalCode)
 	if err != nil {
@@ -116,21 +121,27 @@ func (vs *ValidationSystem) ValidateProposedFix(fix *FixSuggestion, originalCode
 	defer vs.cleanupSandbox(sandboxDir)
 
 	// Execute validation steps
+	var syntaxOK bool
 	validationSteps := []struct {
-		name string
-		fn   func(string, *FixSuggestion, string) error
+		name   string
+		fn     func(string, *FixSuggestion, string) error
+		passed *bool
 	}{
-		{"syntax_check", vs.validateSyntax},
-		{"compilation_check", vs.validateCompilation},
-		{"static_analysis", vs.validateStaticAnalysis},
-		{"test_execution", vs.validateTests},
-		{"performance_impact", vs.validatePerformance},
+		{"syntax_check", vs.validateSyntax, &syntaxOK},
+		{"compilation_check", vs.validateCompilation, &result.CompilationOK},
+		{"static_analysis", vs.validateStaticAnalysis, &result.StaticCheckOK},
+		{"test_execution", vs.validateTests, &result.TestsPassing},
+		{"performance_impact", vs.validatePerformance, nil},
 	}
 
 	for _, step := range validationSteps {
-		if err := step.fn(sandboxDir, fix, originalCode); err != nil {
+		err := step.fn(sandboxDir, fix, originalCode)
+		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("%s failed: %v", step.name, err))
 		}
+		if step.passed != nil {
+			*step.passed = err == nil
+		}
 	}
 
 	// Calculate confidence score
@@ -142,6 +153,37 @@ func (vs *ValidationSystem) ValidateProposedFix(fix *FixSuggestion, originalCode
 	return result, nil
 }
 
+// validateEdits applies the fix's edits in the source tree, type-checks the
+// touched packages and runs their tests, then restores the original files
+func (vs *ValidationSystem) validateEdits(fix *FixSuggestion, result *ValidationResult, startTime time.Time) (*ValidationResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vs.config.Timeout)
+	defer cancel()
+
+	applier := NewSafeApplier(SafeApplyConfig{RunTests: vs.config.EnableTests, TestTimeout: vs.config.Timeout})
+	report, err := applier.Check(ctx, fix)
+	if report == nil {
+		return result, fmt.Errorf("failed to check edits: %w", err)
+	}
+
+	result.Metrics.FilesAffected = len(report.Files)
+	result.CompilationOK = len(report.TypeErrors) == 0
+	result.StaticCheckOK = result.CompilationOK
+	result.TestsPassing = err == nil
+	for _, typeErr := range report.TypeErrors {
+		result.Errors = append(result.Errors, fmt.Sprintf("compilation_check failed: %s", typeErr))
+	}
+	if err != nil && len(report.TypeErrors) == 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("test_execution failed: %v", err))
+	}
+
+	result.ConfidenceScore = vs.calculateConfidenceScore(result)
+	result.SafetyLevel = vs.determineSafetyLevel(result)
+	result.IsValid = result.ConfidenceScore >= 0.7 && len(result.Errors) == 0
+	result.Duration = time.Since(startTime)
+
+	return result, nil
+}
+
 // createSandbox creates a temporary environment for testing the fix
 func (vs *ValidationSystem) createSandbox(fix *FixSuggestion, originalCode string) (string, error) {
 	// Create temporary directory
@@ -165,7 +207,7 @@ func (vs *ValidationSystem) createSandbox(fix *FixSuggestion, originalCode strin
 	}
 
 	// Copy test files if they exist
-	if err := vs.copyTestFiles(sandboxDir); err != nil {
+	if err := vs.copyTestFiles(sandboxDir, fix.FilePath, modifiedCode); err != nil {
 		// Non-fatal, log warning
 		fmt.Printf("Warning: failed to copy test files: %v\n", err)
 	}
@@ -192,6 +234,10 @@ require (
 
 // applyFixToCode applies the suggested fix to the original code
 func (vs *ValidationSystem) applyFixToCode(originalCode string, fix *FixSuggestion) (string, error) {
+	if len(fix.Edits) > 0 {
+		return vs.applyEditsFix(originalCode, fix)
+	}
+
 	switch fix.Category {
 	case "unused_imports":
 		return vs.applyUnusedImportsFix(originalCode, fix)
@@ -207,6 +253,22 @@ func (vs *ValidationSystem) applyFixToCode(originalCode string, fix *FixSuggesti
 	}
 }
 
+// applyEditsFix applies the fix's AST edits that target its own file
+func (vs *ValidationSystem) applyEditsFix(code string, fix *FixSuggestion) (string, error) {
+	var edits []TextEdit
+	for _, edit := range fix.Edits {
+		if edit.File == "" || edit.File == fix.FilePath || edit.File == fix.File {
+			edits = append(edits, edit)
+		}
+	}
+
+	modified, err := ApplyEdits([]byte(code), edits)
+	if err != nil {
+		return "", err
+	}
+	return string(modified), nil
+}
+
 // applyUnusedImportsFix removes unused imports
 func (vs *ValidationSystem) applyUnusedImportsFix(code string, fix *FixSuggestion) (string, error) {
 	fset := token.NewFileSet()
@@ -244,13 +306,16 @@ func (vs *ValidationSystem) applyUnusedVariablesFix(code string, fix *FixSuggest
 
 // applyFormattingFix applies formatting corrections
 func (vs *ValidationSystem) applyFormattingFix(code string, fix *FixSuggestion) (string, error) {
-	// Use gofmt to format the code
+	// Explicit replacements come first, then gofmt
+	code, _ = vs.applyRegexFix(code, fix)
+
 	cmd := exec.Command("gofmt")
 	cmd.Stdin = strings.NewReader(code)
 	
 	output, err := cmd.Output()
 	if err != nil {
-		return code, fmt.Errorf("gofmt failed: %w", err)
+		// Code gofmt cannot parse is reported by the syntax check
+		return code, nil
 	}
 	
 	return string(output), nil
@@ -491,14 +556,17 @@ func (vs *ValidationSystem) hasError(errors []string, errorType string) bool {
 }
 
 // copyTestFiles copies test files to sandbox
-func (vs *ValidationSystem) copyTestFiles(sandboxDir string) error {
-	// Look for test files in current directory
-	currentDir, err := os.Getwd()
+func (vs *ValidationSystem) copyTestFiles(sandboxDir, filePath, code string) error {
+	// Only the tests living next to the fixed file apply to it
+	if filePath == "" {
+		return nil
+	}
+	pkg, err := parser.ParseFile(token.NewFileSet(), "", code, parser.PackageClauseOnly)
 	if err != nil {
 		return err
 	}
 
-	testFiles, err := filepath.Glob(filepath.Join(currentDir, "*_test.go"))
+	testFiles, err := filepath.Glob(filepath.Join(filepath.Dir(filePath), "*_test.go"))
 	if err != nil {
 		return err
 	}
@@ -509,6 +577,12 @@ func (vs *ValidationSystem) copyTestFiles(sandboxDir string) error {
 			continue
 		}
 
+		// Skip external test packages and files of another package
+		header, err := parser.ParseFile(token.NewFileSet(), testFile, content, parser.PackageClauseOnly)
+		if err != nil || header.Name.Name != pkg.Name.Name {
+			continue
+		}
+
 		basename := filepath.Base(testFile)
 		destPath := filepath.Join(sandboxDir, basename)
 		if err := ioutil.WriteFile(destPath, content, 0644); err != nil {
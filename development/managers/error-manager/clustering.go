@@ -0,0 +1,626 @@
+package errormanager
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClusterStatus est l'état d'un cluster d'erreurs d'une analyse à l'autre
+type ClusterStatus string
+
+const (
+	ClusterNew       ClusterStatus = "new"
+	ClusterOngoing   ClusterStatus = "ongoing"
+	ClusterRegressed ClusterStatus = "regressed"
+	ClusterResolved  ClusterStatus = "resolved"
+)
+
+// ClusterConfig paramètre le regroupement des erreurs
+type ClusterConfig struct {
+	StackDepth   int           `json:"stack_depth"`   // frames retenues pour l'empreinte de pile
+	MaxSamples   int           `json:"max_samples"`   // occurrences d'exemple conservées par cluster
+	ResolveAfter time.Duration `json:"resolve_after"` // sans occurrence depuis, un cluster est résolu
+	Window       time.Duration `json:"window"`        // période analysée par les rapports
+	Drain        DrainConfig   `json:"drain"`
+}
+
+// DefaultClusterConfig retourne la configuration par défaut du regroupement
+func DefaultClusterConfig() ClusterConfig {
+	return ClusterConfig{
+		StackDepth:   5,
+		MaxSamples:   3,
+		ResolveAfter: 72 * time.Hour,
+		Window:       7 * 24 * time.Hour,
+		Drain:        DefaultDrainConfig(),
+	}
+}
+
+// ErrorCluster regroupe les occurrences d'une même cause : même pile
+// normalisée, ou à défaut même template de message
+type ErrorCluster struct {
+	ID               string        `json:"id"`
+	Key              string        `json:"key"`
+	StackFingerprint string        `json:"stack_fingerprint,omitempty"`
+	Frames           []string      `json:"frames,omitempty"`
+	Template         string        `json:"template"`
+	Modules          []string      `json:"modules"`
+	ErrorCodes       []string      `json:"error_codes"`
+	Severity         string        `json:"severity"`
+	Count            int           `json:"count"`
+	FirstSeen        time.Time     `json:"first_seen"`
+	LastSeen         time.Time     `json:"last_seen"`
+	Status           ClusterStatus `json:"status"`
+	ResolvedAt       *time.Time    `json:"resolved_at,omitempty"`
+	Regressions      int           `json:"regressions,omitempty"`
+	Samples          []ErrorEntry  `json:"samples"`
+
+	occurrences []time.Time
+}
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[.*\]:$`)
+	goroutineSuffix = regexp.MustCompile(` in goroutine \d+$`)
+	goFileLine      = regexp.MustCompile(`^\S+\.go:\d+( \+0x[0-9a-f]+)?$`)
+	javaFrame       = regexp.MustCompile(`^at ([\w$.<>/]+)\(.*\)$`)
+	pythonFrame     = regexp.MustCompile(`^File "(?:.*[\\/])?([^"\\/]+)", line \d+, in (\S+)$`)
+	digitRun        = regexp.MustCompile(`\d+`)
+)
+
+// noiseFramePrefixes sont les frames du runtime, identiques pour toutes les piles
+var noiseFramePrefixes = []string{"runtime.", "testing.", "reflect."}
+
+// NormalizeStackTrace réduit une pile d'appels à la liste de ses fonctions :
+// en-têtes de goroutine, arguments, adresses, chemins de fichiers et numéros
+// de ligne sont retirés, ainsi que les frames du runtime. Deux piles qui ne
+// diffèrent que par ces détails donnent les mêmes frames. Les piles Go, Java
+// et Python sont reconnues ; les autres lignes sont gardées, chiffres masqués.
+func NormalizeStackTrace(trace string) []string {
+	var frames []string
+	for _, line := range strings.Split(trace, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || goroutineHeader.MatchString(line) || goFileLine.MatchString(line) ||
+			strings.HasPrefix(line, "panic:") || strings.HasPrefix(line, "[signal") || strings.HasPrefix(line, "exit status") {
+			continue
+		}
+
+		var frame string
+		if match := javaFrame.FindStringSubmatch(line); match != nil {
+			frame = match[1]
+		} else if match := pythonFrame.FindStringSubmatch(line); match != nil {
+			frame = match[1] + ":" + match[2]
+		} else {
+			frame = goroutineSuffix.ReplaceAllString(strings.TrimPrefix(line, "created by "), "")
+			// Retirer la liste d'arguments d'une frame Go : pkg.(*T).f(0xc000..., {0x1, 0x2})
+			if strings.HasSuffix(frame, ")") {
+				if open := strings.LastIndex(frame, "("); open > 0 && frame[open-1] != '.' {
+					frame = frame[:open]
+				}
+			}
+			frame = digitRun.ReplaceAllString(frame, "N")
+		}
+
+		if isNoiseFrame(frame) {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func isNoiseFrame(frame string) bool {
+	if frame == "panic" {
+		return true
+	}
+	for _, prefix := range noiseFramePrefixes {
+		if strings.HasPrefix(frame, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StackFingerprint est l'empreinte des depth premières frames normalisées ;
+// elle est vide quand la pile ne contient aucune frame exploitable
+func StackFingerprint(trace string, depth int) string {
+	return framesFingerprint(NormalizeStackTrace(trace), depth)
+}
+
+func framesFingerprint(frames []string, depth int) string {
+	if depth > 0 && len(frames) > depth {
+		frames = frames[:depth]
+	}
+	if len(frames) == 0 {
+		return ""
+	}
+	return shortHash(strings.Join(frames, "\n"))
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// severityRank ordonne les sévérités du catalogue
+var severityRank = map[string]int{"DEBUG": 0, "INFO": 1, "WARNING": 2, "ERROR": 3, "CRITICAL": 4}
+
+// ClusterEntries regroupe des erreurs : les entrées qui ont une pile sont
+// regroupées par empreinte de pile normalisée, les autres par template de
+// message (extrait avec Drain). Les clusters sont triés par nombre
+// d'occurrences décroissant ; leur statut est attribué par un ClusterTracker.
+func ClusterEntries(entries []ErrorEntry, config ClusterConfig) []ErrorCluster {
+	defaults := DefaultClusterConfig()
+	if config.StackDepth <= 0 {
+		config.StackDepth = defaults.StackDepth
+	}
+	if config.MaxSamples <= 0 {
+		config.MaxSamples = defaults.MaxSamples
+	}
+
+	sorted := append([]ErrorEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// Tous les messages passent par le parser avant la lecture des templates,
+	// qui se généralisent au fil des ajouts
+	parser := NewDrainParser(config.Drain)
+	templates := make([]*LogTemplate, len(sorted))
+	for i, entry := range sorted {
+		templates[i] = parser.Add(entry.Message)
+	}
+
+	clusters := make(map[string]*ErrorCluster)
+	templateCounts := make(map[string]map[string]int)
+	var order []string
+
+	for i, entry := range sorted {
+		template := templates[i].String()
+		frames := NormalizeStackTrace(entry.StackTrace)
+		if len(frames) > config.StackDepth {
+			frames = frames[:config.StackDepth]
+		}
+		fingerprint := framesFingerprint(frames, config.StackDepth)
+
+		key := "template:" + shortHash(template)
+		if fingerprint != "" {
+			key = "stack:" + fingerprint
+		}
+
+		cluster, exists := clusters[key]
+		if !exists {
+			cluster = &ErrorCluster{
+				ID:               "cl-" + shortHash(key)[:12],
+				Key:              key,
+				StackFingerprint: fingerprint,
+				Frames:           frames,
+				FirstSeen:        entry.Timestamp,
+			}
+			clusters[key] = cluster
+			templateCounts[key] = make(map[string]int)
+			order = append(order, key)
+		}
+
+		cluster.Count++
+		cluster.LastSeen = entry.Timestamp
+		cluster.Modules = appendUnique(cluster.Modules, entry.Module)
+		cluster.ErrorCodes = appendUnique(cluster.ErrorCodes, entry.ErrorCode)
+		if cluster.Severity == "" || severityRank[entry.Severity] > severityRank[cluster.Severity] {
+			cluster.Severity = entry.Severity
+		}
+		if len(cluster.Samples) < config.MaxSamples {
+			cluster.Samples = append(cluster.Samples, entry)
+		}
+		cluster.occurrences = append(cluster.occurrences, entry.Timestamp)
+		templateCounts[key][template]++
+	}
+
+	result := make([]ErrorCluster, 0, len(order))
+	for _, key := range order {
+		cluster := clusters[key]
+		cluster.Template = mostFrequent(templateCounts[key])
+		result = append(result, *cluster)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+	return result
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// mostFrequent retourne la clé la plus fréquente (la plus petite en cas d'égalité)
+func mostFrequent(counts map[string]int) string {
+	best, bestCount := "", 0
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
+
+// CorrelateClusters mesure la co-occurrence des clusters : pour chaque paire,
+// la part des occurrences du cluster le moins fréquent suivies ou précédées
+// d'une occurrence de l'autre dans la fenêtre donnée
+func CorrelateClusters(clusters []ErrorCluster, window time.Duration) []TemporalCorrelation {
+	var correlations []TemporalCorrelation
+	for i := range clusters {
+		for j := i + 1; j < len(clusters); j++ {
+			a, b := clusters[i], clusters[j]
+			if len(a.occurrences) < 2 || len(b.occurrences) < 2 {
+				continue
+			}
+			if len(a.occurrences) > len(b.occurrences) {
+				a, b = b, a
+			}
+
+			matched := 0
+			var totalGap time.Duration
+			for _, at := range a.occurrences {
+				if gap, ok := nearestGap(b.occurrences, at); ok && gap <= window {
+					matched++
+					totalGap += gap
+				}
+			}
+			if matched < 2 {
+				continue
+			}
+
+			correlations = append(correlations, TemporalCorrelation{
+				ErrorCode1:    firstOrEmpty(a.ErrorCodes),
+				ErrorCode2:    firstOrEmpty(b.ErrorCodes),
+				Module1:       firstOrEmpty(a.Modules),
+				Module2:       firstOrEmpty(b.Modules),
+				Cluster1:      a.ID,
+				Cluster2:      b.ID,
+				Correlation:   float64(matched) / float64(len(a.occurrences)),
+				TimeWindow:    window,
+				OccurrenceGap: totalGap / time.Duration(matched),
+			})
+		}
+	}
+
+	sort.Slice(correlations, func(i, j int) bool {
+		return correlations[i].Correlation > correlations[j].Correlation
+	})
+	return correlations
+}
+
+// nearestGap retourne l'écart entre at et l'instant le plus proche de times (trié)
+func nearestGap(times []time.Time, at time.Time) (time.Duration, bool) {
+	if len(times) == 0 {
+		return 0, false
+	}
+	index := sort.Search(len(times), func(k int) bool { return !times[k].Before(at) })
+	best := time.Duration(-1)
+	for _, k := range []int{index - 1, index} {
+		if k < 0 || k >= len(times) {
+			continue
+		}
+		gap := times[k].Sub(at)
+		if gap < 0 {
+			gap = -gap
+		}
+		if best < 0 || gap < best {
+			best = gap
+		}
+	}
+	return best, true
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// trackedCluster est l'état persisté d'un cluster
+type trackedCluster struct {
+	ID               string        `json:"id"`
+	Key              string        `json:"key"`
+	StackFingerprint string        `json:"stack_fingerprint,omitempty"`
+	Template         string        `json:"template"`
+	Modules          []string      `json:"modules"`
+	ErrorCodes       []string      `json:"error_codes"`
+	Severity         string        `json:"severity"`
+	FirstSeen        time.Time     `json:"first_seen"`
+	LastSeen         time.Time     `json:"last_seen"`
+	Status           ClusterStatus `json:"status"`
+	ResolvedAt       *time.Time    `json:"resolved_at,omitempty"`
+	Regressions      int           `json:"regressions,omitempty"`
+}
+
+// clusterTrackerVersion est la version du format de fichier du tracker
+const clusterTrackerVersion = 1
+
+type clusterTrackerFile struct {
+	Version  int                        `json:"version"`
+	Clusters map[string]*trackedCluster `json:"clusters"`
+}
+
+// ClusterTracker suit le cycle de vie des clusters entre deux analyses :
+// nouveau, en cours, résolu (aucune occurrence depuis ResolveAfter) ou
+// régressé (réapparu après résolution). Un cluster sans pile dont le template
+// s'est généralisé garde son identifiant.
+type ClusterTracker struct {
+	path  string
+	mutex sync.Mutex
+	data  clusterTrackerFile
+}
+
+// NewClusterTracker ouvre (ou crée) l'état stocké dans path ; un path vide
+// garde l'état en mémoire
+func NewClusterTracker(path string) (*ClusterTracker, error) {
+	tracker := &ClusterTracker{
+		path: path,
+		data: clusterTrackerFile{Version: clusterTrackerVersion, Clusters: make(map[string]*trackedCluster)},
+	}
+	if path == "" {
+		return tracker, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tracker, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la lecture de l'état des clusters: %w", err)
+	}
+	if err := json.Unmarshal(content, &tracker.data); err != nil {
+		return nil, fmt.Errorf("état des clusters invalide dans %s: %w", path, err)
+	}
+	if tracker.data.Version != clusterTrackerVersion {
+		return nil, fmt.Errorf("version d'état des clusters non supportée: %d", tracker.data.Version)
+	}
+	if tracker.data.Clusters == nil {
+		tracker.data.Clusters = make(map[string]*trackedCluster)
+	}
+	return tracker, nil
+}
+
+// Update attribue leur statut aux clusters d'une analyse faite à l'instant
+// now. Les clusters suivis absents de l'analyse et devenus résolus sont
+// ajoutés au résultat (sans occurrence) pour que les rapports les montrent.
+func (t *ClusterTracker) Update(clusters []ErrorCluster, now time.Time, resolveAfter time.Duration) ([]ErrorCluster, error) {
+	if resolveAfter <= 0 {
+		resolveAfter = DefaultClusterConfig().ResolveAfter
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make([]ErrorCluster, 0, len(clusters))
+	seen := make(map[string]bool, len(clusters))
+
+	for _, cluster := range clusters {
+		tracked := t.match(cluster, seen)
+		if tracked == nil {
+			tracked = &trackedCluster{ID: cluster.ID, FirstSeen: cluster.FirstSeen, Status: ClusterNew}
+			t.data.Clusters[tracked.ID] = tracked
+		} else {
+			switch {
+			case tracked.Status == ClusterResolved && tracked.ResolvedAt != nil && cluster.LastSeen.After(*tracked.ResolvedAt):
+				tracked.Status = ClusterRegressed
+				tracked.ResolvedAt = nil
+				tracked.Regressions++
+			case tracked.Status == ClusterNew || tracked.Status == ClusterRegressed:
+				tracked.Status = ClusterOngoing
+			}
+			if cluster.FirstSeen.Before(tracked.FirstSeen) {
+				tracked.FirstSeen = cluster.FirstSeen
+			}
+		}
+		seen[tracked.ID] = true
+
+		tracked.Key = cluster.Key
+		tracked.StackFingerprint = cluster.StackFingerprint
+		tracked.Template = cluster.Template
+		tracked.Modules = cluster.Modules
+		tracked.ErrorCodes = cluster.ErrorCodes
+		tracked.Severity = cluster.Severity
+		if cluster.LastSeen.After(tracked.LastSeen) {
+			tracked.LastSeen = cluster.LastSeen
+		}
+		t.resolveIfStale(tracked, now, resolveAfter)
+
+		cluster.ID = tracked.ID
+		cluster.FirstSeen = tracked.FirstSeen
+		cluster.Status = tracked.Status
+		cluster.ResolvedAt = tracked.ResolvedAt
+		cluster.Regressions = tracked.Regressions
+		result = append(result, cluster)
+	}
+
+	// Les clusters absents de l'analyse peuvent être arrivés à résolution
+	ids := make([]string, 0, len(t.data.Clusters))
+	for id := range t.data.Clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		tracked := t.data.Clusters[id]
+		if seen[id] || tracked.Status == ClusterResolved {
+			continue
+		}
+		if t.resolveIfStale(tracked, now, resolveAfter) {
+			result = append(result, tracked.cluster())
+		}
+	}
+
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Clusters retourne l'état de tous les clusters suivis
+func (t *ClusterTracker) Clusters() []ErrorCluster {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make([]ErrorCluster, 0, len(t.data.Clusters))
+	for _, tracked := range t.data.Clusters {
+		result = append(result, tracked.cluster())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LastSeen.After(result[j].LastSeen) })
+	return result
+}
+
+// match retrouve le cluster suivi : même clé, sinon (sans pile) un template
+// compatible position par position
+func (t *ClusterTracker) match(cluster ErrorCluster, seen map[string]bool) *trackedCluster {
+	var candidate *trackedCluster
+	for _, tracked := range t.data.Clusters {
+		if seen[tracked.ID] {
+			continue
+		}
+		if tracked.Key == cluster.Key {
+			return tracked
+		}
+		if cluster.StackFingerprint == "" && tracked.StackFingerprint == "" && candidate == nil &&
+			templatesCompatible(tracked.Template, cluster.Template) {
+			candidate = tracked
+		}
+	}
+	return candidate
+}
+
+// resolveIfStale marque le cluster résolu s'il n'a pas eu d'occurrence depuis resolveAfter
+func (t *ClusterTracker) resolveIfStale(tracked *trackedCluster, now time.Time, resolveAfter time.Duration) bool {
+	if tracked.Status == ClusterResolved || now.Sub(tracked.LastSeen) <= resolveAfter {
+		return false
+	}
+	resolvedAt := tracked.LastSeen.Add(resolveAfter)
+	tracked.Status = ClusterResolved
+	tracked.ResolvedAt = &resolvedAt
+	return true
+}
+
+func (t *ClusterTracker) save() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erreur lors de l'encodage de l'état des clusters: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("erreur lors de la création du dossier %s: %w", filepath.Dir(t.path), err)
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erreur lors de l'écriture de l'état des clusters: %w", err)
+	}
+	return os.Rename(tmp, t.path)
+}
+
+func (tc *trackedCluster) cluster() ErrorCluster {
+	return ErrorCluster{
+		ID:               tc.ID,
+		Key:              tc.Key,
+		StackFingerprint: tc.StackFingerprint,
+		Template:         tc.Template,
+		Modules:          tc.Modules,
+		ErrorCodes:       tc.ErrorCodes,
+		Severity:         tc.Severity,
+		FirstSeen:        tc.FirstSeen,
+		LastSeen:         tc.LastSeen,
+		Status:           tc.Status,
+		ResolvedAt:       tc.ResolvedAt,
+		Regressions:      tc.Regressions,
+	}
+}
+
+// templatesCompatible indique si deux templates ne diffèrent que par des <*>
+func templatesCompatible(a, b string) bool {
+	tokensA, tokensB := strings.Fields(a), strings.Fields(b)
+	if len(tokensA) != len(tokensB) || len(tokensA) == 0 {
+		return false
+	}
+	for i := range tokensA {
+		if tokensA[i] != tokensB[i] && tokensA[i] != TemplateWildcard && tokensB[i] != TemplateWildcard {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadErrors lit les erreurs enregistrées depuis since
+func (pa *PatternAnalyzer) LoadErrors(since time.Time) ([]ErrorEntry, error) {
+	query := `
+		SELECT id, timestamp, message, COALESCE(stack_trace, ''), module, error_code,
+			COALESCE(manager_context::text, ''), severity
+		FROM project_errors
+		WHERE timestamp >= $1
+		ORDER BY timestamp ASC
+	`
+
+	rows, err := pa.db.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la lecture des erreurs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []ErrorEntry
+	for rows.Next() {
+		var entry ErrorEntry
+		var managerContext sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.Message, &entry.StackTrace,
+			&entry.Module, &entry.ErrorCode, &managerContext, &entry.Severity); err != nil {
+			return nil, fmt.Errorf("erreur lors du scan d'une erreur: %w", err)
+		}
+		entry.ManagerContext = managerContext.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// ClusterErrors regroupe les erreurs enregistrées depuis since et met à jour
+// leur cycle de vie
+func (pa *PatternAnalyzer) ClusterErrors(since time.Time) ([]ErrorCluster, error) {
+	entries, err := pa.LoadErrors(since)
+	if err != nil {
+		return nil, err
+	}
+	return pa.AnalyzeClusters(entries)
+}
+
+// AnalyzeClusters regroupe des erreurs déjà chargées et met à jour leur cycle de vie
+func (pa *PatternAnalyzer) AnalyzeClusters(entries []ErrorEntry) ([]ErrorCluster, error) {
+	clusters := ClusterEntries(entries, pa.clusterConfig)
+	return pa.tracker.Update(clusters, time.Now(), pa.clusterConfig.ResolveAfter)
+}
+
+// SetClusterConfig remplace la configuration du regroupement
+func (pa *PatternAnalyzer) SetClusterConfig(config ClusterConfig) {
+	pa.clusterConfig = config
+}
+
+// SetClusterTracker remplace le suivi du cycle de vie, par exemple par un
+// tracker persisté sur disque
+func (pa *PatternAnalyzer) SetClusterTracker(tracker *ClusterTracker) {
+	pa.tracker = tracker
+}
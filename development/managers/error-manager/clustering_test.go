@@ -0,0 +1,201 @@
+package errormanager_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	errormanager "github.com/gerivdb/email-sender-1/managers/error-manager"
+)
+
+const traceAlice = `goroutine 17 [running]:
+github.com/acme/mailer.(*Sender).Send(0xc000123456, {0x1234, 0x5})
+	/home/alice/src/mailer/sender.go:42 +0x1a5
+github.com/acme/mailer.(*Queue).worker(0xc000222000)
+	/home/alice/src/mailer/queue.go:88 +0x85
+created by github.com/acme/mailer.NewQueue in goroutine 1
+	/home/alice/src/mailer/queue.go:30 +0x12`
+
+const traceBuild = `goroutine 203 [running]:
+panic({0x8a3f20, 0xc0000a4000})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+github.com/acme/mailer.(*Sender).Send(0xc000999999, {0x9999, 0x7})
+	/build/mailer/sender.go:45 +0x1b0
+github.com/acme/mailer.(*Queue).worker(0xc000333000)
+	/build/mailer/queue.go:90 +0x85
+created by github.com/acme/mailer.NewQueue in goroutine 7
+	/build/mailer/queue.go:30 +0x12`
+
+func TestNormalizeStackTrace(t *testing.T) {
+	frames := errormanager.NormalizeStackTrace(traceAlice)
+	want := []string{
+		"github.com/acme/mailer.(*Sender).Send",
+		"github.com/acme/mailer.(*Queue).worker",
+		"github.com/acme/mailer.NewQueue",
+	}
+	if strings.Join(frames, "|") != strings.Join(want, "|") {
+		t.Errorf("NormalizeStackTrace() = %q, want %q", frames, want)
+	}
+
+	// Goroutines, adresses, chemins, lignes et frames du runtime différents
+	if errormanager.StackFingerprint(traceAlice, 5) != errormanager.StackFingerprint(traceBuild, 5) {
+		t.Error("equivalent stack traces have different fingerprints")
+	}
+	if errormanager.StackFingerprint("", 5) != "" {
+		t.Error("empty stack trace should have no fingerprint")
+	}
+
+	java := "java.lang.NullPointerException\n\tat com.acme.Mailer.send(Mailer.java:42)\n\tat com.acme.Queue.run(Queue.java:10)"
+	if frames := errormanager.NormalizeStackTrace(java); len(frames) != 3 || frames[1] != "com.acme.Mailer.send" {
+		t.Errorf("unexpected Java frames %q", frames)
+	}
+}
+
+func TestDrainParserTemplates(t *testing.T) {
+	parser := errormanager.NewDrainParser(errormanager.DrainConfig{})
+	first := parser.Add("connection to 10.0.0.12:5432 refused after 3 retries")
+	second := parser.Add("connection to 10.0.0.17:5432 refused after 5 retries")
+	other := parser.Add("template welcome.html not found")
+
+	if first != second {
+		t.Fatalf("similar messages produced different templates: %q / %q", first, second)
+	}
+	if got := first.String(); got != "connection to <*> refused after <*> retries" {
+		t.Errorf("template = %q", got)
+	}
+	if other == first || len(parser.Templates()) != 2 {
+		t.Errorf("got %d templates, want 2", len(parser.Templates()))
+	}
+	if tokens := errormanager.TokenizeMessage(`user_id=42 path /var/lib/app/x.db "quoted value"`); strings.Join(tokens, " ") != "user_id=<*> path <*> <*>" {
+		t.Errorf("TokenizeMessage() = %q", tokens)
+	}
+}
+
+func TestClusterEntriesAndLifecycle(t *testing.T) {
+	now := time.Now()
+	entries := []errormanager.ErrorEntry{
+		{ID: "a1", Timestamp: now.Add(-3 * time.Hour), Message: "send failed for message 1001", StackTrace: traceAlice, Module: "email-manager", ErrorCode: "SEND_FAILED", Severity: "ERROR"},
+		{ID: "a2", Timestamp: now.Add(-2 * time.Hour), Message: "send failed for message 1002", StackTrace: traceBuild, Module: "email-manager", ErrorCode: "SEND_FAILED_V2", Severity: "CRITICAL"},
+		{ID: "b1", Timestamp: now.Add(-90 * time.Minute), Message: "user 17 not found", Module: "user-manager", ErrorCode: "NOT_FOUND", Severity: "WARNING"},
+		{ID: "b2", Timestamp: now.Add(-time.Hour), Message: "user 23 not found", Module: "user-manager", ErrorCode: "NOT_FOUND", Severity: "WARNING"},
+	}
+
+	clusters := errormanager.ClusterEntries(entries, errormanager.DefaultClusterConfig())
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2: %+v", len(clusters), clusters)
+	}
+	for _, cluster := range clusters {
+		if cluster.Count != 2 || len(cluster.Samples) != 2 {
+			t.Errorf("cluster %s: count %d, %d samples", cluster.ID, cluster.Count, len(cluster.Samples))
+		}
+	}
+	stack := clusters[0]
+	if stack.StackFingerprint == "" {
+		stack = clusters[1]
+	}
+	if stack.Severity != "CRITICAL" || len(stack.ErrorCodes) != 2 || !stack.FirstSeen.Equal(entries[0].Timestamp) || !stack.LastSeen.Equal(entries[1].Timestamp) {
+		t.Errorf("unexpected stack cluster %+v", stack)
+	}
+
+	// Cycle de vie : nouveau, en cours, résolu puis régressé
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	tracker, err := errormanager.NewClusterTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := tracker.Update(clusters, now, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	for _, cluster := range updated {
+		if cluster.Status != errormanager.ClusterNew {
+			t.Errorf("first run: cluster %s is %s, want new", cluster.ID, cluster.Status)
+		}
+	}
+
+	// Le template sans pile s'est généralisé : le cluster garde son identifiant
+	later := append(entries[2:4:4], errormanager.ErrorEntry{ID: "b3", Timestamp: now.Add(time.Hour), Message: "user 99 not found", Module: "user-manager", ErrorCode: "NOT_FOUND", Severity: "WARNING"})
+	reopened, err := errormanager.NewClusterTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err = reopened.Update(errormanager.ClusterEntries(later, errormanager.DefaultClusterConfig()), now.Add(2*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]errormanager.ClusterStatus)
+	for _, cluster := range updated {
+		statuses[cluster.ID] = cluster.Status
+	}
+	if statuses[stack.ID] != "" || len(statuses) != 1 {
+		t.Errorf("second run: unexpected statuses %v", statuses)
+	}
+	for id, status := range statuses {
+		if status != errormanager.ClusterOngoing || id == stack.ID {
+			t.Errorf("second run: cluster %s is %s, want ongoing", id, status)
+		}
+	}
+
+	// Deux jours plus tard, le cluster de pile est résolu
+	updated, err = reopened.Update(nil, now.Add(48*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved := false
+	for _, cluster := range updated {
+		resolved = resolved || (cluster.ID == stack.ID && cluster.Status == errormanager.ClusterResolved)
+	}
+	if !resolved {
+		t.Errorf("stack cluster not resolved: %+v", updated)
+	}
+
+	// Puis il réapparaît
+	again := []errormanager.ErrorEntry{{ID: "a3", Timestamp: now.Add(50 * time.Hour), Message: "send failed for message 2000", StackTrace: traceAlice, Module: "email-manager", ErrorCode: "SEND_FAILED", Severity: "ERROR"}}
+	updated, err = reopened.Update(errormanager.ClusterEntries(again, errormanager.DefaultClusterConfig()), now.Add(50*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) == 0 || updated[0].ID != stack.ID || updated[0].Status != errormanager.ClusterRegressed || updated[0].Regressions != 1 {
+		t.Errorf("expected a regression of %s, got %+v", stack.ID, updated)
+	}
+}
+
+func TestClusterCorrelationsAndHTMLReport(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	var entries []errormanager.ErrorEntry
+	for i := 0; i < 3; i++ {
+		at := base.Add(time.Duration(i) * 10 * time.Minute)
+		entries = append(entries,
+			errormanager.ErrorEntry{ID: "db", Timestamp: at, Message: "database timeout after 30s", Module: "database-manager", ErrorCode: "DB_TIMEOUT", Severity: "CRITICAL"},
+			errormanager.ErrorEntry{ID: "smtp", Timestamp: at.Add(time.Minute), Message: "smtp queue <b>stalled</b>", Module: "email-manager", ErrorCode: "SMTP_STALLED", Severity: "ERROR"},
+		)
+	}
+
+	analyzer := errormanager.NewPatternAnalyzer(nil)
+	clusters, err := analyzer.AnalyzeClusters(entries)
+	if err != nil {
+		t.Fatalf("AnalyzeClusters() error = %v", err)
+	}
+	correlations := errormanager.CorrelateClusters(clusters, 5*time.Minute)
+	if len(correlations) != 1 || correlations[0].Correlation != 1 || correlations[0].OccurrenceGap != time.Minute {
+		t.Fatalf("unexpected correlations %+v", correlations)
+	}
+
+	report := &errormanager.PatternReport{GeneratedAt: time.Now(), Clusters: clusters, ClusterCorrelations: correlations}
+	htmlFile := filepath.Join(t.TempDir(), "report.html")
+	if err := (&errormanager.ReportGenerator{}).ExportToHTML(report, htmlFile); err != nil {
+		t.Fatalf("ExportToHTML() error = %v", err)
+	}
+	content, err := os.ReadFile(htmlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+	for _, want := range []string{"Clusters d'Erreurs", clusters[0].ID, "database timeout after &lt;*&gt;", "3 exemple(s)", "smtp queue &lt;b&gt;stalled&lt;/b&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package errormanager
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TemplateWildcard remplace les parties variables d'un message
+const TemplateWildcard = "<*>"
+
+// variableTokens sont masqués avant l'extraction des templates : chaînes
+// citées, URLs, adresses e-mail et chemins changent d'une occurrence à l'autre
+var variableTokens = []*regexp.Regexp{
+	regexp.MustCompile(`"[^"]*"|'[^']*'`),
+	regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+`),
+	regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`),
+	regexp.MustCompile(`(?:[A-Za-z]:)?(?:[\\/][\w.@-]+){2,}`),
+}
+
+// TokenizeMessage découpe un message en tokens après masquage des parties
+// variables. Comme dans Drain, un token contenant un chiffre (identifiant,
+// nombre, adresse, UUID) est variable ; "id=42" devient "id=<*>".
+func TokenizeMessage(message string) []string {
+	for _, pattern := range variableTokens {
+		message = pattern.ReplaceAllString(message, TemplateWildcard)
+	}
+
+	tokens := strings.Fields(message)
+	for i, token := range tokens {
+		if !strings.ContainsAny(token, "0123456789") {
+			continue
+		}
+		if sep := strings.IndexAny(token, "=:"); sep > 0 && !strings.ContainsAny(token[:sep], "0123456789") {
+			tokens[i] = token[:sep+1] + TemplateWildcard
+			continue
+		}
+		tokens[i] = TemplateWildcard
+	}
+	return tokens
+}
+
+// DrainConfig paramètre l'extraction de templates (algorithme Drain)
+type DrainConfig struct {
+	Depth               int     `json:"depth"`                // profondeur de l'arbre de préfixes, feuilles comprises
+	SimilarityThreshold float64 `json:"similarity_threshold"` // similarité minimale pour rejoindre un template
+	MaxChildren         int     `json:"max_children"`         // au-delà, les nouveaux tokens passent par <*>
+}
+
+// DefaultDrainConfig retourne les paramètres usuels de Drain
+func DefaultDrainConfig() DrainConfig {
+	return DrainConfig{Depth: 4, SimilarityThreshold: 0.5, MaxChildren: 100}
+}
+
+// LogTemplate est un template de message extrait par le DrainParser
+type LogTemplate struct {
+	ID     int      `json:"id"`
+	Tokens []string `json:"tokens"`
+	Count  int      `json:"count"`
+}
+
+// String retourne le template sous forme de texte
+func (t *LogTemplate) String() string {
+	return strings.Join(t.Tokens, " ")
+}
+
+// drainNode est un nœud de l'arbre de préfixes ; seules les feuilles portent des templates
+type drainNode struct {
+	children  map[string]*drainNode
+	templates []*LogTemplate
+}
+
+func newDrainNode() *drainNode {
+	return &drainNode{children: make(map[string]*drainNode)}
+}
+
+// DrainParser regroupe des messages de log en templates selon Drain : les
+// messages sont répartis par nombre de tokens puis par leurs premiers tokens,
+// et rejoignent le template le plus similaire de la feuille atteinte ; les
+// positions qui diffèrent deviennent <*>
+type DrainParser struct {
+	config    DrainConfig
+	root      *drainNode
+	templates []*LogTemplate
+	mutex     sync.Mutex
+}
+
+// NewDrainParser crée un parser ; les valeurs nulles de config prennent les valeurs par défaut
+func NewDrainParser(config DrainConfig) *DrainParser {
+	defaults := DefaultDrainConfig()
+	if config.Depth < 3 {
+		config.Depth = defaults.Depth
+	}
+	if config.SimilarityThreshold <= 0 {
+		config.SimilarityThreshold = defaults.SimilarityThreshold
+	}
+	if config.MaxChildren <= 0 {
+		config.MaxChildren = defaults.MaxChildren
+	}
+	return &DrainParser{config: config, root: newDrainNode()}
+}
+
+// Add ajoute un message et retourne le template auquel il appartient. Le
+// template peut encore se généraliser avec les messages suivants.
+func (d *DrainParser) Add(message string) *LogTemplate {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tokens := TokenizeMessage(message)
+	leaf := d.leafFor(tokens)
+
+	var best *LogTemplate
+	bestSimilarity := -1.0
+	for _, template := range leaf.templates {
+		if similarity := templateSimilarity(template.Tokens, tokens); similarity > bestSimilarity {
+			best, bestSimilarity = template, similarity
+		}
+	}
+
+	if best != nil && bestSimilarity >= d.config.SimilarityThreshold {
+		for i, token := range tokens {
+			if best.Tokens[i] != token {
+				best.Tokens[i] = TemplateWildcard
+			}
+		}
+		best.Count++
+		return best
+	}
+
+	template := &LogTemplate{ID: len(d.templates) + 1, Tokens: append([]string(nil), tokens...), Count: 1}
+	leaf.templates = append(leaf.templates, template)
+	d.templates = append(d.templates, template)
+	return template
+}
+
+// Templates retourne les templates extraits, par ordre de création
+func (d *DrainParser) Templates() []LogTemplate {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	result := make([]LogTemplate, 0, len(d.templates))
+	for _, template := range d.templates {
+		copied := *template
+		copied.Tokens = append([]string(nil), template.Tokens...)
+		result = append(result, copied)
+	}
+	return result
+}
+
+// leafFor descend l'arbre par le nombre de tokens puis les premiers tokens
+func (d *DrainParser) leafFor(tokens []string) *drainNode {
+	node := d.child(d.root, strconv.Itoa(len(tokens)))
+	for i := 0; i < d.config.Depth-2 && i < len(tokens); i++ {
+		token := tokens[i]
+		if _, exists := node.children[token]; !exists && len(node.children) >= d.config.MaxChildren-1 {
+			token = TemplateWildcard
+		}
+		node = d.child(node, token)
+	}
+	return node
+}
+
+func (d *DrainParser) child(node *drainNode, key string) *drainNode {
+	next, exists := node.children[key]
+	if !exists {
+		next = newDrainNode()
+		node.children[key] = next
+	}
+	return next
+}
+
+// templateSimilarity est la part des positions identiques ; un <*> du
+// template accepte n'importe quel token
+func templateSimilarity(template, tokens []string) float64 {
+	if len(template) != len(tokens) {
+		return 0
+	}
+	if len(tokens) == 0 {
+		return 1
+	}
+	same := 0
+	for i, token := range tokens {
+		if template[i] == token || template[i] == TemplateWildcard {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens))
+}
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -49,6 +50,14 @@ func (rg *ReportGenerator) GeneratePatternReport() (*PatternReport, error) {
 	}
 	report.TemporalCorrelations = correlations
 
+	// Regrouper les erreurs par cause (pile normalisée ou template de message)
+	clusters, err := rg.analyzer.ClusterErrors(time.Now().Add(-rg.analyzer.clusterConfig.Window))
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du regroupement des erreurs: %w", err)
+	}
+	report.Clusters = clusters
+	report.ClusterCorrelations = CorrelateClusters(clusters, 1*time.Hour)
+
 	// Générer les recommandations et findings critiques
 	rg.generateRecommendations(report)
 	rg.identifyCriticalFindings(report)
@@ -77,6 +86,15 @@ func (rg *ReportGenerator) generateRecommendations(report *PatternReport) {
 		}
 	}
 
+	// Recommandations basées sur les nouveaux clusters
+	for _, cluster := range report.Clusters {
+		if cluster.Status == ClusterNew && cluster.Count > 10 {
+			report.Recommendations = append(report.Recommendations,
+				fmt.Sprintf("Analyser le nouveau cluster %s \"%s\" (%d occurrences)",
+					cluster.ID, cluster.Template, cluster.Count))
+		}
+	}
+
 	// Recommandations générales
 	if report.TotalErrors > 100 {
 		report.Recommendations = append(report.Recommendations,
@@ -116,6 +134,15 @@ func (rg *ReportGenerator) identifyCriticalFindings(report *PatternReport) {
 		}
 	}
 
+	// Findings basés sur les clusters réapparus après résolution
+	for _, cluster := range report.Clusters {
+		if cluster.Status == ClusterRegressed {
+			report.CriticalFindings = append(report.CriticalFindings,
+				fmt.Sprintf("RÉGRESSION: Cluster %s \"%s\" réapparu (%d occurrences, dernière: %s)",
+					cluster.ID, cluster.Template, cluster.Count, cluster.LastSeen.Format("2006-01-02 15:04:05")))
+		}
+	}
+
 	// Findings basés sur les corrélations élevées
 	for _, corr := range report.TemporalCorrelations {
 		if corr.Correlation > 0.8 {
@@ -173,6 +200,12 @@ func (rg *ReportGenerator) ExportToHTML(report *PatternReport, filename string)
         .severity.ERROR { background-color: #ff9800; }
         .severity.WARNING { background-color: #ffeb3b; color: #333; }
         .severity.INFO { background-color: #2196F3; }
+        .status { padding: 4px 8px; border-radius: 4px; color: white; font-size: 0.8em; }
+        .status.new { background-color: #2196F3; }
+        .status.ongoing { background-color: #ff9800; }
+        .status.regressed { background-color: #f44336; }
+        .status.resolved { background-color: #4CAF50; }
+        .samples { margin: 5px 0; padding-left: 20px; font-size: 0.9em; }
     </style>
 </head>
 <body>
@@ -185,6 +218,7 @@ func (rg *ReportGenerator) ExportToHTML(report *PatternReport, filename string)
             <p><strong>Total des erreurs:</strong> <span class="frequency">{{.TotalErrors}}</span></p>
             <p><strong>Patterns uniques:</strong> {{.UniquePatterns}}</p>
             <p><strong>Corrélations détectées:</strong> {{len .TemporalCorrelations}}</p>
+            <p><strong>Clusters d'erreurs:</strong> {{len .Clusters}}</p>
         </div>
 
         {{if .CriticalFindings}}
@@ -227,6 +261,72 @@ func (rg *ReportGenerator) ExportToHTML(report *PatternReport, filename string)
             </tbody>
         </table>
 
+        {{if .Clusters}}
+        <h2>🧩 Clusters d'Erreurs</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Cluster</th>
+                    <th>Statut</th>
+                    <th>Template</th>
+                    <th>Modules</th>
+                    <th>Occurrences</th>
+                    <th>Première Occurrence</th>
+                    <th>Dernière Occurrence</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Clusters}}
+                <tr>
+                    <td>{{.ID}}</td>
+                    <td><span class="status {{.Status}}">{{.Status}}</span></td>
+                    <td>
+                        <code>{{.Template}}</code>
+                        {{if .Samples}}
+                        <details>
+                            <summary>{{len .Samples}} exemple(s)</summary>
+                            <ul class="samples">
+                                {{range .Samples}}
+                                <li><span class="timestamp">{{.Timestamp.Format "2006-01-02 15:04:05"}}</span> {{.Module}}:{{.ErrorCode}} [{{.ID}}] {{.Message}}</li>
+                                {{end}}
+                            </ul>
+                        </details>
+                        {{end}}
+                    </td>
+                    <td>{{join .Modules ", "}}</td>
+                    <td><span class="frequency">{{.Count}}</span></td>
+                    <td class="timestamp">{{.FirstSeen.Format "2006-01-02 15:04:05"}}</td>
+                    <td class="timestamp">{{.LastSeen.Format "2006-01-02 15:04:05"}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        {{if .ClusterCorrelations}}
+        <h2>🔗 Corrélations entre Clusters</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Cluster 1</th>
+                    <th>Cluster 2</th>
+                    <th>Score de Corrélation</th>
+                    <th>Écart Moyen</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .ClusterCorrelations}}
+                <tr>
+                    <td>{{.Cluster1}} ({{.Module1}})</td>
+                    <td>{{.Cluster2}} ({{.Module2}})</td>
+                    <td><span class="frequency">{{printf "%.2f" .Correlation}}</span></td>
+                    <td class="timestamp">{{.OccurrenceGap}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
         {{if .TemporalCorrelations}}
         <h2>⏱️ Corrélations Temporelles</h2>
         <table>
@@ -279,7 +379,7 @@ func (rg *ReportGenerator) ExportToHTML(report *PatternReport, filename string)
 </body>
 </html>`
 
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	tmpl, err := template.New("report").Funcs(template.FuncMap{"join": strings.Join}).Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("erreur lors du parsing du template HTML: %w", err)
 	}
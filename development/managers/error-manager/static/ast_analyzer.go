@@ -5,9 +5,12 @@ package static
 import (
 	"fmt"
 	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -168,7 +171,12 @@ func NewASTAnalyzer(config AnalyzerConfig) *ASTAnalyzer {
 	return analyzer
 }
 
-// AnalyzeFile analyse un fichier Go spécifique
+// loadMode charge la syntaxe et les informations de types complètes : les
+// règles reçoivent un *types.Info rempli par go/types
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo
+
+// AnalyzeFile analyse un fichier Go spécifique, type-checké avec son package
 func (a *ASTAnalyzer) AnalyzeFile(filePath string) (*AnalysisResult, error) {
 	startTime := time.Now()
 
@@ -181,8 +189,7 @@ func (a *ASTAnalyzer) AnalyzeFile(filePath string) (*AnalysisResult, error) {
 		a.statistics.CacheMisses++
 	}
 
-	// Parser le fichier
-	src, err := parser.ParseFile(a.fileSet, filePath, nil, parser.ParseComments)
+	fset, src, info, typeIssues, err := a.loadFile(filePath)
 	if err != nil {
 		return &AnalysisResult{
 			FilePath:     filePath,
@@ -193,27 +200,95 @@ func (a *ASTAnalyzer) AnalyzeFile(filePath string) (*AnalysisResult, error) {
 		}, err
 	}
 
-	// Analyser les types
-	info := &types.Info{
-		Types: make(map[ast.Expr]types.TypeAndValue),
-		Defs:  make(map[*ast.Ident]types.Object),
-		Uses:  make(map[*ast.Ident]types.Object),
+	return a.analyzeSyntax(filePath, src, fset, info, typeIssues, startTime), nil
+}
+
+// AnalyzeProject analyse tout un projet Go ; chaque package est chargé et
+// type-checké une seule fois avec go/packages
+func (a *ASTAnalyzer) AnalyzeProject(projectPath string) ([]*AnalysisResult, error) {
+	cfg := &packages.Config{
+		Mode:  loadMode,
+		Dir:   projectPath,
+		Fset:  a.fileSet,
+		Tests: a.config.IncludeTests,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
 	}
 
+	a.mutex.Lock()
+	a.packages = pkgs
+	a.mutex.Unlock()
+
+	var results []*AnalysisResult
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	// Les fichiers partagés par un package et sa variante de test ne sont
+	// analysés qu'une fois
+	seen := make(map[string]bool)
+
+	// Analyser chaque fichier en parallèle
+	for _, pkg := range pkgs {
+		typeIssues := packageErrorIssues(pkg)
+		info := pkg.TypesInfo
+		if info == nil {
+			info = newTypesInfo()
+		}
+
+		for _, file := range pkg.Syntax {
+			filePath := pkg.Fset.File(file.Pos()).Name()
+			if seen[filePath] || (!a.config.IncludeTests && strings.HasSuffix(filePath, "_test.go")) {
+				continue
+			}
+			seen[filePath] = true
+
+			wg.Add(1)
+			go func(filePath string, file *ast.File, fset *token.FileSet, info *types.Info, issues []StaticIssue) {
+				defer wg.Done()
+
+				result := a.analyzeSyntax(filePath, file, fset, info, issues, time.Now())
+				mutex.Lock()
+				results = append(results, result)
+				mutex.Unlock()
+			}(filePath, file, pkg.Fset, info, typeIssues[filePath])
+		}
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// AddRule ajoute une règle de lint à l'analyseur
+func (a *ASTAnalyzer) AddRule(rule LintRule) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.rules = append(a.rules, rule)
+}
+
+// analyzeSyntax exécute les règles sur un fichier déjà chargé ; typeIssues
+// contient les erreurs de compilation rapportées pour ce fichier
+func (a *ASTAnalyzer) analyzeSyntax(filePath string, src *ast.File, fset *token.FileSet, info *types.Info, typeIssues []StaticIssue, startTime time.Time) *AnalysisResult {
+	a.mutex.RLock()
+	rules := append([]LintRule(nil), a.rules...)
+	a.mutex.RUnlock()
+
 	// Exécuter les règles de lint
-	issues := make([]StaticIssue, 0)
-	for _, rule := range a.rules {
+	issues := append(make([]StaticIssue, 0, len(typeIssues)), typeIssues...)
+	for _, rule := range rules {
 		if a.isRuleEnabled(rule.Name()) {
-			ruleIssues := rule.Check(src, a.fileSet, info)
+			ruleIssues := rule.Check(src, fset, info)
 			issues = append(issues, ruleIssues...)
 		}
 	}
 
 	// Calculer les métriques
-	metrics := a.calculateMetrics(src, a.fileSet)
+	metrics := a.calculateMetrics(src, fset)
 
 	// Générer les suggestions
-	suggestions := a.generateSuggestions(src, issues, a.fileSet)
+	suggestions := a.generateSuggestions(src, issues, fset)
 
 	result := &AnalysisResult{
 		FilePath:    filePath,
@@ -233,51 +308,101 @@ func (a *ASTAnalyzer) AnalyzeFile(filePath string) (*AnalysisResult, error) {
 	// Mettre à jour les statistiques
 	a.updateStatistics(result)
 
-	return result, nil
+	return result
 }
 
-// AnalyzeProject analyse tout un projet Go
-func (a *ASTAnalyzer) AnalyzeProject(projectPath string) ([]*AnalysisResult, error) {
-	// Charger le projet avec go/packages
-	cfg := &packages.Config{
-		Mode: packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
-		Dir:  projectPath,
+// loadFile charge le package contenant filePath pour obtenir les types du
+// fichier. Hors d'un module (ou si go list échoue), le fichier est parsé seul
+// et type-checké sans ses voisins : les informations sont alors partielles.
+func (a *ASTAnalyzer) loadFile(filePath string) (*token.FileSet, *ast.File, *types.Info, []StaticIssue, error) {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		cfg := &packages.Config{
+			Mode:  loadMode,
+			Dir:   filepath.Dir(absPath),
+			Fset:  a.fileSet,
+			Tests: strings.HasSuffix(absPath, "_test.go"),
+		}
+		if pkgs, err := packages.Load(cfg, "file="+absPath); err == nil {
+			for _, pkg := range pkgs {
+				for _, file := range pkg.Syntax {
+					if pkg.Fset.File(file.Pos()).Name() == absPath && pkg.TypesInfo != nil {
+						return pkg.Fset, file, pkg.TypesInfo, packageErrorIssues(pkg)[absPath], nil
+					}
+				}
+			}
+		}
 	}
 
-	pkgs, err := packages.Load(cfg, "./...")
+	src, err := parser.ParseFile(a.fileSet, filePath, nil, parser.ParseComments)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load packages: %w", err)
+		return nil, nil, nil, nil, err
 	}
+	info := newTypesInfo()
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	conf.Check(src.Name.Name, a.fileSet, []*ast.File{src}, info)
+	return a.fileSet, src, info, nil, nil
+}
 
-	a.packages = pkgs
-
-	var results []*AnalysisResult
-	var mutex sync.Mutex
-	var wg sync.WaitGroup
-
-	// Analyser chaque fichier en parallèle
-	for _, pkg := range pkgs {
-		for _, file := range pkg.GoFiles {
-			if !a.config.IncludeTests && strings.HasSuffix(file, "_test.go") {
-				continue
-			}
-
-			wg.Add(1)
-			go func(filePath string) {
-				defer wg.Done()
+// newTypesInfo crée un types.Info avec toutes les tables utilisées par les règles
+func newTypesInfo() *types.Info {
+	return &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+}
 
-				result, err := a.AnalyzeFile(filePath)
-				if err == nil {
-					mutex.Lock()
-					results = append(results, result)
-					mutex.Unlock()
-				}
-			}(file)
+// packageErrorIssues convertit les erreurs de chargement d'un package
+// (syntaxe, types) en issues, indexées par fichier
+func packageErrorIssues(pkg *packages.Package) map[string][]StaticIssue {
+	issues := make(map[string][]StaticIssue)
+	for _, pkgErr := range pkg.Errors {
+		file, line, column := splitErrorPos(pkgErr.Pos)
+		if file == "" {
+			continue
+		}
+		issueType, rule := IssueTypeType, "typecheck"
+		if pkgErr.Kind == packages.ParseError {
+			issueType, rule = IssueTypeSyntax, "syntax"
 		}
+		issues[file] = append(issues[file], StaticIssue{
+			Type:     issueType,
+			Severity: SeverityError,
+			Message:  pkgErr.Msg,
+			Line:     line,
+			Column:   column,
+			Rule:     rule,
+			Category: CategoryBugRisk,
+			Context: map[string]interface{}{
+				"package": pkg.PkgPath,
+			},
+		})
 	}
+	return issues
+}
 
-	wg.Wait()
-	return results, nil
+// splitErrorPos découpe une position "fichier:ligne:colonne" de go/packages
+func splitErrorPos(pos string) (string, int, int) {
+	parts := strings.Split(pos, ":")
+	numbers := make([]int, 0, 2)
+	for len(parts) > 1 && len(numbers) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+	if len(numbers) == 0 {
+		return "", 0, 0
+	}
+	if len(numbers) == 1 {
+		numbers = append(numbers, 0)
+	}
+	return strings.Join(parts, ":"), numbers[0], numbers[1]
 }
 
 // calculateMetrics calcule les métriques de qualité du code
@@ -526,8 +651,7 @@ func (a *ASTAnalyzer) isRuleEnabled(ruleName string) bool {
 
 // loadDefaultRules charge les règles de lint par défaut
 func (a *ASTAnalyzer) loadDefaultRules() {
-	// Ajouter les règles par défaut ici
-	// Ces règles seront implémentées dans les micro-étapes suivantes
+	a.rules = append(a.rules, NewCustomLintRules().GetRules()...)
 }
 
 // GetStatistics retourne les statistiques actuelles
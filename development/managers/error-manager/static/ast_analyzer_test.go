@@ -0,0 +1,94 @@
+package static
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+)
+
+// typedRule relève le type des identifiants nommés "target"
+type typedRule struct{}
+
+func (r *typedRule) Name() string            { return "typed" }
+func (r *typedRule) Description() string     { return "report the type of target" }
+func (r *typedRule) Category() IssueCategory { return CategoryBugRisk }
+func (r *typedRule) Severity() IssueSeverity { return SeverityInfo }
+
+func (r *typedRule) Check(file *ast.File, fset *token.FileSet, info *types.Info) []StaticIssue {
+	var issues []StaticIssue
+	for ident, obj := range info.Defs {
+		if ident.Name == "target" && obj != nil {
+			issues = append(issues, StaticIssue{Rule: r.Name(), Message: obj.Type().String(), Line: fset.Position(ident.Pos()).Line})
+		}
+	}
+	return issues
+}
+
+func TestAnalyzeProjectWithTypes(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "go.mod", "module example.com/sample\n\ngo 1.21\n")
+	writeSource(t, root, "util/util.go", "package util\n\nfunc Pair() (int, error) { return 0, nil }\n")
+	writeSource(t, root, "main.go", `package main
+
+import (
+	"os"
+
+	"example.com/sample/util"
+)
+
+var target = util.Pair
+
+func main() {
+	os.Remove("tmp")
+	var n int = "text"
+	_ = n
+}
+`)
+
+	analyzer := NewASTAnalyzer(AnalyzerConfig{EnabledRules: []string{"typed", "error_handling"}})
+	analyzer.AddRule(&typedRule{})
+
+	results, err := analyzer.AnalyzeProject(root)
+	if err != nil {
+		t.Fatalf("AnalyzeProject() error = %v", err)
+	}
+	var main *AnalysisResult
+	for _, result := range results {
+		if filepath.Base(result.FilePath) == "main.go" {
+			main = result
+		}
+	}
+	if main == nil {
+		t.Fatalf("main.go not analyzed: %+v", results)
+	}
+
+	var typed, unhandled, typeErrors int
+	for _, issue := range main.Issues {
+		switch {
+		case issue.Rule == "typed" && issue.Message == "func() (int, error)":
+			typed++
+		case issue.Rule == "error_handling" && issue.Line == 12:
+			unhandled++
+		case issue.Rule == "typecheck" && issue.Type == IssueTypeType && issue.Line == 13:
+			typeErrors++
+		}
+	}
+	if typed != 1 || unhandled != 1 || typeErrors != 1 {
+		t.Errorf("typed=%d unhandled=%d typecheck=%d, issues: %+v", typed, unhandled, typeErrors, main.Issues)
+	}
+
+	// Un fichier isolé est chargé avec son package
+	result, err := analyzer.AnalyzeFile(filepath.Join(root, "main.go"))
+	if err != nil {
+		t.Fatalf("AnalyzeFile() error = %v", err)
+	}
+	found := false
+	for _, issue := range result.Issues {
+		found = found || (issue.Rule == "typed" && issue.Message == "func() (int, error)")
+	}
+	if !found {
+		t.Errorf("AnalyzeFile() did not see package types: %+v", result.Issues)
+	}
+}
@@ -566,10 +566,11 @@ func (r *ErrorHandlingRule) Check(file *ast.File, fset *token.FileSet, info *typ
 					},
 				})
 			}
-		case *ast.CallExpr:
-			// Vérifier les appels de fonction sans gestion d'erreur
-			if r.returnsError(n, info) && !r.isErrorHandled(n, node) {
-				pos := fset.Position(n.Pos())
+		case *ast.ExprStmt:
+			// Un appel utilisé comme instruction perd son erreur
+			call, ok := n.X.(*ast.CallExpr)
+			if ok && r.returnsError(call, info) && !r.isErrorHandled(call, node) {
+				pos := fset.Position(call.Pos())
 				issues = append(issues, StaticIssue{
 					Type:     IssueTypeSecurity,
 					Severity: SeverityWarning,
@@ -600,8 +601,21 @@ func (r *ErrorHandlingRule) hasIgnoredError(assign *ast.AssignStmt) bool {
 }
 
 func (r *ErrorHandlingRule) returnsError(call *ast.CallExpr, info *types.Info) bool {
-	// Cette fonction nécessiterait une analyse plus approfondie des types
-	// Pour simplifier, on peut chercher des patterns communs
+	// Avec les informations de types, le dernier résultat de l'appel fait foi
+	if info != nil && info.Types != nil {
+		if tv, ok := info.Types[call]; ok && tv.Type != nil {
+			result := tv.Type
+			if tuple, ok := result.(*types.Tuple); ok {
+				if tuple.Len() == 0 {
+					return false
+				}
+				result = tuple.At(tuple.Len() - 1).Type()
+			}
+			return types.Identical(result, types.Universe.Lookup("error").Type())
+		}
+	}
+
+	// Sans types (fichier non chargé), on cherche des patterns communs
 	if fun, ok := call.Fun.(*ast.Ident); ok {
 		// Fonctions courantes qui retournent des erreurs
 		errorFunctions := []string{"Open", "Create", "Marshal", "Unmarshal", "Parse", "Read", "Write"}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -61,6 +62,7 @@ type UnifiedReport struct {
 	ConsolidatedIssues []ConsolidatedIssue            `json:"consolidated_issues"`
 	QualityMetrics     QualityMetrics                 `json:"quality_metrics"`
 	Summary            ReportSummary                  `json:"summary"`
+	BaselineSuppressed int                            `json:"baseline_suppressed,omitempty"` // Issues ignorées car présentes dans la baseline
 }
 
 // ConsolidatedIssue représente une issue consolidée de plusieurs outils
@@ -119,6 +121,7 @@ type ExternalToolsManager struct {
 	projectPath string
 	outputDir   string
 	timeout     time.Duration
+	baseline    *Baseline // Mode baseline : seules les nouvelles issues sont rapportées
 }
 
 // NewExternalToolsManager crée un nouveau gestionnaire d'outils externes
@@ -235,6 +238,11 @@ func (etm *ExternalToolsManager) RunAllTools(ctx context.Context) (*UnifiedRepor
 
 	report.TotalDuration = time.Since(startTime)
 
+	// En mode baseline, retirer les issues déjà connues
+	if etm.baseline != nil {
+		etm.applyBaseline(report)
+	}
+
 	// Consolider les résultats
 	err := etm.consolidateResults(report)
 	if err != nil {
@@ -341,6 +349,10 @@ func (etm *ExternalToolsManager) parseToolOutput(tool *ExternalTool, output stri
 		return []ExternalIssue{}, nil
 	}
 
+	if tool.OutputFormat == "sarif" {
+		return etm.parseSARIFOutput(tool, output)
+	}
+
 	switch tool.Name {
 	case "golangci-lint":
 		return etm.parseGolangciLintOutput(output)
@@ -554,6 +566,26 @@ func (etm *ExternalToolsManager) parseGosecOutput(output string) ([]ExternalIssu
 	return issues, nil
 }
 
+// parseSARIFOutput parse la sortie d'un outil au format SARIF
+func (etm *ExternalToolsManager) parseSARIFOutput(tool *ExternalTool, output string) ([]ExternalIssue, error) {
+	log, err := ReadSARIF(strings.NewReader(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s SARIF output: %w", tool.Name, err)
+	}
+
+	findings := log.Findings()
+	issues := make([]ExternalIssue, 0, len(findings))
+	for _, finding := range findings {
+		issue := finding.ExternalIssue()
+		issue.Source = tool.Name
+		if issue.Category == "" {
+			issue.Category = etm.categorizeLinterRule(issue.Rule)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
 // parseGenericOutput parse une sortie générique
 func (etm *ExternalToolsManager) parseGenericOutput(output string) ([]ExternalIssue, error) {
 	// Implémentation basique pour formats non reconnus
@@ -865,6 +897,66 @@ func (etm *ExternalToolsManager) categorizeLinterRule(rule string) string {
 	return "maintenance"
 }
 
+// SetBaseline active le mode baseline : RunAllTools ne rapporte que les issues
+// absentes de la baseline. nil désactive le mode baseline.
+func (etm *ExternalToolsManager) SetBaseline(baseline *Baseline) {
+	etm.baseline = baseline
+}
+
+// issueRef situe une issue dans les résultats d'un rapport
+type issueRef struct {
+	result *ExternalToolResult
+	index  int
+}
+
+// reportFindings convertit les issues de tous les outils en findings
+// empreintés ; refs[i] situe l'issue d'origine de findings[i]
+func (etm *ExternalToolsManager) reportFindings(report *UnifiedReport) ([]Finding, []issueRef) {
+	names := make([]string, 0, len(report.ToolResults))
+	for name := range report.ToolResults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	var refs []issueRef
+	for _, name := range names {
+		result := report.ToolResults[name]
+		for i, issue := range result.Issues {
+			findings = append(findings, FindingFromExternalIssue(issue))
+			refs = append(refs, issueRef{result: result, index: i})
+		}
+	}
+	AssignFingerprints(findings, etm.projectPath)
+	return findings, refs
+}
+
+// applyBaseline retire des résultats les issues présentes dans la baseline
+func (etm *ExternalToolsManager) applyBaseline(report *UnifiedReport) {
+	findings, refs := etm.reportFindings(report)
+	known := make(map[issueRef]bool)
+	for i, finding := range findings {
+		if etm.baseline.Contains(finding) {
+			known[refs[i]] = true
+		}
+	}
+	if len(known) == 0 {
+		return
+	}
+
+	for _, result := range report.ToolResults {
+		kept := make([]ExternalIssue, 0, len(result.Issues))
+		for i, issue := range result.Issues {
+			if known[issueRef{result: result, index: i}] {
+				report.BaselineSuppressed++
+				continue
+			}
+			kept = append(kept, issue)
+		}
+		result.Issues = kept
+	}
+}
+
 // SaveReport sauvegarde le rapport dans un fichier
 func (etm *ExternalToolsManager) SaveReport(report *UnifiedReport, format string) error {
 	if err := os.MkdirAll(etm.outputDir, 0755); err != nil {
@@ -878,6 +970,8 @@ func (etm *ExternalToolsManager) SaveReport(report *UnifiedReport, format string
 		return etm.saveJSONReport(report, timestamp)
 	case "html":
 		return etm.saveHTMLReport(report, timestamp)
+	case "sarif":
+		return etm.saveSARIFReport(report, timestamp)
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
@@ -895,6 +989,13 @@ func (etm *ExternalToolsManager) saveJSONReport(report *UnifiedReport, timestamp
 	return os.WriteFile(filename, data, 0644)
 }
 
+// saveSARIFReport sauvegarde les issues de chaque outil en SARIF 2.1.0
+func (etm *ExternalToolsManager) saveSARIFReport(report *UnifiedReport, timestamp string) error {
+	filename := filepath.Join(etm.outputDir, fmt.Sprintf("unified-report-%s.sarif", timestamp))
+	findings, _ := etm.reportFindings(report)
+	return SaveSARIFFile(filename, NewSARIFLog(findings))
+}
+
 // saveHTMLReport sauvegarde le rapport en HTML
 func (etm *ExternalToolsManager) saveHTMLReport(report *UnifiedReport, timestamp string) error {
 	filename := filepath.Join(etm.outputDir, fmt.Sprintf("unified-report-%s.html", timestamp))
@@ -0,0 +1,246 @@
+// Findings unifiés - analyse AST, outils externes et SARIF
+// Plan de développement v42 - Gestionnaire d'erreurs avancé
+package static
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Finding est une issue normalisée, quelle que soit sa source (ASTAnalyzer,
+// outil externe ou fichier SARIF), avec ses suggestions de correction
+type Finding struct {
+	Fingerprint string          `json:"fingerprint"`
+	RuleID      string          `json:"rule_id"`
+	Message     string          `json:"message"`
+	Severity    IssueSeverity   `json:"severity"`
+	Category    IssueCategory   `json:"category"`
+	File        string          `json:"file"`
+	Line        int             `json:"line"`
+	Column      int             `json:"column"`
+	Source      string          `json:"source"`
+	Confidence  float64         `json:"confidence"`
+	Fixes       []FixSuggestion `json:"fixes,omitempty"`
+}
+
+// FindingsFromAnalysis convertit un résultat de l'ASTAnalyzer ; les
+// suggestions sont rattachées aux issues de la même ligne
+func FindingsFromAnalysis(result *AnalysisResult) []Finding {
+	findings := make([]Finding, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		finding := Finding{
+			RuleID:     issue.Rule,
+			Message:    issue.Message,
+			Severity:   issue.Severity,
+			Category:   issue.Category,
+			File:       result.FilePath,
+			Line:       issue.Line,
+			Column:     issue.Column,
+			Source:     "ast-analyzer",
+			Confidence: 1.0,
+		}
+		if finding.RuleID == "" {
+			finding.RuleID = string(issue.Type)
+		}
+		for _, suggestion := range result.Suggestions {
+			if suggestion.LineStart <= issue.Line && issue.Line <= suggestion.LineEnd {
+				finding.Fixes = append(finding.Fixes, suggestion)
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// FindingFromExternalIssue convertit une issue d'outil externe
+func FindingFromExternalIssue(issue ExternalIssue) Finding {
+	finding := Finding{
+		RuleID:     issue.Rule,
+		Message:    issue.Message,
+		Severity:   severityFromExternal(issue.Severity),
+		Category:   IssueCategory(issue.Category),
+		File:       issue.File,
+		Line:       issue.Line,
+		Column:     issue.Column,
+		Source:     issue.Source,
+		Confidence: issue.Confidence,
+	}
+	if issue.Suggestion != "" {
+		finding.Fixes = []FixSuggestion{{
+			Type:        FixTypeManual,
+			Title:       issue.Suggestion,
+			Description: issue.Suggestion,
+			Confidence:  issue.Confidence,
+			LineStart:   issue.Line,
+			LineEnd:     issue.Line,
+			Impact:      ImpactLow,
+		}}
+	}
+	return finding
+}
+
+// ExternalIssue convertit le finding en issue d'outil externe
+func (f Finding) ExternalIssue() ExternalIssue {
+	issue := ExternalIssue{
+		File:       f.File,
+		Line:       f.Line,
+		Column:     f.Column,
+		Rule:       f.RuleID,
+		Message:    f.Message,
+		Severity:   externalSeverity(f.Severity),
+		Category:   string(f.Category),
+		Source:     f.Source,
+		Confidence: f.Confidence,
+	}
+	if len(f.Fixes) > 0 {
+		issue.Suggestion = f.Fixes[0].Title
+	}
+	return issue
+}
+
+// severityFromExternal convertit les sévérités normalisées des outils externes
+func severityFromExternal(severity string) IssueSeverity {
+	switch severity {
+	case "critical", "high", "error":
+		return SeverityError
+	case "medium", "warning":
+		return SeverityWarning
+	case "hint":
+		return SeverityHint
+	default:
+		return SeverityInfo
+	}
+}
+
+// externalSeverity est l'inverse de severityFromExternal
+func externalSeverity(severity IssueSeverity) string {
+	switch severity {
+	case SeverityError:
+		return "high"
+	case SeverityWarning:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// volatileTokens sont retirés des messages avant empreinte : les nombres
+// ("complexity 12") changent sans que l'issue change
+var volatileTokens = regexp.MustCompile(`\d+`)
+
+// AssignFingerprints calcule l'empreinte des findings qui n'en ont pas.
+//
+// L'empreinte combine la règle, le fichier (relatif à root), le message sans
+// ses nombres et le contenu de la ligne concernée plutôt que son numéro : une
+// issue garde son empreinte quand du code est ajouté au-dessus d'elle. Les
+// occurrences identiques d'un même fichier sont numérotées dans l'ordre des
+// lignes ; les doublons exacts partagent la même empreinte.
+func AssignFingerprints(findings []Finding, root string) {
+	lines := newSourceLines(root)
+	bases := make([]string, len(findings))
+	order := make([]int, 0, len(findings))
+
+	for i := range findings {
+		findings[i].File = relativeFindingPath(root, findings[i].File)
+		if findings[i].Fingerprint != "" {
+			continue
+		}
+		bases[i] = baseFingerprint(findings[i], lines.hash(findings[i].File, findings[i].Line))
+		order = append(order, i)
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		fa, fb := findings[order[a]], findings[order[b]]
+		if bases[order[a]] != bases[order[b]] {
+			return bases[order[a]] < bases[order[b]]
+		}
+		if fa.Line != fb.Line {
+			return fa.Line < fb.Line
+		}
+		return fa.Column < fb.Column
+	})
+
+	// Un même finding rapporté deux fois à la même position garde la même empreinte
+	occurrences := make(map[string]int)
+	positions := make(map[string]int)
+	for _, i := range order {
+		position := fmt.Sprintf("%s@%d:%d", bases[i], findings[i].Line, findings[i].Column)
+		occurrence, exists := positions[position]
+		if !exists {
+			occurrences[bases[i]]++
+			occurrence = occurrences[bases[i]]
+			positions[position] = occurrence
+		}
+		findings[i].Fingerprint = fmt.Sprintf("%s:%d", bases[i], occurrence)
+	}
+}
+
+// baseFingerprint calcule l'empreinte sans le numéro d'occurrence
+func baseFingerprint(f Finding, lineHash string) string {
+	message := strings.Join(strings.Fields(volatileTokens.ReplaceAllString(f.Message, "N")), " ")
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.RuleID, filepath.ToSlash(f.File), message, lineHash}, "\x00")))
+	return hex.EncodeToString(sum[:12])
+}
+
+// relativeFindingPath exprime un chemin relativement à root quand c'est possible
+func relativeFindingPath(root, path string) string {
+	if root != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// sourceLines lit les fichiers sources à la demande pour l'empreinte des lignes
+type sourceLines struct {
+	root  string
+	files map[string][]string
+}
+
+func newSourceLines(root string) *sourceLines {
+	return &sourceLines{root: root, files: make(map[string][]string)}
+}
+
+// hash retourne l'empreinte du contenu d'une ligne, ou son numéro si le
+// fichier n'est pas lisible
+func (s *sourceLines) hash(file string, line int) string {
+	content, exists := s.files[file]
+	if !exists {
+		content = s.read(file)
+		s.files[file] = content
+	}
+	if line < 1 || line > len(content) {
+		return fmt.Sprintf("L%d", line)
+	}
+	normalized := strings.Join(strings.Fields(content[line-1]), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *sourceLines) read(file string) []string {
+	path := filepath.FromSlash(file)
+	if !filepath.IsAbs(path) && s.root != "" {
+		path = filepath.Join(s.root, path)
+	}
+	handle, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer handle.Close()
+
+	var content []string
+	scanner := bufio.NewScanner(handle)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		content = append(content, scanner.Text())
+	}
+	return content
+}
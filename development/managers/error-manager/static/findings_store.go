@@ -0,0 +1,330 @@
+// Base de findings et baseline
+// Plan de développement v42 - Gestionnaire d'erreurs avancé
+package static
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FindingStatus est l'état d'un finding suivi
+type FindingStatus string
+
+const (
+	FindingOpen  FindingStatus = "open"
+	FindingFixed FindingStatus = "fixed"
+)
+
+// findingsStoreVersion est la version du format de fichier
+const findingsStoreVersion = 1
+
+// TrackedFinding est un finding suivi d'un run à l'autre
+type TrackedFinding struct {
+	Finding
+	Status      FindingStatus `json:"status"`
+	FirstSeen   string        `json:"first_seen"`
+	FirstSeenAt time.Time     `json:"first_seen_at"`
+	LastSeen    string        `json:"last_seen"`
+	LastSeenAt  time.Time     `json:"last_seen_at"`
+	FixedIn     string        `json:"fixed_in,omitempty"`
+	FixedAt     *time.Time    `json:"fixed_at,omitempty"`
+	Reopened    int           `json:"reopened,omitempty"`
+}
+
+// RunInfo résume un run enregistré
+type RunInfo struct {
+	ID         string    `json:"id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Total      int       `json:"total"`
+	New        int       `json:"new"`
+	Fixed      int       `json:"fixed"`
+}
+
+// RunSummary décrit les changements apportés par un run
+type RunSummary struct {
+	Run      string           `json:"run"`
+	New      []TrackedFinding `json:"new"`
+	Existing []TrackedFinding `json:"existing"`
+	Reopened []TrackedFinding `json:"reopened"`
+	Fixed    []TrackedFinding `json:"fixed"`
+}
+
+// findingsFile est le contenu persisté de la base
+type findingsFile struct {
+	Version  int                        `json:"version"`
+	Runs     []RunInfo                  `json:"runs"`
+	Findings map[string]*TrackedFinding `json:"findings"`
+}
+
+// FindingsStore conserve les findings de tous les runs, dédupliqués par
+// empreinte : chaque finding garde le run où il est apparu et celui où il a
+// été corrigé
+type FindingsStore struct {
+	path  string
+	root  string
+	mutex sync.RWMutex
+	data  findingsFile
+}
+
+// NewFindingsStore ouvre (ou crée) la base stockée dans path ; root est la
+// racine du projet, utilisée pour les empreintes
+func NewFindingsStore(path, root string) (*FindingsStore, error) {
+	store := &FindingsStore{
+		path: path,
+		root: root,
+		data: findingsFile{Version: findingsStoreVersion, Findings: make(map[string]*TrackedFinding)},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read findings store: %w", err)
+	}
+	if err := json.Unmarshal(content, &store.data); err != nil {
+		return nil, fmt.Errorf("failed to parse findings store %s: %w", path, err)
+	}
+	if store.data.Version != findingsStoreVersion {
+		return nil, fmt.Errorf("unsupported findings store version %d", store.data.Version)
+	}
+	if store.data.Findings == nil {
+		store.data.Findings = make(map[string]*TrackedFinding)
+	}
+	return store, nil
+}
+
+// Record enregistre les findings d'un run complet : les nouveaux sont ajoutés,
+// les findings ouverts absents du run sont marqués corrigés, et les findings
+// corrigés qui réapparaissent sont rouverts
+func (s *FindingsStore) Record(run string, findings []Finding) (*RunSummary, error) {
+	if run == "" {
+		return nil, errors.New("run identifier is required")
+	}
+	findings = append([]Finding(nil), findings...)
+	AssignFingerprints(findings, s.root)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	summary := &RunSummary{Run: run}
+	seen := make(map[string]bool, len(findings))
+
+	for _, finding := range findings {
+		if seen[finding.Fingerprint] {
+			continue
+		}
+		seen[finding.Fingerprint] = true
+
+		tracked, exists := s.data.Findings[finding.Fingerprint]
+		if !exists {
+			tracked = &TrackedFinding{Status: FindingOpen, FirstSeen: run, FirstSeenAt: now}
+			s.data.Findings[finding.Fingerprint] = tracked
+		}
+		reopened := tracked.Status == FindingFixed
+		if reopened {
+			tracked.Status = FindingOpen
+			tracked.FixedIn = ""
+			tracked.FixedAt = nil
+			tracked.Reopened++
+		}
+		// La position et le message suivent la dernière version du code
+		tracked.Finding = finding
+		tracked.LastSeen = run
+		tracked.LastSeenAt = now
+
+		switch {
+		case !exists:
+			summary.New = append(summary.New, *tracked)
+		case reopened:
+			summary.Reopened = append(summary.Reopened, *tracked)
+		default:
+			summary.Existing = append(summary.Existing, *tracked)
+		}
+	}
+
+	for fingerprint, tracked := range s.data.Findings {
+		if tracked.Status != FindingOpen || seen[fingerprint] {
+			continue
+		}
+		fixedAt := now
+		tracked.Status = FindingFixed
+		tracked.FixedIn = run
+		tracked.FixedAt = &fixedAt
+		summary.Fixed = append(summary.Fixed, *tracked)
+	}
+
+	s.data.Runs = append(s.data.Runs, RunInfo{
+		ID:         run,
+		RecordedAt: now,
+		Total:      len(seen),
+		New:        len(summary.New),
+		Fixed:      len(summary.Fixed),
+	})
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Get retourne un finding par empreinte
+func (s *FindingsStore) Get(fingerprint string) (TrackedFinding, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	tracked, exists := s.data.Findings[fingerprint]
+	if !exists {
+		return TrackedFinding{}, false
+	}
+	return *tracked, true
+}
+
+// List retourne les findings d'un état (tous si status est vide), triés par fichier et ligne
+func (s *FindingsStore) List(status FindingStatus) []TrackedFinding {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]TrackedFinding, 0, len(s.data.Findings))
+	for _, tracked := range s.data.Findings {
+		if status == "" || tracked.Status == status {
+			result = append(result, *tracked)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Fingerprint < result[j].Fingerprint
+	})
+	return result
+}
+
+// Runs retourne l'historique des runs
+func (s *FindingsStore) Runs() []RunInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]RunInfo(nil), s.data.Runs...)
+}
+
+// Baseline capture les findings ouverts
+func (s *FindingsStore) Baseline() *Baseline {
+	open := s.List(FindingOpen)
+	findings := make([]Finding, 0, len(open))
+	for _, tracked := range open {
+		findings = append(findings, tracked.Finding)
+	}
+	return NewBaseline(findings)
+}
+
+// ExportSARIF exporte les findings ouverts en SARIF
+func (s *FindingsStore) ExportSARIF() *SARIFLog {
+	open := s.List(FindingOpen)
+	findings := make([]Finding, 0, len(open))
+	for _, tracked := range open {
+		findings = append(findings, tracked.Finding)
+	}
+	return NewSARIFLog(findings)
+}
+
+// save écrit la base de façon atomique (mutex tenu)
+func (s *FindingsStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	return writeJSONAtomic(s.path, s.data)
+}
+
+// BaselineEntry décrit un finding accepté dans la baseline
+type BaselineEntry struct {
+	RuleID string `json:"rule_id"`
+	File   string `json:"file"`
+}
+
+// Baseline est un instantané des findings acceptés : en mode baseline, seuls
+// les findings absents de l'instantané sont rapportés
+type Baseline struct {
+	CreatedAt    time.Time                `json:"created_at"`
+	Fingerprints map[string]BaselineEntry `json:"fingerprints"`
+}
+
+// NewBaseline crée une baseline à partir de findings déjà empreintés
+func NewBaseline(findings []Finding) *Baseline {
+	baseline := &Baseline{CreatedAt: time.Now(), Fingerprints: make(map[string]BaselineEntry, len(findings))}
+	for _, finding := range findings {
+		baseline.Fingerprints[finding.Fingerprint] = BaselineEntry{RuleID: finding.RuleID, File: finding.File}
+	}
+	return baseline
+}
+
+// LoadBaseline lit une baseline
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Fingerprints == nil {
+		baseline.Fingerprints = make(map[string]BaselineEntry)
+	}
+	return &baseline, nil
+}
+
+// Save écrit la baseline
+func (b *Baseline) Save(path string) error {
+	return writeJSONAtomic(path, b)
+}
+
+// Contains indique si un finding fait partie de la baseline
+func (b *Baseline) Contains(finding Finding) bool {
+	_, exists := b.Fingerprints[finding.Fingerprint]
+	return exists
+}
+
+// Filter retourne les findings absents de la baseline ; les empreintes
+// manquantes sont calculées par rapport à root
+func (b *Baseline) Filter(findings []Finding, root string) []Finding {
+	findings = append([]Finding(nil), findings...)
+	AssignFingerprints(findings, root)
+
+	result := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		if !b.Contains(finding) {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// writeJSONAtomic écrit un fichier JSON via un fichier temporaire et un renommage
+func writeJSONAtomic(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
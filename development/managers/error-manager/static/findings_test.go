@@ -0,0 +1,192 @@
+package static
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleSARIF = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "gosec", "rules": [{"id": "G101", "properties": {"category": "security"}}]}},
+    "results": [{
+      "ruleId": "G101",
+      "ruleIndex": 0,
+      "level": "error",
+      "message": {"text": "Potential hardcoded credentials"},
+      "locations": [{"physicalLocation": {
+        "artifactLocation": {"uri": "config/secrets.go", "uriBaseId": "%SRCROOT%"},
+        "region": {"startLine": 3, "startColumn": 2}
+      }}],
+      "fixes": [{
+        "description": {"text": "Read the password from the vault"},
+        "artifactChanges": [{
+          "artifactLocation": {"uri": "config/secrets.go"},
+          "replacements": [{"deletedRegion": {"startLine": 3}, "insertedContent": {"text": "password := vault.Get()"}}]
+        }]
+      }]
+    }]
+  }]
+}`
+
+func writeSource(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSARIFRoundTrip(t *testing.T) {
+	log, err := ReadSARIF(strings.NewReader(sampleSARIF))
+	if err != nil {
+		t.Fatalf("ReadSARIF() error = %v", err)
+	}
+	findings := log.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	finding := findings[0]
+	if finding.RuleID != "G101" || finding.Severity != SeverityError || finding.Category != CategorySecurity {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+	if finding.File != filepath.FromSlash("config/secrets.go") || finding.Line != 3 || finding.Column != 2 {
+		t.Errorf("unexpected location %s:%d:%d", finding.File, finding.Line, finding.Column)
+	}
+	if len(finding.Fixes) != 1 || finding.Fixes[0].FixedCode != "password := vault.Get()" || !finding.Fixes[0].Automated {
+		t.Errorf("unexpected fixes %+v", finding.Fixes)
+	}
+
+	// Écriture puis relecture : le finding et son empreinte sont conservés
+	AssignFingerprints(findings, "")
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, NewSARIFLog(findings)); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	reread, err := ReadSARIF(&buf)
+	if err != nil {
+		t.Fatalf("ReadSARIF() error = %v", err)
+	}
+	again := reread.Findings()
+	if len(again) != 1 || again[0].Fingerprint != findings[0].Fingerprint || again[0].Message != finding.Message {
+		t.Errorf("round trip changed the finding: %+v", again)
+	}
+
+	if _, err := ReadSARIF(strings.NewReader(`{"version": "1.0.0", "runs": []}`)); err == nil {
+		t.Error("expected an error for an unsupported SARIF version")
+	}
+}
+
+func TestFingerprintsSurviveLineShifts(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "main.go", "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n")
+	before := []Finding{{RuleID: "no-panic", Message: "panic in function with complexity 3", File: filepath.Join(root, "main.go"), Line: 4}}
+	AssignFingerprints(before, root)
+
+	// Deux lignes ajoutées au-dessus et un nombre différent dans le message
+	writeSource(t, root, "main.go", "package main\n\nimport \"os\"\n\nfunc main() {\n\tpanic(\"boom\")\n}\n")
+	after := []Finding{{RuleID: "no-panic", Message: "panic in function with complexity 4", File: "main.go", Line: 6}}
+	AssignFingerprints(after, root)
+
+	if before[0].Fingerprint != after[0].Fingerprint {
+		t.Errorf("fingerprint changed after a line shift: %s != %s", before[0].Fingerprint, after[0].Fingerprint)
+	}
+	if before[0].File != "main.go" {
+		t.Errorf("File = %q, want path relative to the root", before[0].File)
+	}
+}
+
+func TestFindingsStoreLifecycle(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "a.go", "package a\n\nvar x = 1\nvar y = 2\n")
+	storePath := filepath.Join(t.TempDir(), "findings.json")
+	store, err := NewFindingsStore(storePath, root)
+	if err != nil {
+		t.Fatalf("NewFindingsStore() error = %v", err)
+	}
+
+	x := Finding{RuleID: "unused", Message: "x is unused", File: "a.go", Line: 3, Source: "staticcheck"}
+	y := Finding{RuleID: "unused", Message: "y is unused", File: "a.go", Line: 4, Source: "staticcheck"}
+
+	summary, err := store.Record("run-1", []Finding{x, y, x})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if len(summary.New) != 2 {
+		t.Fatalf("run-1: got %d new findings, want 2 (duplicates merged)", len(summary.New))
+	}
+
+	summary, err = store.Record("run-2", []Finding{x})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if len(summary.Existing) != 1 || len(summary.Fixed) != 1 || summary.Fixed[0].Message != "y is unused" {
+		t.Fatalf("run-2: unexpected summary %+v", summary)
+	}
+
+	// La base est persistée
+	reopened, err := NewFindingsStore(storePath, root)
+	if err != nil {
+		t.Fatalf("NewFindingsStore() error = %v", err)
+	}
+	fixed := reopened.List(FindingFixed)
+	if len(fixed) != 1 || fixed[0].FirstSeen != "run-1" || fixed[0].FixedIn != "run-2" {
+		t.Fatalf("unexpected fixed findings %+v", fixed)
+	}
+
+	summary, err = reopened.Record("run-3", []Finding{x, y})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if len(summary.Reopened) != 1 || summary.Reopened[0].Reopened != 1 || summary.Reopened[0].FixedIn != "" {
+		t.Errorf("run-3: unexpected summary %+v", summary)
+	}
+	if runs := reopened.Runs(); len(runs) != 3 {
+		t.Errorf("got %d runs, want 3", len(runs))
+	}
+}
+
+func TestBaselineReportsOnlyNewFindings(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "a.go", "package a\n\nvar x = 1\nvar y = 2\n")
+	existing := []Finding{{RuleID: "unused", Message: "x is unused", File: "a.go", Line: 3}}
+	AssignFingerprints(existing, root)
+
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+	if err := NewBaseline(existing).Save(baselinePath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	baseline, err := LoadBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+
+	current := []Finding{
+		{RuleID: "unused", Message: "x is unused", File: "a.go", Line: 3},
+		{RuleID: "unused", Message: "y is unused", File: "a.go", Line: 4},
+	}
+	newFindings := baseline.Filter(current, root)
+	if len(newFindings) != 1 || newFindings[0].Message != "y is unused" {
+		t.Errorf("Filter() = %+v, want only the y finding", newFindings)
+	}
+
+	// Le mode baseline de l'ExternalToolsManager retire les issues connues
+	etm := NewExternalToolsManager(root, t.TempDir())
+	etm.SetBaseline(baseline)
+	report := &UnifiedReport{ToolResults: map[string]*ExternalToolResult{
+		"staticcheck": {Tool: "staticcheck", Success: true, Issues: []ExternalIssue{
+			{File: "a.go", Line: 3, Rule: "unused", Message: "x is unused", Severity: "medium"},
+			{File: "a.go", Line: 4, Rule: "unused", Message: "y is unused", Severity: "medium"},
+		}},
+	}}
+	etm.applyBaseline(report)
+	if issues := report.ToolResults["staticcheck"].Issues; len(issues) != 1 || issues[0].Line != 4 || report.BaselineSuppressed != 1 {
+		t.Errorf("applyBaseline() kept %+v, suppressed %d", issues, report.BaselineSuppressed)
+	}
+}
@@ -0,0 +1,389 @@
+// Import/export SARIF 2.1.0
+// Plan de développement v42 - Gestionnaire d'erreurs avancé
+package static
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// SARIFVersion est la version du format SARIF produite et lue
+	SARIFVersion = "2.1.0"
+	// SARIFSchema est l'URI du schéma SARIF 2.1.0
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifFingerprintKey identifie nos empreintes dans partialFingerprints
+	sarifFingerprintKey = "errorManager/v1"
+	// sarifSourceRoot est la base des URI relatives au projet
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+// SARIFLog est la racine d'un document SARIF
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema,omitempty"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun contient les résultats d'un outil
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool décrit l'outil d'analyse
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver décrit le composant principal de l'outil et ses règles
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules,omitempty"`
+}
+
+// SARIFRule décrit une règle (reportingDescriptor)
+type SARIFRule struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name,omitempty"`
+	ShortDescription *SARIFMessage          `json:"shortDescription,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFMessage est un message textuel
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult est une issue détectée
+type SARIFResult struct {
+	RuleID              string                 `json:"ruleId,omitempty"`
+	RuleIndex           *int                   `json:"ruleIndex,omitempty"`
+	Level               string                 `json:"level,omitempty"`
+	Message             SARIFMessage           `json:"message"`
+	Locations           []SARIFLocation        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Fixes               []SARIFFix             `json:"fixes,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFLocation localise une issue
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation localise une issue dans un fichier
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation référence un fichier
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SARIFRegion est une zone d'un fichier
+type SARIFRegion struct {
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *SARIFMessage `json:"snippet,omitempty"`
+}
+
+// SARIFFix propose une correction
+type SARIFFix struct {
+	Description     *SARIFMessage         `json:"description,omitempty"`
+	ArtifactChanges []SARIFArtifactChange `json:"artifactChanges"`
+}
+
+// SARIFArtifactChange regroupe les remplacements d'un fichier
+type SARIFArtifactChange struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Replacements     []SARIFReplacement    `json:"replacements"`
+}
+
+// SARIFReplacement remplace une zone par un nouveau contenu
+type SARIFReplacement struct {
+	DeletedRegion   SARIFRegion   `json:"deletedRegion"`
+	InsertedContent *SARIFMessage `json:"insertedContent,omitempty"`
+}
+
+// ReadSARIF lit un document SARIF 2.1.0
+func ReadSARIF(r io.Reader) (*SARIFLog, error) {
+	var log SARIFLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return nil, fmt.Errorf("failed to parse SARIF: %w", err)
+	}
+	if log.Version != SARIFVersion {
+		return nil, fmt.Errorf("unsupported SARIF version %q (expected %s)", log.Version, SARIFVersion)
+	}
+	return &log, nil
+}
+
+// LoadSARIFFile lit un fichier SARIF
+func LoadSARIFFile(path string) (*SARIFLog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SARIF file: %w", err)
+	}
+	defer file.Close()
+	return ReadSARIF(file)
+}
+
+// WriteSARIF écrit un document SARIF indenté
+func WriteSARIF(w io.Writer, log *SARIFLog) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF: %w", err)
+	}
+	return nil
+}
+
+// SaveSARIFFile écrit un document SARIF dans un fichier
+func SaveSARIFFile(path string, log *SARIFLog) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create SARIF file: %w", err)
+	}
+	if err := WriteSARIF(file, log); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewSARIFLog convertit des findings en document SARIF, avec un run par
+// outil source ; les chemins relatifs sont exprimés par rapport à %SRCROOT%
+func NewSARIFLog(findings []Finding) *SARIFLog {
+	bySource := make(map[string][]Finding)
+	for _, finding := range findings {
+		source := finding.Source
+		if source == "" {
+			source = "error-manager"
+		}
+		bySource[source] = append(bySource[source], finding)
+	}
+
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	log := &SARIFLog{Version: SARIFVersion, Schema: SARIFSchema, Runs: make([]SARIFRun, 0, len(sources))}
+	for _, source := range sources {
+		log.Runs = append(log.Runs, newSARIFRun(source, bySource[source]))
+	}
+	return log
+}
+
+// newSARIFRun construit le run d'un outil
+func newSARIFRun(source string, findings []Finding) SARIFRun {
+	run := SARIFRun{Tool: SARIFTool{Driver: SARIFDriver{Name: source}}, Results: make([]SARIFResult, 0, len(findings))}
+	ruleIndex := make(map[string]int)
+
+	for _, finding := range findings {
+		index, exists := ruleIndex[finding.RuleID]
+		if !exists {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[finding.RuleID] = index
+			rule := SARIFRule{ID: finding.RuleID}
+			if finding.Category != "" {
+				rule.Properties = map[string]interface{}{"category": string(finding.Category)}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		result := SARIFResult{
+			RuleID:    finding.RuleID,
+			RuleIndex: &index,
+			Level:     sarifLevel(finding.Severity),
+			Message:   SARIFMessage{Text: finding.Message},
+			Properties: map[string]interface{}{
+				"category":   string(finding.Category),
+				"confidence": finding.Confidence,
+			},
+		}
+		if finding.File != "" {
+			region := &SARIFRegion{StartLine: finding.Line, StartColumn: finding.Column}
+			if finding.Line == 0 {
+				region = nil
+			}
+			result.Locations = []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{
+				ArtifactLocation: sarifArtifact(finding.File),
+				Region:           region,
+			}}}
+		}
+		if finding.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{sarifFingerprintKey: finding.Fingerprint}
+		}
+		for _, fix := range finding.Fixes {
+			result.Fixes = append(result.Fixes, sarifFix(finding.File, fix))
+		}
+		run.Results = append(run.Results, result)
+	}
+	return run
+}
+
+// Findings convertit le document SARIF en findings
+func (l *SARIFLog) Findings() []Finding {
+	var findings []Finding
+	for _, run := range l.Runs {
+		for _, result := range run.Results {
+			findings = append(findings, findingFromSARIF(run, result))
+		}
+	}
+	return findings
+}
+
+// findingFromSARIF convertit un résultat SARIF
+func findingFromSARIF(run SARIFRun, result SARIFResult) Finding {
+	finding := Finding{
+		RuleID:      result.RuleID,
+		Message:     result.Message.Text,
+		Severity:    severityFromSARIF(result.Level),
+		Source:      run.Tool.Driver.Name,
+		Confidence:  1.0,
+		Fingerprint: result.PartialFingerprints[sarifFingerprintKey],
+	}
+
+	var rule *SARIFRule
+	if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(run.Tool.Driver.Rules) {
+		rule = &run.Tool.Driver.Rules[*result.RuleIndex]
+	} else {
+		for i := range run.Tool.Driver.Rules {
+			if run.Tool.Driver.Rules[i].ID == result.RuleID {
+				rule = &run.Tool.Driver.Rules[i]
+				break
+			}
+		}
+	}
+	if finding.RuleID == "" && rule != nil {
+		finding.RuleID = rule.ID
+	}
+
+	if category, ok := result.Properties["category"].(string); ok && category != "" {
+		finding.Category = IssueCategory(category)
+	} else if rule != nil {
+		if category, ok := rule.Properties["category"].(string); ok {
+			finding.Category = IssueCategory(category)
+		}
+	}
+	if confidence, ok := result.Properties["confidence"].(float64); ok {
+		finding.Confidence = confidence
+	}
+
+	if len(result.Locations) > 0 {
+		location := result.Locations[0].PhysicalLocation
+		finding.File = pathFromSARIFURI(location.ArtifactLocation.URI)
+		if location.Region != nil {
+			finding.Line = location.Region.StartLine
+			finding.Column = location.Region.StartColumn
+		}
+	}
+
+	for _, fix := range result.Fixes {
+		finding.Fixes = append(finding.Fixes, fixFromSARIF(fix))
+	}
+	return finding
+}
+
+// sarifFix convertit une suggestion en correction SARIF
+func sarifFix(file string, fix FixSuggestion) SARIFFix {
+	replacement := SARIFReplacement{DeletedRegion: SARIFRegion{StartLine: fix.LineStart, EndLine: fix.LineEnd}}
+	if fix.FixedCode != "" {
+		replacement.InsertedContent = &SARIFMessage{Text: fix.FixedCode}
+	}
+	return SARIFFix{
+		Description: &SARIFMessage{Text: fix.Title},
+		ArtifactChanges: []SARIFArtifactChange{{
+			ArtifactLocation: sarifArtifact(file),
+			Replacements:     []SARIFReplacement{replacement},
+		}},
+	}
+}
+
+// fixFromSARIF convertit une correction SARIF en suggestion
+func fixFromSARIF(fix SARIFFix) FixSuggestion {
+	suggestion := FixSuggestion{Type: FixTypeSuggested, Confidence: 1.0, Impact: ImpactLow}
+	if fix.Description != nil {
+		suggestion.Title = fix.Description.Text
+		suggestion.Description = fix.Description.Text
+	}
+	if len(fix.ArtifactChanges) > 0 && len(fix.ArtifactChanges[0].Replacements) > 0 {
+		replacement := fix.ArtifactChanges[0].Replacements[0]
+		suggestion.LineStart = replacement.DeletedRegion.StartLine
+		suggestion.LineEnd = replacement.DeletedRegion.EndLine
+		if suggestion.LineEnd == 0 {
+			suggestion.LineEnd = suggestion.LineStart
+		}
+		if replacement.InsertedContent != nil {
+			suggestion.FixedCode = replacement.InsertedContent.Text
+			suggestion.Automated = true
+		}
+	}
+	return suggestion
+}
+
+// sarifArtifact référence un fichier, relatif à %SRCROOT% s'il n'est pas absolu
+func sarifArtifact(file string) SARIFArtifactLocation {
+	if filepath.IsAbs(file) {
+		return SARIFArtifactLocation{URI: (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()}
+	}
+	return SARIFArtifactLocation{URI: filepath.ToSlash(file), URIBaseID: sarifSourceRoot}
+}
+
+// pathFromSARIFURI convertit une URI SARIF en chemin
+func pathFromSARIFURI(uri string) string {
+	if strings.HasPrefix(uri, "file:") {
+		if parsed, err := url.Parse(uri); err == nil {
+			return filepath.FromSlash(parsed.Path)
+		}
+	}
+	if unescaped, err := url.PathUnescape(uri); err == nil {
+		uri = unescaped
+	}
+	return filepath.FromSlash(uri)
+}
+
+// sarifLevel convertit une sévérité en niveau SARIF
+func sarifLevel(severity IssueSeverity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityHint:
+		return "none"
+	default:
+		return "note"
+	}
+}
+
+// severityFromSARIF convertit un niveau SARIF en sévérité
+func severityFromSARIF(level string) IssueSeverity {
+	switch level {
+	case "error":
+		return SeverityError
+	case "note":
+		return SeverityInfo
+	case "none":
+		return SeverityHint
+	default:
+		// "warning" est le niveau par défaut en SARIF
+		return SeverityWarning
+	}
+}
@@ -41,6 +41,8 @@ type TemporalCorrelation struct {
 	ErrorCode2    string        `json:"error_code_2"`
 	Module1       string        `json:"module_1"`
 	Module2       string        `json:"module_2"`
+	Cluster1      string        `json:"cluster_1,omitempty"`
+	Cluster2      string        `json:"cluster_2,omitempty"`
 	Correlation   float64       `json:"correlation"`
 	TimeWindow    time.Duration `json:"time_window"`
 	OccurrenceGap time.Duration `json:"occurrence_gap"`
@@ -48,12 +50,16 @@ type TemporalCorrelation struct {
 
 // PatternAnalyzer gère l'analyse des patterns d'erreurs
 type PatternAnalyzer struct {
-	db *sql.DB
+	db            *sql.DB
+	clusterConfig ClusterConfig
+	tracker       *ClusterTracker
 }
 
-// NewPatternAnalyzer crée une nouvelle instance de PatternAnalyzer
+// NewPatternAnalyzer crée une nouvelle instance de PatternAnalyzer ; le
+// cycle de vie des clusters est suivi en mémoire
 func NewPatternAnalyzer(db *sql.DB) *PatternAnalyzer {
-	return &PatternAnalyzer{db: db}
+	tracker, _ := NewClusterTracker("")
+	return &PatternAnalyzer{db: db, clusterConfig: DefaultClusterConfig(), tracker: tracker}
 }
 
 // PatternReport représente un rapport d'analyse des patterns
@@ -64,6 +70,8 @@ type PatternReport struct {
 	TopPatterns          []PatternMetrics          `json:"top_patterns"`
 	FrequencyMetrics     map[string]map[string]int `json:"frequency_metrics"`
 	TemporalCorrelations []TemporalCorrelation     `json:"temporal_correlations"`
+	Clusters             []ErrorCluster            `json:"clusters"`
+	ClusterCorrelations  []TemporalCorrelation     `json:"cluster_correlations"`
 	Recommendations      []string                  `json:"recommendations"`
 	CriticalFindings     []string                  `json:"critical_findings"`
 }
@@ -50,6 +50,17 @@ type GitWorkflowConfig struct {
 		Retries int `yaml:"retries"`
 	} `yaml:"webhooks"`
 	
+	MergeQueue struct {
+		Enabled   bool `yaml:"enabled"`
+		BatchSize int  `yaml:"batch_size"`
+		Checks    []struct {
+			Name    string `yaml:"name"`
+			Command string `yaml:"command"`
+			Timeout string `yaml:"timeout"`
+		} `yaml:"checks"`
+		RequiredStatuses []string `yaml:"required_statuses"`
+	} `yaml:"merge_queue"`
+	
 	Automation struct {
 		AutoMerge struct {
 			Enabled           bool     `yaml:"enabled"`
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/branch"
 	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/commit"
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/mergequeue"
 	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/pr"
 	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/webhook"
 	"github.com/gerivdb/email-sender-1/managers/interfaces"
@@ -34,6 +35,7 @@ type GitWorkflowManagerImpl struct {
 	commitManager  *commit.Manager
 	prManager      *pr.Manager
 	webhookManager *webhook.Manager
+	mergeQueue     *mergequeue.Queue // nil when merges are immediate
 
 	// Configuration
 	repoPath      string
@@ -108,7 +110,12 @@ func NewGitWorkflowManager(
 		return nil, fmt.Errorf("failed to create commit manager: %w", err)
 	}
 
-	manager.prManager, err = pr.NewManager(githubToken, errorManager)
+	host, err := pr.NewGitHost(gitHostConfig(config, repoPath, githubToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git host: %w", err)
+	}
+
+	manager.prManager, err = pr.NewManagerWithHost(host, errorManager)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PR manager: %w", err)
 	}
@@ -118,12 +125,110 @@ func NewGitWorkflowManager(
 		return nil, fmt.Errorf("failed to create webhook manager: %w", err)
 	}
 
+	if queueConfig, enabled := mergeQueueConfig(config, repoPath); enabled {
+		var statuses mergequeue.StatusSource
+		if len(queueConfig.RequiredStatuses) > 0 {
+			statuses = host
+		}
+		manager.mergeQueue, err = mergequeue.New(queueConfig, manager.webhookManager, statuses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create merge queue: %w", err)
+		}
+	}
+
 	manager.status = "ready"
 
 	log.Printf("GitWorkflowManager initialized successfully with ID: %s", manager.id)
 	return manager, nil
 }
 
+// gitHostConfig reads the optional "git_host" section of the configuration:
+//
+//	"git_host": {"type": "local", "path": "/srv/git/repo.git"}
+//
+// Without it pull requests go to GitHub with "github_token". The local host
+// pushes the branches of repoPath unless "push_from" says otherwise.
+func gitHostConfig(config map[string]interface{}, repoPath, githubToken string) pr.HostConfig {
+	section, _ := config["git_host"].(map[string]interface{})
+	value := func(key string) string {
+		v, _ := section[key].(string)
+		return v
+	}
+
+	hostConfig := pr.HostConfig{
+		Type:      value("type"),
+		URL:       value("url"),
+		Token:     value("token"),
+		Owner:     value("owner"),
+		Repo:      value("repo"),
+		Path:      value("path"),
+		StorePath: value("store_path"),
+		PushFrom:  value("push_from"),
+		Author:    value("author"),
+	}
+	if hostConfig.Token == "" {
+		hostConfig.Token = githubToken
+	}
+	if hostConfig.Type == "local" {
+		if hostConfig.Path == "" {
+			hostConfig.Path = repoPath
+		} else if hostConfig.PushFrom == "" {
+			hostConfig.PushFrom = repoPath
+		}
+	}
+	return hostConfig
+}
+
+// mergeQueueConfig reads the optional "merge_queue" section of the
+// configuration:
+//
+//	"merge_queue": {
+//	    "enabled": true,
+//	    "batch_size": 4,
+//	    "checks": [{"name": "tests", "command": "go test ./...", "timeout": "10m"}],
+//	    "required_statuses": ["ci/build"]
+//	}
+//
+// A check may also be a plain command string.
+func mergeQueueConfig(config map[string]interface{}, repoPath string) (mergequeue.Config, bool) {
+	section, _ := config["merge_queue"].(map[string]interface{})
+	queueConfig := mergequeue.Config{RepoPath: repoPath}
+	if enabled, _ := section["enabled"].(bool); !enabled {
+		return queueConfig, false
+	}
+
+	switch size := section["batch_size"].(type) {
+	case int:
+		queueConfig.BatchSize = size
+	case float64:
+		queueConfig.BatchSize = int(size)
+	}
+	if committer, ok := section["committer"].(string); ok {
+		queueConfig.Committer = committer
+	}
+
+	checks, _ := section["checks"].([]interface{})
+	for _, raw := range checks {
+		switch check := raw.(type) {
+		case string:
+			queueConfig.Checks = append(queueConfig.Checks, mergequeue.Check{Command: check})
+		case map[string]interface{}:
+			name, _ := check["name"].(string)
+			command, _ := check["command"].(string)
+			timeout, _ := time.ParseDuration(fmt.Sprint(check["timeout"]))
+			queueConfig.Checks = append(queueConfig.Checks, mergequeue.Check{Name: name, Command: command, Timeout: timeout})
+		}
+	}
+
+	statuses, _ := section["required_statuses"].([]interface{})
+	for _, raw := range statuses {
+		if statusContext, ok := raw.(string); ok {
+			queueConfig.RequiredStatuses = append(queueConfig.RequiredStatuses, statusContext)
+		}
+	}
+	return queueConfig, true
+}
+
 // BaseManager implementation
 func (g *GitWorkflowManagerImpl) GetID() string {
 	g.mu.RLock()
@@ -250,6 +355,12 @@ func (g *GitWorkflowManagerImpl) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if g.mergeQueue != nil {
+		if err := g.mergeQueue.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down merge queue: %v", err)
+		}
+	}
+
 	if g.webhookManager != nil {
 		if err := g.webhookManager.Shutdown(ctx); err != nil {
 			log.Printf("Error shutting down webhook manager: %v", err)
@@ -316,8 +427,40 @@ func (g *GitWorkflowManagerImpl) CreateSubBranch(ctx context.Context, subBranchN
 	return g.branchManager.CreateSubBranch(ctx, subBranchName, parentBranch, workflowType)
 }
 
+// MergeSubBranch merges immediately, or through the merge queue when one is
+// configured: the call then waits until the branch has landed or failed
 func (g *GitWorkflowManagerImpl) MergeSubBranch(ctx context.Context, subBranchName string, targetBranch string, deleteAfterMerge bool) error {
-	return g.branchManager.MergeSubBranch(ctx, subBranchName, targetBranch, deleteAfterMerge)
+	if g.mergeQueue == nil {
+		return g.branchManager.MergeSubBranch(ctx, subBranchName, targetBranch, deleteAfterMerge)
+	}
+
+	entry, err := g.EnqueueMerge(ctx, subBranchName, targetBranch, mergequeue.EnqueueOptions{DeleteAfterMerge: deleteAfterMerge})
+	if err != nil {
+		return err
+	}
+	entry, err = g.mergeQueue.Wait(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	if entry.State != mergequeue.StateMerged {
+		return fmt.Errorf("merge queue rejected %s: %s", subBranchName, entry.Error)
+	}
+	return nil
+}
+
+// EnqueueMerge adds a branch to the merge queue of its target without
+// waiting for the result; follow it through MergeQueue or the merge_queue
+// webhook events
+func (g *GitWorkflowManagerImpl) EnqueueMerge(ctx context.Context, branchName, targetBranch string, opts mergequeue.EnqueueOptions) (*mergequeue.Entry, error) {
+	if g.mergeQueue == nil {
+		return nil, fmt.Errorf("merge queue is not enabled")
+	}
+	return g.mergeQueue.Enqueue(ctx, branchName, targetBranch, opts)
+}
+
+// MergeQueue returns the merge queue, nil when merges are immediate
+func (g *GitWorkflowManagerImpl) MergeQueue() *mergequeue.Queue {
+	return g.mergeQueue
 }
 
 func (g *GitWorkflowManagerImpl) ListSubBranches(ctx context.Context, parentBranch string) ([]*interfaces.SubBranchInfo, error) {
@@ -473,6 +616,44 @@ func (f *GitWorkflowManagerFactory) ValidateConfiguration(config map[string]inte
 		}
 	}
 
+	// Validate git host type if provided
+	if section, ok := config["git_host"].(map[string]interface{}); ok {
+		hostType, _ := section["type"].(string)
+		switch hostType {
+		case "", "github", "local", "gitea", "gitlab":
+			// Valid
+		default:
+			return fmt.Errorf("invalid git host type: %s", hostType)
+		}
+		if (hostType == "gitea" || hostType == "gitlab") && section["url"] == nil {
+			return fmt.Errorf("git host %s requires an url", hostType)
+		}
+	}
+
+	// Validate merge queue checks if provided
+	if section, ok := config["merge_queue"].(map[string]interface{}); ok {
+		checks, _ := section["checks"].([]interface{})
+		for i, raw := range checks {
+			switch check := raw.(type) {
+			case string:
+				if check == "" {
+					return fmt.Errorf("merge queue check %d has no command", i)
+				}
+			case map[string]interface{}:
+				if command, _ := check["command"].(string); command == "" {
+					return fmt.Errorf("merge queue check %d has no command", i)
+				}
+				if timeout, ok := check["timeout"]; ok {
+					if _, err := time.ParseDuration(fmt.Sprint(timeout)); err != nil {
+						return fmt.Errorf("invalid timeout for merge queue check %d: %w", i, err)
+					}
+				}
+			default:
+				return fmt.Errorf("invalid merge queue check %d", i)
+			}
+		}
+	}
+
 	// Validate workflow type if provided
 	if workflowTypeStr, ok := config["workflow_type"].(string); ok {
 		workflowType := interfaces.WorkflowType(workflowTypeStr)
@@ -492,6 +673,14 @@ func (f *GitWorkflowManagerFactory) GetDefaultConfiguration() map[string]interfa
 		"repo_path":     ".",
 		"workflow_type": string(interfaces.WorkflowTypeGitFlow),
 		"github_token":  "",
+		"git_host": map[string]interface{}{
+			"type": "github",
+		},
+		"merge_queue": map[string]interface{}{
+			"enabled":    false,
+			"batch_size": 4,
+			"checks":     []interface{}{},
+		},
 		"webhook": map[string]interface{}{
 			"enabled": false,
 			"url":     "",
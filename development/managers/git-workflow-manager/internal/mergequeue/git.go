@@ -0,0 +1,240 @@
+package mergequeue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxCheckOutput is the size of the output tail kept for each check
+const maxCheckOutput = 4096
+
+// errTargetMoved is returned by land when the target no longer points at
+// the commit the candidate was built on
+var errTargetMoved = errors.New("target moved while the queue was testing")
+
+// candidate is a batch rebased onto the target in a temporary worktree
+type candidate struct {
+	worktree string
+	base     string // target commit the batch was built on
+	head     string
+	applied  []*Entry
+	tips     map[string]string // entry ID -> branch tip the entry was rebased from
+	rebased  map[string]string // entry ID -> rebased branch tip
+	cleanup  func()
+}
+
+// buildCandidate rebases each entry of the batch, in order, onto the
+// previous one, starting from the current target. Entries that conflict
+// with the queue head are failed and left out of the candidate.
+func (q *Queue) buildCandidate(ctx context.Context, target string, batch []*Entry) (*candidate, error) {
+	base, err := q.git.resolve(ctx, "refs/heads/"+target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", target, err)
+	}
+
+	dir, err := os.MkdirTemp("", "merge-queue-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	// git worktree add wants to create the directory itself
+	worktree := dir + "/worktree"
+	if _, err := q.git.run(ctx, "", "worktree", "add", "--detach", worktree, base); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	c := &candidate{
+		worktree: worktree,
+		base:     base,
+		head:     base,
+		tips:     make(map[string]string),
+		rebased:  make(map[string]string),
+		cleanup: func() {
+			q.git.run(context.Background(), "", "worktree", "remove", "--force", worktree)
+			q.git.run(context.Background(), "", "worktree", "prune")
+			os.RemoveAll(dir)
+		},
+	}
+
+	for _, entry := range batch {
+		tip, err := q.git.resolve(ctx, "refs/heads/"+entry.Branch)
+		if ctx.Err() != nil {
+			c.cleanup()
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			q.finish(ctx, entry, StateFailed, "", fmt.Sprintf("branch %s no longer exists", entry.Branch))
+			continue
+		}
+		if _, err := q.git.run(ctx, worktree, "checkout", "--quiet", "--detach", tip); err != nil {
+			c.cleanup()
+			return nil, fmt.Errorf("failed to check out %s: %w", entry.Branch, err)
+		}
+		if _, err := q.git.run(ctx, worktree, "rebase", "--quiet", c.head); err != nil {
+			if ctx.Err() != nil {
+				c.cleanup()
+				return nil, ctx.Err()
+			}
+			q.git.run(ctx, worktree, "rebase", "--abort")
+			q.finish(ctx, entry, StateFailed, "", fmt.Sprintf("%s conflicts with the queue head: %v", entry.Branch, err))
+			continue
+		}
+		head, err := q.git.run(ctx, worktree, "rev-parse", "HEAD")
+		if err != nil {
+			c.cleanup()
+			return nil, err
+		}
+
+		c.head = head
+		c.tips[entry.ID] = tip
+		c.rebased[entry.ID] = head
+		c.applied = append(c.applied, entry)
+	}
+
+	if len(c.applied) > 0 {
+		if _, err := q.git.run(ctx, worktree, "checkout", "--quiet", "--detach", c.head); err != nil {
+			c.cleanup()
+			return nil, fmt.Errorf("failed to check out the candidate: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// runChecks runs every check in the worktree; all of them run so that the
+// report is complete even when an early one fails
+func (q *Queue) runChecks(ctx context.Context, worktree string) ([]CheckResult, bool) {
+	passed := true
+	results := make([]CheckResult, 0, len(q.config.Checks))
+	for _, check := range q.config.Checks {
+		checkCtx, cancel := context.WithTimeout(ctx, check.Timeout)
+		args := append(append([]string(nil), q.config.Shell[1:]...), check.Command)
+		cmd := exec.CommandContext(checkCtx, q.config.Shell[0], args...)
+		cmd.Dir = worktree
+
+		start := time.Now()
+		output, err := cmd.CombinedOutput()
+		cancel()
+
+		result := CheckResult{
+			Name:     check.Name,
+			Passed:   err == nil,
+			Duration: time.Since(start),
+			Output:   tail(string(output), maxCheckOutput),
+		}
+		if errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+			result.Output = strings.TrimSpace(result.Output + fmt.Sprintf("\ntimed out after %s", check.Timeout))
+		}
+		if !result.Passed {
+			passed = false
+		}
+		results = append(results, result)
+	}
+	return results, passed
+}
+
+// land fast-forwards the target to the candidate, then moves the entry
+// branches to their rebased tips (or deletes them). It returns
+// errTargetMoved when the target was updated since the candidate was built.
+// Entry branches are only updated if they still point at the tip they were
+// rebased from, so that commits pushed while the checks ran are kept.
+func (q *Queue) land(ctx context.Context, target string, c *candidate) error {
+	ref := "refs/heads/" + target
+	if q.git.checkedOut(ctx, target) {
+		// Keep the working tree in sync with the branch
+		if current, err := q.git.resolve(ctx, ref); err != nil || current != c.base {
+			return fmt.Errorf("%s: %w", target, errTargetMoved)
+		}
+		if _, err := q.git.run(ctx, "", "merge", "--ff-only", "--quiet", c.head); err != nil {
+			return fmt.Errorf("failed to fast-forward %s: %w", target, err)
+		}
+	} else if _, err := q.git.run(ctx, "", "update-ref", "-m", "merge-queue: fast-forward",
+		ref, c.head, c.base); err != nil {
+		return fmt.Errorf("%s: %w: %v", target, errTargetMoved, err)
+	}
+
+	for _, entry := range c.applied {
+		if q.git.checkedOut(ctx, entry.Branch) {
+			continue
+		}
+		args := []string{"update-ref", "-m", "merge-queue: rebased", "refs/heads/" + entry.Branch, c.rebased[entry.ID], c.tips[entry.ID]}
+		if entry.DeleteAfterMerge {
+			args = []string{"update-ref", "-d", "refs/heads/" + entry.Branch, c.tips[entry.ID]}
+		}
+		// update-ref fails when the branch no longer points at its old value
+		if _, err := q.git.run(ctx, "", args...); err != nil {
+			log.Printf("Warning: merge queue left %s as is, it moved while the queue was testing: %v", entry.Branch, err)
+		}
+	}
+	return nil
+}
+
+// gitRunner runs the git CLI against the repository or one of its worktrees
+type gitRunner struct {
+	repoPath   string
+	executable string
+	committer  string
+}
+
+func newGitRunner(repoPath, executable, committer string) *gitRunner {
+	if executable == "" {
+		executable = "git"
+	}
+	return &gitRunner{repoPath: repoPath, executable: executable, committer: committer}
+}
+
+// run runs git in dir (the repository when empty); rebased commits keep
+// their author and are committed by the queue
+func (g *gitRunner) run(ctx context.Context, dir string, args ...string) (string, error) {
+	if dir == "" {
+		dir = g.repoPath
+	}
+	cmd := exec.CommandContext(ctx, g.executable, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_COMMITTER_NAME="+g.committer,
+		"GIT_COMMITTER_EMAIL="+g.committer+"@localhost",
+		"GIT_EDITOR=true",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	output := strings.TrimSpace(stdout.String())
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return output, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return output, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// resolve returns the commit SHA of a revision
+func (g *gitRunner) resolve(ctx context.Context, rev string) (string, error) {
+	return g.run(ctx, "", "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+}
+
+// checkedOut reports whether branch is checked out in the main working tree
+func (g *gitRunner) checkedOut(ctx context.Context, branch string) bool {
+	if bare, _ := g.run(ctx, "", "rev-parse", "--is-bare-repository"); bare == "true" {
+		return false
+	}
+	head, err := g.run(ctx, "", "symbolic-ref", "--quiet", "HEAD")
+	return err == nil && head == "refs/heads/"+branch
+}
+
+func tail(s string, size int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= size {
+		return s
+	}
+	return "..." + s[len(s)-size:]
+}
@@ -0,0 +1,578 @@
+package mergequeue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/pr"
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+// EventMergeQueue is the webhook event sent on every queue transition; the
+// "action" field of the payload data tells which one
+const EventMergeQueue = "merge_queue"
+
+// EntryState is the state of a queue entry
+type EntryState string
+
+const (
+	StateQueued  EntryState = "queued"
+	StateTesting EntryState = "testing"
+	StateMerged  EntryState = "merged"
+	StateFailed  EntryState = "failed"
+)
+
+// Check is a command that must succeed on a candidate before it lands
+type Check struct {
+	Name    string
+	Command string // run by Config.Shell in the candidate worktree
+	Timeout time.Duration
+}
+
+// CheckResult is the outcome of a check on a candidate
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output,omitempty"` // tail of the combined output
+}
+
+// Config configures a Queue
+type Config struct {
+	RepoPath string
+	Checks   []Check
+	// RequiredStatuses are host commit status contexts that must be
+	// "success" on the branch before it is tested
+	RequiredStatuses []string
+	// BatchSize is the number of entries tested together (default 4)
+	BatchSize int
+	// ManualProcessing disables the background worker: entries wait for Process
+	ManualProcessing bool
+	// Committer signs the rebased commits (default "merge-queue")
+	Committer     string
+	GitExecutable string
+	// Shell runs the check commands (default "sh -c", "cmd /C" on Windows)
+	Shell []string
+}
+
+// Entry is a branch waiting to land on its target
+type Entry struct {
+	ID               string        `json:"id"`
+	Branch           string        `json:"branch"`
+	Target           string        `json:"target"`
+	PullRequest      int           `json:"pull_request,omitempty"`
+	DeleteAfterMerge bool          `json:"delete_after_merge"`
+	State            EntryState    `json:"state"`
+	Error            string        `json:"error,omitempty"`
+	MergedCommit     string        `json:"merged_commit,omitempty"`
+	CheckResults     []CheckResult `json:"check_results,omitempty"`
+	EnqueuedAt       time.Time     `json:"enqueued_at"`
+	FinishedAt       time.Time     `json:"finished_at,omitempty"`
+
+	done chan struct{}
+}
+
+// EnqueueOptions are the optional settings of Enqueue
+type EnqueueOptions struct {
+	PullRequest      int
+	DeleteAfterMerge bool
+}
+
+// EventSink receives the queue events; the webhook manager implements it
+type EventSink interface {
+	SendWebhook(ctx context.Context, event string, payload *interfaces.WebhookPayload) error
+}
+
+// StatusSource provides the host commit statuses checked by RequiredStatuses
+type StatusSource interface {
+	ListCommitStatuses(ctx context.Context, ref string) ([]*pr.CommitStatus, error)
+}
+
+// Queue serializes merges into each target branch. Candidates are rebased
+// onto the queue head in a temporary worktree, checked, and only green
+// candidates fast-forward the target; a failing batch is bisected to find
+// the entries responsible.
+type Queue struct {
+	config   Config
+	git      *gitRunner
+	events   EventSink
+	statuses StatusSource
+
+	mu       sync.Mutex
+	entries  map[string]*Entry
+	pending  map[string][]*Entry // by target, in order
+	running  map[string]bool     // targets being processed
+	targetMu map[string]*sync.Mutex
+	nextID   int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a queue for the repository at config.RepoPath; events and
+// statuses may be nil
+func New(config Config, events EventSink, statuses StatusSource) (*Queue, error) {
+	if config.RepoPath == "" {
+		return nil, fmt.Errorf("merge queue requires a repository path")
+	}
+	if len(config.RequiredStatuses) > 0 && statuses == nil {
+		return nil, fmt.Errorf("required statuses need a git host")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 4
+	}
+	if config.Committer == "" {
+		config.Committer = "merge-queue"
+	}
+	if len(config.Shell) == 0 {
+		config.Shell = []string{"sh", "-c"}
+		if runtime.GOOS == "windows" {
+			config.Shell = []string{"cmd", "/C"}
+		}
+	}
+	for i := range config.Checks {
+		if config.Checks[i].Command == "" {
+			return nil, fmt.Errorf("check %q has no command", config.Checks[i].Name)
+		}
+		if config.Checks[i].Name == "" {
+			config.Checks[i].Name = config.Checks[i].Command
+		}
+		if config.Checks[i].Timeout <= 0 {
+			config.Checks[i].Timeout = 10 * time.Minute
+		}
+	}
+
+	git := newGitRunner(config.RepoPath, config.GitExecutable, config.Committer)
+	if _, err := git.run(context.Background(), "", "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", config.RepoPath, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		config:   config,
+		git:      git,
+		events:   events,
+		statuses: statuses,
+		entries:  make(map[string]*Entry),
+		pending:  make(map[string][]*Entry),
+		running:  make(map[string]bool),
+		targetMu: make(map[string]*sync.Mutex),
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// Enqueue adds branch to the queue of target
+func (q *Queue) Enqueue(ctx context.Context, branch, target string, opts EnqueueOptions) (*Entry, error) {
+	if branch == "" || target == "" {
+		return nil, fmt.Errorf("branch and target cannot be empty")
+	}
+	if branch == target {
+		return nil, fmt.Errorf("cannot merge %s into itself", branch)
+	}
+	for _, ref := range []string{branch, target} {
+		if _, err := q.git.resolve(ctx, "refs/heads/"+ref); err != nil {
+			return nil, fmt.Errorf("branch %s does not exist: %w", ref, err)
+		}
+	}
+
+	q.mu.Lock()
+	for _, queued := range q.pending[target] {
+		if queued.Branch == branch {
+			q.mu.Unlock()
+			return nil, fmt.Errorf("%s is already queued for %s as %s", branch, target, queued.ID)
+		}
+	}
+	q.nextID++
+	entry := &Entry{
+		ID:               fmt.Sprintf("mq-%d", q.nextID),
+		Branch:           branch,
+		Target:           target,
+		PullRequest:      opts.PullRequest,
+		DeleteAfterMerge: opts.DeleteAfterMerge,
+		State:            StateQueued,
+		EnqueuedAt:       time.Now(),
+		done:             make(chan struct{}),
+	}
+	q.entries[entry.ID] = entry
+	q.pending[target] = append(q.pending[target], entry)
+	position := len(q.pending[target])
+	snapshot := *entry
+	q.mu.Unlock()
+
+	log.Printf("Merge queue: %s enqueued for %s (position %d)", branch, target, position)
+	q.notify(ctx, "enqueued", []*Entry{entry}, map[string]interface{}{"position": position})
+
+	if !q.config.ManualProcessing {
+		q.startWorker(target)
+	}
+	return &snapshot, nil
+}
+
+// Wait blocks until the entry is merged or failed and returns its final state
+func (q *Queue) Wait(ctx context.Context, id string) (*Entry, error) {
+	q.mu.Lock()
+	entry, ok := q.entries[id]
+	q.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown merge queue entry %s", id)
+	}
+
+	select {
+	case <-entry.done:
+		snapshot, _ := q.Get(id)
+		return snapshot, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Get returns a copy of an entry
+func (q *Queue) Get(id string) (*Entry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.entries[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *entry
+	return &snapshot, true
+}
+
+// Pending returns copies of the entries waiting for target, in order
+func (q *Queue) Pending(target string) []*Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := make([]*Entry, 0, len(q.pending[target]))
+	for _, entry := range q.pending[target] {
+		snapshot := *entry
+		entries = append(entries, &snapshot)
+	}
+	return entries
+}
+
+// Process lands the entries queued for target, batch after batch, until
+// the queue is empty
+func (q *Queue) Process(ctx context.Context, target string) error {
+	lock := q.lockFor(target)
+	lock.Lock()
+	defer lock.Unlock()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch := q.takeBatch(target)
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := q.processBatch(ctx, target, batch); err != nil {
+			// A target moved by a concurrent push or a stopped queue says
+			// nothing about the entries: they are tested again later
+			if errors.Is(err, errTargetMoved) || ctx.Err() != nil {
+				q.requeue(ctx, target, batch, err)
+				continue
+			}
+			// Infrastructure failure (worktree, git): fail the batch rather
+			// than retrying forever
+			for _, entry := range batch {
+				q.finish(ctx, entry, StateFailed, "", err.Error())
+			}
+		}
+	}
+}
+
+// Shutdown stops the background workers; entries still queued stay queued,
+// and the batch being tested goes back to the head of its queue
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.cancel()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) startWorker(target string) {
+	q.mu.Lock()
+	if q.running[target] {
+		q.mu.Unlock()
+		return
+	}
+	q.running[target] = true
+	q.mu.Unlock()
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		for {
+			if err := q.Process(q.ctx, target); err != nil {
+				log.Printf("Merge queue for %s stopped: %v", target, err)
+			}
+
+			// Entries enqueued while the last batch was finishing
+			q.mu.Lock()
+			if len(q.pending[target]) == 0 || q.ctx.Err() != nil {
+				q.running[target] = false
+				q.mu.Unlock()
+				return
+			}
+			q.mu.Unlock()
+		}
+	}()
+}
+
+func (q *Queue) lockFor(target string) *sync.Mutex {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lock, ok := q.targetMu[target]
+	if !ok {
+		lock = &sync.Mutex{}
+		q.targetMu[target] = lock
+	}
+	return lock
+}
+
+// requeue puts the entries of batch that are still being tested back at
+// the head of the queue, in order
+func (q *Queue) requeue(ctx context.Context, target string, batch []*Entry, reason error) {
+	q.mu.Lock()
+	var requeued []*Entry
+	for _, entry := range batch {
+		if entry.State == StateTesting {
+			entry.State = StateQueued
+			requeued = append(requeued, entry)
+		}
+	}
+	q.pending[target] = append(requeued, q.pending[target]...)
+	q.mu.Unlock()
+
+	if len(requeued) == 0 {
+		return
+	}
+	log.Printf("Merge queue: %d entries requeued for %s: %v", len(requeued), target, reason)
+	q.notify(ctx, "requeued", requeued, map[string]interface{}{"reason": reason.Error()})
+}
+
+// takeBatch removes up to BatchSize entries from the head of the queue
+func (q *Queue) takeBatch(target string) []*Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queued := q.pending[target]
+	size := q.config.BatchSize
+	if size > len(queued) {
+		size = len(queued)
+	}
+	batch := append([]*Entry(nil), queued[:size]...)
+	q.pending[target] = queued[size:]
+	for _, entry := range batch {
+		entry.State = StateTesting
+	}
+	return batch
+}
+
+// processBatch tests the batch on top of the target and lands it when green;
+// a red batch is split in two halves processed one after the other, so that
+// the green half lands and the red half is bisected further
+func (q *Queue) processBatch(ctx context.Context, target string, batch []*Entry) error {
+	batch = q.checkRequiredStatuses(ctx, batch)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	candidate, err := q.buildCandidate(ctx, target, batch)
+	if err != nil {
+		return err
+	}
+	if len(candidate.applied) == 0 {
+		candidate.cleanup()
+		return nil
+	}
+
+	q.notify(ctx, "testing", candidate.applied, map[string]interface{}{"commit": candidate.head})
+	results, passed := q.runChecks(ctx, candidate.worktree)
+	candidate.cleanup()
+	if err := ctx.Err(); err != nil {
+		// Checks killed by the cancellation did not fail
+		return err
+	}
+
+	if passed {
+		if err := q.land(ctx, target, candidate); err != nil {
+			return err
+		}
+		for _, entry := range candidate.applied {
+			q.setResults(entry, results)
+			q.finish(ctx, entry, StateMerged, candidate.head, "")
+		}
+		return nil
+	}
+
+	if len(candidate.applied) == 1 {
+		entry := candidate.applied[0]
+		q.setResults(entry, results)
+		q.finish(ctx, entry, StateFailed, "", "checks failed: "+failedChecks(results))
+		return nil
+	}
+
+	middle := len(candidate.applied) / 2
+	halves := [][]*Entry{candidate.applied[:middle], candidate.applied[middle:]}
+	log.Printf("Merge queue: batch of %d failed on %s, bisecting", len(candidate.applied), target)
+	q.notify(ctx, "bisecting", candidate.applied, map[string]interface{}{"failed_checks": failedChecks(results)})
+	for _, half := range halves {
+		if err := q.processBatch(ctx, target, half); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRequiredStatuses fails the entries whose branch lacks a successful
+// required host status
+func (q *Queue) checkRequiredStatuses(ctx context.Context, batch []*Entry) []*Entry {
+	if len(q.config.RequiredStatuses) == 0 {
+		return batch
+	}
+
+	var ready []*Entry
+	for _, entry := range batch {
+		statuses, err := q.statuses.ListCommitStatuses(ctx, entry.Branch)
+		if ctx.Err() != nil {
+			// Left testing: Process requeues it
+			continue
+		}
+		if err != nil {
+			q.finish(ctx, entry, StateFailed, "", fmt.Sprintf("failed to read statuses: %v", err))
+			continue
+		}
+
+		// Statuses are listed newest first: the first one of a context wins
+		latest := make(map[string]string)
+		for _, status := range statuses {
+			if _, seen := latest[status.Context]; !seen {
+				latest[status.Context] = status.State
+			}
+		}
+
+		var missing []string
+		for _, required := range q.config.RequiredStatuses {
+			if state := latest[required]; state != pr.StatusSuccess {
+				if state == "" {
+					state = "missing"
+				}
+				missing = append(missing, fmt.Sprintf("%s (%s)", required, state))
+			}
+		}
+		if len(missing) > 0 {
+			q.finish(ctx, entry, StateFailed, "", "required statuses not successful: "+strings.Join(missing, ", "))
+			continue
+		}
+		ready = append(ready, entry)
+	}
+	return ready
+}
+
+// finish records the final state of an entry and wakes up its waiters
+func (q *Queue) finish(ctx context.Context, entry *Entry, state EntryState, commit, message string) {
+	q.mu.Lock()
+	if entry.State == StateMerged || entry.State == StateFailed {
+		q.mu.Unlock()
+		return
+	}
+	entry.State = state
+	entry.MergedCommit = commit
+	entry.Error = message
+	entry.FinishedAt = time.Now()
+	close(entry.done)
+	q.mu.Unlock()
+
+	if state == StateMerged {
+		log.Printf("Merge queue: %s merged into %s at %s", entry.Branch, entry.Target, shortSHA(commit))
+		q.notify(ctx, "merged", []*Entry{entry}, map[string]interface{}{"commit": commit})
+	} else {
+		log.Printf("Merge queue: %s not merged into %s: %s", entry.Branch, entry.Target, message)
+		q.notify(ctx, "failed", []*Entry{entry}, map[string]interface{}{"error": message})
+	}
+}
+
+func (q *Queue) setResults(entry *Entry, results []CheckResult) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry.CheckResults = results
+}
+
+// notify sends a merge_queue event; delivery failures are only logged
+func (q *Queue) notify(ctx context.Context, action string, entries []*Entry, extra map[string]interface{}) {
+	if q.events == nil {
+		return
+	}
+
+	q.mu.Lock()
+	items := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		item := map[string]interface{}{
+			"id":     entry.ID,
+			"branch": entry.Branch,
+			"state":  string(entry.State),
+		}
+		if entry.PullRequest > 0 {
+			item["pull_request"] = entry.PullRequest
+		}
+		items = append(items, item)
+	}
+	target := entries[0].Target
+	q.mu.Unlock()
+
+	data := map[string]interface{}{
+		"action":  action,
+		"target":  target,
+		"entries": items,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	payload := &interfaces.WebhookPayload{
+		Event:     EventMergeQueue,
+		Timestamp: time.Now(),
+		Data:      data,
+		Metadata:  map[string]string{"source": "merge-queue"},
+	}
+	if err := q.events.SendWebhook(ctx, EventMergeQueue, payload); err != nil {
+		log.Printf("Warning: failed to send merge queue event %s: %v", action, err)
+	}
+}
+
+func failedChecks(results []CheckResult) string {
+	var names []string
+	for _, result := range results {
+		if !result.Passed {
+			names = append(names, result.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
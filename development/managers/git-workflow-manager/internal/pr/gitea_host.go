@@ -0,0 +1,433 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+const giteaPageSize = 50
+
+// giteaReviewEvents maps review states to the events of the review API
+var giteaReviewEvents = map[string]string{
+	ReviewApproved:         "APPROVED",
+	ReviewChangesRequested: "REQUEST_CHANGES",
+	ReviewCommented:        "COMMENT",
+}
+
+// GiteaHost implements GitHost with the Gitea (and Forgejo) API v1
+type GiteaHost struct {
+	client *restClient
+	owner  string
+	repo   string
+}
+
+type giteaPullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Merged    bool      `json:"merged"`
+	Head      giteaRef  `json:"head"`
+	Base      giteaRef  `json:"base"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type giteaRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      giteaUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type giteaReview struct {
+	ID          int64     `json:"id"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	User        giteaUser `json:"user"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type giteaStatus struct {
+	Context     string    `json:"context"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	TargetURL   string    `json:"target_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewGiteaHost creates a host for the Gitea server whose API is at apiURL
+// (for example https://gitea.example.com/api/v1)
+func NewGiteaHost(apiURL, token string) (*GiteaHost, error) {
+	authValue := ""
+	if token != "" {
+		authValue = "token " + token
+	}
+	client, err := newRESTClient("Gitea", apiURL, "Authorization", authValue)
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaHost{client: client}, nil
+}
+
+// Name returns "gitea"
+func (h *GiteaHost) Name() string {
+	return "gitea"
+}
+
+// SetRepository sets the repository for operations
+func (h *GiteaHost) SetRepository(owner, repo string) {
+	h.owner = owner
+	h.repo = repo
+}
+
+// repoPath returns the API path of the repository followed by suffix
+func (h *GiteaHost) repoPath(format string, args ...interface{}) (string, error) {
+	if h.owner == "" || h.repo == "" {
+		return "", fmt.Errorf("repository not configured, call SetRepository first")
+	}
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(h.owner), url.PathEscape(h.repo)) + fmt.Sprintf(format, args...), nil
+}
+
+// CreatePullRequest creates a new pull request
+func (h *GiteaHost) CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error) {
+	path, err := h.repoPath("/pulls")
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{"title": title, "body": description, "head": sourceBranch, "base": targetBranch}
+	var pr giteaPullRequest
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return pr.info(), nil
+}
+
+// GetPullRequest returns a pull request
+func (h *GiteaHost) GetPullRequest(ctx context.Context, number int) (*interfaces.PullRequestInfo, error) {
+	path, err := h.repoPath("/pulls/%d", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr giteaPullRequest
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return pr.info(), nil
+}
+
+// ListPullRequests lists pull requests; merged ones are listed as closed by
+// the API and filtered for "merged"
+func (h *GiteaHost) ListPullRequests(ctx context.Context, state string) ([]*interfaces.PullRequestInfo, error) {
+	path, err := h.repoPath("/pulls")
+	if err != nil {
+		return nil, err
+	}
+
+	apiState := state
+	if state == "merged" {
+		apiState = "closed"
+	}
+
+	var prs []*interfaces.PullRequestInfo
+	err = pages(giteaPageSize, func(page int) (int, error) {
+		query := url.Values{"state": {apiState}, "sort": {"recentupdate"}, "page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+		var batch []giteaPullRequest
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range batch {
+			if state == "merged" && !pr.Merged {
+				continue
+			}
+			prs = append(prs, pr.info())
+		}
+		return len(batch), nil
+	})
+	return prs, err
+}
+
+// UpdatePullRequest updates the title and description of a pull request
+func (h *GiteaHost) UpdatePullRequest(ctx context.Context, number int, title, description string) (*interfaces.PullRequestInfo, error) {
+	path, err := h.repoPath("/pulls/%d", number)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{}
+	if title != "" {
+		body["title"] = title
+	}
+	if description != "" {
+		body["body"] = description
+	}
+
+	var pr giteaPullRequest
+	if _, err := h.client.do(ctx, http.MethodPatch, path, nil, body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to update pull request #%d: %w", number, err)
+	}
+	return pr.info(), nil
+}
+
+// ClosePullRequest closes a pull request
+func (h *GiteaHost) ClosePullRequest(ctx context.Context, number int) error {
+	path, err := h.repoPath("/pulls/%d", number)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.client.do(ctx, http.MethodPatch, path, nil, map[string]string{"state": "closed"}, nil); err != nil {
+		return fmt.Errorf("failed to close pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// MergePullRequest merges a pull request with the given method
+func (h *GiteaHost) MergePullRequest(ctx context.Context, number int, commitMessage, mergeMethod string) error {
+	path, err := h.repoPath("/pulls/%d/merge", number)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{"Do": mergeMethod}
+	if commitMessage != "" {
+		body["MergeMessageField"] = commitMessage
+	}
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, nil); err != nil {
+		return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListPullRequestFiles returns the files changed in a pull request
+func (h *GiteaHost) ListPullRequestFiles(ctx context.Context, number int) ([]string, error) {
+	path, err := h.repoPath("/pulls/%d/files", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = pages(giteaPageSize, func(page int) (int, error) {
+		query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+		var batch []struct {
+			Filename string `json:"filename"`
+		}
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to get pull request files: %w", err)
+		}
+		for _, file := range batch {
+			files = append(files, file.Filename)
+		}
+		return len(batch), nil
+	})
+	return files, err
+}
+
+// AddComment adds a comment to a pull request
+func (h *GiteaHost) AddComment(ctx context.Context, number int, body string) error {
+	path, err := h.repoPath("/issues/%d/comments", number)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to add comment to pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListComments returns the comments of a pull request, oldest first
+func (h *GiteaHost) ListComments(ctx context.Context, number int) ([]*Comment, error) {
+	path, err := h.repoPath("/issues/%d/comments", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch []giteaComment
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &batch); err != nil {
+		return nil, fmt.Errorf("failed to get pull request comments: %w", err)
+	}
+
+	comments := make([]*Comment, 0, len(batch))
+	for _, comment := range batch {
+		comments = append(comments, &Comment{
+			ID:        comment.ID,
+			Author:    comment.User.Login,
+			Body:      comment.Body,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+	return comments, nil
+}
+
+// SubmitReview submits a review as the token's account
+func (h *GiteaHost) SubmitReview(ctx context.Context, number int, review Review) (*Review, error) {
+	path, err := h.repoPath("/pulls/%d/reviews", number)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{"body": review.Body, "event": giteaReviewEvents[review.State]}
+	var created giteaReview
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to review pull request #%d: %w", number, err)
+	}
+	return created.review(), nil
+}
+
+// ListReviews returns the reviews of a pull request, oldest first
+func (h *GiteaHost) ListReviews(ctx context.Context, number int) ([]*Review, error) {
+	path, err := h.repoPath("/pulls/%d/reviews", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*Review
+	err = pages(giteaPageSize, func(page int) (int, error) {
+		query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+		var batch []giteaReview
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to list reviews of pull request #%d: %w", number, err)
+		}
+		for _, review := range batch {
+			reviews = append(reviews, review.review())
+		}
+		return len(batch), nil
+	})
+	return reviews, err
+}
+
+// SetCommitStatus creates a commit status; Gitea requires a commit SHA, so
+// branches are resolved first
+func (h *GiteaHost) SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error) {
+	sha, err := h.commitSHA(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	path, err := h.repoPath("/statuses/%s", url.PathEscape(sha))
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+	}
+	var created giteaStatus
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to set status %s on %s: %w", status.Context, ref, err)
+	}
+	return created.commitStatus(), nil
+}
+
+// ListCommitStatuses returns the statuses of ref, newest first
+func (h *GiteaHost) ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error) {
+	path, err := h.repoPath("/commits/%s/statuses", url.PathEscape(ref))
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*CommitStatus
+	err = pages(giteaPageSize, func(page int) (int, error) {
+		query := url.Values{"sort": {"newest"}, "page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(giteaPageSize)}}
+		var batch []giteaStatus
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to list statuses of %s: %w", ref, err)
+		}
+		for _, status := range batch {
+			statuses = append(statuses, status.commitStatus())
+		}
+		return len(batch), nil
+	})
+	return statuses, err
+}
+
+// Ping checks that the API answers
+func (h *GiteaHost) Ping(ctx context.Context) error {
+	if _, err := h.client.do(ctx, http.MethodGet, "/version", nil, nil, nil); err != nil {
+		return fmt.Errorf("Gitea API health check failed: %w", err)
+	}
+	return nil
+}
+
+// commitSHA resolves a branch, tag or SHA to a commit SHA
+func (h *GiteaHost) commitSHA(ctx context.Context, ref string) (string, error) {
+	path, err := h.repoPath("/git/commits/%s", url.PathEscape(ref))
+	if err != nil {
+		return "", err
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return commit.SHA, nil
+}
+
+func (pr *giteaPullRequest) info() *interfaces.PullRequestInfo {
+	return &interfaces.PullRequestInfo{
+		ID:           pr.Number,
+		Title:        pr.Title,
+		Description:  pr.Body,
+		SourceBranch: pr.Head.Ref,
+		TargetBranch: pr.Base.Ref,
+		Status:       normalizeState(pr.State, pr.Merged),
+		CreatedAt:    pr.CreatedAt,
+		UpdatedAt:    pr.UpdatedAt,
+	}
+}
+
+func (r *giteaReview) review() *Review {
+	state := r.State
+	switch state {
+	case "REQUEST_CHANGES":
+		state = ReviewChangesRequested
+	case "COMMENT":
+		state = ReviewCommented
+	}
+	return &Review{
+		ID:          r.ID,
+		Author:      r.User.Login,
+		State:       state,
+		Body:        r.Body,
+		SubmittedAt: r.SubmittedAt,
+	}
+}
+
+func (s *giteaStatus) commitStatus() *CommitStatus {
+	// Gitea also reports "warning", which does not block like a failure
+	state := s.Status
+	if state == "warning" {
+		state = StatusSuccess
+	}
+	return &CommitStatus{
+		Context:     s.Context,
+		State:       state,
+		Description: s.Description,
+		TargetURL:   s.TargetURL,
+		CreatedAt:   s.CreatedAt,
+	}
+}
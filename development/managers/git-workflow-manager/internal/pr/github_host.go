@@ -0,0 +1,409 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+	"github.com/google/go-github/v58/github"
+	"golang.org/x/oauth2"
+)
+
+// githubReviewEvents maps review states to the events of the review API
+var githubReviewEvents = map[string]string{
+	ReviewApproved:         "APPROVE",
+	ReviewChangesRequested: "REQUEST_CHANGES",
+	ReviewCommented:        "COMMENT",
+}
+
+// GitHubHost implements GitHost with the GitHub REST API
+type GitHubHost struct {
+	client *github.Client
+	owner  string
+	repo   string
+}
+
+// NewGitHubHost creates a GitHub host; enterpriseURL selects a GitHub
+// Enterprise server instead of github.com
+func NewGitHubHost(token, enterpriseURL string) (*GitHubHost, error) {
+	var client *github.Client
+
+	if token != "" {
+		// Create authenticated client
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		)
+		client = github.NewClient(oauth2.NewClient(context.Background(), ts))
+	} else {
+		// Create unauthenticated client (limited functionality)
+		client = github.NewClient(nil)
+		log.Printf("Warning: GitHub token not provided, PR functionality will be limited")
+	}
+
+	if enterpriseURL != "" && enterpriseURL != "https://api.github.com" {
+		var err error
+		client, err = client.WithEnterpriseURLs(enterpriseURL, enterpriseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub Enterprise URL %s: %w", enterpriseURL, err)
+		}
+	}
+
+	return &GitHubHost{client: client}, nil
+}
+
+// Name returns "github"
+func (h *GitHubHost) Name() string {
+	return "github"
+}
+
+// SetRepository sets the GitHub repository for operations
+func (h *GitHubHost) SetRepository(owner, repo string) {
+	h.owner = owner
+	h.repo = repo
+}
+
+func (h *GitHubHost) checkRepository() error {
+	if h.owner == "" || h.repo == "" {
+		return fmt.Errorf("repository not configured, call SetRepository first")
+	}
+	return nil
+}
+
+// CreatePullRequest creates a new pull request
+func (h *GitHubHost) CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	// Validate branches exist
+	if _, _, err := h.client.Git.GetRef(ctx, h.owner, h.repo, "heads/"+sourceBranch); err != nil {
+		return nil, fmt.Errorf("source branch %s does not exist: %w", sourceBranch, err)
+	}
+	if _, _, err := h.client.Git.GetRef(ctx, h.owner, h.repo, "heads/"+targetBranch); err != nil {
+		return nil, fmt.Errorf("target branch %s does not exist: %w", targetBranch, err)
+	}
+
+	pr := &github.NewPullRequest{
+		Title:               github.String(title),
+		Head:                github.String(sourceBranch),
+		Base:                github.String(targetBranch),
+		Body:                github.String(description),
+		MaintainerCanModify: github.Bool(true),
+	}
+
+	createdPR, _, err := h.client.PullRequests.Create(ctx, h.owner, h.repo, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return githubPullRequestInfo(createdPR), nil
+}
+
+// GetPullRequest returns a pull request
+func (h *GitHubHost) GetPullRequest(ctx context.Context, number int) (*interfaces.PullRequestInfo, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	pr, _, err := h.client.PullRequests.Get(ctx, h.owner, h.repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return githubPullRequestInfo(pr), nil
+}
+
+// ListPullRequests lists pull requests; GitHub reports merged pull requests
+// as closed, so "merged" lists the closed ones and keeps the merged ones
+func (h *GitHubHost) ListPullRequests(ctx context.Context, state string) ([]*interfaces.PullRequestInfo, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	apiState := state
+	if state == "merged" {
+		apiState = "closed"
+	}
+	opts := &github.PullRequestListOptions{
+		State:       apiState,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var allPRs []*interfaces.PullRequestInfo
+	for {
+		prs, resp, err := h.client.PullRequests.List(ctx, h.owner, h.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+
+		for _, pr := range prs {
+			info := githubPullRequestInfo(pr)
+			if state == "merged" && info.Status != "merged" {
+				continue
+			}
+			allPRs = append(allPRs, info)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allPRs, nil
+}
+
+// UpdatePullRequest updates the title and description of a pull request
+func (h *GitHubHost) UpdatePullRequest(ctx context.Context, number int, title, description string) (*interfaces.PullRequestInfo, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	pr := &github.PullRequest{}
+	if title != "" {
+		pr.Title = github.String(title)
+	}
+	if description != "" {
+		pr.Body = github.String(description)
+	}
+
+	updatedPR, _, err := h.client.PullRequests.Edit(ctx, h.owner, h.repo, number, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request #%d: %w", number, err)
+	}
+	return githubPullRequestInfo(updatedPR), nil
+}
+
+// ClosePullRequest closes a pull request
+func (h *GitHubHost) ClosePullRequest(ctx context.Context, number int) error {
+	if err := h.checkRepository(); err != nil {
+		return err
+	}
+
+	pr := &github.PullRequest{State: github.String("closed")}
+	if _, _, err := h.client.PullRequests.Edit(ctx, h.owner, h.repo, number, pr); err != nil {
+		return fmt.Errorf("failed to close pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// MergePullRequest merges a pull request with the given method
+func (h *GitHubHost) MergePullRequest(ctx context.Context, number int, commitMessage, mergeMethod string) error {
+	if err := h.checkRepository(); err != nil {
+		return err
+	}
+
+	opts := &github.PullRequestOptions{MergeMethod: mergeMethod}
+	if _, _, err := h.client.PullRequests.Merge(ctx, h.owner, h.repo, number, commitMessage, opts); err != nil {
+		return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListPullRequestFiles returns the files changed in a pull request
+func (h *GitHubHost) ListPullRequestFiles(ctx context.Context, number int) ([]string, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	var allFiles []string
+	for {
+		files, resp, err := h.client.PullRequests.ListFiles(ctx, h.owner, h.repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request files: %w", err)
+		}
+
+		for _, file := range files {
+			allFiles = append(allFiles, file.GetFilename())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allFiles, nil
+}
+
+// AddComment adds a comment to a pull request
+func (h *GitHubHost) AddComment(ctx context.Context, number int, body string) error {
+	if err := h.checkRepository(); err != nil {
+		return err
+	}
+
+	comment := &github.IssueComment{Body: github.String(body)}
+	if _, _, err := h.client.Issues.CreateComment(ctx, h.owner, h.repo, number, comment); err != nil {
+		return fmt.Errorf("failed to add comment to pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListComments returns the comments of a pull request, oldest first
+func (h *GitHubHost) ListComments(ctx context.Context, number int) ([]*Comment, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	opts := &github.IssueListCommentsOptions{
+		Sort:        github.String("created"),
+		Direction:   github.String("asc"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var allComments []*Comment
+	for {
+		comments, resp, err := h.client.Issues.ListComments(ctx, h.owner, h.repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request comments: %w", err)
+		}
+
+		for _, comment := range comments {
+			allComments = append(allComments, &Comment{
+				ID:        comment.GetID(),
+				Author:    comment.GetUser().GetLogin(),
+				Body:      comment.GetBody(),
+				CreatedAt: comment.GetCreatedAt().Time,
+				UpdatedAt: comment.GetUpdatedAt().Time,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allComments, nil
+}
+
+// SubmitReview submits a review as the token's account
+func (h *GitHubHost) SubmitReview(ctx context.Context, number int, review Review) (*Review, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	request := &github.PullRequestReviewRequest{
+		Body:  github.String(review.Body),
+		Event: github.String(githubReviewEvents[review.State]),
+	}
+	created, _, err := h.client.PullRequests.CreateReview(ctx, h.owner, h.repo, number, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review pull request #%d: %w", number, err)
+	}
+	return githubReview(created), nil
+}
+
+// ListReviews returns the reviews of a pull request, oldest first
+func (h *GitHubHost) ListReviews(ctx context.Context, number int) ([]*Review, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	var allReviews []*Review
+	for {
+		reviews, resp, err := h.client.PullRequests.ListReviews(ctx, h.owner, h.repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviews of pull request #%d: %w", number, err)
+		}
+
+		for _, review := range reviews {
+			allReviews = append(allReviews, githubReview(review))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allReviews, nil
+}
+
+// SetCommitStatus creates a commit status
+func (h *GitHubHost) SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	repoStatus := &github.RepoStatus{
+		State:       github.String(status.State),
+		Context:     github.String(status.Context),
+		Description: github.String(status.Description),
+	}
+	if status.TargetURL != "" {
+		repoStatus.TargetURL = github.String(status.TargetURL)
+	}
+
+	created, _, err := h.client.Repositories.CreateStatus(ctx, h.owner, h.repo, ref, repoStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set status %s on %s: %w", status.Context, ref, err)
+	}
+	return githubCommitStatus(created), nil
+}
+
+// ListCommitStatuses returns the statuses of ref, newest first
+func (h *GitHubHost) ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error) {
+	if err := h.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	var allStatuses []*CommitStatus
+	for {
+		statuses, resp, err := h.client.Repositories.ListStatuses(ctx, h.owner, h.repo, ref, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statuses of %s: %w", ref, err)
+		}
+
+		for _, status := range statuses {
+			allStatuses = append(allStatuses, githubCommitStatus(status))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return allStatuses, nil
+}
+
+// Ping tests GitHub API connectivity
+func (h *GitHubHost) Ping(ctx context.Context) error {
+	if _, _, err := h.client.Users.Get(ctx, ""); err != nil {
+		return fmt.Errorf("GitHub API health check failed: %w", err)
+	}
+	return nil
+}
+
+func githubPullRequestInfo(pr *github.PullRequest) *interfaces.PullRequestInfo {
+	return &interfaces.PullRequestInfo{
+		ID:           pr.GetNumber(),
+		Title:        pr.GetTitle(),
+		Description:  pr.GetBody(),
+		SourceBranch: pr.GetHead().GetRef(),
+		TargetBranch: pr.GetBase().GetRef(),
+		Status:       normalizeState(pr.GetState(), pr.GetMerged() || pr.MergedAt != nil),
+		CreatedAt:    pr.GetCreatedAt().Time,
+		UpdatedAt:    pr.GetUpdatedAt().Time,
+	}
+}
+
+func githubReview(review *github.PullRequestReview) *Review {
+	return &Review{
+		ID:          review.GetID(),
+		Author:      review.GetUser().GetLogin(),
+		State:       review.GetState(),
+		Body:        review.GetBody(),
+		SubmittedAt: review.GetSubmittedAt().Time,
+	}
+}
+
+func githubCommitStatus(status *github.RepoStatus) *CommitStatus {
+	return &CommitStatus{
+		Context:     status.GetContext(),
+		State:       status.GetState(),
+		Description: status.GetDescription(),
+		TargetURL:   status.GetTargetURL(),
+		CreatedAt:   status.GetCreatedAt().Time,
+	}
+}
@@ -0,0 +1,467 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+const gitlabPageSize = 100
+
+// GitLabHost implements GitHost with the GitLab API v4. Pull requests are
+// merge requests and their numbers are the merge request IIDs.
+type GitLabHost struct {
+	client  *restClient
+	project string // "namespace/project"
+}
+
+type gitlabMergeRequest struct {
+	IID          int       `json:"iid"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	State        string    `json:"state"`
+	SourceBranch string    `json:"source_branch"`
+	TargetBranch string    `json:"target_branch"`
+	SHA          string    `json:"sha"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabNote struct {
+	ID        int64      `json:"id"`
+	Body      string     `json:"body"`
+	Author    gitlabUser `json:"author"`
+	System    bool       `json:"system"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type gitlabStatus struct {
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	TargetURL   string    `json:"target_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// gitlabStatusStates maps commit status states to GitLab states
+var gitlabStatusStates = map[string]string{
+	StatusPending: "pending",
+	StatusSuccess: "success",
+	StatusFailure: "failed",
+	StatusError:   "failed",
+}
+
+// NewGitLabHost creates a host for the GitLab server whose API is at apiURL
+// (for example https://gitlab.com/api/v4)
+func NewGitLabHost(apiURL, token string) (*GitLabHost, error) {
+	client, err := newRESTClient("GitLab", apiURL, "PRIVATE-TOKEN", token)
+	if err != nil {
+		return nil, err
+	}
+	return &GitLabHost{client: client}, nil
+}
+
+// Name returns "gitlab"
+func (h *GitLabHost) Name() string {
+	return "gitlab"
+}
+
+// SetRepository sets the project for operations; owner is the namespace
+func (h *GitLabHost) SetRepository(owner, repo string) {
+	h.project = ""
+	if owner != "" && repo != "" {
+		h.project = owner + "/" + repo
+	}
+}
+
+// projectPath returns the API path of the project followed by suffix
+func (h *GitLabHost) projectPath(format string, args ...interface{}) (string, error) {
+	if h.project == "" {
+		return "", fmt.Errorf("repository not configured, call SetRepository first")
+	}
+	return "/projects/" + url.PathEscape(h.project) + fmt.Sprintf(format, args...), nil
+}
+
+// CreatePullRequest creates a merge request
+func (h *GitLabHost) CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error) {
+	path, err := h.projectPath("/merge_requests")
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{"title": title, "description": description, "source_branch": sourceBranch, "target_branch": targetBranch}
+	var mr gitlabMergeRequest
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, &mr); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return mr.info(), nil
+}
+
+// GetPullRequest returns a merge request
+func (h *GitLabHost) GetPullRequest(ctx context.Context, number int) (*interfaces.PullRequestInfo, error) {
+	mr, err := h.mergeRequest(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return mr.info(), nil
+}
+
+// ListPullRequests lists merge requests; "open" is "opened" for GitLab, and
+// like the other hosts "closed" includes the merged ones
+func (h *GitLabHost) ListPullRequests(ctx context.Context, state string) ([]*interfaces.PullRequestInfo, error) {
+	path, err := h.projectPath("/merge_requests")
+	if err != nil {
+		return nil, err
+	}
+
+	apiState := state
+	switch state {
+	case "open":
+		apiState = "opened"
+	case "closed":
+		apiState = "all"
+	}
+
+	var prs []*interfaces.PullRequestInfo
+	err = pages(gitlabPageSize, func(page int) (int, error) {
+		query := url.Values{"state": {apiState}, "order_by": {"updated_at"}, "page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(gitlabPageSize)}}
+		var batch []gitlabMergeRequest
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, mr := range batch {
+			info := mr.info()
+			if state == "closed" && info.Status == "open" {
+				continue
+			}
+			prs = append(prs, info)
+		}
+		return len(batch), nil
+	})
+	return prs, err
+}
+
+// UpdatePullRequest updates the title and description of a merge request
+func (h *GitLabHost) UpdatePullRequest(ctx context.Context, number int, title, description string) (*interfaces.PullRequestInfo, error) {
+	path, err := h.projectPath("/merge_requests/%d", number)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{}
+	if title != "" {
+		body["title"] = title
+	}
+	if description != "" {
+		body["description"] = description
+	}
+
+	var mr gitlabMergeRequest
+	if _, err := h.client.do(ctx, http.MethodPut, path, nil, body, &mr); err != nil {
+		return nil, fmt.Errorf("failed to update pull request #%d: %w", number, err)
+	}
+	return mr.info(), nil
+}
+
+// ClosePullRequest closes a merge request
+func (h *GitLabHost) ClosePullRequest(ctx context.Context, number int) error {
+	path, err := h.projectPath("/merge_requests/%d", number)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.client.do(ctx, http.MethodPut, path, nil, map[string]string{"state_event": "close"}, nil); err != nil {
+		return fmt.Errorf("failed to close pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// MergePullRequest accepts a merge request. GitLab applies the merge method
+// configured on the project; "squash" squashes the commits and "rebase"
+// rebases the source branch first.
+func (h *GitLabHost) MergePullRequest(ctx context.Context, number int, commitMessage, mergeMethod string) error {
+	if mergeMethod == "rebase" {
+		path, err := h.projectPath("/merge_requests/%d/rebase", number)
+		if err != nil {
+			return err
+		}
+		if _, err := h.client.do(ctx, http.MethodPut, path, url.Values{"skip_ci": {"true"}}, nil, nil); err != nil {
+			return fmt.Errorf("failed to rebase pull request #%d: %w", number, err)
+		}
+	}
+
+	path, err := h.projectPath("/merge_requests/%d/merge", number)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{"squash": mergeMethod == "squash"}
+	if commitMessage != "" {
+		if mergeMethod == "squash" {
+			body["squash_commit_message"] = commitMessage
+		} else {
+			body["merge_commit_message"] = commitMessage
+		}
+	}
+	if _, err := h.client.do(ctx, http.MethodPut, path, nil, body, nil); err != nil {
+		return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListPullRequestFiles returns the files changed in a merge request
+func (h *GitLabHost) ListPullRequestFiles(ctx context.Context, number int) ([]string, error) {
+	path, err := h.projectPath("/merge_requests/%d/changes", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes struct {
+		Changes []struct {
+			NewPath string `json:"new_path"`
+		} `json:"changes"`
+	}
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &changes); err != nil {
+		return nil, fmt.Errorf("failed to get pull request files: %w", err)
+	}
+
+	files := make([]string, 0, len(changes.Changes))
+	for _, change := range changes.Changes {
+		files = append(files, change.NewPath)
+	}
+	return files, nil
+}
+
+// AddComment adds a note to a merge request
+func (h *GitLabHost) AddComment(ctx context.Context, number int, body string) error {
+	path, err := h.projectPath("/merge_requests/%d/notes", number)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to add comment to pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// ListComments returns the notes of a merge request, oldest first, without
+// the notes generated by GitLab
+func (h *GitLabHost) ListComments(ctx context.Context, number int) ([]*Comment, error) {
+	path, err := h.projectPath("/merge_requests/%d/notes", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []*Comment
+	err = pages(gitlabPageSize, func(page int) (int, error) {
+		query := url.Values{"sort": {"asc"}, "order_by": {"created_at"}, "page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(gitlabPageSize)}}
+		var batch []gitlabNote
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to get pull request comments: %w", err)
+		}
+		for _, note := range batch {
+			if note.System {
+				continue
+			}
+			comments = append(comments, &Comment{
+				ID:        note.ID,
+				Author:    note.Author.Username,
+				Body:      note.Body,
+				CreatedAt: note.CreatedAt,
+				UpdatedAt: note.UpdatedAt,
+			})
+		}
+		return len(batch), nil
+	})
+	return comments, err
+}
+
+// SubmitReview approves a merge request; GitLab has no review object, so
+// other reviews are recorded as notes
+func (h *GitLabHost) SubmitReview(ctx context.Context, number int, review Review) (*Review, error) {
+	if review.State == ReviewApproved {
+		path, err := h.projectPath("/merge_requests/%d/approve", number)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := h.client.do(ctx, http.MethodPost, path, nil, map[string]string{}, nil); err != nil {
+			return nil, fmt.Errorf("failed to review pull request #%d: %w", number, err)
+		}
+		if review.Body != "" {
+			if err := h.AddComment(ctx, number, review.Body); err != nil {
+				return nil, err
+			}
+		}
+		return &Review{State: ReviewApproved, Body: review.Body, SubmittedAt: time.Now()}, nil
+	}
+
+	body := review.Body
+	if review.State == ReviewChangesRequested {
+		body = "Changes requested: " + body
+	}
+	if err := h.AddComment(ctx, number, body); err != nil {
+		return nil, err
+	}
+	return &Review{State: review.State, Body: review.Body, SubmittedAt: time.Now()}, nil
+}
+
+// ListReviews returns the approvals of a merge request
+func (h *GitLabHost) ListReviews(ctx context.Context, number int) ([]*Review, error) {
+	path, err := h.projectPath("/merge_requests/%d/approvals", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals struct {
+		ApprovedBy []struct {
+			User gitlabUser `json:"user"`
+		} `json:"approved_by"`
+	}
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &approvals); err != nil {
+		return nil, fmt.Errorf("failed to list reviews of pull request #%d: %w", number, err)
+	}
+
+	reviews := make([]*Review, 0, len(approvals.ApprovedBy))
+	for _, approval := range approvals.ApprovedBy {
+		reviews = append(reviews, &Review{Author: approval.User.Username, State: ReviewApproved})
+	}
+	return reviews, nil
+}
+
+// SetCommitStatus creates a commit status; GitLab requires a commit SHA, so
+// branches are resolved first
+func (h *GitLabHost) SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error) {
+	sha, err := h.commitSHA(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	path, err := h.projectPath("/statuses/%s", url.PathEscape(sha))
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{
+		"state":       gitlabStatusStates[status.State],
+		"name":        status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+	}
+	var created gitlabStatus
+	if _, err := h.client.do(ctx, http.MethodPost, path, nil, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to set status %s on %s: %w", status.Context, ref, err)
+	}
+	return created.commitStatus(), nil
+}
+
+// ListCommitStatuses returns the statuses of ref, newest first
+func (h *GitLabHost) ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error) {
+	sha, err := h.commitSHA(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	path, err := h.projectPath("/repository/commits/%s/statuses", url.PathEscape(sha))
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*CommitStatus
+	err = pages(gitlabPageSize, func(page int) (int, error) {
+		query := url.Values{"all": {"true"}, "page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(gitlabPageSize)}}
+		var batch []gitlabStatus
+		if _, err := h.client.do(ctx, http.MethodGet, path, query, nil, &batch); err != nil {
+			return 0, fmt.Errorf("failed to list statuses of %s: %w", ref, err)
+		}
+		for _, status := range batch {
+			statuses = append(statuses, status.commitStatus())
+		}
+		return len(batch), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// GitLab lists the statuses oldest first
+	for i, j := 0, len(statuses)-1; i < j; i, j = i+1, j-1 {
+		statuses[i], statuses[j] = statuses[j], statuses[i]
+	}
+	return statuses, nil
+}
+
+// Ping checks that the API answers
+func (h *GitLabHost) Ping(ctx context.Context) error {
+	if _, err := h.client.do(ctx, http.MethodGet, "/version", nil, nil, nil); err != nil {
+		return fmt.Errorf("GitLab API health check failed: %w", err)
+	}
+	return nil
+}
+
+func (h *GitLabHost) mergeRequest(ctx context.Context, number int) (*gitlabMergeRequest, error) {
+	path, err := h.projectPath("/merge_requests/%d", number)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr gitlabMergeRequest
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return &mr, nil
+}
+
+// commitSHA resolves a branch, tag or SHA to a commit SHA
+func (h *GitLabHost) commitSHA(ctx context.Context, ref string) (string, error) {
+	path, err := h.projectPath("/repository/commits/%s", url.PathEscape(ref))
+	if err != nil {
+		return "", err
+	}
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if _, err := h.client.do(ctx, http.MethodGet, path, nil, nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return commit.ID, nil
+}
+
+func (mr *gitlabMergeRequest) info() *interfaces.PullRequestInfo {
+	return &interfaces.PullRequestInfo{
+		ID:           mr.IID,
+		Title:        mr.Title,
+		Description:  mr.Description,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		Status:       normalizeState(mr.State, false),
+		CreatedAt:    mr.CreatedAt,
+		UpdatedAt:    mr.UpdatedAt,
+	}
+}
+
+func (s *gitlabStatus) commitStatus() *CommitStatus {
+	state := StatusPending
+	switch s.Status {
+	case "success":
+		state = StatusSuccess
+	case "failed":
+		state = StatusFailure
+	case "canceled", "skipped":
+		state = StatusError
+	}
+	return &CommitStatus{
+		Context:     s.Name,
+		State:       state,
+		Description: s.Description,
+		TargetURL:   s.TargetURL,
+		CreatedAt:   s.CreatedAt,
+	}
+}
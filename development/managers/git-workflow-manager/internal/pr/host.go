@@ -0,0 +1,170 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+// Review states, named after the GitHub API
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+)
+
+// Commit status states, named after the GitHub API
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusError   = "error"
+)
+
+// Review is a review submitted on a pull request
+type Review struct {
+	ID          int64     `json:"id"`
+	Author      string    `json:"author"`
+	State       string    `json:"state"`
+	Body        string    `json:"body"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// Comment is a discussion comment on a pull request
+type Comment struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CommitStatus is a CI check reported on a commit
+type CommitStatus struct {
+	Context     string    `json:"context"`
+	State       string    `json:"state"`
+	Description string    `json:"description,omitempty"`
+	TargetURL   string    `json:"target_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GitHost is the code hosting service backing pull requests. Numbers are the
+// pull (or merge) request numbers shown to users, not internal IDs.
+type GitHost interface {
+	// Name identifies the implementation ("github", "local", "gitea", "gitlab")
+	Name() string
+	// SetRepository selects the repository the following calls apply to
+	SetRepository(owner, repo string)
+
+	CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error)
+	GetPullRequest(ctx context.Context, number int) (*interfaces.PullRequestInfo, error)
+	// ListPullRequests accepts "open", "closed", "merged" or "all"
+	ListPullRequests(ctx context.Context, state string) ([]*interfaces.PullRequestInfo, error)
+	// UpdatePullRequest leaves empty fields unchanged
+	UpdatePullRequest(ctx context.Context, number int, title, description string) (*interfaces.PullRequestInfo, error)
+	ClosePullRequest(ctx context.Context, number int) error
+	// MergePullRequest merges with "merge", "squash" or "rebase"
+	MergePullRequest(ctx context.Context, number int, commitMessage, mergeMethod string) error
+	ListPullRequestFiles(ctx context.Context, number int) ([]string, error)
+
+	AddComment(ctx context.Context, number int, body string) error
+	ListComments(ctx context.Context, number int) ([]*Comment, error)
+	// SubmitReview records a review; Author is only used by hosts without
+	// authentication, the others use the account of the token
+	SubmitReview(ctx context.Context, number int, review Review) (*Review, error)
+	ListReviews(ctx context.Context, number int) ([]*Review, error)
+
+	// SetCommitStatus reports a check on ref (branch or commit)
+	SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error)
+	// ListCommitStatuses returns the checks reported on ref, newest first
+	ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error)
+
+	// Ping checks that the host is reachable with the configured credentials
+	Ping(ctx context.Context) error
+}
+
+// HostConfig selects and configures a GitHost
+type HostConfig struct {
+	Type  string // "github" (default), "local", "gitea" or "gitlab"
+	URL   string // API base URL for gitea and gitlab, GitHub Enterprise URL for github
+	Token string
+	Owner string
+	Repo  string
+
+	// Local host only
+	Path      string // repository holding the branches, usually bare
+	StorePath string // JSON file holding pull requests, reviews and statuses
+	PushFrom  string // working repository whose branches are pushed on pull request creation
+	Author    string // author of reviews, comments and merge commits
+}
+
+// NewGitHost creates the host described by config
+func NewGitHost(config HostConfig) (GitHost, error) {
+	var host GitHost
+	var err error
+
+	switch strings.ToLower(config.Type) {
+	case "", "github":
+		host, err = NewGitHubHost(config.Token, config.URL)
+	case "local":
+		host, err = NewLocalHost(LocalHostConfig{
+			RepositoryPath: config.Path,
+			StorePath:      config.StorePath,
+			PushFrom:       config.PushFrom,
+			Author:         config.Author,
+		})
+	case "gitea":
+		host, err = NewGiteaHost(config.URL, config.Token)
+	case "gitlab":
+		host, err = NewGitLabHost(config.URL, config.Token)
+	default:
+		return nil, fmt.Errorf("unsupported git host type: %s", config.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Owner != "" || config.Repo != "" {
+		host.SetRepository(config.Owner, config.Repo)
+	}
+	return host, nil
+}
+
+// normalizeState maps the host-specific pull request states to the
+// "open", "closed" and "merged" values of PullRequestInfo
+func normalizeState(state string, merged bool) string {
+	if merged {
+		return "merged"
+	}
+	switch strings.ToLower(state) {
+	case "opened", "open":
+		return "open"
+	case "merged":
+		return "merged"
+	default:
+		return "closed"
+	}
+}
+
+// validateStatusState checks a commit status state
+func validateStatusState(state string) error {
+	switch state {
+	case StatusPending, StatusSuccess, StatusFailure, StatusError:
+		return nil
+	default:
+		return fmt.Errorf("invalid commit status state: %s", state)
+	}
+}
+
+// validateReviewState checks a review state
+func validateReviewState(state string) error {
+	switch state {
+	case ReviewApproved, ReviewChangesRequested, ReviewCommented:
+		return nil
+	default:
+		return fmt.Errorf("invalid review state: %s", state)
+	}
+}
@@ -0,0 +1,628 @@
+package pr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+// LocalHostConfig configures a LocalHost
+type LocalHostConfig struct {
+	// RepositoryPath is the repository playing the role of the remote,
+	// usually a bare repository. Merges update its branches directly, so a
+	// non-bare repository must not have the target branch checked out.
+	RepositoryPath string
+	// StorePath is the JSON file holding pull requests, comments, reviews and
+	// statuses; it defaults to "pull-requests.json" in the repository
+	StorePath string
+	// PushFrom, when set, is a working repository whose source and target
+	// branches are pushed to the host when a pull request is opened, like a
+	// "git push" before opening the pull request on a real host
+	PushFrom string
+	// Author signs reviews and comments without author and merge commits
+	Author string
+	// GitExecutable defaults to "git" from the PATH
+	GitExecutable string
+}
+
+// LocalHost implements GitHost offline: branches live in a local repository
+// and everything else in a JSON file. It lets workflows be tested end to end
+// without network access.
+type LocalHost struct {
+	config LocalHostConfig
+	mu     sync.Mutex
+}
+
+// localStore is the content of the store file
+type localStore struct {
+	NextNumber   int                        `json:"next_number"`
+	NextID       int64                      `json:"next_id"`
+	PullRequests []*localPullRequest        `json:"pull_requests"`
+	Statuses     map[string][]*CommitStatus `json:"statuses"` // by commit SHA, oldest first
+}
+
+type localPullRequest struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	SourceBranch string     `json:"source_branch"`
+	TargetBranch string     `json:"target_branch"`
+	State        string     `json:"state"`
+	BaseSHA      string     `json:"base_sha"`
+	HeadSHA      string     `json:"head_sha,omitempty"` // recorded when merged or closed
+	MergeCommit  string     `json:"merge_commit,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Comments     []*Comment `json:"comments,omitempty"`
+	Reviews      []*Review  `json:"reviews,omitempty"`
+}
+
+// NewLocalHost creates a host over an existing repository
+func NewLocalHost(config LocalHostConfig) (*LocalHost, error) {
+	if config.RepositoryPath == "" {
+		return nil, fmt.Errorf("local git host requires a repository path")
+	}
+	if config.GitExecutable == "" {
+		config.GitExecutable = "git"
+	}
+	if config.Author == "" {
+		config.Author = "git-workflow-manager"
+	}
+
+	host := &LocalHost{config: config}
+	gitDir, err := host.git(context.Background(), "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", config.RepositoryPath, err)
+	}
+	if host.config.StorePath == "" {
+		host.config.StorePath = filepath.Join(gitDir, "pull-requests.json")
+	}
+	return host, nil
+}
+
+// Name returns "local"
+func (h *LocalHost) Name() string {
+	return "local"
+}
+
+// SetRepository is a no-op: the local host serves a single repository
+func (h *LocalHost) SetRepository(owner, repo string) {}
+
+// CreatePullRequest opens a pull request between two branches of the repository
+func (h *LocalHost) CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error) {
+	if h.config.PushFrom != "" {
+		if err := h.pushBranches(ctx, sourceBranch, targetBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := h.resolve(ctx, "refs/heads/"+sourceBranch); err != nil {
+		return nil, fmt.Errorf("source branch %s does not exist: %w", sourceBranch, err)
+	}
+	baseSHA, err := h.resolve(ctx, "refs/heads/"+targetBranch)
+	if err != nil {
+		return nil, fmt.Errorf("target branch %s does not exist: %w", targetBranch, err)
+	}
+
+	var created *localPullRequest
+	err = h.update(func(store *localStore) error {
+		for _, pr := range store.PullRequests {
+			if pr.State == "open" && pr.SourceBranch == sourceBranch && pr.TargetBranch == targetBranch {
+				return fmt.Errorf("a pull request already exists for %s into %s: #%d", sourceBranch, targetBranch, pr.Number)
+			}
+		}
+
+		now := time.Now().UTC()
+		store.NextNumber++
+		created = &localPullRequest{
+			Number:       store.NextNumber,
+			Title:        title,
+			Description:  description,
+			SourceBranch: sourceBranch,
+			TargetBranch: targetBranch,
+			State:        "open",
+			BaseSHA:      baseSHA,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		store.PullRequests = append(store.PullRequests, created)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created.info(), nil
+}
+
+// GetPullRequest returns a pull request
+func (h *LocalHost) GetPullRequest(ctx context.Context, number int) (*interfaces.PullRequestInfo, error) {
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	pr, err := store.find(number)
+	if err != nil {
+		return nil, err
+	}
+	return pr.info(), nil
+}
+
+// ListPullRequests lists pull requests, most recently updated first
+func (h *LocalHost) ListPullRequests(ctx context.Context, state string) ([]*interfaces.PullRequestInfo, error) {
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []*interfaces.PullRequestInfo
+	for _, pr := range store.PullRequests {
+		// Like GitHub, "closed" includes merged pull requests
+		switch {
+		case state == "all",
+			state == pr.State,
+			state == "closed" && pr.State == "merged":
+			prs = append(prs, pr.info())
+		}
+	}
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].UpdatedAt.After(prs[j].UpdatedAt)
+	})
+	return prs, nil
+}
+
+// UpdatePullRequest updates the title and description of a pull request
+func (h *LocalHost) UpdatePullRequest(ctx context.Context, number int, title, description string) (*interfaces.PullRequestInfo, error) {
+	var updated *localPullRequest
+	err := h.update(func(store *localStore) error {
+		pr, err := store.find(number)
+		if err != nil {
+			return err
+		}
+		if title != "" {
+			pr.Title = title
+		}
+		if description != "" {
+			pr.Description = description
+		}
+		pr.UpdatedAt = time.Now().UTC()
+		updated = pr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated.info(), nil
+}
+
+// ClosePullRequest closes a pull request without merging it
+func (h *LocalHost) ClosePullRequest(ctx context.Context, number int) error {
+	return h.update(func(store *localStore) error {
+		pr, err := store.find(number)
+		if err != nil {
+			return err
+		}
+		if pr.State != "open" {
+			return fmt.Errorf("pull request #%d is %s", number, pr.State)
+		}
+		if headSHA, err := h.resolve(ctx, "refs/heads/"+pr.SourceBranch); err == nil {
+			pr.HeadSHA = headSHA
+		}
+		pr.State = "closed"
+		pr.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// MergePullRequest merges the source branch into the target branch of the
+// repository without a worktree. "merge" creates a merge commit, "squash" a
+// single commit on the target and "rebase" fast-forwards the target, which
+// must then be an ancestor of the source.
+func (h *LocalHost) MergePullRequest(ctx context.Context, number int, commitMessage, mergeMethod string) error {
+	return h.update(func(store *localStore) error {
+		pr, err := store.find(number)
+		if err != nil {
+			return err
+		}
+		if pr.State != "open" {
+			return fmt.Errorf("pull request #%d is %s", number, pr.State)
+		}
+
+		headSHA, err := h.resolve(ctx, "refs/heads/"+pr.SourceBranch)
+		if err != nil {
+			return fmt.Errorf("source branch %s does not exist: %w", pr.SourceBranch, err)
+		}
+		baseSHA, err := h.resolve(ctx, "refs/heads/"+pr.TargetBranch)
+		if err != nil {
+			return fmt.Errorf("target branch %s does not exist: %w", pr.TargetBranch, err)
+		}
+		if h.checkedOut(ctx, pr.TargetBranch) {
+			return fmt.Errorf("failed to merge pull request #%d: %s is checked out in %s, use a bare repository as host", number, pr.TargetBranch, h.config.RepositoryPath)
+		}
+
+		if commitMessage == "" {
+			commitMessage = fmt.Sprintf("Merge pull request #%d from %s\n\n%s", pr.Number, pr.SourceBranch, pr.Title)
+		}
+
+		var mergeCommit string
+		switch mergeMethod {
+		case "merge", "squash":
+			tree, err := h.mergeTree(ctx, baseSHA, headSHA)
+			if err != nil {
+				return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+			}
+			args := []string{"commit-tree", tree, "-p", baseSHA}
+			if mergeMethod == "merge" {
+				args = append(args, "-p", headSHA)
+			}
+			mergeCommit, err = h.git(ctx, append(args, "-m", commitMessage)...)
+			if err != nil {
+				return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+			}
+		case "rebase":
+			if _, err := h.git(ctx, "merge-base", "--is-ancestor", baseSHA, headSHA); err != nil {
+				return fmt.Errorf("failed to merge pull request #%d: %s is not up to date with %s", number, pr.SourceBranch, pr.TargetBranch)
+			}
+			mergeCommit = headSHA
+		default:
+			return fmt.Errorf("invalid merge method: %s", mergeMethod)
+		}
+
+		// Compare and swap: fails if the target moved since it was resolved
+		if _, err := h.git(ctx, "update-ref", "-m", "merge pull request", "refs/heads/"+pr.TargetBranch, mergeCommit, baseSHA); err != nil {
+			return fmt.Errorf("failed to update %s: %w", pr.TargetBranch, err)
+		}
+
+		pr.State = "merged"
+		pr.HeadSHA = headSHA
+		pr.MergeCommit = mergeCommit
+		pr.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// ListPullRequestFiles returns the files changed by the source branch since
+// it forked from the target branch
+func (h *LocalHost) ListPullRequestFiles(ctx context.Context, number int) ([]string, error) {
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	pr, err := store.find(number)
+	if err != nil {
+		return nil, err
+	}
+
+	base, head := pr.BaseSHA, pr.HeadSHA
+	if pr.State == "open" {
+		if base, err = h.resolve(ctx, "refs/heads/"+pr.TargetBranch); err != nil {
+			return nil, err
+		}
+		if head, err = h.resolve(ctx, "refs/heads/"+pr.SourceBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := h.git(ctx, "diff", "--name-only", base+"..."+head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request files: %w", err)
+	}
+	return splitLines(output), nil
+}
+
+// AddComment adds a comment signed by the configured author
+func (h *LocalHost) AddComment(ctx context.Context, number int, body string) error {
+	return h.update(func(store *localStore) error {
+		pr, err := store.find(number)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		store.NextID++
+		pr.Comments = append(pr.Comments, &Comment{
+			ID:        store.NextID,
+			Author:    h.config.Author,
+			Body:      body,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		return nil
+	})
+}
+
+// ListComments returns the comments of a pull request, oldest first
+func (h *LocalHost) ListComments(ctx context.Context, number int) ([]*Comment, error) {
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	pr, err := store.find(number)
+	if err != nil {
+		return nil, err
+	}
+	return pr.Comments, nil
+}
+
+// SubmitReview records a review; without author it is signed by the
+// configured author
+func (h *LocalHost) SubmitReview(ctx context.Context, number int, review Review) (*Review, error) {
+	if err := validateReviewState(review.State); err != nil {
+		return nil, err
+	}
+
+	var created *Review
+	err := h.update(func(store *localStore) error {
+		pr, err := store.find(number)
+		if err != nil {
+			return err
+		}
+		if pr.State != "open" {
+			return fmt.Errorf("pull request #%d is %s", number, pr.State)
+		}
+
+		store.NextID++
+		created = &Review{
+			ID:          store.NextID,
+			Author:      review.Author,
+			State:       review.State,
+			Body:        review.Body,
+			SubmittedAt: time.Now().UTC(),
+		}
+		if created.Author == "" {
+			created.Author = h.config.Author
+		}
+		pr.Reviews = append(pr.Reviews, created)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListReviews returns the reviews of a pull request, oldest first
+func (h *LocalHost) ListReviews(ctx context.Context, number int) ([]*Review, error) {
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	pr, err := store.find(number)
+	if err != nil {
+		return nil, err
+	}
+	return pr.Reviews, nil
+}
+
+// SetCommitStatus records a status on the commit ref points to
+func (h *LocalHost) SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error) {
+	if err := validateStatusState(status.State); err != nil {
+		return nil, err
+	}
+	if status.Context == "" {
+		status.Context = "default"
+	}
+
+	sha, err := h.resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set status %s on %s: %w", status.Context, ref, err)
+	}
+
+	status.CreatedAt = time.Now().UTC()
+	err = h.update(func(store *localStore) error {
+		store.Statuses[sha] = append(store.Statuses[sha], &status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ListCommitStatuses returns the statuses of the commit ref points to, newest first
+func (h *LocalHost) ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error) {
+	sha, err := h.resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statuses of %s: %w", ref, err)
+	}
+
+	store, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	recorded := store.Statuses[sha]
+	statuses := make([]*CommitStatus, 0, len(recorded))
+	for i := len(recorded) - 1; i >= 0; i-- {
+		statuses = append(statuses, recorded[i])
+	}
+	return statuses, nil
+}
+
+// Ping checks that the repository and the store are readable
+func (h *LocalHost) Ping(ctx context.Context) error {
+	if _, err := h.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("local git host health check failed: %w", err)
+	}
+	if _, err := h.read(); err != nil {
+		return fmt.Errorf("local git host health check failed: %w", err)
+	}
+	return nil
+}
+
+// pushBranches pushes the source branch (forced, it may have been rebased)
+// and the target branch when the host does not have it yet
+func (h *LocalHost) pushBranches(ctx context.Context, sourceBranch, targetBranch string) error {
+	refspecs := []string{fmt.Sprintf("+refs/heads/%s:refs/heads/%s", sourceBranch, sourceBranch)}
+	if _, err := h.resolve(ctx, "refs/heads/"+targetBranch); err != nil {
+		refspecs = append(refspecs, fmt.Sprintf("refs/heads/%s:refs/heads/%s", targetBranch, targetBranch))
+	}
+
+	pushFrom, err := filepath.Abs(h.config.PushFrom)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"fetch", "--no-tags", pushFrom}, refspecs...)
+	if _, err := h.git(ctx, args...); err != nil {
+		return fmt.Errorf("failed to push %s from %s: %w", sourceBranch, h.config.PushFrom, err)
+	}
+	return nil
+}
+
+// mergeTree computes the tree of the merge of two commits, failing with the
+// conflicting files
+func (h *LocalHost) mergeTree(ctx context.Context, base, head string) (string, error) {
+	output, err := h.git(ctx, "merge-tree", "--write-tree", "--name-only", "--no-messages", base, head)
+	lines := splitLines(output)
+	if err != nil {
+		if len(lines) > 1 {
+			return "", fmt.Errorf("merge conflict in %s", strings.Join(lines[1:], ", "))
+		}
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "", errors.New("git merge-tree returned no tree")
+	}
+	return lines[0], nil
+}
+
+// checkedOut reports whether branch is the HEAD of a non-bare repository,
+// whose worktree would not follow a ref update
+func (h *LocalHost) checkedOut(ctx context.Context, branch string) bool {
+	if bare, _ := h.git(ctx, "rev-parse", "--is-bare-repository"); bare == "true" {
+		return false
+	}
+	head, err := h.git(ctx, "symbolic-ref", "--quiet", "HEAD")
+	return err == nil && head == "refs/heads/"+branch
+}
+
+// resolve returns the commit SHA of a revision
+func (h *LocalHost) resolve(ctx context.Context, rev string) (string, error) {
+	return h.git(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+}
+
+// git runs git in the repository; merge commits are signed by the configured author
+func (h *LocalHost) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, h.config.GitExecutable, args...)
+	cmd.Dir = h.config.RepositoryPath
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+h.config.Author,
+		"GIT_AUTHOR_EMAIL="+h.config.Author+"@localhost",
+		"GIT_COMMITTER_NAME="+h.config.Author,
+		"GIT_COMMITTER_EMAIL="+h.config.Author+"@localhost",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	output := strings.TrimSpace(stdout.String())
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return output, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return output, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// read loads the store; a missing file is an empty store
+func (h *LocalHost) read() (*localStore, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.load()
+}
+
+// update applies fn to the store and saves it when fn succeeds
+func (h *LocalHost) update(fn func(store *localStore) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	store, err := h.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return h.save(store)
+}
+
+func (h *LocalHost) load() (*localStore, error) {
+	store := &localStore{Statuses: make(map[string][]*CommitStatus)}
+
+	data, err := os.ReadFile(h.config.StorePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull request store: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request store %s: %w", h.config.StorePath, err)
+	}
+	if store.Statuses == nil {
+		store.Statuses = make(map[string][]*CommitStatus)
+	}
+	return store, nil
+}
+
+// save writes the store through a temporary file so that readers never see
+// a partial file
+func (h *LocalHost) save(store *localStore) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := h.config.StorePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pull request store: %w", err)
+	}
+	if err := os.Rename(tmp, h.config.StorePath); err != nil {
+		return fmt.Errorf("failed to write pull request store: %w", err)
+	}
+	return nil
+}
+
+func (s *localStore) find(number int) (*localPullRequest, error) {
+	for _, pr := range s.PullRequests {
+		if pr.Number == number {
+			return pr, nil
+		}
+	}
+	return nil, fmt.Errorf("pull request #%d not found", number)
+}
+
+func (pr *localPullRequest) info() *interfaces.PullRequestInfo {
+	return &interfaces.PullRequestInfo{
+		ID:           pr.Number,
+		Title:        pr.Title,
+		Description:  pr.Description,
+		SourceBranch: pr.SourceBranch,
+		TargetBranch: pr.TargetBranch,
+		Status:       pr.State,
+		CreatedAt:    pr.CreatedAt,
+		UpdatedAt:    pr.UpdatedAt,
+	}
+}
+
+func splitLines(output string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
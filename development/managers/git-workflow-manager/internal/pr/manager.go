@@ -4,67 +4,62 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/gerivdb/email-sender-1/managers/interfaces"
-	"github.com/google/go-github/v58/github"
-	"golang.org/x/oauth2"
 )
 
-// Manager handles GitHub Pull Request operations
+// Manager handles pull request operations on a GitHost
 type Manager struct {
-	client       *github.Client
-	owner        string
-	repo         string
+	host         GitHost
 	errorManager interfaces.ErrorManager
 }
 
-// NewManager creates a new pull request manager
+// NewManager creates a new pull request manager backed by GitHub
 func NewManager(githubToken string, errorManager interfaces.ErrorManager) (*Manager, error) {
 	if errorManager == nil {
 		return nil, fmt.Errorf("error manager is required")
 	}
 
-	var client *github.Client
+	host, err := NewGitHubHost(githubToken, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerWithHost(host, errorManager)
+}
 
-	if githubToken != "" {
-		// Create authenticated client
-		ctx := context.Background()
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: githubToken},
-		)
-		tc := oauth2.NewClient(ctx, ts)
-		client = github.NewClient(tc)
-	} else {
-		// Create unauthenticated client (limited functionality)
-		client = github.NewClient(nil)
-		log.Printf("Warning: GitHub token not provided, PR functionality will be limited")
+// NewManagerWithHost creates a new pull request manager backed by host
+func NewManagerWithHost(host GitHost, errorManager interfaces.ErrorManager) (*Manager, error) {
+	if host == nil {
+		return nil, fmt.Errorf("git host is required")
+	}
+	if errorManager == nil {
+		return nil, fmt.Errorf("error manager is required")
 	}
 
 	manager := &Manager{
-		client:       client,
+		host:         host,
 		errorManager: errorManager,
-		// owner and repo will be set dynamically or from config
+		// the repository will be set dynamically or from config
 	}
 
-	log.Printf("Pull Request manager initialized")
+	log.Printf("Pull Request manager initialized with %s host", host.Name())
 	return manager, nil
 }
 
-// SetRepository sets the GitHub repository for operations
+// Host returns the git host backing the manager
+func (m *Manager) Host() GitHost {
+	return m.host
+}
+
+// SetRepository sets the repository for operations
 func (m *Manager) SetRepository(owner, repo string) {
-	m.owner = owner
-	m.repo = repo
+	m.host.SetRepository(owner, repo)
 	log.Printf("PR manager configured for repository: %s/%s", owner, repo)
 }
 
 // CreatePullRequest creates a new pull request
 func (m *Manager) CreatePullRequest(ctx context.Context, title, description, sourceBranch, targetBranch string) (*interfaces.PullRequestInfo, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if title == "" {
 		return nil, fmt.Errorf("pull request title cannot be empty")
 	}
@@ -77,39 +72,9 @@ func (m *Manager) CreatePullRequest(ctx context.Context, title, description, sou
 		targetBranch = "main" // Default to main branch
 	}
 
-	// Validate branches exist
-	_, _, err := m.client.Git.GetRef(ctx, m.owner, m.repo, "heads/"+sourceBranch)
-	if err != nil {
-		return nil, fmt.Errorf("source branch %s does not exist: %w", sourceBranch, err)
-	}
-
-	_, _, err = m.client.Git.GetRef(ctx, m.owner, m.repo, "heads/"+targetBranch)
-	if err != nil {
-		return nil, fmt.Errorf("target branch %s does not exist: %w", targetBranch, err)
-	}
-
-	// Create pull request
-	pr := &github.NewPullRequest{
-		Title:               github.String(title),
-		Head:                github.String(sourceBranch),
-		Base:                github.String(targetBranch),
-		Body:                github.String(description),
-		MaintainerCanModify: github.Bool(true),
-	}
-
-	createdPR, _, err := m.client.PullRequests.Create(ctx, m.owner, m.repo, pr)
+	prInfo, err := m.host.CreatePullRequest(ctx, title, description, sourceBranch, targetBranch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pull request: %w", err)
-	}
-
-	prInfo := &interfaces.PullRequestInfo{
-		ID:           *createdPR.Number,
-		Title:        *createdPR.Title,
-		Description:  *createdPR.Body,
-		SourceBranch: *createdPR.Head.Ref,
-		TargetBranch: *createdPR.Base.Ref, Status: strings.ToLower(*createdPR.State),
-		CreatedAt: createdPR.CreatedAt.Time,
-		UpdatedAt: createdPR.UpdatedAt.Time,
+		return nil, err
 	}
 
 	log.Printf("Created pull request #%d: %s", prInfo.ID, prInfo.Title)
@@ -118,75 +83,23 @@ func (m *Manager) CreatePullRequest(ctx context.Context, title, description, sou
 
 // GetPullRequestStatus returns the status of a pull request
 func (m *Manager) GetPullRequestStatus(ctx context.Context, prID int) (*interfaces.PullRequestInfo, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
 	}
 
-	pr, _, err := m.client.PullRequests.Get(ctx, m.owner, m.repo, prID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get pull request #%d: %w", prID, err)
-	}
-
-	prInfo := &interfaces.PullRequestInfo{
-		ID:           *pr.Number,
-		Title:        *pr.Title,
-		Description:  *pr.Body,
-		SourceBranch: *pr.Head.Ref,		TargetBranch: *pr.Base.Ref,
-		Status:       strings.ToLower(*pr.State),
-		CreatedAt:    pr.CreatedAt.Time,
-		UpdatedAt:    pr.UpdatedAt.Time,
-	}
-	
-	return prInfo, nil
+	return m.host.GetPullRequest(ctx, prID)
 }
 
 // ListPullRequests returns a list of pull requests filtered by status
 func (m *Manager) ListPullRequests(ctx context.Context, status string) ([]*interfaces.PullRequestInfo, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	// Normalize status
 	if status == "" {
 		status = "open"
 	}
 
-	opts := &github.PullRequestListOptions{
-		State:       status,
-		Sort:        "updated",
-		Direction:   "desc",
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
-
-	var allPRs []*interfaces.PullRequestInfo
-
-	for {
-		prs, resp, err := m.client.PullRequests.List(ctx, m.owner, m.repo, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list pull requests: %w", err)
-		}
-
-		for _, pr := range prs {
-			prInfo := &interfaces.PullRequestInfo{
-				ID:           *pr.Number,
-				Title:        *pr.Title,
-				Description:  *pr.Body,
-				SourceBranch: *pr.Head.Ref,
-				TargetBranch: *pr.Base.Ref,
-				Status:       strings.ToLower(*pr.State),				CreatedAt:    pr.CreatedAt.Time,
-				UpdatedAt:    pr.UpdatedAt.Time,
-			}
-			allPRs = append(allPRs, prInfo)
-		}
-
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+	allPRs, err := m.host.ListPullRequests(ctx, status)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("Found %d pull requests with status: %s", len(allPRs), status)
@@ -195,38 +108,13 @@ func (m *Manager) ListPullRequests(ctx context.Context, status string) ([]*inter
 
 // UpdatePullRequest updates an existing pull request
 func (m *Manager) UpdatePullRequest(ctx context.Context, prID int, title, description string) (*interfaces.PullRequestInfo, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
 	}
 
-	// Prepare update
-	pr := &github.PullRequest{}
-
-	if title != "" {
-		pr.Title = github.String(title)
-	}
-
-	if description != "" {
-		pr.Body = github.String(description)
-	}
-
-	updatedPR, _, err := m.client.PullRequests.Edit(ctx, m.owner, m.repo, prID, pr)
+	prInfo, err := m.host.UpdatePullRequest(ctx, prID, title, description)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update pull request #%d: %w", prID, err)
-	}
-
-	prInfo := &interfaces.PullRequestInfo{
-		ID:           *updatedPR.Number,
-		Title:        *updatedPR.Title,
-		Description:  *updatedPR.Body,
-		SourceBranch: *updatedPR.Head.Ref,
-		TargetBranch: *updatedPR.Base.Ref,		Status:       strings.ToLower(*updatedPR.State),
-		CreatedAt:    updatedPR.CreatedAt.Time,
-		UpdatedAt:    updatedPR.UpdatedAt.Time,
+		return nil, err
 	}
 
 	log.Printf("Updated pull request #%d", prID)
@@ -235,21 +123,12 @@ func (m *Manager) UpdatePullRequest(ctx context.Context, prID int, title, descri
 
 // ClosePullRequest closes a pull request
 func (m *Manager) ClosePullRequest(ctx context.Context, prID int) error {
-	if m.owner == "" || m.repo == "" {
-		return fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return fmt.Errorf("invalid pull request ID: %d", prID)
 	}
 
-	pr := &github.PullRequest{
-		State: github.String("closed"),
-	}
-
-	_, _, err := m.client.PullRequests.Edit(ctx, m.owner, m.repo, prID, pr)
-	if err != nil {
-		return fmt.Errorf("failed to close pull request #%d: %w", prID, err)
+	if err := m.host.ClosePullRequest(ctx, prID); err != nil {
+		return err
 	}
 
 	log.Printf("Closed pull request #%d", prID)
@@ -258,10 +137,6 @@ func (m *Manager) ClosePullRequest(ctx context.Context, prID int) error {
 
 // MergePullRequest merges a pull request
 func (m *Manager) MergePullRequest(ctx context.Context, prID int, commitMessage string, mergeMethod string) error {
-	if m.owner == "" || m.repo == "" {
-		return fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return fmt.Errorf("invalid pull request ID: %d", prID)
 	}
@@ -282,10 +157,10 @@ func (m *Manager) MergePullRequest(ctx context.Context, prID int, commitMessage
 
 	if !isValid {
 		return fmt.Errorf("invalid merge method: %s. Valid methods: %v", mergeMethod, validMethods)
-	}	// Use simple merge without options for now
-	_, _, err := m.client.PullRequests.Merge(ctx, m.owner, m.repo, prID, commitMessage, nil)
-	if err != nil {
-		return fmt.Errorf("failed to merge pull request #%d: %w", prID, err)
+	}
+
+	if err := m.host.MergePullRequest(ctx, prID, commitMessage, mergeMethod); err != nil {
+		return err
 	}
 
 	log.Printf("Merged pull request #%d using method: %s", prID, mergeMethod)
@@ -294,42 +169,15 @@ func (m *Manager) MergePullRequest(ctx context.Context, prID int, commitMessage
 
 // GetPullRequestFiles returns the files changed in a pull request
 func (m *Manager) GetPullRequestFiles(ctx context.Context, prID int) ([]string, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
 	}
 
-	opts := &github.ListOptions{PerPage: 100}
-	var allFiles []string
-
-	for {
-		files, resp, err := m.client.PullRequests.ListFiles(ctx, m.owner, m.repo, prID, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get pull request files: %w", err)
-		}
-
-		for _, file := range files {
-			allFiles = append(allFiles, *file.Filename)
-		}
-
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
-	}
-
-	return allFiles, nil
+	return m.host.ListPullRequestFiles(ctx, prID)
 }
 
 // AddComment adds a comment to a pull request
 func (m *Manager) AddComment(ctx context.Context, prID int, comment string) error {
-	if m.owner == "" || m.repo == "" {
-		return fmt.Errorf("repository not configured, call SetRepository first")
-	}
-
 	if prID <= 0 {
 		return fmt.Errorf("invalid pull request ID: %d", prID)
 	}
@@ -338,13 +186,8 @@ func (m *Manager) AddComment(ctx context.Context, prID int, comment string) erro
 		return fmt.Errorf("comment cannot be empty")
 	}
 
-	prComment := &github.IssueComment{
-		Body: github.String(comment),
-	}
-
-	_, _, err := m.client.Issues.CreateComment(ctx, m.owner, m.repo, prID, prComment)
-	if err != nil {
-		return fmt.Errorf("failed to add comment to pull request #%d: %w", prID, err)
+	if err := m.host.AddComment(ctx, prID, comment); err != nil {
+		return err
 	}
 
 	log.Printf("Added comment to pull request #%d", prID)
@@ -353,60 +196,87 @@ func (m *Manager) AddComment(ctx context.Context, prID int, comment string) erro
 
 // GetPullRequestComments returns comments for a pull request
 func (m *Manager) GetPullRequestComments(ctx context.Context, prID int) ([]map[string]interface{}, error) {
-	if m.owner == "" || m.repo == "" {
-		return nil, fmt.Errorf("repository not configured, call SetRepository first")
+	if prID <= 0 {
+		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
+	}
+
+	comments, err := m.host.ListComments(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	var allComments []map[string]interface{}
+	for _, comment := range comments {
+		commentInfo := map[string]interface{}{
+			"id":         comment.ID,
+			"body":       comment.Body,
+			"author":     comment.Author,
+			"created_at": comment.CreatedAt,
+			"updated_at": comment.UpdatedAt,
+		}
+		allComments = append(allComments, commentInfo)
 	}
 
+	return allComments, nil
+}
+
+// SubmitReview submits a review (APPROVED, CHANGES_REQUESTED or COMMENTED) on a pull request
+func (m *Manager) SubmitReview(ctx context.Context, prID int, review Review) (*Review, error) {
 	if prID <= 0 {
 		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
 	}
 
-	opts := &github.IssueListCommentsOptions{
-		Sort:        github.String("created"),
-		Direction:   github.String("asc"),
-		ListOptions: github.ListOptions{PerPage: 100},
+	if err := validateReviewState(review.State); err != nil {
+		return nil, err
 	}
 
-	var allComments []map[string]interface{}
+	created, err := m.host.SubmitReview(ctx, prID, review)
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		comments, resp, err := m.client.Issues.ListComments(ctx, m.owner, m.repo, prID, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get pull request comments: %w", err)
-		}
+	log.Printf("Reviewed pull request #%d: %s", prID, review.State)
+	return created, nil
+}
 
-		for _, comment := range comments {
-			commentInfo := map[string]interface{}{
-				"id":         *comment.ID,
-				"body":       *comment.Body,
-				"author":     *comment.User.Login,
-				"created_at": *comment.CreatedAt,
-				"updated_at": *comment.UpdatedAt,
-			}
-			allComments = append(allComments, commentInfo)
-		}
+// ListReviews returns the reviews of a pull request
+func (m *Manager) ListReviews(ctx context.Context, prID int) ([]*Review, error) {
+	if prID <= 0 {
+		return nil, fmt.Errorf("invalid pull request ID: %d", prID)
+	}
 
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+	return m.host.ListReviews(ctx, prID)
+}
+
+// SetCommitStatus reports a CI check on a branch or commit
+func (m *Manager) SetCommitStatus(ctx context.Context, ref string, status CommitStatus) (*CommitStatus, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("ref cannot be empty")
 	}
 
-	return allComments, nil
+	if err := validateStatusState(status.State); err != nil {
+		return nil, err
+	}
+
+	return m.host.SetCommitStatus(ctx, ref, status)
+}
+
+// ListCommitStatuses returns the CI checks reported on a branch or commit, newest first
+func (m *Manager) ListCommitStatuses(ctx context.Context, ref string) ([]*CommitStatus, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("ref cannot be empty")
+	}
+
+	return m.host.ListCommitStatuses(ctx, ref)
 }
 
 // Health checks the health of the PR manager
 func (m *Manager) Health() error {
-	// Test GitHub API connectivity
+	// Test git host connectivity
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, _, err := m.client.Users.Get(ctx, "")
-	if err != nil {
-		return fmt.Errorf("GitHub API health check failed: %w", err)
-	}
-
-	return nil
+	return m.host.Ping(ctx)
 }
 
 // Shutdown gracefully shuts down the PR manager
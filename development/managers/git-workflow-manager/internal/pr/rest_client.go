@@ -0,0 +1,100 @@
+package pr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// restClient is the JSON client shared by the Gitea and GitLab hosts
+type restClient struct {
+	baseURL     string
+	authHeader  string
+	authValue   string
+	httpClient  *http.Client
+	serviceName string
+}
+
+func newRESTClient(serviceName, baseURL, authHeader, authValue string) (*restClient, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("%s host requires an API URL", serviceName)
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid %s API URL %s: %w", serviceName, baseURL, err)
+	}
+
+	return &restClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		authHeader:  authHeader,
+		authValue:   authValue,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		serviceName: serviceName,
+	}, nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out when it is not nil
+func (c *restClient) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authValue != "" {
+		req.Header.Set(c.authHeader, c.authValue)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s API request failed: %w", c.serviceName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp, fmt.Errorf("%s API %s %s: %s: %s", c.serviceName, method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+			return resp, fmt.Errorf("failed to decode %s API response: %w", c.serviceName, err)
+		}
+	}
+	return resp, nil
+}
+
+// pages calls fetch for pages 1, 2, ... until it returns fewer items than perPage
+func pages(perPage int, fetch func(page int) (int, error)) error {
+	for page := 1; ; page++ {
+		count, err := fetch(page)
+		if err != nil {
+			return err
+		}
+		if count < perPage {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gitworkflowmanager "github.com/gerivdb/email-sender-1/git-workflow-manager"
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/pr"
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/workflows"
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+// runGit runs git in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile writes a file and commits it on the checked out branch
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "-m", message)
+}
+
+// setupLocalRemote creates a bare "origin" repository and a clone with an
+// initial commit on main, and returns their paths
+func setupLocalRemote(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	work := filepath.Join(root, "work")
+	runGit(t, root, "init", "--bare", "--initial-branch=main", origin)
+	runGit(t, root, "clone", origin, work)
+	runGit(t, work, "checkout", "-B", "main")
+	commitFile(t, work, "README.md", "# test\n", "chore: initial commit")
+	runGit(t, work, "push", "origin", "main")
+	return origin, work
+}
+
+func TestLocalHostPullRequestLifecycle(t *testing.T) {
+	origin, work := setupLocalRemote(t)
+	ctx := context.Background()
+
+	host, err := pr.NewGitHost(pr.HostConfig{Type: "local", Path: origin, PushFrom: work, Author: "ci-bot"})
+	if err != nil {
+		t.Fatalf("Failed to create local host: %v", err)
+	}
+	manager, err := pr.NewManagerWithHost(host, &MockErrorManager{})
+	if err != nil {
+		t.Fatalf("Failed to create PR manager: %v", err)
+	}
+
+	runGit(t, work, "checkout", "-b", "feature/login")
+	commitFile(t, work, "login.go", "package login\n", "feat: add login")
+
+	// The feature branch is pushed to the host when the pull request is opened
+	info, err := manager.CreatePullRequest(ctx, "Add login", "Login page", "feature/login", "main")
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+	if info.ID != 1 || info.Status != "open" || info.SourceBranch != "feature/login" {
+		t.Errorf("Unexpected pull request: %+v", info)
+	}
+	if _, err := manager.CreatePullRequest(ctx, "Again", "", "feature/login", "main"); err == nil {
+		t.Error("Expected a second open pull request for the same branches to be refused")
+	}
+
+	files, err := manager.GetPullRequestFiles(ctx, info.ID)
+	if err != nil || len(files) != 1 || files[0] != "login.go" {
+		t.Errorf("Unexpected pull request files: %v (%v)", files, err)
+	}
+
+	if _, err := manager.SubmitReview(ctx, info.ID, pr.Review{Author: "alice", State: pr.ReviewApproved, Body: "LGTM"}); err != nil {
+		t.Fatalf("Failed to submit review: %v", err)
+	}
+	if err := manager.AddComment(ctx, info.ID, "Merging once CI passes"); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	if _, err := manager.SetCommitStatus(ctx, "feature/login", pr.CommitStatus{Context: "ci/test", State: pr.StatusPending}); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	if _, err := manager.SetCommitStatus(ctx, "feature/login", pr.CommitStatus{Context: "ci/test", State: pr.StatusSuccess}); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	if _, err := manager.SetCommitStatus(ctx, "feature/login", pr.CommitStatus{Context: "ci/test", State: "green"}); err == nil {
+		t.Error("Expected an invalid status state to be refused")
+	}
+
+	// A second host over the same store sees everything recorded so far
+	reopened, err := pr.NewLocalHost(pr.LocalHostConfig{RepositoryPath: origin})
+	if err != nil {
+		t.Fatalf("Failed to reopen local host: %v", err)
+	}
+	reviews, err := reopened.ListReviews(ctx, info.ID)
+	if err != nil || len(reviews) != 1 || reviews[0].Author != "alice" || reviews[0].State != pr.ReviewApproved {
+		t.Errorf("Unexpected reviews: %+v (%v)", reviews, err)
+	}
+	comments, err := reopened.ListComments(ctx, info.ID)
+	if err != nil || len(comments) != 1 || comments[0].Author != "ci-bot" {
+		t.Errorf("Unexpected comments: %+v (%v)", comments, err)
+	}
+	statuses, err := reopened.ListCommitStatuses(ctx, "feature/login")
+	if err != nil || len(statuses) != 2 || statuses[0].State != pr.StatusSuccess {
+		t.Errorf("Unexpected statuses (newest first): %+v (%v)", statuses, err)
+	}
+
+	if err := manager.MergePullRequest(ctx, info.ID, "", "merge"); err != nil {
+		t.Fatalf("Failed to merge pull request: %v", err)
+	}
+	merged, err := manager.GetPullRequestStatus(ctx, info.ID)
+	if err != nil || merged.Status != "merged" {
+		t.Errorf("Expected merged pull request, got %+v (%v)", merged, err)
+	}
+	if parents := runGit(t, origin, "rev-list", "--parents", "-n", "1", "main"); len(strings.Fields(parents)) != 3 {
+		t.Errorf("Expected a merge commit on main, got %s", parents)
+	}
+	if err := manager.MergePullRequest(ctx, info.ID, "", "merge"); err == nil {
+		t.Error("Expected merging a merged pull request to fail")
+	}
+
+	open, err := manager.ListPullRequests(ctx, "open")
+	if err != nil || len(open) != 0 {
+		t.Errorf("Expected no open pull request, got %v (%v)", open, err)
+	}
+	closed, err := manager.ListPullRequests(ctx, "closed")
+	if err != nil || len(closed) != 1 {
+		t.Errorf("Expected the merged pull request to be listed as closed, got %v (%v)", closed, err)
+	}
+}
+
+func TestLocalHostMergeMethodsAndConflicts(t *testing.T) {
+	origin, work := setupLocalRemote(t)
+	ctx := context.Background()
+
+	host, err := pr.NewLocalHost(pr.LocalHostConfig{RepositoryPath: origin, PushFrom: work})
+	if err != nil {
+		t.Fatalf("Failed to create local host: %v", err)
+	}
+
+	// Squash: a single commit with one parent
+	runGit(t, work, "checkout", "-b", "feature/squash", "main")
+	commitFile(t, work, "a.txt", "a\n", "feat: a")
+	commitFile(t, work, "b.txt", "b\n", "feat: b")
+	squash, err := host.CreatePullRequest(ctx, "Squash", "", "feature/squash", "main")
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+	if err := host.MergePullRequest(ctx, squash.ID, "feat: a and b", "squash"); err != nil {
+		t.Fatalf("Failed to squash pull request: %v", err)
+	}
+	if parents := runGit(t, origin, "log", "-1", "--format=%P", "main"); len(strings.Fields(parents)) != 1 {
+		t.Errorf("Expected a single-parent squash commit, got %q", parents)
+	}
+
+	// Rebase: only when the target is an ancestor of the source
+	runGit(t, work, "fetch", "origin")
+	runGit(t, work, "checkout", "-b", "feature/ff", "origin/main")
+	commitFile(t, work, "c.txt", "c\n", "feat: c")
+	ff, err := host.CreatePullRequest(ctx, "Fast-forward", "", "feature/ff", "main")
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+	if err := host.MergePullRequest(ctx, ff.ID, "", "rebase"); err != nil {
+		t.Fatalf("Failed to fast-forward pull request: %v", err)
+	}
+	if runGit(t, origin, "rev-parse", "main") != runGit(t, work, "rev-parse", "feature/ff") {
+		t.Error("Expected main to be fast-forwarded to feature/ff")
+	}
+
+	// Conflicting changes are refused and leave main untouched
+	runGit(t, work, "checkout", "-b", "feature/left", "feature/ff")
+	commitFile(t, work, "README.md", "# left\n", "docs: left")
+	runGit(t, work, "checkout", "-b", "feature/right", "feature/ff")
+	commitFile(t, work, "README.md", "# right\n", "docs: right")
+	left, _ := host.CreatePullRequest(ctx, "Left", "", "feature/left", "main")
+	right, _ := host.CreatePullRequest(ctx, "Right", "", "feature/right", "main")
+	if err := host.MergePullRequest(ctx, left.ID, "", "merge"); err != nil {
+		t.Fatalf("Failed to merge pull request: %v", err)
+	}
+	before := runGit(t, origin, "rev-parse", "main")
+	err = host.MergePullRequest(ctx, right.ID, "", "merge")
+	if err == nil || !strings.Contains(err.Error(), "merge conflict in README.md") {
+		t.Errorf("Expected a merge conflict on README.md, got %v", err)
+	}
+	if after := runGit(t, origin, "rev-parse", "main"); after != before {
+		t.Error("A conflicting merge must not move main")
+	}
+
+	if err := host.ClosePullRequest(ctx, right.ID); err != nil {
+		t.Fatalf("Failed to close pull request: %v", err)
+	}
+	if info, _ := host.GetPullRequest(ctx, right.ID); info.Status != "closed" {
+		t.Errorf("Expected closed pull request, got %s", info.Status)
+	}
+}
+
+func TestLocalHostRefusesCheckedOutTarget(t *testing.T) {
+	_, work := setupLocalRemote(t)
+	ctx := context.Background()
+
+	host, err := pr.NewLocalHost(pr.LocalHostConfig{RepositoryPath: work})
+	if err != nil {
+		t.Fatalf("Failed to create local host: %v", err)
+	}
+	runGit(t, work, "branch", "feature/x")
+	info, err := host.CreatePullRequest(ctx, "X", "", "feature/x", "main")
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+	if err := host.MergePullRequest(ctx, info.ID, "", "merge"); err == nil || !strings.Contains(err.Error(), "checked out") {
+		t.Errorf("Expected merging into the checked out branch to be refused, got %v", err)
+	}
+}
+
+// TestWorkflowsWithLocalHost runs the GitFlow and GitHub Flow workflows
+// end to end against a local bare repository, without network access
+func TestWorkflowsWithLocalHost(t *testing.T) {
+	origin, work := setupLocalRemote(t)
+	ctx := context.Background()
+
+	config := map[string]interface{}{
+		"repo_path":     work,
+		"workflow_type": string(interfaces.WorkflowTypeGitFlow),
+		"git_host": map[string]interface{}{
+			"type": "local",
+			"path": origin,
+		},
+	}
+	manager, err := gitworkflowmanager.NewGitWorkflowManager(&MockErrorManager{}, &MockConfigManager{}, &MockStorageManager{}, config)
+	if err != nil {
+		t.Fatalf("Failed to create GitWorkflowManager: %v", err)
+	}
+	host, err := pr.NewLocalHost(pr.LocalHostConfig{RepositoryPath: origin})
+	if err != nil {
+		t.Fatalf("Failed to open local host: %v", err)
+	}
+
+	// GitFlow: feature from develop, finished with a pull request into develop
+	if err := manager.CreateBranch(ctx, "develop", "main"); err != nil {
+		t.Fatalf("Failed to create develop: %v", err)
+	}
+	gitflow := workflows.NewGitFlowWorkflow(manager)
+	feature, err := gitflow.CreateFeatureBranch(ctx, "Login")
+	if err != nil {
+		t.Fatalf("Failed to create feature branch: %v", err)
+	}
+	runGit(t, work, "checkout", feature.Name)
+	commitFile(t, work, "login.go", "package login\n", "feat: add login")
+
+	if err := gitflow.FinishFeature(ctx, "Login"); err != nil {
+		t.Fatalf("Failed to finish feature: %v", err)
+	}
+	open, err := manager.ListPullRequests(ctx, "open")
+	if err != nil || len(open) != 1 || open[0].TargetBranch != "develop" {
+		t.Fatalf("Expected one pull request into develop, got %+v (%v)", open, err)
+	}
+	if err := host.MergePullRequest(ctx, open[0].ID, "", "merge"); err != nil {
+		t.Fatalf("Failed to merge feature: %v", err)
+	}
+	runGit(t, origin, "cat-file", "-e", "develop:login.go")
+
+	// GitHub Flow: branch from main, pull request into main
+	githubFlow := workflows.NewGitHubFlowWorkflow(manager)
+	branch, err := githubFlow.CreateFeatureBranch(ctx, "docs")
+	if err != nil {
+		t.Fatalf("Failed to create GitHub Flow branch: %v", err)
+	}
+	runGit(t, work, "checkout", branch.Name)
+	commitFile(t, work, "CONTRIBUTING.md", "# Contributing\n", "docs: add contributing guide")
+
+	prInfo, err := githubFlow.CreatePullRequest(ctx, branch.Name, "Contributing guide", "")
+	if err != nil {
+		t.Fatalf("Failed to create GitHub Flow pull request: %v", err)
+	}
+	if _, err := host.SetCommitStatus(ctx, branch.Name, pr.CommitStatus{Context: "ci", State: pr.StatusSuccess}); err != nil {
+		t.Fatalf("Failed to report CI status: %v", err)
+	}
+	if err := host.MergePullRequest(ctx, prInfo.ID, "", "squash"); err != nil {
+		t.Fatalf("Failed to merge GitHub Flow pull request: %v", err)
+	}
+	status, err := manager.GetPullRequestStatus(ctx, prInfo.ID)
+	if err != nil || status.Status != "merged" {
+		t.Errorf("Expected merged pull request, got %+v (%v)", status, err)
+	}
+	runGit(t, origin, "cat-file", "-e", "main:CONTRIBUTING.md")
+}
+
+func TestGiteaHost(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/repos/acme/app/pulls":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"number": 7, "title": body["title"], "body": body["body"], "state": "open",
+				"head": map[string]string{"ref": body["head"]}, "base": map[string]string{"ref": body["base"]},
+			})
+		case "GET /api/v1/repos/acme/app/pulls":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"number": 7, "state": "closed", "merged": true},
+				{"number": 8, "state": "closed", "merged": false},
+			})
+		case "POST /api/v1/repos/acme/app/pulls/7/merge":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["Do"] != "squash" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+			}
+		case "GET /api/v1/repos/acme/app/git/commits/feature/x":
+			json.NewEncoder(w).Encode(map[string]string{"sha": "abc123"})
+		case "POST /api/v1/repos/acme/app/statuses/abc123":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"context": "ci", "status": "success"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	host, err := pr.NewGitHost(pr.HostConfig{Type: "gitea", URL: server.URL + "/api/v1", Token: "secret", Owner: "acme", Repo: "app"})
+	if err != nil {
+		t.Fatalf("Failed to create Gitea host: %v", err)
+	}
+
+	info, err := host.CreatePullRequest(ctx, "Feature", "Body", "feature/x", "main")
+	if err != nil || info.ID != 7 || info.SourceBranch != "feature/x" || info.Status != "open" {
+		t.Fatalf("Unexpected pull request %+v (%v)", info, err)
+	}
+	merged, err := host.ListPullRequests(ctx, "merged")
+	if err != nil || len(merged) != 1 || merged[0].ID != 7 || merged[0].Status != "merged" {
+		t.Errorf("Unexpected merged pull requests %+v (%v)", merged, err)
+	}
+	if err := host.MergePullRequest(ctx, 7, "", "squash"); err != nil {
+		t.Errorf("Failed to merge: %v", err)
+	}
+	status, err := host.SetCommitStatus(ctx, "feature/x", pr.CommitStatus{Context: "ci", State: pr.StatusSuccess})
+	if err != nil || status.State != pr.StatusSuccess {
+		t.Errorf("Unexpected status %+v (%v)", status, err)
+	}
+	if _, err := host.GetPullRequest(ctx, 99); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
+
+func TestGitLabHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// The project path is URL-encoded
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "POST /api/v4/projects/acme%2Fapp/merge_requests":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"iid": 3, "title": body["title"], "state": "opened",
+				"source_branch": body["source_branch"], "target_branch": body["target_branch"],
+			})
+		case "GET /api/v4/projects/acme%2Fapp/merge_requests":
+			if r.URL.Query().Get("state") != "opened" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"iid": 3, "state": "opened"}})
+		case "POST /api/v4/projects/acme%2Fapp/merge_requests/3/approve":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		case "GET /api/v4/projects/acme%2Fapp/merge_requests/3/approvals":
+			w.Write([]byte(`{"approved_by": [{"user": {"username": "alice"}}]}`))
+		case "GET /api/v4/projects/acme%2Fapp/repository/commits/main":
+			w.Write([]byte(`{"id": "def456"}`))
+		case "GET /api/v4/projects/acme%2Fapp/repository/commits/def456/statuses":
+			w.Write([]byte(`[{"name": "build", "status": "running"}, {"name": "build", "status": "failed"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	host, err := pr.NewGitHost(pr.HostConfig{Type: "gitlab", URL: server.URL + "/api/v4", Token: "secret", Owner: "acme", Repo: "app"})
+	if err != nil {
+		t.Fatalf("Failed to create GitLab host: %v", err)
+	}
+
+	info, err := host.CreatePullRequest(ctx, "Feature", "", "feature/x", "main")
+	if err != nil || info.ID != 3 || info.Status != "open" || info.TargetBranch != "main" {
+		t.Fatalf("Unexpected merge request %+v (%v)", info, err)
+	}
+	open, err := host.ListPullRequests(ctx, "open")
+	if err != nil || len(open) != 1 {
+		t.Errorf("Unexpected open merge requests %+v (%v)", open, err)
+	}
+	if _, err := host.SubmitReview(ctx, 3, pr.Review{State: pr.ReviewApproved}); err != nil {
+		t.Errorf("Failed to approve: %v", err)
+	}
+	reviews, err := host.ListReviews(ctx, 3)
+	if err != nil || len(reviews) != 1 || reviews[0].Author != "alice" {
+		t.Errorf("Unexpected reviews %+v (%v)", reviews, err)
+	}
+	statuses, err := host.ListCommitStatuses(ctx, "main")
+	if err != nil || len(statuses) != 2 || statuses[0].State != pr.StatusFailure || statuses[1].State != pr.StatusPending {
+		t.Errorf("Unexpected statuses (newest first) %+v (%v)", statuses, err)
+	}
+}
+
+func TestNewGitHostValidation(t *testing.T) {
+	if _, err := pr.NewGitHost(pr.HostConfig{Type: "bitbucket"}); err == nil {
+		t.Error("Expected an unsupported host type to be refused")
+	}
+	if _, err := pr.NewGitHost(pr.HostConfig{Type: "gitea"}); err == nil {
+		t.Error("Expected a Gitea host without URL to be refused")
+	}
+	if _, err := pr.NewGitHost(pr.HostConfig{Type: "local", Path: t.TempDir()}); err == nil {
+		t.Error("Expected a local host outside a repository to be refused")
+	}
+}
@@ -0,0 +1,345 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/mergequeue"
+	"github.com/gerivdb/email-sender-1/git-workflow-manager/internal/pr"
+	"github.com/gerivdb/email-sender-1/managers/interfaces"
+)
+
+// recordingSink collects the merge queue events
+type recordingSink struct {
+	mu       sync.Mutex
+	payloads []*interfaces.WebhookPayload
+}
+
+func (s *recordingSink) SendWebhook(ctx context.Context, event string, payload *interfaces.WebhookPayload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payloads = append(s.payloads, payload)
+	return nil
+}
+
+func (s *recordingSink) actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var actions []string
+	for _, payload := range s.payloads {
+		actions = append(actions, payload.Data["action"].(string))
+	}
+	return actions
+}
+
+// setupQueueRepo creates a repository with main checked out and one branch
+// per file content, each forked from the initial commit
+func setupQueueRepo(t *testing.T, branches map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "init", "--initial-branch=main")
+	commitFile(t, repo, "README.md", "# test\n", "chore: initial commit")
+	for branch, content := range branches {
+		runGit(t, repo, "checkout", "-q", "-b", branch, "main")
+		commitFile(t, repo, strings.ReplaceAll(branch, "/", "-")+".txt", content, "feat: "+branch)
+	}
+	runGit(t, repo, "checkout", "-q", "main")
+	return repo
+}
+
+// noBrokenFiles fails when a text file contains BROKEN
+var noBrokenFiles = mergequeue.Check{Name: "no-broken", Command: "! grep -q BROKEN *.txt"}
+
+func TestMergeQueueLandsGreenBranch(t *testing.T) {
+	repo := setupQueueRepo(t, map[string]string{"feature/a": "a\n"})
+	ctx := context.Background()
+	sink := &recordingSink{}
+
+	queue, err := mergequeue.New(mergequeue.Config{RepoPath: repo, Checks: []mergequeue.Check{noBrokenFiles}}, sink, nil)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+	defer queue.Shutdown(ctx)
+
+	entry, err := queue.Enqueue(ctx, "feature/a", "main", mergequeue.EnqueueOptions{PullRequest: 7, DeleteAfterMerge: true})
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	entry, err = queue.Wait(waitCtx, entry.ID)
+	if err != nil {
+		t.Fatalf("Failed to wait for entry: %v", err)
+	}
+	if entry.State != mergequeue.StateMerged || len(entry.CheckResults) != 1 || !entry.CheckResults[0].Passed {
+		t.Fatalf("Unexpected entry: %+v", entry)
+	}
+
+	// main was fast-forwarded: linear history and an up to date working tree
+	if head := runGit(t, repo, "rev-parse", "main"); head != entry.MergedCommit {
+		t.Errorf("main is at %s, expected %s", head, entry.MergedCommit)
+	}
+	if merges := runGit(t, repo, "rev-list", "--merges", "main"); merges != "" {
+		t.Errorf("Expected a linear history, found merges %s", merges)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "feature-a.txt")); err != nil {
+		t.Errorf("Expected the checked out main to be updated: %v", err)
+	}
+	if branches := runGit(t, repo, "branch", "--list", "feature/a"); branches != "" {
+		t.Errorf("Expected feature/a to be deleted, got %q", branches)
+	}
+	if worktrees := runGit(t, repo, "worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Errorf("Expected the temporary worktree to be removed:\n%s", worktrees)
+	}
+
+	actions := strings.Join(sink.actions(), ",")
+	if actions != "enqueued,testing,merged" {
+		t.Errorf("Unexpected events: %s", actions)
+	}
+	if sink.payloads[0].Event != mergequeue.EventMergeQueue {
+		t.Errorf("Unexpected event name: %s", sink.payloads[0].Event)
+	}
+}
+
+func TestMergeQueueBisectsFailingBatch(t *testing.T) {
+	repo := setupQueueRepo(t, map[string]string{
+		"feature/a": "a\n",
+		"feature/b": "BROKEN\n",
+		"feature/c": "c\n",
+		"feature/d": "d\n",
+	})
+	ctx := context.Background()
+	sink := &recordingSink{}
+
+	queue, err := mergequeue.New(mergequeue.Config{
+		RepoPath:         repo,
+		Checks:           []mergequeue.Check{noBrokenFiles},
+		BatchSize:        4,
+		ManualProcessing: true,
+	}, sink, nil)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+
+	ids := make(map[string]string)
+	for _, branch := range []string{"feature/a", "feature/b", "feature/c", "feature/d"} {
+		entry, err := queue.Enqueue(ctx, branch, "main", mergequeue.EnqueueOptions{})
+		if err != nil {
+			t.Fatalf("Failed to enqueue %s: %v", branch, err)
+		}
+		ids[branch] = entry.ID
+	}
+	if _, err := queue.Enqueue(ctx, "feature/a", "main", mergequeue.EnqueueOptions{}); err == nil {
+		t.Error("Expected a branch to be queued only once")
+	}
+	if pending := queue.Pending("main"); len(pending) != 4 {
+		t.Fatalf("Expected 4 pending entries, got %d", len(pending))
+	}
+
+	if err := queue.Process(ctx, "main"); err != nil {
+		t.Fatalf("Failed to process queue: %v", err)
+	}
+
+	for branch, id := range ids {
+		entry, _ := queue.Get(id)
+		expected := mergequeue.StateMerged
+		if branch == "feature/b" {
+			expected = mergequeue.StateFailed
+		}
+		if entry.State != expected {
+			t.Errorf("%s: expected %s, got %s (%s)", branch, expected, entry.State, entry.Error)
+		}
+	}
+	failed, _ := queue.Get(ids["feature/b"])
+	if !strings.Contains(failed.Error, "no-broken") {
+		t.Errorf("Expected the failing check to be reported, got %q", failed.Error)
+	}
+
+	files := runGit(t, repo, "ls-tree", "--name-only", "main")
+	for _, name := range []string{"feature-a.txt", "feature-c.txt", "feature-d.txt"} {
+		if !strings.Contains(files, name) {
+			t.Errorf("Expected %s on main, got:\n%s", name, files)
+		}
+	}
+	if strings.Contains(files, "feature-b.txt") {
+		t.Error("The failing branch must not land")
+	}
+	// Merged branches point at their rebased commits, now part of main
+	runGit(t, repo, "merge-base", "--is-ancestor", "feature/c", "main")
+
+	if !strings.Contains(strings.Join(sink.actions(), ","), "bisecting") {
+		t.Errorf("Expected a bisecting event, got %v", sink.actions())
+	}
+}
+
+func TestMergeQueueKeepsConcurrentPushes(t *testing.T) {
+	repo := setupQueueRepo(t, map[string]string{"feature/a": "a\n", "feature/b": "b\n"})
+	ctx := context.Background()
+	sink := &recordingSink{}
+	dir := t.TempDir()
+
+	// The checks run in a worktree sharing the repository refs: the first
+	// run pushes to main, the second one to feature/b
+	push := func(ref string) string {
+		return "git update-ref refs/heads/" + ref + " $(git -c user.name=test -c user.email=test@example.com commit-tree refs/heads/" + ref + "^{tree} -p refs/heads/" + ref + " -m concurrent)"
+	}
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	concurrentPush := mergequeue.Check{
+		Name: "concurrent-push",
+		Command: "if [ ! -e " + first + " ]; then touch " + first + " && " + push("main") +
+			"; elif [ ! -e " + second + " ]; then touch " + second + " && " + push("feature/b") + "; fi",
+	}
+
+	queue, err := mergequeue.New(mergequeue.Config{
+		RepoPath:         repo,
+		Checks:           []mergequeue.Check{concurrentPush},
+		ManualProcessing: true,
+	}, sink, nil)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+	a, _ := queue.Enqueue(ctx, "feature/a", "main", mergequeue.EnqueueOptions{DeleteAfterMerge: true})
+	b, _ := queue.Enqueue(ctx, "feature/b", "main", mergequeue.EnqueueOptions{})
+	if err := queue.Process(ctx, "main"); err != nil {
+		t.Fatalf("Failed to process queue: %v", err)
+	}
+
+	// The moved target sends the batch back to the queue instead of failing it
+	for _, id := range []string{a.ID, b.ID} {
+		if entry, _ := queue.Get(id); entry.State != mergequeue.StateMerged {
+			t.Errorf("Expected %s to land after being requeued, got %s (%s)", entry.Branch, entry.State, entry.Error)
+		}
+	}
+	if !strings.Contains(strings.Join(sink.actions(), ","), "requeued") {
+		t.Errorf("Expected a requeued event, got %v", sink.actions())
+	}
+	if message := runGit(t, repo, "log", "-1", "--format=%s", "main~2"); message != "concurrent" {
+		t.Errorf("Expected the concurrent commit of main to be kept, got %q", message)
+	}
+
+	// feature/b moved while its batch was tested: it is left as pushed
+	if message := runGit(t, repo, "log", "-1", "--format=%s", "feature/b"); message != "concurrent" {
+		t.Errorf("Expected the commit pushed to feature/b to be kept, got %q", message)
+	}
+	if branches := runGit(t, repo, "branch", "--list", "feature/a"); branches != "" {
+		t.Errorf("Expected feature/a to be deleted, got %q", branches)
+	}
+}
+
+func TestMergeQueueRequeuesOnCancel(t *testing.T) {
+	repo := setupQueueRepo(t, map[string]string{"feature/a": "a\n"})
+
+	queue, err := mergequeue.New(mergequeue.Config{
+		RepoPath:         repo,
+		Checks:           []mergequeue.Check{{Name: "slow", Command: "exec sleep 10"}},
+		ManualProcessing: true,
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+	entry, _ := queue.Enqueue(context.Background(), "feature/a", "main", mergequeue.EnqueueOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := queue.Process(ctx, "main"); err == nil {
+		t.Fatal("Expected the cancelled processing to return an error")
+	}
+
+	// The interrupted entry is queued again, not rejected
+	if entry, _ := queue.Get(entry.ID); entry.State != mergequeue.StateQueued {
+		t.Errorf("Expected the entry to stay queued, got %s (%s)", entry.State, entry.Error)
+	}
+	if pending := queue.Pending("main"); len(pending) != 1 {
+		t.Errorf("Expected 1 pending entry, got %d", len(pending))
+	}
+}
+
+func TestMergeQueueRejectsConflicts(t *testing.T) {
+	repo := setupQueueRepo(t, nil)
+	ctx := context.Background()
+
+	for _, branch := range []string{"feature/one", "feature/two"} {
+		runGit(t, repo, "checkout", "-q", "-b", branch, "main")
+		commitFile(t, repo, "README.md", "# "+branch+"\n", "docs: "+branch)
+	}
+	runGit(t, repo, "checkout", "-q", "main")
+
+	queue, err := mergequeue.New(mergequeue.Config{RepoPath: repo, ManualProcessing: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+	first, _ := queue.Enqueue(ctx, "feature/one", "main", mergequeue.EnqueueOptions{})
+	second, _ := queue.Enqueue(ctx, "feature/two", "main", mergequeue.EnqueueOptions{})
+	if err := queue.Process(ctx, "main"); err != nil {
+		t.Fatalf("Failed to process queue: %v", err)
+	}
+
+	if entry, _ := queue.Get(first.ID); entry.State != mergequeue.StateMerged {
+		t.Errorf("Expected the first branch to land, got %s (%s)", entry.State, entry.Error)
+	}
+	entry, _ := queue.Get(second.ID)
+	if entry.State != mergequeue.StateFailed || !strings.Contains(entry.Error, "conflicts with the queue head") {
+		t.Errorf("Expected a conflict, got %s (%s)", entry.State, entry.Error)
+	}
+	if status := runGit(t, repo, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected a clean working tree, got:\n%s", status)
+	}
+}
+
+func TestMergeQueueRequiredStatuses(t *testing.T) {
+	origin, work := setupLocalRemote(t)
+	ctx := context.Background()
+
+	host, err := pr.NewLocalHost(pr.LocalHostConfig{RepositoryPath: origin, PushFrom: work})
+	if err != nil {
+		t.Fatalf("Failed to create local host: %v", err)
+	}
+	for _, branch := range []string{"feature/green", "feature/pending"} {
+		runGit(t, work, "checkout", "-q", "-b", branch, "main")
+		commitFile(t, work, strings.ReplaceAll(branch, "/", "-")+".txt", branch+"\n", "feat: "+branch)
+		runGit(t, work, "push", "-q", "origin", branch)
+	}
+	runGit(t, work, "checkout", "-q", "main")
+	host.SetCommitStatus(ctx, "feature/green", pr.CommitStatus{Context: "ci/build", State: pr.StatusSuccess})
+	host.SetCommitStatus(ctx, "feature/pending", pr.CommitStatus{Context: "ci/build", State: pr.StatusPending})
+
+	if _, err := mergequeue.New(mergequeue.Config{RepoPath: origin, RequiredStatuses: []string{"ci/build"}}, nil, nil); err == nil {
+		t.Error("Expected required statuses without a host to be refused")
+	}
+
+	// The queue runs in the host repository, where the statuses are recorded
+	queue, err := mergequeue.New(mergequeue.Config{
+		RepoPath:         origin,
+		RequiredStatuses: []string{"ci/build"},
+		ManualProcessing: true,
+	}, nil, host)
+	if err != nil {
+		t.Fatalf("Failed to create merge queue: %v", err)
+	}
+	green, _ := queue.Enqueue(ctx, "feature/green", "main", mergequeue.EnqueueOptions{})
+	pending, _ := queue.Enqueue(ctx, "feature/pending", "main", mergequeue.EnqueueOptions{})
+	if err := queue.Process(ctx, "main"); err != nil {
+		t.Fatalf("Failed to process queue: %v", err)
+	}
+
+	if entry, _ := queue.Get(green.ID); entry.State != mergequeue.StateMerged {
+		t.Errorf("Expected feature/green to land, got %s (%s)", entry.State, entry.Error)
+	}
+	entry, _ := queue.Get(pending.ID)
+	if entry.State != mergequeue.StateFailed || !strings.Contains(entry.Error, "ci/build (pending)") {
+		t.Errorf("Expected feature/pending to be rejected, got %s (%s)", entry.State, entry.Error)
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"email_sender/development/managers/roadmap-manager/roadmap-cli/ingestion"
 	parallelprocessor "email_sender/development/managers/roadmap-manager/roadmap-cli/parallel"
-	rag "email_sender/development/managers/roadmap-manager/roadmap-cli/rag"
 	"email_sender/development/managers/roadmap-manager/roadmap-cli/storage"
 
 	"github.com/spf13/cobra"
@@ -134,21 +133,20 @@ func runIngest(cmd *cobra.Command, args []string) error {
 	// Create RAG client for indexing (unless dry run)
 	var ragClient ingestion.RAGClient
 	if !dryRun {
-		ragClient = rag.NewRAGClient(
-			"http://localhost:6333", // QDrant URL
-			"http://localhost:8080", // OpenAI URL (placeholder)
-			"test-api-key",          // API key (placeholder)
-		)
+		client := newRAGClient()
 
 		// Test RAG connectivity
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := ragClient.HealthCheck(ctx); err != nil {
+		if err := client.HealthCheck(ctx); err != nil {
 			fmt.Printf("⚠️  RAG system not available: %v\n", err)
 			fmt.Println("   Proceeding with analysis only (no indexing)")
-			ragClient = nil
+		} else if err := client.InitializeCollection(ctx); err != nil {
+			fmt.Printf("⚠️  RAG collection not available: %v\n", err)
+			fmt.Println("   Proceeding with analysis only (no indexing)")
 		} else {
+			ragClient = client
 			fmt.Println("✅ RAG system connected")
 		}
 		fmt.Println()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -271,6 +272,15 @@ var syncRAGCmd = &cobra.Command{
 			return fmt.Errorf("failed to load roadmap: %w", err)
 		}
 
+		// Use the statistics stored with the collection, or fit them on the items
+		corpus := make([]string, 0, len(roadmapData.Items))
+		for _, item := range roadmapData.Items {
+			corpus = append(corpus, rag.ItemText(item.Title, item.Description))
+		}
+		if err := ragClient.SyncCorpus(ctx, corpus); err != nil {
+			return fmt.Errorf("failed to prepare corpus statistics: %w", err)
+		}
+
 		// Index all roadmap items
 		indexedCount := 0
 		for _, item := range roadmapData.Items {
@@ -297,8 +307,21 @@ var syncRAGCmd = &cobra.Command{
 	},
 }
 
-// createRAGClient creates a new RAG client with EMAIL_SENDER_1 configuration
+// createRAGClient creates a new RAG client with EMAIL_SENDER_1 configuration.
+// The provider statistics stored with the collection by "sync" or "ingest" are
+// loaded so that the query vectors match the indexed ones.
 func createRAGClient() *rag.RAGClient {
+	client := newRAGClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := client.LoadCorpus(ctx); err != nil {
+		fmt.Printf("⚠️  Failed to load the corpus statistics: %v\n", err)
+	}
+	return client
+}
+
+// newRAGClient selects the embedding provider from the environment
+func newRAGClient() *rag.RAGClient {
 	// Default to local QDrant instance (EMAIL_SENDER_1 setup)
 	qdrantURL := os.Getenv("QDRANT_URL")
 	if qdrantURL == "" {
@@ -312,7 +335,26 @@ func createRAGClient() *rag.RAGClient {
 
 	apiKey := os.Getenv("OPENAI_API_KEY")
 
-	return rag.NewRAGClient(qdrantURL, openaiURL, apiKey)
+	// Embeddings are computed offline unless an OpenAI-compatible API is requested
+	if os.Getenv("RAG_EMBEDDING_PROVIDER") != "openai" {
+		return rag.NewRAGClient(qdrantURL, openaiURL, apiKey)
+	}
+
+	model := os.Getenv("RAG_EMBEDDING_MODEL")
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+
+	dimensions, err := strconv.Atoi(os.Getenv("RAG_EMBEDDING_DIMENSIONS"))
+	if err != nil || dimensions <= 0 {
+		dimensions = 1536
+	}
+
+	provider := rag.NewOpenAIEmbeddingProvider(openaiURL, apiKey, model, dimensions)
+	if batchSize, err := strconv.Atoi(os.Getenv("RAG_EMBEDDING_BATCH_SIZE")); err == nil {
+		provider.SetBatchSize(batchSize)
+	}
+	return rag.NewRAGClient(qdrantURL, openaiURL, apiKey, rag.WithEmbeddingProvider(provider))
 }
 
 func init() {
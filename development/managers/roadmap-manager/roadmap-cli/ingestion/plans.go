@@ -339,6 +339,20 @@ func (p *PlanIngester) indexChunksInRAG(ctx context.Context) error {
 		return fmt.Errorf("RAG system not available: %w", err)
 	}
 
+	// Use the provider statistics (IDF) stored with the collection, fitting
+	// them on the chunks when the collection has none yet
+	if syncer, ok := p.ragClient.(interface {
+		SyncCorpus(ctx context.Context, texts []string) error
+	}); ok {
+		corpus := make([]string, 0, len(p.chunks))
+		for _, chunk := range p.chunks {
+			corpus = append(corpus, fmt.Sprintf("[%s] %s %s", chunk.PlanFile, chunk.Title, chunk.Content))
+		}
+		if err := syncer.SyncCorpus(ctx, corpus); err != nil {
+			return fmt.Errorf("failed to prepare corpus statistics: %w", err)
+		}
+	}
+
 	successCount := 0
 	for _, chunk := range p.chunks {
 		// Create a search-optimized title and description
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,6 +22,27 @@ type RAGClient struct {
 	apiKey         string
 	client         *http.Client
 	collectionName string
+	embedder       EmbeddingProvider
+}
+
+// dependencyThreshold is the minimum similarity for an item to be reported as a dependency
+const dependencyThreshold = 0.35
+
+// ClientOption configures a RAGClient
+type ClientOption func(*RAGClient)
+
+// WithEmbeddingProvider replaces the default offline embedding provider
+func WithEmbeddingProvider(provider EmbeddingProvider) ClientOption {
+	return func(r *RAGClient) {
+		r.embedder = provider
+	}
+}
+
+// WithCollectionName overrides the QDrant collection used for roadmap items
+func WithCollectionName(name string) ClientOption {
+	return func(r *RAGClient) {
+		r.collectionName = name
+	}
 }
 
 // RoadmapInsight represents AI-generated insights about roadmap items
@@ -50,9 +72,11 @@ type SearchRequest struct {
 	Filter      map[string]interface{} `json:"filter,omitempty"`
 }
 
-// NewRAGClient creates a new RAG client connected to EMAIL_SENDER_1 ecosystem
-func NewRAGClient(qdrantURL, openaiURL, apiKey string) *RAGClient {
-	return &RAGClient{
+// NewRAGClient creates a new RAG client connected to EMAIL_SENDER_1 ecosystem.
+// Embeddings are computed offline by a HashingEmbeddingProvider unless another
+// provider is given with WithEmbeddingProvider.
+func NewRAGClient(qdrantURL, openaiURL, apiKey string, opts ...ClientOption) *RAGClient {
+	client := &RAGClient{
 		qdrantURL:      qdrantURL,
 		openaiURL:      openaiURL,
 		apiKey:         apiKey,
@@ -60,14 +84,26 @@ func NewRAGClient(qdrantURL, openaiURL, apiKey string) *RAGClient {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		embedder: NewHashingEmbeddingProvider(DefaultEmbeddingDimensions),
 	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// EmbeddingProvider returns the provider used to vectorize roadmap text
+func (r *RAGClient) EmbeddingProvider() EmbeddingProvider {
+	return r.embedder
 }
 
 // InitializeCollection creates the roadmap vector collection in QDrant
 func (r *RAGClient) InitializeCollection(ctx context.Context) error {
 	createPayload := map[string]interface{}{
 		"vectors": map[string]interface{}{
-			"size":     384, // Standard embedding dimension
+			"size":     r.embedder.GetDimensions(),
 			"distance": "Cosine",
 		},
 	}
@@ -98,10 +134,114 @@ func (r *RAGClient) InitializeCollection(ctx context.Context) error {
 	return nil
 }
 
+// ItemText is the text embedded for a roadmap item
+func ItemText(title, description string) string {
+	return fmt.Sprintf("%s %s", title, description)
+}
+
+// corpusPointType is the payload type of the point holding the corpus
+// statistics of a collection; searches only match "roadmap_item" points
+const corpusPointType = "corpus_statistics"
+
+// FitCorpus fits the corpus statistics of the embedding provider (the IDF
+// table of the offline provider) on the texts of the indexed items, in memory
+// only. Use SyncCorpus to share them between indexing and querying. Providers
+// without corpus statistics are left unchanged.
+func (r *RAGClient) FitCorpus(texts []string) {
+	if fitter, ok := r.embedder.(CorpusFitter); ok && len(texts) > 0 {
+		fitter.FitIDF(texts)
+	}
+}
+
+// SyncCorpus prepares the provider for indexing texts into the collection.
+// The statistics stored in the collection are reused when there are some;
+// otherwise they are fitted on texts and stored. Every vector of the
+// collection is thus built with the same statistics, whichever command
+// indexed it, and LoadCorpus gives the queries the same ones.
+func (r *RAGClient) SyncCorpus(ctx context.Context, texts []string) error {
+	fitter, ok := r.embedder.(CorpusFitter)
+	if !ok {
+		return nil
+	}
+	loaded, err := r.LoadCorpus(ctx)
+	if err != nil || loaded {
+		return err
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	fitter.FitIDF(texts)
+	// The point needs a valid vector; its type keeps it out of the searches
+	vector := make([]float32, r.embedder.GetDimensions())
+	vector[0] = 1
+	point := QDrantPoint{
+		ID:     r.corpusPointID(),
+		Vector: vector,
+		Payload: map[string]interface{}{
+			"type": corpusPointType,
+			"idf":  fitter.IDF(),
+		},
+	}
+	if err := r.upsertPoints(ctx, []QDrantPoint{point}); err != nil {
+		return fmt.Errorf("failed to store corpus statistics: %w", err)
+	}
+	return nil
+}
+
+// LoadCorpus loads the corpus statistics stored in the collection by
+// SyncCorpus. It reports false when the collection has none yet.
+func (r *RAGClient) LoadCorpus(ctx context.Context) (bool, error) {
+	fitter, ok := r.embedder.(CorpusFitter)
+	if !ok {
+		return false, nil
+	}
+
+	url := fmt.Sprintf("%s/collections/%s/points/%s", r.qdrantURL, r.collectionName, r.corpusPointID())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to load corpus statistics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("loading corpus statistics failed with status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result struct {
+			Payload struct {
+				IDF *IDFTable `json:"idf"`
+			} `json:"payload"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode corpus statistics: %w", err)
+	}
+	if result.Result.Payload.IDF == nil {
+		return false, nil
+	}
+	fitter.SetIDF(*result.Result.Payload.IDF)
+	return true, nil
+}
+
+// corpusPointID is the ID of the corpus statistics point, stable per collection
+func (r *RAGClient) corpusPointID() string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("roadmap-cli/corpus/"+r.collectionName)).String()
+}
+
 // IndexRoadmapItem stores a roadmap item as a vector in QDrant for future analysis
 func (r *RAGClient) IndexRoadmapItem(ctx context.Context, itemID, title, description string, metadata map[string]interface{}) error {
 	// Generate embedding for the roadmap item
-	vector, err := r.generateEmbedding(ctx, fmt.Sprintf("%s %s", title, description))
+	vector, err := r.generateEmbedding(ctx, ItemText(title, description))
 	if err != nil {
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
@@ -160,6 +300,8 @@ func (r *RAGClient) GetSimilarItems(ctx context.Context, query string, limit int
 	// Convert results to insights
 	insights := make([]RoadmapInsight, 0, len(points))
 	for _, point := range points {
+		r.explainMatch(query, point.Payload)
+
 		insight := RoadmapInsight{
 			ID:          uuid.New().String(),
 			Type:        "recommendation",
@@ -182,7 +324,7 @@ func (r *RAGClient) GetSimilarItems(ctx context.Context, query string, limit int
 // AnalyzeDependencies uses RAG to identify potential dependencies between roadmap items
 func (r *RAGClient) AnalyzeDependencies(ctx context.Context, itemTitle, itemDescription string) ([]RoadmapInsight, error) {
 	// Search for related items that might be dependencies
-	query := fmt.Sprintf("dependency prerequisite requirement %s", itemTitle)
+	query := strings.TrimSpace(fmt.Sprintf("%s %s", itemTitle, itemDescription))
 	similarItems, err := r.GetSimilarItems(ctx, query, 5)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find potential dependencies: %w", err)
@@ -191,7 +333,10 @@ func (r *RAGClient) AnalyzeDependencies(ctx context.Context, itemTitle, itemDesc
 	// Generate dependency insights
 	insights := make([]RoadmapInsight, 0, len(similarItems))
 	for _, item := range similarItems {
-		if item.Confidence > 0.7 { // High confidence threshold for dependencies
+		if title, _ := item.Context["title"].(string); strings.EqualFold(title, itemTitle) {
+			continue // The item itself is not one of its dependencies
+		}
+		if item.Confidence > dependencyThreshold {
 			insight := RoadmapInsight{
 				ID:          uuid.New().String(),
 				Type:        "dependency",
@@ -207,44 +352,102 @@ func (r *RAGClient) AnalyzeDependencies(ctx context.Context, itemTitle, itemDesc
 	return insights, nil
 }
 
-// GenerateRecommendations provides AI-powered recommendations for roadmap optimization
+// GenerateRecommendations provides recommendations for roadmap optimization.
+// Items of the context (one "Item: ..." entry each) are compared pairwise with
+// the embedding provider: near-duplicates are reported as consolidation
+// opportunities and closely related items as candidates for grouping.
 func (r *RAGClient) GenerateRecommendations(ctx context.Context, roadmapContext string) ([]RoadmapInsight, error) {
-	// Use the existing EMAIL_SENDER_1 RAG context to generate recommendations
-	recommendations := []RoadmapInsight{
-		{
-			ID:          uuid.New().String(),
-			Type:        "optimization",
-			Message:     "Consider parallelizing independent tasks to reduce overall timeline",
-			Confidence:  0.85,
-			Context:     map[string]interface{}{"source": "rag_analysis"},
-			GeneratedAt: time.Now(),
-		},
-		{
-			ID:          uuid.New().String(),
-			Type:        "risk",
-			Message:     "High priority items without clear dependencies detected",
-			Confidence:  0.78,
-			Context:     map[string]interface{}{"source": "pattern_analysis"},
-			GeneratedAt: time.Now(),
-		},
+	items := extractContextItems(roadmapContext)
+	if len(items) < 2 {
+		return []RoadmapInsight{}, nil
 	}
 
+	vectors, err := r.embedder.GetEmbeddings(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate context embeddings: %w", err)
+	}
+
+	recommendations := make([]RoadmapInsight, 0)
+	for i := 0; i < len(items); i++ {
+		for j := i + 1; j < len(items); j++ {
+			similarity := cosineSimilarity(vectors[i], vectors[j])
+
+			var insightType, message string
+			switch {
+			case similarity >= 0.8:
+				insightType = "risk"
+				message = fmt.Sprintf("Possible duplicate work: %q and %q", items[i], items[j])
+			case similarity >= 0.5:
+				insightType = "optimization"
+				message = fmt.Sprintf("Consider grouping related items %q and %q", items[i], items[j])
+			default:
+				continue
+			}
+
+			context := map[string]interface{}{
+				"source": "embedding_similarity",
+				"items":  []string{items[i], items[j]},
+			}
+			if explainer, ok := r.embedder.(SimilarityExplainer); ok {
+				context["top_terms"] = explainer.ExplainSimilarity(items[i], items[j], 5)
+			}
+
+			recommendations = append(recommendations, RoadmapInsight{
+				ID:          uuid.New().String(),
+				Type:        insightType,
+				Message:     message,
+				Confidence:  similarity,
+				Context:     context,
+				GeneratedAt: time.Now(),
+			})
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Confidence > recommendations[j].Confidence
+	})
+
 	return recommendations, nil
 }
 
-// generateEmbedding creates a vector embedding for text using OpenAI or mock generation
-func (r *RAGClient) generateEmbedding(_ context.Context, text string) ([]float32, error) {
-	// For now, generate a mock embedding based on text content
-	// In production, this would call OpenAI's embedding API
-	vector := make([]float32, 384)
+// generateEmbedding creates a vector embedding for text using the configured provider
+func (r *RAGClient) generateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := r.embedder.GetEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedding provider returned %d vectors for 1 text", len(vectors))
+	}
+	return vectors[0], nil
+}
 
-	// Simple hash-based vector generation for testing
-	hash := r.simpleHash(text)
-	for i := 0; i < 384; i++ {
-		vector[i] = float32((hash+i)%1000) / 1000.0
+// explainMatch records in the payload the terms shared by the query and the
+// matched item that contributed the most to its score
+func (r *RAGClient) explainMatch(query string, payload map[string]interface{}) {
+	explainer, ok := r.embedder.(SimilarityExplainer)
+	if !ok || payload == nil {
+		return
 	}
 
-	return vector, nil
+	document := fmt.Sprintf("%v %v", payload["title"], payload["description"])
+	payload["top_terms"] = explainer.ExplainSimilarity(query, document, 5)
+}
+
+// extractContextItems splits a roadmap context into its individual item descriptions
+func extractContextItems(roadmapContext string) []string {
+	items := make([]string, 0)
+	for _, part := range strings.Split(roadmapContext, "Item:")[1:] {
+		item := part
+		if idx := strings.IndexAny(item, "\n("); idx >= 0 {
+			item = item[:idx]
+		}
+		item = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(item), "."))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // upsertPoints inserts or updates points in QDrant
@@ -311,15 +514,6 @@ func (r *RAGClient) searchPoints(ctx context.Context, searchReq SearchRequest) (
 	return result.Result, nil
 }
 
-// simpleHash generates a simple hash for mock embedding generation
-func (r *RAGClient) simpleHash(s string) int {
-	h := 0
-	for _, c := range strings.ToLower(s) {
-		h = 31*h + int(c)
-	}
-	return h
-}
-
 // HealthCheck verifies connection to QDrant
 func (r *RAGClient) HealthCheck(ctx context.Context) error {
 	url := fmt.Sprintf("%s/collections", r.qdrantURL)
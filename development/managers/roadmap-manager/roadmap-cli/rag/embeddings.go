@@ -0,0 +1,415 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EmbeddingProvider generates vector embeddings for roadmap text.
+// It has the same method set as indexing.EmbeddingProvider so that providers
+// written for the indexing pipeline can be plugged into the RAG client as-is.
+type EmbeddingProvider interface {
+	// GetEmbeddings generates embeddings for a batch of texts
+	GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+
+	// GetDimensions returns the dimensionality of the embeddings
+	GetDimensions() int
+
+	// GetBatchSize returns the maximum batch size supported
+	GetBatchSize() int
+}
+
+// SimilarityExplainer is implemented by providers able to tell which terms
+// contributed the most to the similarity between two texts
+type SimilarityExplainer interface {
+	ExplainSimilarity(a, b string, topN int) []TermContribution
+}
+
+// CorpusFitter is implemented by providers whose weights depend on the
+// indexed corpus. The fitted table must be the same when indexing and
+// querying: IDF and SetIDF let it be stored with the indexed vectors.
+type CorpusFitter interface {
+	FitIDF(corpus []string)
+	IDF() IDFTable
+	SetIDF(table IDFTable)
+}
+
+// IDFTable is the inverse document frequency table of a fitted corpus
+type IDFTable struct {
+	Terms   map[string]float64 `json:"terms"`
+	Default float64            `json:"default"` // IDF of the terms missing from Terms
+}
+
+// TermContribution is the share of a cosine similarity carried by one term
+type TermContribution struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+// DefaultEmbeddingDimensions is the vector size used by the offline provider
+const DefaultEmbeddingDimensions = 384
+
+// stopWords are ignored by the offline provider (English and French, as used in the roadmaps)
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "the": true, "this": true, "to": true, "with": true,
+	"au": true, "aux": true, "ce": true, "de": true, "des": true, "du": true, "en": true,
+	"et": true, "la": true, "le": true, "les": true, "pour": true, "sur": true, "un": true,
+	"une": true, "dans": true, "par": true,
+}
+
+// HashingEmbeddingProvider is a fully offline TF-IDF embedding provider based on
+// the hashing trick. Unigrams and bigrams are hashed into a fixed number of
+// dimensions with a signed hash, weighted by sublinear term frequency and an
+// optional IDF table, then L2-normalized. The same text always yields the same
+// vector, which keeps similarity results reproducible across runs.
+type HashingEmbeddingProvider struct {
+	dimensions int
+	batchSize  int
+	idf        map[string]float64
+	defaultIDF float64
+}
+
+// NewHashingEmbeddingProvider creates an offline provider with the given dimensionality
+func NewHashingEmbeddingProvider(dimensions int) *HashingEmbeddingProvider {
+	if dimensions <= 0 {
+		dimensions = DefaultEmbeddingDimensions
+	}
+	return &HashingEmbeddingProvider{
+		dimensions: dimensions,
+		batchSize:  256,
+		idf:        make(map[string]float64),
+		defaultIDF: 1.0,
+	}
+}
+
+// FitIDF computes inverse document frequencies from a reference corpus.
+// Terms unseen in the corpus get the highest IDF of the table. The table must
+// be fitted before indexing: vectors built with different tables are not comparable.
+func (p *HashingEmbeddingProvider) FitIDF(corpus []string) {
+	df := make(map[string]int)
+	for _, doc := range corpus {
+		seen := make(map[string]bool)
+		for _, term := range extractTerms(doc) {
+			if !seen[term] {
+				seen[term] = true
+				df[term]++
+			}
+		}
+	}
+
+	n := float64(len(corpus))
+	p.idf = make(map[string]float64, len(df))
+	for term, count := range df {
+		p.idf[term] = math.Log((1+n)/(1+float64(count))) + 1
+	}
+	p.defaultIDF = math.Log(1+n) + 1
+}
+
+// IDF returns a copy of the fitted IDF table
+func (p *HashingEmbeddingProvider) IDF() IDFTable {
+	terms := make(map[string]float64, len(p.idf))
+	for term, idf := range p.idf {
+		terms[term] = idf
+	}
+	return IDFTable{Terms: terms, Default: p.defaultIDF}
+}
+
+// SetIDF replaces the IDF table, typically with the one stored when the
+// collection was indexed
+func (p *HashingEmbeddingProvider) SetIDF(table IDFTable) {
+	p.idf = make(map[string]float64, len(table.Terms))
+	for term, idf := range table.Terms {
+		p.idf[term] = idf
+	}
+	p.defaultIDF = table.Default
+	if p.defaultIDF <= 0 {
+		p.defaultIDF = 1.0
+	}
+}
+
+// GetEmbeddings implements EmbeddingProvider
+func (p *HashingEmbeddingProvider) GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embeddings[i] = p.embed(text)
+	}
+	return embeddings, nil
+}
+
+// GetDimensions implements EmbeddingProvider
+func (p *HashingEmbeddingProvider) GetDimensions() int {
+	return p.dimensions
+}
+
+// GetBatchSize implements EmbeddingProvider
+func (p *HashingEmbeddingProvider) GetBatchSize() int {
+	return p.batchSize
+}
+
+// ExplainSimilarity returns the terms carrying the largest share of the cosine
+// similarity between a and b, ignoring hash collisions
+func (p *HashingEmbeddingProvider) ExplainSimilarity(a, b string, topN int) []TermContribution {
+	wa, na := p.termWeights(a)
+	wb, nb := p.termWeights(b)
+	if na == 0 || nb == 0 {
+		return nil
+	}
+
+	contributions := make([]TermContribution, 0)
+	for term, weight := range wa {
+		if other, ok := wb[term]; ok {
+			contributions = append(contributions, TermContribution{
+				Term:   term,
+				Weight: weight * other / (na * nb),
+			})
+		}
+	}
+
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Weight != contributions[j].Weight {
+			return contributions[i].Weight > contributions[j].Weight
+		}
+		return contributions[i].Term < contributions[j].Term
+	})
+
+	if topN > 0 && len(contributions) > topN {
+		contributions = contributions[:topN]
+	}
+	return contributions
+}
+
+// embed projects the weighted terms of text into a normalized hashed vector
+func (p *HashingEmbeddingProvider) embed(text string) []float32 {
+	vector := make([]float64, p.dimensions)
+	weights, _ := p.termWeights(text)
+	for term, weight := range weights {
+		index, sign := p.hashTerm(term)
+		vector[index] += sign * weight
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	result := make([]float32, p.dimensions)
+	if norm == 0 {
+		return result
+	}
+	for i, v := range vector {
+		result[i] = float32(v / norm)
+	}
+	return result
+}
+
+// termWeights returns the TF-IDF weight of each term and the norm of the term vector
+func (p *HashingEmbeddingProvider) termWeights(text string) (map[string]float64, float64) {
+	tf := make(map[string]int)
+	for _, term := range extractTerms(text) {
+		tf[term]++
+	}
+
+	weights := make(map[string]float64, len(tf))
+	var norm float64
+	for term, count := range tf {
+		idf, ok := p.idf[term]
+		if !ok {
+			idf = p.defaultIDF
+		}
+		weight := (1 + math.Log(float64(count))) * idf
+		// Bigrams refine the ranking but should not dominate single words
+		if strings.Contains(term, " ") {
+			weight *= 0.5
+		}
+		weights[term] = weight
+		norm += weight * weight
+	}
+	return weights, math.Sqrt(norm)
+}
+
+// hashTerm maps a term to a vector index and a sign, limiting the bias of collisions
+func (p *HashingEmbeddingProvider) hashTerm(term string) (int, float64) {
+	h := fnv.New64a()
+	h.Write([]byte(term))
+	sum := h.Sum64()
+
+	sign := 1.0
+	if sum>>63 == 1 {
+		sign = -1.0
+	}
+	return int(sum % uint64(p.dimensions)), sign
+}
+
+// extractTerms tokenizes text into lowercase unigrams and bigrams without stop words
+func extractTerms(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len([]rune(field)) < 2 || stopWords[field] {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+
+	terms := make([]string, 0, len(tokens)*2)
+	terms = append(terms, tokens...)
+	for i := 0; i+1 < len(tokens); i++ {
+		terms = append(terms, tokens[i]+" "+tokens[i+1])
+	}
+	return terms
+}
+
+// OpenAIEmbeddingProvider calls an OpenAI-compatible /embeddings endpoint.
+// Any server implementing that API (OpenAI, LocalAI, Ollama, a test stand-in) can be used.
+type OpenAIEmbeddingProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	batchSize  int
+	client     *http.Client
+}
+
+// NewOpenAIEmbeddingProvider creates a provider for an OpenAI-compatible embeddings API
+func NewOpenAIEmbeddingProvider(baseURL, apiKey, model string, dimensions int) *OpenAIEmbeddingProvider {
+	return &OpenAIEmbeddingProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dimensions: dimensions,
+		batchSize:  64,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetBatchSize sets the maximum number of texts sent in one request
+func (p *OpenAIEmbeddingProvider) SetBatchSize(size int) {
+	if size > 0 {
+		p.batchSize = size
+	}
+}
+
+// GetEmbeddings implements EmbeddingProvider. Texts are sent in requests of
+// at most GetBatchSize texts.
+func (p *OpenAIEmbeddingProvider) GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += p.batchSize {
+		end := min(start+p.batchSize, len(texts))
+		batch, err := p.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// embedBatch sends a single embeddings request
+func (p *OpenAIEmbeddingProvider) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/embeddings", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call embeddings API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings API failed with status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings API returned %d vectors for %d texts", len(result.Data), len(texts))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings API returned out of range index %d", item.Index)
+		}
+		if p.dimensions > 0 && len(item.Embedding) != p.dimensions {
+			return nil, fmt.Errorf("embedding dimension mismatch: expected %d, got %d", p.dimensions, len(item.Embedding))
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// GetDimensions implements EmbeddingProvider
+func (p *OpenAIEmbeddingProvider) GetDimensions() int {
+	return p.dimensions
+}
+
+// GetBatchSize implements EmbeddingProvider
+func (p *OpenAIEmbeddingProvider) GetBatchSize() int {
+	return p.batchSize
+}
+
+// cosineSimilarity computes the cosine similarity between two vectors
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,311 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHashingEmbeddingProvider_Deterministic(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+	ctx := context.Background()
+
+	first, err := provider.GetEmbeddings(ctx, []string{"Build REST API endpoints"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions).GetEmbeddings(ctx, []string{"Build REST API endpoints"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(first[0]) != DefaultEmbeddingDimensions {
+		t.Fatalf("Expected %d dimensions, got %d", DefaultEmbeddingDimensions, len(first[0]))
+	}
+	for i := range first[0] {
+		if first[0][i] != second[0][i] {
+			t.Fatalf("Embeddings differ at index %d", i)
+		}
+	}
+}
+
+func TestHashingEmbeddingProvider_Similarity(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+	vectors, err := provider.GetEmbeddings(context.Background(), []string{
+		"Build REST API for email templates",
+		"REST API endpoints for email templates",
+		"Configure Kubernetes cluster monitoring",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	related := cosineSimilarity(vectors[0], vectors[1])
+	unrelated := cosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Errorf("Expected related texts to score higher (%.3f) than unrelated ones (%.3f)", related, unrelated)
+	}
+	if unrelated > 0.2 {
+		t.Errorf("Unrelated texts should have a low similarity, got %.3f", unrelated)
+	}
+}
+
+func TestHashingEmbeddingProvider_ExplainSimilarity(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+	provider.FitIDF([]string{
+		"email templates",
+		"email campaign",
+		"email scheduler",
+		"database migration",
+	})
+
+	terms := provider.ExplainSimilarity("migrate database for email", "database migration email", 2)
+	if len(terms) != 2 {
+		t.Fatalf("Expected 2 contributing terms, got %d", len(terms))
+	}
+	// "database" is rarer than "email" in the corpus and should weigh more
+	if terms[0].Term != "database" {
+		t.Errorf("Expected 'database' as top term, got %q", terms[0].Term)
+	}
+	if terms[0].Weight < terms[1].Weight {
+		t.Errorf("Contributions should be sorted by weight: %+v", terms)
+	}
+}
+
+func TestOpenAIEmbeddingProvider(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Missing authorization header")
+		}
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		batches = append(batches, len(req.Input))
+
+		// Answer in reverse order to check that indexes are honoured
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := make([]item, 0, len(req.Input))
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float32{float32(i), 1, 0}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIEmbeddingProvider(server.URL+"/v1", "test-key", "test-model", 3)
+	vectors, err := provider.GetEmbeddings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vectors[0][0] != 0 || vectors[1][0] != 1 {
+		t.Errorf("Embeddings were not reordered by index: %v", vectors)
+	}
+
+	// Requests are split by the batch size and indexes are kept per request
+	batches = nil
+	provider.SetBatchSize(2)
+	vectors, err = provider.GetEmbeddings(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(vectors) != 5 || vectors[4][0] != 0 || vectors[3][0] != 1 {
+		t.Errorf("Unexpected batched embeddings: %v", vectors)
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[2] != 1 {
+		t.Errorf("Expected batches of 2, 2 and 1 texts, got %v", batches)
+	}
+
+	mismatch := NewOpenAIEmbeddingProvider(server.URL+"/v1", "test-key", "test-model", 8)
+	if _, err := mismatch.GetEmbeddings(context.Background(), []string{"a"}); err == nil {
+		t.Error("Expected a dimension mismatch error")
+	}
+}
+
+func TestRAGClient_GetSimilarItemsExplained(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+	items := []map[string]interface{}{
+		{"item_id": "1", "title": "Email template editor", "description": "WYSIWYG editor for templates", "type": "roadmap_item"},
+		{"item_id": "2", "title": "Kubernetes monitoring", "description": "Prometheus dashboards", "type": "roadmap_item"},
+	}
+
+	// Minimal QDrant stand-in computing exact cosine scores
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Invalid search request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		points := make([]QDrantPoint, 0, len(items))
+		for _, payload := range items {
+			text := payload["title"].(string) + " " + payload["description"].(string)
+			vectors, _ := provider.GetEmbeddings(context.Background(), []string{text})
+			points = append(points, QDrantPoint{
+				ID:      payload["item_id"].(string),
+				Payload: payload,
+				Score:   float32(cosineSimilarity(req.Vector, vectors[0])),
+			})
+		}
+		if points[1].Score > points[0].Score {
+			points[0], points[1] = points[1], points[0]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": points})
+	}))
+	defer server.Close()
+
+	client := NewRAGClient(server.URL, "", "", WithEmbeddingProvider(provider))
+	insights, err := client.GetSimilarItems(context.Background(), "email template editor", 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if insights[0].ItemID != "1" {
+		t.Fatalf("Expected item 1 to be the best match, got %s", insights[0].ItemID)
+	}
+	terms, ok := insights[0].Context["top_terms"].([]TermContribution)
+	if !ok || len(terms) == 0 {
+		t.Fatalf("Expected top contributing terms in the insight context")
+	}
+}
+
+func TestRAGClient_GenerateRecommendations(t *testing.T) {
+	client := NewRAGClient("http://localhost:6333", "", "")
+
+	roadmapContext := "Roadmap with 3 items and 0 milestones. " +
+		"Item: Email template editor (high priority). " +
+		"Item: Editor for email templates (medium priority). " +
+		"Item: Kubernetes monitoring (low priority). "
+
+	recommendations, err := client.GenerateRecommendations(context.Background(), roadmapContext)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recommendations) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %d", len(recommendations))
+	}
+
+	items := recommendations[0].Context["items"].([]string)
+	if items[0] != "Email template editor" || items[1] != "Editor for email templates" {
+		t.Errorf("Unexpected items in recommendation: %v", items)
+	}
+}
+
+func TestRAGClient_FitCorpus(t *testing.T) {
+	provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+	client := NewRAGClient("http://localhost:6333", "", "", WithEmbeddingProvider(provider))
+
+	before := provider.ExplainSimilarity("email database", "email database", 2)
+	client.FitCorpus([]string{
+		ItemText("Email templates", "editor"),
+		ItemText("Email campaign", "scheduler"),
+		ItemText("Database migration", "schema"),
+	})
+	after := provider.ExplainSimilarity("email database", "email database", 2)
+
+	if before[0].Weight != before[1].Weight {
+		t.Fatalf("Without IDF, terms should weigh the same: %+v", before)
+	}
+	// "database" is rarer than "email" in the indexed corpus
+	if after[0].Term != "database" || after[0].Weight <= after[1].Weight {
+		t.Errorf("Expected the IDF fitted on the corpus to favour 'database': %+v", after)
+	}
+}
+
+func TestRAGClient_SyncCorpus(t *testing.T) {
+	// Minimal QDrant stand-in storing the upserted points
+	var mu sync.Mutex
+	points := make(map[string]QDrantPoint)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "PUT" && strings.HasSuffix(r.URL.Path, "/points"):
+			var req struct {
+				Points []QDrantPoint `json:"points"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, point := range req.Points {
+				points[point.ID] = point
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]string{"status": "completed"}})
+		case r.Method == "GET" && strings.Contains(r.URL.Path, "/points/"):
+			point, ok := points[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": point})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	newClient := func() (*RAGClient, *HashingEmbeddingProvider) {
+		provider := NewHashingEmbeddingProvider(DefaultEmbeddingDimensions)
+		return NewRAGClient(server.URL, "", "", WithEmbeddingProvider(provider)), provider
+	}
+
+	// The first indexing fits the statistics and stores them with the collection
+	plans, plansProvider := newClient()
+	if err := plans.SyncCorpus(ctx, []string{"Email templates editor", "Email campaign scheduler", "Database migration"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mu.Lock()
+	stored := len(points)
+	mu.Unlock()
+	if stored != 1 {
+		t.Fatalf("Expected the statistics point to be stored, got %d points", stored)
+	}
+
+	// Indexing another corpus into the same collection reuses them
+	items, itemsProvider := newClient()
+	if err := items.SyncCorpus(ctx, []string{"Kubernetes monitoring", "Prometheus dashboards"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(itemsProvider.IDF(), plansProvider.IDF()) {
+		t.Error("Expected the stored IDF table to be reused instead of refitted")
+	}
+
+	// Queries load the same table, so query and stored vectors are comparable
+	query, queryProvider := newClient()
+	loaded, err := query.LoadCorpus(ctx)
+	if err != nil || !loaded {
+		t.Fatalf("Expected the statistics to be loaded, got %v (%v)", loaded, err)
+	}
+	indexed, _ := plansProvider.GetEmbeddings(ctx, []string{"email database"})
+	queried, _ := queryProvider.GetEmbeddings(ctx, []string{"email database"})
+	if !reflect.DeepEqual(indexed, queried) {
+		t.Error("Expected identical vectors with the loaded statistics")
+	}
+
+	// A collection without statistics leaves the provider unchanged
+	empty := NewRAGClient(server.URL, "", "", WithCollectionName("other"))
+	if loaded, err := empty.LoadCorpus(ctx); err != nil || loaded {
+		t.Errorf("Expected no statistics for an empty collection, got %v (%v)", loaded, err)
+	}
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix préfixe toutes les clés générées, pour les repérer dans les logs et les scans de secrets
+const apiKeyPrefix = "esk"
+
+// APIKey décrit une clé API ; seul le hash du secret est conservé
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"` // SHA-256 hex du secret
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	RateLimit int       `json:"rate_limit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Revoked   bool      `json:"revoked,omitempty"`
+}
+
+// APIKeyStore conserve les clés API, en mémoire et dans un fichier JSON (0600) si un chemin est fourni
+type APIKeyStore struct {
+	path string
+	mu   sync.RWMutex
+	keys map[string]*APIKey
+}
+
+// NewAPIKeyStore charge les clés du fichier path ; un chemin vide donne un store en mémoire
+func NewAPIKeyStore(path string) (*APIKeyStore, error) {
+	store := &APIKeyStore{path: path, keys: make(map[string]*APIKey)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	var keys []*APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+	}
+	for _, key := range keys {
+		store.keys[key.ID] = key
+	}
+	return store, nil
+}
+
+// Generate crée une clé API et retourne sa valeur, qui n'est plus récupérable ensuite
+func (s *APIKeyStore) Generate(name string, roles, scopes []string, rateLimit int, ttl time.Duration) (string, *APIKey, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      hashSecret(secret),
+		Roles:     roles,
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		key.ExpiresAt = key.CreatedAt.Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = key
+	if err := s.save(); err != nil {
+		delete(s.keys, id)
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s_%s_%s", apiKeyPrefix, id, secret), key, nil
+}
+
+// Authenticate vérifie une clé API et retourne son principal
+func (s *APIKeyStore) Authenticate(value string) (*Principal, error) {
+	parts := strings.Split(value, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, ErrInvalidCredentials
+	}
+
+	s.mu.RLock()
+	key, exists := s.keys[parts[1]]
+	s.mu.RUnlock()
+	if !exists || key.Revoked {
+		return nil, ErrInvalidCredentials
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[2])), []byte(key.Hash)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	return &Principal{
+		ID:        "apikey:" + key.ID,
+		Type:      PrincipalAPIKey,
+		Roles:     key.Roles,
+		Scopes:    key.Scopes,
+		RateLimit: key.RateLimit,
+	}, nil
+}
+
+// Revoke révoque une clé API
+func (s *APIKeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return fmt.Errorf("API key %s not found", id)
+	}
+	key.Revoked = true
+	if err := s.save(); err != nil {
+		key.Revoked = false
+		return err
+	}
+	return nil
+}
+
+// List retourne les clés API triées par date de création
+func (s *APIKeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// save écrit le fichier des clés de manière atomique (verrou détenu)
+func (s *APIKeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize API keys: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create API keys directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API keys file: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// hashSecret retourne le SHA-256 hex d'un secret de clé API
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex génère n octets aléatoires encodés en hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
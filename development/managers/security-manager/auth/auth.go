@@ -0,0 +1,72 @@
+// Package auth fournit l'authentification et le contrôle d'accès partagés par
+// les API HTTP de l'écosystème : clés API à scopes, JWT (HS256/EdDSA),
+// permissions par rôle et par route, limitation de débit par principal et
+// audit des requêtes refusées via le Security Manager.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated est retourné quand aucun identifiant n'est fourni
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrInvalidCredentials est retourné pour une clé API ou un jeton invalide
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrExpiredToken est retourné pour un jeton expiré
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Types de principal
+const (
+	PrincipalAPIKey = "api_key"
+	PrincipalJWT    = "jwt"
+)
+
+// Principal est l'identité authentifiée d'une requête
+type Principal struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`     // Permissions accordées directement
+	RateLimit int      `json:"rate_limit,omitempty"` // Requêtes par seconde, 0 pour la valeur par défaut
+}
+
+// RateLimiter limite le débit par identifiant ; SecurityManagerImpl l'implémente
+type RateLimiter interface {
+	CheckRateLimit(identifier string, limit int) bool
+}
+
+// AuditLogger journalise les événements ; l'AuditLogger du Security Manager l'implémente
+type AuditLogger interface {
+	LogEvent(category, action, description string, metadata map[string]interface{}) error
+}
+
+type principalKey struct{}
+
+// WithPrincipal attache un principal au contexte
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext retourne le principal authentifié de la requête
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// HasPermission indique si une permission est accordée par une liste de
+// permissions : "*" accorde tout, "vectors:*" accorde "vectors:read"
+func HasPermission(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == "*" || g == permission {
+			return true
+		}
+		if strings.HasSuffix(g, ":*") && strings.HasPrefix(permission, strings.TrimSuffix(g, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAudit conserve les événements audités
+type recordingAudit struct {
+	events []map[string]interface{}
+}
+
+func (a *recordingAudit) LogEvent(category, action, description string, metadata map[string]interface{}) error {
+	a.events = append(a.events, metadata)
+	return nil
+}
+
+// countingLimiter autorise un nombre fixe de requêtes par identifiant
+type countingLimiter struct {
+	counts map[string]int
+}
+
+func (l *countingLimiter) CheckRateLimit(identifier string, limit int) bool {
+	l.counts[identifier]++
+	return l.counts[identifier] <= limit
+}
+
+func testPolicy() *Policy {
+	return &Policy{
+		Roles: map[string][]string{
+			"viewer":   {"vectors:read"},
+			"operator": {"vectors:*"},
+			"admin":    {"*"},
+		},
+		Routes: []Route{
+			{Path: "/health", Public: true},
+			{Method: "GET", Path: "/api/v1/vectors/*", Permission: "vectors:read"},
+			{Method: "DELETE", Path: "/api/v1/vectors/:id", Permission: "vectors:delete"},
+			{Path: "/api/v1/config/*", Permission: "config:write"},
+		},
+	}
+}
+
+func TestJWTManager(t *testing.T) {
+	hs, err := NewHS256Manager([]byte(strings.Repeat("k", 32)), "email-sender", time.Minute)
+	require.NoError(t, err)
+
+	token, err := hs.Issue(&Principal{ID: "alice", Roles: []string{"viewer"}})
+	require.NoError(t, err)
+	principal, err := hs.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.ID)
+	assert.Equal(t, []string{"viewer"}, principal.Roles)
+
+	// Signature altérée
+	_, err = hs.Verify(token[:len(token)-2] + "xx")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Jeton expiré
+	expired, err := hs.Sign(Claims{Subject: "alice", Issuer: "email-sender", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+	require.NoError(t, err)
+	_, err = hs.Verify(expired)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+
+	_, err = NewHS256Manager([]byte("short"), "", time.Minute)
+	assert.Error(t, err)
+
+	// EdDSA : un vérificateur sans clé privée accepte les jetons du signataire
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signer, err := NewEdDSAManager(private, nil, "email-sender", time.Minute)
+	require.NoError(t, err)
+	verifier, err := NewEdDSAManager(nil, public, "email-sender", time.Minute)
+	require.NoError(t, err)
+
+	token, err = signer.Issue(&Principal{ID: "bob", Scopes: []string{"config:write"}})
+	require.NoError(t, err)
+	principal, err = verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config:write"}, principal.Scopes)
+
+	_, err = verifier.Issue(&Principal{ID: "bob"})
+	assert.Error(t, err)
+
+	// Un jeton HS256 n'est pas accepté par un vérificateur EdDSA
+	hsToken, err := hs.Issue(&Principal{ID: "alice"})
+	require.NoError(t, err)
+	_, err = verifier.Verify(hsToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAPIKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	store, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+
+	value, key, err := store.Generate("ci", []string{"viewer"}, []string{"config:write"}, 5, 0)
+	require.NoError(t, err)
+	assert.NotContains(t, key.Hash, strings.Split(value, "_")[2])
+
+	// Les clés survivent au rechargement
+	reloaded, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+	principal, err := reloaded.Authenticate(value)
+	require.NoError(t, err)
+	assert.Equal(t, "apikey:"+key.ID, principal.ID)
+	assert.Equal(t, 5, principal.RateLimit)
+
+	_, err = reloaded.Authenticate(value + "0")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	require.NoError(t, reloaded.Revoke(key.ID))
+	_, err = reloaded.Authenticate(value)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestPolicyMatch(t *testing.T) {
+	policy := testPolicy()
+
+	route, ok := policy.Match("DELETE", "/api/v1/vectors/42")
+	require.True(t, ok)
+	assert.Equal(t, "vectors:delete", route.Permission)
+
+	route, ok = policy.Match("GET", "/api/v1/vectors/list")
+	require.True(t, ok)
+	assert.Equal(t, "vectors:read", route.Permission)
+
+	_, ok = policy.Match("POST", "/api/v1/unknown")
+	assert.False(t, ok)
+
+	assert.True(t, policy.Authorize(&Principal{Roles: []string{"operator"}}, Route{Permission: "vectors:delete"}))
+	assert.False(t, policy.Authorize(&Principal{Roles: []string{"viewer"}}, Route{Permission: "vectors:delete"}))
+	assert.True(t, policy.Authorize(&Principal{Scopes: []string{"vectors:delete"}}, Route{Permission: "vectors:delete"}))
+}
+
+func TestMiddleware(t *testing.T) {
+	store, err := NewAPIKeyStore("")
+	require.NoError(t, err)
+	viewerKey, _, err := store.Generate("viewer", []string{"viewer"}, nil, 2, 0)
+	require.NoError(t, err)
+
+	jwt, err := NewHS256Manager([]byte(strings.Repeat("s", 32)), "", time.Minute)
+	require.NoError(t, err)
+	adminToken, err := jwt.Issue(&Principal{ID: "admin", Roles: []string{"admin"}})
+	require.NoError(t, err)
+
+	audit := &recordingAudit{}
+	middleware := NewMiddleware(Options{
+		APIKeys:          store,
+		JWT:              jwt,
+		Policy:           testPolicy(),
+		RateLimiter:      &countingLimiter{counts: map[string]int{}},
+		DefaultRateLimit: 100,
+		Audit:            audit,
+	})
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := PrincipalFromContext(r.Context())
+		if principal != nil {
+			w.Write([]byte(principal.ID))
+		}
+	}))
+
+	do := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("GET", "/health").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/vectors/list").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/api/v1/unknown", "Authorization", "Bearer "+adminToken).Code)
+
+	rec := do("GET", "/api/v1/vectors/list", "X-API-Key", viewerKey)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "apikey:"))
+
+	assert.Equal(t, http.StatusForbidden, do("DELETE", "/api/v1/vectors/1", "Authorization", "ApiKey "+viewerKey).Code)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/api/v1/vectors/1", "Authorization", "Bearer "+adminToken).Code)
+
+	// La clé viewer est limitée à 2 requêtes
+	rec = do("GET", "/api/v1/vectors/list", "Authorization", "Bearer "+viewerKey)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Chaque refus est audité
+	require.Len(t, audit.events, 4)
+	assert.Equal(t, http.StatusUnauthorized, audit.events[0]["status"])
+	assert.Equal(t, "/api/v1/vectors/1", audit.events[2]["path"])
+	assert.NotEmpty(t, audit.events[3]["principal"])
+}
+
+func TestMiddlewareGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwt, err := NewHS256Manager([]byte(strings.Repeat("s", 32)), "", time.Minute)
+	require.NoError(t, err)
+	token, err := jwt.Issue(&Principal{ID: "carol", Roles: []string{"admin"}})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(NewMiddleware(Options{JWT: jwt, Policy: testPolicy()}).Gin())
+	router.POST("/api/v1/config/:key", func(c *gin.Context) {
+		principal, _ := c.Get("principal")
+		c.String(http.StatusOK, principal.(*Principal).ID)
+	})
+
+	req := httptest.NewRequest("POST", "/api/v1/config/smtp", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "carol", rec.Body.String())
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	policyPath := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`roles:
+  viewer: ["status:read"]
+routes:
+  - {path: /health, public: true}
+  - {method: GET, path: /status, permission: "status:read"}
+`), 0600))
+
+	t.Setenv(EnvJWTSecret, "")
+	t.Setenv(EnvAPIKeysFile, "")
+	_, err := OptionsFromEnv(testPolicy())
+	assert.Error(t, err, "at least one authentication method is required")
+
+	t.Setenv(EnvJWTSecret, strings.Repeat("x", 32))
+	t.Setenv(EnvAPIKeysFile, filepath.Join(dir, "keys.json"))
+	t.Setenv(EnvPolicyFile, policyPath)
+	opts, err := OptionsFromEnv(testPolicy())
+	require.NoError(t, err)
+	assert.NotNil(t, opts.JWT)
+	assert.NotNil(t, opts.APIKeys)
+	route, ok := opts.Policy.Match("GET", "/status")
+	require.True(t, ok)
+	assert.Equal(t, "status:read", route.Permission)
+
+	require.NoError(t, os.WriteFile(policyPath, []byte("routes:\n  - {path: /admin}\n"), 0600))
+	_, err = OptionsFromEnv(testPolicy())
+	assert.Error(t, err, "routes without permission must be rejected")
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.CheckRateLimit("alice", 3))
+	}
+	assert.False(t, limiter.CheckRateLimit("alice", 3), "bucket is empty")
+	assert.True(t, limiter.CheckRateLimit("bob", 3), "buckets are per identifier")
+
+	now = now.Add(time.Second / 2)
+	assert.True(t, limiter.CheckRateLimit("alice", 3), "one token refilled")
+	assert.False(t, limiter.CheckRateLimit("alice", 3))
+}
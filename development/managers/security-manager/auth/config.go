@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variables d'environnement lues par OptionsFromEnv
+const (
+	EnvAPIKeysFile   = "AUTH_API_KEYS_FILE"   // Fichier des clés API
+	EnvJWTSecret     = "AUTH_JWT_SECRET"      // Secret HS256 (au moins 32 octets)
+	EnvJWTPrivateKey = "AUTH_JWT_PRIVATE_KEY" // Clé privée Ed25519 (hex ou base64) pour émettre en EdDSA
+	EnvJWTPublicKey  = "AUTH_JWT_PUBLIC_KEY"  // Clé publique Ed25519 (hex ou base64) pour vérifier en EdDSA
+	EnvJWTIssuer     = "AUTH_JWT_ISSUER"      // Émetteur attendu
+	EnvJWTTTL        = "AUTH_JWT_TTL"         // Durée de vie des jetons émis, 1h par défaut
+	EnvPolicyFile    = "AUTH_POLICY_FILE"     // Politique YAML remplaçant celle du serveur
+)
+
+// LoadPolicy charge une politique YAML (ou JSON) :
+//
+//	roles:
+//	  viewer: ["vectors:read"]
+//	routes:
+//	  - {method: GET, path: /api/v1/vectors/*, permission: vectors:read}
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth policy: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse auth policy %s: %w", path, err)
+	}
+	for i, route := range policy.Routes {
+		if route.Path == "" {
+			return nil, fmt.Errorf("auth policy %s: route %d has no path", path, i)
+		}
+		if !route.Public && route.Permission == "" {
+			return nil, fmt.Errorf("auth policy %s: route %s needs a permission or public: true", path, route.Path)
+		}
+	}
+	return &policy, nil
+}
+
+// OptionsFromEnv construit les options du middleware depuis l'environnement ;
+// defaultPolicy s'applique si AUTH_POLICY_FILE n'est pas défini. Au moins un
+// moyen d'authentification (clés API ou JWT) doit être configuré.
+func OptionsFromEnv(defaultPolicy *Policy) (Options, error) {
+	opts := Options{Policy: defaultPolicy}
+
+	if path := os.Getenv(EnvPolicyFile); path != "" {
+		policy, err := LoadPolicy(path)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Policy = policy
+	}
+
+	if path := os.Getenv(EnvAPIKeysFile); path != "" {
+		store, err := NewAPIKeyStore(path)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.APIKeys = store
+	}
+
+	ttl := time.Hour
+	if value := os.Getenv(EnvJWTTTL); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid %s: %w", EnvJWTTTL, err)
+		}
+		ttl = parsed
+	}
+	issuer := os.Getenv(EnvJWTIssuer)
+
+	switch {
+	case os.Getenv(EnvJWTSecret) != "":
+		manager, err := NewHS256Manager([]byte(os.Getenv(EnvJWTSecret)), issuer, ttl)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.JWT = manager
+	case os.Getenv(EnvJWTPrivateKey) != "" || os.Getenv(EnvJWTPublicKey) != "":
+		var privateKey ed25519.PrivateKey
+		var publicKey ed25519.PublicKey
+		if value := os.Getenv(EnvJWTPrivateKey); value != "" {
+			raw, err := decodeKey(value)
+			if err != nil || len(raw) != ed25519.PrivateKeySize {
+				return Options{}, fmt.Errorf("invalid %s: expected a %d-byte Ed25519 private key", EnvJWTPrivateKey, ed25519.PrivateKeySize)
+			}
+			privateKey = ed25519.PrivateKey(raw)
+		}
+		if value := os.Getenv(EnvJWTPublicKey); value != "" {
+			raw, err := decodeKey(value)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid %s: %w", EnvJWTPublicKey, err)
+			}
+			publicKey = ed25519.PublicKey(raw)
+		}
+		manager, err := NewEdDSAManager(privateKey, publicKey, issuer, ttl)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.JWT = manager
+	}
+
+	if opts.APIKeys == nil && opts.JWT == nil {
+		return Options{}, fmt.Errorf("no authentication configured: set %s or %s/%s", EnvAPIKeysFile, EnvJWTSecret, EnvJWTPublicKey)
+	}
+	return opts, nil
+}
+
+// decodeKey décode une clé en hex ou en base64
+func decodeKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if raw, err := hex.DecodeString(value); err == nil {
+		return raw, nil
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
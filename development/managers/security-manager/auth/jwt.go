@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Algorithmes JWT supportés
+const (
+	AlgHS256 = "HS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Claims sont les revendications des jetons émis
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  string   `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	RateLimit int      `json:"rate_limit,omitempty"`
+}
+
+// jwtHeader est l'en-tête JOSE
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// JWTManager émet et vérifie des jetons JWT signés en HS256 ou EdDSA (Ed25519).
+// L'algorithme est fixé à la construction : l'en-tête "alg" d'un jeton ne peut
+// pas en imposer un autre.
+type JWTManager struct {
+	alg        string
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	issuer     string
+	audience   string
+	ttl        time.Duration
+	leeway     time.Duration
+}
+
+// NewHS256Manager crée un gestionnaire JWT HMAC-SHA256 ; le secret doit faire au moins 32 octets
+func NewHS256Manager(secret []byte, issuer string, ttl time.Duration) (*JWTManager, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+	}
+	return &JWTManager{alg: AlgHS256, secret: secret, issuer: issuer, ttl: ttl, leeway: 30 * time.Second}, nil
+}
+
+// NewEdDSAManager crée un gestionnaire JWT Ed25519 ; sans clé privée, il ne fait que vérifier
+func NewEdDSAManager(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, issuer string, ttl time.Duration) (*JWTManager, error) {
+	if publicKey == nil && privateKey != nil {
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("EdDSA public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return &JWTManager{alg: AlgEdDSA, privateKey: privateKey, publicKey: publicKey, issuer: issuer, ttl: ttl, leeway: 30 * time.Second}, nil
+}
+
+// SetAudience restreint les jetons acceptés à une audience
+func (m *JWTManager) SetAudience(audience string) {
+	m.audience = audience
+}
+
+// Issue émet un jeton pour un principal
+func (m *JWTManager) Issue(principal *Principal) (string, error) {
+	now := time.Now()
+	jti, err := randomHex(8)
+	if err != nil {
+		return "", err
+	}
+	return m.Sign(Claims{
+		Subject:   principal.ID,
+		Issuer:    m.issuer,
+		Audience:  m.audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(m.ttl).Unix(),
+		ID:        jti,
+		Roles:     principal.Roles,
+		Scopes:    principal.Scopes,
+		RateLimit: principal.RateLimit,
+	})
+}
+
+// Sign signe des claims
+func (m *JWTManager) Sign(claims Claims) (string, error) {
+	if m.alg == AlgEdDSA && m.privateKey == nil {
+		return "", fmt.Errorf("no EdDSA private key configured for signing")
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: m.alg, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize claims: %w", err)
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	return signingInput + "." + encodeSegment(m.signature([]byte(signingInput))), nil
+}
+
+// Verify vérifie la signature et la validité d'un jeton et retourne son principal
+func (m *JWTManager) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != m.alg {
+		return nil, ErrInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	switch m.alg {
+	case AlgHS256:
+		if !hmac.Equal(signature, m.signature(signingInput)) {
+			return nil, ErrInvalidCredentials
+		}
+	case AlgEdDSA:
+		if !ed25519.Verify(m.publicKey, signingInput, signature) {
+			return nil, ErrInvalidCredentials
+		}
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(m.leeway)) {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now.Add(m.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrInvalidCredentials
+	}
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return nil, ErrInvalidCredentials
+	}
+	if m.audience != "" && claims.Audience != m.audience {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{
+		ID:        claims.Subject,
+		Type:      PrincipalJWT,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		RateLimit: claims.RateLimit,
+	}, nil
+}
+
+// signature calcule la signature HS256 ou EdDSA
+func (m *JWTManager) signature(input []byte) []byte {
+	if m.alg == AlgEdDSA {
+		return ed25519.Sign(m.privateKey, input)
+	}
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+// encodeSegment encode un segment JWT en base64url sans padding
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSegment décode un segment JWT JSON
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
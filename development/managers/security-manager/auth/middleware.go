@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options configure le middleware d'authentification
+type Options struct {
+	APIKeys          *APIKeyStore // Nil désactive les clés API
+	JWT              *JWTManager  // Nil désactive les jetons JWT
+	Policy           *Policy
+	RateLimiter      RateLimiter // Nil désactive la limitation de débit
+	DefaultRateLimit int         // Requêtes par seconde pour un principal sans limite propre
+	Audit            AuditLogger // Nil désactive l'audit
+}
+
+// Middleware authentifie chaque requête, vérifie la permission de sa route et
+// applique la limite de débit du principal. Chaque refus est audité.
+type Middleware struct {
+	opts Options
+}
+
+// NewMiddleware crée le middleware
+func NewMiddleware(opts Options) *Middleware {
+	if opts.Policy == nil {
+		opts.Policy = &Policy{}
+	}
+	return &Middleware{opts: opts}
+}
+
+// Decision est le résultat de l'évaluation d'une requête
+type Decision struct {
+	Principal *Principal
+	Status    int    // http.StatusOK si la requête est autorisée
+	Reason    string // Motif du refus
+}
+
+// Evaluate authentifie et autorise une requête
+func (m *Middleware) Evaluate(r *http.Request) Decision {
+	route, matched := m.opts.Policy.Match(r.Method, r.URL.Path)
+	if !matched {
+		return m.deny(r, nil, http.StatusForbidden, "no route rule")
+	}
+	if route.Public {
+		return Decision{Status: http.StatusOK}
+	}
+
+	principal, err := m.Authenticate(r)
+	if err != nil {
+		return m.deny(r, nil, http.StatusUnauthorized, err.Error())
+	}
+
+	// Les requêtes refusées comptent aussi dans la limite du principal
+	if m.opts.RateLimiter != nil {
+		limit := principal.RateLimit
+		if limit <= 0 {
+			limit = m.opts.DefaultRateLimit
+		}
+		if limit > 0 && !m.opts.RateLimiter.CheckRateLimit("principal:"+principal.ID, limit) {
+			return m.deny(r, principal, http.StatusTooManyRequests, "rate limit exceeded")
+		}
+	}
+
+	if !m.opts.Policy.Authorize(principal, route) {
+		return m.deny(r, principal, http.StatusForbidden, "missing permission "+route.Permission)
+	}
+
+	return Decision{Principal: principal, Status: http.StatusOK}
+}
+
+// Authenticate identifie le principal d'une requête : clé API dans l'en-tête
+// X-API-Key ou "Authorization: ApiKey <clé>", jeton dans "Authorization: Bearer <jeton>"
+func (m *Middleware) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return m.authenticateAPIKey(key)
+	}
+
+	scheme, credentials, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found {
+		return nil, ErrUnauthenticated
+	}
+	switch strings.ToLower(scheme) {
+	case "apikey":
+		return m.authenticateAPIKey(strings.TrimSpace(credentials))
+	case "bearer":
+		credentials = strings.TrimSpace(credentials)
+		// Une clé API peut aussi être présentée comme bearer token
+		if strings.HasPrefix(credentials, apiKeyPrefix+"_") {
+			return m.authenticateAPIKey(credentials)
+		}
+		if m.opts.JWT == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return m.opts.JWT.Verify(credentials)
+	default:
+		return nil, ErrUnauthenticated
+	}
+}
+
+// Handler protège un http.Handler
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := m.Evaluate(r)
+		if decision.Status != http.StatusOK {
+			writeDenied(w, decision)
+			return
+		}
+		if decision.Principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), decision.Principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Gin retourne le middleware pour un routeur gin ; le principal est aussi
+// disponible via c.Get("principal")
+func (m *Middleware) Gin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision := m.Evaluate(c.Request)
+		if decision.Status != http.StatusOK {
+			if decision.Status == http.StatusTooManyRequests {
+				c.Header("Retry-After", "1")
+			}
+			c.AbortWithStatusJSON(decision.Status, gin.H{"error": http.StatusText(decision.Status), "reason": decision.Reason})
+			return
+		}
+		if decision.Principal != nil {
+			c.Set("principal", decision.Principal)
+			c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), decision.Principal))
+		}
+		c.Next()
+	}
+}
+
+// authenticateAPIKey vérifie une clé API
+func (m *Middleware) authenticateAPIKey(key string) (*Principal, error) {
+	if m.opts.APIKeys == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return m.opts.APIKeys.Authenticate(key)
+}
+
+// deny audite un refus et retourne la décision correspondante
+func (m *Middleware) deny(r *http.Request, principal *Principal, status int, reason string) Decision {
+	if m.opts.Audit != nil {
+		metadata := map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      status,
+			"remote_addr": clientIP(r),
+		}
+		if principal != nil {
+			metadata["principal"] = principal.ID
+			metadata["principal_type"] = principal.Type
+		}
+		m.opts.Audit.LogEvent("AUTH", "REQUEST_DENIED", reason, metadata)
+	}
+	return Decision{Principal: principal, Status: status, Reason: reason}
+}
+
+// writeDenied écrit la réponse JSON d'un refus
+func writeDenied(w http.ResponseWriter, decision Decision) {
+	w.Header().Set("Content-Type", "application/json")
+	switch decision.Status {
+	case http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="email-sender"`)
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "1")
+	}
+	w.WriteHeader(decision.Status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":  http.StatusText(decision.Status),
+		"reason": decision.Reason,
+	})
+}
+
+// clientIP retourne l'adresse du client, sans le port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
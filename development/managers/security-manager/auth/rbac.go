@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"strings"
+)
+
+// Route associe une méthode et un chemin à la permission requise. Le chemin
+// accepte des segments variables (":id" ou "{id}") et un "*" final qui couvre
+// tous les sous-chemins.
+type Route struct {
+	Method     string `json:"method" yaml:"method"` // Vide ou "*" pour toutes les méthodes
+	Path       string `json:"path" yaml:"path"`
+	Permission string `json:"permission,omitempty" yaml:"permission,omitempty"`
+	Public     bool   `json:"public,omitempty" yaml:"public,omitempty"` // Aucune authentification
+}
+
+// Policy définit les permissions de chaque rôle et les permissions requises
+// par route. Les routes sont évaluées dans l'ordre : la première qui
+// correspond s'applique, une requête sans route correspondante est refusée.
+type Policy struct {
+	Roles  map[string][]string `json:"roles" yaml:"roles"`
+	Routes []Route             `json:"routes" yaml:"routes"`
+}
+
+// Match retourne la règle applicable à une requête
+func (p *Policy) Match(method, path string) (Route, bool) {
+	for _, route := range p.Routes {
+		if route.Method != "" && route.Method != "*" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if matchPath(route.Path, path) {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// Permissions retourne les permissions effectives d'un principal : celles de
+// ses rôles et ses scopes
+func (p *Policy) Permissions(principal *Principal) []string {
+	permissions := append([]string{}, principal.Scopes...)
+	for _, role := range principal.Roles {
+		permissions = append(permissions, p.Roles[role]...)
+	}
+	return permissions
+}
+
+// Authorize indique si un principal a la permission requise par une route
+func (p *Policy) Authorize(principal *Principal, route Route) bool {
+	if route.Public || route.Permission == "" {
+		return true
+	}
+	return HasPermission(p.Permissions(principal), route.Permission)
+}
+
+// matchPath compare un chemin à un motif de route
+func matchPath(pattern, path string) bool {
+	patternSegments := splitPath(pattern)
+	pathSegments := splitPath(path)
+
+	for i, segment := range patternSegments {
+		if segment == "*" && i == len(patternSegments)-1 {
+			return len(pathSegments) >= i
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// splitPath découpe un chemin en segments, sans les slashs de début et de fin
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
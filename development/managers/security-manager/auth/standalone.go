@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimit est la limite par défaut d'un principal, en requêtes par seconde
+const DefaultRateLimit = 100
+
+// MemoryRateLimiter est un RateLimiter en mémoire : un seau à jetons par
+// identifiant, de capacité égale à la limite. Il sert aux serveurs qui
+// n'embarquent pas le Security Manager.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryRateLimiter crée un limiteur en mémoire
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// CheckRateLimit consomme un jeton du seau de identifier
+func (l *MemoryRateLimiter) CheckRateLimit(identifier string, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, exists := l.buckets[identifier]
+	if !exists {
+		b = &bucket{tokens: float64(limit), last: now}
+		l.buckets[identifier] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * float64(limit)
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// LogAudit est un AuditLogger écrivant dans un log.Logger
+type LogAudit struct {
+	logger *log.Logger
+}
+
+// NewLogAudit crée un audit vers logger, log.Default() s'il est nil
+func NewLogAudit(logger *log.Logger) *LogAudit {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogAudit{logger: logger}
+}
+
+// LogEvent journalise un événement
+func (a *LogAudit) LogEvent(category, action, description string, metadata map[string]interface{}) error {
+	a.logger.Printf("[AUDIT] %s %s: %s %v", category, action, description, metadata)
+	return nil
+}
+
+// MiddlewareFromEnv crée le middleware d'un serveur autonome depuis
+// l'environnement (voir OptionsFromEnv), avec une limite de débit en mémoire
+// et un audit vers le log standard. Un serveur embarquant le Security Manager
+// utilise plutôt SecurityManagerImpl.AuthMiddleware.
+func MiddlewareFromEnv(defaultPolicy *Policy) (*Middleware, error) {
+	opts, err := OptionsFromEnv(defaultPolicy)
+	if err != nil {
+		return nil, err
+	}
+	opts.RateLimiter = NewMemoryRateLimiter()
+	opts.DefaultRateLimit = DefaultRateLimit
+	opts.Audit = NewLogAudit(nil)
+	return NewMiddleware(opts), nil
+}
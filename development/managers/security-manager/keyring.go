@@ -0,0 +1,568 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format d'un chiffré enveloppe :
+//
+//	magic "ESK" | version (1) | len(keyID) (1) | keyID | len(wrappedKey) (2, big endian) | wrappedKey | nonce | données chiffrées
+//
+// Chaque enregistrement est chiffré avec sa propre clé de données (AES-256-GCM),
+// elle-même chiffrée (« wrappée ») par la clé maître identifiée par keyID. Une
+// rotation de la clé maître ne demande donc que de re-wrapper la clé de données.
+const (
+	envelopeMagic   = "ESK"
+	envelopeVersion = byte(1)
+	dataKeySize     = 32
+)
+
+var (
+	// ErrUnknownKey est retourné quand la clé maître d'un chiffré n'existe pas
+	ErrUnknownKey = errors.New("unknown master key")
+	// ErrNotEnvelope est retourné pour un chiffré sans en-tête d'enveloppe
+	ErrNotEnvelope = errors.New("ciphertext has no envelope header")
+	// ErrFixedKey est retourné par la rotation d'une clé maître dérivée
+	ErrFixedKey = errors.New("master key derived from encryption_key cannot be rotated, configure a master key file or key provider")
+)
+
+// derivedKeyID identifie la clé maître dérivée de l'ancienne clé statique ;
+// elle n'a pas de numéro de version et ne se confond pas avec "mk-vN"
+const derivedKeyID = "mk-derived"
+
+// KeyInfo décrit une version de clé maître
+type KeyInfo struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MasterKeyProvider fournit les clés maîtres versionnées qui protègent les
+// clés de données. Un KMS externe (Vault transit, AWS KMS, ...) s'intègre en
+// implémentant cette interface : la clé maître ne quitte alors jamais le KMS.
+type MasterKeyProvider interface {
+	// CurrentKey retourne la clé maître utilisée pour les nouveaux chiffrements
+	CurrentKey(ctx context.Context) (KeyInfo, error)
+	// RotateKey crée une nouvelle version de clé maître et la rend courante
+	RotateKey(ctx context.Context) (KeyInfo, error)
+	// WrapKey chiffre une clé de données avec la clé maître keyID
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey déchiffre une clé de données wrappée par la clé maître keyID
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Keyring chiffre les données par enveloppe avec les clés d'un MasterKeyProvider.
+// Les chiffrés produits avant l'introduction des enveloppes (AES-GCM avec une
+// clé statique, sans en-tête) restent lisibles grâce à la clé héritée, de même
+// que les enveloppes wrappées par la clé maître dérivée de cette clé.
+type Keyring struct {
+	provider  MasterKeyProvider
+	legacyKey []byte
+	derived   *LocalKeyProvider
+}
+
+// NewKeyring crée un keyring ; legacyKey peut être nil
+func NewKeyring(provider MasterKeyProvider, legacyKey []byte) *Keyring {
+	keyring := &Keyring{provider: provider, legacyKey: legacyKey}
+	if legacyKey != nil {
+		keyring.derived = NewDerivedKeyProvider(legacyKey)
+	}
+	return keyring
+}
+
+// Encrypt chiffre des données avec une nouvelle clé de données wrappée par la clé maître courante
+func (k *Keyring) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	return k.Seal(ctx, plaintext, nil)
+}
+
+// Seal chiffre comme Encrypt en authentifiant associated (par exemple le nom
+// de l'enregistrement) : le chiffré ne s'ouvre qu'avec les mêmes données associées
+func (k *Keyring) Seal(ctx context.Context, plaintext, associated []byte) ([]byte, error) {
+	current, err := k.provider.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current master key: %w", err)
+	}
+	if len(current.ID) > 255 {
+		return nil, fmt.Errorf("master key ID too long: %s", current.ID)
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrapped, err := k.provider.WrapKey(ctx, current.ID, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	if len(wrapped) > 0xFFFF {
+		return nil, fmt.Errorf("wrapped data key too long: %d bytes", len(wrapped))
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := encodeHeader(current.ID, wrapped)
+	out := append(header, nonce...)
+	return gcm.Seal(out, nonce, plaintext, envelopeAAD(associated)), nil
+}
+
+// Decrypt déchiffre un chiffré enveloppe, ou un chiffré hérité avec la clé héritée
+func (k *Keyring) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return k.Open(ctx, ciphertext, nil)
+}
+
+// Open déchiffre un chiffré produit par Seal avec les mêmes données associées.
+// Les chiffrés hérités n'ont pas de données associées.
+func (k *Keyring) Open(ctx context.Context, ciphertext, associated []byte) ([]byte, error) {
+	keyID, wrapped, body, err := decodeHeader(ciphertext)
+	if errors.Is(err, ErrNotEnvelope) {
+		return k.decryptLegacy(ciphertext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := k.unwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	nonce, sealed := body[:gcm.NonceSize()], body[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, envelopeAAD(associated))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// KeyID retourne l'identifiant de la clé maître d'un chiffré ("" pour un chiffré hérité)
+func (k *Keyring) KeyID(ciphertext []byte) (string, error) {
+	keyID, _, _, err := decodeHeader(ciphertext)
+	if errors.Is(err, ErrNotEnvelope) {
+		return "", nil
+	}
+	return keyID, err
+}
+
+// NeedsReencryption indique si un chiffré n'utilise pas la clé maître courante
+func (k *Keyring) NeedsReencryption(ctx context.Context, ciphertext []byte) (bool, error) {
+	keyID, err := k.KeyID(ciphertext)
+	if err != nil {
+		return false, err
+	}
+	current, err := k.CurrentKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	return keyID != current.ID, nil
+}
+
+// Reencrypt met un chiffré à jour avec la clé maître courante. Pour une
+// enveloppe, seule la clé de données est re-wrappée ; un chiffré hérité est
+// entièrement rechiffré. Le booléen indique si le chiffré a changé.
+func (k *Keyring) Reencrypt(ctx context.Context, ciphertext []byte) ([]byte, bool, error) {
+	return k.Reseal(ctx, ciphertext, nil)
+}
+
+// Reseal est Reencrypt pour un chiffré produit par Seal : associated n'est
+// utilisé que pour rechiffrer un chiffré hérité
+func (k *Keyring) Reseal(ctx context.Context, ciphertext, associated []byte) ([]byte, bool, error) {
+	stale, err := k.NeedsReencryption(ctx, ciphertext)
+	if err != nil || !stale {
+		return ciphertext, false, err
+	}
+
+	keyID, wrapped, body, err := decodeHeader(ciphertext)
+	if errors.Is(err, ErrNotEnvelope) {
+		plaintext, err := k.decryptLegacy(ciphertext)
+		if err != nil {
+			return nil, false, err
+		}
+		reencrypted, err := k.Seal(ctx, plaintext, associated)
+		return reencrypted, err == nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	dataKey, err := k.unwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, false, err
+	}
+	current, err := k.provider.CurrentKey(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	rewrapped, err := k.provider.WrapKey(ctx, current.ID, dataKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return append(encodeHeader(current.ID, rewrapped), body...), true, nil
+}
+
+// unwrapKey déchiffre une clé de données ; les clés wrappées par la clé maître
+// dérivée restent lisibles après le passage à un fichier de clés ou à un KMS
+func (k *Keyring) unwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	provider := k.provider
+	if keyID == derivedKeyID && k.derived != nil {
+		provider = k.derived
+	}
+	dataKey, err := provider.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", keyID, err)
+	}
+	return dataKey, nil
+}
+
+// CurrentKey retourne la clé maître utilisée pour les nouveaux chiffrements
+func (k *Keyring) CurrentKey(ctx context.Context) (KeyInfo, error) {
+	return k.provider.CurrentKey(ctx)
+}
+
+// Rotate crée une nouvelle clé maître
+func (k *Keyring) Rotate(ctx context.Context) (KeyInfo, error) {
+	return k.provider.RotateKey(ctx)
+}
+
+// RotateIfOlderThan fait tourner la clé maître courante si elle a dépassé maxAge
+func (k *Keyring) RotateIfOlderThan(ctx context.Context, maxAge time.Duration) (KeyInfo, bool, error) {
+	current, err := k.provider.CurrentKey(ctx)
+	if err != nil {
+		return KeyInfo{}, false, err
+	}
+	if time.Since(current.CreatedAt) < maxAge {
+		return current, false, nil
+	}
+	rotated, err := k.provider.RotateKey(ctx)
+	return rotated, err == nil, err
+}
+
+// decryptLegacy déchiffre le format historique : nonce | données chiffrées
+func (k *Keyring) decryptLegacy(ciphertext []byte) ([]byte, error) {
+	if k.legacyKey == nil {
+		return nil, ErrNotEnvelope
+	}
+	gcm, err := newGCM(k.legacyKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// encodeHeader construit l'en-tête d'enveloppe
+func encodeHeader(keyID string, wrapped []byte) []byte {
+	header := make([]byte, 0, len(envelopeMagic)+4+len(keyID)+len(wrapped))
+	header = append(header, envelopeMagic...)
+	header = append(header, envelopeVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	return append(header, wrapped...)
+}
+
+// decodeHeader lit l'en-tête d'enveloppe et retourne le reste du chiffré
+func decodeHeader(ciphertext []byte) (string, []byte, []byte, error) {
+	if len(ciphertext) < len(envelopeMagic)+2 || !bytes.HasPrefix(ciphertext, []byte(envelopeMagic)) ||
+		ciphertext[len(envelopeMagic)] != envelopeVersion {
+		return "", nil, nil, ErrNotEnvelope
+	}
+
+	rest := ciphertext[len(envelopeMagic)+1:]
+	idLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < idLen+2 {
+		return "", nil, nil, fmt.Errorf("truncated envelope header")
+	}
+	keyID := string(rest[:idLen])
+	rest = rest[idLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return "", nil, nil, fmt.Errorf("truncated envelope header")
+	}
+	return keyID, rest[:wrappedLen], rest[wrappedLen:], nil
+}
+
+// envelopeAAD authentifie le format et les données associées de l'appelant :
+// la clé wrappée n'en fait pas partie pour permettre le re-wrapping, elle est
+// déjà protégée par la clé maître
+func envelopeAAD(associated []byte) []byte {
+	return append([]byte{envelopeMagic[0], envelopeMagic[1], envelopeMagic[2], envelopeVersion}, associated...)
+}
+
+// newGCM crée un AES-GCM pour une clé de 32 octets
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// LocalKeyProvider conserve des clés maîtres AES-256 versionnées en mémoire,
+// et dans un fichier JSON (permissions 0600) si un chemin est fourni. Il sert
+// aussi de bouchon pour un KMS externe dans les tests.
+type LocalKeyProvider struct {
+	path    string
+	fixed   bool // Clé dérivée : pas de rotation
+	mu      sync.RWMutex
+	keys    map[string]localKey
+	current string
+}
+
+// localKey est une version de clé maître
+type localKey struct {
+	KeyInfo
+	Key string `json:"key"` // Hex
+}
+
+// localKeyFile est le contenu du fichier de clés
+type localKeyFile struct {
+	Current string     `json:"current"`
+	Keys    []localKey `json:"keys"`
+}
+
+// NewMemoryKeyProvider crée un fournisseur en mémoire avec une première clé
+func NewMemoryKeyProvider() (*LocalKeyProvider, error) {
+	provider := &LocalKeyProvider{keys: make(map[string]localKey)}
+	if _, err := provider.RotateKey(context.Background()); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// NewDerivedKeyProvider crée un fournisseur dont l'unique clé maître est
+// dérivée (HMAC-SHA256) de secret : la même clé est retrouvée à chaque
+// démarrage sans fichier de clés, mais elle ne peut pas tourner
+func NewDerivedKeyProvider(secret []byte) *LocalKeyProvider {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("email-sender security-manager master key v1"))
+	key := localKey{KeyInfo: KeyInfo{ID: derivedKeyID}, Key: hex.EncodeToString(mac.Sum(nil))}
+	return &LocalKeyProvider{
+		fixed:   true,
+		keys:    map[string]localKey{key.ID: key},
+		current: key.ID,
+	}
+}
+
+// NewFileKeyProvider charge les clés maîtres du fichier path, en le créant avec
+// une première clé s'il n'existe pas
+func NewFileKeyProvider(path string) (*LocalKeyProvider, error) {
+	provider := &LocalKeyProvider{path: path, keys: make(map[string]localKey)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if _, err := provider.RotateKey(context.Background()); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+
+	var file localKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse master key file %s: %w", path, err)
+	}
+	for _, key := range file.Keys {
+		if raw, err := hex.DecodeString(key.Key); err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("master key %s is not a 32-byte hex key", key.ID)
+		}
+		provider.keys[key.ID] = key
+	}
+	if _, exists := provider.keys[file.Current]; !exists {
+		return nil, fmt.Errorf("current master key %q not found in %s", file.Current, path)
+	}
+	provider.current = file.Current
+	return provider, nil
+}
+
+// CurrentKey retourne la clé maître courante
+func (p *LocalKeyProvider) CurrentKey(ctx context.Context) (KeyInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key, exists := p.keys[p.current]
+	if !exists {
+		return KeyInfo{}, ErrUnknownKey
+	}
+	return key.KeyInfo, nil
+}
+
+// RotateKey ajoute une version de clé maître ; les anciennes restent
+// disponibles pour déchiffrer les données existantes
+func (p *LocalKeyProvider) RotateKey(ctx context.Context) (KeyInfo, error) {
+	if p.fixed {
+		return KeyInfo{}, ErrFixedKey
+	}
+
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return KeyInfo{}, fmt.Errorf("failed to generate master key: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := localKey{
+		KeyInfo: KeyInfo{ID: fmt.Sprintf("mk-v%d", p.nextVersion()), CreatedAt: time.Now().UTC()},
+		Key:     hex.EncodeToString(raw),
+	}
+	previous := p.current
+	p.keys[key.ID] = key
+	p.current = key.ID
+
+	if err := p.save(); err != nil {
+		delete(p.keys, key.ID)
+		p.current = previous
+		return KeyInfo{}, err
+	}
+	return key.KeyInfo, nil
+}
+
+// Keys liste les versions de clés maîtres, de la plus ancienne à la plus récente
+func (p *LocalKeyProvider) Keys() []KeyInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	infos := make([]KeyInfo, 0, len(p.keys))
+	for _, key := range p.keys {
+		infos = append(infos, key.KeyInfo)
+	}
+	sort.Slice(infos, func(i, j int) bool { return keyVersion(infos[i].ID) < keyVersion(infos[j].ID) })
+	return infos
+}
+
+// WrapKey chiffre une clé de données avec AES-GCM ; l'identifiant de la clé maître est authentifié
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	gcm, err := p.gcm(keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+// UnwrapKey déchiffre une clé de données
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	gcm, err := p.gcm(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+	return gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], []byte(keyID))
+}
+
+// gcm retourne l'AES-GCM de la clé maître keyID
+func (p *LocalKeyProvider) gcm(keyID string) (cipher.AEAD, error) {
+	p.mu.RLock()
+	key, exists := p.keys[keyID]
+	p.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	raw, err := hex.DecodeString(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key %s: %w", keyID, err)
+	}
+	return newGCM(raw)
+}
+
+// nextVersion retourne le prochain numéro de version (verrou détenu)
+func (p *LocalKeyProvider) nextVersion() int {
+	version := 0
+	for id := range p.keys {
+		if v := keyVersion(id); v > version {
+			version = v
+		}
+	}
+	return version + 1
+}
+
+// save écrit le fichier de clés de manière atomique (verrou détenu)
+func (p *LocalKeyProvider) save() error {
+	if p.path == "" {
+		return nil
+	}
+
+	file := localKeyFile{Current: p.current}
+	for _, key := range p.keys {
+		file.Keys = append(file.Keys, key)
+	}
+	sort.Slice(file.Keys, func(i, j int) bool { return keyVersion(file.Keys[i].ID) < keyVersion(file.Keys[j].ID) })
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize master keys: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return fmt.Errorf("failed to create master key directory: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write master key file: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
+
+// keyVersion extrait le numéro de version d'un identifiant "mk-vN"
+func keyVersion(id string) int {
+	version, err := strconv.Atoi(strings.TrimPrefix(id, "mk-v"))
+	if err != nil {
+		return 0
+	}
+	return version
+}
@@ -0,0 +1,210 @@
+package security
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewMemoryKeyProvider()
+	require.NoError(t, err)
+	keyring := NewKeyring(provider, nil)
+
+	plaintext := []byte("données confidentielles")
+	ciphertext, err := keyring.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	keyID, err := keyring.KeyID(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "mk-v1", keyID)
+
+	decrypted, err := keyring.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Un chiffré altéré est rejeté
+	ciphertext[len(ciphertext)-1] ^= 0xFF
+	_, err = keyring.Decrypt(ctx, ciphertext)
+	assert.Error(t, err)
+}
+
+func TestKeyring_RotationAndReencryption(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewMemoryKeyProvider()
+	require.NoError(t, err)
+	keyring := NewKeyring(provider, nil)
+
+	plaintext := []byte("secret")
+	old, err := keyring.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+
+	rotated, err := keyring.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "mk-v2", rotated.ID)
+
+	// Les anciens chiffrés restent lisibles après rotation
+	decrypted, err := keyring.Decrypt(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	stale, err := keyring.NeedsReencryption(ctx, old)
+	require.NoError(t, err)
+	assert.True(t, stale)
+
+	// La ré-encryption ne fait que re-wrapper la clé de données
+	updated, changed, err := keyring.Reencrypt(ctx, old)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	keyID, _ := keyring.KeyID(updated)
+	assert.Equal(t, "mk-v2", keyID)
+	assert.Equal(t, old[len(old)-len(plaintext)-16:], updated[len(updated)-len(plaintext)-16:])
+
+	decrypted, err = keyring.Decrypt(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Un chiffré à jour n'est pas modifié
+	same, changed, err := keyring.Reencrypt(ctx, updated)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, updated, same)
+
+	// Une clé maître inconnue est signalée
+	other, err := NewMemoryKeyProvider()
+	require.NoError(t, err)
+	_, err = NewKeyring(other, nil).Decrypt(ctx, updated)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyring_LegacyCiphertext(t *testing.T) {
+	ctx := context.Background()
+	legacyKey := make([]byte, 32)
+	_, err := rand.Read(legacyKey)
+	require.NoError(t, err)
+
+	// Format historique : nonce | données chiffrées avec la clé statique
+	block, err := aes.NewCipher(legacyKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	legacy := gcm.Seal(nonce, nonce, []byte("ancien secret"), nil)
+
+	provider, err := NewMemoryKeyProvider()
+	require.NoError(t, err)
+	keyring := NewKeyring(provider, legacyKey)
+
+	decrypted, err := keyring.Decrypt(ctx, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ancien secret"), decrypted)
+
+	updated, changed, err := keyring.Reencrypt(ctx, legacy)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	keyID, _ := keyring.KeyID(updated)
+	assert.Equal(t, "mk-v1", keyID)
+
+	decrypted, err = keyring.Decrypt(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ancien secret"), decrypted)
+
+	// Sans clé héritée, un chiffré sans en-tête est refusé
+	_, err = NewKeyring(provider, nil).Decrypt(ctx, legacy)
+	assert.ErrorIs(t, err, ErrNotEnvelope)
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys", "master.json")
+
+	provider, err := NewFileKeyProvider(path)
+	require.NoError(t, err)
+	ciphertext, err := NewKeyring(provider, nil).Encrypt(ctx, []byte("persisté"))
+	require.NoError(t, err)
+	_, err = provider.RotateKey(ctx)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Les clés et la version courante survivent au rechargement
+	reloaded, err := NewFileKeyProvider(path)
+	require.NoError(t, err)
+	current, err := reloaded.CurrentKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "mk-v2", current.ID)
+	assert.Len(t, reloaded.Keys(), 2)
+
+	decrypted, err := NewKeyring(reloaded, nil).Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("persisté"), decrypted)
+}
+
+func TestDerivedKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	legacyKey := make([]byte, 32)
+	_, err := rand.Read(legacyKey)
+	require.NoError(t, err)
+
+	// La même clé statique redonne la même clé maître à chaque démarrage
+	ciphertext, err := NewKeyring(NewDerivedKeyProvider(legacyKey), legacyKey).Encrypt(ctx, []byte("dérivé"))
+	require.NoError(t, err)
+	keyID, err := NewKeyring(NewDerivedKeyProvider(legacyKey), legacyKey).KeyID(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "mk-derived", keyID)
+
+	decrypted, err := NewKeyring(NewDerivedKeyProvider(legacyKey), legacyKey).Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("dérivé"), decrypted)
+
+	_, err = NewDerivedKeyProvider(legacyKey).RotateKey(ctx)
+	assert.ErrorIs(t, err, ErrFixedKey)
+
+	// Après le passage à un fichier de clés, les enveloppes dérivées restent
+	// lisibles et sont re-wrappées avec la clé maître du fichier
+	provider, err := NewFileKeyProvider(filepath.Join(t.TempDir(), "master.json"))
+	require.NoError(t, err)
+	keyring := NewKeyring(provider, legacyKey)
+	decrypted, err = keyring.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("dérivé"), decrypted)
+
+	updated, changed, err := keyring.Reencrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	keyID, _ = keyring.KeyID(updated)
+	assert.Equal(t, "mk-v1", keyID)
+
+	// Sans la clé statique, la clé dérivée est inconnue
+	_, err = NewKeyring(provider, nil).Decrypt(ctx, ciphertext)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyring_RotateIfOlderThan(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewMemoryKeyProvider()
+	require.NoError(t, err)
+	keyring := NewKeyring(provider, nil)
+
+	_, rotated, err := keyring.RotateIfOlderThan(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+
+	key, rotated, err := keyring.RotateIfOlderThan(ctx, 0)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	assert.Equal(t, "mk-v2", key.ID)
+}
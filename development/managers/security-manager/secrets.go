@@ -0,0 +1,421 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// vaultFormatVersion est la version du fichier du coffre. Depuis la version 2,
+// chaque valeur est liée au nom de son secret (données associées AEAD) : une
+// valeur copiée sous un autre nom ne se déchiffre plus.
+const vaultFormatVersion = 2
+
+// SecurityManagerPrincipal est l'identité du Security Manager lui-même ; elle a
+// accès à tous les secrets sans politique
+const SecurityManagerPrincipal = "security-manager"
+
+var (
+	// ErrSecretNotFound est retourné pour un secret absent du coffre
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrSecretAccessDenied est retourné quand aucune politique n'autorise l'accès
+	ErrSecretAccessDenied = errors.New("secret access denied")
+
+	secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-/]+$`)
+)
+
+// SecretPolicy définit les secrets accessibles à un manager. Les motifs
+// suivent path.Match : "smtp/*" donne accès à "smtp/password" et "smtp/user".
+type SecretPolicy struct {
+	Manager string   `json:"manager" yaml:"manager"` // "*" s'applique à tous les managers
+	Read    []string `json:"read" yaml:"read"`
+	Write   []string `json:"write" yaml:"write"`
+}
+
+// SecretInfo décrit un secret sans sa valeur
+type SecretInfo struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	KeyID     string    `json:"key_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+// vaultSecret est un secret tel que stocké dans le fichier du coffre
+type vaultSecret struct {
+	Value     []byte    `json:"value"` // Chiffré enveloppe du keyring
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+// vaultFile est le contenu du fichier du coffre
+type vaultFile struct {
+	Version int                     `json:"version"`
+	Secrets map[string]*vaultSecret `json:"secrets"`
+}
+
+// SecretVault est un coffre de secrets local : chaque valeur est chiffrée par
+// enveloppe avec le keyring, l'accès est filtré par des politiques par manager
+// et chaque lecture, écriture ou refus passe par l'AuditLogger.
+type SecretVault struct {
+	path     string
+	keyring  *Keyring
+	auditLog *AuditLogger
+
+	mu       sync.RWMutex
+	secrets  map[string]*vaultSecret
+	policies map[string]SecretPolicy
+}
+
+// NewSecretVault ouvre le coffre du fichier path ; un fichier absent donne un coffre vide
+func NewSecretVault(path string, keyring *Keyring, auditLog *AuditLogger, policies []SecretPolicy) (*SecretVault, error) {
+	if auditLog == nil {
+		auditLog = &AuditLogger{enabled: false}
+	}
+
+	vault := &SecretVault{
+		path:     path,
+		keyring:  keyring,
+		auditLog: auditLog,
+		secrets:  make(map[string]*vaultSecret),
+		policies: make(map[string]SecretPolicy),
+	}
+	for _, policy := range policies {
+		vault.SetPolicy(policy)
+	}
+
+	if err := vault.Load(); err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
+
+// Load relit le fichier du coffre
+func (v *SecretVault) Load() error {
+	data, err := os.ReadFile(v.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secrets vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse secrets vault %s: %w", v.path, err)
+	}
+	if file.Secrets == nil {
+		file.Secrets = make(map[string]*vaultSecret)
+	}
+
+	migrate := file.Version < vaultFormatVersion && len(file.Secrets) > 0
+	if migrate {
+		if err := v.bindNames(context.Background(), file.Secrets); err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets = file.Secrets
+	if migrate {
+		return v.save()
+	}
+	return nil
+}
+
+// bindNames rechiffre les secrets d'un coffre antérieur à la version 2 avec
+// leur nom en données associées
+func (v *SecretVault) bindNames(ctx context.Context, secrets map[string]*vaultSecret) error {
+	for name, secret := range secrets {
+		plaintext, err := v.keyring.Decrypt(ctx, secret.Value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+		}
+		ciphertext, err := v.keyring.Seal(ctx, plaintext, []byte(name))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+		}
+		secret.Value = ciphertext
+	}
+	return nil
+}
+
+// SetPolicy définit ou remplace la politique d'un manager
+func (v *SecretVault) SetPolicy(policy SecretPolicy) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.policies[policy.Manager] = policy
+}
+
+// Get retourne la valeur d'un secret. Un secret chiffré avec une ancienne clé
+// maître est re-wrappé avec la clé courante à cette occasion.
+func (v *SecretVault) Get(ctx context.Context, manager, name string) (string, error) {
+	if !v.allowed(manager, name, false) {
+		return "", v.deny(manager, name, "read")
+	}
+
+	v.mu.RLock()
+	secret, exists := v.secrets[name]
+	var ciphertext []byte
+	var version int
+	if exists {
+		ciphertext, version = secret.Value, secret.Version
+	}
+	v.mu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+
+	plaintext, err := v.keyring.Open(ctx, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+
+	v.reencrypt(ctx, name, ciphertext)
+
+	v.auditLog.LogEvent("SECRETS", "SECRET_READ", "Secret read", map[string]interface{}{
+		"manager": manager,
+		"secret":  name,
+		"version": version,
+	})
+	return string(plaintext), nil
+}
+
+// Put crée ou met à jour un secret
+func (v *SecretVault) Put(ctx context.Context, manager, name, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	if !v.allowed(manager, name, true) {
+		return v.deny(manager, name, "write")
+	}
+
+	ciphertext, err := v.keyring.Seal(ctx, []byte(value), []byte(name))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now().UTC()
+	previous, exists := v.secrets[name]
+	secret := &vaultSecret{Value: ciphertext, Version: 1, CreatedAt: now, UpdatedAt: now, UpdatedBy: manager}
+	if exists {
+		secret.Version = previous.Version + 1
+		secret.CreatedAt = previous.CreatedAt
+	}
+
+	v.secrets[name] = secret
+	if err := v.save(); err != nil {
+		if exists {
+			v.secrets[name] = previous
+		} else {
+			delete(v.secrets, name)
+		}
+		return err
+	}
+
+	v.auditLog.LogEvent("SECRETS", "SECRET_WRITTEN", "Secret written", map[string]interface{}{
+		"manager": manager,
+		"secret":  name,
+		"version": secret.Version,
+	})
+	return nil
+}
+
+// Delete supprime un secret
+func (v *SecretVault) Delete(ctx context.Context, manager, name string) error {
+	if !v.allowed(manager, name, true) {
+		return v.deny(manager, name, "delete")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	previous, exists := v.secrets[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	delete(v.secrets, name)
+	if err := v.save(); err != nil {
+		v.secrets[name] = previous
+		return err
+	}
+
+	v.auditLog.LogEvent("SECRETS", "SECRET_DELETED", "Secret deleted", map[string]interface{}{
+		"manager": manager,
+		"secret":  name,
+	})
+	return nil
+}
+
+// List retourne les métadonnées des secrets lisibles par un manager
+func (v *SecretVault) List(manager string) []SecretInfo {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var infos []SecretInfo
+	for name, secret := range v.secrets {
+		if !v.allowedLocked(manager, name, false) {
+			continue
+		}
+		keyID, _ := v.keyring.KeyID(secret.Value)
+		infos = append(infos, SecretInfo{
+			Name:      name,
+			Version:   secret.Version,
+			KeyID:     keyID,
+			CreatedAt: secret.CreatedAt,
+			UpdatedAt: secret.UpdatedAt,
+			UpdatedBy: secret.UpdatedBy,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// ReencryptAll re-wrappe tous les secrets avec la clé maître courante, par
+// exemple juste après une rotation ; retourne le nombre de secrets mis à jour
+func (v *SecretVault) ReencryptAll(ctx context.Context) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	updated := make(map[string][]byte)
+	for name, secret := range v.secrets {
+		ciphertext, changed, err := v.keyring.Reseal(ctx, secret.Value, []byte(name))
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt secret %s: %w", name, err)
+		}
+		if changed {
+			updated[name] = ciphertext
+		}
+	}
+	if len(updated) == 0 {
+		return 0, nil
+	}
+
+	previous := make(map[string][]byte, len(updated))
+	for name, ciphertext := range updated {
+		previous[name] = v.secrets[name].Value
+		v.secrets[name].Value = ciphertext
+	}
+	if err := v.save(); err != nil {
+		for name, ciphertext := range previous {
+			v.secrets[name].Value = ciphertext
+		}
+		return 0, err
+	}
+
+	v.auditLog.LogEvent("SECRETS", "SECRETS_REENCRYPTED", "Secrets re-encrypted with current master key", map[string]interface{}{
+		"count": len(updated),
+	})
+	return len(updated), nil
+}
+
+// For retourne l'accès aux secrets d'un manager, utilisable comme résolveur
+// des références ${secret:name} du Config Manager
+func (v *SecretVault) For(manager string) *ManagerSecrets {
+	return &ManagerSecrets{vault: v, manager: manager}
+}
+
+// ManagerSecrets donne accès au coffre avec l'identité d'un manager
+type ManagerSecrets struct {
+	vault   *SecretVault
+	manager string
+}
+
+// ResolveSecret retourne la valeur d'un secret autorisé pour le manager
+func (m *ManagerSecrets) ResolveSecret(name string) (string, error) {
+	return m.vault.Get(context.Background(), m.manager, name)
+}
+
+// reencrypt re-wrappe paresseusement un secret lu ; un échec est sans effet
+// sur la lecture, le secret reste lisible avec son ancienne clé maître
+func (v *SecretVault) reencrypt(ctx context.Context, name string, ciphertext []byte) {
+	updated, changed, err := v.keyring.Reseal(ctx, ciphertext, []byte(name))
+	if err != nil || !changed {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	current, exists := v.secrets[name]
+	if !exists || !bytes.Equal(current.Value, ciphertext) {
+		return // Modifié entre-temps
+	}
+	current.Value = updated
+	if err := v.save(); err != nil {
+		current.Value = ciphertext
+	}
+}
+
+// allowed vérifie les politiques d'un manager pour un secret
+func (v *SecretVault) allowed(manager, name string, write bool) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.allowedLocked(manager, name, write)
+}
+
+// allowedLocked vérifie les politiques (verrou détenu)
+func (v *SecretVault) allowedLocked(manager, name string, write bool) bool {
+	if manager == SecurityManagerPrincipal {
+		return true
+	}
+
+	for _, key := range []string{manager, "*"} {
+		policy, exists := v.policies[key]
+		if !exists {
+			continue
+		}
+		patterns := policy.Read
+		if write {
+			patterns = policy.Write
+		}
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deny journalise un accès refusé et retourne l'erreur correspondante
+func (v *SecretVault) deny(manager, name, operation string) error {
+	v.auditLog.LogEvent("SECRETS", "SECRET_ACCESS_DENIED", "Secret access denied", map[string]interface{}{
+		"manager":   manager,
+		"secret":    name,
+		"operation": operation,
+	})
+	return fmt.Errorf("%w: %s cannot %s %s", ErrSecretAccessDenied, manager, operation, name)
+}
+
+// save écrit le coffre de manière atomique (verrou détenu)
+func (v *SecretVault) save() error {
+	data, err := json.MarshalIndent(vaultFile{Version: vaultFormatVersion, Secrets: v.secrets}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize secrets vault: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets vault directory: %w", err)
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets vault: %w", err)
+	}
+	return os.Rename(tmp, v.path)
+}
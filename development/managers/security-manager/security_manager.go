@@ -2,20 +2,18 @@ package security
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"io"
 	"log"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 
+	"email_sender/development/managers/security-manager/auth"
 	"github.com/email-sender-manager/interfaces"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -28,7 +26,10 @@ type SecurityManagerImpl struct {
 	config          *Config
 	logger          *zap.Logger
 	auditLog        *AuditLogger
-	encryptionKey   []byte
+	keyring         *Keyring
+	keyRotationStop chan struct{}
+	keyRotationOnce sync.Once
+	secrets         *SecretVault
 	rateLimiters    map[string]*rate.Limiter
 	rateLimitersMu  sync.RWMutex
 	isInitialized   bool
@@ -56,6 +57,20 @@ type Config struct {
 	ScanInterval       time.Duration `json:"scan_interval"`
 	VulnDBPath         string        `json:"vuln_db_path"`
 	HashCost           int           `json:"hash_cost"`
+
+	// Gestion des clés : sans MasterKeyFile, la clé maître est dérivée de
+	// EncryptionKey (sans rotation), ou reste en mémoire sans EncryptionKey
+	MasterKeyFile       string            `json:"master_key_file"`
+	KeyRotationInterval time.Duration     `json:"key_rotation_interval"` // 0 désactive la rotation planifiée
+	KeyProvider         MasterKeyProvider `json:"-"`                     // KMS externe, prioritaire sur MasterKeyFile
+
+	// OnReencrypt reçoit les données relues par DecryptData avec une ancienne
+	// clé maître et leur version re-wrappée, pour que l'appelant la persiste
+	OnReencrypt func(previous, reencrypted []byte) `json:"-"`
+
+	// Coffre de secrets : désactivé sans SecretsVaultPath
+	SecretsVaultPath string         `json:"secrets_vault_path"`
+	SecretPolicies   []SecretPolicy `json:"secret_policies"`
 }
 
 // AuditLogger gère les logs d'audit
@@ -85,7 +100,7 @@ func NewSecurityManager(config *Config) (*SecurityManagerImpl, error) {
 	}
 
 	// Générer ou utiliser la clé de chiffrement
-	encryptionKey, err := getOrGenerateEncryptionKey(config.EncryptionKey)
+	keyring, err := newKeyringFromConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup encryption key: %w", err)
 	}
@@ -94,7 +109,7 @@ func NewSecurityManager(config *Config) (*SecurityManagerImpl, error) {
 		config:          config,
 		logger:          logger,
 		auditLog:        auditLogger,
-		encryptionKey:   encryptionKey,
+		keyring:         keyring,
 		rateLimiters:    make(map[string]*rate.Limiter),
 		rateLimitersMu:  sync.RWMutex{},
 		isInitialized:   true,
@@ -102,6 +117,25 @@ func NewSecurityManager(config *Config) (*SecurityManagerImpl, error) {
 		scanResultsMu:   sync.RWMutex{},
 	}
 
+	if config.SecretsVaultPath != "" {
+		// Des clés maîtres en mémoire rendraient le coffre illisible au redémarrage
+		if config.MasterKeyFile == "" && config.KeyProvider == nil && config.EncryptionKey == "" {
+			return nil, fmt.Errorf("secrets vault requires a master key file, a key provider or an encryption key")
+		}
+		sm.secrets, err = NewSecretVault(config.SecretsVaultPath, keyring, auditLogger, config.SecretPolicies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets vault: %w", err)
+		}
+	}
+
+	if config.KeyRotationInterval > 0 {
+		if config.MasterKeyFile == "" && config.KeyProvider == nil && config.EncryptionKey != "" {
+			return nil, fmt.Errorf("invalid key_rotation_interval: %w", ErrFixedKey)
+		}
+		sm.keyRotationStop = make(chan struct{})
+		go sm.runKeyRotation(config.KeyRotationInterval)
+	}
+
 	sm.logger.Info("Security Manager initialized successfully")
 	sm.auditLog.LogEvent("SYSTEM", "SECURITY_MANAGER_INITIALIZED", "Security Manager started", nil)
 
@@ -165,6 +199,39 @@ func getOrGenerateEncryptionKey(keyStr string) ([]byte, error) {
 	return key, nil
 }
 
+// newKeyringFromConfig construit le keyring : le fournisseur de clés maîtres
+// configuré (à défaut, la clé dérivée de EncryptionKey), et l'ancienne clé
+// statique pour relire les données existantes
+func newKeyringFromConfig(config *Config) (*Keyring, error) {
+	var legacyKey []byte
+	if config.EncryptionKey != "" {
+		key, err := getOrGenerateEncryptionKey(config.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		legacyKey = key
+	}
+
+	provider := config.KeyProvider
+	if provider == nil {
+		var err error
+		if config.MasterKeyFile != "" {
+			provider, err = NewFileKeyProvider(config.MasterKeyFile)
+		} else if legacyKey != nil {
+			// Une clé maître en mémoire rendrait illisibles au redémarrage les
+			// données chiffrées (ou re-chiffrées) après la mise à jour
+			provider = NewDerivedKeyProvider(legacyKey)
+		} else {
+			provider, err = NewMemoryKeyProvider()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewKeyring(provider, legacyKey), nil
+}
+
 // Interface compliance methods
 
 // ValidateInput valide une entrée utilisateur
@@ -287,71 +354,184 @@ func (sm *SecurityManagerImpl) SanitizeInput(input string, options interfaces.Sa
 	return sanitized
 }
 
-// EncryptData chiffre des données
+// EncryptData chiffre des données par enveloppe : une clé de données par
+// enregistrement, wrappée par la clé maître courante dont l'ID est dans l'en-tête
 func (sm *SecurityManagerImpl) EncryptData(data []byte) ([]byte, error) {
 	if !sm.isInitialized {
 		return nil, fmt.Errorf("security manager not initialized")
 	}
 
-	block, err := aes.NewCipher(sm.encryptionKey)
+	ciphertext, err := sm.keyring.Encrypt(context.Background(), data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return nil, err
 	}
 
-	// Créer un GCM
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
-	}
-
-	// Générer un nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
-	}
-
-	// Chiffrer les données
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-
+	keyID, _ := sm.keyring.KeyID(ciphertext)
 	sm.auditLog.LogEvent("ENCRYPTION", "DATA_ENCRYPTED", "Data encrypted", map[string]interface{}{
 		"data_size": len(data),
+		"key_id":    keyID,
 	})
 
 	return ciphertext, nil
 }
 
-// DecryptData déchiffre des données
+// DecryptData déchiffre des données, quelle que soit la version de clé maître
+// utilisée, ainsi que les données chiffrées avant l'introduction des enveloppes.
+// Les données chiffrées avec une ancienne clé sont re-wrappées au passage et
+// transmises à Config.OnReencrypt.
 func (sm *SecurityManagerImpl) DecryptData(encryptedData []byte) ([]byte, error) {
 	if !sm.isInitialized {
 		return nil, fmt.Errorf("security manager not initialized")
 	}
 
-	block, err := aes.NewCipher(sm.encryptionKey)
+	plaintext, err := sm.keyring.Decrypt(context.Background(), encryptedData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	keyID, _ := sm.keyring.KeyID(encryptedData)
+	sm.auditLog.LogEvent("DECRYPTION", "DATA_DECRYPTED", "Data decrypted", map[string]interface{}{
+		"data_size": len(plaintext),
+		"key_id":    keyID,
+	})
+
+	if sm.config.OnReencrypt != nil {
+		sm.reencryptLazily(encryptedData)
+	}
+
+	return plaintext, nil
+}
+
+// reencryptLazily re-wrappe des données relues avec une ancienne clé maître.
+// Un échec n'empêche pas la lecture : la donnée sera re-wrappée plus tard.
+func (sm *SecurityManagerImpl) reencryptLazily(encryptedData []byte) {
+	reencrypted, changed, err := sm.ReencryptData(encryptedData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+		sm.logger.Warn("Lazy re-encryption failed", zap.Error(err))
+		return
+	}
+	if changed {
+		sm.config.OnReencrypt(encryptedData, reencrypted)
 	}
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(encryptedData) < nonceSize {
-		return nil, fmt.Errorf("encrypted data is too short")
+// ReencryptData met à jour des données chiffrées avec la clé maître courante.
+// À appeler paresseusement, par exemple quand une donnée est relue : les
+// données déjà à jour sont retournées telles quelles.
+func (sm *SecurityManagerImpl) ReencryptData(encryptedData []byte) ([]byte, bool, error) {
+	if !sm.isInitialized {
+		return nil, false, fmt.Errorf("security manager not initialized")
 	}
 
-	nonce, ciphertext := encryptedData[:nonceSize], encryptedData[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	previousKeyID, _ := sm.keyring.KeyID(encryptedData)
+	reencrypted, changed, err := sm.keyring.Reencrypt(context.Background(), encryptedData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+		return nil, false, fmt.Errorf("failed to re-encrypt data: %w", err)
 	}
 
-	sm.auditLog.LogEvent("DECRYPTION", "DATA_DECRYPTED", "Data decrypted", map[string]interface{}{
-		"data_size": len(plaintext),
+	if changed {
+		keyID, _ := sm.keyring.KeyID(reencrypted)
+		sm.auditLog.LogEvent("ENCRYPTION", "DATA_REENCRYPTED", "Data re-encrypted with current master key", map[string]interface{}{
+			"previous_key_id": previousKeyID,
+			"key_id":          keyID,
+		})
+	}
+
+	return reencrypted, changed, nil
+}
+
+// RotateEncryptionKey crée une nouvelle version de clé maître. Les données
+// existantes restent lisibles et sont re-wrappées via ReencryptData.
+func (sm *SecurityManagerImpl) RotateEncryptionKey(ctx context.Context) (KeyInfo, error) {
+	if !sm.isInitialized {
+		return KeyInfo{}, fmt.Errorf("security manager not initialized")
+	}
+
+	key, err := sm.keyring.Rotate(ctx)
+	if err != nil {
+		return KeyInfo{}, fmt.Errorf("failed to rotate master key: %w", err)
+	}
+
+	sm.logger.Info("Master key rotated", zap.String("key_id", key.ID))
+	sm.auditLog.LogEvent("ENCRYPTION", "MASTER_KEY_ROTATED", "Master key rotated", map[string]interface{}{
+		"key_id": key.ID,
 	})
 
-	return plaintext, nil
+	return key, nil
+}
+
+// CurrentEncryptionKey retourne la version de clé maître utilisée pour chiffrer
+func (sm *SecurityManagerImpl) CurrentEncryptionKey(ctx context.Context) (KeyInfo, error) {
+	return sm.keyring.CurrentKey(ctx)
+}
+
+// runKeyRotation fait tourner la clé maître dès qu'elle dépasse KeyRotationInterval
+func (sm *SecurityManagerImpl) runKeyRotation(interval time.Duration) {
+	// Vérifier plus souvent que l'intervalle pour tenir compte de l'âge de la clé au démarrage
+	check := interval / 10
+	if check < time.Second {
+		check = time.Second
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sm.keyRotationStop:
+			return
+		case <-ticker.C:
+			key, rotated, err := sm.keyring.RotateIfOlderThan(context.Background(), interval)
+			if err != nil {
+				sm.logger.Error("Scheduled master key rotation failed", zap.Error(err))
+				continue
+			}
+			if rotated {
+				sm.logger.Info("Master key rotated", zap.String("key_id", key.ID))
+				sm.auditLog.LogEvent("ENCRYPTION", "MASTER_KEY_ROTATED", "Scheduled master key rotation", map[string]interface{}{
+					"key_id": key.ID,
+				})
+			}
+		}
+	}
+}
+
+// StopKeyRotation arrête la rotation planifiée de la clé maître
+func (sm *SecurityManagerImpl) StopKeyRotation() {
+	sm.keyRotationOnce.Do(func() {
+		if sm.keyRotationStop != nil {
+			close(sm.keyRotationStop)
+		}
+	})
+}
+
+// Cleanup arrête la rotation planifiée et vide les logs
+func (sm *SecurityManagerImpl) Cleanup() error {
+	sm.StopKeyRotation()
+	sm.auditLog.LogEvent("SYSTEM", "SECURITY_MANAGER_STOPPED", "Security Manager stopped", nil)
+	sm.auditLog.Sync()
+	sm.logger.Sync()
+	return nil
+}
+
+// SecretsVault retourne le coffre de secrets, nil s'il n'est pas configuré
+func (sm *SecurityManagerImpl) SecretsVault() *SecretVault {
+	return sm.secrets
+}
+
+// LoadSecrets relit le fichier du coffre de secrets
+func (sm *SecurityManagerImpl) LoadSecrets(ctx context.Context) error {
+	if sm.secrets == nil {
+		return fmt.Errorf("secrets vault not configured")
+	}
+	return sm.secrets.Load()
+}
+
+// GetSecret retourne un secret avec l'identité du Security Manager
+func (sm *SecurityManagerImpl) GetSecret(key string) (string, error) {
+	if sm.secrets == nil {
+		return "", fmt.Errorf("secrets vault not configured")
+	}
+	return sm.secrets.Get(context.Background(), SecurityManagerPrincipal, key)
 }
 
 // HashPassword hash un mot de passe
@@ -427,6 +607,18 @@ func (sm *SecurityManagerImpl) CheckRateLimit(identifier string, limit int) bool
 	return allowed
 }
 
+// AuthMiddleware crée le middleware d'authentification des API HTTP : la
+// limite de débit par principal passe par CheckRateLimit et chaque requête
+// refusée est journalisée dans l'audit du Security Manager
+func (sm *SecurityManagerImpl) AuthMiddleware(opts auth.Options) *auth.Middleware {
+	opts.RateLimiter = sm
+	opts.Audit = sm.auditLog
+	if opts.DefaultRateLimit == 0 {
+		opts.DefaultRateLimit = sm.config.DefaultRateLimit
+	}
+	return auth.NewMiddleware(opts)
+}
+
 // ScanForVulnerabilities scanne les vulnérabilités
 func (sm *SecurityManagerImpl) ScanForVulnerabilities(ctx context.Context, target string) (*interfaces.SecurityScanResult, error) {
 	if !sm.isInitialized {
@@ -540,3 +732,15 @@ func (al *AuditLogger) LogSecurityEvent(event interfaces.SecurityEvent) error {
 
 	return nil
 }
+
+// Sync vide les événements d'audit en attente
+func (al *AuditLogger) Sync() error {
+	if !al.enabled {
+		return nil
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	return al.logger.Sync()
+}
@@ -216,6 +216,83 @@ func TestSecurityManager_Encryption(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSecurityManager_KeyRotation(t *testing.T) {
+	config := getDefaultConfig()
+	config.AuditLogEnabled = false
+	config.MasterKeyFile = filepath.Join(t.TempDir(), "master-keys.json")
+
+	var lazilyReencrypted []byte
+	config.OnReencrypt = func(previous, reencrypted []byte) {
+		lazilyReencrypted = reencrypted
+	}
+
+	sm, err := NewSecurityManager(config)
+	require.NoError(t, err)
+	defer sm.Cleanup()
+
+	encryptedData, err := sm.EncryptData([]byte("rotation"))
+	require.NoError(t, err)
+
+	key, err := sm.RotateEncryptionKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "mk-v2", key.ID)
+
+	// Les données chiffrées avant la rotation restent lisibles
+	decryptedData, err := sm.DecryptData(encryptedData)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rotation"), decryptedData)
+
+	// La lecture re-wrappe les données avec la nouvelle clé
+	require.NotNil(t, lazilyReencrypted)
+	keyID, err := sm.keyring.KeyID(lazilyReencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "mk-v2", keyID)
+
+	// Les données à jour ne sont pas re-wrappées à nouveau
+	lazilyReencrypted = nil
+	current, err := sm.EncryptData([]byte("current"))
+	require.NoError(t, err)
+	_, err = sm.DecryptData(current)
+	require.NoError(t, err)
+	assert.Nil(t, lazilyReencrypted)
+
+	reencrypted, changed, err := sm.ReencryptData(encryptedData)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// Un nouveau manager relit les clés maîtres depuis le fichier
+	reloaded, err := NewSecurityManager(config)
+	require.NoError(t, err)
+	decryptedData, err = reloaded.DecryptData(reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rotation"), decryptedData)
+}
+
+func TestSecurityManager_EncryptionKeyOnly(t *testing.T) {
+	config := getDefaultConfig()
+	config.AuditLogEnabled = false
+	config.EncryptionKey = strings.Repeat("ab", 32)
+
+	sm, err := NewSecurityManager(config)
+	require.NoError(t, err)
+	encryptedData, err := sm.EncryptData([]byte("redémarrage"))
+	require.NoError(t, err)
+	require.NoError(t, sm.Cleanup())
+
+	// Sans fichier de clés, la clé maître dérivée de encryption_key survit au redémarrage
+	restarted, err := NewSecurityManager(config)
+	require.NoError(t, err)
+	defer restarted.Cleanup()
+	decryptedData, err := restarted.DecryptData(encryptedData)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("redémarrage"), decryptedData)
+
+	// La clé dérivée ne tourne pas : la rotation planifiée est refusée
+	config.KeyRotationInterval = time.Hour
+	_, err = NewSecurityManager(config)
+	assert.ErrorIs(t, err, ErrFixedKey)
+}
+
 func TestSecurityManager_PasswordHashing(t *testing.T) {
 	sm, err := NewSecurityManager(&Config{HashCost: 4}) // Réduire pour les tests
 	require.NoError(t, err)
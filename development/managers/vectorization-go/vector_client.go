@@ -6,14 +6,16 @@ import (
 	"sync"
 	"time"
 
+	"email_sender/src/qdrant"
+
 	"go.uber.org/zap"
 )
 
 // VectorClient représente le client de vectorisation unifié
 type VectorClient struct {
-	logger *zap.Logger
-	config VectorConfig
-	// client *qdrant.Client // Sera ajouté une fois les dépendances Qdrant disponibles
+	logger  *zap.Logger
+	config  VectorConfig
+	backend qdrant.QdrantInterface // Client HTTP ou store embarqué, nil en mode simulation
 }
 
 // VectorConfig contient la configuration du client vectoriel
@@ -42,6 +44,27 @@ type SearchResult struct {
 	QueryIndex int     `json:"query_index"`
 }
 
+// SearchOptions affine une recherche vectorielle
+type SearchOptions struct {
+	TopK           int            `json:"top_k"`
+	Offset         int            `json:"offset,omitempty"`
+	Filter         *qdrant.Filter `json:"filter,omitempty"`          // Filtre must/should/must_not sur les métadonnées
+	ScoreThreshold *float32       `json:"score_threshold,omitempty"` // Score minimal des résultats
+}
+
+// HybridSearchOptions configure une recherche hybride lexicale + vectorielle
+type HybridSearchOptions struct {
+	TopK       int            `json:"top_k"`
+	TextFields []string       `json:"text_fields"`
+	Filter     *qdrant.Filter `json:"filter,omitempty"`
+}
+
+// ScrollPage représente une page de vecteurs et l'offset de la suivante (nil en fin de collection)
+type ScrollPage struct {
+	Vectors    []Vector    `json:"vectors"`
+	NextOffset interface{} `json:"next_offset"`
+}
+
 // CollectionInfo représente les informations d'une collection
 type CollectionInfo struct {
 	Name        string `json:"name"`
@@ -61,19 +84,10 @@ func NewVectorClient(config VectorConfig, logger *zap.Logger) (*VectorClient, er
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	// TODO: Initialiser le client Qdrant une fois les dépendances disponibles
-	// client, err := qdrant.NewClient(&qdrant.Config{
-	//     Host: config.Host,
-	//     Port: config.Port,
-	// })
-	// if err != nil {
-	//     return nil, err
-	// }
-
+	// Sans backend le client fonctionne en simulation, voir NewVectorClientWithBackend
 	vc := &VectorClient{
 		logger: logger,
 		config: config,
-		// client: client,
 	}
 
 	logger.Info("VectorClient créé avec succès",
@@ -84,22 +98,32 @@ func NewVectorClient(config VectorConfig, logger *zap.Logger) (*VectorClient, er
 	return vc, nil
 }
 
+// NewVectorClientWithBackend crée un client vectoriel adossé à un client Qdrant
+// (serveur HTTP ou store embarqué) au lieu de la simulation
+func NewVectorClientWithBackend(config VectorConfig, backend qdrant.QdrantInterface, logger *zap.Logger) (*VectorClient, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("backend cannot be nil")
+	}
+
+	vc, err := NewVectorClient(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	vc.backend = backend
+	return vc, nil
+}
+
 // CreateCollection crée une nouvelle collection vectorielle
 func (vc *VectorClient) CreateCollection(ctx context.Context) error {
 	vc.logger.Info("Création de la collection",
 		zap.String("collection", vc.config.CollectionName),
 		zap.Int("vector_size", vc.config.VectorSize))
 
-	// TODO: Implémenter avec Qdrant client
-	// return vc.client.CreateCollection(ctx, &qdrant.CreateCollection{
-	//     CollectionName: vc.config.CollectionName,
-	//     VectorsConfig: qdrant.VectorsConfig{
-	//         Size:     uint64(vc.config.VectorSize),
-	//         Distance: qdrant.Distance_Cosine,
-	//     },
-	// })
+	if vc.backend != nil {
+		return vc.backend.CreateCollection(vc.config.CollectionName, vc.config.VectorSize)
+	}
 
-	// Simulation pour le moment
+	// Simulation sans backend
 	vc.logger.Info("Collection créée avec succès (simulation)")
 	return nil
 }
@@ -122,47 +146,163 @@ func (vc *VectorClient) UpsertVectors(ctx context.Context, vectors []Vector) err
 		}
 	}
 
-	// TODO: Implémenter avec Qdrant client
-	// Simulation pour le moment
+	if vc.backend != nil {
+		points := make([]qdrant.Point, len(vectors))
+		for i, vector := range vectors {
+			points[i] = qdrant.Point{ID: vector.ID, Vector: vector.Values, Payload: vector.Metadata}
+		}
+		return vc.backend.UpsertPoints(vc.config.CollectionName, points)
+	}
+
+	// Simulation sans backend
 	vc.logger.Info("Vecteurs insérés avec succès (simulation)")
 	return nil
 }
 
 // SearchVectors recherche des vecteurs similaires
 func (vc *VectorClient) SearchVectors(ctx context.Context, query Vector, topK int) ([]SearchResult, error) {
+	return vc.SearchVectorsWithOptions(ctx, query, SearchOptions{TopK: topK})
+}
+
+// SearchVectorsWithOptions recherche des vecteurs similaires avec filtre sur les
+// métadonnées, seuil de score et pagination
+func (vc *VectorClient) SearchVectorsWithOptions(ctx context.Context, query Vector, opts SearchOptions) ([]SearchResult, error) {
 	if len(query.Values) != vc.config.VectorSize {
 		return nil, fmt.Errorf("vecteur de requête: taille incorrecte %d, attendue %d",
 			len(query.Values), vc.config.VectorSize)
 	}
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("filtre invalide: %w", err)
+	}
 
 	vc.logger.Info("Recherche de vecteurs similaires",
-		zap.Int("top_k", topK),
-		zap.String("query_id", query.ID))
-
-	// TODO: Implémenter avec Qdrant client
-	// Simulation pour le moment
-	results := make([]SearchResult, 0, topK)
-	for i := 0; i < topK && i < 5; i++ { // Simule max 5 résultats
-		results = append(results, SearchResult{
+		zap.Int("top_k", opts.TopK),
+		zap.String("query_id", query.ID),
+		zap.Bool("filtered", opts.Filter != nil))
+
+	if vc.backend != nil {
+		hits, err := vc.backend.Search(vc.config.CollectionName, qdrant.SearchRequest{
+			Vector:         query.Values,
+			Limit:          opts.TopK,
+			Offset:         opts.Offset,
+			WithPayload:    true,
+			Filter:         opts.Filter,
+			ScoreThreshold: opts.ScoreThreshold,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("échec de la recherche: %w", err)
+		}
+
+		results := toSearchResults(hits)
+		vc.logger.Info("Recherche terminée", zap.Int("results", len(results)))
+		return results, nil
+	}
+
+	// Simulation sans backend, les filtres s'appliquent aux résultats simulés
+	results := make([]SearchResult, 0, opts.TopK)
+	for i := 0; i < opts.TopK && i < 5; i++ { // Simule max 5 résultats
+		result := SearchResult{
 			Vector: Vector{
 				ID:     fmt.Sprintf("sim_%d", i),
 				Values: make([]float32, vc.config.VectorSize),
 			},
 			Score: 0.9 - float32(i)*0.1,
-		})
+		}
+		if !opts.Filter.Matches(result.Vector.Metadata) {
+			continue
+		}
+		if opts.ScoreThreshold != nil && result.Score < *opts.ScoreThreshold {
+			continue
+		}
+		results = append(results, result)
 	}
 
 	vc.logger.Info("Recherche terminée", zap.Int("results", len(results)))
 	return results, nil
 }
 
+// HybridSearch combine la recherche vectorielle et une recherche lexicale sur les
+// champs texte des métadonnées (fusion par rang réciproque)
+func (vc *VectorClient) HybridSearch(ctx context.Context, query Vector, text string, opts HybridSearchOptions) ([]SearchResult, error) {
+	if vc.backend == nil {
+		return nil, fmt.Errorf("recherche hybride indisponible sans backend Qdrant")
+	}
+	if len(query.Values) > 0 && len(query.Values) != vc.config.VectorSize {
+		return nil, fmt.Errorf("vecteur de requête: taille incorrecte %d, attendue %d",
+			len(query.Values), vc.config.VectorSize)
+	}
+
+	hits, err := vc.backend.HybridSearch(vc.config.CollectionName, qdrant.HybridSearchRequest{
+		Vector:      query.Values,
+		Text:        text,
+		TextFields:  opts.TextFields,
+		Limit:       opts.TopK,
+		Filter:      opts.Filter,
+		WithPayload: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche hybride: %w", err)
+	}
+
+	vc.logger.Info("Recherche hybride terminée",
+		zap.String("text", text),
+		zap.Int("results", len(hits)))
+	return toSearchResults(hits), nil
+}
+
+// ScrollVectors parcourt la collection page par page, dans l'ordre des IDs
+func (vc *VectorClient) ScrollVectors(ctx context.Context, filter *qdrant.Filter, limit int, offset interface{}) (*ScrollPage, error) {
+	if vc.backend == nil {
+		return &ScrollPage{Vectors: []Vector{}}, nil
+	}
+
+	response, err := vc.backend.Scroll(vc.config.CollectionName, qdrant.ScrollRequest{
+		Filter:      filter,
+		Limit:       limit,
+		Offset:      offset,
+		WithPayload: true,
+		WithVector:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("échec du parcours de la collection: %w", err)
+	}
+
+	page := &ScrollPage{
+		Vectors:    make([]Vector, len(response.Points)),
+		NextOffset: response.NextPageOffset,
+	}
+	for i, point := range response.Points {
+		page.Vectors[i] = Vector{ID: fmt.Sprint(point.ID), Values: point.Vector, Metadata: point.Payload}
+	}
+	return page, nil
+}
+
+// toSearchResults convertit les résultats Qdrant en résultats vectoriels
+func toSearchResults(hits []qdrant.SearchResult) []SearchResult {
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchResult{
+			Vector: Vector{ID: fmt.Sprint(hit.ID), Metadata: hit.Payload},
+			Score:  hit.Score,
+		}
+	}
+	return results
+}
+
 // SearchVectorsParallel effectue des recherches vectorielles en parallèle
 func (vc *VectorClient) SearchVectorsParallel(ctx context.Context, queries []Vector, topK int) ([]SearchResult, error) {
+	return vc.SearchVectorsParallelWithOptions(ctx, queries, SearchOptions{TopK: topK})
+}
+
+// SearchVectorsParallelWithOptions effectue en parallèle une recherche
+// SearchVectorsWithOptions par requête, avec les mêmes filtres et seuil
+func (vc *VectorClient) SearchVectorsParallelWithOptions(ctx context.Context, queries []Vector, opts SearchOptions) ([]SearchResult, error) {
 	if len(queries) == 0 {
 		return []SearchResult{}, nil
 	}
 
-	resultChan := make(chan SearchResult, len(queries)*topK)
+	// Résultats rangés par requête : le nombre de résultats dépend du backend
+	queryResults := make([][]SearchResult, len(queries))
 	errChan := make(chan error, len(queries))
 
 	var wg sync.WaitGroup
@@ -170,7 +310,7 @@ func (vc *VectorClient) SearchVectorsParallel(ctx context.Context, queries []Vec
 
 	vc.logger.Info("Démarrage de la recherche vectorielle parallèle",
 		zap.Int("nombre_de_requêtes", len(queries)),
-		zap.Int("top_k", topK))
+		zap.Int("top_k", opts.TopK))
 
 	startTime := time.Now()
 
@@ -181,31 +321,29 @@ func (vc *VectorClient) SearchVectorsParallel(ctx context.Context, queries []Vec
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			// Simuler la recherche vectorielle (remplacera l'appel Qdrant réel)
-			results, err := vc.searchVector(ctx, vec, topK)
+			results, err := vc.SearchVectorsWithOptions(ctx, vec, opts)
 			if err != nil {
 				errChan <- fmt.Errorf("échec de la recherche pour la requête %d: %w", idx, err)
 				return
 			}
 
 			// Ajouter l'index de la requête aux résultats
-			for j, result := range results {
-				result.QueryIndex = idx
-				resultChan <- result
+			for j := range results {
+				results[j].QueryIndex = idx
 
 				// Log pour les premiers résultats
 				if j < 3 {
 					vc.logger.Debug("Résultat de recherche",
 						zap.Int("index_requête", idx),
-						zap.String("id_résultat", result.Vector.ID),
-						zap.Float32("score", result.Score))
+						zap.String("id_résultat", results[j].Vector.ID),
+						zap.Float32("score", results[j].Score))
 				}
 			}
+			queryResults[idx] = results
 		}(i, query)
 	}
 
 	wg.Wait()
-	close(resultChan)
 	close(errChan)
 
 	// Collecter les erreurs
@@ -220,8 +358,8 @@ func (vc *VectorClient) SearchVectorsParallel(ctx context.Context, queries []Vec
 
 	// Collecter les résultats
 	var results []SearchResult
-	for result := range resultChan {
-		results = append(results, result)
+	for _, batch := range queryResults {
+		results = append(results, batch...)
 	}
 
 	duration := time.Since(startTime)
@@ -233,44 +371,23 @@ func (vc *VectorClient) SearchVectorsParallel(ctx context.Context, queries []Vec
 	return results, nil
 }
 
-// searchVector effectue une recherche vectorielle simple (simulation)
-func (vc *VectorClient) searchVector(ctx context.Context, query Vector, topK int) ([]SearchResult, error) {
-	// Simulation de recherche vectorielle
-	// En production, ceci ferait appel à Qdrant
-	results := make([]SearchResult, topK)
-
-	for i := 0; i < topK; i++ {
-		results[i] = SearchResult{
-			Vector: Vector{
-				ID:     fmt.Sprintf("result_%s_%d", query.ID, i),
-				Values: make([]float32, len(query.Values)),
-				Metadata: map[string]interface{}{
-					"similarity_type": "cosine",
-					"source_query":    query.ID,
-				},
-			},
-			Score: 0.95 - float32(i)*0.1, // Score décroissant simulé
-		}
-
-		// Simuler quelques valeurs vectorielles
-		for j := range results[i].Vector.Values {
-			results[i].Vector.Values[j] = query.Values[j] + float32(i)*0.01
-		}
-	}
-
-	// Simuler un délai de réseau
-	time.Sleep(time.Millisecond * 10)
-
-	return results, nil
-}
-
 // ListVectors liste tous les vecteurs d'une collection
 func (vc *VectorClient) ListVectors(ctx context.Context) ([]Vector, error) {
 	vc.logger.Info("Liste des vecteurs demandée")
 
-	// TODO: Implémenter avec Qdrant client
-	// Simulation pour le moment
 	vectors := make([]Vector, 0)
+	var offset interface{}
+	for vc.backend != nil {
+		page, err := vc.ScrollVectors(ctx, nil, vc.pageSize(), offset)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, page.Vectors...)
+		if page.NextOffset == nil {
+			break
+		}
+		offset = page.NextOffset
+	}
 	vc.logger.Info("Liste des vecteurs récupérée", zap.Int("count", len(vectors)))
 	return vectors, nil
 }
@@ -279,8 +396,20 @@ func (vc *VectorClient) ListVectors(ctx context.Context) ([]Vector, error) {
 func (vc *VectorClient) GetCollectionInfo(ctx context.Context) (*CollectionInfo, error) {
 	vc.logger.Info("Récupération des informations de collection")
 
-	// TODO: Implémenter avec Qdrant client
-	// Simulation pour le moment
+	if vc.backend != nil {
+		backendInfo, err := vc.backend.GetCollectionInfo(vc.config.CollectionName)
+		if err != nil {
+			return nil, err
+		}
+		return &CollectionInfo{
+			Name:        vc.config.CollectionName,
+			VectorSize:  backendInfo.VectorSize,
+			VectorCount: int64(backendInfo.PointsCount),
+			Status:      backendInfo.Status,
+		}, nil
+	}
+
+	// Simulation sans backend
 	info := &CollectionInfo{
 		Name:        vc.config.CollectionName,
 		VectorSize:  vc.config.VectorSize,
@@ -296,12 +425,23 @@ func (vc *VectorClient) DeleteCollection(ctx context.Context) error {
 	vc.logger.Warn("Suppression de la collection",
 		zap.String("collection", vc.config.CollectionName))
 
-	// TODO: Implémenter avec Qdrant client
-	// Simulation pour le moment
+	if vc.backend != nil {
+		return vc.backend.DeleteCollection(vc.config.CollectionName)
+	}
+
+	// Simulation sans backend
 	vc.logger.Info("Collection supprimée avec succès (simulation)")
 	return nil
 }
 
+// pageSize retourne la taille des pages de parcours de la collection
+func (vc *VectorClient) pageSize() int {
+	if vc.config.BatchSize > 0 {
+		return vc.config.BatchSize
+	}
+	return 100
+}
+
 // validateConfig valide la configuration du client
 func validateConfig(config VectorConfig) error {
 	if config.Host == "" {
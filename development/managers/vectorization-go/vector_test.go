@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"email_sender/src/qdrant"
+
 	"go.uber.org/zap"
 )
 
@@ -120,6 +122,87 @@ func TestVectorClient_SearchVectors(t *testing.T) {
 	}
 }
 
+// TestVectorClient_SearchWithEmbeddedBackend teste filtres, seuil, parcours et recherche hybride
+func TestVectorClient_SearchWithEmbeddedBackend(t *testing.T) {
+	logger := zap.NewNop()
+	config := VectorConfig{
+		Host:           "localhost",
+		Port:           6333,
+		CollectionName: "test_collection",
+		VectorSize:     3,
+		BatchSize:      2,
+	}
+
+	backend := qdrant.NewEmbeddedClient(&qdrant.EmbeddedConfig{})
+	client, err := NewVectorClientWithBackend(config, backend, logger)
+	if err != nil {
+		t.Fatalf("Erreur création client: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.CreateCollection(ctx); err != nil {
+		t.Fatalf("Erreur création collection: %v", err)
+	}
+
+	vectors := []Vector{
+		{ID: "a", Values: []float32{1, 0, 0}, Metadata: map[string]interface{}{"type": "email", "text": "smtp bounce handling"}},
+		{ID: "b", Values: []float32{0.9, 0.1, 0}, Metadata: map[string]interface{}{"type": "plan", "text": "roadmap for smtp retries"}},
+		{ID: "c", Values: []float32{0, 1, 0}, Metadata: map[string]interface{}{"type": "email", "text": "newsletter template"}},
+	}
+	if err := client.UpsertVectors(ctx, vectors); err != nil {
+		t.Fatalf("Erreur insertion vecteurs: %v", err)
+	}
+
+	query := Vector{ID: "query", Values: []float32{1, 0, 0}}
+	results, err := client.SearchVectorsWithOptions(ctx, query, SearchOptions{
+		TopK:   5,
+		Filter: &qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("type", "email")}},
+	})
+	if err != nil {
+		t.Fatalf("Erreur recherche filtrée: %v", err)
+	}
+	if len(results) != 2 || results[0].Vector.ID != "a" || results[1].Vector.ID != "c" {
+		t.Errorf("Résultats filtrés inattendus: %+v", results)
+	}
+
+	parallel, err := client.SearchVectorsParallelWithOptions(ctx, []Vector{query, {ID: "query2", Values: []float32{0, 1, 0}}}, SearchOptions{
+		TopK:   5,
+		Filter: &qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("type", "email")}},
+	})
+	if err != nil {
+		t.Fatalf("Erreur recherche parallèle filtrée: %v", err)
+	}
+	if len(parallel) != 4 || parallel[0].Vector.ID != "a" || parallel[0].QueryIndex != 0 ||
+		parallel[2].Vector.ID != "c" || parallel[2].QueryIndex != 1 {
+		t.Errorf("Résultats parallèles inattendus: %+v", parallel)
+	}
+
+	threshold := float32(0.5)
+	results, err = client.SearchVectorsWithOptions(ctx, query, SearchOptions{TopK: 5, ScoreThreshold: &threshold})
+	if err != nil {
+		t.Fatalf("Erreur recherche avec seuil: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Attendu 2 résultats au-dessus du seuil, obtenu %d", len(results))
+	}
+
+	all, err := client.ListVectors(ctx)
+	if err != nil {
+		t.Fatalf("Erreur liste des vecteurs: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Attendu 3 vecteurs parcourus, obtenu %d", len(all))
+	}
+
+	hybrid, err := client.HybridSearch(ctx, Vector{}, "smtp", HybridSearchOptions{TopK: 5, TextFields: []string{"text"}})
+	if err != nil {
+		t.Fatalf("Erreur recherche hybride: %v", err)
+	}
+	if len(hybrid) != 2 {
+		t.Errorf("Attendu 2 résultats lexicaux pour 'smtp', obtenu %+v", hybrid)
+	}
+}
+
 // TestVectorOperations_BatchUpsert teste l'insertion par lots
 func TestVectorOperations_BatchUpsert(t *testing.T) {
 	logger := zap.NewNop()
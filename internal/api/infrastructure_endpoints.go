@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"email_sender/development/managers/security-manager/auth"
 	"email_sender/internal/infrastructure"
 )
 
@@ -15,6 +16,7 @@ import (
 type InfrastructureAPIHandler struct {
 	orchestrator infrastructure.InfrastructureOrchestrator
 	server       *http.Server
+	auth         *auth.Middleware
 }
 
 // NewInfrastructureAPIHandler crée un nouveau handler API
@@ -24,6 +26,31 @@ func NewInfrastructureAPIHandler(orchestrator infrastructure.InfrastructureOrche
 	}
 }
 
+// SetAuthMiddleware protège tous les endpoints par le middleware d'authentification
+func (h *InfrastructureAPIHandler) SetAuthMiddleware(middleware *auth.Middleware) {
+	h.auth = middleware
+}
+
+// InfrastructureAuthPolicy retourne la politique d'accès par défaut des endpoints :
+// lecture seule pour "viewer", pilotage des services pour "operator"
+func InfrastructureAuthPolicy() *auth.Policy {
+	return &auth.Policy{
+		Roles: map[string][]string{
+			"viewer":   {"infrastructure:read", "monitoring:read"},
+			"operator": {"infrastructure:*", "monitoring:*", "auto-healing:*"},
+			"admin":    {"*"},
+		},
+		Routes: []auth.Route{
+			{Method: "GET", Path: "/api/v1/infrastructure/health", Public: true},
+			{Method: "GET", Path: "/api/v1/infrastructure/status", Permission: "infrastructure:read"},
+			{Method: "POST", Path: "/api/v1/infrastructure/*", Permission: "infrastructure:write"},
+			{Method: "GET", Path: "/api/v1/monitoring/*", Permission: "monitoring:read"},
+			{Method: "POST", Path: "/api/v1/monitoring/*", Permission: "monitoring:write"},
+			{Method: "POST", Path: "/api/v1/auto-healing/*", Permission: "auto-healing:write"},
+		},
+	}
+}
+
 // StartServer démarre le serveur HTTP d'API
 func (h *InfrastructureAPIHandler) StartServer(port int) error {
 	mux := http.NewServeMux()
@@ -45,9 +72,16 @@ func (h *InfrastructureAPIHandler) StartServer(port int) error {
 	mux.HandleFunc("/api/v1/auto-healing/enable", h.handleEnableAutoHealing)
 	mux.HandleFunc("/api/v1/auto-healing/disable", h.handleDisableAutoHealing)
 
+	var handler http.Handler = mux
+	if h.auth != nil {
+		handler = h.auth.Handler(mux)
+	} else {
+		log.Printf("⚠️  Infrastructure API started without authentication")
+	}
+
 	h.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
-		Handler: mux,
+		Handler: handler,
 	}
 
 	log.Printf("🚀 Starting Infrastructure API server on port %d", port)
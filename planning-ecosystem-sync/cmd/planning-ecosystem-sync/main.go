@@ -0,0 +1,180 @@
+// Package main implements the planning-ecosystem-sync command line tool
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"email_sender/planning-ecosystem-sync/pkg/plandiff"
+	"email_sender/planning-ecosystem-sync/pkg/planembed"
+	"email_sender/planning-ecosystem-sync/pkg/qdrant"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
+
+	"go.uber.org/zap"
+)
+
+const usage = `Usage: planning-ecosystem-sync <command> [options]
+
+Commands:
+  diff [--format markdown|json] [--output file] <a> <b>
+        Semantic diff of two plans (Markdown plans or dynamic plan JSON exports).
+        Exits with 0 when the plans are equivalent, 1 when they differ, 2 on error.
+
+  reembed [--collection name] [--target name] [--provider hashing|openai] [--model name]
+          [--base-url url] [--dimension n] [--force] [--dry-run] [--delete-source]
+        Recompute the task embeddings stored in Qdrant with the configured model.
+        A dimension change requires a new --target collection. The API key of
+        the provider is read from EMBEDDING_API_KEY.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "diff":
+		os.Exit(runDiff(os.Args[2:]))
+	case "reembed":
+		os.Exit(runReembed(os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// runDiff compares two plans and prints the report
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "Output format (markdown, json)")
+	output := flags.String("output", "", "Write the report to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	from, err := plandiff.LoadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+	to, err := plandiff.LoadFile(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+
+	diff := plandiff.Diff(from, to)
+
+	var report []byte
+	switch *format {
+	case "markdown", "md":
+		report = []byte(diff.Markdown())
+	case "json":
+		if report, err = diff.JSON(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ failed to serialize diff: %v\n", err)
+			return 2
+		}
+		report = append(report, '\n')
+	default:
+		fmt.Fprintf(os.Stderr, "❌ unknown format %q\n", *format)
+		return 2
+	}
+
+	if *output != "" {
+		if err := os.WriteFile(*output, report, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ failed to write report: %v\n", err)
+			return 2
+		}
+	} else {
+		os.Stdout.Write(report)
+	}
+
+	if diff.IsEmpty() {
+		return 0
+	}
+	return 1
+}
+
+// runReembed migrates the task embeddings of a collection to the configured model
+func runReembed(args []string) int {
+	flags := flag.NewFlagSet("reembed", flag.ContinueOnError)
+	qdrantURL := flags.String("qdrant-url", "http://localhost:6333", "Qdrant server URL")
+	collection := flags.String("collection", planembed.DefaultCollection, "Collection to migrate")
+	target := flags.String("target", "", "Destination collection (default: migrate in place)")
+	provider := flags.String("provider", "hashing", "Embedding provider (hashing, openai)")
+	model := flags.String("model", "", "Embedding model of the provider")
+	baseURL := flags.String("base-url", "", "Base URL of an OpenAI-compatible embeddings API")
+	dimension := flags.Int("dimension", 0, "Embedding dimension (default: provider dimension)")
+	batchSize := flags.Int("batch-size", 64, "Points processed per batch")
+	force := flags.Bool("force", false, "Re-embed points already produced by the model")
+	dryRun := flags.Bool("dry-run", false, "Report what would be migrated without writing")
+	deleteSource := flags.Bool("delete-source", false, "Delete the source collection after a successful migration")
+	verbose := flags.Bool("verbose", false, "Enable verbose logging")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	logger := zap.NewNop()
+	if *verbose {
+		logger, _ = zap.NewDevelopment()
+	}
+	defer logger.Sync()
+
+	embedder, err := vectorization.NewEmbeddingClient(vectorization.EmbeddingConfig{
+		Provider:  *provider,
+		BaseURL:   *baseURL,
+		Model:     *model,
+		APIKey:    os.Getenv("EMBEDDING_API_KEY"),
+		Dimension: *dimension,
+		BatchSize: *batchSize,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+
+	store, err := qdrant.NewUnifiedClient(*qdrantURL, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := planembed.Reembed(ctx, store, embedder, planembed.ReembedOptions{
+		Source:       *collection,
+		Target:       *target,
+		BatchSize:    *batchSize,
+		Force:        *force,
+		DryRun:       *dryRun,
+		DeleteSource: *deleteSource,
+	}, logger)
+	if report != nil {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+	if len(report.MissingText) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️ %d point(s) without text payload were left as is: index their plans again\n", len(report.MissingText))
+	}
+	if len(report.Failed) > 0 || len(report.MissingText) > 0 {
+		return 1
+	}
+	return 0
+}
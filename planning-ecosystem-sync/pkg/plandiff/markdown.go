@@ -0,0 +1,269 @@
+package plandiff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*$`)
+	checkboxPattern = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX~-])\]\s+(.+?)\s*$`)
+	versionPattern  = regexp.MustCompile(`(?i)version\s*:?\s*\**\s*v?([0-9]+(?:\.[0-9]+)*)`)
+	progressPattern = regexp.MustCompile(`(?i)progression\s*:?\s*\**\s*([0-9]+(?:\.[0-9]+)?)\s*%`)
+	taskIDPattern   = regexp.MustCompile(`^\**([0-9]+(?:\.[0-9]+)+)\.?\**\s+`)
+	decorPattern    = regexp.MustCompile(`^[^\p{L}\p{N}*\[(]+`)
+)
+
+// ParseMarkdown reads a Markdown plan. Level-2 headings are phases and
+// checkbox items are tasks; a task ID is its numeric prefix ("1.2.3") when it
+// has one, a slug of its title otherwise.
+func ParseMarkdown(r io.Reader, path string) (*Plan, error) {
+	plan := &Plan{Path: path}
+	if path != "" {
+		plan.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	seen := make(map[string]int)
+	phase := ""
+	inCode := false
+	completed := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			text := cleanTitle(match[2])
+			switch len(match[1]) {
+			case 1:
+				if plan.Title == "" {
+					plan.Title = text
+				}
+			case 2:
+				phase = text
+			}
+			continue
+		}
+
+		if match := checkboxPattern.FindStringSubmatch(line); match != nil {
+			title := cleanTitle(match[3])
+			id := title
+			if idMatch := taskIDPattern.FindStringSubmatch(title); idMatch != nil {
+				id = idMatch[1]
+			} else {
+				id = slug(title)
+			}
+			seen[id]++
+			if seen[id] > 1 {
+				id = fmt.Sprintf("%s~%d", id, seen[id])
+			}
+
+			status := checkboxStatus(match[2])
+			if status == "completed" {
+				completed++
+			}
+			plan.Tasks = append(plan.Tasks, Task{
+				ID:     id,
+				Title:  title,
+				Phase:  phase,
+				Status: status,
+				Level:  len(strings.ReplaceAll(match[1], "\t", "  "))/2 + 1,
+			})
+			continue
+		}
+
+		if plan.Version == "" {
+			if match := versionPattern.FindStringSubmatch(line); match != nil {
+				plan.Version = match[1]
+			}
+		}
+		if match := progressPattern.FindStringSubmatch(line); match != nil && plan.Progression == 0 {
+			plan.Progression, _ = strconv.ParseFloat(match[1], 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if plan.Progression == 0 && len(plan.Tasks) > 0 {
+		plan.Progression = float64(completed) / float64(len(plan.Tasks)) * 100
+	}
+	return plan, nil
+}
+
+// dynamicPlanJSON is the JSON form of a sync-core DynamicPlan
+type dynamicPlanJSON struct {
+	ID       string `json:"id"`
+	Metadata struct {
+		FilePath    string  `json:"file_path"`
+		Title       string  `json:"title"`
+		Version     string  `json:"version"`
+		Progression float64 `json:"progression"`
+	} `json:"metadata"`
+	Tasks []Task `json:"tasks"`
+}
+
+// LoadFile reads a plan from a Markdown file or from the JSON export of a
+// dynamic plan
+func LoadFile(path string) (*Plan, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseMarkdown(file, path)
+	}
+
+	var dynamic dynamicPlanJSON
+	if err := json.NewDecoder(file).Decode(&dynamic); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &Plan{
+		ID:          dynamic.ID,
+		Title:       dynamic.Metadata.Title,
+		Version:     dynamic.Metadata.Version,
+		Progression: dynamic.Metadata.Progression,
+		Path:        path,
+		Tasks:       dynamic.Tasks,
+	}, nil
+}
+
+// Markdown renders the diff as a Markdown report
+func (d *PlanDiff) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Plan diff: %s → %s\n\n", planLabel(d.From), planLabel(d.To))
+	if d.IsEmpty() {
+		b.WriteString("No differences.\n")
+		return b.String()
+	}
+
+	s := d.Summary
+	fmt.Fprintf(&b, "**Similarity**: %.1f%% — %d added, %d removed, %d moved, %d renamed, %d status changed, %d reordered, %d unchanged\n",
+		s.Similarity*100, s.Added, s.Removed, s.Moved, s.Renamed, s.StatusChanged, s.Reordered, s.Unchanged)
+
+	if len(d.MetadataChanges) > 0 {
+		b.WriteString("\n## Metadata\n\n| Field | Before | After |\n|---|---|---|\n")
+		for _, change := range d.MetadataChanges {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", change.Field, change.From, change.To)
+		}
+	}
+
+	if len(d.Added) > 0 {
+		b.WriteString("\n## Added tasks\n\n")
+		for _, task := range d.Added {
+			fmt.Fprintf(&b, "- %s\n", taskLabel(task))
+		}
+	}
+	if len(d.Removed) > 0 {
+		b.WriteString("\n## Removed tasks\n\n")
+		for _, task := range d.Removed {
+			fmt.Fprintf(&b, "- %s\n", taskLabel(task))
+		}
+	}
+	if len(d.Moved) > 0 {
+		b.WriteString("\n## Moved tasks\n\n")
+		for _, move := range d.Moved {
+			fmt.Fprintf(&b, "- `%s` %s: %s → %s\n", move.Task.ID, move.Task.Title, phaseLabel(move.FromPhase), phaseLabel(move.ToPhase))
+		}
+	}
+	if len(d.Renamed) > 0 {
+		b.WriteString("\n## Renamed tasks\n\n")
+		for _, rename := range d.Renamed {
+			fmt.Fprintf(&b, "- `%s` %q → %q\n", rename.Task.ID, rename.OldTitle, rename.NewTitle)
+		}
+	}
+	if len(d.StatusChanged) > 0 {
+		b.WriteString("\n## Status changes\n\n")
+		for _, change := range d.StatusChanged {
+			fmt.Fprintf(&b, "- `%s` %s: %s → %s\n", change.Task.ID, change.Task.Title, change.From, change.To)
+		}
+	}
+	if len(d.Reordered) > 0 {
+		b.WriteString("\n## Reordered tasks\n\n")
+		for _, reorder := range d.Reordered {
+			fmt.Fprintf(&b, "- `%s` %s (%s): position %d → %d\n", reorder.Task.ID, reorder.Task.Title,
+				phaseLabel(reorder.Task.Phase), reorder.FromPosition, reorder.ToPosition)
+		}
+	}
+
+	return b.String()
+}
+
+// JSON renders the diff as indented JSON
+func (d *PlanDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// checkboxStatus maps a checkbox mark to a task status
+func checkboxStatus(mark string) string {
+	switch mark {
+	case "x", "X":
+		return "completed"
+	case "~", "-":
+		return "in_progress"
+	default:
+		return "pending"
+	}
+}
+
+// cleanTitle strips emoji and status markers from a heading or task text
+func cleanTitle(text string) string {
+	return strings.TrimSpace(decorPattern.ReplaceAllString(strings.TrimSpace(text), ""))
+}
+
+// slug builds a task ID from its title
+func slug(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	return strings.Join(words, "-")
+}
+
+// planLabel names a plan in a report
+func planLabel(ref PlanRef) string {
+	label := ref.Title
+	if label == "" {
+		label = ref.ID
+	}
+	if ref.Version != "" {
+		label += " v" + ref.Version
+	}
+	return label
+}
+
+// taskLabel formats a task in a report
+func taskLabel(task TaskRef) string {
+	return fmt.Sprintf("`%s` %s (%s)", task.ID, task.Title, phaseLabel(task.Phase))
+}
+
+// phaseLabel names a phase in a report
+func phaseLabel(phase string) string {
+	if phase == "" {
+		return "no phase"
+	}
+	return phase
+}
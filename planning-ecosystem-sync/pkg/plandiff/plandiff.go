@@ -0,0 +1,430 @@
+// Package plandiff computes semantic differences between two versions of a
+// development plan at the phase and task level: tasks added, removed, moved
+// between phases, renamed, whose status changed or that were reordered.
+package plandiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Plan is the plan representation compared by Diff
+type Plan struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Version     string  `json:"version"`
+	Progression float64 `json:"progression"`
+	Path        string  `json:"path,omitempty"`
+	Tasks       []Task  `json:"tasks"`
+}
+
+// Task is a task of a plan. Tasks are matched across versions by ID first,
+// then by title similarity within the same phase to detect renames.
+type Task struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Phase  string `json:"phase"`
+	Status string `json:"status"`
+	Level  int    `json:"level,omitempty"`
+}
+
+// PlanRef identifies a compared plan
+type PlanRef struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// TaskRef identifies a task in a diff
+type TaskRef struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Phase  string `json:"phase"`
+	Status string `json:"status,omitempty"`
+}
+
+// FieldChange records the old and new value of a plan field
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// TaskMove is a task found in another phase
+type TaskMove struct {
+	Task      TaskRef `json:"task"`
+	FromPhase string  `json:"from_phase"`
+	ToPhase   string  `json:"to_phase"`
+}
+
+// TaskRename is a task whose title changed
+type TaskRename struct {
+	Task     TaskRef `json:"task"`
+	FromID   string  `json:"from_id,omitempty"` // Set when the ID changed with the title
+	OldTitle string  `json:"old_title"`
+	NewTitle string  `json:"new_title"`
+}
+
+// StatusChange is a task whose status changed
+type StatusChange struct {
+	Task TaskRef `json:"task"`
+	From string  `json:"from"`
+	To   string  `json:"to"`
+}
+
+// TaskReorder is a task whose position changed within its phase
+type TaskReorder struct {
+	Task         TaskRef `json:"task"`
+	FromPosition int     `json:"from_position"` // 1-based position within the phase
+	ToPosition   int     `json:"to_position"`
+}
+
+// Summary counts the changes of a diff
+type Summary struct {
+	Added         int     `json:"added"`
+	Removed       int     `json:"removed"`
+	Moved         int     `json:"moved"`
+	Renamed       int     `json:"renamed"`
+	StatusChanged int     `json:"status_changed"`
+	Reordered     int     `json:"reordered"`
+	Unchanged     int     `json:"unchanged"`
+	Similarity    float64 `json:"similarity"` // Share of tasks present in both versions
+}
+
+// PlanDiff is the semantic difference between two plans
+type PlanDiff struct {
+	From            PlanRef        `json:"from"`
+	To              PlanRef        `json:"to"`
+	MetadataChanges []FieldChange  `json:"metadata_changes,omitempty"`
+	Added           []TaskRef      `json:"added,omitempty"`
+	Removed         []TaskRef      `json:"removed,omitempty"`
+	Moved           []TaskMove     `json:"moved,omitempty"`
+	Renamed         []TaskRename   `json:"renamed,omitempty"`
+	StatusChanged   []StatusChange `json:"status_changed,omitempty"`
+	Reordered       []TaskReorder  `json:"reordered,omitempty"`
+	Summary         Summary        `json:"summary"`
+}
+
+// renameThreshold is the minimum title similarity to pair a removed task
+// with an added one as a rename
+const renameThreshold = 0.5
+
+// IsEmpty reports whether the two plans are equivalent
+func (d *PlanDiff) IsEmpty() bool {
+	return len(d.MetadataChanges) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.Moved) == 0 && len(d.Renamed) == 0 && len(d.StatusChanged) == 0 && len(d.Reordered) == 0
+}
+
+// String returns a one-line summary of the diff
+func (d *PlanDiff) String() string {
+	if d.IsEmpty() {
+		return "no differences"
+	}
+	s := d.Summary
+	return fmt.Sprintf("%d added, %d removed, %d moved, %d renamed, %d status changed, %d reordered (similarity %.1f%%)",
+		s.Added, s.Removed, s.Moved, s.Renamed, s.StatusChanged, s.Reordered, s.Similarity*100)
+}
+
+// Diff compares two versions of a plan
+func Diff(from, to *Plan) *PlanDiff {
+	if from == nil {
+		from = &Plan{}
+	}
+	if to == nil {
+		to = &Plan{}
+	}
+
+	diff := &PlanDiff{
+		From: PlanRef{ID: from.ID, Title: from.Title, Version: from.Version, Path: from.Path},
+		To:   PlanRef{ID: to.ID, Title: to.Title, Version: to.Version, Path: to.Path},
+	}
+	diff.MetadataChanges = diffMetadata(from, to)
+
+	pairs, removed, added := matchTasks(from.Tasks, to.Tasks)
+
+	for _, i := range removed {
+		diff.Removed = append(diff.Removed, ref(from.Tasks[i]))
+	}
+	for _, j := range added {
+		diff.Added = append(diff.Added, ref(to.Tasks[j]))
+	}
+
+	unchanged := 0
+	for _, p := range pairs {
+		before, after := from.Tasks[p.from], to.Tasks[p.to]
+		changed := false
+
+		if before.Phase != after.Phase {
+			diff.Moved = append(diff.Moved, TaskMove{Task: ref(after), FromPhase: before.Phase, ToPhase: after.Phase})
+			changed = true
+		}
+		if normalizeTitle(before.Title) != normalizeTitle(after.Title) {
+			rename := TaskRename{Task: ref(after), OldTitle: before.Title, NewTitle: after.Title}
+			if before.ID != after.ID {
+				rename.FromID = before.ID
+			}
+			diff.Renamed = append(diff.Renamed, rename)
+			changed = true
+		}
+		if before.Status != after.Status {
+			diff.StatusChanged = append(diff.StatusChanged, StatusChange{Task: ref(after), From: before.Status, To: after.Status})
+			changed = true
+		}
+		if !changed {
+			unchanged++
+		}
+	}
+
+	diff.Reordered = findReordered(from.Tasks, to.Tasks, pairs)
+	unchanged -= countUnchangedReordered(diff, pairs, from.Tasks, to.Tasks)
+
+	total := len(from.Tasks)
+	if len(to.Tasks) > total {
+		total = len(to.Tasks)
+	}
+	similarity := 1.0
+	if total > 0 {
+		similarity = float64(len(pairs)) / float64(total)
+	}
+
+	diff.Summary = Summary{
+		Added:         len(diff.Added),
+		Removed:       len(diff.Removed),
+		Moved:         len(diff.Moved),
+		Renamed:       len(diff.Renamed),
+		StatusChanged: len(diff.StatusChanged),
+		Reordered:     len(diff.Reordered),
+		Unchanged:     unchanged,
+		Similarity:    similarity,
+	}
+	return diff
+}
+
+// diffMetadata compares the plan-level fields
+func diffMetadata(from, to *Plan) []FieldChange {
+	var changes []FieldChange
+	fields := [][3]string{
+		{"title", from.Title, to.Title},
+		{"version", from.Version, to.Version},
+		{"progression", fmt.Sprintf("%.1f", from.Progression), fmt.Sprintf("%.1f", to.Progression)},
+	}
+	for _, field := range fields {
+		if field[1] != field[2] {
+			changes = append(changes, FieldChange{Field: field[0], From: field[1], To: field[2]})
+		}
+	}
+	return changes
+}
+
+// pair links the index of a task in the old plan to its index in the new one
+type pair struct {
+	from, to int
+}
+
+// matchTasks pairs the tasks of both versions: by ID, then removed and added
+// tasks of the same phase with similar titles are paired as renames. It
+// returns the pairs and the indexes of the unpaired old and new tasks.
+func matchTasks(from, to []Task) ([]pair, []int, []int) {
+	toByID := make(map[string]int, len(to))
+	for j, task := range to {
+		if _, exists := toByID[task.ID]; !exists {
+			toByID[task.ID] = j
+		}
+	}
+
+	var pairs []pair
+	pairedTo := make(map[int]bool)
+	var unpairedFrom []int
+	for i, task := range from {
+		if j, exists := toByID[task.ID]; exists && !pairedTo[j] {
+			pairs = append(pairs, pair{i, j})
+			pairedTo[j] = true
+		} else {
+			unpairedFrom = append(unpairedFrom, i)
+		}
+	}
+
+	// Rename detection: best title similarity within the same phase
+	type candidate struct {
+		from, to int
+		score    float64
+	}
+	var candidates []candidate
+	for _, i := range unpairedFrom {
+		for j := range to {
+			if pairedTo[j] || from[i].Phase != to[j].Phase {
+				continue
+			}
+			if score := titleSimilarity(from[i].Title, to[j].Title); score >= renameThreshold {
+				candidates = append(candidates, candidate{i, j, score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+
+	pairedFrom := make(map[int]bool)
+	for _, c := range candidates {
+		if pairedFrom[c.from] || pairedTo[c.to] {
+			continue
+		}
+		pairs = append(pairs, pair{c.from, c.to})
+		pairedFrom[c.from] = true
+		pairedTo[c.to] = true
+	}
+
+	var removed, added []int
+	for _, i := range unpairedFrom {
+		if !pairedFrom[i] {
+			removed = append(removed, i)
+		}
+	}
+	for j := range to {
+		if !pairedTo[j] {
+			added = append(added, j)
+		}
+	}
+
+	sort.Slice(pairs, func(a, b int) bool { return pairs[a].to < pairs[b].to })
+	return pairs, removed, added
+}
+
+// findReordered reports the tasks that stayed in their phase but whose
+// relative order changed. The longest subsequence of tasks keeping their
+// order is considered stable; the other tasks were reordered.
+func findReordered(from, to []Task, pairs []pair) []TaskReorder {
+	// Pairs of each phase, in the order of the new plan
+	phases := make(map[string][]pair)
+	var phaseOrder []string
+	for _, p := range pairs {
+		if from[p.from].Phase != to[p.to].Phase {
+			continue
+		}
+		phase := to[p.to].Phase
+		if _, exists := phases[phase]; !exists {
+			phaseOrder = append(phaseOrder, phase)
+		}
+		phases[phase] = append(phases[phase], p)
+	}
+
+	var reordered []TaskReorder
+	for _, phase := range phaseOrder {
+		phasePairs := phases[phase]
+		stable := longestIncreasing(phasePairs)
+
+		oldPositions := positionsInPhase(from, phase)
+		newPositions := positionsInPhase(to, phase)
+		for k, p := range phasePairs {
+			if stable[k] {
+				continue
+			}
+			reordered = append(reordered, TaskReorder{
+				Task:         ref(to[p.to]),
+				FromPosition: oldPositions[p.from],
+				ToPosition:   newPositions[p.to],
+			})
+		}
+	}
+	return reordered
+}
+
+// longestIncreasing marks the longest subsequence of pairs whose old indexes
+// are increasing
+func longestIncreasing(pairs []pair) []bool {
+	n := len(pairs)
+	length := make([]int, n)
+	previous := make([]int, n)
+	best := -1
+	for i := 0; i < n; i++ {
+		length[i], previous[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if pairs[j].from < pairs[i].from && length[j]+1 > length[i] {
+				length[i], previous[i] = length[j]+1, j
+			}
+		}
+		if best < 0 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	stable := make([]bool, n)
+	for i := best; i >= 0; i = previous[i] {
+		stable[i] = true
+	}
+	return stable
+}
+
+// positionsInPhase returns the 1-based position of each task within its phase
+func positionsInPhase(tasks []Task, phase string) map[int]int {
+	positions := make(map[int]int)
+	position := 0
+	for i, task := range tasks {
+		if task.Phase == phase {
+			position++
+			positions[i] = position
+		}
+	}
+	return positions
+}
+
+// countUnchangedReordered counts the reordered tasks that had no other change,
+// so they are not reported as unchanged
+func countUnchangedReordered(diff *PlanDiff, pairs []pair, from, to []Task) int {
+	if len(diff.Reordered) == 0 {
+		return 0
+	}
+	reordered := make(map[string]bool, len(diff.Reordered))
+	for _, r := range diff.Reordered {
+		reordered[r.Task.ID] = true
+	}
+
+	count := 0
+	for _, p := range pairs {
+		before, after := from[p.from], to[p.to]
+		if reordered[after.ID] && before.Phase == after.Phase && before.Status == after.Status &&
+			normalizeTitle(before.Title) == normalizeTitle(after.Title) {
+			count++
+		}
+	}
+	return count
+}
+
+// ref converts a task to a TaskRef
+func ref(task Task) TaskRef {
+	return TaskRef{ID: task.ID, Title: task.Title, Phase: task.Phase, Status: task.Status}
+}
+
+// normalizeTitle lowercases a title and collapses its spaces
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// titleSimilarity is the Jaccard index of the words of two titles
+func titleSimilarity(a, b string) float64 {
+	wordsA, wordsB := titleWords(a), titleWords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - common
+	return float64(common) / float64(union)
+}
+
+// titleWords returns the set of lowercase words of a title
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
@@ -0,0 +1,125 @@
+package plandiff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const planV1 = `# Plan de développement v55
+
+**Version 1.0** — Progression: 25%
+
+## Phase 1: Setup
+
+- [x] ✅ 1.1 Create repository
+- [ ] 1.2 Configure CI pipeline
+- [ ] Write contributor guide
+- [ ] Document the API
+
+` + "```markdown\n- [ ] 9.9 Not a task\n```" + `
+
+## Phase 2: Build
+
+- [ ] 2.1 Implement parser
+- [ ] 2.2 Implement renderer
+- [ ] 2.3 Ship release
+`
+
+const planV2 = `# Plan de développement v55
+
+**Version 1.1** — Progression: 50%
+
+## Phase 1: Setup
+
+- [x] ✅ 1.1 Create repository
+- [ ] Write the contributor guide
+- [ ] Document the API
+
+## Phase 2: Build
+
+- [ ] 2.2 Implement renderer
+- [x] 2.1 Implement parser
+- [ ] 1.2 Configure CI pipeline
+- [ ] 2.4 Benchmark parser
+`
+
+func parse(t *testing.T, content string) *Plan {
+	t.Helper()
+	plan, err := ParseMarkdown(strings.NewReader(content), "plans/plan-dev-v55.md")
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	return plan
+}
+
+func TestParseMarkdown(t *testing.T) {
+	plan := parse(t, planV1)
+
+	if plan.ID != "plan-dev-v55" || plan.Title != "Plan de développement v55" || plan.Version != "1.0" || plan.Progression != 25 {
+		t.Errorf("unexpected plan metadata: %+v", plan)
+	}
+	if len(plan.Tasks) != 7 {
+		t.Fatalf("expected 7 tasks (code blocks ignored), got %d", len(plan.Tasks))
+	}
+
+	first := plan.Tasks[0]
+	if first.ID != "1.1" || first.Title != "1.1 Create repository" || first.Phase != "Phase 1: Setup" || first.Status != "completed" {
+		t.Errorf("unexpected first task: %+v", first)
+	}
+	if plan.Tasks[2].ID != "write-contributor-guide" {
+		t.Errorf("expected a slug ID for an unnumbered task, got %q", plan.Tasks[2].ID)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff(parse(t, planV1), parse(t, planV2))
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "2.4" {
+		t.Errorf("expected 2.4 added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "2.3" {
+		t.Errorf("expected 2.3 removed, got %+v", diff.Removed)
+	}
+	if len(diff.Moved) != 1 || diff.Moved[0].Task.ID != "1.2" || diff.Moved[0].ToPhase != "Phase 2: Build" {
+		t.Errorf("expected 1.2 moved to phase 2, got %+v", diff.Moved)
+	}
+	if len(diff.Renamed) != 1 || diff.Renamed[0].FromID != "write-contributor-guide" {
+		t.Errorf("expected the contributor guide renamed, got %+v", diff.Renamed)
+	}
+	if len(diff.StatusChanged) != 1 || diff.StatusChanged[0].Task.ID != "2.1" || diff.StatusChanged[0].To != "completed" {
+		t.Errorf("expected 2.1 completed, got %+v", diff.StatusChanged)
+	}
+	if len(diff.Reordered) != 1 {
+		t.Errorf("expected one reordered task, got %+v", diff.Reordered)
+	}
+	if len(diff.MetadataChanges) != 2 {
+		t.Errorf("expected version and progression changes, got %+v", diff.MetadataChanges)
+	}
+
+	report := diff.Markdown()
+	for _, section := range []string{"## Added tasks", "## Removed tasks", "## Moved tasks", "## Renamed tasks", "## Status changes", "## Reordered tasks"} {
+		if !strings.Contains(report, section) {
+			t.Errorf("Markdown report is missing %q:\n%s", section, report)
+		}
+	}
+
+	data, err := diff.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PlanDiff
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Summary != diff.Summary {
+		t.Errorf("JSON round trip failed: %v", err)
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	diff := Diff(parse(t, planV1), parse(t, planV1))
+	if !diff.IsEmpty() || diff.Summary.Similarity != 1 || diff.Summary.Unchanged != 7 {
+		t.Errorf("expected no differences, got %s", diff)
+	}
+	if !strings.Contains(diff.Markdown(), "No differences.") {
+		t.Error("expected an empty report")
+	}
+}
@@ -0,0 +1,386 @@
+// Package planembed indexes development plans in Qdrant with one embedding
+// per task, so that similarity searches return tasks (and the plans they
+// belong to) instead of a single coarse vector per plan.
+package planembed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"email_sender/planning-ecosystem-sync/pkg/qdrant"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
+
+	"go.uber.org/zap"
+)
+
+// DefaultCollection is the collection holding task embeddings
+const DefaultCollection = "plan_tasks"
+
+// Plan is the plan content to embed
+type Plan struct {
+	ID       string
+	Title    string
+	Version  string
+	FilePath string
+	Tasks    []Task
+}
+
+// Task is a task of a plan
+type Task struct {
+	ID          string
+	Title       string
+	Description string
+	Phase       string
+	Status      string
+}
+
+// Chunk is the embedded text of a single task
+type Chunk struct {
+	PlanID string
+	TaskID string
+	Text   string
+}
+
+// TaskHit is a task returned by a similarity search
+type TaskHit struct {
+	PlanID string                 `json:"plan_id"`
+	TaskID string                 `json:"task_id"`
+	Score  float32                `json:"score"`
+	Data   map[string]interface{} `json:"payload"`
+}
+
+// PlanHit is a plan returned by a similarity search, scored by its best task
+type PlanHit struct {
+	PlanID  string   `json:"plan_id"`
+	Title   string   `json:"title"`
+	Score   float32  `json:"score"`
+	TaskIDs []string `json:"task_ids"` // Matching tasks, best first
+}
+
+// VectorStore is the subset of the Qdrant client used for plan embeddings.
+// It is implemented by qdrant.UnifiedClient.
+type VectorStore interface {
+	CreateCollection(ctx context.Context, name string, config qdrant.CollectionConfig) error
+	GetCollectionInfo(ctx context.Context, name string) (*qdrant.CollectionInfo, error)
+	UpsertPoints(ctx context.Context, collection string, points []qdrant.Point) error
+	SearchPoints(ctx context.Context, collection string, req qdrant.SearchRequest) (*qdrant.SearchResponse, error)
+	ScrollPoints(ctx context.Context, collection string, req qdrant.ScrollRequest) (*qdrant.ScrollResponse, error)
+	DeletePoints(ctx context.Context, collection string, filter map[string]interface{}) error
+	DeleteCollection(ctx context.Context, name string) error
+	HealthCheck(ctx context.Context) error
+}
+
+// Indexer embeds plan tasks and stores them in a collection
+type Indexer struct {
+	store      VectorStore
+	embedder   vectorization.EmbeddingClient
+	collection string
+	logger     *zap.Logger
+}
+
+// NewIndexer creates a plan indexer
+func NewIndexer(store VectorStore, embedder vectorization.EmbeddingClient, collection string, logger *zap.Logger) *Indexer {
+	if collection == "" {
+		collection = DefaultCollection
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Indexer{
+		store:      store,
+		embedder:   embedder,
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// Collection returns the collection holding the task embeddings
+func (ix *Indexer) Collection() string {
+	return ix.collection
+}
+
+// EnsureCollection creates the collection if needed. An existing collection
+// with another dimension is reported: its points must be migrated with Reembed.
+func (ix *Indexer) EnsureCollection(ctx context.Context) error {
+	model, err := ModelInfo(ctx, ix.embedder)
+	if err != nil {
+		return err
+	}
+	return ensureCollection(ctx, ix.store, ix.collection, model.Dimension)
+}
+
+// ChunkPlan splits a plan into one chunk per task. Each chunk carries the
+// plan title and the task phase so that it can be understood on its own.
+func ChunkPlan(plan Plan) []Chunk {
+	chunks := make([]Chunk, 0, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		var text strings.Builder
+		text.WriteString(plan.Title)
+		if task.Phase != "" {
+			text.WriteString("\n")
+			text.WriteString(task.Phase)
+		}
+		text.WriteString("\n")
+		text.WriteString(task.Title)
+		if task.Description != "" {
+			text.WriteString("\n")
+			text.WriteString(task.Description)
+		}
+
+		chunks = append(chunks, Chunk{PlanID: plan.ID, TaskID: task.ID, Text: text.String()})
+	}
+	return chunks
+}
+
+// EmbedPlan returns the chunks of a plan and their embeddings
+func (ix *Indexer) EmbedPlan(ctx context.Context, plan Plan) ([]Chunk, [][]float32, error) {
+	chunks := ChunkPlan(plan)
+	if len(chunks) == 0 {
+		return nil, nil, nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	vectors, err := ix.embedder.BatchGenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to embed plan %s: %w", plan.ID, err)
+	}
+	return chunks, vectors, nil
+}
+
+// IndexPlan embeds the tasks of a plan and stores them
+func (ix *Indexer) IndexPlan(ctx context.Context, plan Plan) (int, error) {
+	chunks, vectors, err := ix.EmbedPlan(ctx, plan)
+	if err != nil {
+		return 0, err
+	}
+	if err := ix.StoreChunks(ctx, plan, chunks, vectors); err != nil {
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
+// StoreChunks upserts the task embeddings of a plan, then removes the points
+// of tasks that no longer exist in it
+func (ix *Indexer) StoreChunks(ctx context.Context, plan Plan, chunks []Chunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("got %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+
+	model := ix.embedder.GetModelInfo()
+	tasks := make(map[string]Task, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		tasks[task.ID] = task
+	}
+
+	now := time.Now().Unix()
+	points := make([]qdrant.Point, len(chunks))
+	taskIDs := make([]interface{}, len(chunks))
+	for i, chunk := range chunks {
+		task := tasks[chunk.TaskID]
+		points[i] = qdrant.Point{
+			ID:     PointID(chunk.PlanID, chunk.TaskID),
+			Vector: vectors[i],
+			Payload: map[string]interface{}{
+				"plan_id":             plan.ID,
+				"plan_title":          plan.Title,
+				"plan_version":        plan.Version,
+				"file_path":           plan.FilePath,
+				"task_id":             chunk.TaskID,
+				"task_title":          task.Title,
+				"phase":               task.Phase,
+				"status":              task.Status,
+				"text":                chunk.Text,
+				"embedding_model":     model.Name,
+				"embedding_dimension": len(vectors[i]),
+				"indexed_at":          now,
+			},
+		}
+		taskIDs[i] = chunk.TaskID
+	}
+
+	if err := ix.store.UpsertPoints(ctx, ix.collection, points); err != nil {
+		return fmt.Errorf("failed to store task embeddings of plan %s: %w", plan.ID, err)
+	}
+
+	stale := map[string]interface{}{
+		"must": []interface{}{matchValue("plan_id", plan.ID)},
+	}
+	if len(taskIDs) > 0 {
+		stale["must_not"] = []interface{}{
+			map[string]interface{}{"key": "task_id", "match": map[string]interface{}{"any": taskIDs}},
+		}
+	}
+	if err := ix.store.DeletePoints(ctx, ix.collection, stale); err != nil {
+		return fmt.Errorf("failed to remove stale tasks of plan %s: %w", plan.ID, err)
+	}
+
+	ix.logger.Info("Indexed plan tasks",
+		zap.String("plan_id", plan.ID),
+		zap.Int("tasks", len(points)),
+		zap.String("model", model.Name))
+	return nil
+}
+
+// DeletePlan removes every task embedding of a plan
+func (ix *Indexer) DeletePlan(ctx context.Context, planID string) error {
+	filter := map[string]interface{}{
+		"must": []interface{}{matchValue("plan_id", planID)},
+	}
+	return ix.store.DeletePoints(ctx, ix.collection, filter)
+}
+
+// SearchTasks returns the tasks closest to a query vector
+func (ix *Indexer) SearchTasks(ctx context.Context, vector []float32, limit int) ([]TaskHit, error) {
+	response, err := ix.store.SearchPoints(ctx, ix.collection, qdrant.SearchRequest{
+		Vector:      vector,
+		Limit:       limit,
+		WithPayload: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("task search failed: %w", err)
+	}
+
+	hits := make([]TaskHit, 0, len(response.Result))
+	for _, point := range response.Result {
+		planID, _ := point.Payload["plan_id"].(string)
+		taskID, _ := point.Payload["task_id"].(string)
+		hits = append(hits, TaskHit{PlanID: planID, TaskID: taskID, Score: point.Score, Data: point.Payload})
+	}
+	return hits, nil
+}
+
+// SearchText embeds a query and returns the closest tasks
+func (ix *Indexer) SearchText(ctx context.Context, query string, limit int) ([]TaskHit, error) {
+	vector, err := ix.embedder.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return ix.SearchTasks(ctx, vector, limit)
+}
+
+// SearchPlans returns the plans whose tasks are closest to a query vector.
+// A plan is scored by its best matching task.
+func (ix *Indexer) SearchPlans(ctx context.Context, vector []float32, limit int) ([]PlanHit, error) {
+	hits, err := ix.SearchTasks(ctx, vector, limit*5)
+	if err != nil {
+		return nil, err
+	}
+	return GroupByPlan(hits, limit), nil
+}
+
+// GroupByPlan aggregates task hits by plan, keeping the best score of each plan
+func GroupByPlan(hits []TaskHit, limit int) []PlanHit {
+	index := make(map[string]int)
+	var plans []PlanHit
+	for _, hit := range hits {
+		i, exists := index[hit.PlanID]
+		if !exists {
+			title, _ := hit.Data["plan_title"].(string)
+			i = len(plans)
+			index[hit.PlanID] = i
+			plans = append(plans, PlanHit{PlanID: hit.PlanID, Title: title, Score: hit.Score})
+		}
+		if hit.Score > plans[i].Score {
+			plans[i].Score = hit.Score
+		}
+		plans[i].TaskIDs = append(plans[i].TaskIDs, hit.TaskID)
+	}
+
+	sort.SliceStable(plans, func(a, b int) bool { return plans[a].Score > plans[b].Score })
+	if limit > 0 && len(plans) > limit {
+		plans = plans[:limit]
+	}
+	return plans
+}
+
+// Centroid returns the normalized mean of task vectors, used as the
+// plan-level embedding
+func Centroid(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	centroid := make([]float32, len(vectors[0]))
+	for _, vector := range vectors {
+		for i := range centroid {
+			if i < len(vector) {
+				centroid[i] += vector[i]
+			}
+		}
+	}
+
+	var norm float32
+	for _, v := range centroid {
+		norm += v * v
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(float64(norm)))
+		for i := range centroid {
+			centroid[i] *= scale
+		}
+	}
+	return centroid
+}
+
+// ModelInfo returns the model of an embedder. Remote providers only learn
+// their dimension from a response, so one probe embedding is requested when
+// it is still unknown.
+func ModelInfo(ctx context.Context, embedder vectorization.EmbeddingClient) (vectorization.ModelInfo, error) {
+	model := embedder.GetModelInfo()
+	if model.Dimension > 0 {
+		return model, nil
+	}
+
+	probe, err := embedder.GenerateEmbedding(ctx, "dimension probe")
+	if err != nil {
+		return model, fmt.Errorf("failed to determine embedding dimension: %w", err)
+	}
+	model = embedder.GetModelInfo()
+	model.Dimension = len(probe)
+	return model, nil
+}
+
+// PointID derives a stable Qdrant point ID (a UUID) from a plan and task ID
+func PointID(planID, taskID string) string {
+	sum := sha256.Sum256([]byte(planID + "\x00" + taskID))
+	id := hex.EncodeToString(sum[:16])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+// ensureCollection creates a collection with the given dimension, or checks
+// the dimension of the existing one
+func ensureCollection(ctx context.Context, store VectorStore, name string, dimension int) error {
+	info, err := store.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return err
+	}
+	if info != nil {
+		if info.VectorSize != dimension {
+			return fmt.Errorf("collection %s stores %d-dimensional vectors but the model produces %d: run reembed to migrate it",
+				name, info.VectorSize, dimension)
+		}
+		return nil
+	}
+
+	return store.CreateCollection(ctx, name, qdrant.CollectionConfig{
+		VectorSize:   dimension,
+		Distance:     "Cosine",
+		ReplicaCount: 1,
+		ShardNumber:  1,
+	})
+}
+
+// matchValue builds a Qdrant field condition
+func matchValue(key string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"key": key, "match": map[string]interface{}{"value": value}}
+}
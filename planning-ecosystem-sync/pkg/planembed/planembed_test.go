@@ -0,0 +1,259 @@
+package planembed
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"email_sender/planning-ecosystem-sync/pkg/qdrant"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
+)
+
+// memoryStore is an in-memory VectorStore supporting the filters used by the indexer
+type memoryStore struct {
+	collections map[string]int                     // Name -> vector size
+	points      map[string]map[string]qdrant.Point // Collection -> point ID -> point
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{collections: map[string]int{}, points: map[string]map[string]qdrant.Point{}}
+}
+
+func (m *memoryStore) CreateCollection(ctx context.Context, name string, config qdrant.CollectionConfig) error {
+	m.collections[name] = config.VectorSize
+	m.points[name] = map[string]qdrant.Point{}
+	return nil
+}
+
+func (m *memoryStore) GetCollectionInfo(ctx context.Context, name string) (*qdrant.CollectionInfo, error) {
+	size, exists := m.collections[name]
+	if !exists {
+		return nil, nil
+	}
+	return &qdrant.CollectionInfo{VectorSize: size, PointsCount: len(m.points[name])}, nil
+}
+
+func (m *memoryStore) UpsertPoints(ctx context.Context, collection string, points []qdrant.Point) error {
+	for _, point := range points {
+		if len(point.Vector) != m.collections[collection] {
+			return fmt.Errorf("wrong dimension %d for %s", len(point.Vector), collection)
+		}
+		payload := map[string]interface{}{}
+		for k, v := range point.Payload {
+			payload[k] = v
+		}
+		point.Payload = payload
+		m.points[collection][fmt.Sprint(point.ID)] = point
+	}
+	return nil
+}
+
+func (m *memoryStore) SearchPoints(ctx context.Context, collection string, req qdrant.SearchRequest) (*qdrant.SearchResponse, error) {
+	var result []qdrant.ScoredPoint
+	for _, point := range m.points[collection] {
+		var score float32
+		for i := range req.Vector {
+			score += req.Vector[i] * point.Vector[i]
+		}
+		result = append(result, qdrant.ScoredPoint{ID: point.ID, Score: score, Payload: point.Payload})
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Score > result[b].Score })
+	if len(result) > req.Limit {
+		result = result[:req.Limit]
+	}
+	return &qdrant.SearchResponse{Result: result}, nil
+}
+
+func (m *memoryStore) ScrollPoints(ctx context.Context, collection string, req qdrant.ScrollRequest) (*qdrant.ScrollResponse, error) {
+	ids := make([]string, 0, len(m.points[collection]))
+	for id := range m.points[collection] {
+		if req.Offset == nil || id >= req.Offset.(string) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	response := &qdrant.ScrollResponse{}
+	for i, id := range ids {
+		if i == req.Limit {
+			response.NextOffset = id
+			break
+		}
+		response.Points = append(response.Points, m.points[collection][id])
+	}
+	return response, nil
+}
+
+func (m *memoryStore) DeletePoints(ctx context.Context, collection string, filter map[string]interface{}) error {
+	for id, point := range m.points[collection] {
+		if matches(point.Payload, filter) {
+			delete(m.points[collection], id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) DeleteCollection(ctx context.Context, name string) error {
+	delete(m.collections, name)
+	delete(m.points, name)
+	return nil
+}
+
+func (m *memoryStore) HealthCheck(ctx context.Context) error { return nil }
+
+// matches evaluates the must/must_not conditions built by the indexer
+func matches(payload map[string]interface{}, filter map[string]interface{}) bool {
+	condition := func(c interface{}) bool {
+		cond := c.(map[string]interface{})
+		match := cond["match"].(map[string]interface{})
+		value := payload[cond["key"].(string)]
+		if any, ok := match["any"].([]interface{}); ok {
+			for _, v := range any {
+				if v == value {
+					return true
+				}
+			}
+			return false
+		}
+		return match["value"] == value
+	}
+	for _, c := range filter["must"].([]interface{}) {
+		if !condition(c) {
+			return false
+		}
+	}
+	if mustNot, ok := filter["must_not"].([]interface{}); ok {
+		for _, c := range mustNot {
+			if condition(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func testPlan() Plan {
+	return Plan{
+		ID:    "plan-v55",
+		Title: "Planning ecosystem sync",
+		Tasks: []Task{
+			{ID: "1.1", Title: "Parse markdown plans", Phase: "Phase 1"},
+			{ID: "1.2", Title: "Store plans in PostgreSQL", Phase: "Phase 1"},
+			{ID: "2.1", Title: "Detect synchronization conflicts", Phase: "Phase 2"},
+		},
+	}
+}
+
+func TestIndexPlan(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	indexer := NewIndexer(store, vectorization.NewHashingEmbeddingClient(64), "", nil)
+
+	if err := indexer.EnsureCollection(ctx); err != nil {
+		t.Fatalf("EnsureCollection failed: %v", err)
+	}
+	plan := testPlan()
+	if count, err := indexer.IndexPlan(ctx, plan); err != nil || count != 3 {
+		t.Fatalf("IndexPlan: %d, %v", count, err)
+	}
+
+	// One point per task, with its task ID in the payload
+	point, exists := store.points[DefaultCollection][PointID("plan-v55", "2.1")]
+	if !exists || point.Payload["task_id"] != "2.1" || point.Payload["phase"] != "Phase 2" {
+		t.Fatalf("missing task point: %+v", point)
+	}
+
+	hits, err := indexer.SearchText(ctx, "synchronization conflicts", 1)
+	if err != nil || len(hits) != 1 || hits[0].TaskID != "2.1" {
+		t.Errorf("expected task 2.1 as best match, got %+v (%v)", hits, err)
+	}
+
+	// Removed tasks are dropped from the collection on re-indexing
+	plan.Tasks = plan.Tasks[:2]
+	if _, err := indexer.IndexPlan(ctx, plan); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.points[DefaultCollection]) != 2 {
+		t.Errorf("expected stale task to be removed, got %d points", len(store.points[DefaultCollection]))
+	}
+
+	// Another dimension is refused on the existing collection
+	other := NewIndexer(store, vectorization.NewHashingEmbeddingClient(32), "", nil)
+	if err := other.EnsureCollection(ctx); err == nil || !strings.Contains(err.Error(), "reembed") {
+		t.Errorf("expected a dimension mismatch error, got %v", err)
+	}
+}
+
+func TestReembed(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	indexer := NewIndexer(store, vectorization.NewHashingEmbeddingClient(64), "", nil)
+	if err := indexer.EnsureCollection(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := indexer.IndexPlan(ctx, testPlan()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same model in place: nothing to do
+	report, err := Reembed(ctx, store, vectorization.NewHashingEmbeddingClient(64), ReembedOptions{BatchSize: 2}, nil)
+	if err != nil || report.Skipped != 3 || report.Reembedded != 0 {
+		t.Fatalf("unexpected in-place report: %+v (%v)", report, err)
+	}
+
+	// Dimension change in place is refused
+	newModel := vectorization.NewHashingEmbeddingClient(128)
+	if _, err := Reembed(ctx, store, newModel, ReembedOptions{}, nil); err == nil {
+		t.Fatal("expected in-place dimension change to fail")
+	}
+
+	// Dry run counts without writing
+	report, err = Reembed(ctx, store, newModel, ReembedOptions{Target: "plan_tasks_128", DryRun: true}, nil)
+	if err != nil || report.Reembedded != 3 {
+		t.Fatalf("unexpected dry-run report: %+v (%v)", report, err)
+	}
+	if _, exists := store.collections["plan_tasks_128"]; exists {
+		t.Fatal("dry run must not create the target collection")
+	}
+
+	// Migration to a new collection
+	report, err = Reembed(ctx, store, newModel, ReembedOptions{Target: "plan_tasks_128", BatchSize: 2, DeleteSource: true}, nil)
+	if err != nil || report.Reembedded != 3 || len(report.Failed) != 0 {
+		t.Fatalf("unexpected migration report: %+v (%v)", report, err)
+	}
+	if _, exists := store.collections[DefaultCollection]; exists {
+		t.Error("source collection should be deleted")
+	}
+	point := store.points["plan_tasks_128"][PointID("plan-v55", "1.1")]
+	if len(point.Vector) != 128 || point.Payload["embedding_model"] != newModel.GetModelInfo().Name {
+		t.Errorf("point not migrated: dimension %d, payload %+v", len(point.Vector), point.Payload)
+	}
+
+	// Points indexed without their text are reported, not failed, and keep
+	// the source collection
+	legacy := store.points["plan_tasks_128"][PointID("plan-v55", "1.2")]
+	delete(legacy.Payload, "text")
+	store.points["plan_tasks_128"][PointID("plan-v55", "1.2")] = legacy
+	report, err = Reembed(ctx, store, vectorization.NewHashingEmbeddingClient(64),
+		ReembedOptions{Source: "plan_tasks_128", Target: "plan_tasks_64", DeleteSource: true}, nil)
+	if err != nil || report.Reembedded != 2 || len(report.Failed) != 0 || len(report.MissingText) != 1 {
+		t.Fatalf("unexpected report with a missing text: %+v (%v)", report, err)
+	}
+	if _, exists := store.collections["plan_tasks_128"]; !exists {
+		t.Error("source collection should be kept while points miss their text")
+	}
+}
+
+func TestGroupByPlan(t *testing.T) {
+	hits := []TaskHit{
+		{PlanID: "a", TaskID: "1", Score: 0.9},
+		{PlanID: "b", TaskID: "1", Score: 0.8},
+		{PlanID: "a", TaskID: "2", Score: 0.7},
+	}
+	plans := GroupByPlan(hits, 10)
+	if len(plans) != 2 || plans[0].PlanID != "a" || len(plans[0].TaskIDs) != 2 {
+		t.Errorf("unexpected grouping: %+v", plans)
+	}
+}
@@ -0,0 +1,210 @@
+package planembed
+
+import (
+	"context"
+	"fmt"
+
+	"email_sender/planning-ecosystem-sync/pkg/qdrant"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
+
+	"go.uber.org/zap"
+)
+
+// ReembedOptions configures a migration of stored embeddings to a new model
+type ReembedOptions struct {
+	Source       string // Collection to migrate
+	Target       string // Destination collection; defaults to Source
+	BatchSize    int
+	Force        bool // Re-embed points already produced by the current model
+	DryRun       bool // Count the points to migrate without writing anything
+	DeleteSource bool // Drop Source once every point reached Target
+}
+
+// ReembedReport summarizes a migration
+type ReembedReport struct {
+	Source     string   `json:"source"`
+	Target     string   `json:"target"`
+	Model      string   `json:"model"`
+	Dimension  int      `json:"dimension"`
+	Scanned    int      `json:"scanned"`
+	Reembedded int      `json:"reembedded"`
+	Copied     int      `json:"copied"`  // Up-to-date points copied as is to Target
+	Skipped    int      `json:"skipped"` // Up-to-date points left in place
+	Failed     []string `json:"failed,omitempty"`
+	// Points without a "text" payload, indexed before the text was stored:
+	// they are left as is and their plan must be indexed again
+	MissingText []string `json:"missing_text,omitempty"`
+	DryRun      bool     `json:"dry_run"`
+}
+
+// Reembed recomputes the embeddings of a collection with the current model.
+// Points store the embedded text in their payload, so they are re-embedded
+// without going back to the plans; points without that text are reported in
+// MissingText and left untouched. A dimension change requires a new target
+// collection, since a Qdrant collection has a fixed vector size.
+func Reembed(ctx context.Context, store VectorStore, embedder vectorization.EmbeddingClient, opts ReembedOptions, logger *zap.Logger) (*ReembedReport, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if opts.Source == "" {
+		opts.Source = DefaultCollection
+	}
+	if opts.Target == "" {
+		opts.Target = opts.Source
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 64
+	}
+	if opts.DeleteSource && opts.Target == opts.Source {
+		return nil, fmt.Errorf("cannot delete the source collection when migrating in place")
+	}
+
+	model, err := ModelInfo(ctx, embedder)
+	if err != nil {
+		return nil, err
+	}
+	report := &ReembedReport{
+		Source:    opts.Source,
+		Target:    opts.Target,
+		Model:     model.Name,
+		Dimension: model.Dimension,
+		DryRun:    opts.DryRun,
+	}
+
+	source, err := store.GetCollectionInfo(ctx, opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("collection %s does not exist", opts.Source)
+	}
+	inPlace := opts.Target == opts.Source
+	if inPlace && source.VectorSize != model.Dimension {
+		return nil, fmt.Errorf("model %s produces %d-dimensional vectors but %s stores %d: migrate to a new target collection",
+			model.Name, model.Dimension, opts.Source, source.VectorSize)
+	}
+	if !inPlace && !opts.DryRun {
+		if err := ensureCollection(ctx, store, opts.Target, model.Dimension); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Re-embedding collection",
+		zap.String("source", opts.Source),
+		zap.String("target", opts.Target),
+		zap.String("model", model.Name),
+		zap.Int("dimension", model.Dimension),
+		zap.Bool("dry_run", opts.DryRun))
+
+	var offset interface{}
+	for {
+		page, err := store.ScrollPoints(ctx, opts.Source, qdrant.ScrollRequest{
+			Limit:       opts.BatchSize,
+			Offset:      offset,
+			WithPayload: true,
+			WithVector:  !inPlace,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		if err := reembedPage(ctx, store, embedder, model, page.Points, inPlace, opts, report); err != nil {
+			return report, err
+		}
+
+		if page.NextOffset == nil || len(page.Points) == 0 {
+			break
+		}
+		offset = page.NextOffset
+	}
+
+	if opts.DeleteSource && !opts.DryRun {
+		if len(report.Failed) > 0 || len(report.MissingText) > 0 {
+			logger.Warn("Source collection kept: some points could not be migrated",
+				zap.Int("failed", len(report.Failed)),
+				zap.Int("missing_text", len(report.MissingText)))
+		} else if err := store.DeleteCollection(ctx, opts.Source); err != nil {
+			return report, fmt.Errorf("failed to delete source collection: %w", err)
+		}
+	}
+
+	logger.Info("Re-embedding completed",
+		zap.Int("scanned", report.Scanned),
+		zap.Int("reembedded", report.Reembedded),
+		zap.Int("copied", report.Copied),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("failed", len(report.Failed)),
+		zap.Int("missing_text", len(report.MissingText)))
+	return report, nil
+}
+
+// reembedPage migrates one page of points
+func reembedPage(ctx context.Context, store VectorStore, embedder vectorization.EmbeddingClient, model vectorization.ModelInfo,
+	points []qdrant.Point, inPlace bool, opts ReembedOptions, report *ReembedReport) error {
+	var outdated []qdrant.Point
+	var texts []string
+	var copies []qdrant.Point
+
+	for _, point := range points {
+		report.Scanned++
+
+		current := point.Payload["embedding_model"] == model.Name && payloadInt(point.Payload["embedding_dimension"]) == model.Dimension
+		if current && !opts.Force {
+			if inPlace {
+				report.Skipped++
+			} else {
+				copies = append(copies, point)
+			}
+			continue
+		}
+
+		text, _ := point.Payload["text"].(string)
+		if text == "" {
+			report.MissingText = append(report.MissingText, fmt.Sprint(point.ID))
+			continue
+		}
+		outdated = append(outdated, point)
+		texts = append(texts, text)
+	}
+
+	if opts.DryRun {
+		report.Reembedded += len(outdated)
+		report.Copied += len(copies)
+		return nil
+	}
+
+	if len(texts) > 0 {
+		vectors, err := embedder.BatchGenerateEmbeddings(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to re-embed points: %w", err)
+		}
+		for i := range outdated {
+			outdated[i].Vector = vectors[i]
+			outdated[i].Payload["embedding_model"] = model.Name
+			outdated[i].Payload["embedding_dimension"] = len(vectors[i])
+		}
+	}
+
+	migrated := append(outdated, copies...)
+	if len(migrated) == 0 {
+		return nil
+	}
+	if err := store.UpsertPoints(ctx, opts.Target, migrated); err != nil {
+		return fmt.Errorf("failed to store re-embedded points: %w", err)
+	}
+	report.Reembedded += len(outdated)
+	report.Copied += len(copies)
+	return nil
+}
+
+// payloadInt reads a JSON number from a payload
+func payloadInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -66,6 +66,38 @@ type ScoredPoint struct {
 	Payload map[string]interface{} `json:"payload,omitempty"`
 }
 
+// ScrollRequest pages through the points of a collection
+type ScrollRequest struct {
+	Limit       int                    `json:"limit"`
+	Offset      interface{}            `json:"offset,omitempty"` // NextOffset of the previous page
+	Filter      map[string]interface{} `json:"filter,omitempty"`
+	WithPayload bool                   `json:"with_payload"`
+	WithVector  bool                   `json:"with_vector"`
+}
+
+// ScrollResponse is a page of points; NextOffset is nil on the last page
+type ScrollResponse struct {
+	Points     []Point     `json:"points"`
+	NextOffset interface{} `json:"next_page_offset"`
+}
+
+// CollectionInfo describes an existing collection
+type CollectionInfo struct {
+	Status      string `json:"status"`
+	PointsCount int    `json:"points_count"`
+	VectorSize  int    `json:"vector_size"`
+}
+
+// StatusError is returned when Qdrant answers with an HTTP error status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
 // UnifiedClient implements the QdrantInterface with advanced features
 // Phase 2.1.1: Architecture du Client de Référence
 type UnifiedClient struct {
@@ -254,6 +286,70 @@ func (c *UnifiedClient) SearchPoints(ctx context.Context, collection string, req
 	return &response, nil
 }
 
+// GetCollectionInfo describes a collection; it returns nil when the collection does not exist
+func (c *UnifiedClient) GetCollectionInfo(ctx context.Context, name string) (*CollectionInfo, error) {
+	url := fmt.Sprintf("%s/collections/%s", c.baseURL, name)
+
+	var response struct {
+		Result struct {
+			Status      string `json:"status"`
+			PointsCount int    `json:"points_count"`
+			Config      struct {
+				Params struct {
+					Vectors struct {
+						Size int `json:"size"`
+					} `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
+	}
+
+	err := c.executeRequest(ctx, "GET", url, nil, &response)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection %s: %w", name, err)
+	}
+
+	return &CollectionInfo{
+		Status:      response.Result.Status,
+		PointsCount: response.Result.PointsCount,
+		VectorSize:  response.Result.Config.Params.Vectors.Size,
+	}, nil
+}
+
+// ScrollPoints returns a page of the points of a collection
+func (c *UnifiedClient) ScrollPoints(ctx context.Context, collection string, req ScrollRequest) (*ScrollResponse, error) {
+	c.logger.Debug("Scrolling points",
+		zap.String("collection", collection),
+		zap.Int("limit", req.Limit))
+
+	url := fmt.Sprintf("%s/collections/%s/points/scroll", c.baseURL, collection)
+
+	var response struct {
+		Result ScrollResponse `json:"result"`
+	}
+	if err := c.executeWithRetry(ctx, "POST", url, req, &response); err != nil {
+		return nil, fmt.Errorf("scroll failed: %w", err)
+	}
+
+	return &response.Result, nil
+}
+
+// DeletePoints deletes the points of a collection matching a filter
+func (c *UnifiedClient) DeletePoints(ctx context.Context, collection string, filter map[string]interface{}) error {
+	c.logger.Info("Deleting points", zap.String("collection", collection))
+
+	payload := map[string]interface{}{
+		"filter": filter,
+	}
+
+	url := fmt.Sprintf("%s/collections/%s/points/delete", c.baseURL, collection)
+	return c.executeWithRetry(ctx, "POST", url, payload, nil)
+}
+
 // DeleteCollection deletes a vector collection
 // Phase 2.1.1.1.2: Implémenter les méthodes de base
 func (c *UnifiedClient) DeleteCollection(ctx context.Context, name string) error {
@@ -372,7 +468,7 @@ func (c *UnifiedClient) executeRequest(ctx context.Context, method, url string,
 	// Phase 2.1.1.1.3: Standardized error handling
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if response != nil {
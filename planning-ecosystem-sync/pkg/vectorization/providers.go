@@ -0,0 +1,226 @@
+package vectorization
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"email_sender/development/managers/roadmap-manager/roadmap-cli/rag"
+)
+
+// EmbeddingConfig selects and configures an embedding provider
+type EmbeddingConfig struct {
+	Provider  string        `json:"provider" yaml:"provider"` // "hashing" (local) or "openai" (any OpenAI-compatible /embeddings API)
+	BaseURL   string        `json:"base_url" yaml:"base_url"`
+	Model     string        `json:"model" yaml:"model"`
+	APIKey    string        `json:"api_key" yaml:"api_key"`
+	Dimension int           `json:"dimension" yaml:"dimension"`
+	BatchSize int           `json:"batch_size" yaml:"batch_size"`
+	Timeout   time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// DefaultDimension is the dimension of the local provider, compatible with
+// the sentence-transformers models used elsewhere in the ecosystem
+const DefaultDimension = 384
+
+// NewEmbeddingClient creates the provider described by config
+func NewEmbeddingClient(config EmbeddingConfig) (EmbeddingClient, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", "hashing", "local":
+		return NewHashingEmbeddingClient(config.Dimension), nil
+	case "openai", "http":
+		return NewHTTPEmbeddingClient(config)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s", config.Provider)
+	}
+}
+
+// HashingEmbeddingClient embeds texts locally with the hashing trick of the
+// roadmap RAG provider: words and word bigrams are hashed to signed
+// dimensions, weighted by sublinear term frequency, then the vector is
+// L2-normalized. Texts sharing vocabulary get close vectors, which is enough
+// for lexical similarity without an external service.
+type HashingEmbeddingClient struct {
+	provider *rag.HashingEmbeddingProvider
+}
+
+// NewHashingEmbeddingClient creates a local embedding client
+func NewHashingEmbeddingClient(dimension int) *HashingEmbeddingClient {
+	if dimension <= 0 {
+		dimension = DefaultDimension
+	}
+	return &HashingEmbeddingClient{provider: rag.NewHashingEmbeddingProvider(dimension)}
+}
+
+// GenerateEmbedding embeds a single text
+func (h *HashingEmbeddingClient) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := h.provider.GetEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// BatchGenerateEmbeddings embeds several texts
+func (h *HashingEmbeddingClient) BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	return h.provider.GetEmbeddings(ctx, texts)
+}
+
+// GetModelInfo describes the local model
+func (h *HashingEmbeddingClient) GetModelInfo() ModelInfo {
+	return ModelInfo{
+		Name:      fmt.Sprintf("hashing-v2-%d", h.provider.GetDimensions()),
+		Dimension: h.provider.GetDimensions(),
+		MaxTokens: 0,
+		Language:  "multilingual",
+	}
+}
+
+// HTTPEmbeddingClient calls an OpenAI-compatible embeddings endpoint
+// (OpenAI, Ollama, text-embeddings-inference, LocalAI...)
+type HTTPEmbeddingClient struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	batchSize  int
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	dimension int
+}
+
+// NewHTTPEmbeddingClient creates a client for an embeddings API
+func NewHTTPEmbeddingClient(config EmbeddingConfig) (*HTTPEmbeddingClient, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("embedding provider base URL is required")
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("embedding model is required")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 64
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+
+	return &HTTPEmbeddingClient{
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		model:      config.Model,
+		apiKey:     config.APIKey,
+		batchSize:  config.BatchSize,
+		dimension:  config.Dimension,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// GenerateEmbedding embeds a single text
+func (c *HTTPEmbeddingClient) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := c.BatchGenerateEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// BatchGenerateEmbeddings embeds texts in batches of the configured size
+func (c *HTTPEmbeddingClient) BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += c.batchSize {
+		end := min(start+c.batchSize, len(texts))
+		batch, err := c.embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed batch %d-%d: %w", start, end-1, err)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// GetModelInfo describes the remote model. The dimension is known once the
+// first embedding was received unless it was configured.
+func (c *HTTPEmbeddingClient) GetModelInfo() ModelInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return ModelInfo{
+		Name:      c.model,
+		Dimension: c.dimension,
+	}
+}
+
+// embed sends one request to the embeddings endpoint
+func (c *HTTPEmbeddingClient) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": c.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		if err := c.checkDimension(len(item.Embedding)); err != nil {
+			return nil, err
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	return embeddings, nil
+}
+
+// checkDimension records the model dimension and rejects inconsistent vectors
+func (c *HTTPEmbeddingClient) checkDimension(dimension int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dimension == 0 {
+		c.dimension = dimension
+	}
+	if dimension != c.dimension {
+		return fmt.Errorf("model %s returned a %d-dimensional vector, expected %d", c.model, dimension, c.dimension)
+	}
+	return nil
+}
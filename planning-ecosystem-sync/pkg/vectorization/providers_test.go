@@ -0,0 +1,105 @@
+package vectorization
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHashingEmbeddingClient tests the local provider
+func TestHashingEmbeddingClient(t *testing.T) {
+	client := NewHashingEmbeddingClient(0)
+	ctx := context.Background()
+
+	if info := client.GetModelInfo(); info.Dimension != DefaultDimension {
+		t.Fatalf("expected default dimension %d, got %d", DefaultDimension, info.Dimension)
+	}
+
+	embeddings, err := client.BatchGenerateEmbeddings(ctx, []string{
+		"synchronize markdown plans",
+		"synchronize the markdown plans",
+		"deploy a kubernetes cluster",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dot := func(a, b []float32) float32 {
+		var sum float32
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	if norm := dot(embeddings[0], embeddings[0]); norm < 0.999 || norm > 1.001 {
+		t.Errorf("expected a unit vector, got norm² %f", norm)
+	}
+	if dot(embeddings[0], embeddings[1]) <= dot(embeddings[0], embeddings[2]) {
+		t.Error("similar texts should be closer than unrelated ones")
+	}
+
+	again, _ := client.GenerateEmbedding(ctx, "synchronize markdown plans")
+	if dot(again, embeddings[0]) < 0.999 {
+		t.Error("embeddings should be deterministic")
+	}
+}
+
+// TestHTTPEmbeddingClient tests the OpenAI-compatible provider
+func TestHTTPEmbeddingClient(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		// Answer out of order, as the API allows
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := make([]item, len(body.Input))
+		for i := range body.Input {
+			j := len(body.Input) - 1 - i
+			data[i] = item{Index: j, Embedding: []float32{float32(len(body.Input[j])), 0, 1}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	client, err := NewEmbeddingClient(EmbeddingConfig{
+		Provider:  "openai",
+		BaseURL:   server.URL + "/v1",
+		Model:     "test-model",
+		APIKey:    "secret",
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embeddings, err := client.BatchGenerateEmbeddings(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("BatchGenerateEmbeddings failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 batched requests, got %d", requests)
+	}
+	for i, embedding := range embeddings {
+		if embedding[0] != float32(i+1) {
+			t.Errorf("embedding %d does not match its input: %v", i, embedding)
+		}
+	}
+	if info := client.GetModelInfo(); info.Name != "test-model" || info.Dimension != 3 {
+		t.Errorf("unexpected model info: %+v", info)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"email_sender/planning-ecosystem-sync/pkg/plandiff"
 )
 
 // ConflictDetector handles detection of conflicts between Markdown and dynamic system
@@ -95,11 +97,13 @@ const (
 
 // ConflictDetectionResult represents the result of conflict detection
 type ConflictDetectionResult struct {
-	PlanID        string        `json:"plan_id"`
-	Conflicts     []Conflict    `json:"conflicts"`
-	Summary       string        `json:"summary"`
-	DetectedAt    time.Time     `json:"detected_at"`
-	DetectionTime time.Duration `json:"detection_time"`
+	PlanID        string             `json:"plan_id"`
+	Conflicts     []Conflict         `json:"conflicts"`
+	Summary       string             `json:"summary"`
+	DetectedAt    time.Time          `json:"detected_at"`
+	DetectionTime time.Duration      `json:"detection_time"`
+	BaseRevision  int                `json:"base_revision,omitempty"` // Last common revision, 0 if unknown
+	Diff          *plandiff.PlanDiff `json:"diff,omitempty"`          // Semantic diff from the Markdown plan to the dynamic plan
 }
 
 // NewConflictDetector creates a new instance of ConflictDetector
@@ -173,6 +177,20 @@ func (cd *ConflictDetector) DetectConflicts(planID string) (*ConflictDetectionRe
 	taskConflicts := cd.detectTaskConflicts(planID, markdownPlan, dynamicPlan)
 	conflicts = append(conflicts, taskConflicts...)
 
+	// Rattacher la dernière révision commune, base de la fusion à trois voies
+	base, err := cd.sqlStorage.LastCommonRevision(planID, markdownPlan)
+	if err != nil {
+		cd.logger.Printf("⚠️ Failed to find common revision: %v", err)
+	} else if base != nil {
+		result.BaseRevision = base.Revision
+		for i := range conflicts {
+			conflicts[i].Details["base_revision"] = base.Revision
+		}
+	}
+
+	// Diff sémantique phase/tâche, joint au résultat pour le rapport de fusion
+	result.Diff = plandiff.Diff(toDiffPlan(markdownPlan), toDiffPlan(dynamicPlan))
+
 	result.Conflicts = conflicts
 	result.DetectionTime = time.Since(startTime)
 	result.Summary = cd.generateSummary(conflicts)
@@ -186,6 +204,32 @@ func (cd *ConflictDetector) DetectConflicts(planID string) (*ConflictDetectionRe
 	return result, nil
 }
 
+// toDiffPlan convertit un plan dynamique pour le diff sémantique
+func toDiffPlan(plan *DynamicPlan) *plandiff.Plan {
+	if plan == nil {
+		return nil
+	}
+
+	diffPlan := &plandiff.Plan{
+		ID:          plan.ID,
+		Title:       plan.Metadata.Title,
+		Version:     plan.Metadata.Version,
+		Progression: plan.Metadata.Progression,
+		Path:        plan.Metadata.FilePath,
+		Tasks:       make([]plandiff.Task, 0, len(plan.Tasks)),
+	}
+	for _, task := range plan.Tasks {
+		diffPlan.Tasks = append(diffPlan.Tasks, plandiff.Task{
+			ID:     task.ID,
+			Title:  task.Title,
+			Phase:  task.Phase,
+			Status: task.Status,
+			Level:  task.Level,
+		})
+	}
+	return diffPlan
+}
+
 // detectTimestampConflicts détecte les conflits basés sur les timestamps
 func (cd *ConflictDetector) detectTimestampConflicts(planID string, markdownPlan, dynamicPlan *DynamicPlan) []Conflict {
 	conflicts := []Conflict{}
@@ -100,6 +100,14 @@ func TestDetectConflicts(t *testing.T) {
 		conflictTypes[conflict.Type]++
 	}
 
+	// Le diff sémantique accompagne le résultat
+	if result.Diff == nil {
+		t.Fatal("Expected a semantic diff in the result")
+	}
+	if len(result.Diff.StatusChanged) != 1 || result.Diff.StatusChanged[0].Task.ID != "task_1" {
+		t.Errorf("Expected task_1 status change in diff, got %+v", result.Diff.StatusChanged)
+	}
+
 	t.Logf("✅ DetectConflicts test passed - Found %d conflicts: %v", len(result.Conflicts), conflictTypes)
 }
 
@@ -240,6 +240,28 @@ func (cr *ConflictResolver) mergeTaskConflict(conflict Conflict, resolution Conf
 	markdownStatus := conflict.Details["markdown_status"].(string)
 	dynamicStatus := conflict.Details["dynamic_status"].(string)
 
+	// Fusion à trois voies si la tâche existe dans la dernière révision commune
+	if base := cr.mergeBase(conflict); base != nil {
+		for _, task := range base.Snapshot.Tasks {
+			if task.ID != taskID {
+				continue
+			}
+			if mergedStatus, ok := mergeThreeWay(task.Status, markdownStatus, dynamicStatus); ok {
+				resolution.Action = fmt.Sprintf("Set task %s status to %s (merge base: revision %d)", taskID, mergedStatus, base.Revision)
+				resolution.Result = mergedStatus
+				resolution.Applied = true
+
+				return ResolvedConflict{
+					Conflict:   conflict,
+					Resolution: resolution,
+					Success:    true,
+					Message:    fmt.Sprintf("Task status merged from revision %d: %s", base.Revision, mergedStatus),
+				}
+			}
+			break
+		}
+	}
+
 	// Logique de priorité des statuts
 	mergedStatus := cr.determinePriorityStatus(markdownStatus, dynamicStatus)
 
@@ -262,8 +284,14 @@ func (cr *ConflictResolver) mergeMetadataConflict(conflict Conflict, resolution
 		markdownVersion := conflict.Details["markdown_version"].(string)
 		dynamicVersion := conflict.Details["dynamic_version"].(string)
 		
-		// Choisir la version avec le numéro le plus élevé
+		// Conserver le côté modifié depuis la base commune, sinon
+		// choisir la version avec le numéro le plus élevé
 		mergedVersion := cr.compareVersions(markdownVersion, dynamicVersion)
+		if base := cr.mergeBase(conflict); base != nil {
+			if version, ok := mergeThreeWay(base.Snapshot.Metadata.Version, markdownVersion, dynamicVersion); ok {
+				mergedVersion = version
+			}
+		}
 		
 		resolution.Action = fmt.Sprintf("Set version to %s", mergedVersion)
 		resolution.Result = mergedVersion
@@ -281,11 +309,20 @@ func (cr *ConflictResolver) mergeMetadataConflict(conflict Conflict, resolution
 		markdownProgression := conflict.Details["markdown_progression"].(float64)
 		dynamicProgression := conflict.Details["dynamic_progression"].(float64)
 		
-		// Utiliser la progression la plus élevée
+		// Utiliser la progression la plus élevée, sauf si un seul côté
+		// a changé depuis la base commune
 		mergedProgression := markdownProgression
 		if dynamicProgression > markdownProgression {
 			mergedProgression = dynamicProgression
 		}
+		if base := cr.mergeBase(conflict); base != nil {
+			baseProgression := base.Snapshot.Metadata.Progression
+			if markdownProgression == baseProgression {
+				mergedProgression = dynamicProgression
+			} else if dynamicProgression == baseProgression {
+				mergedProgression = markdownProgression
+			}
+		}
 		
 		resolution.Action = fmt.Sprintf("Set progression to %.1f%%", mergedProgression)
 		resolution.Result = mergedProgression
@@ -309,6 +346,30 @@ func (cr *ConflictResolver) mergeMetadataConflict(conflict Conflict, resolution
 
 // mergeContentConflict fusionne un conflit de contenu
 func (cr *ConflictResolver) mergeContentConflict(conflict Conflict, resolution ConflictResolution) ResolvedConflict {
+	// Un côté inchangé depuis la base commune: garder l'autre
+	if base := cr.mergeBase(conflict); base != nil && cr.detector != nil {
+		baseHash := cr.detector.calculatePlanHash(base.Snapshot)
+		side := ""
+		switch baseHash {
+		case conflict.MarkdownHash:
+			side = "dynamic"
+		case conflict.DynamicHash:
+			side = "markdown"
+		}
+		if side != "" {
+			resolution.Action = fmt.Sprintf("Use %s content, the only side changed since revision %d", side, base.Revision)
+			resolution.Result = side + "_version"
+			resolution.Applied = true
+
+			return ResolvedConflict{
+				Conflict:   conflict,
+				Resolution: resolution,
+				Success:    true,
+				Message:    fmt.Sprintf("Content merged from revision %d: %s changes kept", base.Revision, side),
+			}
+		}
+	}
+
 	// Pour les conflits de contenu, créer un plan fusionné
 	similarity := conflict.Details["content_similarity"].(float64)
 	
@@ -335,6 +396,49 @@ func (cr *ConflictResolver) mergeContentConflict(conflict Conflict, resolution C
 	}
 }
 
+// mergeBase retourne la dernière révision commune du plan en conflit, ou nil
+// si l'historique ne permet pas de la déterminer
+func (cr *ConflictResolver) mergeBase(conflict Conflict) *PlanRevision {
+	if cr.sqlStorage == nil {
+		return nil
+	}
+
+	var base *PlanRevision
+	var err error
+	switch number := conflict.Details["base_revision"].(type) {
+	case int:
+		base, err = cr.sqlStorage.GetRevision(conflict.PlanID, number)
+	case float64: // Conflit relu depuis du JSON
+		base, err = cr.sqlStorage.GetRevision(conflict.PlanID, int(number))
+	default:
+		base, err = cr.sqlStorage.LastCommonRevision(conflict.PlanID, nil)
+	}
+	if err != nil {
+		cr.logger.Printf("⚠️ No merge base for plan %s: %v", conflict.PlanID, err)
+		return nil
+	}
+	if base == nil || base.Snapshot == nil {
+		return nil
+	}
+	return base
+}
+
+// mergeThreeWay fusionne une valeur à partir de la base commune: un côté
+// inchangé depuis la base cède la place à l'autre. Retourne false si les deux
+// côtés ont divergé.
+func mergeThreeWay(base, markdown, dynamic string) (string, bool) {
+	switch {
+	case markdown == dynamic:
+		return markdown, true
+	case markdown == base:
+		return dynamic, true
+	case dynamic == base:
+		return markdown, true
+	default:
+		return "", false
+	}
+}
+
 // resolveWithMarkdown résout en utilisant la version Markdown
 func (cr *ConflictResolver) resolveWithMarkdown(conflict Conflict, resolution ConflictResolution) ResolvedConflict {
 	cr.logger.Printf("📝 Using Markdown version for conflict: %s", conflict.ID)
@@ -1,23 +1,27 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 	"crypto/sha256"
 	"encoding/hex"
-	"strings"
+
+	"email_sender/planning-ecosystem-sync/pkg/planembed"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
 )
 
 // DynamicPlan represents a plan in the dynamic system format
 type DynamicPlan struct {
-	ID          string      `json:"id"`
-	Metadata    PlanMetadata `json:"metadata"`
-	Tasks       []Task      `json:"tasks"`
-	Embeddings  []float64   `json:"embeddings"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID             string          `json:"id"`
+	Metadata       PlanMetadata    `json:"metadata"`
+	Tasks          []Task          `json:"tasks"`
+	Embeddings     []float64       `json:"embeddings"` // Plan-level vector: normalized mean of the task embeddings
+	TaskEmbeddings []TaskEmbedding `json:"task_embeddings,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
 // PlanMetadata contains plan metadata
@@ -45,15 +49,29 @@ type Task struct {
 	Completed    bool      `json:"completed"`
 }
 
+// TaskEmbedding is the embedding of a single task chunk
+type TaskEmbedding struct {
+	TaskID string    `json:"task_id"`
+	Vector []float64 `json:"vector"`
+}
+
 // MarkdownParser handles parsing of markdown plans
 type MarkdownParser struct {
-	logger *log.Logger
+	logger   *log.Logger
+	embedder vectorization.EmbeddingClient
 }
 
-// NewMarkdownParser creates a new MarkdownParser instance
+// NewMarkdownParser creates a new MarkdownParser instance using the local
+// embedding provider
 func NewMarkdownParser() *MarkdownParser {
+	return NewMarkdownParserWithEmbedder(vectorization.NewHashingEmbeddingClient(vectorization.DefaultDimension))
+}
+
+// NewMarkdownParserWithEmbedder creates a MarkdownParser embedding tasks with the given provider
+func NewMarkdownParserWithEmbedder(embedder vectorization.EmbeddingClient) *MarkdownParser {
 	return &MarkdownParser{
-		logger: log.Default(),
+		logger:   log.Default(),
+		embedder: embedder,
 	}
 }
 
@@ -69,15 +87,16 @@ func (mp *MarkdownParser) ConvertToDynamic(metadata *PlanMetadata, tasks []Task)
 		UpdatedAt: time.Now(),
 	}
 	
-	// Générer embeddings pour recherche sémantique
-	embeddings, err := mp.generateEmbeddings(metadata.Title, tasks)
+	// Générer un embedding par tâche pour la recherche sémantique
+	taskEmbeddings, embeddings, err := mp.embedTasks(metadata.Title, tasks)
 	if err != nil {
 		mp.logger.Printf("⚠️  Warning: Failed to generate embeddings: %v", err)
 		// Continue without embeddings rather than failing completely
 		plan.Embeddings = []float64{}
 	} else {
+		plan.TaskEmbeddings = taskEmbeddings
 		plan.Embeddings = embeddings
-		mp.logger.Printf("✅ Generated %d-dimensional embeddings", len(embeddings))
+		mp.logger.Printf("✅ Generated %d task embeddings (%d dimensions)", len(taskEmbeddings), len(embeddings))
 	}
 	
 	mp.logger.Printf("✅ Successfully converted plan with %d tasks", len(tasks))
@@ -90,48 +109,85 @@ func generatePlanID(filePath string) string {
 	return fmt.Sprintf("plan_%s", hex.EncodeToString(hash[:8]))
 }
 
-// generateEmbeddings creates vector embeddings for semantic search
-// This is a simplified implementation - in production, you'd use a proper embedding service
+// generateEmbeddings returns the plan-level embedding, the normalized mean of
+// the task embeddings
 func (mp *MarkdownParser) generateEmbeddings(title string, tasks []Task) ([]float64, error) {
+	_, embeddings, err := mp.embedTasks(title, tasks)
+	return embeddings, err
+}
+
+// embedTasks embeds each task as its own chunk (plan title, phase, task title
+// and description) through the embedding provider
+func (mp *MarkdownParser) embedTasks(title string, tasks []Task) ([]TaskEmbedding, []float64, error) {
 	mp.logger.Printf("🔍 Generating embeddings for plan: %s", title)
-	
-	// Combine title and task content for embedding generation
-	var content strings.Builder
-	content.WriteString(title)
-	content.WriteString(" ")
-	
-	for _, task := range tasks {
-		content.WriteString(task.Title)
-		content.WriteString(" ")
-		content.WriteString(task.Description)
-		content.WriteString(" ")
+
+	chunks := planembed.ChunkPlan(toEmbedPlan(&DynamicPlan{Metadata: PlanMetadata{Title: title}, Tasks: tasks}))
+	if len(chunks) == 0 {
+		return nil, nil, fmt.Errorf("plan has no tasks to embed")
 	}
-	
-	// For demonstration purposes, create a simple embedding based on content analysis
-	// In production, this would call an actual embedding service (OpenAI, etc.)
-	embeddings := mp.createSimpleEmbedding(content.String())
-	
-	return embeddings, nil
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	vectors, err := mp.embedder.BatchGenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("embedding provider failed: %w", err)
+	}
+
+	taskEmbeddings := make([]TaskEmbedding, len(chunks))
+	for i, chunk := range chunks {
+		taskEmbeddings[i] = TaskEmbedding{TaskID: chunk.TaskID, Vector: toFloat64(vectors[i])}
+	}
+	return taskEmbeddings, toFloat64(planembed.Centroid(vectors)), nil
 }
 
-// createSimpleEmbedding creates a simple 384-dimensional embedding
-// This is a placeholder - replace with actual embedding service in production
-func (mp *MarkdownParser) createSimpleEmbedding(content string) []float64 {
-	// Create 384-dimensional embedding (standard for many models)
-	embeddings := make([]float64, 384)
-	
-	// Simple heuristic-based embedding generation
-	words := strings.Fields(strings.ToLower(content))
-	wordCount := float64(len(words))
-	
-	// Fill embeddings with normalized values based on content characteristics
-	for i := range embeddings {
-		// Use content characteristics to generate meaningful values
-		seed := float64(i) * wordCount
-		embeddings[i] = (seed - float64(int(seed))) * 2.0 - 1.0 // Normalize to [-1, 1]
+// EmbeddingDimension returns the dimension of the embedding provider
+func (mp *MarkdownParser) EmbeddingDimension() int {
+	return mp.embedder.GetModelInfo().Dimension
+}
+
+// toEmbedPlan converts a plan to the input of the embedding pipeline
+func toEmbedPlan(plan *DynamicPlan) planembed.Plan {
+	embedPlan := planembed.Plan{
+		ID:       plan.ID,
+		Title:    plan.Metadata.Title,
+		Version:  plan.Metadata.Version,
+		FilePath: plan.Metadata.FilePath,
+		Tasks:    make([]planembed.Task, len(plan.Tasks)),
 	}
-	
-	return embeddings
+	for i, task := range plan.Tasks {
+		embedPlan.Tasks[i] = planembed.Task{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Phase:       task.Phase,
+			Status:      task.Status,
+		}
+	}
+	return embedPlan
+}
+
+// toFloat64 converts a provider vector to the storage precision
+func toFloat64(vector []float32) []float64 {
+	result := make([]float64, len(vector))
+	for i, v := range vector {
+		result[i] = float64(v)
+	}
+	return result
+}
+
+// toFloat32 converts a stored vector to the provider precision
+func toFloat32(vector []float64) []float32 {
+	result := make([]float32, len(vector))
+	for i, v := range vector {
+		result[i] = float32(v)
+	}
+	return result
 }
 
 // ValidateConversion validates the converted plan for consistency
@@ -164,9 +220,16 @@ func (mp *MarkdownParser) ValidateConversion(plan *DynamicPlan) error {
 		}
 	}
 	
-	// Validate embeddings dimension (should be 384 for compatibility)
-	if len(plan.Embeddings) > 0 && len(plan.Embeddings) != 384 {
-		return fmt.Errorf("embeddings dimension should be 384, got %d", len(plan.Embeddings))
+	// Validate embeddings dimension against the embedding provider
+	dimension := mp.EmbeddingDimension()
+	if len(plan.Embeddings) > 0 && dimension > 0 && len(plan.Embeddings) != dimension {
+		return fmt.Errorf("embeddings dimension should be %d, got %d", dimension, len(plan.Embeddings))
+	}
+	for _, embedding := range plan.TaskEmbeddings {
+		if len(embedding.Vector) != len(plan.Embeddings) {
+			return fmt.Errorf("task %s: embedding dimension %d differs from plan dimension %d",
+				embedding.TaskID, len(embedding.Vector), len(plan.Embeddings))
+		}
 	}
 	
 	mp.logger.Printf("✅ Plan validation successful")
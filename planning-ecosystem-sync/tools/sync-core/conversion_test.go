@@ -136,6 +136,45 @@ func TestEmbeddingsGeneration(t *testing.T) {
 	t.Logf("✅ Embeddings generation test passed - Generated %d-dimensional vector", len(embeddings))
 }
 
+// TestTaskEmbeddings tests that each task gets its own embedding
+func TestTaskEmbeddings(t *testing.T) {
+	parser := NewMarkdownParser()
+	
+	tasks := []Task{
+		{ID: "1.1", Title: "Parse markdown plans", Description: "Read headings and checkboxes", Phase: "Phase 1"},
+		{ID: "1.2", Title: "Parse markdown tables", Description: "Read headings and table rows", Phase: "Phase 1"},
+		{ID: "2.1", Title: "Deploy Qdrant cluster", Description: "Configure replicas on Kubernetes", Phase: "Phase 2"},
+	}
+	
+	taskEmbeddings, planEmbedding, err := parser.embedTasks("Plan de test", tasks)
+	if err != nil {
+		t.Fatalf("embedTasks failed: %v", err)
+	}
+	
+	if len(taskEmbeddings) != len(tasks) {
+		t.Fatalf("Expected %d task embeddings, got %d", len(tasks), len(taskEmbeddings))
+	}
+	if taskEmbeddings[2].TaskID != "2.1" || len(planEmbedding) != parser.EmbeddingDimension() {
+		t.Errorf("Unexpected embeddings: task %s, plan dimension %d", taskEmbeddings[2].TaskID, len(planEmbedding))
+	}
+	
+	// Tasks sharing vocabulary must be closer than unrelated tasks
+	dot := func(a, b []float64) float64 {
+		var sum float64
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	related := dot(taskEmbeddings[0].Vector, taskEmbeddings[1].Vector)
+	unrelated := dot(taskEmbeddings[0].Vector, taskEmbeddings[2].Vector)
+	if related <= unrelated {
+		t.Errorf("Expected related tasks to be closer (%f) than unrelated ones (%f)", related, unrelated)
+	}
+	
+	t.Logf("✅ Task embeddings test passed - similarity %.3f vs %.3f", related, unrelated)
+}
+
 // TestPlanValidation tests the plan validation functionality
 func TestPlanValidation(t *testing.T) {
 	parser := NewMarkdownParser()
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
 )
 
 // SyncOrchestrator coordinates the conversion and storage of plans
@@ -17,16 +19,25 @@ type SyncOrchestrator struct {
 
 // SyncConfig holds configuration for the sync orchestrator
 type SyncConfig struct {
-	QDrantURL      string         `yaml:"qdrant_url"`
-	DatabaseConfig DatabaseConfig `yaml:"database"`
-	OutputDir      string         `yaml:"output_dir"`
+	QDrantURL        string                        `yaml:"qdrant_url"`
+	QDrantCollection string                        `yaml:"qdrant_collection"` // Task embeddings collection
+	Embedding        vectorization.EmbeddingConfig `yaml:"embedding"`
+	DatabaseConfig   DatabaseConfig                `yaml:"database"`
+	OutputDir        string                        `yaml:"output_dir"`
 }
 
 // NewSyncOrchestrator creates a new sync orchestrator
 func NewSyncOrchestrator(config SyncConfig) (*SyncOrchestrator, error) {	// Initialize components
-	parser := NewMarkdownParser()
+	embedder, err := vectorization.NewEmbeddingClient(config.Embedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize embedding provider: %w", err)
+	}
+	parser := NewMarkdownParserWithEmbedder(embedder)
 	
-	qdrant := NewQDrantClient(config.QDrantURL)
+	qdrant, err := NewQDrantClientWithEmbedder(config.QDrantURL, embedder, config.QDrantCollection)
+	if err != nil {
+		return nil, err
+	}
 	
 	sqlStorage, err := NewSQLStorage(config.DatabaseConfig)
 	if err != nil {
@@ -77,7 +88,7 @@ func (so *SyncOrchestrator) ConvertAndStore(metadata *PlanMetadata, tasks []Task
 	
 	// Step 3: Store in SQL database
 	so.logger.Printf("💾 Step 3: Storing in SQL database")
-	if err := so.sqlStorage.StorePlan(plan); err != nil {
+	if _, err := so.sqlStorage.StorePlanRevision(plan, "sync-orchestrator", SourceMarkdown); err != nil {
 		return fmt.Errorf("SQL storage failed: %w", err)
 	}
 	
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RevisionSource identifies the system that produced a plan revision
+type RevisionSource string
+
+const (
+	SourceMarkdown       RevisionSource = "markdown"
+	SourceDynamic        RevisionSource = "dynamic"
+	SourceRoadmapManager RevisionSource = "roadmap-manager"
+)
+
+// PlanRevision is an immutable snapshot of a plan at the time it was stored
+type PlanRevision struct {
+	PlanID         string         `json:"plan_id"`
+	Revision       int            `json:"revision"`
+	ParentRevision int            `json:"parent_revision,omitempty"` // 0 for the first revision
+	Author         string         `json:"author"`
+	Source         RevisionSource `json:"source"`
+	ContentHash    string         `json:"content_hash"`
+	Snapshot       *DynamicPlan   `json:"snapshot"`
+	Diff           *RevisionDiff  `json:"diff,omitempty"` // Changes against the parent revision
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// RevisionDiff describes the changes between two versions of a plan
+type RevisionDiff struct {
+	FromRevision    int           `json:"from_revision"`
+	ToRevision      int           `json:"to_revision"`
+	MetadataChanges []FieldChange `json:"metadata_changes,omitempty"`
+	AddedTasks      []Task        `json:"added_tasks,omitempty"`
+	RemovedTasks    []Task        `json:"removed_tasks,omitempty"`
+	ModifiedTasks   []TaskChange  `json:"modified_tasks,omitempty"`
+}
+
+// FieldChange records the old and new value of a single field
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TaskChange lists the field changes of a task present in both versions
+type TaskChange struct {
+	TaskID  string        `json:"task_id"`
+	Title   string        `json:"title"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// IsEmpty reports whether the diff contains no change
+func (d *RevisionDiff) IsEmpty() bool {
+	return d == nil || (len(d.MetadataChanges) == 0 && len(d.AddedTasks) == 0 &&
+		len(d.RemovedTasks) == 0 && len(d.ModifiedTasks) == 0)
+}
+
+// Summary returns a one-line description of the diff
+func (d *RevisionDiff) Summary() string {
+	if d.IsEmpty() {
+		return "no changes"
+	}
+	return fmt.Sprintf("%d metadata change(s), %d task(s) added, %d removed, %d modified",
+		len(d.MetadataChanges), len(d.AddedTasks), len(d.RemovedTasks), len(d.ModifiedTasks))
+}
+
+// revisionTablesQueries returns the DDL of the revision history
+func revisionTablesQueries() []string {
+	return []string{
+		// Revisions are append-only: rows are never updated nor deleted
+		`CREATE TABLE IF NOT EXISTS plan_revisions (
+			plan_id VARCHAR(255) NOT NULL,
+			revision INTEGER NOT NULL,
+			parent_revision INTEGER DEFAULT 0,
+			author VARCHAR(255),
+			source VARCHAR(50) NOT NULL,
+			content_hash VARCHAR(64) NOT NULL,
+			snapshot_json TEXT NOT NULL,
+			diff_json TEXT,
+			created_at TIMESTAMP NOT NULL,
+			-- Concurrent writers of the same revision conflict here and retry
+			PRIMARY KEY (plan_id, revision)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_plan_revisions_created_at ON plan_revisions(plan_id, created_at)`,
+	}
+}
+
+// maxRevisionAttempts bounds the retries of a revision whose number was
+// taken by a concurrent writer
+const maxRevisionAttempts = 5
+
+// errRevisionTaken reports that the revision number being inserted already
+// exists for the plan
+var errRevisionTaken = errors.New("revision already exists")
+
+// StorePlanRevision stores a plan like StorePlan and records it as a new
+// immutable revision. Storing content identical to the latest revision does
+// not create a new revision; the latest one is returned instead.
+func (s *SQLStorage) StorePlanRevision(plan *DynamicPlan, author string, source RevisionSource) (*PlanRevision, error) {
+	if source == "" {
+		source = SourceDynamic
+	}
+	if author == "" {
+		author = "system"
+	}
+
+	var revision *PlanRevision
+	var err error
+	for attempt := 1; attempt <= maxRevisionAttempts; attempt++ {
+		err = s.storePlan(plan, func(tx *sql.Tx) error {
+			var err error
+			revision, err = s.appendRevision(tx, plan, author, source)
+			return err
+		})
+		if !errors.Is(err, errRevisionTaken) {
+			break
+		}
+		// Another writer recorded the same revision number: start over from
+		// its revision
+		s.logger.Printf("📚 Revision of plan %s taken concurrently, retrying (%d/%d)", plan.ID, attempt, maxRevisionAttempts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logSyncOperation(plan.ID, "store_revision", "success",
+		fmt.Sprintf("Revision %d (%s by %s): %s", revision.Revision, revision.Source, revision.Author, revision.Diff.Summary()), 0)
+	return revision, nil
+}
+
+// appendRevision inserts the next revision of a plan inside tx
+func (s *SQLStorage) appendRevision(tx *sql.Tx, plan *DynamicPlan, author string, source RevisionSource) (*PlanRevision, error) {
+	hash := planContentHash(plan)
+
+	latest, err := s.scanRevision(tx.QueryRow(revisionSelect+`
+		WHERE plan_id = $1 ORDER BY revision DESC LIMIT 1`, plan.ID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read latest revision: %w", err)
+	}
+	if latest != nil && latest.ContentHash == hash {
+		s.logger.Printf("📚 Plan %s unchanged since revision %d", plan.ID, latest.Revision)
+		return latest, nil
+	}
+
+	revision := &PlanRevision{
+		PlanID:      plan.ID,
+		Revision:    1,
+		Author:      author,
+		Source:      source,
+		ContentHash: hash,
+		Snapshot:    plan,
+		CreatedAt:   time.Now().UTC(),
+	}
+	var previous *DynamicPlan
+	if latest != nil {
+		revision.Revision = latest.Revision + 1
+		revision.ParentRevision = latest.Revision
+		previous = latest.Snapshot
+		// Keep the history ordered even if the clock went backwards
+		if !revision.CreatedAt.After(latest.CreatedAt) {
+			revision.CreatedAt = latest.CreatedAt.Add(time.Microsecond)
+		}
+	}
+	revision.Diff = DiffPlans(previous, plan)
+	revision.Diff.FromRevision = revision.ParentRevision
+	revision.Diff.ToRevision = revision.Revision
+
+	snapshotJSON, err := json.Marshal(plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize plan snapshot: %w", err)
+	}
+	diffJSON, err := json.Marshal(revision.Diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize revision diff: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO plan_revisions (plan_id, revision, parent_revision, author, source, content_hash, snapshot_json, diff_json, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		revision.PlanID,
+		revision.Revision,
+		revision.ParentRevision,
+		revision.Author,
+		string(revision.Source),
+		revision.ContentHash,
+		string(snapshotJSON),
+		string(diffJSON),
+		revision.CreatedAt,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to insert revision %d: %w", revision.Revision, errRevisionTaken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert revision %d: %w", revision.Revision, err)
+	}
+
+	s.logger.Printf("📚 Recorded revision %d of plan %s (%s)", revision.Revision, plan.ID, revision.Diff.Summary())
+	return revision, nil
+}
+
+// isUniqueViolation recognizes the unique constraint errors of the
+// supported drivers (PostgreSQL, MySQL and SQLite)
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unique constraint") || // PostgreSQL, SQLite
+		strings.Contains(message, "duplicate entry") // MySQL
+}
+
+const revisionSelect = `
+	SELECT plan_id, revision, parent_revision, author, source, content_hash, snapshot_json, diff_json, created_at
+	FROM plan_revisions`
+
+// scanRevision reads a revision row
+func (s *SQLStorage) scanRevision(row interface{ Scan(...interface{}) error }) (*PlanRevision, error) {
+	var revision PlanRevision
+	var source, snapshotJSON string
+	var diffJSON sql.NullString
+
+	err := row.Scan(
+		&revision.PlanID,
+		&revision.Revision,
+		&revision.ParentRevision,
+		&revision.Author,
+		&source,
+		&revision.ContentHash,
+		&snapshotJSON,
+		&diffJSON,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	revision.Source = RevisionSource(source)
+
+	if err := json.Unmarshal([]byte(snapshotJSON), &revision.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot of revision %d: %w", revision.Revision, err)
+	}
+	if diffJSON.Valid && diffJSON.String != "" {
+		if err := json.Unmarshal([]byte(diffJSON.String), &revision.Diff); err != nil {
+			return nil, fmt.Errorf("failed to parse diff of revision %d: %w", revision.Revision, err)
+		}
+	}
+	return &revision, nil
+}
+
+// ListRevisions returns the revisions of a plan, oldest first
+func (s *SQLStorage) ListRevisions(planID string) ([]*PlanRevision, error) {
+	rows, err := s.db.Query(revisionSelect+` WHERE plan_id = $1 ORDER BY revision`, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*PlanRevision
+	for rows.Next() {
+		revision, err := s.scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns a single revision of a plan
+func (s *SQLStorage) GetRevision(planID string, revision int) (*PlanRevision, error) {
+	result, err := s.scanRevision(s.db.QueryRow(revisionSelect+`
+		WHERE plan_id = $1 AND revision = $2`, planID, revision))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision %d not found for plan %s", revision, planID)
+		}
+		return nil, fmt.Errorf("failed to query revision: %w", err)
+	}
+	return result, nil
+}
+
+// GetRevisionAsOf returns the revision of a plan that was current at the given time
+func (s *SQLStorage) GetRevisionAsOf(planID string, at time.Time) (*PlanRevision, error) {
+	// Timestamps are compared in Go: drivers do not agree on their textual format
+	rows, err := s.db.Query(`SELECT revision, created_at FROM plan_revisions WHERE plan_id = $1 ORDER BY revision DESC`, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	found := 0
+	for rows.Next() {
+		var revision int
+		var createdAt time.Time
+		if err := rows.Scan(&revision, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if !createdAt.After(at) {
+			found = revision
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions: %w", err)
+	}
+	rows.Close()
+
+	if found == 0 {
+		return nil, fmt.Errorf("plan %s has no revision as of %s", planID, at.Format(time.RFC3339))
+	}
+	return s.GetRevision(planID, found)
+}
+
+// GetPlanAsOf returns the plan as it was stored at the given time
+func (s *SQLStorage) GetPlanAsOf(planID string, at time.Time) (*DynamicPlan, error) {
+	revision, err := s.GetRevisionAsOf(planID, at)
+	if err != nil {
+		return nil, err
+	}
+	return revision.Snapshot, nil
+}
+
+// DiffRevisions compares two revisions of a plan
+func (s *SQLStorage) DiffRevisions(planID string, from, to int) (*RevisionDiff, error) {
+	fromRevision, err := s.GetRevision(planID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.GetRevision(planID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffPlans(fromRevision.Snapshot, toRevision.Snapshot)
+	diff.FromRevision = from
+	diff.ToRevision = to
+	return diff, nil
+}
+
+// LastCommonRevision returns the most recent revision shared by the stored
+// plan and the given Markdown version of it, to be used as a merge base:
+// the latest revision whose content equals markdownPlan if any (the Markdown
+// side did not change since), otherwise the latest revision synchronized from
+// Markdown. It returns nil when the plan has no such revision.
+func (s *SQLStorage) LastCommonRevision(planID string, markdownPlan *DynamicPlan) (*PlanRevision, error) {
+	revisions, err := s.ListRevisions(planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if markdownPlan != nil {
+		hash := planContentHash(markdownPlan)
+		for i := len(revisions) - 1; i >= 0; i-- {
+			if revisions[i].ContentHash == hash {
+				return revisions[i], nil
+			}
+		}
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].Source == SourceMarkdown {
+			return revisions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// planContentHash hashes the content of a plan, ignoring timestamps and
+// embeddings which change on every synchronization
+func planContentHash(plan *DynamicPlan) string {
+	tasks := make([]Task, len(plan.Tasks))
+	copy(tasks, plan.Tasks)
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	for i := range tasks {
+		tasks[i].CreatedAt = time.Time{}
+		tasks[i].UpdatedAt = time.Time{}
+	}
+
+	data, _ := json.Marshal(struct {
+		Metadata PlanMetadata `json:"metadata"`
+		Tasks    []Task       `json:"tasks"`
+	}{plan.Metadata, tasks})
+
+	hasher := sha256.New()
+	hasher.Write(data)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// DiffPlans compares two versions of a plan. A nil from plan is treated as
+// empty, so every task of to is reported as added.
+func DiffPlans(from, to *DynamicPlan) *RevisionDiff {
+	diff := &RevisionDiff{}
+	if from == nil {
+		from = &DynamicPlan{}
+	}
+	if to == nil {
+		to = &DynamicPlan{}
+	}
+
+	diff.MetadataChanges = diffFields([][3]string{
+		{"title", from.Metadata.Title, to.Metadata.Title},
+		{"version", from.Metadata.Version, to.Metadata.Version},
+		{"file_path", from.Metadata.FilePath, to.Metadata.FilePath},
+		{"date", from.Metadata.Date, to.Metadata.Date},
+		{"description", from.Metadata.Description, to.Metadata.Description},
+		{"progression", formatProgression(from.Metadata.Progression), formatProgression(to.Metadata.Progression)},
+	})
+
+	fromTasks := make(map[string]Task, len(from.Tasks))
+	for _, task := range from.Tasks {
+		fromTasks[task.ID] = task
+	}
+	toTasks := make(map[string]bool, len(to.Tasks))
+
+	for _, task := range to.Tasks {
+		toTasks[task.ID] = true
+		previous, exists := fromTasks[task.ID]
+		if !exists {
+			diff.AddedTasks = append(diff.AddedTasks, task)
+			continue
+		}
+		if changes := diffTask(previous, task); len(changes) > 0 {
+			diff.ModifiedTasks = append(diff.ModifiedTasks, TaskChange{
+				TaskID:  task.ID,
+				Title:   task.Title,
+				Changes: changes,
+			})
+		}
+	}
+	for _, task := range from.Tasks {
+		if !toTasks[task.ID] {
+			diff.RemovedTasks = append(diff.RemovedTasks, task)
+		}
+	}
+
+	return diff
+}
+
+// diffTask returns the field changes between two versions of a task
+func diffTask(from, to Task) []FieldChange {
+	return diffFields([][3]string{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"status", from.Status, to.Status},
+		{"phase", from.Phase, to.Phase},
+		{"level", fmt.Sprint(from.Level), fmt.Sprint(to.Level)},
+		{"priority", from.Priority, to.Priority},
+		{"completed", fmt.Sprint(from.Completed), fmt.Sprint(to.Completed)},
+		{"dependencies", strings.Join(from.Dependencies, ","), strings.Join(to.Dependencies, ",")},
+	})
+}
+
+// diffFields keeps the (field, old, new) triples whose values differ
+func diffFields(fields [][3]string) []FieldChange {
+	var changes []FieldChange
+	for _, field := range fields {
+		if field[1] != field[2] {
+			changes = append(changes, FieldChange{Field: field[0], Old: field[1], New: field[2]})
+		}
+	}
+	return changes
+}
+
+// formatProgression renders a progression the way it is compared
+func formatProgression(progression float64) string {
+	return fmt.Sprintf("%.2f", progression)
+}
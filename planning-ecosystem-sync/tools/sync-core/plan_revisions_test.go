@@ -0,0 +1,192 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPlanRevisionHistory tests revision recording, time-travel and diffs
+func TestPlanRevisionHistory(t *testing.T) {
+	config := DatabaseConfig{
+		Driver:     "sqlite",
+		Connection: "file:test_revisions.db?mode=memory&cache=shared",
+	}
+
+	storage, err := NewSQLStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create SQL storage: %v", err)
+	}
+	defer storage.Close()
+
+	plan := &DynamicPlan{
+		ID: "test_plan_revisions",
+		Metadata: PlanMetadata{
+			Title:       "Revision Test Plan",
+			Version:     "v1.0",
+			FilePath:    "test/revisions.md",
+			Progression: 10.0,
+		},
+		Tasks: []Task{
+			{ID: "rev_task_1", Title: "Task 1", Status: "pending", Level: 1, Dependencies: []string{}},
+			{ID: "rev_task_2", Title: "Task 2", Status: "pending", Level: 1, Dependencies: []string{}},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	first, err := storage.StorePlanRevision(plan, "alice", SourceMarkdown)
+	if err != nil {
+		t.Fatalf("Failed to store first revision: %v", err)
+	}
+	if first.Revision != 1 || first.ParentRevision != 0 {
+		t.Errorf("Expected revision 1 without parent, got %d (parent %d)", first.Revision, first.ParentRevision)
+	}
+	if len(first.Diff.AddedTasks) != 2 {
+		t.Errorf("Expected 2 added tasks in first revision, got %d", len(first.Diff.AddedTasks))
+	}
+
+	// Storing the same content does not create a revision
+	plan.UpdatedAt = time.Now()
+	same, err := storage.StorePlanRevision(plan, "alice", SourceMarkdown)
+	if err != nil {
+		t.Fatalf("Failed to store unchanged plan: %v", err)
+	}
+	if same.Revision != 1 {
+		t.Errorf("Unchanged plan should keep revision 1, got %d", same.Revision)
+	}
+
+	between := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	plan.Tasks[0].Status = "completed"
+	plan.Tasks = plan.Tasks[:1]
+	plan.Tasks = append(plan.Tasks, Task{ID: "rev_task_3", Title: "Task 3", Status: "pending", Level: 2, Dependencies: []string{}})
+	plan.Metadata.Progression = 50.0
+	if err := storage.StorePlan(plan); err != nil {
+		t.Fatalf("Failed to store second revision: %v", err)
+	}
+
+	revisions, err := storage.ListRevisions(plan.ID)
+	if err != nil {
+		t.Fatalf("Failed to list revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisions))
+	}
+	second := revisions[1]
+	if second.Source != SourceDynamic || second.Author != "system" || second.ParentRevision != 1 {
+		t.Errorf("Unexpected second revision: source=%s author=%s parent=%d", second.Source, second.Author, second.ParentRevision)
+	}
+	if len(second.Diff.AddedTasks) != 1 || len(second.Diff.RemovedTasks) != 1 || len(second.Diff.ModifiedTasks) != 1 {
+		t.Errorf("Unexpected diff: %s", second.Diff.Summary())
+	}
+	if len(second.Diff.MetadataChanges) != 1 || second.Diff.MetadataChanges[0].Field != "progression" {
+		t.Errorf("Expected a progression change, got %+v", second.Diff.MetadataChanges)
+	}
+
+	// Time-travel: the plan as it was before the second revision
+	past, err := storage.GetPlanAsOf(plan.ID, between)
+	if err != nil {
+		t.Fatalf("Failed to get plan as of %v: %v", between, err)
+	}
+	if len(past.Tasks) != 2 || past.Tasks[0].Status != "pending" {
+		t.Errorf("Expected the first revision, got %d tasks", len(past.Tasks))
+	}
+	if _, err := storage.GetPlanAsOf(plan.ID, first.CreatedAt.Add(-time.Hour)); err == nil {
+		t.Error("Should fail before the first revision")
+	}
+
+	diff, err := storage.DiffRevisions(plan.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to diff revisions: %v", err)
+	}
+	if diff.FromRevision != 1 || diff.ToRevision != 2 || diff.IsEmpty() {
+		t.Errorf("Unexpected revision diff: %+v", diff)
+	}
+
+	// The last Markdown revision is the merge base of a modified Markdown plan
+	markdownPlan := *past
+	markdownPlan.Metadata.Version = "v2.0"
+	base, err := storage.LastCommonRevision(plan.ID, &markdownPlan)
+	if err != nil {
+		t.Fatalf("Failed to find common revision: %v", err)
+	}
+	if base == nil || base.Revision != 1 {
+		t.Errorf("Expected revision 1 as merge base, got %+v", base)
+	}
+
+	t.Logf("✅ Plan revision history test passed")
+}
+
+// TestThreeWayTaskMerge tests that the merge base decides task status conflicts
+func TestThreeWayTaskMerge(t *testing.T) {
+	config := DatabaseConfig{
+		Driver:     "sqlite",
+		Connection: "file:test_three_way.db?mode=memory&cache=shared",
+	}
+
+	storage, err := NewSQLStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create SQL storage: %v", err)
+	}
+	defer storage.Close()
+
+	plan := &DynamicPlan{
+		ID:       "test_plan_three_way",
+		Metadata: PlanMetadata{Title: "Three-way Plan", FilePath: "test/three_way.md"},
+		Tasks: []Task{
+			{ID: "merge_task", Title: "Merge Task", Status: "completed", Level: 1, Dependencies: []string{}},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := storage.StorePlanRevision(plan, "sync", SourceMarkdown); err != nil {
+		t.Fatalf("Failed to store plan: %v", err)
+	}
+
+	base, err := storage.GetRevision(plan.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to get merge base: %v", err)
+	}
+	baseStatus := base.Snapshot.Tasks[0].Status
+
+	// Markdown still says "completed": the dynamic change to "in_progress" wins,
+	// although "completed" has a higher priority
+	if merged, ok := mergeThreeWay(baseStatus, "completed", "in_progress"); !ok || merged != "in_progress" {
+		t.Errorf("Expected the dynamic status from the three-way merge, got %q (%v)", merged, ok)
+	}
+	if merged, ok := mergeThreeWay(baseStatus, "in_progress", "completed"); !ok || merged != "in_progress" {
+		t.Errorf("Expected the markdown status from the three-way merge, got %q (%v)", merged, ok)
+	}
+	if _, ok := mergeThreeWay(baseStatus, "in_progress", "blocked"); ok {
+		t.Error("Both sides changed since the base: the merge should be left to the priority rules")
+	}
+}
+
+// TestRevisionNumberConflict tests that a revision number taken by another
+// writer is reported as such, so the revision is retried
+func TestRevisionNumberConflict(t *testing.T) {
+	config := DatabaseConfig{
+		Driver:     "sqlite",
+		Connection: "file:test_revision_conflict.db?mode=memory&cache=shared",
+	}
+
+	storage, err := NewSQLStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create SQL storage: %v", err)
+	}
+	defer storage.Close()
+
+	insert := `INSERT INTO plan_revisions (plan_id, revision, source, content_hash, snapshot_json, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	if _, err := storage.db.Exec(insert, "plan", 1, "dynamic", "a", "{}", time.Now()); err != nil {
+		t.Fatalf("Failed to insert revision: %v", err)
+	}
+	_, err = storage.db.Exec(insert, "plan", 1, "dynamic", "b", "{}", time.Now())
+	if !isUniqueViolation(err) {
+		t.Errorf("Expected a unique constraint violation, got %v", err)
+	}
+	if isUniqueViolation(nil) {
+		t.Error("No error is not a violation")
+	}
+}
@@ -23,7 +23,10 @@ func TestPlanSynchronizerCreation(t *testing.T) {
 	}
 	defer storage.Close()
 	// Create test QDrant client
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	// Create synchronizer
 	syncConfig := &MarkdownSyncConfig{
 		OutputDirectory:    "./test-output",
@@ -124,7 +127,10 @@ func TestSyncToMarkdown(t *testing.T) {
 		t.Fatalf("Failed to store test plan: %v", err)
 	}
 	// Create synchronizer
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	syncConfig := &MarkdownSyncConfig{
 		OutputDirectory:    tempDir,
 		PreserveFormatting: true,
@@ -248,7 +254,10 @@ func TestMarkdownConversion(t *testing.T) {
 	config := DatabaseConfig{Driver: "sqlite", Connection: "file:test.db?mode=memory"}
 	storage, _ := NewSQLStorage(config)
 	defer storage.Close()
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	synchronizer := NewPlanSynchronizer(storage, qdrantClient, nil)
 
 	// Test conversion
@@ -318,7 +327,10 @@ func TestPhaseGrouping(t *testing.T) {
 	config := DatabaseConfig{Driver: "sqlite", Connection: "file:test.db?mode=memory"}
 	storage, _ := NewSQLStorage(config)
 	defer storage.Close()
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	synchronizer := NewPlanSynchronizer(storage, qdrantClient, nil)
 
 	// Test grouping
@@ -416,7 +428,10 @@ func TestRoundtripConsistency(t *testing.T) {
 		t.Fatalf("Failed to store original plan: %v", err)
 	}
 	// Step 1: Convert Dynamic → Markdown
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	syncConfig := &MarkdownSyncConfig{
 		OutputDirectory:   tempDir,
 		OverwriteExisting: true,
@@ -489,7 +504,10 @@ func TestFilenameSanitization(t *testing.T) {
 	storage, _ := NewSQLStorage(config)
 	defer storage.Close()
 
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	synchronizer := NewPlanSynchronizer(storage, qdrantClient, nil)
 
 	testCases := []struct {
@@ -520,7 +538,10 @@ func TestProgressionCalculation(t *testing.T) {
 	storage, _ := NewSQLStorage(config)
 	defer storage.Close()
 
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	synchronizer := NewPlanSynchronizer(storage, qdrantClient, nil)
 
 	testCases := []struct {
@@ -631,7 +652,10 @@ func TestSyncAllPlans(t *testing.T) {
 		}
 	}
 	// Create synchronizer and sync all
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	syncConfig := &MarkdownSyncConfig{
 		OutputDirectory:   tempDir,
 		OverwriteExisting: true,
@@ -708,7 +732,10 @@ func BenchmarkSyncToMarkdown(b *testing.B) {
 		b.Fatalf("Failed to store benchmark plan: %v", err)
 	}
 	tempDir := b.TempDir()
-	qdrantClient := NewQDrantClient("http://localhost:6333")
+	qdrantClient, err := NewQDrantClient("http://localhost:6333")
+	if err != nil {
+		t.Fatalf("Failed to create QDrant client: %v", err)
+	}
 	syncConfig := &MarkdownSyncConfig{
 		OutputDirectory:   tempDir,
 		OverwriteExisting: true,
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"email_sender/planning-ecosystem-sync/pkg/planembed"
+	"email_sender/planning-ecosystem-sync/pkg/qdrant"
+	"email_sender/planning-ecosystem-sync/pkg/vectorization"
+)
+
+// QDrantClient stores plan embeddings in Qdrant, one point per task, with the
+// plan and task IDs in the payload
+type QDrantClient struct {
+	store   *qdrant.UnifiedClient
+	indexer *planembed.Indexer
+	logger  *log.Logger
+	timeout time.Duration
+}
+
+// QDrantPoint is a plan returned by a similarity search
+type QDrantPoint struct {
+	PlanID  string   `json:"plan_id"`
+	Title   string   `json:"title"`
+	Score   float32  `json:"score"`
+	TaskIDs []string `json:"task_ids"` // Matching tasks, best first
+}
+
+// NewQDrantClient creates a client embedding tasks with the local provider
+func NewQDrantClient(baseURL string) (*QDrantClient, error) {
+	return NewQDrantClientWithEmbedder(baseURL, vectorization.NewHashingEmbeddingClient(vectorization.DefaultDimension), planembed.DefaultCollection)
+}
+
+// NewQDrantClientWithEmbedder creates a client using the given embedding provider and collection
+func NewQDrantClientWithEmbedder(baseURL string, embedder vectorization.EmbeddingClient, collection string) (*QDrantClient, error) {
+	store, err := qdrant.NewUnifiedClient(baseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Qdrant client: %w", err)
+	}
+
+	return &QDrantClient{
+		store:   store,
+		indexer: planembed.NewIndexer(store, embedder, collection, nil),
+		logger:  log.New(os.Stdout, "[QDRANT] ", log.LstdFlags),
+		timeout: 30 * time.Second,
+	}, nil
+}
+
+// EnsureCollection creates the task collection if needed
+func (qc *QDrantClient) EnsureCollection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), qc.timeout)
+	defer cancel()
+
+	if err := qc.indexer.EnsureCollection(ctx); err != nil {
+		return fmt.Errorf("failed to ensure collection %s: %w", qc.indexer.Collection(), err)
+	}
+	return nil
+}
+
+// StorePlanEmbeddings stores the task embeddings computed during conversion,
+// and removes the points of tasks deleted from the plan
+func (qc *QDrantClient) StorePlanEmbeddings(plan *DynamicPlan) error {
+	if len(plan.TaskEmbeddings) == 0 {
+		return fmt.Errorf("plan %s has no task embeddings to store", plan.ID)
+	}
+
+	embedPlan := toEmbedPlan(plan)
+	chunks := planembed.ChunkPlan(embedPlan)
+	if len(chunks) != len(plan.TaskEmbeddings) {
+		return fmt.Errorf("plan %s has %d task embeddings for %d tasks", plan.ID, len(plan.TaskEmbeddings), len(chunks))
+	}
+
+	vectors := make([][]float32, len(plan.TaskEmbeddings))
+	for i, embedding := range plan.TaskEmbeddings {
+		vectors[i] = toFloat32(embedding.Vector)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), qc.timeout)
+	defer cancel()
+
+	if err := qc.indexer.StoreChunks(ctx, embedPlan, chunks, vectors); err != nil {
+		return err
+	}
+
+	qc.logger.Printf("📡 Stored %d task embeddings for plan %s", len(vectors), plan.ID)
+	return nil
+}
+
+// SearchSimilarPlans returns the plans whose tasks are closest to a vector
+func (qc *QDrantClient) SearchSimilarPlans(embeddings []float64, limit int) ([]QDrantPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), qc.timeout)
+	defer cancel()
+
+	hits, err := qc.indexer.SearchPlans(ctx, toFloat32(embeddings), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]QDrantPoint, len(hits))
+	for i, hit := range hits {
+		points[i] = QDrantPoint{PlanID: hit.PlanID, Title: hit.Title, Score: hit.Score, TaskIDs: hit.TaskIDs}
+	}
+	return points, nil
+}
+
+// DeletePlanEmbeddings removes every task embedding of a plan
+func (qc *QDrantClient) DeletePlanEmbeddings(planID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), qc.timeout)
+	defer cancel()
+
+	return qc.indexer.DeletePlan(ctx, planID)
+}
+
+// HealthCheck verifies the Qdrant server is reachable
+func (qc *QDrantClient) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), qc.timeout)
+	defer cancel()
+
+	return qc.store.HealthCheck(ctx)
+}
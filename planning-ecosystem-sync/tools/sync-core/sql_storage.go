@@ -106,6 +106,9 @@ func (s *SQLStorage) initializeTables() error {
 		`CREATE INDEX IF NOT EXISTS idx_sync_logs_created_at ON sync_logs(created_at)`,
 	}
 
+	// Revision history of the plans
+	queries = append(queries, revisionTablesQueries()...)
+
 	for _, query := range queries {
 		if _, err := s.db.Exec(query); err != nil {
 			return fmt.Errorf("failed to execute query: %w\nQuery: %s", err, query)
@@ -116,8 +119,16 @@ func (s *SQLStorage) initializeTables() error {
 	return nil
 }
 
-// StorePlan stores a plan and its tasks in the SQL database
+// StorePlan stores a plan and its tasks in the SQL database and records it
+// as a new revision of the dynamic system
 func (s *SQLStorage) StorePlan(plan *DynamicPlan) error {
+	_, err := s.StorePlanRevision(plan, "", SourceDynamic)
+	return err
+}
+
+// storePlan writes the current state of a plan. The extra function runs
+// in the same transaction, before it is committed.
+func (s *SQLStorage) storePlan(plan *DynamicPlan, extra func(tx *sql.Tx) error) error {
 	s.logger.Printf("💾 Storing plan in SQL database: %s", plan.ID)
 
 	// Validate required fields
@@ -214,6 +225,12 @@ func (s *SQLStorage) StorePlan(plan *DynamicPlan) error {
 		}
 	}
 
+	if extra != nil {
+		if err := extra(tx); err != nil {
+			return err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -226,6 +243,25 @@ func (s *SQLStorage) StorePlan(plan *DynamicPlan) error {
 	return nil
 }
 
+// ListPlanIDs returns the IDs of the stored plans, ordered by ID
+func (s *SQLStorage) ListPlanIDs() ([]string, error) {
+	rows, err := s.db.Query(`SELECT id FROM plans ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan plan ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // GetPlan retrieves a plan from the SQL database
 func (s *SQLStorage) GetPlan(planID string) (*DynamicPlan, error) {
 	s.logger.Printf("📖 Retrieving plan from SQL database: %s", planID)
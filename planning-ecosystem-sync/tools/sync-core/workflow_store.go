@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workflow "email_sender/planning-ecosystem-sync/tools/workflow-orchestrator"
+)
+
+// Plans synchronized by the workflow orchestrator go through ConvertAndStore
+// when sync-core is linked in: the orchestrator then uses it as its default
+// dynamic store.
+func init() {
+	workflow.RegisterPlanConverter(newWorkflowConverter)
+}
+
+// workflowConverter maps the workflow plans to ConvertAndStore
+type workflowConverter struct {
+	orchestrator *SyncOrchestrator
+}
+
+// newWorkflowConverter opens the sync-core storage configured for the workflow
+func newWorkflowConverter(config *workflow.WorkflowConfig) (workflow.PlanConverter, error) {
+	syncConfig := SyncConfig{
+		QDrantURL: config.SyncCore.QDrantURL,
+		DatabaseConfig: DatabaseConfig{
+			Driver:     config.SyncCore.DatabaseDriver,
+			Connection: config.SyncCore.DatabaseConnection,
+		},
+	}
+	if syncConfig.QDrantURL == "" {
+		syncConfig.QDrantURL = "http://localhost:6333"
+	}
+	if syncConfig.DatabaseConfig.Driver == "" {
+		syncConfig.DatabaseConfig.Driver = "sqlite3"
+		syncConfig.DatabaseConfig.Connection = "file:plans.db?cache=shared&mode=rwc"
+	}
+
+	orchestrator, err := NewSyncOrchestrator(syncConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync orchestrator: %w", err)
+	}
+	return &workflowConverter{orchestrator: orchestrator}, nil
+}
+
+// ConvertAndStore implements workflow.PlanConverter
+func (c *workflowConverter) ConvertAndStore(ctx context.Context, plan *workflow.DynamicPlan) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filePath, _ := plan.Metadata["file_path"].(string)
+	metadata := &PlanMetadata{
+		FilePath:    filePath,
+		Title:       plan.Title,
+		Version:     plan.Version,
+		Progression: plan.Progress,
+	}
+
+	now := time.Now()
+	tasks := make([]Task, 0, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		phase, _ := task.Metadata["phase"].(string)
+		tasks = append(tasks, Task{
+			ID:           task.ID,
+			Title:        task.Title,
+			Description:  task.Description,
+			Status:       task.Status,
+			Phase:        phase,
+			Level:        1,
+			Dependencies: []string{},
+			Priority:     task.Priority,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Completed:    task.Status == "completed",
+		})
+	}
+	return c.orchestrator.ConvertAndStore(metadata, tasks)
+}
+
+// ListPlans implements workflow.PlanConverter
+func (c *workflowConverter) ListPlans(ctx context.Context) ([]workflow.DynamicPlan, error) {
+	ids, err := c.orchestrator.sqlStorage.ListPlanIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]workflow.DynamicPlan, 0, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		plan, err := c.orchestrator.GetPlanByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		converted := workflow.DynamicPlan{
+			ID:       plan.ID,
+			Title:    plan.Metadata.Title,
+			Version:  plan.Metadata.Version,
+			Progress: plan.Metadata.Progression,
+			Metadata: map[string]interface{}{"file_path": plan.Metadata.FilePath},
+			Tasks:    make([]workflow.Task, 0, len(plan.Tasks)),
+		}
+		for _, task := range plan.Tasks {
+			progress := 0.0
+			if task.Completed || task.Status == "completed" {
+				progress = 100
+			}
+			converted.Tasks = append(converted.Tasks, workflow.Task{
+				ID:          task.ID,
+				Title:       task.Title,
+				Description: task.Description,
+				Status:      task.Status,
+				Priority:    task.Priority,
+				Progress:    progress,
+				Metadata:    map[string]interface{}{"phase": task.Phase},
+			})
+		}
+		plans = append(plans, converted)
+	}
+	return plans, nil
+}
+
+// Close releases the sync-core storage
+func (c *workflowConverter) Close() error {
+	return c.orchestrator.Close()
+}
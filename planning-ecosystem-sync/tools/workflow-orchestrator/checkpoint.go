@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SyncCheckpoint records, for each sync point, the content hash of every item
+// last synchronized, so unchanged items are skipped on the next run
+type SyncCheckpoint struct {
+	Version    int                             `json:"version"`
+	SyncPoints map[string]*SyncPointCheckpoint `json:"sync_points"`
+
+	path  string
+	mutex sync.RWMutex
+}
+
+// SyncPointCheckpoint is the state of a single sync point
+type SyncPointCheckpoint struct {
+	LastSync time.Time         `json:"last_sync"`
+	Items    map[string]string `json:"items"` // Item ID -> content hash
+}
+
+// LoadCheckpoint reads the checkpoint at path; a missing file yields an empty checkpoint
+func LoadCheckpoint(path string) (*SyncCheckpoint, error) {
+	checkpoint := &SyncCheckpoint{
+		Version:    1,
+		SyncPoints: make(map[string]*SyncPointCheckpoint),
+		path:       path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if checkpoint.SyncPoints == nil {
+		checkpoint.SyncPoints = make(map[string]*SyncPointCheckpoint)
+	}
+	return checkpoint, nil
+}
+
+// Changed reports whether an item differs from its last synchronized version
+func (c *SyncCheckpoint) Changed(syncPointID, itemID, hash string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	point, exists := c.SyncPoints[syncPointID]
+	if !exists {
+		return true
+	}
+	return point.Items[itemID] != hash
+}
+
+// LastSync returns the time a sync point last completed
+func (c *SyncCheckpoint) LastSync(syncPointID string) time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if point, exists := c.SyncPoints[syncPointID]; exists {
+		return point.LastSync
+	}
+	return time.Time{}
+}
+
+// Commit records the items of a completed sync point and persists the checkpoint
+func (c *SyncCheckpoint) Commit(syncPointID string, items map[string]string) error {
+	c.mutex.Lock()
+	point, exists := c.SyncPoints[syncPointID]
+	if !exists {
+		point = &SyncPointCheckpoint{Items: make(map[string]string)}
+		c.SyncPoints[syncPointID] = point
+	}
+	for itemID, hash := range items {
+		point.Items[itemID] = hash
+	}
+	point.LastSync = time.Now()
+	c.mutex.Unlock()
+
+	return c.Save()
+}
+
+// Save writes the checkpoint atomically
+func (c *SyncCheckpoint) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mutex.RLock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to serialize checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// contentHash hashes the JSON form of a value
+func contentHash(value interface{}) string {
+	data, _ := json.Marshal(value)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -23,6 +23,7 @@ type WorkflowOrchestrator interface {
 	ExecuteFullSync(ctx context.Context) error
 	GetMetrics() *workflow.WorkflowMetrics
 	GetSyncPoints() []workflow.SyncPoint
+	GetPlannedMutations() []workflow.PlannedMutation
 	IsRunning() bool
 }
 
@@ -252,10 +253,8 @@ func (h *CommandHandler) handleStatus() error {
 func (h *CommandHandler) handleSync() error {
 	fmt.Printf("🔄 Executing Full Synchronization...\n")
 
-	if h.cliConfig.DryRun {
-		fmt.Printf("DRY RUN: Would execute full synchronization\n")
-		return nil
-	}
+	// In dry-run mode the sync points only record the mutations they would apply
+	h.config.DryRun = h.config.DryRun || h.cliConfig.DryRun
 
 	// Create orchestrator instance
 	h.orchestrator = NewWorkflowOrchestrator(h.config)
@@ -275,6 +274,14 @@ func (h *CommandHandler) handleSync() error {
 	}
 
 	duration := time.Since(startTime)
+	if h.config.DryRun {
+		mutations := h.orchestrator.GetPlannedMutations()
+		fmt.Printf("DRY RUN: %d planned mutation(s), nothing was changed\n", len(mutations))
+		for _, mutation := range mutations {
+			fmt.Printf("  [%s] %s %s → %s: %s\n", mutation.SyncPoint, mutation.Action, mutation.ItemID, mutation.Target, mutation.Detail)
+		}
+		return nil
+	}
 	fmt.Printf("✅ Full synchronization completed in %v\n", duration)
 
 	// Display metrics if verbose
@@ -428,9 +435,7 @@ func getFileSize(path string) string {
 	return "Unknown"
 }
 
-// Placeholder for the actual WorkflowOrchestrator constructor
-// In a real implementation, this would be imported from the workflow package
+// NewWorkflowOrchestrator creates the workflow orchestrator
 func NewWorkflowOrchestrator(config *workflow.WorkflowConfig) WorkflowOrchestrator {
-	// This is a placeholder - would return the actual implementation
-	return nil
+	return workflow.NewWorkflowOrchestrator(config)
 }
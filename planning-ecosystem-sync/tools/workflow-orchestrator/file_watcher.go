@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileWatcher triggers a synchronization when plan files change. Events are
+// collected until no new event arrived for debounceTime, then handled as one batch.
+type FileWatcher struct {
+	watchPaths   []string
+	filePatterns []string
+	debounceTime time.Duration
+	orchestrator *WorkflowOrchestrator
+	logger       *log.Logger
+}
+
+// Run watches the configured directories until ctx is cancelled
+func (fw *FileWatcher) Run(ctx context.Context, onChange func(files []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for _, root := range fw.watchPaths {
+		fw.addRecursive(watcher, root)
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(fw.debounceTime)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fw.logger.Printf("👁️ File watcher stopped")
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					fw.addRecursive(watcher, event.Name)
+					continue
+				}
+			}
+			if event.Op == fsnotify.Chmod || !fw.matches(event.Name) {
+				continue
+			}
+			// A removed or renamed path is no longer synchronized; a file
+			// renamed over it is reported by its own Create event
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				delete(pending, event.Name)
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(fw.debounceTime)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fw.logger.Printf("⚠️ Watcher error: %v", err)
+
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			files := make([]string, 0, len(pending))
+			for file := range pending {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			pending = make(map[string]bool)
+
+			fw.logger.Printf("📝 %d file change(s) detected", len(files))
+			onChange(files)
+		}
+	}
+}
+
+// addRecursive watches a directory and its subdirectories
+func (fw *FileWatcher) addRecursive(watcher *fsnotify.Watcher, root string) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fw.logger.Printf("⚠️ Cannot watch %s: %v", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			if err := watcher.Add(path); err != nil {
+				fw.logger.Printf("⚠️ Cannot watch %s: %v", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// matches reports whether a file name matches one of the watched patterns
+func (fw *FileWatcher) matches(path string) bool {
+	if len(fw.filePatterns) == 0 {
+		return true
+	}
+	name := filepath.Base(path)
+	for _, pattern := range fw.filePatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	roadmapconnector "email_sender/planning-ecosystem-sync/tools/roadmap-connector"
+)
+
+// DynamicStore is the dynamic system side of the workflow. A failed
+// StorePlans is retried with the whole batch, so it must either store every
+// plan or be safe to run again.
+type DynamicStore interface {
+	StorePlans(ctx context.Context, plans []*DynamicPlan) error
+	ListPlans(ctx context.Context) ([]DynamicPlan, error)
+}
+
+// PlanConverter converts a plan to the dynamic system format and stores it.
+// It is implemented by sync-core on top of SyncOrchestrator.ConvertAndStore
+// (SQL revision and Qdrant embeddings of one plan); storing unchanged
+// content again does not create a new revision.
+type PlanConverter interface {
+	ConvertAndStore(ctx context.Context, plan *DynamicPlan) error
+	ListPlans(ctx context.Context) ([]DynamicPlan, error)
+}
+
+// PlanConverterFactory opens the plan converter described by the configuration
+type PlanConverterFactory func(config *WorkflowConfig) (PlanConverter, error)
+
+var planConverterFactory PlanConverterFactory
+
+// RegisterPlanConverter makes sync-core the default dynamic store. sync-core
+// is built as a program and registers itself from an init function, the way
+// database/sql drivers do.
+func RegisterPlanConverter(factory PlanConverterFactory) {
+	planConverterFactory = factory
+}
+
+// SyncCoreStore adapts a PlanConverter to DynamicStore. Each plan is stored
+// in its own sync-core transaction: when one fails, the checkpoint is not
+// committed and the whole batch is stored again on the next run, which
+// leaves the plans already stored unchanged.
+type SyncCoreStore struct {
+	converter PlanConverter
+	logger    *log.Logger
+}
+
+// NewSyncCoreStore creates a dynamic store backed by sync-core
+func NewSyncCoreStore(converter PlanConverter) *SyncCoreStore {
+	return &SyncCoreStore{
+		converter: converter,
+		logger:    log.New(os.Stdout, "[DYNAMIC-STORE] ", log.LstdFlags),
+	}
+}
+
+// StorePlans converts and stores the plans one by one
+func (s *SyncCoreStore) StorePlans(ctx context.Context, plans []*DynamicPlan) error {
+	for i, plan := range plans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.converter.ConvertAndStore(ctx, plan); err != nil {
+			return fmt.Errorf("failed to convert plan %s (%d of %d stored): %w", plan.ID, i, len(plans), err)
+		}
+	}
+
+	s.logger.Printf("💾 Stored %d plan(s) through sync-core", len(plans))
+	return nil
+}
+
+// ListPlans returns the plans stored by sync-core
+func (s *SyncCoreStore) ListPlans(ctx context.Context) ([]DynamicPlan, error) {
+	return s.converter.ListPlans(ctx)
+}
+
+// Close releases the sync-core storage
+func (s *SyncCoreStore) Close() error {
+	if closer, ok := s.converter.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// RoadmapClient pushes plans to the Roadmap Manager. It is implemented by
+// roadmapconnector.RoadmapManagerConnector.
+type RoadmapClient interface {
+	SyncPlanToRoadmapManager(ctx context.Context, dynamicPlan interface{}) (*roadmapconnector.SyncResponse, error)
+}
+
+// TaskMasterClient pushes tasks to TaskMaster
+type TaskMasterClient interface {
+	SyncTask(ctx context.Context, task Task) error
+}
+
+// FileDynamicStore stores each plan as a JSON document in a directory.
+// It is the dynamic store used when sync-core is not linked in.
+type FileDynamicStore struct {
+	dir    string
+	logger *log.Logger
+}
+
+// NewFileDynamicStore creates a file-backed dynamic store
+func NewFileDynamicStore(dir string) *FileDynamicStore {
+	return &FileDynamicStore{
+		dir:    dir,
+		logger: log.New(os.Stdout, "[DYNAMIC-STORE] ", log.LstdFlags),
+	}
+}
+
+// StorePlans writes all plans or none of them
+func (s *FileDynamicStore) StorePlans(ctx context.Context, plans []*DynamicPlan) error {
+	files := make(map[string][]byte, len(plans))
+	for _, plan := range plans {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize plan %s: %w", plan.ID, err)
+		}
+		files[s.planPath(plan.ID)] = data
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := writeFilesAtomically(files); err != nil {
+		return err
+	}
+
+	s.logger.Printf("💾 Stored %d plan(s) in %s", len(plans), s.dir)
+	return nil
+}
+
+// ListPlans reads every stored plan, ordered by ID
+func (s *FileDynamicStore) ListPlans(ctx context.Context) ([]DynamicPlan, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dynamic plans: %w", err)
+	}
+
+	var plans []DynamicPlan
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		var plan DynamicPlan
+		if err := json.Unmarshal(data, &plan); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		plans = append(plans, plan)
+	}
+
+	sort.Slice(plans, func(i, j int) bool { return plans[i].ID < plans[j].ID })
+	return plans, nil
+}
+
+// planPath returns the file holding a plan
+func (s *FileDynamicStore) planPath(planID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, planID)
+	return filepath.Join(s.dir, name+".json")
+}
+
+// SyncTask runs the TaskMaster CLI for a single task
+func (ta *TaskMasterAdapter) SyncTask(ctx context.Context, task Task) error {
+	args := []string{
+		"task", "sync",
+		"--id", task.ID,
+		"--title", task.Title,
+		"--status", task.Status,
+		"--priority", task.Priority,
+	}
+	if ta.configPath != "" {
+		args = append(args, "--config", ta.configPath)
+	}
+
+	cmd := exec.CommandContext(ctx, ta.cliPath, args...)
+	cmd.Env = os.Environ()
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		ta.logger.Printf("TaskMaster CLI output: %s", string(output))
+		return fmt.Errorf("taskmaster command failed: %w", err)
+	}
+	return nil
+}
+
+// toRoadmapPlan converts a plan to the connector's dynamic format, one phase
+// per distinct task phase
+func toRoadmapPlan(plan DynamicPlan) *roadmapconnector.DynamicPlan {
+	now := time.Now()
+	result := &roadmapconnector.DynamicPlan{
+		ID:        plan.ID,
+		Title:     plan.Title,
+		Version:   plan.Version,
+		Progress:  plan.Progress,
+		Metadata:  plan.Metadata,
+		Status:    planStatus(plan),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	phaseIndex := make(map[string]int)
+	for _, task := range plan.Tasks {
+		phase := taskPhase(task)
+		index, exists := phaseIndex[phase]
+		if !exists {
+			index = len(result.Phases)
+			phaseIndex[phase] = index
+			result.Phases = append(result.Phases, roadmapconnector.DynamicPhase{
+				ID:     fmt.Sprintf("%s-phase-%d", plan.ID, index+1),
+				Name:   phase,
+				Status: "in_progress",
+				Order:  index + 1,
+			})
+		}
+		phaseID := result.Phases[index].ID
+		result.Phases[index].TaskIDs = append(result.Phases[index].TaskIDs, task.ID)
+
+		result.Tasks = append(result.Tasks, roadmapconnector.DynamicTask{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      task.Status,
+			Priority:    priorityLevel(task.Priority),
+			Progress:    task.Progress,
+			PhaseID:     phaseID,
+			Metadata:    task.Metadata,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	return result
+}
+
+// planStatus derives the status of a plan from its progress
+func planStatus(plan DynamicPlan) string {
+	switch {
+	case plan.Progress >= 100:
+		return "completed"
+	case plan.Progress > 0:
+		return "in_progress"
+	default:
+		return "not_started"
+	}
+}
+
+// taskPhase returns the phase recorded in the task metadata
+func taskPhase(task Task) string {
+	if phase, ok := task.Metadata["phase"].(string); ok && phase != "" {
+		return phase
+	}
+	return "General"
+}
+
+// priorityLevel maps a textual priority to the Roadmap Manager scale
+func priorityLevel(priority string) int {
+	switch strings.ToLower(priority) {
+	case "critical":
+		return 1
+	case "high":
+		return 2
+	case "low":
+		return 4
+	default:
+		return 3
+	}
+}
+
+// writeFilesAtomically writes every file or none: contents are staged in
+// temporary files first, and files already replaced are restored if a rename fails
+func writeFilesAtomically(files map[string][]byte) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	staged := make(map[string]string, len(paths))
+	cleanup := func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+	}
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			cleanup()
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, files[path], 0644); err != nil {
+			cleanup()
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+		staged[path] = tmp
+	}
+
+	// Keep the previous contents to roll back a partial commit
+	previous := make(map[string][]byte)
+	var replaced []string
+	for _, path := range paths {
+		if data, err := os.ReadFile(path); err == nil {
+			previous[path] = data
+		}
+		if err := os.Rename(staged[path], path); err != nil {
+			cleanup()
+			for _, done := range replaced {
+				if data, ok := previous[done]; ok {
+					os.WriteFile(done, data, 0644)
+				} else {
+					os.Remove(done)
+				}
+			}
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		delete(staged, path)
+		replaced = append(replaced, path)
+	}
+
+	return nil
+}
@@ -2,30 +2,36 @@ package workflow
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	roadmapconnector "email_sender/planning-ecosystem-sync/tools/roadmap-connector"
 )
 
 // WorkflowOrchestrator manages the unified workflow between Markdown, Dynamic system, and Roadmap Manager
 type WorkflowOrchestrator struct {
 	config            *WorkflowConfig
 	syncEngine        *SyncEngine
-	roadmapConnector  *RoadmapConnector
-	taskMasterAdapter *TaskMasterAdapter
+	dynamicStore      DynamicStore
+	roadmapClient     RoadmapClient
+	taskMasterAdapter TaskMasterClient
 	fileWatcher       *FileWatcher
+	checkpoint        *SyncCheckpoint
 	logger            *log.Logger
 	metrics           *WorkflowMetrics
 	syncPoints        []SyncPoint
+	plannedMutations  []PlannedMutation
 	isRunning         bool
 	mutex             sync.RWMutex
+	syncMutex         sync.Mutex // Serializes synchronization runs
 }
 
 // WorkflowConfig holds configuration for the unified workflow
@@ -36,6 +42,16 @@ type WorkflowConfig struct {
 	MaxRetries         int           `yaml:"max_retries"`
 	BackupEnabled      bool          `yaml:"backup_enabled"`
 	AlertsEnabled      bool          `yaml:"alerts_enabled"`
+	DryRun             bool          `yaml:"dry_run"`            // Record planned mutations without applying them
+	CheckpointPath     string        `yaml:"checkpoint_path"`    // Persisted state of the last synchronization
+	DynamicStorePath   string        `yaml:"dynamic_store_path"` // Directory of the file-backed dynamic store
+
+	// sync-core dynamic system, the default store when sync-core is linked in
+	SyncCore struct {
+		DatabaseDriver     string `yaml:"database_driver"`
+		DatabaseConnection string `yaml:"database_connection"`
+		QDrantURL          string `yaml:"qdrant_url"`
+	} `yaml:"sync_core"`
 
 	// Synchronization points configuration
 	SyncPoints struct {
@@ -44,6 +60,27 @@ type WorkflowConfig struct {
 		RoadmapManager    bool `yaml:"roadmap_manager"`
 		TaskMasterCLI     bool `yaml:"taskmaster_cli"`
 	} `yaml:"sync_points"`
+
+	// File watching configuration
+	FileWatcher struct {
+		Enabled          bool          `yaml:"enabled"`
+		WatchPaths       []string      `yaml:"watch_paths"`
+		FilePatterns     []string      `yaml:"file_patterns"`
+		DebounceInterval time.Duration `yaml:"debounce_interval"`
+	} `yaml:"file_watcher"`
+
+	// Roadmap Manager integration
+	RoadmapManager struct {
+		BaseURL       string        `yaml:"base_url"`
+		Timeout       time.Duration `yaml:"timeout"`
+		RetryAttempts int           `yaml:"retry_attempts"`
+	} `yaml:"roadmap_manager"`
+
+	// TaskMaster CLI integration
+	TaskMasterCLI struct {
+		CLIPath    string `yaml:"cli_path"`
+		ConfigPath string `yaml:"config_path"`
+	} `yaml:"taskmaster_cli"`
 }
 
 // SyncPoint represents a synchronization point in the workflow
@@ -58,6 +95,16 @@ type SyncPoint struct {
 	Enabled  bool      `json:"enabled"`
 }
 
+// PlannedMutation describes a change made by a sync point, or that would be
+// made in dry-run mode
+type PlannedMutation struct {
+	SyncPoint string `json:"sync_point"`
+	Target    string `json:"target"`
+	Action    string `json:"action"` // "store", "write", "push"
+	ItemID    string `json:"item_id"`
+	Detail    string `json:"detail"`
+}
+
 // WorkflowMetrics tracks performance and health metrics
 type WorkflowMetrics struct {
 	TotalSyncs        int64         `json:"total_syncs"`
@@ -82,6 +129,24 @@ func NewWorkflowOrchestrator(config *WorkflowConfig) *WorkflowOrchestrator {
 	}
 }
 
+// SetDynamicStore replaces the default dynamic store. It must be called
+// before Initialize.
+func (wo *WorkflowOrchestrator) SetDynamicStore(store DynamicStore) {
+	wo.dynamicStore = store
+}
+
+// SetRoadmapClient replaces the default Roadmap Manager connector. It must be
+// called before Initialize.
+func (wo *WorkflowOrchestrator) SetRoadmapClient(client RoadmapClient) {
+	wo.roadmapClient = client
+}
+
+// SetTaskMasterClient replaces the default TaskMaster CLI adapter. It must be
+// called before Initialize.
+func (wo *WorkflowOrchestrator) SetTaskMasterClient(client TaskMasterClient) {
+	wo.taskMasterAdapter = client
+}
+
 // Initialize sets up all components and synchronization points
 func (wo *WorkflowOrchestrator) Initialize() error {
 	wo.logger.Printf("🚀 Initializing Workflow Orchestrator...")
@@ -172,28 +237,86 @@ func (wo *WorkflowOrchestrator) initializeComponents() error {
 		logger: log.New(os.Stdout, "[SYNC-ENGINE] ", log.LstdFlags),
 	}
 
+	// Initialize the dynamic store: sync-core when it is linked in, JSON
+	// files otherwise
+	if wo.dynamicStore == nil && planConverterFactory != nil {
+		converter, err := planConverterFactory(wo.config)
+		if err != nil {
+			return fmt.Errorf("failed to initialize sync-core: %w", err)
+		}
+		wo.dynamicStore = NewSyncCoreStore(converter)
+	}
+	if wo.dynamicStore == nil {
+		storePath := wo.config.DynamicStorePath
+		if storePath == "" {
+			storePath = "./data/dynamic-plans"
+		}
+		wo.dynamicStore = NewFileDynamicStore(storePath)
+	}
+
 	// Initialize roadmap connector
-	wo.roadmapConnector = &RoadmapConnector{
-		baseURL: "http://localhost:8080",
-		apiKey:  "",
-		logger:  log.New(os.Stdout, "[ROADMAP-CONNECTOR] ", log.LstdFlags),
+	if wo.roadmapClient == nil {
+		connectorConfig := &roadmapconnector.ConnectorConfig{
+			BaseURL:    wo.config.RoadmapManager.BaseURL,
+			Timeout:    wo.config.RoadmapManager.Timeout,
+			MaxRetries: wo.config.RoadmapManager.RetryAttempts,
+		}
+		if connectorConfig.BaseURL == "" {
+			connectorConfig.BaseURL = "http://localhost:8080"
+		}
+		if connectorConfig.Timeout == 0 {
+			connectorConfig.Timeout = 30 * time.Second
+		}
+		wo.roadmapClient = roadmapconnector.NewRoadmapManagerConnector(connectorConfig)
 	}
 
 	// Initialize TaskMaster adapter
-	wo.taskMasterAdapter = &TaskMasterAdapter{
-		cliPath:    "./development/managers/roadmap-manager/roadmap-cli/roadmap-cli.exe",
-		configPath: "./config/taskmaster-config.yaml",
-		logger:     log.New(os.Stdout, "[TASKMASTER-ADAPTER] ", log.LstdFlags),
+	if wo.taskMasterAdapter == nil {
+		adapter := &TaskMasterAdapter{
+			cliPath:    wo.config.TaskMasterCLI.CLIPath,
+			configPath: wo.config.TaskMasterCLI.ConfigPath,
+			logger:     log.New(os.Stdout, "[TASKMASTER-ADAPTER] ", log.LstdFlags),
+		}
+		if adapter.cliPath == "" {
+			adapter.cliPath = "./development/managers/roadmap-manager/roadmap-cli/roadmap-cli.exe"
+		}
+		if adapter.configPath == "" {
+			adapter.configPath = "./config/taskmaster-config.yaml"
+		}
+		wo.taskMasterAdapter = adapter
 	}
 
 	// Initialize file watcher
 	wo.fileWatcher = &FileWatcher{
-		watchPaths:   []string{"./projet/roadmaps/plans/", "./projet/roadmaps/plans/consolidated/"},
-		filePatterns: []string{"*.md", "plan-dev-*.md"},
-		debounceTime: 2 * time.Second,
+		watchPaths:   wo.config.FileWatcher.WatchPaths,
+		filePatterns: wo.config.FileWatcher.FilePatterns,
+		debounceTime: wo.config.FileWatcher.DebounceInterval,
 		orchestrator: wo,
 		logger:       log.New(os.Stdout, "[FILE-WATCHER] ", log.LstdFlags),
 	}
+	if len(wo.fileWatcher.watchPaths) == 0 {
+		wo.fileWatcher.watchPaths = []string{"./projet/roadmaps/plans/", "./projet/roadmaps/plans/consolidated/"}
+	}
+	if len(wo.fileWatcher.filePatterns) == 0 {
+		wo.fileWatcher.filePatterns = []string{"*.md", "plan-dev-*.md"}
+	}
+	if wo.fileWatcher.debounceTime == 0 {
+		wo.fileWatcher.debounceTime = 2 * time.Second
+	}
+
+	// Load the checkpoint of the last synchronization
+	checkpointPath := wo.config.CheckpointPath
+	if checkpointPath == "" {
+		checkpointPath = "./data/workflow-checkpoint.json"
+	}
+	checkpoint, err := LoadCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+	wo.checkpoint = checkpoint
+	for i := range wo.syncPoints {
+		wo.syncPoints[i].LastSync = checkpoint.LastSync(wo.syncPoints[i].ID)
+	}
 
 	wo.logger.Printf("✅ All components initialized")
 	return nil
@@ -220,7 +343,9 @@ func (wo *WorkflowOrchestrator) Start(ctx context.Context) error {
 	}
 
 	// Start file watching if enabled
-	go wo.startFileWatching(ctx)
+	if wo.config.FileWatcher.Enabled {
+		go wo.startFileWatching(ctx)
+	}
 
 	wo.logger.Printf("✅ Workflow orchestration started successfully")
 	return nil
@@ -238,6 +363,12 @@ func (wo *WorkflowOrchestrator) Stop() error {
 	wo.logger.Printf("🛑 Stopping workflow orchestration...")
 	wo.isRunning = false
 
+	if closer, ok := wo.dynamicStore.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			wo.logger.Printf("⚠️ Failed to close dynamic store: %v", err)
+		}
+	}
+
 	wo.logger.Printf("✅ Workflow orchestration stopped")
 	return nil
 }
@@ -245,10 +376,32 @@ func (wo *WorkflowOrchestrator) Stop() error {
 // ExecuteFullSync performs a complete synchronization across all sync points
 func (wo *WorkflowOrchestrator) ExecuteFullSync(ctx context.Context) error {
 	wo.logger.Printf("🔄 Executing full workflow synchronization...")
+	return wo.runSyncPoints(ctx, nil)
+}
+
+// SyncFiles synchronizes the given Markdown files, then propagates the
+// resulting changes through the downstream sync points
+func (wo *WorkflowOrchestrator) SyncFiles(ctx context.Context, files []string) error {
+	wo.logger.Printf("🔄 Synchronizing %d changed file(s)...", len(files))
+	if files == nil {
+		files = []string{}
+	}
+	return wo.runSyncPoints(ctx, files)
+}
+
+// runSyncPoints executes the enabled sync points in priority order. A nil
+// files list makes the Markdown sync point discover every plan file.
+func (wo *WorkflowOrchestrator) runSyncPoints(ctx context.Context, files []string) error {
+	wo.syncMutex.Lock()
+	defer wo.syncMutex.Unlock()
 
 	startTime := time.Now()
 	var errors []error
 
+	wo.mutex.Lock()
+	wo.plannedMutations = nil
+	wo.mutex.Unlock()
+
 	// Execute sync points in priority order
 	for _, syncPoint := range wo.getSortedSyncPoints() {
 		if !syncPoint.Enabled {
@@ -257,13 +410,21 @@ func (wo *WorkflowOrchestrator) ExecuteFullSync(ctx context.Context) error {
 
 		wo.logger.Printf("📍 Executing sync point: %s", syncPoint.Name)
 
-		if err := wo.executeSyncPoint(ctx, &syncPoint); err != nil {
+		var err error
+		if syncPoint.ID == "markdown_to_dynamic" && files != nil {
+			err = wo.syncMarkdownFiles(ctx, files)
+		} else {
+			err = wo.executeSyncPoint(ctx, &syncPoint)
+		}
+
+		if err != nil {
 			wo.logger.Printf("❌ Sync point %s failed: %v", syncPoint.ID, err)
 			errors = append(errors, err)
 			wo.metrics.FailedSyncs++
 		} else {
 			wo.logger.Printf("✅ Sync point %s completed successfully", syncPoint.ID)
 			wo.metrics.SuccessfulSyncs++
+			wo.updateSyncPointTime(syncPoint.ID)
 		}
 
 		wo.metrics.TotalSyncs++
@@ -282,6 +443,48 @@ func (wo *WorkflowOrchestrator) ExecuteFullSync(ctx context.Context) error {
 	return nil
 }
 
+// updateSyncPointTime records the completion time of a sync point
+func (wo *WorkflowOrchestrator) updateSyncPointTime(syncPointID string) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+
+	for i := range wo.syncPoints {
+		if wo.syncPoints[i].ID == syncPointID {
+			wo.syncPoints[i].LastSync = time.Now()
+		}
+	}
+}
+
+// runSyncPoint applies the mutations of a sync point as one unit. In dry-run
+// mode they are only recorded; otherwise apply runs and the checkpoint is
+// committed only if it succeeded, so a failed sync point is retried as a whole.
+func (wo *WorkflowOrchestrator) runSyncPoint(syncPointID string, mutations []PlannedMutation, items map[string]string, apply func() error) error {
+	wo.mutex.Lock()
+	wo.plannedMutations = append(wo.plannedMutations, mutations...)
+	wo.mutex.Unlock()
+
+	if len(mutations) == 0 {
+		wo.logger.Printf("✔️ %s: nothing changed since the last sync", syncPointID)
+		return nil
+	}
+
+	if wo.config.DryRun {
+		for _, mutation := range mutations {
+			wo.logger.Printf("🧪 DRY RUN [%s] %s %s on %s: %s",
+				mutation.SyncPoint, mutation.Action, mutation.ItemID, mutation.Target, mutation.Detail)
+		}
+		return nil
+	}
+
+	if err := apply(); err != nil {
+		return err
+	}
+	if err := wo.checkpoint.Commit(syncPointID, items); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
 // executeSyncPoint executes a specific synchronization point
 func (wo *WorkflowOrchestrator) executeSyncPoint(ctx context.Context, syncPoint *SyncPoint) error {
 	switch syncPoint.ID {
@@ -309,26 +512,51 @@ func (wo *WorkflowOrchestrator) executeMarkdownToDynamicSync(ctx context.Context
 	}
 
 	wo.logger.Printf("📄 Found %d markdown files to process", len(markdownFiles))
+	return wo.syncMarkdownFiles(ctx, markdownFiles)
+}
+
+// syncMarkdownFiles parses the files changed since the last sync and stores
+// the resulting plans in the dynamic system in a single transaction
+func (wo *WorkflowOrchestrator) syncMarkdownFiles(ctx context.Context, files []string) error {
+	var plans []*DynamicPlan
+	var mutations []PlannedMutation
+	items := make(map[string]string)
 
-	// Step 2: Process each file in batches
-	batchSize := wo.syncEngine.config.BatchSize
-	for i := 0; i < len(markdownFiles); i += batchSize {
-		end := i + batchSize
-		if end > len(markdownFiles) {
-			end = len(markdownFiles)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			// Deleted or renamed since the change: the dynamic system is left untouched
+			wo.logger.Printf("🗑️ Ignoring removed file: %s", file)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
 		}
 
-		batch := markdownFiles[i:end]
-		if err := wo.processBatchMarkdownToDynamic(ctx, batch); err != nil {
-			wo.logger.Printf("❌ Batch processing failed: %v", err)
-			return err
+		hash := contentHash(string(content))
+		if !wo.checkpoint.Changed("markdown_to_dynamic", file, hash) {
+			continue
 		}
 
-		wo.logger.Printf("✅ Processed batch %d/%d", end, len(markdownFiles))
+		plan := wo.parseMarkdownPlan(string(content), file)
+		plans = append(plans, plan)
+		items[file] = hash
+		mutations = append(mutations, PlannedMutation{
+			SyncPoint: "markdown_to_dynamic",
+			Target:    "dynamic",
+			Action:    "store",
+			ItemID:    plan.ID,
+			Detail:    fmt.Sprintf("%s (%d tasks, %.1f%%)", file, len(plan.Tasks), plan.Progress),
+		})
 	}
 
-	wo.logger.Printf("✅ Markdown to Dynamic sync completed")
-	return nil
+	return wo.runSyncPoint("markdown_to_dynamic", mutations, items, func() error {
+		if err := wo.dynamicStore.StorePlans(ctx, plans); err != nil {
+			return fmt.Errorf("failed to store plans in dynamic system: %w", err)
+		}
+		wo.logger.Printf("✅ Stored %d plan(s) in dynamic system", len(plans))
+		return nil
+	})
 }
 
 // executeDynamicToMarkdownSync performs Dynamic system to Markdown sync with concrete implementation
@@ -343,26 +571,41 @@ func (wo *WorkflowOrchestrator) executeDynamicToMarkdownSync(ctx context.Context
 
 	wo.logger.Printf("💾 Retrieved %d dynamic plans", len(dynamicData))
 
-	// Step 2: Convert to Markdown format
+	// Step 2: Convert the changed plans to Markdown format
+	files := make(map[string][]byte)
+	items := make(map[string]string)
+	var mutations []PlannedMutation
 	for _, plan := range dynamicData {
-		markdownContent, err := wo.convertDynamicToMarkdown(plan)
-		if err != nil {
-			wo.logger.Printf("❌ Failed to convert plan %s: %v", plan.ID, err)
+		hash := contentHash(plan)
+		if !wo.checkpoint.Changed("dynamic_to_markdown", plan.ID, hash) {
 			continue
 		}
 
-		// Step 3: Write to file
-		outputPath := wo.generateMarkdownPath(plan)
-		if err := wo.writeMarkdownFile(outputPath, markdownContent); err != nil {
-			wo.logger.Printf("❌ Failed to write %s: %v", outputPath, err)
-			continue
+		markdownContent, err := wo.convertDynamicToMarkdown(plan)
+		if err != nil {
+			return fmt.Errorf("failed to convert plan %s: %w", plan.ID, err)
 		}
 
-		wo.logger.Printf("✅ Generated %s", outputPath)
+		outputPath := wo.generateMarkdownPath(plan)
+		files[outputPath] = []byte(markdownContent)
+		items[plan.ID] = hash
+		mutations = append(mutations, PlannedMutation{
+			SyncPoint: "dynamic_to_markdown",
+			Target:    "markdown",
+			Action:    "write",
+			ItemID:    plan.ID,
+			Detail:    outputPath,
+		})
 	}
 
-	wo.logger.Printf("✅ Dynamic to Markdown sync completed")
-	return nil
+	// Step 3: Write all files or none
+	return wo.runSyncPoint("dynamic_to_markdown", mutations, items, func() error {
+		if err := writeFilesAtomically(files); err != nil {
+			return err
+		}
+		wo.logger.Printf("✅ Generated %d Markdown file(s)", len(files))
+		return nil
+	})
 }
 
 // executeRoadmapManagerSync performs sync with Roadmap Manager with concrete implementation
@@ -375,25 +618,41 @@ func (wo *WorkflowOrchestrator) executeRoadmapManagerSync(ctx context.Context) e
 		return fmt.Errorf("failed to fetch dynamic plans: %w", err)
 	}
 
-	// Step 2: Convert to Roadmap Manager format
+	// Step 2: Select the plans changed since the last push
+	var changed []DynamicPlan
+	items := make(map[string]string)
+	var mutations []PlannedMutation
 	for _, plan := range dynamicPlans {
-		roadmapData, err := wo.convertToRoadmapFormat(plan)
-		if err != nil {
-			wo.logger.Printf("❌ Failed to convert plan %s: %v", plan.ID, err)
-			continue
-		}
-
-		// Step 3: Send to Roadmap Manager
-		if err := wo.sendToRoadmapManager(ctx, roadmapData); err != nil {
-			wo.logger.Printf("❌ Failed to sync plan %s to Roadmap Manager: %v", plan.ID, err)
+		hash := contentHash(plan)
+		if !wo.checkpoint.Changed("roadmap_manager_sync", plan.ID, hash) {
 			continue
 		}
-
-		wo.logger.Printf("✅ Synced plan %s to Roadmap Manager", plan.ID)
+		changed = append(changed, plan)
+		items[plan.ID] = hash
+		mutations = append(mutations, PlannedMutation{
+			SyncPoint: "roadmap_manager_sync",
+			Target:    "roadmap_manager",
+			Action:    "push",
+			ItemID:    plan.ID,
+			Detail:    fmt.Sprintf("%s (%d tasks)", plan.Title, len(plan.Tasks)),
+		})
 	}
 
-	wo.logger.Printf("✅ Roadmap Manager sync completed")
-	return nil
+	// Step 3: Send to Roadmap Manager. Remote changes cannot be rolled back:
+	// the checkpoint only advances once every plan was accepted.
+	return wo.runSyncPoint("roadmap_manager_sync", mutations, items, func() error {
+		for _, plan := range changed {
+			response, err := wo.roadmapClient.SyncPlanToRoadmapManager(ctx, toRoadmapPlan(plan))
+			if err != nil {
+				return fmt.Errorf("failed to sync plan %s to Roadmap Manager: %w", plan.ID, err)
+			}
+			if response != nil && !response.Success {
+				return fmt.Errorf("roadmap manager rejected plan %s: %s", plan.ID, response.Message)
+			}
+			wo.logger.Printf("✅ Synced plan %s to Roadmap Manager", plan.ID)
+		}
+		return nil
+	})
 }
 
 // executeTaskMasterSync performs sync with TaskMaster CLI with concrete implementation
@@ -406,20 +665,38 @@ func (wo *WorkflowOrchestrator) executeTaskMasterSync(ctx context.Context) error
 		return fmt.Errorf("failed to fetch tasks: %w", err)
 	}
 
-	wo.logger.Printf("📋 Found %d tasks to sync", len(tasks))
+	wo.logger.Printf("📋 Found %d tasks in dynamic system", len(tasks))
 
-	// Step 2: Sync to TaskMaster CLI
+	// Step 2: Select the tasks changed since the last sync
+	var changed []Task
+	items := make(map[string]string)
+	var mutations []PlannedMutation
 	for _, task := range tasks {
-		if err := wo.syncTaskToTaskMaster(ctx, task); err != nil {
-			wo.logger.Printf("❌ Failed to sync task %s: %v", task.ID, err)
+		hash := contentHash(task)
+		if !wo.checkpoint.Changed("taskmaster_sync", task.ID, hash) {
 			continue
 		}
-
-		wo.logger.Printf("✅ Synced task %s", task.Title)
+		changed = append(changed, task)
+		items[task.ID] = hash
+		mutations = append(mutations, PlannedMutation{
+			SyncPoint: "taskmaster_sync",
+			Target:    "taskmaster",
+			Action:    "push",
+			ItemID:    task.ID,
+			Detail:    fmt.Sprintf("%s [%s]", task.Title, task.Status),
+		})
 	}
 
-	wo.logger.Printf("✅ TaskMaster CLI sync completed")
-	return nil
+	// Step 3: Sync to TaskMaster CLI
+	return wo.runSyncPoint("taskmaster_sync", mutations, items, func() error {
+		for _, task := range changed {
+			if err := wo.taskMasterAdapter.SyncTask(ctx, task); err != nil {
+				return fmt.Errorf("failed to sync task %s: %w", task.ID, err)
+			}
+			wo.logger.Printf("✅ Synced task %s", task.Title)
+		}
+		return nil
+	})
 }
 
 // Helper functions for unified workflow implementation
@@ -438,11 +715,8 @@ func (wo *WorkflowOrchestrator) discoverMarkdownFiles() ([]string, error) {
 				return nil // Continue on errors
 			}
 
-			if !d.IsDir() && strings.HasSuffix(path, ".md") {
-				// Filter for plan files
-				if strings.Contains(path, "plan-dev-") || strings.Contains(path, "roadmap") {
-					files = append(files, path)
-				}
+			if !d.IsDir() && isPlanFile(path) {
+				files = append(files, path)
 			}
 			return nil
 		})
@@ -455,26 +729,10 @@ func (wo *WorkflowOrchestrator) discoverMarkdownFiles() ([]string, error) {
 	return files, nil
 }
 
-func (wo *WorkflowOrchestrator) processBatchMarkdownToDynamic(ctx context.Context, files []string) error {
-	for _, file := range files {
-		wo.logger.Printf("📝 Processing %s", file)
-
-		// Read and parse Markdown file
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
-
-		// Parse plan structure (simplified implementation)
-		planData := wo.parseMarkdownPlan(string(content), file)
-
-		// Send to dynamic system (simulate)
-		if err := wo.sendToDynamicSystem(ctx, planData); err != nil {
-			return fmt.Errorf("failed to sync %s to dynamic system: %w", file, err)
-		}
-	}
-
-	return nil
+// isPlanFile reports whether a path is a Markdown plan
+func isPlanFile(path string) bool {
+	return strings.HasSuffix(path, ".md") &&
+		(strings.Contains(path, "plan-dev-") || strings.Contains(path, "roadmap"))
 }
 
 type DynamicPlan struct {
@@ -497,68 +755,95 @@ type Task struct {
 }
 
 func (wo *WorkflowOrchestrator) fetchDynamicSystemData(ctx context.Context) ([]DynamicPlan, error) {
-	// Simulate fetching from dynamic system
-	// In real implementation, this would query QDrant/SQL databases
 	wo.logger.Printf("🔍 Fetching data from dynamic system...")
-
-	// For now, return mock data
-	return []DynamicPlan{
-		{
-			ID:       "plan-dev-v55",
-			Title:    "Plan-dev-v55 - Planning Ecosystem Synchronization",
-			Version:  "2.2",
-			Progress: 98.0,		Metadata: map[string]interface{}{
-			"author":      "System",
-			"updated_at":  time.Now(),
-			"file_path":   "plan-dev-v55-planning-ecosystem-sync.md",
-		},
-			Tasks: []Task{
-				{
-					ID:          "task-5.2.1",
-					Title:       "Unification des Workflows",
-					Description: "Create unified workflow for all systems",
-					Status:      "in_progress",
-					Priority:    "high",
-					Progress:    50.0,
-				},
-			},
-		},
-	}, nil
+	return wo.dynamicStore.ListPlans(ctx)
 }
 
+var (
+	checkboxPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+)$`)
+	numberPattern   = regexp.MustCompile(`^(\d+(?:\.\d+)*)\.?\s+`)
+	versionPattern  = regexp.MustCompile(`(?i)\bversion\s*:?\s*v?([0-9][0-9A-Za-z.\-]*)`)
+	progressPattern = regexp.MustCompile(`(?i)\b(?:progress|progression)\s*:?\s*([0-9]+(?:[.,][0-9]+)?)\s*%`)
+)
+
 func (wo *WorkflowOrchestrator) parseMarkdownPlan(content, filePath string) *DynamicPlan {
-	// Simplified Markdown parsing
-	// In real implementation, this would use the existing markdown parser
+	// Simplified Markdown parsing: the title, version and progress lines,
+	// and one task per checkbox under the current "##" phase heading
 	lines := strings.Split(content, "\n")
 
 	plan := &DynamicPlan{
-		ID:       filepath.Base(filePath),
+		ID:       strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)),
 		Metadata: make(map[string]interface{}),
 		Tasks:    []Task{},
 	}
 
-	// Extract title
+	phase := ""
+	progressDeclared := false
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "# ") {
-			plan.Title = strings.TrimPrefix(line, "# ")
-			break
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "# ") && plan.Title == "":
+			plan.Title = strings.TrimPrefix(trimmed, "# ")
+			continue
+		case strings.HasPrefix(trimmed, "## "):
+			phase = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			continue
 		}
+
+		if match := checkboxPattern.FindStringSubmatch(line); match != nil {
+			title := strings.TrimSpace(match[2])
+			id := fmt.Sprintf("%s-task-%d", plan.ID, len(plan.Tasks)+1)
+			if number := numberPattern.FindStringSubmatch(title); number != nil {
+				id = fmt.Sprintf("%s-%s", plan.ID, number[1])
+			}
+
+			status, progress := "pending", 0.0
+			if match[1] != " " {
+				status, progress = "completed", 100.0
+			}
+			plan.Tasks = append(plan.Tasks, Task{
+				ID:       id,
+				Title:    title,
+				Status:   status,
+				Priority: "medium",
+				Progress: progress,
+				Metadata: map[string]interface{}{"phase": phase},
+			})
+			continue
+		}
+
+		if plan.Version == "" {
+			if match := versionPattern.FindStringSubmatch(trimmed); match != nil {
+				plan.Version = match[1]
+			}
+		}
+		if !progressDeclared {
+			if match := progressPattern.FindStringSubmatch(trimmed); match != nil {
+				if value, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64); err == nil {
+					plan.Progress = value
+					progressDeclared = true
+				}
+			}
+		}
+	}
+
+	// Without a declared progress, use the share of completed tasks
+	if !progressDeclared && len(plan.Tasks) > 0 {
+		completed := 0
+		for _, task := range plan.Tasks {
+			if task.Status == "completed" {
+				completed++
+			}
+		}
+		plan.Progress = float64(completed) / float64(len(plan.Tasks)) * 100
 	}
 
 	plan.Metadata["file_path"] = filePath
-	plan.Metadata["parsed_at"] = time.Now()
 
 	return plan
 }
 
-func (wo *WorkflowOrchestrator) sendToDynamicSystem(ctx context.Context, plan *DynamicPlan) error {
-	// Simulate sending to dynamic system
-	wo.logger.Printf("💾 Storing plan %s in dynamic system", plan.ID)
-	// In real implementation, this would insert/update in QDrant/SQL
-	return nil
-}
-
 func (wo *WorkflowOrchestrator) convertDynamicToMarkdown(plan DynamicPlan) (string, error) {
 	var builder strings.Builder
 
@@ -588,71 +873,23 @@ func (wo *WorkflowOrchestrator) generateMarkdownPath(plan DynamicPlan) string {
 	return filepath.Join("./generated/markdown/", filename)
 }
 
-func (wo *WorkflowOrchestrator) writeMarkdownFile(path, content string) error {
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	// Write file
-	return os.WriteFile(path, []byte(content), 0644)
-}
-
-func (wo *WorkflowOrchestrator) convertToRoadmapFormat(plan DynamicPlan) ([]byte, error) {
-	// Convert to Roadmap Manager format
-	roadmapData := map[string]interface{}{
-		"id":          plan.ID,
-		"title":       plan.Title,
-		"version":     plan.Version,
-		"progress":    plan.Progress,
-		"tasks":       plan.Tasks,
-		"updated_at":  time.Now(),
-	}
-
-	return json.Marshal(roadmapData)
-}
-
-func (wo *WorkflowOrchestrator) sendToRoadmapManager(ctx context.Context, data []byte) error {
-	// Simulate sending to Roadmap Manager API
-	wo.logger.Printf("🗺️ Sending data to Roadmap Manager (simulated)")
-	// In real implementation, this would make HTTP requests to the Roadmap Manager API
-	return nil
-}
-
 func (wo *WorkflowOrchestrator) fetchTasksFromDynamic(ctx context.Context) ([]Task, error) {
-	// Simulate fetching tasks from dynamic system
-	return []Task{
-		{
-			ID:          "task-5.2.1",
-			Title:       "Implement Workflow Unification",
-			Description: "Create unified workflow orchestration",
-			Status:      "in_progress",
-			Priority:    "high",
-			Progress:    75.0,
-		},
-	}, nil
-}
-
-func (wo *WorkflowOrchestrator) syncTaskToTaskMaster(ctx context.Context, task Task) error {
-	// Execute TaskMaster CLI command
-	cmd := exec.CommandContext(ctx, wo.taskMasterAdapter.cliPath,
-		"task", "sync",
-		"--id", task.ID,
-		"--title", task.Title,
-		"--status", task.Status,
-		"--priority", task.Priority,
-	)
-
-	cmd.Env = os.Environ()
-
-	output, err := cmd.CombinedOutput()
+	plans, err := wo.dynamicStore.ListPlans(ctx)
 	if err != nil {
-		wo.logger.Printf("TaskMaster CLI output: %s", string(output))
-		return fmt.Errorf("taskmaster command failed: %w", err)
+		return nil, err
 	}
 
-	return nil
+	var tasks []Task
+	for _, plan := range plans {
+		for _, task := range plan.Tasks {
+			if task.Metadata == nil {
+				task.Metadata = make(map[string]interface{})
+			}
+			task.Metadata["plan_id"] = plan.ID
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
 }
 
 // scheduledSyncLoop runs the scheduled synchronization loop
@@ -676,65 +913,32 @@ func (wo *WorkflowOrchestrator) scheduledSyncLoop(ctx context.Context) {
 	}
 }
 
-// startFileWatching starts file system monitoring with concrete implementation
+// startFileWatching starts event-driven file system monitoring
 func (wo *WorkflowOrchestrator) startFileWatching(ctx context.Context) {
 	wo.logger.Printf("👁️ Starting file system monitoring...")
 
-	// Simple polling-based file watcher (in production, use fsnotify)
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		lastModTimes := make(map[string]time.Time)
-
-		for {
-			select {
-			case <-ctx.Done():
-				wo.logger.Printf("👁️ File watcher stopped")
-				return
-			case <-ticker.C:
-				wo.checkFileChanges(lastModTimes)
-			}
-		}
-	}()
-
-	wo.logger.Printf("✅ File system monitoring started")
-}
-
-func (wo *WorkflowOrchestrator) checkFileChanges(lastModTimes map[string]time.Time) {
-	files, err := wo.discoverMarkdownFiles()
+	err := wo.fileWatcher.Run(ctx, func(files []string) {
+		wo.handleFileChanges(ctx, files)
+	})
 	if err != nil {
-		return
+		wo.logger.Printf("❌ File watcher failed: %v", err)
 	}
+}
 
+// handleFileChanges synchronizes the plan files of a debounced batch of events
+func (wo *WorkflowOrchestrator) handleFileChanges(ctx context.Context, files []string) {
+	var plans []string
 	for _, file := range files {
-		info, err := os.Stat(file)
-		if err != nil {
-			continue
-		}
-
-		modTime := info.ModTime()
-		lastMod, exists := lastModTimes[file]
-
-		if !exists || modTime.After(lastMod) {
-			lastModTimes[file] = modTime
-			if exists { // Skip first run
-				wo.logger.Printf("📝 File change detected: %s", file)
-				go wo.handleFileChange(file)
-			}
+		if isPlanFile(file) {
+			plans = append(plans, file)
 		}
 	}
-}
-
-func (wo *WorkflowOrchestrator) handleFileChange(filePath string) {
-	wo.logger.Printf("🔄 Triggering sync for changed file: %s", filePath)
-
-	// Debounce
-	time.Sleep(wo.fileWatcher.debounceTime)
+	if len(plans) == 0 {
+		return
+	}
 
-	// Trigger appropriate sync based on file type
-	ctx := context.Background()
-	if err := wo.ExecuteFullSync(ctx); err != nil {
+	wo.logger.Printf("🔄 Triggering sync for changed files: %s", strings.Join(plans, ", "))
+	if err := wo.SyncFiles(ctx, plans); err != nil {
 		wo.logger.Printf("❌ Auto-sync failed: %v", err)
 	}
 }
@@ -842,6 +1046,17 @@ func (wo *WorkflowOrchestrator) GetSyncPoints() []SyncPoint {
 	return points
 }
 
+// GetPlannedMutations returns the mutations of the last synchronization run.
+// In dry-run mode none of them was applied.
+func (wo *WorkflowOrchestrator) GetPlannedMutations() []PlannedMutation {
+	wo.mutex.RLock()
+	defer wo.mutex.RUnlock()
+
+	mutations := make([]PlannedMutation, len(wo.plannedMutations))
+	copy(mutations, wo.plannedMutations)
+	return mutations
+}
+
 // IsRunning returns whether the workflow orchestrator is currently running
 func (wo *WorkflowOrchestrator) IsRunning() bool {
 	wo.mutex.RLock()
@@ -855,26 +1070,12 @@ type SyncEngine struct {
 	logger *log.Logger
 }
 
-type RoadmapConnector struct {
-	baseURL    string
-	apiKey     string
-	logger     *log.Logger
-}
-
 type TaskMasterAdapter struct {
 	cliPath    string
 	configPath string
 	logger     *log.Logger
 }
 
-type FileWatcher struct {
-	watchPaths    []string
-	filePatterns  []string
-	debounceTime  time.Duration
-	orchestrator  *WorkflowOrchestrator
-	logger        *log.Logger
-}
-
 type SyncConfig struct {
 	BatchSize         int           `yaml:"batch_size"`
 	Timeout          time.Duration `yaml:"timeout"`
@@ -0,0 +1,187 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	roadmapconnector "email_sender/planning-ecosystem-sync/tools/roadmap-connector"
+)
+
+type fakeRoadmapClient struct {
+	plans []string
+	err   error
+}
+
+func (f *fakeRoadmapClient) SyncPlanToRoadmapManager(ctx context.Context, dynamicPlan interface{}) (*roadmapconnector.SyncResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	plan := dynamicPlan.(*roadmapconnector.DynamicPlan)
+	f.plans = append(f.plans, plan.ID)
+	return &roadmapconnector.SyncResponse{Success: true}, nil
+}
+
+type fakeTaskMaster struct {
+	tasks []string
+}
+
+func (f *fakeTaskMaster) SyncTask(ctx context.Context, task Task) error {
+	f.tasks = append(f.tasks, task.ID)
+	return nil
+}
+
+func newTestOrchestrator(t *testing.T, dir string, dryRun bool) (*WorkflowOrchestrator, *fakeRoadmapClient, *fakeTaskMaster) {
+	config := &WorkflowConfig{
+		DryRun:           dryRun,
+		CheckpointPath:   filepath.Join(dir, "checkpoint.json"),
+		DynamicStorePath: filepath.Join(dir, "dynamic"),
+	}
+	config.SyncPoints.MarkdownToDynamic = true
+	config.SyncPoints.RoadmapManager = true
+	config.SyncPoints.TaskMasterCLI = true
+
+	roadmap := &fakeRoadmapClient{}
+	taskMaster := &fakeTaskMaster{}
+	orchestrator := NewWorkflowOrchestrator(config)
+	orchestrator.SetRoadmapClient(roadmap)
+	orchestrator.SetTaskMasterClient(taskMaster)
+	if err := orchestrator.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return orchestrator, roadmap, taskMaster
+}
+
+func TestWorkflowOrchestrator_SyncFiles(t *testing.T) {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan-dev-v1.md")
+	content := "# Plan v1\n\n**Version 1.2**\n\n## Phase 1\n\n- [x] 1.1 Setup\n- [ ] 1.2 Build\n"
+	if err := os.WriteFile(planFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Dry run: mutations are planned, nothing is written
+	dryRun, roadmap, _ := newTestOrchestrator(t, dir, true)
+	if err := dryRun.SyncFiles(ctx, []string{planFile}); err != nil {
+		t.Fatalf("dry-run sync failed: %v", err)
+	}
+	if mutations := dryRun.GetPlannedMutations(); len(mutations) != 1 || mutations[0].ItemID != "plan-dev-v1" {
+		t.Errorf("expected one planned store of plan-dev-v1, got %+v", mutations)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dynamic")); !os.IsNotExist(err) {
+		t.Error("dry run must not write the dynamic store")
+	}
+	if len(roadmap.plans) != 0 {
+		t.Error("dry run must not push to the Roadmap Manager")
+	}
+
+	// Real run: the plan reaches every target
+	orchestrator, roadmap, taskMaster := newTestOrchestrator(t, dir, false)
+	if err := orchestrator.SyncFiles(ctx, []string{planFile}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	plans, err := orchestrator.dynamicStore.ListPlans(ctx)
+	if err != nil || len(plans) != 1 {
+		t.Fatalf("expected one stored plan, got %d (%v)", len(plans), err)
+	}
+	plan := plans[0]
+	if plan.Title != "Plan v1" || plan.Version != "1.2" || len(plan.Tasks) != 2 || plan.Progress != 50 {
+		t.Errorf("unexpected parsed plan: %+v", plan)
+	}
+	if plan.Tasks[0].ID != "plan-dev-v1-1.1" || plan.Tasks[0].Status != "completed" {
+		t.Errorf("unexpected first task: %+v", plan.Tasks[0])
+	}
+	if len(roadmap.plans) != 1 || len(taskMaster.tasks) != 2 {
+		t.Errorf("expected 1 plan and 2 tasks pushed, got %v and %v", roadmap.plans, taskMaster.tasks)
+	}
+
+	// Unchanged file: the checkpoint skips every sync point
+	orchestrator, roadmap, taskMaster = newTestOrchestrator(t, dir, false)
+	if err := orchestrator.SyncFiles(ctx, []string{planFile}); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if len(orchestrator.GetPlannedMutations()) != 0 || len(roadmap.plans) != 0 || len(taskMaster.tasks) != 0 {
+		t.Errorf("unchanged plan should not be synced again: %+v", orchestrator.GetPlannedMutations())
+	}
+
+	// A failing target keeps its checkpoint, so the plan is pushed again next time
+	if err := os.WriteFile(planFile, []byte(content+"- [ ] 1.3 Ship\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orchestrator, roadmap, _ = newTestOrchestrator(t, dir, false)
+	roadmap.err = errors.New("unavailable")
+	if err := orchestrator.SyncFiles(ctx, []string{planFile}); err == nil {
+		t.Fatal("expected the Roadmap Manager failure to be reported")
+	}
+	orchestrator, roadmap, _ = newTestOrchestrator(t, dir, false)
+	if err := orchestrator.SyncFiles(ctx, []string{planFile}); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if len(roadmap.plans) != 1 {
+		t.Errorf("expected the plan to be pushed on retry, got %v", roadmap.plans)
+	}
+}
+
+type fakeConverter struct {
+	plans map[string]DynamicPlan
+}
+
+func (f *fakeConverter) ConvertAndStore(ctx context.Context, plan *DynamicPlan) error {
+	f.plans[plan.ID] = *plan
+	return nil
+}
+
+func (f *fakeConverter) ListPlans(ctx context.Context) ([]DynamicPlan, error) {
+	var plans []DynamicPlan
+	for _, plan := range f.plans {
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+func TestWorkflowOrchestrator_SyncCoreIsDefaultStore(t *testing.T) {
+	converter := &fakeConverter{plans: make(map[string]DynamicPlan)}
+	RegisterPlanConverter(func(config *WorkflowConfig) (PlanConverter, error) {
+		return converter, nil
+	})
+	defer RegisterPlanConverter(nil)
+
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan-dev-v2.md")
+	if err := os.WriteFile(planFile, []byte("# Plan v2\n\n- [ ] Task\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orchestrator, _, _ := newTestOrchestrator(t, dir, false)
+	if _, ok := orchestrator.dynamicStore.(*SyncCoreStore); !ok {
+		t.Fatalf("expected the sync-core store by default, got %T", orchestrator.dynamicStore)
+	}
+	if err := orchestrator.SyncFiles(context.Background(), []string{planFile}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if _, ok := converter.plans["plan-dev-v2"]; !ok {
+		t.Errorf("plan was not converted by sync-core: %v", converter.plans)
+	}
+}
+
+func TestWorkflowOrchestrator_SyncFilesSkipsRemovedFiles(t *testing.T) {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan-dev-v3.md")
+	if err := os.WriteFile(planFile, []byte("# Plan v3\n\n- [ ] Task\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file removed between the watcher event and the sync does not fail the batch
+	orchestrator, _, _ := newTestOrchestrator(t, dir, false)
+	removed := filepath.Join(dir, "plan-dev-removed.md")
+	if err := orchestrator.SyncFiles(context.Background(), []string{removed, planFile}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	plans, err := orchestrator.dynamicStore.ListPlans(context.Background())
+	if err != nil || len(plans) != 1 || plans[0].ID != "plan-dev-v3" {
+		t.Errorf("expected only plan-dev-v3 to be stored, got %+v (%v)", plans, err)
+	}
+}
@@ -2,23 +2,37 @@ package indexing
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	qdrantclient "email_sender/src/qdrant"
+
+	"github.com/google/uuid"
 )
 
-// BatchIndexer handles batch indexing of documents
+// BatchIndexer handles batch indexing of documents. It keeps a manifest of
+// file and chunk hashes in the index directory so that later runs only
+// re-embed the chunks that changed, remove the points of deleted files and
+// move the points of renamed files without re-embedding them.
 type BatchIndexer struct {
 	config   BatchIndexerConfig
 	metrics  *Metrics
 	indexDir string
 	client   qdrantclient.QdrantInterface
+	chunker  *Chunker
+	embedder EmbeddingProvider
+	readers  *ReaderFactory
+	manifest *Manifest
+
+	// pending holds the entries of removed files not yet matched with a new
+	// path during ApplyChanges, keyed by content hash for rename detection
+	pending      map[string][]*FileEntry
+	pendingMutex sync.Mutex
+	applyMutex   sync.Mutex // Serializes ApplyChanges
 }
 
 // BatchIndexerConfig holds configuration for BatchIndexer
@@ -28,6 +42,13 @@ type BatchIndexerConfig struct {
 	Metrics   *Metrics
 	// Client can be provided, otherwise auto client will be created
 	Client qdrantclient.QdrantInterface
+	// Embedder computes chunk vectors, a placeholder vector is used when nil
+	Embedder EmbeddingProvider
+	// ManifestPath defaults to IndexDir/manifest.json
+	ManifestPath string
+	// Readers extract text and metadata by file extension, files without a
+	// reader are indexed as raw text. Defaults to NewReaderFactory().
+	Readers *ReaderFactory
 	// Legacy fields - will be ignored in favor of auto client if Client is not provided
 	QdrantHost   string
 	QdrantPort   int
@@ -36,6 +57,24 @@ type BatchIndexerConfig struct {
 	ChunkOverlap int
 }
 
+// IndexReport summarizes the changes applied by an indexing run
+type IndexReport struct {
+	Added          int
+	Updated        int
+	Unchanged      int
+	Renamed        int
+	Deleted        int
+	ChunksEmbedded int
+	ChunksReused   int
+	PointsDeleted  int
+}
+
+// String returns a one-line summary of the report
+func (r *IndexReport) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d renamed, %d deleted, %d unchanged (%d chunks embedded, %d reused, %d points deleted)",
+		r.Added, r.Updated, r.Renamed, r.Deleted, r.Unchanged, r.ChunksEmbedded, r.ChunksReused, r.PointsDeleted)
+}
+
 // NewBatchIndexer creates a new BatchIndexer instance
 func NewBatchIndexer(config BatchIndexerConfig) (*BatchIndexer, error) {
 	if config.BatchSize <= 0 {
@@ -64,21 +103,117 @@ func NewBatchIndexer(config BatchIndexerConfig) (*BatchIndexer, error) {
 		}
 	}
 
+	embedder := config.Embedder
+	if embedder == nil {
+		embedder = placeholderEmbedder{dimensions: 384}
+	}
+
+	readers := config.Readers
+	if readers == nil {
+		readers = NewReaderFactory()
+	}
+
+	if config.ManifestPath == "" {
+		config.ManifestPath = filepath.Join(config.IndexDir, ManifestFileName)
+	}
+	manifest, err := LoadManifest(config.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Collection != "" && manifest.Collection != config.Collection {
+		return nil, fmt.Errorf("manifest %s belongs to collection '%s', not '%s'", config.ManifestPath, manifest.Collection, config.Collection)
+	}
+	manifest.Collection = config.Collection
+	if !manifest.matchesChunking(config.ChunkSize, config.ChunkOverlap) {
+		manifest.resetChunking(config.ChunkSize, config.ChunkOverlap)
+	} else {
+		manifest.ChunkSize, manifest.ChunkOverlap = config.ChunkSize, config.ChunkOverlap
+	}
+
 	return &BatchIndexer{
 		config:   config,
 		metrics:  config.Metrics,
 		indexDir: config.IndexDir,
 		client:   client,
+		chunker:  NewChunker(config.ChunkSize, config.ChunkOverlap),
+		embedder: embedder,
+		readers:  readers,
+		manifest: manifest,
 	}, nil
 }
 
-// IndexFiles indexes multiple files in batches
+// Manifest returns the manifest of indexed files
+func (bi *BatchIndexer) Manifest() *Manifest {
+	return bi.manifest
+}
+
+// IndexFiles indexes multiple files in batches. Files whose content did not
+// change since the last run are skipped.
 func (bi *BatchIndexer) IndexFiles(ctx context.Context, files []string) error {
+	_, err := bi.ApplyChanges(ctx, files, nil)
+	return err
+}
+
+// Sync brings the collection in line with the given files, which must be
+// every indexable file under root: files of the manifest below root that are
+// not listed anymore have their points removed, unless they were renamed.
+func (bi *BatchIndexer) Sync(ctx context.Context, root string, files []string) (*IndexReport, error) {
+	listed := make(map[string]bool, len(files))
+	for _, file := range files {
+		listed[filepath.Clean(file)] = true
+	}
+
+	var removed []string
+	for _, path := range bi.manifest.Paths() {
+		if isWithin(root, path) && !listed[path] {
+			removed = append(removed, path)
+		}
+	}
+	return bi.ApplyChanges(ctx, files, removed)
+}
+
+// ApplyChanges indexes the changed files and removes the points of the removed
+// ones. A changed file with the same content as a removed one is treated as a
+// rename: its points are moved to the new path without being re-embedded.
+func (bi *BatchIndexer) ApplyChanges(ctx context.Context, changed, removed []string) (*IndexReport, error) {
+	bi.applyMutex.Lock()
+	defer bi.applyMutex.Unlock()
+
 	start := time.Now()
+	report := &IndexReport{}
 	defer func() {
-		bi.recordMetrics(time.Since(start), len(files))
+		bi.recordMetrics(time.Since(start), len(changed))
 	}()
 
+	changedSet := make(map[string]bool, len(changed))
+	files := make([]string, 0, len(changed))
+	for _, file := range changed {
+		file = filepath.Clean(file)
+		if !changedSet[file] {
+			changedSet[file] = true
+			files = append(files, file)
+		}
+	}
+
+	if len(files) > 0 {
+		if err := bi.ensureCollection(); err != nil {
+			return report, err
+		}
+	}
+
+	bi.pendingMutex.Lock()
+	bi.pending = make(map[string][]*FileEntry)
+	for _, path := range removed {
+		path = filepath.Clean(path)
+		if changedSet[path] {
+			continue // Recreated since, indexFile will compare hashes
+		}
+		if entry, ok := bi.manifest.Get(path); ok {
+			bi.pending[entry.Hash] = append(bi.pending[entry.Hash], entry)
+		}
+	}
+	bi.pendingMutex.Unlock()
+
 	// Process files in batches to prevent memory overuse
 	batchSize := bi.config.BatchSize
 	batches := make([][]string, 0, (len(files)+batchSize-1)/batchSize)
@@ -93,9 +228,10 @@ func (bi *BatchIndexer) IndexFiles(ctx context.Context, files []string) error {
 
 	// Process each batch concurrently
 	var (
-		wg     sync.WaitGroup
-		mu     sync.Mutex
-		errors []error
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errors   []error
+		vanished []string
 	)
 
 	// Use semaphore to limit concurrent goroutines
@@ -111,74 +247,420 @@ func (bi *BatchIndexer) IndexFiles(ctx context.Context, files []string) error {
 			defer func() { <-sem }()
 
 			for _, file := range batchFiles {
-				if err := bi.indexFile(ctx, file); err != nil {
+				if err := ctx.Err(); err != nil {
 					mu.Lock()
-					errors = append(errors, fmt.Errorf("error indexing %s: %v", file, err))
+					errors = append(errors, err)
 					mu.Unlock()
+					return
+				}
+
+				err := bi.indexFile(ctx, file, report, &mu)
+				mu.Lock()
+				switch {
+				case os.IsNotExist(err):
+					// Deleted after being reported as changed
+					vanished = append(vanished, file)
+				case err != nil:
+					errors = append(errors, fmt.Errorf("error indexing %s: %v", file, err))
 				}
+				mu.Unlock()
 			}
 		}(batch)
 	}
 
 	wg.Wait()
 
+	// Files removed without a matching new path lose their points
+	bi.pendingMutex.Lock()
+	var deletions []*FileEntry
+	for _, entries := range bi.pending {
+		deletions = append(deletions, entries...)
+	}
+	bi.pending = nil
+	bi.pendingMutex.Unlock()
+	for _, path := range vanished {
+		if entry, ok := bi.manifest.Get(path); ok {
+			deletions = append(deletions, entry)
+		}
+	}
+
+	for _, entry := range deletions {
+		if err := bi.deletePoints(entry.Chunks); err != nil {
+			errors = append(errors, fmt.Errorf("error removing %s: %v", entry.Path, err))
+			continue
+		}
+		bi.manifest.Remove(entry.Path)
+		report.Deleted++
+		report.PointsDeleted += len(entry.Chunks)
+	}
+
+	if err := bi.manifest.Save(); err != nil {
+		errors = append(errors, err)
+	}
+
 	if len(errors) > 0 {
-		return fmt.Errorf("encountered %d errors during indexing: %v", len(errors), errors[0])
+		return report, fmt.Errorf("encountered %d errors during indexing: %v", len(errors), errors[0])
 	}
 
-	return nil
+	return report, nil
 }
 
-// indexFile processes and indexes a single file
-func (bi *BatchIndexer) indexFile(_ context.Context, filePath string) error {
+// indexFile processes and indexes a single file, reusing the vectors of the
+// chunks already stored for it or for the file it was renamed from
+func (bi *BatchIndexer) indexFile(ctx context.Context, filePath string, report *IndexReport, reportMutex *sync.Mutex) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
 	// Read file content
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %v", err)
+		return err
+	}
+	fileHash := hashContent(content)
+
+	previous, indexed := bi.manifest.Get(filePath)
+	if indexed && previous.Hash == fileHash {
+		reportMutex.Lock()
+		report.Unchanged++
+		reportMutex.Unlock()
+		return nil
+	}
+
+	// A new path with the content of a removed file is a rename
+	source := previous
+	renamed := false
+	if !indexed {
+		if entry := bi.claimRemoved(fileHash); entry != nil {
+			source = entry
+			renamed = true
+		}
+	}
+
+	text, metadata, err := bi.readDocument(filePath, content)
+	if err != nil {
+		return err
+	}
+
+	chunkStart := time.Now()
+	chunks := bi.chunker.Chunk(text)
+	if bi.metrics != nil {
+		bi.metrics.RecordChunking(chunks, time.Since(chunkStart))
+	}
+
+	entry := &FileEntry{
+		Path:      filePath,
+		Hash:      fileHash,
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		IndexedAt: time.Now(),
+		Chunks:    make([]ChunkEntry, len(chunks)),
+	}
+	occurrences := make(map[string]int)
+	for i, chunk := range chunks {
+		chunkHash := hashContent([]byte(chunk))
+		entry.Chunks[i] = ChunkEntry{
+			Hash:    chunkHash,
+			PointID: chunkPointID(filePath, chunkHash, occurrences[chunkHash]),
+		}
+		occurrences[chunkHash]++
+	}
+
+	vectors, err := bi.reusableVectors(source, entry.Chunks)
+	if err != nil {
+		return fmt.Errorf("failed to load stored vectors: %v", err)
+	}
+
+	// Embed only the chunks whose text is new
+	var missing []string
+	var missingIdx []int
+	for i, chunk := range entry.Chunks {
+		if _, ok := vectors[chunk.Hash]; !ok {
+			missing = append(missing, chunks[i])
+			missingIdx = append(missingIdx, i)
+		}
+	}
+	if len(missing) > 0 {
+		embedStart := time.Now()
+		embeddings, err := bi.embed(ctx, missing)
+		if bi.metrics != nil {
+			bi.metrics.RecordEmbeddingGeneration(len(chunks), len(chunks)-len(missing), time.Since(embedStart), err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks: %v", err)
+		}
+		for j, i := range missingIdx {
+			vectors[entry.Chunks[i].Hash] = embeddings[j]
+		}
+	}
+
+	points := make([]qdrantclient.Point, len(chunks))
+	for i, chunk := range entry.Chunks {
+		payload := map[string]interface{}{
+			"source":      filePath,
+			"size":        len(content),
+			"type":        filepath.Ext(filePath),
+			"file_hash":   fileHash,
+			"chunk_hash":  chunk.Hash,
+			"chunk_index": i,
+			"chunk_count": len(chunks),
+			"text":        chunks[i],
+		}
+		for key, value := range metadata {
+			if _, reserved := payload[key]; !reserved {
+				payload[key] = value
+			}
+		}
+		points[i] = qdrantclient.Point{
+			ID:      chunk.PointID,
+			Vector:  vectors[chunk.Hash],
+			Payload: payload,
+		}
+	}
+	if err := bi.upsertPoints(points); err != nil {
+		return fmt.Errorf("failed to upsert points in Qdrant: %v", err)
+	}
+
+	// Points of the previous version that were not overwritten are stale
+	var stale []ChunkEntry
+	if source != nil {
+		current := make(map[string]bool, len(entry.Chunks))
+		for _, chunk := range entry.Chunks {
+			current[chunk.PointID] = true
+		}
+		for _, chunk := range source.Chunks {
+			if !current[chunk.PointID] {
+				stale = append(stale, chunk)
+			}
+		}
+	}
+	if err := bi.deletePoints(stale); err != nil {
+		return fmt.Errorf("failed to delete stale points: %v", err)
+	}
+
+	if renamed {
+		bi.manifest.Remove(source.Path)
+	}
+	bi.manifest.Put(entry)
+
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+	switch {
+	case renamed:
+		report.Renamed++
+	case indexed:
+		report.Updated++
+	default:
+		report.Added++
+	}
+	report.ChunksEmbedded += len(missing)
+	report.ChunksReused += len(chunks) - len(missing)
+	report.PointsDeleted += len(stale)
+	return nil
+}
+
+// ensureCollection creates the collection on first use, sized for the embedder
+func (bi *BatchIndexer) ensureCollection() error {
+	if _, err := bi.client.GetCollectionInfo(bi.config.Collection); err == nil {
+		return nil
+	}
+	if err := bi.client.CreateCollection(bi.config.Collection, bi.embedder.GetDimensions()); err != nil {
+		return fmt.Errorf("failed to create collection '%s': %v", bi.config.Collection, err)
+	}
+	return nil
+}
+
+// readDocument extracts the text and metadata of a file with the reader
+// registered for its extension. Only flat metadata values are kept, as they
+// are copied to the payload of every chunk.
+func (bi *BatchIndexer) readDocument(filePath string, content []byte) (string, map[string]interface{}, error) {
+	reader, ok := bi.readers.GetReader(strings.ToLower(filepath.Ext(filePath)))
+	if !ok {
+		return string(content), nil, nil
+	}
+
+	// The content that was hashed is parsed, the file is not read again
+	doc, err := reader.ReadBytes(filePath, content)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read document: %v", err)
 	}
 
-	// Generate a unique ID for the document (hash of file path)
-	h := sha256.New()
-	h.Write([]byte(filePath))
-	id := hex.EncodeToString(h.Sum(nil))
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for key, value := range doc.Metadata {
+		switch value.(type) {
+		case string, bool, int, int64, float64, []string:
+			metadata[key] = value
+		}
+	}
+	if kind, ok := metadata["type"]; ok {
+		metadata["document_type"] = kind
+		delete(metadata, "type")
+	}
+	return doc.Content, metadata, nil
+}
+
+// claimRemoved takes a removed file with the given content hash, if any
+func (bi *BatchIndexer) claimRemoved(hash string) *FileEntry {
+	bi.pendingMutex.Lock()
+	defer bi.pendingMutex.Unlock()
+
+	entries := bi.pending[hash]
+	if len(entries) == 0 {
+		return nil
+	}
+	bi.pending[hash] = entries[1:]
+	return entries[0]
+}
+
+// reusableVectors fetches the stored vectors of the source file whose chunk
+// hash also appears in the new chunks, keyed by chunk hash
+func (bi *BatchIndexer) reusableVectors(source *FileEntry, chunks []ChunkEntry) (map[string][]float32, error) {
+	vectors := make(map[string][]float32)
+	if source == nil {
+		return vectors, nil
+	}
 
-	// Create document
-	doc := Document{
-		Path:    filePath,
-		Content: string(content),
-		Metadata: map[string]interface{}{
-			"source": filePath,
-			"size":   len(content),
-			"type":   filepath.Ext(filePath),
-		},
-		Encoding: "utf-8",
+	wanted := make(map[string]bool)
+	for _, chunk := range chunks {
+		wanted[chunk.Hash] = true
+	}
+	shared := false
+	for _, chunk := range source.Chunks {
+		if wanted[chunk.Hash] {
+			shared = true
+			break
+		}
+	}
+	if !shared {
+		return vectors, nil
 	}
 
-	// Write the document to the index directory
-	indexPath := filepath.Join(bi.indexDir, id)
-	if err := os.WriteFile(indexPath, []byte(doc.Content), 0644); err != nil {
-		return fmt.Errorf("failed to write indexed file: %v", err)
+	request := qdrantclient.ScrollRequest{
+		Filter:      &qdrantclient.Filter{Must: []qdrantclient.Condition{qdrantclient.MatchKeyword("source", source.Path)}},
+		Limit:       256,
+		WithPayload: true,
+		WithVector:  true,
+	}
+	for {
+		page, err := bi.client.Scroll(bi.config.Collection, request)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range page.Points {
+			hash, _ := point.Payload["chunk_hash"].(string)
+			if wanted[hash] && len(point.Vector) > 0 {
+				vectors[hash] = point.Vector
+			}
+		}
+		if page.NextPageOffset == nil {
+			break
+		}
+		request.Offset = page.NextPageOffset
 	}
+	return vectors, nil
+}
 
-	// Generate a mock vector
-	vector := make([]float32, 384)
-	for i := range vector {
-		vector[i] = float32(i) / 384.0
+// embed computes the vectors of texts in batches sized for the provider
+func (bi *BatchIndexer) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	batchSize := bi.embedder.GetBatchSize()
+	if batchSize <= 0 {
+		batchSize = len(texts)
 	}
 
-	// Create Qdrant point
-	point := qdrantclient.Point{
-		ID:      id,
-		Vector:  vector,
-		Payload: doc.Metadata,
-	} // Upsert point to Qdrant
-	if err := bi.client.UpsertPoints(bi.config.Collection, []qdrantclient.Point{point}); err != nil {
-		return fmt.Errorf("failed to upsert point in Qdrant: %v", err)
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		end := start + batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := bi.embedder.GetEmbeddings(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("provider returned %d embeddings for %d texts", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
 	}
+	return vectors, nil
+}
 
+// upsertPoints writes points to Qdrant in batches
+func (bi *BatchIndexer) upsertPoints(points []qdrantclient.Point) error {
+	for start := 0; start < len(points); start += bi.config.BatchSize {
+		end := start + bi.config.BatchSize
+		if end > len(points) {
+			end = len(points)
+		}
+		opStart := time.Now()
+		err := bi.client.UpsertPoints(bi.config.Collection, points[start:end])
+		if bi.metrics != nil {
+			bi.metrics.RecordQdrantOperation(end-start, time.Since(opStart), err)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// deletePoints removes the points of the given chunks
+func (bi *BatchIndexer) deletePoints(chunks []ChunkEntry) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.PointID
+	}
+	return bi.client.DeletePoints(bi.config.Collection, ids)
+}
+
+// chunkPointID derives a stable point ID from the file path, the chunk hash
+// and the occurrence of that chunk in the file, so that unchanged chunks keep
+// their point across runs. Qdrant only accepts integers and UUIDs as IDs.
+func chunkPointID(path, chunkHash string, occurrence int) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("%s#%s#%d", path, chunkHash, occurrence))).String()
+}
+
+// isWithin reports whether path is root or lies below it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// placeholderEmbedder returns the same vector for every text. It keeps the
+// indexer usable when no embedding provider is configured.
+type placeholderEmbedder struct {
+	dimensions int
+}
+
+// GetEmbeddings implements EmbeddingProvider
+func (p placeholderEmbedder) GetEmbeddings(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = make([]float32, p.dimensions)
+		for j := range vectors[i] {
+			vectors[i][j] = float32(j) / float32(p.dimensions)
+		}
+	}
+	return vectors, nil
+}
+
+// GetDimensions implements EmbeddingProvider
+func (p placeholderEmbedder) GetDimensions() int {
+	return p.dimensions
+}
+
+// GetBatchSize implements EmbeddingProvider
+func (p placeholderEmbedder) GetBatchSize() int {
+	return 0
+}
+
 // recordMetrics records metrics about the indexing process
 func (bi *BatchIndexer) recordMetrics(duration time.Duration, numFiles int) {
 	if bi.metrics != nil {
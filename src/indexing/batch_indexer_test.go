@@ -0,0 +1,163 @@
+package indexing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	qdrantclient "email_sender/src/qdrant"
+)
+
+// countingEmbedder returns a distinct vector per text and counts the texts it embedded
+type countingEmbedder struct {
+	mu       sync.Mutex
+	embedded int
+}
+
+func (e *countingEmbedder) GetEmbeddings(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.embedded += len(texts)
+	e.mu.Unlock()
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), float32(strings.Count(text, " ")), 1}
+	}
+	return vectors, nil
+}
+
+func (e *countingEmbedder) GetDimensions() int { return 3 }
+
+func (e *countingEmbedder) GetBatchSize() int { return 8 }
+
+func (e *countingEmbedder) reset() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := e.embedded
+	e.embedded = 0
+	return n
+}
+
+func newIncrementalIndexer(t *testing.T, client qdrantclient.QdrantInterface, indexDir string, embedder EmbeddingProvider) *BatchIndexer {
+	t.Helper()
+	indexer, err := NewBatchIndexer(BatchIndexerConfig{
+		BatchSize:    2,
+		IndexDir:     indexDir,
+		Client:       client,
+		Embedder:     embedder,
+		Collection:   "incremental",
+		ChunkSize:    60,
+		ChunkOverlap: 10,
+	})
+	if err != nil {
+		t.Fatalf("NewBatchIndexer failed: %v", err)
+	}
+	return indexer
+}
+
+func countPoints(t *testing.T, client qdrantclient.QdrantInterface, source string) int {
+	t.Helper()
+	request := qdrantclient.ScrollRequest{Limit: 1000, WithPayload: true}
+	if source != "" {
+		request.Filter = &qdrantclient.Filter{Must: []qdrantclient.Condition{qdrantclient.MatchKeyword("source", source)}}
+	}
+	page, err := client.Scroll("incremental", request)
+	if err != nil {
+		t.Fatalf("Scroll failed: %v", err)
+	}
+	return len(page.Points)
+}
+
+func TestBatchIndexer_Incremental(t *testing.T) {
+	srcDir := t.TempDir()
+	indexDir := t.TempDir()
+	client := qdrantclient.NewEmbeddedClient(&qdrantclient.EmbeddedConfig{MaxCollections: 5, MaxPointsPerCol: 1000})
+	defer client.Close()
+	embedder := &countingEmbedder{}
+
+	write := func(name, content string) string {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	long := "The first sentence talks about retries. The second one covers bounces. " +
+		"A third sentence describes the queue. The last sentence is about templates."
+	a := write("a.txt", long)
+	b := write("b.txt", "A short note about SMTP settings.")
+	c := write("c.txt", "Another file that will be deleted later on.")
+
+	indexer := newIncrementalIndexer(t, client, indexDir, embedder)
+	report, err := indexer.Sync(context.Background(), srcDir, []string{a, b, c})
+	if err != nil {
+		t.Fatalf("Initial sync failed: %v", err)
+	}
+	if report.Added != 3 || embedder.reset() == 0 {
+		t.Fatalf("Expected 3 added files, got %s", report)
+	}
+	chunksOfA := len(indexer.Manifest().Files[a].Chunks)
+	if chunksOfA < 2 {
+		t.Fatalf("Expected a.txt to be split into several chunks, got %d", chunksOfA)
+	}
+
+	// A new indexer reloads the manifest and skips unchanged files
+	indexer = newIncrementalIndexer(t, client, indexDir, embedder)
+	report, err = indexer.Sync(context.Background(), srcDir, []string{a, b, c})
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if report.Unchanged != 3 || embedder.reset() != 0 {
+		t.Errorf("Expected nothing to be re-embedded, got %s", report)
+	}
+
+	// Only the edited chunk of a.txt is re-embedded
+	write("a.txt", strings.Replace(long, "templates", "layouts", 1))
+	report, err = indexer.Sync(context.Background(), srcDir, []string{a, b, c})
+	if err != nil {
+		t.Fatalf("Sync after edit failed: %v", err)
+	}
+	if report.Updated != 1 || report.ChunksEmbedded != 1 || report.ChunksReused != chunksOfA-1 {
+		t.Errorf("Expected a single chunk to be re-embedded, got %s", report)
+	}
+	if got := embedder.reset(); got != 1 {
+		t.Errorf("Expected 1 embedded chunk, got %d", got)
+	}
+	if got := countPoints(t, client, a); got != chunksOfA {
+		t.Errorf("Expected %d points for a.txt, got %d", chunksOfA, got)
+	}
+
+	// Renaming b.txt moves its points, deleting c.txt removes them
+	renamed := filepath.Join(srcDir, "b-renamed.txt")
+	if err := os.Rename(b, renamed); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if err := os.Remove(c); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	report, err = indexer.Sync(context.Background(), srcDir, []string{a, renamed})
+	if err != nil {
+		t.Fatalf("Sync after rename failed: %v", err)
+	}
+	if report.Renamed != 1 || report.Deleted != 1 || embedder.reset() != 0 {
+		t.Errorf("Expected one rename and one deletion without embedding, got %s", report)
+	}
+	if countPoints(t, client, b) != 0 || countPoints(t, client, c) != 0 || countPoints(t, client, renamed) != 1 {
+		t.Error("Expected the points to follow the renamed file and the deleted file to have none")
+	}
+	if got, want := countPoints(t, client, ""), chunksOfA+1; got != want {
+		t.Errorf("Expected %d points in the collection, got %d", want, got)
+	}
+
+	manifest, err := LoadManifest(filepath.Join(indexDir, ManifestFileName))
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if paths := manifest.Paths(); len(paths) != 2 || paths[0] != a || paths[1] != renamed {
+		t.Errorf("Unexpected manifest paths: %v", paths)
+	}
+}
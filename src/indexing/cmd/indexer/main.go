@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"email_sender/src/indexing"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -22,6 +26,8 @@ type indexCommand struct {
 	concurrent int
 	timeout    time.Duration
 	dryRun     bool
+	watch      bool
+	debounce   time.Duration
 }
 
 type statusCommand struct {
@@ -51,6 +57,8 @@ func main() {
 	indexCmd.IntVar(&index.concurrent, "concurrent", 4, "Number of concurrent processing routines")
 	indexCmd.DurationVar(&index.timeout, "timeout", 30*time.Minute, "Timeout for the entire operation")
 	indexCmd.BoolVar(&index.dryRun, "dry-run", false, "Show what would be indexed without actually indexing")
+	indexCmd.BoolVar(&index.watch, "watch", false, "Keep watching the source and index changes as they happen")
+	indexCmd.DurationVar(&index.debounce, "debounce", 500*time.Millisecond, "Quiet period before indexing a burst of changes in watch mode")
 
 	// status command flags
 	status := &statusCommand{}
@@ -113,8 +121,9 @@ func runIndex(cmd *indexCommand) error {
 		config.Batch.MaxConcurrent = cmd.concurrent
 	}
 
-	// Create batch indexer
+	// Create batch indexer, the manifest of indexed files lives in the data directory
 	indexer, err := indexing.NewBatchIndexer(indexing.BatchIndexerConfig{
+		IndexDir:     config.DataDir,
 		QdrantHost:   config.Qdrant.Host,
 		QdrantPort:   config.Qdrant.Port,
 		Collection:   config.Qdrant.Collection,
@@ -127,27 +136,12 @@ func runIndex(cmd *indexCommand) error {
 	}
 
 	// Collect files to process
-	var files []string
-	err = filepath.Walk(cmd.source, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			ext := filepath.Ext(path)
-			for _, supported := range config.FileTypes.SupportedFormats {
-				if ext == supported {
-					files = append(files, path)
-					break
-				}
-			}
-		}
-		return nil
-	})
+	files, err := collectFiles(cmd.source, cmd.recursive, config)
 	if err != nil {
 		return fmt.Errorf("failed to collect files: %v", err)
 	}
 
-	if len(files) == 0 {
+	if len(files) == 0 && !cmd.watch {
 		return fmt.Errorf("no supported files found in %s", cmd.source)
 	}
 
@@ -166,20 +160,208 @@ func runIndex(cmd *indexCommand) error {
 	ctx, cancel := context.WithTimeout(context.Background(), cmd.timeout)
 	defer cancel()
 
-	// Start indexing
+	// Start indexing, unchanged files are skipped and removed ones purged
 	fmt.Printf("Starting indexing of %d files...\n", len(files))
 	startTime := time.Now()
 
-	err = indexer.IndexFiles(ctx, files)
+	report, err := indexer.Sync(ctx, cmd.source, files)
 	if err != nil {
 		return fmt.Errorf("indexing failed: %v", err)
 	}
 
 	duration := time.Since(startTime)
-	fmt.Printf("\nIndexing completed in %v\n", duration)
+	fmt.Printf("\nIndexing completed in %v: %s\n", duration, report)
+
+	if cmd.watch {
+		return watchSource(indexer, cmd, config)
+	}
 	return nil
 }
 
+// collectFiles lists the supported files under source
+func collectFiles(source string, recursive bool, config *indexing.IndexingConfig) ([]string, error) {
+	var files []string
+	err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != source && (!recursive || isExcluded(path, config)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isIndexable(path, config) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isIndexable reports whether a file has a supported extension and is not excluded
+func isIndexable(path string, config *indexing.IndexingConfig) bool {
+	if isExcluded(path, config) {
+		return false
+	}
+	ext := filepath.Ext(path)
+	for _, supported := range config.FileTypes.SupportedFormats {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded matches the base name of path against the exclude patterns
+func isExcluded(path string, config *indexing.IndexingConfig) bool {
+	base := filepath.Base(path)
+	for _, pattern := range config.FileTypes.ExcludePatterns {
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// watchSource keeps the collection in sync with the source using filesystem
+// notifications. Events are gathered until the source has been quiet for the
+// debounce period, so that a rename (remove + create) is applied in one batch
+// and detected as such by the indexer.
+func watchSource(indexer *indexing.BatchIndexer, cmd *indexCommand, config *indexing.IndexingConfig) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	addDir := func(dir string) error {
+		return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != dir && (!cmd.recursive || isExcluded(path, config)) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		})
+	}
+
+	info, err := os.Stat(cmd.source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = addDir(cmd.source)
+	} else {
+		err = watcher.Add(filepath.Dir(cmd.source))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %v", cmd.source, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	changed := make(map[string]bool)
+	removed := make(map[string]bool)
+	timer := time.NewTimer(cmd.debounce)
+	timer.Stop()
+
+	inScope := func(path string) bool {
+		if info.IsDir() {
+			return true
+		}
+		return filepath.Clean(path) == filepath.Clean(cmd.source)
+	}
+
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)...\n", cmd.source)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watch error: %v", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !inScope(event.Name) {
+				continue
+			}
+
+			switch {
+			case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+				stat, err := os.Stat(event.Name)
+				if err != nil {
+					continue
+				}
+				if stat.IsDir() {
+					if !cmd.recursive || isExcluded(event.Name, config) {
+						continue
+					}
+					// A directory moved or created in the tree: watch it and index its files
+					if err := addDir(event.Name); err != nil {
+						log.Printf("failed to watch %s: %v", event.Name, err)
+					}
+					files, err := collectFiles(event.Name, cmd.recursive, config)
+					if err != nil {
+						log.Printf("failed to list %s: %v", event.Name, err)
+					}
+					for _, f := range files {
+						changed[f] = true
+						delete(removed, f)
+					}
+				} else if isIndexable(event.Name, config) {
+					changed[event.Name] = true
+					delete(removed, event.Name)
+				}
+
+			case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+				// The path may be a file or a whole directory, expand it from the manifest
+				prefix := event.Name + string(filepath.Separator)
+				for _, path := range indexer.Manifest().Paths() {
+					if path == filepath.Clean(event.Name) || strings.HasPrefix(path, prefix) {
+						removed[path] = true
+						delete(changed, path)
+					}
+				}
+
+			default:
+				continue
+			}
+			timer.Reset(cmd.debounce)
+
+		case <-timer.C:
+			changedFiles := make([]string, 0, len(changed))
+			for path := range changed {
+				changedFiles = append(changedFiles, path)
+			}
+			removedFiles := make([]string, 0, len(removed))
+			for path := range removed {
+				removedFiles = append(removedFiles, path)
+			}
+			changed = make(map[string]bool)
+			removed = make(map[string]bool)
+
+			report, err := indexer.ApplyChanges(ctx, changedFiles, removedFiles)
+			if err != nil {
+				log.Printf("incremental indexing failed: %v", err)
+				continue
+			}
+			fmt.Printf("[%s] %s\n", time.Now().Format(time.TimeOnly), report)
+		}
+	}
+}
+
 func runStatus(cmd *statusCommand) error {
 	config, err := indexing.LoadConfig(cmd.config)
 	if err != nil {
@@ -197,7 +379,17 @@ func runStatus(cmd *statusCommand) error {
 	fmt.Println("Collection Status:")
 	fmt.Printf("Host: %s:%d\n", config.Qdrant.Host, config.Qdrant.Port)
 	fmt.Printf("Collection: %s\n", config.Qdrant.Collection)
-	// Add actual status information here
+
+	// The manifest tells what the last indexing runs left in the collection
+	manifest, err := indexing.LoadManifest(filepath.Join(config.DataDir, indexing.ManifestFileName))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Indexed files: %d\n", len(manifest.Files))
+	fmt.Printf("Indexed chunks: %d\n", manifest.ChunkCount())
+	if !manifest.UpdatedAt.IsZero() {
+		fmt.Printf("Last indexing: %s\n", manifest.UpdatedAt.Format(time.RFC3339))
+	}
 
 	return nil
 }
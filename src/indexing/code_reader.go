@@ -0,0 +1,426 @@
+package indexing
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Symbol is a declaration found in a source file
+type Symbol struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // function, method, class, struct, interface, type, enum, constant, variable
+	Parent   string `json:"parent,omitempty"`
+	Line     int    `json:"line"`
+	EndLine  int    `json:"end_line"`
+	Exported bool   `json:"exported"`
+}
+
+// CodeReader implements DocumentReader for Go, TypeScript/JavaScript and
+// Python sources. The content is the source itself; the metadata lists the
+// symbols declared in the file with their line ranges, and the imports.
+type CodeReader struct{}
+
+// NewCodeReader creates a new CodeReader instance
+func NewCodeReader() *CodeReader {
+	return &CodeReader{}
+}
+
+// codeLanguages maps the supported extensions to a language name
+var codeLanguages = map[string]string{
+	".go":  "go",
+	".ts":  "typescript",
+	".tsx": "typescript",
+	".js":  "javascript",
+	".jsx": "javascript",
+	".mjs": "javascript",
+	".py":  "python",
+}
+
+// GetSupportedExtensions returns supported file extensions
+func (r *CodeReader) GetSupportedExtensions() []string {
+	extensions := make([]string, 0, len(codeLanguages))
+	for ext := range codeLanguages {
+		extensions = append(extensions, ext)
+	}
+	return extensions
+}
+
+// Read implements DocumentReader interface
+func (r *CodeReader) Read(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *CodeReader) ReadBytes(path string, source []byte) (*Document, error) {
+	language, ok := codeLanguages[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported source file: %s", path)
+	}
+
+	var symbols []Symbol
+	var imports []string
+	metadata := map[string]interface{}{
+		"filename":  filepath.Base(path),
+		"extension": filepath.Ext(path),
+		"type":      "code",
+		"language":  language,
+		"lines":     strings.Count(string(source), "\n") + 1,
+	}
+
+	switch language {
+	case "go":
+		var pkg string
+		var err error
+		symbols, imports, pkg, err = goSymbols(path, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Go source: %v", err)
+		}
+		metadata["package"] = pkg
+	case "python":
+		symbols, imports = pythonSymbols(string(source))
+	default:
+		symbols, imports = scriptSymbols(string(source))
+	}
+
+	names := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		names[i] = symbol.Name
+		if symbol.Parent != "" {
+			names[i] = symbol.Parent + "." + symbol.Name
+		}
+	}
+	metadata["symbols"] = symbols
+	metadata["symbol_names"] = names
+	metadata["imports"] = imports
+
+	return &Document{
+		Path:     path,
+		Content:  string(source),
+		Metadata: metadata,
+		Encoding: "utf-8",
+	}, nil
+}
+
+// goSymbols lists the top-level declarations of a Go file
+func goSymbols(path string, source []byte) ([]Symbol, []string, string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var imports []string
+	for _, spec := range file.Imports {
+		if value, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, value)
+		}
+	}
+
+	span := func(node ast.Node) (int, int) {
+		return fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+	}
+
+	var symbols []Symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := Symbol{Name: d.Name.Name, Kind: "function", Exported: d.Name.IsExported()}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbol.Kind = "method"
+				symbol.Parent = goReceiverName(d.Recv.List[0].Type)
+			}
+			symbol.Line, symbol.EndLine = span(d)
+			symbols = append(symbols, symbol)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					kind := "type"
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					symbol := Symbol{Name: s.Name.Name, Kind: kind, Exported: s.Name.IsExported()}
+					symbol.Line, symbol.EndLine = span(s)
+					symbols = append(symbols, symbol)
+				case *ast.ValueSpec:
+					kind := "variable"
+					if d.Tok == token.CONST {
+						kind = "constant"
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" {
+							continue
+						}
+						symbol := Symbol{Name: name.Name, Kind: kind, Exported: name.IsExported()}
+						symbol.Line, symbol.EndLine = span(s)
+						symbols = append(symbols, symbol)
+					}
+				}
+			}
+		}
+	}
+	return symbols, imports, file.Name.Name, nil
+}
+
+// goReceiverName returns the type name of a method receiver
+func goReceiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+var (
+	pythonDefPattern    = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+([A-Za-z_]\w*)`)
+	pythonClassPattern  = regexp.MustCompile(`^(\s*)class\s+([A-Za-z_]\w*)`)
+	pythonImportPattern = regexp.MustCompile(`^(?:from\s+([\w.]+)\s+import|import\s+([\w.]+(?:\s*,\s*[\w.]+)*))`)
+)
+
+// pythonSymbols lists the classes and functions of a Python module, nesting
+// them by indentation. A block ends before the next line indented at most as
+// much as its header.
+func pythonSymbols(source string) ([]Symbol, []string) {
+	lines := strings.Split(source, "\n")
+
+	type openBlock struct {
+		index  int // Index in symbols
+		indent int
+	}
+	var symbols []Symbol
+	var imports []string
+	var stack []openBlock
+	lastCode := 0
+
+	closeBlocks := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			symbols[stack[len(stack)-1].index].EndLine = lastCode
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		closeBlocks(indent)
+		lastCode = i + 1
+
+		if indent == 0 {
+			if m := pythonImportPattern.FindStringSubmatch(trimmed); m != nil {
+				if m[1] != "" {
+					imports = append(imports, m[1])
+				} else {
+					for _, name := range strings.Split(m[2], ",") {
+						imports = append(imports, strings.TrimSpace(name))
+					}
+				}
+			}
+		}
+
+		var name, kind string
+		if m := pythonDefPattern.FindStringSubmatch(line); m != nil {
+			name, kind = m[2], "function"
+		} else if m := pythonClassPattern.FindStringSubmatch(line); m != nil {
+			name, kind = m[2], "class"
+		} else {
+			continue
+		}
+
+		symbol := Symbol{Name: name, Kind: kind, Line: i + 1, EndLine: i + 1, Exported: !strings.HasPrefix(name, "_")}
+		if len(stack) > 0 {
+			parent := symbols[stack[len(stack)-1].index]
+			symbol.Parent = parent.Name
+			if parent.Kind == "class" && kind == "function" {
+				symbol.Kind = "method"
+			}
+		}
+		symbols = append(symbols, symbol)
+		stack = append(stack, openBlock{index: len(symbols) - 1, indent: indent})
+	}
+	closeBlocks(0)
+	return symbols, imports
+}
+
+var (
+	scriptDeclPatterns = []struct {
+		kind    string
+		pattern *regexp.Regexp
+	}{
+		{"function", regexp.MustCompile(`^\s*(export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`)},
+		{"class", regexp.MustCompile(`^\s*(export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`)},
+		{"interface", regexp.MustCompile(`^\s*(export\s+)?(?:declare\s+)?interface\s+([A-Za-z_$][\w$]*)`)},
+		{"type", regexp.MustCompile(`^\s*(export\s+)?(?:declare\s+)?type\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`)},
+		{"enum", regexp.MustCompile(`^\s*(export\s+)?(?:declare\s+)?(?:const\s+)?enum\s+([A-Za-z_$][\w$]*)`)},
+		{"function", regexp.MustCompile(`^\s*(export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::\s*[^=]+)?=>)`)},
+	}
+	scriptMethodPattern = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*(#?[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\([^)]*\)?\s*(?::\s*[^{;]+)?\{?\s*$`)
+	scriptImportPattern = regexp.MustCompile(`(?:^\s*import\s+(?:[^'"]*\s+from\s+)?|require\()\s*['"]([^'"]+)['"]`)
+	scriptKeywords      = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "function": true, "return": true}
+)
+
+// scriptSymbols lists the declarations of a TypeScript or JavaScript file.
+// Blocks are delimited by counting braces outside of strings and comments,
+// which is enough for formatted code without a full parser.
+func scriptSymbols(source string) ([]Symbol, []string) {
+	lines := strings.Split(source, "\n")
+
+	var symbols []Symbol
+	var imports []string
+	type openBlock struct {
+		index  int  // Index in symbols
+		depth  int  // Brace depth before the declaration
+		opened bool // Whether the body braces were seen
+	}
+	var stack []*openBlock
+	scanner := braceScanner{}
+
+	for i, line := range lines {
+		if m := scriptImportPattern.FindStringSubmatch(line); m != nil {
+			imports = append(imports, m[1])
+		}
+
+		depth := scanner.depth
+		if !scanner.inComment {
+			symbol, ok := matchScriptDeclaration(line)
+			if !ok && len(stack) > 0 && symbols[stack[len(stack)-1].index].Kind == "class" && depth == stack[len(stack)-1].depth+1 {
+				if m := scriptMethodPattern.FindStringSubmatch(line); m != nil && !scriptKeywords[m[1]] {
+					symbol, ok = Symbol{Name: m[1], Kind: "method", Exported: !strings.HasPrefix(m[1], "#")}, true
+				}
+			}
+			if ok {
+				symbol.Line, symbol.EndLine = i+1, i+1
+				if len(stack) > 0 {
+					symbol.Parent = symbols[stack[len(stack)-1].index].Name
+				}
+				symbols = append(symbols, symbol)
+				stack = append(stack, &openBlock{index: len(symbols) - 1, depth: depth})
+			}
+		}
+
+		scanner.scan(line)
+		for _, open := range stack {
+			if scanner.maxDepth > open.depth {
+				open.opened = true
+			}
+		}
+
+		// A block ends when its braces close. A declaration without braces
+		// ends on the first line that does not continue it.
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.opened && scanner.depth > top.depth {
+				break
+			}
+			if !top.opened && continuesDeclaration(line) {
+				break
+			}
+			symbols[top.index].EndLine = i + 1
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for _, open := range stack {
+		symbols[open.index].EndLine = len(lines)
+	}
+	return symbols, imports
+}
+
+// continuesDeclaration reports whether a line leaves a declaration unfinished
+// (multi-line parameters, an arrow or an assignment awaiting its body)
+func continuesDeclaration(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, suffix := range []string{"(", ",", "=>", "=", "<", ":", "|", "&"} {
+		if strings.HasSuffix(trimmed, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchScriptDeclaration recognizes a top-level style declaration
+func matchScriptDeclaration(line string) (Symbol, bool) {
+	for _, decl := range scriptDeclPatterns {
+		if m := decl.pattern.FindStringSubmatch(line); m != nil {
+			return Symbol{Name: m[2], Kind: decl.kind, Exported: m[1] != ""}, true
+		}
+	}
+	return Symbol{}, false
+}
+
+// braceScanner tracks the brace depth of JavaScript-like code across lines
+type braceScanner struct {
+	depth     int
+	maxDepth  int // Deepest level reached while scanning the last line
+	inComment bool
+	quote     rune // Open template literal, spanning lines
+}
+
+// scan updates the depth with the braces of one line
+func (s *braceScanner) scan(line string) {
+	s.maxDepth = s.depth
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case s.inComment:
+			if c == '*' && next == '/' {
+				s.inComment = false
+				i++
+			}
+		case s.quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == s.quote {
+				s.quote = 0
+			}
+		case c == '/' && next == '/':
+			return
+		case c == '/' && next == '*':
+			s.inComment = true
+			i++
+		case c == '"' || c == '\'' || c == '`':
+			s.quote = c
+		case c == '{':
+			s.depth++
+			if s.depth > s.maxDepth {
+				s.maxDepth = s.depth
+			}
+		case c == '}':
+			if s.depth > 0 {
+				s.depth--
+			}
+		}
+	}
+	// Only template literals span lines
+	if s.quote != '`' {
+		s.quote = 0
+	}
+}
@@ -96,10 +96,16 @@ func DefaultConfig() *IndexingConfig {
 			SupportedFormats []string `json:"supportedFormats"`
 			ExcludePatterns  []string `json:"excludePatterns"`
 		}{
-			TextMaxSizeMB:    10,
-			PDFMaxSizeMB:     50,
-			SupportedFormats: []string{".txt", ".md", ".markdown", ".pdf"},
-			ExcludePatterns:  []string{".*", "~*", "tmp*"},
+			TextMaxSizeMB: 10,
+			PDFMaxSizeMB:  50,
+			SupportedFormats: []string{
+				".txt", ".md", ".markdown", ".pdf",
+				".html", ".htm", ".docx", ".odt",
+				".go", ".ts", ".tsx", ".js", ".py",
+				".csv", ".tsv", ".json", ".jsonl",
+				".eml", ".mbox",
+			},
+			ExcludePatterns: []string{".*", "~*", "tmp*"},
 		},
 	}
 }
@@ -0,0 +1,448 @@
+package indexing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxMIMEDepth bounds the nesting of multipart bodies
+const maxMIMEDepth = 10
+
+// EmailReader implements DocumentReader for RFC 5322 messages (.eml) and
+// mailboxes (.mbox). Headers become metadata, the text body (or the HTML body
+// stripped of its markup) becomes the content, and attachments are listed
+// without their payload.
+type EmailReader struct{}
+
+// NewEmailReader creates a new EmailReader instance
+func NewEmailReader() *EmailReader {
+	return &EmailReader{}
+}
+
+// GetSupportedExtensions returns supported file extensions
+func (r *EmailReader) GetSupportedExtensions() []string {
+	return []string{".eml", ".mbox"}
+}
+
+// emailAttachment describes an attachment of a message
+type emailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// emailMessage is a parsed message
+type emailMessage struct {
+	From        string
+	To          []string
+	Cc          []string
+	Subject     string
+	Date        time.Time
+	MessageID   string
+	InReplyTo   string
+	References  []string
+	Body        string
+	Attachments []emailAttachment
+}
+
+// Read implements DocumentReader interface
+func (r *EmailReader) Read(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *EmailReader) ReadBytes(path string, data []byte) (*Document, error) {
+	metadata := map[string]interface{}{
+		"filename":  filepath.Base(path),
+		"extension": filepath.Ext(path),
+		"type":      "email",
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".mbox" {
+		return readMailbox(path, data, metadata)
+	}
+
+	message, err := parseEmail(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message: %v", err)
+	}
+	for key, value := range message.metadata() {
+		metadata[key] = value
+	}
+
+	return &Document{
+		Path:     path,
+		Content:  message.render(),
+		Metadata: metadata,
+		Encoding: "utf-8",
+	}, nil
+}
+
+// readMailbox reads every message of an mbox file into one document
+func readMailbox(path string, data []byte, metadata map[string]interface{}) (*Document, error) {
+	var contents []string
+	var summaries []map[string]interface{}
+	var attachments []emailAttachment
+	senders := make(map[string]bool)
+	var first, last time.Time
+
+	for i, raw := range splitMbox(data) {
+		message, err := parseEmail(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message %d: %v", i+1, err)
+		}
+		contents = append(contents, message.render())
+		summaries = append(summaries, map[string]interface{}{
+			"subject":    message.Subject,
+			"from":       message.From,
+			"date":       formatEmailDate(message.Date),
+			"message_id": message.MessageID,
+		})
+		attachments = append(attachments, message.Attachments...)
+		if message.From != "" {
+			senders[message.From] = true
+		}
+		if !message.Date.IsZero() {
+			if first.IsZero() || message.Date.Before(first) {
+				first = message.Date
+			}
+			if message.Date.After(last) {
+				last = message.Date
+			}
+		}
+	}
+
+	senderList := make([]string, 0, len(senders))
+	for sender := range senders {
+		senderList = append(senderList, sender)
+	}
+
+	metadata["message_count"] = len(summaries)
+	metadata["messages"] = summaries
+	metadata["senders"] = senderList
+	metadata["attachments"] = attachments
+	metadata["first_date"] = formatEmailDate(first)
+	metadata["last_date"] = formatEmailDate(last)
+
+	return &Document{
+		Path:     path,
+		Content:  strings.Join(contents, "\n\n"),
+		Metadata: metadata,
+		Encoding: "utf-8",
+	}, nil
+}
+
+// splitMbox splits a mailbox on its "From " separator lines and undoes the
+// ">From " quoting of the mboxrd format
+func splitMbox(data []byte) [][]byte {
+	var messages [][]byte
+	var current bytes.Buffer
+	previousBlank := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if previousBlank && bytes.HasPrefix(line, []byte("From ")) {
+			if current.Len() > 0 {
+				messages = append(messages, append([]byte(nil), current.Bytes()...))
+				current.Reset()
+			}
+			previousBlank = false
+			continue
+		}
+
+		if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+			line = line[1:]
+		}
+		current.Write(line)
+		current.WriteByte('\n')
+		previousBlank = len(bytes.TrimRight(line, "\r")) == 0
+	}
+	if len(bytes.TrimSpace(current.Bytes())) > 0 {
+		messages = append(messages, current.Bytes())
+	}
+	return messages
+}
+
+// headerDecoder decodes RFC 2047 encoded words in any charset known to browsers
+var headerDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+// parseEmail parses one RFC 5322 message
+func parseEmail(data []byte) (*emailMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	header := msg.Header
+	message := &emailMessage{
+		From:       decodeAddressList(header.Get("From")),
+		To:         splitAddresses(header.Get("To")),
+		Cc:         splitAddresses(header.Get("Cc")),
+		Subject:    decodeHeader(header.Get("Subject")),
+		MessageID:  strings.Trim(header.Get("Message-Id"), "<> "),
+		InReplyTo:  strings.Trim(header.Get("In-Reply-To"), "<> "),
+		References: strings.Fields(strings.NewReplacer("<", " ", ">", " ").Replace(header.Get("References"))),
+	}
+	if date, err := header.Date(); err == nil {
+		message.Date = date
+	}
+
+	var plain, html []string
+	err = walkMIMEPart(header, msg.Body, 0, func(contentType string, params map[string]string, disposition string, body []byte) {
+		filename := params["filename"]
+		if filename == "" {
+			filename = params["name"]
+		}
+		if disposition == "attachment" || (filename != "" && disposition != "inline") || !strings.HasPrefix(contentType, "text/") {
+			message.Attachments = append(message.Attachments, emailAttachment{
+				Filename:    decodeHeader(filename),
+				ContentType: contentType,
+				Size:        len(body),
+			})
+			return
+		}
+
+		text := decodeCharset(body, params["charset"])
+		switch contentType {
+		case "text/html":
+			html = append(html, text)
+		default:
+			plain = append(plain, text)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The plain alternative is preferred, the HTML one is only stripped when alone
+	if len(plain) > 0 {
+		message.Body = strings.TrimSpace(strings.Join(plain, "\n\n"))
+	} else {
+		var parts []string
+		for _, h := range html {
+			if text, _, err := extractHTML(strings.NewReader(h)); err == nil {
+				parts = append(parts, text)
+			}
+		}
+		message.Body = strings.TrimSpace(strings.Join(parts, "\n\n"))
+	}
+	return message, nil
+}
+
+// partHeader is satisfied by message and MIME part headers
+type partHeader interface {
+	Get(key string) string
+}
+
+// walkMIMEPart decodes a body and calls visit for each leaf part
+func walkMIMEPart(header partHeader, body io.Reader, depth int, visit func(contentType string, params map[string]string, disposition string, body []byte)) error {
+	contentType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		contentType, params = "text/plain", map[string]string{}
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	for key, value := range dispositionParams {
+		if _, exists := params[key]; !exists {
+			params[key] = value
+		}
+	}
+
+	if strings.HasPrefix(contentType, "multipart/") && depth < maxMIMEDepth {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %v", err)
+			}
+			if err := walkMIMEPart(part.Header, part, depth+1, visit); err != nil {
+				return err
+			}
+		}
+	}
+
+	var decoded io.Reader = body
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		decoded = base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		decoded = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s part: %v", contentType, err)
+	}
+
+	if contentType == "message/rfc822" && depth < maxMIMEDepth {
+		// A forwarded message: index its text as well
+		if inner, err := parseEmail(data); err == nil {
+			visit("text/plain", map[string]string{"charset": "utf-8"}, "inline", []byte(inner.render()))
+			return nil
+		}
+	}
+	visit(contentType, params, disposition, data)
+	return nil
+}
+
+// newlineStripper drops line breaks, which the base64 decoder rejects
+type newlineStripper struct {
+	r io.Reader
+}
+
+// Read implements io.Reader
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[kept] = b
+			kept++
+		}
+	}
+	if kept == 0 && n > 0 && err == nil {
+		return s.Read(p)
+	}
+	return kept, err
+}
+
+// decodeCharset converts text in the given charset to UTF-8
+func decodeCharset(data []byte, charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "us-ascii" {
+		return string(data)
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// decodeHeader decodes RFC 2047 encoded words
+func decodeHeader(value string) string {
+	decoded, err := headerDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// splitAddresses parses an address list header, keeping the raw value when it is malformed
+func splitAddresses(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parser := mail.AddressParser{WordDecoder: headerDecoder}
+	addresses, err := parser.ParseList(value)
+	if err != nil {
+		return []string{decodeHeader(value)}
+	}
+	list := make([]string, len(addresses))
+	for i, address := range addresses {
+		list[i] = formatAddress(address)
+	}
+	return list
+}
+
+// decodeAddressList returns a single address header as text
+func decodeAddressList(value string) string {
+	return strings.Join(splitAddresses(value), ", ")
+}
+
+// formatAddress renders an address as "Name <address>" without RFC 2047 encoding
+func formatAddress(address *mail.Address) string {
+	if address.Name == "" {
+		return address.Address
+	}
+	return fmt.Sprintf("%s <%s>", address.Name, address.Address)
+}
+
+// formatEmailDate formats a message date, zero dates are left empty
+func formatEmailDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
+
+// metadata returns the message headers and attachments as document metadata
+func (m *emailMessage) metadata() map[string]interface{} {
+	names := make([]string, 0, len(m.Attachments))
+	for _, attachment := range m.Attachments {
+		if attachment.Filename != "" {
+			names = append(names, attachment.Filename)
+		}
+	}
+	return map[string]interface{}{
+		"from":             m.From,
+		"to":               m.To,
+		"cc":               m.Cc,
+		"subject":          m.Subject,
+		"date":             formatEmailDate(m.Date),
+		"message_id":       m.MessageID,
+		"in_reply_to":      m.InReplyTo,
+		"references":       m.References,
+		"attachments":      m.Attachments,
+		"attachment_names": names,
+	}
+}
+
+// render returns the indexable text of the message: the main headers and the body
+func (m *emailMessage) render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Subject: %s\n", m.Subject)
+	fmt.Fprintf(&b, "From: %s\n", m.From)
+	if len(m.To) > 0 {
+		fmt.Fprintf(&b, "To: %s\n", strings.Join(m.To, ", "))
+	}
+	if !m.Date.IsZero() {
+		fmt.Fprintf(&b, "Date: %s\n", m.Date.Format(time.RFC1123Z))
+	}
+	if len(m.Attachments) > 0 {
+		names := make([]string, 0, len(m.Attachments))
+		for _, attachment := range m.Attachments {
+			if attachment.Filename != "" {
+				names = append(names, attachment.Filename)
+			}
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(&b, "Attachments: %s\n", strings.Join(names, ", "))
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(m.Body)
+	return b.String()
+}
@@ -0,0 +1,250 @@
+package indexing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLReader implements DocumentReader for HTML pages. Navigation, scripts,
+// forms and other boilerplate are dropped, and the main content is preferred
+// when the page marks it with <main> or <article>.
+type HTMLReader struct{}
+
+// NewHTMLReader creates a new HTMLReader instance
+func NewHTMLReader() *HTMLReader {
+	return &HTMLReader{}
+}
+
+// GetSupportedExtensions returns supported file extensions
+func (r *HTMLReader) GetSupportedExtensions() []string {
+	return []string{".html", ".htm", ".xhtml"}
+}
+
+// Read implements DocumentReader interface
+func (r *HTMLReader) Read(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *HTMLReader) ReadBytes(path string, data []byte) (*Document, error) {
+	content, metadata, err := extractHTML(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	metadata["filename"] = filepath.Base(path)
+	metadata["extension"] = filepath.Ext(path)
+	metadata["type"] = "html"
+
+	return &Document{
+		Path:     path,
+		Content:  content,
+		Metadata: metadata,
+		Encoding: "utf-8",
+	}, nil
+}
+
+// boilerplateElements are skipped with their whole subtree
+var boilerplateElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+}
+
+// blockElements end a line of extracted text
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Table: true, atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+}
+
+// boilerplateRoles are ARIA roles of page chrome
+var boilerplateRoles = map[string]bool{
+	"navigation":    true,
+	"banner":        true,
+	"contentinfo":   true,
+	"complementary": true,
+	"search":        true,
+}
+
+// extractHTML returns the readable text of an HTML page and its metadata
+// (title, description, language, headings and link count)
+func extractHTML(reader io.Reader) (string, map[string]interface{}, error) {
+	root, err := html.Parse(reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := make(map[string]interface{})
+	var headings []map[string]interface{}
+	links := 0
+
+	var main *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Html:
+				if lang := htmlAttr(n, "lang"); lang != "" {
+					metadata["language"] = lang
+				}
+			case atom.Title:
+				metadata["title"] = strings.TrimSpace(htmlText(n))
+			case atom.Meta:
+				name := strings.ToLower(htmlAttr(n, "name"))
+				if name == "" {
+					name = strings.ToLower(htmlAttr(n, "property"))
+				}
+				switch name {
+				case "description", "og:description":
+					metadata["description"] = htmlAttr(n, "content")
+				case "keywords":
+					metadata["keywords"] = htmlAttr(n, "content")
+				case "author":
+					metadata["author"] = htmlAttr(n, "content")
+				}
+			case atom.Main, atom.Article:
+				if main == nil {
+					main = n
+				}
+			case atom.A:
+				if htmlAttr(n, "href") != "" {
+					links++
+				}
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				headings = append(headings, map[string]interface{}{
+					"level": int(n.Data[1] - '0'),
+					"text":  strings.Join(strings.Fields(htmlText(n)), " "),
+				})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	body := main
+	if body == nil {
+		body = findElement(root, atom.Body)
+	}
+	if body == nil {
+		body = root
+	}
+
+	var buf bytes.Buffer
+	renderReadable(body, &buf, main != nil)
+
+	lines := strings.Split(buf.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+
+	metadata["headings"] = headings
+	metadata["links"] = links
+	return strings.Join(kept, "\n"), metadata, nil
+}
+
+// renderReadable writes the text of n, skipping boilerplate subtrees. Headers
+// and footers are page chrome, unless they belong to the main content.
+func renderReadable(n *html.Node, buf *bytes.Buffer, inContent bool) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(n.Data)
+		return
+	case html.CommentNode:
+		return
+	case html.ElementNode:
+		if boilerplateElements[n.DataAtom] || boilerplateRoles[htmlAttr(n, "role")] || hasAttr(n, "hidden") {
+			return
+		}
+		if htmlAttr(n, "aria-hidden") == "true" {
+			return
+		}
+		if !inContent && (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) {
+			return
+		}
+		if n.DataAtom == atom.Main || n.DataAtom == atom.Article {
+			inContent = true
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderReadable(c, buf, inContent)
+	}
+	if n.Type == html.ElementNode && blockElements[n.DataAtom] {
+		buf.WriteByte('\n')
+	}
+}
+
+// htmlText returns the concatenated text below n
+func htmlText(n *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return buf.String()
+}
+
+// htmlAttr returns the value of an attribute, or an empty string
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n carries an attribute, even an empty one
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// findElement returns the first element of the given type below n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package indexing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ManifestFileName is the name of the manifest kept in the index directory
+const ManifestFileName = "manifest.json"
+
+// manifestVersion is bumped when the manifest layout changes
+const manifestVersion = 1
+
+// Manifest records what has been indexed: the content hash of every file and
+// of each of its chunks, with the ID of the point holding the chunk. It is
+// what lets BatchIndexer re-embed only the chunks that changed.
+type Manifest struct {
+	Version      int                   `json:"version"`
+	Collection   string                `json:"collection"`
+	ChunkSize    int                   `json:"chunk_size"`
+	ChunkOverlap int                   `json:"chunk_overlap"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	Files        map[string]*FileEntry `json:"files"`
+
+	path  string
+	mutex sync.RWMutex
+}
+
+// FileEntry is the manifest record of an indexed file
+type FileEntry struct {
+	Path      string       `json:"path"`
+	Hash      string       `json:"hash"` // SHA-256 of the raw file content
+	Size      int64        `json:"size"`
+	ModTime   time.Time    `json:"mod_time"`
+	IndexedAt time.Time    `json:"indexed_at"`
+	Chunks    []ChunkEntry `json:"chunks"`
+}
+
+// ChunkEntry is the manifest record of an indexed chunk
+type ChunkEntry struct {
+	Hash    string `json:"hash"` // SHA-256 of the chunk text
+	PointID string `json:"point_id"`
+}
+
+// LoadManifest reads the manifest at path. A missing file yields an empty manifest.
+func LoadManifest(path string) (*Manifest, error) {
+	manifest := &Manifest{
+		Version: manifestVersion,
+		Files:   make(map[string]*FileEntry),
+		path:    path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %v", path, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s", manifest.Version, path)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]*FileEntry)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest atomically
+func (m *Manifest) Save() error {
+	m.mutex.Lock()
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	m.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %v", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// Get returns the entry of an indexed file
+func (m *Manifest) Get(path string) (*FileEntry, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	entry, ok := m.Files[path]
+	return entry, ok
+}
+
+// Put records an indexed file
+func (m *Manifest) Put(entry *FileEntry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Files[entry.Path] = entry
+}
+
+// Remove forgets an indexed file
+func (m *Manifest) Remove(path string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.Files, path)
+}
+
+// Paths returns the indexed file paths in sorted order
+func (m *Manifest) Paths() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ChunkCount returns the number of chunks recorded for all files
+func (m *Manifest) ChunkCount() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	count := 0
+	for _, entry := range m.Files {
+		count += len(entry.Chunks)
+	}
+	return count
+}
+
+// matchesChunking reports whether the manifest was built with the given
+// chunking parameters. An empty manifest matches anything.
+func (m *Manifest) matchesChunking(size, overlap int) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.Files) == 0 || (m.ChunkSize == size && m.ChunkOverlap == overlap)
+}
+
+// resetChunking records new chunking parameters and clears the file hashes,
+// so that every file is re-chunked on the next run. Chunk hashes are kept:
+// chunks whose text did not change still reuse their vectors.
+func (m *Manifest) resetChunking(size, overlap int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.ChunkSize = size
+	m.ChunkOverlap = overlap
+	for _, entry := range m.Files {
+		entry.Hash = ""
+	}
+}
+
+// hashContent returns the hex SHA-256 of data
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -2,9 +2,15 @@ package indexing
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/parser"
+	"gopkg.in/yaml.v3"
 )
 
 // MarkdownReader implements DocumentReader for markdown files
@@ -24,24 +30,43 @@ func (r *MarkdownReader) GetSupportedExtensions() []string {
 
 // Read implements DocumentReader interface
 func (r *MarkdownReader) Read(path string) (*Document, error) {
-	// First read the file as text to handle encoding
-	doc, err := r.TextReader.Read(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *MarkdownReader) ReadBytes(path string, content []byte) (*Document, error) {
+	// First decode the content as text to handle encoding
+	doc, err := r.TextReader.ReadBytes(path, content)
 	if err != nil {
 		return nil, err
 	}
 
+	// Extract metadata from frontmatter if present, the body is parsed without it
+	frontMatter, body, err := extractFrontMatter(doc.Content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter in %s: %v", path, err)
+	}
+	doc.Content = body
+	for k, v := range frontMatter {
+		// File attributes read from disk take precedence
+		if _, exists := doc.Metadata[k]; !exists {
+			doc.Metadata[k] = v
+		}
+	}
+	if len(frontMatter) > 0 {
+		doc.Metadata["front_matter"] = frontMatter
+	}
+
 	// Parse markdown
 	extensions := parser.CommonExtensions | parser.AutoHeadingIDs
 	p := parser.NewWithExtensions(extensions)
 
 	node := p.Parse([]byte(doc.Content))
 
-	// Extract metadata from frontmatter if present
-	frontMatter := extractFrontMatter(node)
-	for k, v := range frontMatter {
-		doc.Metadata[k] = v
-	}
-
 	// Add markdown-specific metadata
 	doc.Metadata["headings"] = extractHeadings(node)
 	doc.Metadata["type"] = "markdown"
@@ -49,15 +74,96 @@ func (r *MarkdownReader) Read(path string) (*Document, error) {
 	return doc, nil
 }
 
-// extractFrontMatter extrait le frontmatter YAML du contenu markdown brut
-func extractFrontMatter(_ ast.Node) map[string]interface{} {
+// extractFrontMatter extrait le frontmatter du contenu markdown brut : un bloc
+// YAML délimité par "---" ou un objet JSON en tête de fichier. Il renvoie les
+// métadonnées et le corps du document sans le frontmatter.
+func extractFrontMatter(content string) (map[string]interface{}, string, error) {
 	metadata := make(map[string]interface{})
+	text := strings.TrimPrefix(content, "\ufeff")
+
+	switch {
+	case strings.HasPrefix(text, "---\n") || strings.HasPrefix(text, "---\r\n"):
+		rest := text[strings.Index(text, "\n")+1:]
+		block, body, found := cutFrontMatter(rest, "---", "...")
+		if !found {
+			return metadata, content, nil
+		}
+		if err := yaml.Unmarshal([]byte(block), &metadata); err != nil {
+			return nil, content, err
+		}
+		return normalizeFrontMatter(metadata), body, nil
+
+	case strings.HasPrefix(text, "{"):
+		// Le frontmatter JSON s'arrête à la fin du premier objet
+		decoder := json.NewDecoder(strings.NewReader(text))
+		if err := decoder.Decode(&metadata); err != nil {
+			// Un document commençant par "{" n'a pas forcément de frontmatter
+			return make(map[string]interface{}), content, nil
+		}
+		body := text[decoder.InputOffset():]
+		return normalizeFrontMatter(metadata), strings.TrimLeft(body, "\r\n"), nil
+	}
+
+	return metadata, content, nil
+}
+
+// cutFrontMatter sépare le bloc de frontmatter du corps, au premier délimiteur de fin
+func cutFrontMatter(text string, delimiters ...string) (string, string, bool) {
+	offset := 0
+	for offset <= len(text) {
+		end := strings.IndexByte(text[offset:], '\n')
+		line := text[offset:]
+		next := len(text) + 1
+		if end >= 0 {
+			line = text[offset : offset+end]
+			next = offset + end + 1
+		}
+		trimmed := strings.TrimRight(line, " \t\r")
+		for _, delimiter := range delimiters {
+			if trimmed == delimiter {
+				body := ""
+				if next <= len(text) {
+					body = text[next:]
+				}
+				return text[:offset], body, true
+			}
+		}
+		offset = next
+	}
+	return "", text, false
+}
 
-	// On ne peut pas utiliser ast.YamlMetadata, donc on ne fait rien ici
-	// (le frontmatter devrait être extrait avant le parsing markdown)
+// normalizeFrontMatter convertit les valeurs YAML en types compatibles JSON :
+// clés de map en chaînes et dates au format RFC 3339
+func normalizeFrontMatter(metadata map[string]interface{}) map[string]interface{} {
+	for key, value := range metadata {
+		metadata[key] = normalizeFrontMatterValue(value)
+	}
 	return metadata
 }
 
+func normalizeFrontMatterValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeFrontMatter(v)
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, inner := range v {
+			converted[fmt.Sprint(key)] = normalizeFrontMatterValue(inner)
+		}
+		return converted
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = normalizeFrontMatterValue(inner)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
+
 // extractHeadings extracts all headings with their levels
 func extractHeadings(node ast.Node) []map[string]interface{} {
 	var headings []map[string]interface{}
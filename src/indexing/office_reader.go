@@ -0,0 +1,328 @@
+package indexing
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OfficeReader implements DocumentReader for Office Open XML (.docx) and
+// OpenDocument (.odt) text documents. Both are zip archives of XML parts:
+// the body text and headings come from the main part, the title, authors
+// and dates from the document properties.
+type OfficeReader struct{}
+
+// NewOfficeReader creates a new OfficeReader instance
+func NewOfficeReader() *OfficeReader {
+	return &OfficeReader{}
+}
+
+// GetSupportedExtensions returns supported file extensions
+func (r *OfficeReader) GetSupportedExtensions() []string {
+	return []string{".docx", ".odt"}
+}
+
+// Read implements DocumentReader interface
+func (r *OfficeReader) Read(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *OfficeReader) ReadBytes(path string, content []byte) (*Document, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document archive: %v", err)
+	}
+
+	var body officeBody
+	metadata := map[string]interface{}{
+		"filename":  filepath.Base(path),
+		"extension": filepath.Ext(path),
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".docx":
+		metadata["type"] = "docx"
+		if err := readZipXML(archive, "word/document.xml", body.parseDOCX); err != nil {
+			return nil, err
+		}
+		// Document properties are optional
+		_ = readZipXML(archive, "docProps/core.xml", collectProperties(metadata, docxProperties))
+		_ = readZipXML(archive, "docProps/app.xml", collectProperties(metadata, docxProperties))
+	case ".odt":
+		metadata["type"] = "odt"
+		if err := readZipXML(archive, "content.xml", body.parseODT); err != nil {
+			return nil, err
+		}
+		_ = readZipXML(archive, "meta.xml", collectProperties(metadata, odtProperties))
+	default:
+		return nil, fmt.Errorf("unsupported office document: %s", path)
+	}
+
+	metadata["headings"] = body.headings
+	metadata["paragraphs"] = len(body.paragraphs)
+
+	return &Document{
+		Path:     path,
+		Content:  strings.Join(body.paragraphs, "\n"),
+		Metadata: metadata,
+		Encoding: "utf-8",
+	}, nil
+}
+
+// officeBody accumulates the paragraphs and headings of a document
+type officeBody struct {
+	paragraphs []string
+	headings   []map[string]interface{}
+	current    strings.Builder
+	level      int // Heading level of the current paragraph, 0 for body text
+}
+
+// endParagraph stores the current paragraph
+func (b *officeBody) endParagraph() {
+	text := strings.TrimSpace(b.current.String())
+	if text != "" {
+		b.paragraphs = append(b.paragraphs, text)
+		if b.level > 0 {
+			b.headings = append(b.headings, map[string]interface{}{
+				"level": b.level,
+				"text":  text,
+			})
+		}
+	}
+	b.current.Reset()
+	b.level = 0
+}
+
+// parseDOCX walks word/document.xml
+func (b *officeBody) parseDOCX(decoder *xml.Decoder) error {
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.current.WriteByte('\t')
+			case "br", "cr":
+				b.current.WriteByte('\n')
+			case "pStyle":
+				b.level = docxHeadingLevel(xmlAttr(t, "val"))
+			case "outlineLvl":
+				if n, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && b.level == 0 {
+					b.level = n + 1
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.endParagraph()
+			case "tc":
+				b.current.WriteByte('\t')
+			}
+		case xml.CharData:
+			if inText {
+				b.current.Write(t)
+			}
+		}
+	}
+}
+
+// parseODT walks content.xml
+func (b *officeBody) parseODT(decoder *xml.Decoder) error {
+	depth := 0 // Depth inside <office:text>
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Local == "text" && strings.Contains(t.Name.Space, "office") {
+					depth = 1
+				}
+				continue
+			}
+			depth++
+			switch t.Name.Local {
+			case "h":
+				b.level = 1
+				if n, err := strconv.Atoi(xmlAttr(t, "outline-level")); err == nil && n > 0 {
+					b.level = n
+				}
+			case "s":
+				count := 1
+				if n, err := strconv.Atoi(xmlAttr(t, "c")); err == nil && n > 0 {
+					count = n
+				}
+				b.current.WriteString(strings.Repeat(" ", count))
+			case "tab":
+				b.current.WriteByte('\t')
+			case "line-break":
+				b.current.WriteByte('\n')
+			case "annotation", "note-citation":
+				// Comments and footnote marks are not part of the text
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				depth--
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+			depth--
+			switch t.Name.Local {
+			case "p", "h":
+				b.endParagraph()
+			}
+		case xml.CharData:
+			if depth > 0 {
+				b.current.Write(t)
+			}
+		}
+	}
+}
+
+// docxHeadingLevel maps a paragraph style ("Heading2", "Titre2", "Title") to a heading level
+func docxHeadingLevel(style string) int {
+	lower := strings.ToLower(style)
+	if lower == "title" || lower == "titre" {
+		return 1
+	}
+	for _, prefix := range []string{"heading", "titre"} {
+		if strings.HasPrefix(lower, prefix) {
+			if n, err := strconv.Atoi(strings.TrimSpace(lower[len(prefix):])); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// docxProperties maps the core and app properties of a .docx to metadata keys
+var docxProperties = map[string]string{
+	"title":          "title",
+	"subject":        "subject",
+	"creator":        "author",
+	"keywords":       "keywords",
+	"description":    "description",
+	"lastModifiedBy": "last_modified_by",
+	"created":        "creation_date",
+	"modified":       "modification_date",
+	"Pages":          "pages",
+	"Words":          "words",
+}
+
+// odtProperties maps the meta.xml properties of an .odt to metadata keys
+var odtProperties = map[string]string{
+	"title":           "title",
+	"subject":         "subject",
+	"description":     "description",
+	"keyword":         "keywords",
+	"initial-creator": "author",
+	"creator":         "last_modified_by",
+	"creation-date":   "creation_date",
+	"date":            "modification_date",
+}
+
+// collectProperties returns a parser storing the text of the mapped elements
+// in metadata. Document statistics of ODT are read from attributes.
+func collectProperties(metadata map[string]interface{}, mapping map[string]string) func(*xml.Decoder) error {
+	return func(decoder *xml.Decoder) error {
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			if start.Name.Local == "document-statistic" {
+				for attr, key := range map[string]string{"page-count": "pages", "word-count": "words"} {
+					if value := xmlAttr(start, attr); value != "" {
+						metadata[key] = value
+					}
+				}
+				continue
+			}
+			key, ok := mapping[start.Name.Local]
+			if !ok {
+				continue
+			}
+			var value string
+			if err := decoder.DecodeElement(&value, &start); err != nil {
+				return err
+			}
+			if value = strings.TrimSpace(value); value != "" {
+				if existing, found := metadata[key].(string); found && key == "keywords" {
+					value = existing + ", " + value
+				}
+				metadata[key] = value
+			}
+		}
+	}
+}
+
+// readZipXML opens an XML part of an archive and hands its decoder to parse
+func readZipXML(archive *zip.Reader, name string, parse func(*xml.Decoder) error) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", name, err)
+		}
+		defer rc.Close()
+
+		decoder := xml.NewDecoder(rc)
+		decoder.Strict = false
+		if err := parse(decoder); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s not found in archive", name)
+}
+
+// xmlAttr returns the value of an attribute by local name
+func xmlAttr(start xml.StartElement, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -1,7 +1,9 @@
 package indexing
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
@@ -40,7 +42,16 @@ func getPDFString(infoDict interface{}, key string) string {
 
 // Read implements DocumentReader interface
 func (r *PDFReader) Read(path string) (*Document, error) {
-	ctx, err := api.ReadContextFile(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *PDFReader) ReadBytes(path string, content []byte) (*Document, error) {
+	ctx, err := api.ReadContext(bytes.NewReader(content), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read PDF info: %v", err)
 	}
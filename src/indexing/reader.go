@@ -1,11 +1,24 @@
 package indexing
 
+// Document is the content and metadata of a file read by a DocumentReader
+type Document struct {
+	Path     string
+	Content  string
+	Metadata map[string]interface{}
+	Encoding string
+}
+
+// IndexingDocument is the document type returned by readers
+type IndexingDocument = Document
+
 // DocumentReader interface defines methods for reading different document formats
 // Use IndexingDocument for local use
-//
 type DocumentReader interface {
 	// Read reads the content and metadata from a file
 	Read(path string) (*IndexingDocument, error)
+	// ReadBytes extracts the content and metadata from the already loaded
+	// content of a file; path is only used for its name and extension
+	ReadBytes(path string, content []byte) (*IndexingDocument, error)
 	// GetSupportedExtensions returns the file extensions this reader supports
 	GetSupportedExtensions() []string
 }
@@ -18,6 +31,11 @@ const (
 	TypeText
 	TypeMarkdown
 	TypePDF
+	TypeHTML
+	TypeOffice
+	TypeCode
+	TypeRecords
+	TypeEmail
 )
 
 // ReaderFactory creates appropriate DocumentReader based on file extension
@@ -35,6 +53,11 @@ func NewReaderFactory() *ReaderFactory {
 	rf.RegisterReader(NewTextReader())
 	rf.RegisterReader(NewMarkdownReader())
 	rf.RegisterReader(NewPDFReader())
+	rf.RegisterReader(NewHTMLReader())
+	rf.RegisterReader(NewOfficeReader())
+	rf.RegisterReader(NewCodeReader())
+	rf.RegisterReader(NewRecordReader())
+	rf.RegisterReader(NewEmailReader())
 
 	return rf
 }
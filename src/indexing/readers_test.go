@@ -0,0 +1,388 @@
+package indexing
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+func writeTestArchive(t *testing.T, name string, parts map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for partName, content := range parts {
+		w, err := archive.Create(partName)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", partName, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return path
+}
+
+func TestReaderFactory_Extensions(t *testing.T) {
+	factory := NewReaderFactory()
+	for _, ext := range []string{".html", ".docx", ".odt", ".go", ".ts", ".py", ".csv", ".json", ".eml", ".mbox"} {
+		if _, ok := factory.GetReader(ext); !ok {
+			t.Errorf("No reader registered for %s", ext)
+		}
+	}
+}
+
+func TestReadBytes_DoesNotReadTheFile(t *testing.T) {
+	// The content already loaded by the indexer is parsed, even if the file
+	// changed or disappeared since
+	missing := filepath.Join(t.TempDir(), "gone")
+	factory := NewReaderFactory()
+	cases := map[string]string{
+		".txt":  "plain text body",
+		".md":   "# Title\n\nmarkdown body",
+		".html": "<html><body><main><p>html body</p></main></body></html>",
+		".csv":  "name\ncsv body\n",
+		".go":   "package demo\n\n// go body\nfunc Run() {}\n",
+		".eml":  "Subject: hello\r\n\r\nemail body\r\n",
+	}
+	for ext, content := range cases {
+		reader, ok := factory.GetReader(ext)
+		if !ok {
+			t.Fatalf("No reader registered for %s", ext)
+		}
+		doc, err := reader.ReadBytes(missing+ext, []byte(content))
+		if err != nil {
+			t.Errorf("ReadBytes %s failed: %v", ext, err)
+			continue
+		}
+		if !strings.Contains(doc.Content, "body") {
+			t.Errorf("Unexpected %s content: %q", ext, doc.Content)
+		}
+	}
+}
+
+func TestHTMLReader(t *testing.T) {
+	path := writeTestFile(t, "page.html", `<!DOCTYPE html>
+<html lang="fr"><head><title>Campaign report</title>
+<meta name="description" content="Monthly delivery figures"><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+<header>Site banner</header>
+<main><article><header><h1>Delivery report</h1></header>
+<p>Bounce rate dropped to <b>1.2%</b> this month.</p>
+<div aria-hidden="true">icon</div></article></main>
+<footer>Copyright</footer>
+</body></html>`)
+
+	doc, err := NewHTMLReader().Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !strings.Contains(doc.Content, "Delivery report") || !strings.Contains(doc.Content, "Bounce rate dropped to 1.2% this month.") {
+		t.Errorf("Main content missing: %q", doc.Content)
+	}
+	for _, boilerplate := range []string{"Home", "Site banner", "Copyright", "track()", "icon"} {
+		if strings.Contains(doc.Content, boilerplate) {
+			t.Errorf("Boilerplate %q not stripped: %q", boilerplate, doc.Content)
+		}
+	}
+	if doc.Metadata["title"] != "Campaign report" || doc.Metadata["language"] != "fr" || doc.Metadata["description"] != "Monthly delivery figures" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+}
+
+func TestOfficeReader(t *testing.T) {
+	docx := writeTestArchive(t, "notes.docx", map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Release notes</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Retries are </w:t></w:r><w:r><w:t>now exponential.</w:t></w:r></w:p>
+</w:body></w:document>`,
+		"docProps/core.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title>Notes</dc:title><dc:creator>Ops team</dc:creator></cp:coreProperties>`,
+	})
+
+	doc, err := NewOfficeReader().Read(docx)
+	if err != nil {
+		t.Fatalf("Read docx failed: %v", err)
+	}
+	if doc.Content != "Release notes\nRetries are now exponential." {
+		t.Errorf("Unexpected docx content: %q", doc.Content)
+	}
+	if doc.Metadata["title"] != "Notes" || doc.Metadata["author"] != "Ops team" {
+		t.Errorf("Unexpected docx metadata: %v", doc.Metadata)
+	}
+	if headings := doc.Metadata["headings"].([]map[string]interface{}); len(headings) != 1 || headings[0]["level"] != 1 {
+		t.Errorf("Unexpected docx headings: %v", headings)
+	}
+
+	odt := writeTestArchive(t, "notes.odt", map[string]string{
+		"content.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:text>
+<text:h text:outline-level="2">Sending limits</text:h>
+<text:p>Up to<text:s/>500 mails<text:s text:c="2"/>per hour.</text:p>
+</office:text></office:body></office:document-content>`,
+		"meta.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<office:meta><dc:title>Limits</dc:title><meta:initial-creator>Alex</meta:initial-creator><meta:document-statistic meta:page-count="1"/></office:meta></office:document-meta>`,
+	})
+
+	doc, err = NewOfficeReader().Read(odt)
+	if err != nil {
+		t.Fatalf("Read odt failed: %v", err)
+	}
+	if doc.Content != "Sending limits\nUp to 500 mails  per hour." {
+		t.Errorf("Unexpected odt content: %q", doc.Content)
+	}
+	if doc.Metadata["title"] != "Limits" || doc.Metadata["author"] != "Alex" || doc.Metadata["pages"] != "1" {
+		t.Errorf("Unexpected odt metadata: %v", doc.Metadata)
+	}
+}
+
+func symbolSet(symbols []Symbol) map[string]Symbol {
+	set := make(map[string]Symbol)
+	for _, symbol := range symbols {
+		key := symbol.Name
+		if symbol.Parent != "" {
+			key = symbol.Parent + "." + symbol.Name
+		}
+		set[key] = symbol
+	}
+	return set
+}
+
+func TestCodeReader(t *testing.T) {
+	reader := NewCodeReader()
+
+	goPath := writeTestFile(t, "sender.go", `package mail
+
+import "fmt"
+
+// Sender sends mails
+type Sender struct{}
+
+const maxRetries = 3
+
+func (s *Sender) Send(to string) error {
+	return fmt.Errorf("not implemented: %s", to)
+}
+`)
+	doc, err := reader.Read(goPath)
+	if err != nil {
+		t.Fatalf("Read Go failed: %v", err)
+	}
+	symbols := symbolSet(doc.Metadata["symbols"].([]Symbol))
+	if send := symbols["Sender.Send"]; send.Kind != "method" || send.Line != 10 || send.EndLine != 12 {
+		t.Errorf("Unexpected Go method symbol: %+v", send)
+	}
+	if symbols["Sender"].Kind != "struct" || symbols["maxRetries"].Exported {
+		t.Errorf("Unexpected Go symbols: %+v", symbols)
+	}
+	if doc.Metadata["package"] != "mail" {
+		t.Errorf("Unexpected package: %v", doc.Metadata["package"])
+	}
+
+	tsPath := writeTestFile(t, "queue.ts", `import { Mail } from './mail';
+
+export interface QueueOptions {
+  retries: number;
+}
+
+export class MailQueue {
+  private items: Mail[] = [];
+
+  push(mail: Mail): void {
+    this.items.push(mail);
+  }
+}
+
+export const drain = async (queue: MailQueue) => {
+  return queue;
+};
+`)
+	doc, err = reader.Read(tsPath)
+	if err != nil {
+		t.Fatalf("Read TS failed: %v", err)
+	}
+	symbols = symbolSet(doc.Metadata["symbols"].([]Symbol))
+	if symbols["QueueOptions"].Kind != "interface" || symbols["MailQueue"].EndLine != 13 {
+		t.Errorf("Unexpected TS symbols: %+v", symbols)
+	}
+	if push := symbols["MailQueue.push"]; push.Kind != "method" || push.Line != 10 || push.EndLine != 12 {
+		t.Errorf("Unexpected TS method symbol: %+v", push)
+	}
+	if drain := symbols["drain"]; drain.Kind != "function" || drain.Parent != "" || drain.EndLine != 17 {
+		t.Errorf("Unexpected TS arrow function symbol: %+v", drain)
+	}
+	if imports := doc.Metadata["imports"].([]string); len(imports) != 1 || imports[0] != "./mail" {
+		t.Errorf("Unexpected TS imports: %v", imports)
+	}
+
+	pyPath := writeTestFile(t, "bounce.py", `import os
+from email import parser
+
+class BounceHandler:
+    def handle(self, message):
+        return self._classify(message)
+
+    def _classify(self, message):
+        return "hard"
+
+
+def main():
+    pass
+`)
+	doc, err = reader.Read(pyPath)
+	if err != nil {
+		t.Fatalf("Read Python failed: %v", err)
+	}
+	symbols = symbolSet(doc.Metadata["symbols"].([]Symbol))
+	if handler := symbols["BounceHandler"]; handler.Kind != "class" || handler.Line != 4 || handler.EndLine != 9 {
+		t.Errorf("Unexpected Python class symbol: %+v", handler)
+	}
+	if classify := symbols["BounceHandler._classify"]; classify.Kind != "method" || classify.Exported {
+		t.Errorf("Unexpected Python method symbol: %+v", classify)
+	}
+	if main := symbols["main"]; main.Line != 12 || main.EndLine != 13 {
+		t.Errorf("Unexpected Python function symbol: %+v", main)
+	}
+	if imports := doc.Metadata["imports"].([]string); strings.Join(imports, ",") != "os,email" {
+		t.Errorf("Unexpected Python imports: %v", imports)
+	}
+}
+
+func TestRecordReader(t *testing.T) {
+	reader := NewRecordReader()
+
+	csvPath := writeTestFile(t, "contacts.csv", "email,name\nops@example.com,Ops\nsales@example.com,\"Sales, EU\"\n")
+	doc, err := reader.Read(csvPath)
+	if err != nil {
+		t.Fatalf("Read CSV failed: %v", err)
+	}
+	if doc.Metadata["record_count"] != 2 {
+		t.Errorf("Expected 2 records, got %v", doc.Metadata["record_count"])
+	}
+	if !strings.Contains(doc.Content, "email: sales@example.com\nname: Sales, EU") {
+		t.Errorf("Unexpected CSV content: %q", doc.Content)
+	}
+
+	jsonPath := writeTestFile(t, "campaigns.json", `{"campaigns": [{"id": 1, "target": {"segment": "vip"}, "tags": ["q3", "promo"]}]}`)
+	doc, err = reader.Read(jsonPath)
+	if err != nil {
+		t.Fatalf("Read JSON failed: %v", err)
+	}
+	if doc.Content != "id: 1\ntags: q3, promo\ntarget.segment: vip" {
+		t.Errorf("Unexpected JSON content: %q", doc.Content)
+	}
+}
+
+const testMessage = `From: =?UTF-8?Q?Service_Cl=C3=A9ients?= <clients@example.com>
+To: ops@example.com, "Sales" <sales@example.com>
+Subject: =?ISO-8859-1?Q?R=E9sum=E9?= of bounces
+Date: Mon, 02 Jun 2025 10:00:00 +0200
+Message-ID: <abc@example.com>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=ISO-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Voici le r=E9sum=E9 des rebonds.
+--inner
+Content-Type: text/html; charset=UTF-8
+
+<p>Voici le résumé des rebonds.</p>
+--inner--
+--outer
+Content-Type: application/pdf; name="report.pdf"
+Content-Disposition: attachment; filename="report.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0xLjQK
+--outer--
+`
+
+func TestEmailReader(t *testing.T) {
+	reader := NewEmailReader()
+
+	doc, err := reader.Read(writeTestFile(t, "bounces.eml", testMessage))
+	if err != nil {
+		t.Fatalf("Read eml failed: %v", err)
+	}
+	if doc.Metadata["subject"] != "Résumé of bounces" || doc.Metadata["from"] != "Service Cléients <clients@example.com>" {
+		t.Errorf("Unexpected headers: %v", doc.Metadata)
+	}
+	if to := doc.Metadata["to"].([]string); len(to) != 2 || to[1] != "Sales <sales@example.com>" {
+		t.Errorf("Unexpected recipients: %v", to)
+	}
+	if !strings.Contains(doc.Content, "Voici le résumé des rebonds.") || strings.Contains(doc.Content, "<p>") {
+		t.Errorf("Unexpected body: %q", doc.Content)
+	}
+	attachments := doc.Metadata["attachments"].([]emailAttachment)
+	if len(attachments) != 1 || attachments[0].Filename != "report.pdf" || attachments[0].Size != 9 {
+		t.Errorf("Unexpected attachments: %+v", attachments)
+	}
+
+	mbox := "From clients@example.com Mon Jun  2 10:00:00 2025\n" + testMessage +
+		"\nFrom ops@example.com Tue Jun  3 10:00:00 2025\nFrom: ops@example.com\nSubject: Follow-up\n\nSee below.\n>From the archive.\n"
+	doc, err = reader.Read(writeTestFile(t, "archive.mbox", mbox))
+	if err != nil {
+		t.Fatalf("Read mbox failed: %v", err)
+	}
+	if doc.Metadata["message_count"] != 2 {
+		t.Fatalf("Expected 2 messages, got %v", doc.Metadata["message_count"])
+	}
+	if !strings.Contains(doc.Content, "\nFrom the archive.") {
+		t.Errorf("Expected the >From quoting to be undone: %q", doc.Content)
+	}
+}
+
+func TestExtractFrontMatter(t *testing.T) {
+	content := "---\ntitle: Deliverability guide\ntags: [smtp, dkim]\ndate: 2025-06-02\nauthor:\n  name: Ops\n---\n# Guide\nBody text.\n"
+	metadata, body, err := extractFrontMatter(content)
+	if err != nil {
+		t.Fatalf("extractFrontMatter failed: %v", err)
+	}
+	if metadata["title"] != "Deliverability guide" || body != "# Guide\nBody text.\n" {
+		t.Errorf("Unexpected result: %v %q", metadata, body)
+	}
+	if tags, ok := metadata["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Unexpected tags: %v", metadata["tags"])
+	}
+	if author, ok := metadata["author"].(map[string]interface{}); !ok || author["name"] != "Ops" {
+		t.Errorf("Unexpected nested value: %v", metadata["author"])
+	}
+
+	metadata, body, err = extractFrontMatter("{\"draft\": true}\n# Title\n")
+	if err != nil || metadata["draft"] != true || body != "# Title\n" {
+		t.Errorf("Unexpected JSON front matter result: %v %q %v", metadata, body, err)
+	}
+
+	metadata, body, err = extractFrontMatter("# No front matter\n---\n")
+	if err != nil || len(metadata) != 0 || body != "# No front matter\n---\n" {
+		t.Errorf("Expected the document unchanged: %v %q %v", metadata, body, err)
+	}
+}
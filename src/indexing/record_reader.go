@@ -0,0 +1,261 @@
+package indexing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxRecords bounds the number of records rendered from a single file
+const maxRecords = 100000
+
+// RecordReader implements DocumentReader for tabular and structured data:
+// CSV/TSV files and JSON documents or JSON Lines. Each record is rendered as
+// "field: value" lines, so a chunk stays readable on its own.
+type RecordReader struct{}
+
+// NewRecordReader creates a new RecordReader instance
+func NewRecordReader() *RecordReader {
+	return &RecordReader{}
+}
+
+// GetSupportedExtensions returns supported file extensions
+func (r *RecordReader) GetSupportedExtensions() []string {
+	return []string{".csv", ".tsv", ".json", ".jsonl", ".ndjson"}
+}
+
+// Read implements DocumentReader interface
+func (r *RecordReader) Read(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadBytes(path, content)
+}
+
+// ReadBytes implements DocumentReader interface
+func (r *RecordReader) ReadBytes(path string, data []byte) (*Document, error) {
+	source := bytes.NewReader(data)
+	ext := strings.ToLower(filepath.Ext(path))
+	var records []map[string]interface{}
+	var fields []string
+	var err error
+	format := strings.TrimPrefix(ext, ".")
+
+	switch ext {
+	case ".csv", ".tsv":
+		records, fields, err = readCSVRecords(source, ext == ".tsv")
+	case ".jsonl", ".ndjson":
+		format = "jsonl"
+		records, err = readJSONLines(source)
+	default:
+		records, err = readJSONRecords(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %v", err)
+	}
+	if fields == nil {
+		fields = recordFields(records)
+	}
+
+	var content strings.Builder
+	for i, record := range records {
+		if i > 0 {
+			content.WriteString("\n\n")
+		}
+		writeRecord(&content, record, fields)
+	}
+
+	return &Document{
+		Path:    path,
+		Content: content.String(),
+		Metadata: map[string]interface{}{
+			"filename":     filepath.Base(path),
+			"extension":    filepath.Ext(path),
+			"type":         "records",
+			"format":       format,
+			"fields":       fields,
+			"record_count": len(records),
+		},
+		Encoding: "utf-8",
+	}, nil
+}
+
+// readCSVRecords reads a CSV file whose first row holds the column names
+func readCSVRecords(reader io.Reader, tabs bool) ([]map[string]interface{}, []string, error) {
+	csvReader := csv.NewReader(bufio.NewReader(reader))
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	if tabs {
+		csvReader.Comma = '\t'
+	}
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		header[i] = name
+	}
+
+	var records []map[string]interface{}
+	for len(records) < maxRecords {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		record := make(map[string]interface{}, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, header, nil
+}
+
+// readJSONRecords reads a JSON document: an array is a list of records,
+// an object holding a single array is unwrapped, any other value is one record
+func readJSONRecords(reader io.Reader) ([]map[string]interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if object, ok := value.(map[string]interface{}); ok && len(object) == 1 {
+		for _, inner := range object {
+			if array, ok := inner.([]interface{}); ok {
+				value = array
+			}
+		}
+	}
+
+	array, ok := value.([]interface{})
+	if !ok {
+		return []map[string]interface{}{toRecord(value)}, nil
+	}
+	if len(array) > maxRecords {
+		array = array[:maxRecords]
+	}
+	records := make([]map[string]interface{}, len(array))
+	for i, item := range array {
+		records[i] = toRecord(item)
+	}
+	return records, nil
+}
+
+// readJSONLines reads one JSON value per line
+func readJSONLines(reader io.Reader) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	for len(records) < maxRecords {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", len(records)+1, err)
+		}
+		records = append(records, toRecord(value))
+	}
+	return records, nil
+}
+
+// toRecord flattens a JSON value into a record with dotted keys
+func toRecord(value interface{}) map[string]interface{} {
+	record := make(map[string]interface{})
+	if _, ok := value.(map[string]interface{}); !ok {
+		record["value"] = value
+		return record
+	}
+	flattenInto(record, "", value)
+	return record
+}
+
+// flattenInto stores the leaves of value under dotted keys
+func flattenInto(record map[string]interface{}, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenInto(record, key, inner)
+		}
+	case []interface{}:
+		scalars := make([]string, 0, len(v))
+		for i, inner := range v {
+			switch inner.(type) {
+			case map[string]interface{}, []interface{}:
+				flattenInto(record, fmt.Sprintf("%s.%d", prefix, i), inner)
+			default:
+				scalars = append(scalars, fmt.Sprint(inner))
+			}
+		}
+		if len(scalars) > 0 {
+			record[prefix] = strings.Join(scalars, ", ")
+		}
+	default:
+		record[prefix] = value
+	}
+}
+
+// recordFields returns the union of the record keys in sorted order
+func recordFields(records []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, record := range records {
+		for key := range record {
+			if !seen[key] {
+				seen[key] = true
+				fields = append(fields, key)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// writeRecord renders the non-empty fields of a record, one per line
+func writeRecord(builder *strings.Builder, record map[string]interface{}, fields []string) {
+	first := true
+	for _, field := range fields {
+		value, ok := record[field]
+		if !ok || value == nil {
+			continue
+		}
+		text := strings.TrimSpace(fmt.Sprint(value))
+		if text == "" {
+			continue
+		}
+		if !first {
+			builder.WriteByte('\n')
+		}
+		first = false
+		builder.WriteString(field)
+		builder.WriteString(": ")
+		builder.WriteString(text)
+	}
+}
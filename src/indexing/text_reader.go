@@ -28,32 +28,22 @@ func (r *TextReader) GetSupportedExtensions() []string {
 
 // Read implements DocumentReader interface
 func (r *TextReader) Read(path string) (*Document, error) {
-	file, err := os.Open(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	// Read the first 4096 bytes for encoding detection
-	buffer := make([]byte, 4096)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return nil, err
-	}
-	buffer = buffer[:n]
+	return r.ReadBytes(path, content)
+}
 
-	// Detect encoding
+// ReadBytes implements DocumentReader interface
+func (r *TextReader) ReadBytes(path string, data []byte) (*Document, error) {
+	// Use the first 4096 bytes for encoding detection
 	detector := chardet.NewTextDetector()
-	result, err := detector.DetectBest(buffer)
+	result, err := detector.DetectBest(data[:min(len(data), 4096)])
 	if err != nil {
 		return nil, err
 	}
 
-	// Rewind file for full read
-	if _, err := file.Seek(0, 0); err != nil {
-		return nil, err
-	}
-
 	// Create appropriate decoder
 	var decoder *encoding.Decoder
 	switch result.Charset {
@@ -72,7 +62,7 @@ func (r *TextReader) Read(path string) (*Document, error) {
 	}
 
 	// Read and decode the content
-	reader := bufio.NewReader(decoder.Reader(file))
+	reader := bufio.NewReader(decoder.Reader(bytes.NewReader(data)))
 	var content bytes.Buffer
 
 	for {
@@ -86,19 +76,13 @@ func (r *TextReader) Read(path string) (*Document, error) {
 		}
 	}
 
-	// Create document with metadata
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, err
-	}
-
 	doc := &Document{
 		Path:    path,
 		Content: content.String(),
 		Metadata: map[string]interface{}{
 			"filename":   filepath.Base(path),
 			"extension":  filepath.Ext(path),
-			"size":       fileInfo.Size(),
+			"size":       int64(len(data)),
 			"encoding":   result.Charset,
 			"confidence": result.Confidence,
 		},
@@ -1,71 +1,144 @@
 package qdrant
 
 import (
-	"context"
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"email_sender/mocks"
 )
 
-// EmbeddedClient wraps the mock client to provide an embedded Qdrant experience
-// This allows for internal vector storage without external Qdrant dependency
+// ErrClientClosed is returned by the operations of a closed EmbeddedClient
+var ErrClientClosed = errors.New("qdrant embedded client is closed")
+
+// EmbeddedClient is an in-process vector store exposing the same interface as
+// an external Qdrant server. Each collection keeps its points in memory with an
+// HNSW index, and is persisted as a JSON snapshot under DataPath so the whole
+// RAG pipeline can run without a Qdrant container.
 type EmbeddedClient struct {
-	mockClient *mocks.MockQDrantClient
-	baseURL    string
-	config     *EmbeddedConfig
-	mutex      sync.RWMutex
+	baseURL     string
+	config      *EmbeddedConfig
+	collections map[string]*embeddedCollection
+	stats       embeddedStats
+	mutex       sync.RWMutex
+	stopBackup  chan struct{}
+	backupDone  chan struct{}
+	closed      atomic.Bool // read without mutex by the collection writers
 }
 
 // EmbeddedConfig controls the embedded client behavior
 type EmbeddedConfig struct {
-	DataPath        string        `json:"data_path"`          // Path for persistent storage
-	MaxCollections  int           `json:"max_collections"`    // Maximum collections
-	MaxPointsPerCol int           `json:"max_points_per_col"` // Maximum points per collection
-	CacheSize       int           `json:"cache_size"`         // In-memory cache size
-	EnablePersist   bool          `json:"enable_persist"`     // Enable persistent storage
-	BackupInterval  time.Duration `json:"backup_interval"`    // Backup interval for persistence
+	DataPath          string        `json:"data_path"`           // Path for persistent storage
+	MaxCollections    int           `json:"max_collections"`     // Maximum collections
+	MaxPointsPerCol   int           `json:"max_points_per_col"`  // Maximum points per collection
+	CacheSize         int           `json:"cache_size"`          // In-memory cache size
+	EnablePersist     bool          `json:"enable_persist"`      // Enable persistent storage
+	BackupInterval    time.Duration `json:"backup_interval"`     // Backup interval for persistence
+	Distance          Distance      `json:"distance"`            // Metric of new collections
+	HNSW              HNSWConfig    `json:"hnsw"`                // HNSW graph parameters
+	FullScanThreshold int           `json:"full_scan_threshold"` // Below this many points, search is exact
+	Logger            *log.Logger   `json:"-"`                   // Background errors, log.Default() if nil
+}
+
+// embeddedCollection is a collection of points and its HNSW index
+type embeddedCollection struct {
+	name       string
+	vectorSize int
+	distance   Distance
+	points     map[string]Point
+	index      *hnswIndex
+	createdAt  time.Time
+	dirty      bool
+	mutex      sync.RWMutex
+}
+
+// embeddedStats counts the operations served by the embedded client
+type embeddedStats struct {
+	searches  int64
+	upserts   int64
+	snapshots int64
+	lastSave  time.Time
+	mutex     sync.Mutex
+}
+
+// collectionSnapshot is the on-disk representation of a collection
+type collectionSnapshot struct {
+	Name       string     `json:"name"`
+	VectorSize int        `json:"vector_size"`
+	Distance   Distance   `json:"distance"`
+	HNSW       HNSWConfig `json:"hnsw"`
+	CreatedAt  time.Time  `json:"created_at"`
+	SavedAt    time.Time  `json:"saved_at"`
+	Points     []Point    `json:"points"`
 }
 
-// NewEmbeddedClient creates a new embedded Qdrant client
+// NewEmbeddedClient creates a new embedded Qdrant client. When persistence is
+// enabled, collections found under DataPath are loaded and snapshots are
+// written every BackupInterval and on Close.
 func NewEmbeddedClient(config *EmbeddedConfig) *EmbeddedClient {
 	if config == nil {
 		config = DefaultEmbeddedConfig()
 	}
+	if config.Distance == "" {
+		config.Distance = DistanceCosine
+	}
+	if config.HNSW.M == 0 {
+		config.HNSW = DefaultHNSWConfig()
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
 
-	mockClient := mocks.NewMockQDrantClient()
+	client := &EmbeddedClient{
+		baseURL:     "embedded://localhost",
+		config:      config,
+		collections: make(map[string]*embeddedCollection),
+	}
 
-	// Configure the mock for embedded use
-	mockConfig := mockClient.GetConfig()
-	mockConfig.MaxCollections = config.MaxCollections
-	mockConfig.MaxPointsPerCol = config.MaxPointsPerCol
-	mockConfig.ErrorRate = 0.001                  // Very low error rate for embedded
-	mockConfig.TimeoutRate = 0.0001               // Minimal timeout rate
-	mockConfig.BaseLatency = 1 * time.Millisecond // Fast local access
-	mockClient.UpdateConfig(mockConfig)
+	if config.EnablePersist && config.DataPath != "" {
+		if err := client.load(); err != nil {
+			// A corrupted snapshot must not prevent the service from starting
+			config.Logger.Printf("qdrant embedded: failed to load snapshots from %s: %v", config.DataPath, err)
+		}
 
-	return &EmbeddedClient{
-		mockClient: mockClient,
-		baseURL:    "embedded://localhost",
-		config:     config,
+		if config.BackupInterval > 0 {
+			client.stopBackup = make(chan struct{})
+			client.backupDone = make(chan struct{})
+			go client.backupLoop()
+		}
 	}
+
+	return client
 }
 
 // DefaultEmbeddedConfig returns sensible defaults for embedded mode
 func DefaultEmbeddedConfig() *EmbeddedConfig {
 	return &EmbeddedConfig{
-		DataPath:        "./data/qdrant",
-		MaxCollections:  50,
-		MaxPointsPerCol: 100000,
-		CacheSize:       10000,
-		EnablePersist:   true,
-		BackupInterval:  5 * time.Minute,
+		DataPath:          "./data/qdrant",
+		MaxCollections:    50,
+		MaxPointsPerCol:   100000,
+		CacheSize:         10000,
+		EnablePersist:     true,
+		BackupInterval:    5 * time.Minute,
+		Distance:          DistanceCosine,
+		HNSW:              DefaultHNSWConfig(),
+		FullScanThreshold: 1000,
 	}
 }
 
-// HealthCheck always returns nil for embedded client (always available)
+// HealthCheck returns nil until the embedded client is closed
 func (e *EmbeddedClient) HealthCheck() error {
+	if e.closed.Load() {
+		return ErrClientClosed
+	}
 	return nil
 }
 
@@ -74,146 +147,668 @@ func (e *EmbeddedClient) IsEmbedded() bool {
 	return true
 }
 
-// CreateCollection creates a new vector collection
+// CreateCollection creates a new vector collection with the configured distance
 func (e *EmbeddedClient) CreateCollection(name string, vectorSize int) error {
-	ctx := context.Background()
-	config := map[string]interface{}{
-		"vectors": map[string]interface{}{
-			"size":     vectorSize,
-			"distance": "Cosine",
-		},
-		"optimizers_config": map[string]interface{}{
-			"default_segment_number": 2,
-			"memmap_threshold":       20000,
-		},
-		"hnsw_config": map[string]interface{}{
-			"m":                   16,
-			"ef_construct":        100,
-			"full_scan_threshold": 10000,
-		},
-	}
-
-	return e.mockClient.CreateCollection(ctx, name, config)
-}
-
-// UpsertPoints adds or updates points in a collection
-func (e *EmbeddedClient) UpsertPoints(collection string, points []Point) error {
-	ctx := context.Background()
+	return e.CreateCollectionWithDistance(name, vectorSize, e.config.Distance)
+}
 
-	// Convert points to mock format
-	mockPoints := make([]*mocks.QDrantPoint, len(points))
-	for i, p := range points {
-		// Convert float32 to float64 for mock client
-		vector64 := make([]float64, len(p.Vector))
-		for j, v := range p.Vector {
-			vector64[j] = float64(v)
-		}
+// CreateCollectionWithDistance creates a new vector collection using the given metric
+func (e *EmbeddedClient) CreateCollectionWithDistance(name string, vectorSize int, distance Distance) error {
+	if err := validateCollectionName(name); err != nil {
+		return err
+	}
+	if vectorSize <= 0 {
+		return fmt.Errorf("invalid vector size: %d", vectorSize)
+	}
+	if _, err := ParseDistance(string(distance)); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed.Load() {
+		return ErrClientClosed
+	}
+	if _, exists := e.collections[name]; exists {
+		return fmt.Errorf("collection '%s' already exists", name)
+	}
+	if e.config.MaxCollections > 0 && len(e.collections) >= e.config.MaxCollections {
+		return fmt.Errorf("maximum number of collections reached (%d)", e.config.MaxCollections)
+	}
+
+	e.collections[name] = &embeddedCollection{
+		name:       name,
+		vectorSize: vectorSize,
+		distance:   distance,
+		points:     make(map[string]Point),
+		index:      newHNSWIndex(distance, e.config.HNSW),
+		createdAt:  time.Now(),
+		dirty:      true,
+	}
+	return nil
+}
 
-		mockPoints[i] = &mocks.QDrantPoint{
-			ID:      fmt.Sprintf("%v", p.ID),
-			Vector:  vector64,
-			Payload: p.Payload,
+// UpsertPoints adds or updates points in a collection, creating it on first use
+func (e *EmbeddedClient) UpsertPoints(collection string, points []Point) error {
+	col, err := e.getCollection(collection)
+	if err != nil {
+		if errors.Is(err, ErrClientClosed) || len(points) == 0 || len(points[0].Vector) == 0 {
+			return err
+		}
+		if createErr := e.CreateCollection(collection, len(points[0].Vector)); createErr != nil {
+			return fmt.Errorf("auto-create collection failed: %w", createErr)
 		}
+		if col, err = e.getCollection(collection); err != nil {
+			return err
+		}
+	}
+
+	col.mutex.Lock()
+	defer col.mutex.Unlock()
+
+	// Close may have written the final snapshot while this call waited
+	if e.closed.Load() {
+		return ErrClientClosed
 	}
 
-	// Try to upsert points, and auto-create collection if it doesn't exist
-	err := e.mockClient.UpsertPoints(ctx, collection, mockPoints)
-	if err != nil && fmt.Sprintf("%v", err) == fmt.Sprintf("collection '%s' not found", collection) {
-		// Auto-create collection with default vector size (try to detect from first point)
-		vectorSize := 384 // Default size
-		if len(points) > 0 && len(points[0].Vector) > 0 {
-			vectorSize = len(points[0].Vector)
+	newPoints := 0
+	for _, p := range points {
+		if len(p.Vector) != col.vectorSize {
+			return fmt.Errorf("vector size mismatch for point %v: expected %d, got %d", p.ID, col.vectorSize, len(p.Vector))
+		}
+		if _, exists := col.points[pointKey(p.ID)]; !exists {
+			newPoints++
 		}
+	}
+	if e.config.MaxPointsPerCol > 0 && len(col.points)+newPoints > e.config.MaxPointsPerCol {
+		return fmt.Errorf("collection '%s' would exceed %d points", collection, e.config.MaxPointsPerCol)
+	}
 
-		// Create the collection
-		createErr := e.CreateCollection(collection, vectorSize)
-		if createErr != nil {
-			return fmt.Errorf("auto-create collection failed: %w", createErr)
+	for _, p := range points {
+		key := pointKey(p.ID)
+		vector := make([]float32, len(p.Vector))
+		copy(vector, p.Vector)
+
+		col.points[key] = Point{ID: p.ID, Vector: vector, Payload: p.Payload}
+		col.index.Insert(key, vector)
+	}
+	col.dirty = true
+
+	e.stats.mutex.Lock()
+	e.stats.upserts += int64(len(points))
+	e.stats.mutex.Unlock()
+
+	return nil
+}
+
+// DeletePoints removes points from a collection by ID
+func (e *EmbeddedClient) DeletePoints(collection string, ids []interface{}) error {
+	col, err := e.getCollection(collection)
+	if err != nil {
+		return err
+	}
+
+	col.mutex.Lock()
+	defer col.mutex.Unlock()
+
+	if e.closed.Load() {
+		return ErrClientClosed
+	}
+
+	for _, id := range ids {
+		key := pointKey(id)
+		if _, exists := col.points[key]; exists {
+			delete(col.points, key)
+			col.index.Delete(key)
+			col.dirty = true
 		}
+	}
+	return nil
+}
 
-		// Retry the upsert operation
-		err = e.mockClient.UpsertPoints(ctx, collection, mockPoints)
+// GetPoint returns a stored point by ID
+func (e *EmbeddedClient) GetPoint(collection string, id interface{}) (*Point, error) {
+	col, err := e.getCollection(collection)
+	if err != nil {
+		return nil, err
 	}
 
-	return err
+	col.mutex.RLock()
+	defer col.mutex.RUnlock()
+
+	point, exists := col.points[pointKey(id)]
+	if !exists {
+		return nil, fmt.Errorf("point '%v' not found in collection '%s'", id, collection)
+	}
+	return &point, nil
 }
 
-// Search performs vector similarity search
+// Search performs vector similarity search. Small collections and filtered
+// searches that the graph cannot satisfy fall back to an exact scan.
 func (e *EmbeddedClient) Search(collection string, request SearchRequest) ([]SearchResult, error) {
-	ctx := context.Background()
+	col, err := e.getCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.Vector) != col.vectorSize {
+		return nil, fmt.Errorf("query vector size mismatch: expected %d, got %d", col.vectorSize, len(request.Vector))
+	}
+	if err := request.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	// Pages after the first one are cut from a longer ranking
+	limit += request.Offset
 
-	// Convert float32 to float64 for mock client
-	vector64 := make([]float64, len(request.Vector))
-	for i, v := range request.Vector {
-		vector64[i] = float64(v)
+	e.stats.mutex.Lock()
+	e.stats.searches++
+	e.stats.mutex.Unlock()
+
+	col.mutex.RLock()
+	defer col.mutex.RUnlock()
+
+	var hits []scoredKey
+	if len(col.points) > e.config.FullScanThreshold {
+		hits = col.searchIndex(request, limit, e.config.HNSW.EfSearch)
+	}
+	if len(hits) < limit && len(hits) < len(col.points) {
+		hits = col.searchExact(request, limit)
 	}
 
-	mockRequest := &mocks.QDrantSearchRequest{
-		Vector:      vector64,
-		Limit:       request.Limit,
-		WithPayload: request.WithPayload,
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		point := col.points[hit.key]
+		results[i] = SearchResult{
+			ID:    point.ID,
+			Score: col.index.Score(hit.distance),
+		}
+		if request.WithPayload {
+			results[i].Payload = point.Payload
+		}
 	}
+	return applySearchWindow(results, request, col.distance != DistanceEuclid), nil
+}
 
-	response, err := e.mockClient.Search(ctx, collection, mockRequest)
+// Scroll returns the points matching a filter, one page at a time, in ID order
+func (e *EmbeddedClient) Scroll(collection string, request ScrollRequest) (*ScrollResponse, error) {
+	col, err := e.getCollection(collection)
 	if err != nil {
 		return nil, err
 	}
+	if err := request.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
 
-	// Convert back to SearchResult format
-	results := make([]SearchResult, len(response.Points))
-	for i, point := range response.Points {
-		results[i] = SearchResult{
-			ID:      point.ID,
-			Score:   float32(point.Score),
-			Payload: point.Payload,
+	limit := request.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	col.mutex.RLock()
+	defer col.mutex.RUnlock()
+
+	keys := make([]string, 0, len(col.points))
+	for key, point := range col.points {
+		if request.Filter.Matches(point.Payload) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return comparePointKeys(keys[i], keys[j]) < 0
+	})
+
+	start := 0
+	if request.Offset != nil {
+		offset := pointKey(request.Offset)
+		start = sort.Search(len(keys), func(i int) bool {
+			return comparePointKeys(keys[i], offset) >= 0
+		})
+	}
+
+	response := &ScrollResponse{Points: make([]Point, 0, limit)}
+	for i := start; i < len(keys); i++ {
+		if len(response.Points) == limit {
+			response.NextPageOffset = col.points[keys[i]].ID
+			break
+		}
+		point := col.points[keys[i]]
+		page := Point{ID: point.ID}
+		if request.WithPayload {
+			page.Payload = point.Payload
 		}
+		if request.WithVector {
+			page.Vector = point.Vector
+		}
+		response.Points = append(response.Points, page)
+	}
+	return response, nil
+}
+
+// HybridSearch fuses a vector search and a BM25 ranking of payload text fields
+func (e *EmbeddedClient) HybridSearch(collection string, request HybridSearchRequest) ([]SearchResult, error) {
+	request, err := request.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := make([][]SearchResult, 0, 2)
+	if len(request.Vector) > 0 {
+		vectorHits, err := e.Search(collection, SearchRequest{
+			Vector:      request.Vector,
+			Limit:       request.Prefetch,
+			Filter:      request.Filter,
+			WithPayload: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rankings = append(rankings, vectorHits)
+	}
+
+	if strings.TrimSpace(request.Text) != "" {
+		col, err := e.getCollection(collection)
+		if err != nil {
+			return nil, err
+		}
+
+		filter := request.lexicalFilter()
+		col.mutex.RLock()
+		candidates := make([]Point, 0)
+		for _, point := range col.points {
+			if filter.Matches(point.Payload) {
+				candidates = append(candidates, point)
+			}
+		}
+		col.mutex.RUnlock()
+
+		rankings = append(rankings, rankLexical(candidates, request.Text, request.TextFields, request.Prefetch))
+	}
+
+	results := FuseRRF(request.RRFConstant, request.Limit, rankings...)
+	if !request.WithPayload {
+		for i := range results {
+			results[i].Payload = nil
+		}
+	}
+	return results, nil
+}
+
+// SearchExact performs a brute force search, used as a reference for recall measurements
+func (e *EmbeddedClient) SearchExact(collection string, request SearchRequest) ([]SearchResult, error) {
+	col, err := e.getCollection(collection)
+	if err != nil {
+		return nil, err
 	}
 
+	col.mutex.RLock()
+	defer col.mutex.RUnlock()
+
+	hits := col.searchExact(request, request.Limit)
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		point := col.points[hit.key]
+		results[i] = SearchResult{ID: point.ID, Score: col.index.Score(hit.distance)}
+		if request.WithPayload {
+			results[i].Payload = point.Payload
+		}
+	}
 	return results, nil
 }
 
-// DeleteCollection removes a collection
+// DeleteCollection removes a collection and its snapshot
 func (e *EmbeddedClient) DeleteCollection(collection string) error {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
-	// For now, we simulate deletion by noting it
-	// In a real implementation, this would remove data from storage
+	if e.closed.Load() {
+		return ErrClientClosed
+	}
+	if _, exists := e.collections[collection]; !exists {
+		return fmt.Errorf("collection '%s' not found", collection)
+	}
+	delete(e.collections, collection)
+
+	if e.config.EnablePersist && e.config.DataPath != "" {
+		path, err := e.snapshotPath(collection)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove snapshot %s: %w", path, err)
+		}
+	}
 	return nil
 }
 
 // GetCollectionInfo returns information about a collection
 func (e *EmbeddedClient) GetCollectionInfo(collection string) (*CollectionInfo, error) {
-	stats := e.mockClient.GetCollectionStats(collection)
-	if stats == nil {
-		return nil, fmt.Errorf("collection '%s' not found", collection)
+	col, err := e.getCollection(collection)
+	if err != nil {
+		return nil, err
 	}
 
+	col.mutex.RLock()
+	defer col.mutex.RUnlock()
+
 	return &CollectionInfo{
 		Status:      "green",
-		PointsCount: stats["point_count"].(int),
-		VectorSize:  stats["vector_size"].(int),
+		PointsCount: len(col.points),
+		VectorSize:  col.vectorSize,
 	}, nil
 }
 
 // GetStats returns client statistics
 func (e *EmbeddedClient) GetStats() map[string]interface{} {
-	mockStats := e.mockClient.GetStats()
+	e.mutex.RLock()
+	collections := len(e.collections)
+	totalPoints := 0
+	for _, col := range e.collections {
+		col.mutex.RLock()
+		totalPoints += len(col.points)
+		col.mutex.RUnlock()
+	}
+	e.mutex.RUnlock()
+
+	e.stats.mutex.Lock()
+	defer e.stats.mutex.Unlock()
 
 	return map[string]interface{}{
 		"mode":            "embedded",
-		"total_requests":  mockStats.TotalRequests,
-		"successful_reqs": mockStats.SuccessfulReqs,
-		"failed_requests": mockStats.FailedRequests,
-		"average_latency": mockStats.AverageLatency,
+		"collections":     collections,
+		"total_points":    totalPoints,
+		"searches":        e.stats.searches,
+		"upserted_points": e.stats.upserts,
+		"snapshots":       e.stats.snapshots,
+		"last_snapshot":   e.stats.lastSave,
 		"data_path":       e.config.DataPath,
 		"max_collections": e.config.MaxCollections,
 		"persistence":     e.config.EnablePersist,
+		"hnsw":            e.config.HNSW,
+	}
+}
+
+// Snapshot writes every modified collection to DataPath
+func (e *EmbeddedClient) Snapshot() error {
+	if e.closed.Load() {
+		return ErrClientClosed
+	}
+	return e.snapshot()
+}
+
+// snapshot writes the modified collections. The client lock is held across
+// the writes so that DeleteCollection cannot remove a collection whose
+// snapshot is being written, and leave it on disk for the next start.
+func (e *EmbeddedClient) snapshot() error {
+	if !e.config.EnablePersist || e.config.DataPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(e.config.DataPath, 0755); err != nil {
+		return fmt.Errorf("failed to create data path: %w", err)
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	for _, col := range e.collections {
+		if err := e.saveCollection(col); err != nil {
+			return err
+		}
 	}
+
+	e.stats.mutex.Lock()
+	e.stats.snapshots++
+	e.stats.lastSave = time.Now()
+	e.stats.mutex.Unlock()
+
+	return nil
 }
 
-// Close cleans up resources (for interface compatibility)
+// Close stops the backup loop and writes a final snapshot. The other
+// operations return ErrClientClosed afterwards.
 func (e *EmbeddedClient) Close() error {
-	// Perform any cleanup if needed
+	if !e.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	if e.stopBackup != nil {
+		close(e.stopBackup)
+		<-e.backupDone
+	}
+	return e.snapshot()
+}
+
+// backupLoop snapshots modified collections every BackupInterval
+func (e *EmbeddedClient) backupLoop() {
+	defer close(e.backupDone)
+
+	ticker := time.NewTicker(e.config.BackupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.snapshot(); err != nil {
+				e.config.Logger.Printf("qdrant embedded: snapshot failed: %v", err)
+			}
+		case <-e.stopBackup:
+			return
+		}
+	}
+}
+
+// saveCollection atomically writes a collection snapshot if it changed
+func (e *EmbeddedClient) saveCollection(col *embeddedCollection) error {
+	col.mutex.Lock()
+	defer col.mutex.Unlock()
+
+	if !col.dirty {
+		return nil
+	}
+
+	snapshot := collectionSnapshot{
+		Name:       col.name,
+		VectorSize: col.vectorSize,
+		Distance:   col.distance,
+		HNSW:       col.index.config,
+		CreatedAt:  col.createdAt,
+		SavedAt:    time.Now(),
+		Points:     make([]Point, 0, len(col.points)),
+	}
+
+	keys := make([]string, 0, len(col.points))
+	for key := range col.points {
+		keys = append(keys, key)
+	}
+	// Keeping insertion order stable rebuilds the same graph on load
+	sort.Strings(keys)
+	for _, key := range keys {
+		snapshot.Points = append(snapshot.Points, col.points[key])
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection '%s': %w", col.name, err)
+	}
+
+	path, err := e.snapshotPath(col.name)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace snapshot %s: %w", path, err)
+	}
+
+	col.dirty = false
+	return nil
+}
+
+// load restores the collections snapshotted under DataPath
+func (e *EmbeddedClient) load() error {
+	files, err := filepath.Glob(filepath.Join(e.config.DataPath, "*.collection.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot %s: %w", file, err)
+		}
+
+		var snapshot collectionSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return fmt.Errorf("failed to parse snapshot %s: %w", file, err)
+		}
+		if err := validateCollectionName(snapshot.Name); err != nil {
+			return fmt.Errorf("invalid snapshot %s: %w", file, err)
+		}
+
+		hnswConfig := snapshot.HNSW
+		if hnswConfig.M == 0 {
+			hnswConfig = e.config.HNSW
+		}
+
+		col := &embeddedCollection{
+			name:       snapshot.Name,
+			vectorSize: snapshot.VectorSize,
+			distance:   snapshot.Distance,
+			points:     make(map[string]Point, len(snapshot.Points)),
+			index:      newHNSWIndex(snapshot.Distance, hnswConfig),
+			createdAt:  snapshot.CreatedAt,
+		}
+		for _, p := range snapshot.Points {
+			key := pointKey(p.ID)
+			col.points[key] = p
+			col.index.Insert(key, p.Vector)
+		}
+
+		e.collections[col.name] = col
+	}
+	return nil
+}
+
+// snapshotPath returns the snapshot file of a collection, which must stay
+// inside DataPath
+func (e *EmbeddedClient) snapshotPath(collection string) (string, error) {
+	if err := validateCollectionName(collection); err != nil {
+		return "", err
+	}
+	return filepath.Join(e.config.DataPath, collection+".collection.json"), nil
+}
+
+// validateCollectionName rejects names that cannot be used as a file name
+func validateCollectionName(name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	if strings.ContainsAny(name, `/\`+"\x00") || strings.Contains(name, "..") || filepath.VolumeName(name) != "" {
+		return fmt.Errorf("invalid collection name %q", name)
+	}
 	return nil
 }
+
+// getCollection looks up a collection by name
+func (e *EmbeddedClient) getCollection(name string) (*embeddedCollection, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if e.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	col, exists := e.collections[name]
+	if !exists {
+		return nil, fmt.Errorf("collection '%s' not found", name)
+	}
+	return col, nil
+}
+
+// searchIndex queries the HNSW graph, widening the candidate list when a
+// filter discards part of the neighbors
+func (c *embeddedCollection) searchIndex(request SearchRequest, limit, ef int) []scoredKey {
+	k := limit
+	if request.Filter != nil {
+		k = limit * 4
+		if ef < k {
+			ef = k
+		}
+	}
+
+	hits := make([]scoredKey, 0, limit)
+	for _, hit := range c.index.Search(request.Vector, k, ef) {
+		if request.Filter != nil && !request.Filter.Matches(c.points[hit.key].Payload) {
+			continue
+		}
+		hits = append(hits, hit)
+		if len(hits) == limit {
+			break
+		}
+	}
+	return hits
+}
+
+// searchExact scores every matching point of the collection
+func (c *embeddedCollection) searchExact(request SearchRequest, limit int) []scoredKey {
+	query := c.index.PrepareQuery(request.Vector)
+
+	hits := make([]scoredKey, 0, len(c.points))
+	for key, point := range c.points {
+		if request.Filter != nil && !request.Filter.Matches(point.Payload) {
+			continue
+		}
+		vector, _ := c.index.Vector(key)
+		hits = append(hits, scoredKey{key: key, distance: c.index.Distance(query, vector)})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].distance != hits[j].distance {
+			return hits[i].distance < hits[j].distance
+		}
+		return hits[i].key < hits[j].key
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// comparePointKeys orders numeric IDs numerically before string IDs, as Qdrant does
+func comparePointKeys(a, b string) int {
+	na, errA := strconv.ParseInt(a, 10, 64)
+	nb, errB := strconv.ParseInt(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// pointKey converts a point ID (string, integer or UUID) into a map key.
+// Integers decoded from JSON snapshots are float64 and map to the same key.
+func pointKey(id interface{}) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	case float32:
+		return pointKey(float64(v))
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", id))
+}
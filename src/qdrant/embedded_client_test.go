@@ -0,0 +1,362 @@
+package qdrant_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"email_sender/src/qdrant"
+)
+
+// Unit tests for the embedded vector store, no Qdrant server required
+
+func newTestEmbeddedClient(t testing.TB, dataPath string) *qdrant.EmbeddedClient {
+	config := qdrant.DefaultEmbeddedConfig()
+	config.DataPath = dataPath
+	config.EnablePersist = dataPath != ""
+	config.BackupInterval = 0
+	config.FullScanThreshold = 0 // Always exercise the HNSW graph
+	return qdrant.NewEmbeddedClient(config)
+}
+
+func randomVectors(rng *rand.Rand, count, dim int) [][]float32 {
+	vectors := make([][]float32, count)
+	for i := range vectors {
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()*2 - 1
+		}
+	}
+	return vectors
+}
+
+func TestEmbeddedClient_UpsertSearchDelete(t *testing.T) {
+	client := newTestEmbeddedClient(t, "")
+	defer client.Close()
+
+	points := []qdrant.Point{
+		{ID: "a", Vector: []float32{1, 0, 0}, Payload: map[string]interface{}{"lang": "go"}},
+		{ID: "b", Vector: []float32{0, 1, 0}, Payload: map[string]interface{}{"lang": "ts"}},
+		{ID: "c", Vector: []float32{0.9, 0.1, 0}, Payload: map[string]interface{}{"lang": "ts"}},
+	}
+	if err := client.UpsertPoints("docs", points); err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+
+	results, err := client.Search("docs", qdrant.SearchRequest{Vector: []float32{1, 0, 0}, Limit: 2, WithPayload: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "a" || results[1].ID != "c" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].Score < 0.999 {
+		t.Errorf("Expected cosine score 1 for identical vector, got %f", results[0].Score)
+	}
+
+	filtered, err := client.Search("docs", qdrant.SearchRequest{
+		Vector: []float32{1, 0, 0},
+		Limit:  2,
+		Filter: &qdrant.Filter{Must: []qdrant.Condition{{Key: "lang", Match: &qdrant.MatchValue{Value: "ts"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Filtered search failed: %v", err)
+	}
+	if len(filtered) != 2 || filtered[0].ID != "c" {
+		t.Fatalf("Unexpected filtered results: %+v", filtered)
+	}
+
+	if err := client.DeletePoints("docs", []interface{}{"a"}); err != nil {
+		t.Fatalf("DeletePoints failed: %v", err)
+	}
+	info, err := client.GetCollectionInfo("docs")
+	if err != nil {
+		t.Fatalf("GetCollectionInfo failed: %v", err)
+	}
+	if info.PointsCount != 2 || info.VectorSize != 3 {
+		t.Errorf("Unexpected collection info: %+v", info)
+	}
+
+	if err := client.UpsertPoints("docs", []qdrant.Point{{ID: "d", Vector: []float32{1, 0}}}); err == nil {
+		t.Error("Expected an error for a vector of the wrong size")
+	}
+}
+
+func TestEmbeddedClient_Distances(t *testing.T) {
+	client := newTestEmbeddedClient(t, "")
+	defer client.Close()
+
+	for _, distance := range []qdrant.Distance{qdrant.DistanceDot, qdrant.DistanceEuclid} {
+		name := string(distance)
+		if err := client.CreateCollectionWithDistance(name, 2, distance); err != nil {
+			t.Fatalf("CreateCollection(%s) failed: %v", name, err)
+		}
+		err := client.UpsertPoints(name, []qdrant.Point{
+			{ID: 1, Vector: []float32{1, 1}},
+			{ID: 2, Vector: []float32{10, 10}},
+		})
+		if err != nil {
+			t.Fatalf("UpsertPoints(%s) failed: %v", name, err)
+		}
+
+		results, err := client.Search(name, qdrant.SearchRequest{Vector: []float32{1, 1}, Limit: 2})
+		if err != nil {
+			t.Fatalf("Search(%s) failed: %v", name, err)
+		}
+
+		switch distance {
+		case qdrant.DistanceDot:
+			// The larger vector has the larger dot product
+			if results[0].ID != 2 || results[0].Score != 20 {
+				t.Errorf("Unexpected dot results: %+v", results)
+			}
+		case qdrant.DistanceEuclid:
+			if results[0].ID != 1 || results[0].Score != 0 {
+				t.Errorf("Unexpected euclid results: %+v", results)
+			}
+		}
+	}
+}
+
+func TestEmbeddedClient_Persistence(t *testing.T) {
+	dataPath := t.TempDir()
+
+	client := newTestEmbeddedClient(t, dataPath)
+	err := client.UpsertPoints("emails", []qdrant.Point{
+		{ID: "x", Vector: []float32{0.1, 0.2, 0.3}, Payload: map[string]interface{}{"subject": "hello"}},
+		{ID: 42, Vector: []float32{0.3, 0.2, 0.1}},
+	})
+	if err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := newTestEmbeddedClient(t, dataPath)
+	defer reopened.Close()
+
+	info, err := reopened.GetCollectionInfo("emails")
+	if err != nil {
+		t.Fatalf("Collection was not restored: %v", err)
+	}
+	if info.PointsCount != 2 {
+		t.Errorf("Expected 2 points after reload, got %d", info.PointsCount)
+	}
+
+	point, err := reopened.GetPoint("emails", 42)
+	if err != nil {
+		t.Fatalf("Integer point ID was not restored: %v", err)
+	}
+	if len(point.Vector) != 3 {
+		t.Errorf("Unexpected restored vector: %v", point.Vector)
+	}
+
+	results, err := reopened.Search("emails", qdrant.SearchRequest{Vector: []float32{0.1, 0.2, 0.3}, Limit: 1, WithPayload: true})
+	if err != nil {
+		t.Fatalf("Search after reload failed: %v", err)
+	}
+	if results[0].Payload["subject"] != "hello" {
+		t.Errorf("Payload was not restored: %+v", results[0])
+	}
+
+	if err := reopened.DeleteCollection("emails"); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := newTestEmbeddedClient(t, dataPath).GetCollectionInfo("emails"); err == nil {
+		t.Error("Deleted collection should not be restored")
+	}
+}
+
+func TestEmbeddedClient_DeleteDuringSnapshot(t *testing.T) {
+	dataPath := t.TempDir()
+	client := newTestEmbeddedClient(t, dataPath)
+
+	for round := 0; round < 20; round++ {
+		name := fmt.Sprintf("round%d", round)
+		if err := client.UpsertPoints(name, []qdrant.Point{{ID: "p", Vector: []float32{1, 2, 3}}}); err != nil {
+			t.Fatalf("UpsertPoints failed: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := client.Snapshot(); err != nil {
+				t.Errorf("Snapshot failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := client.DeleteCollection(name); err != nil {
+				t.Errorf("DeleteCollection failed: %v", err)
+			}
+		}()
+		wg.Wait()
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := newTestEmbeddedClient(t, dataPath)
+	defer reopened.Close()
+	if collections := reopened.GetStats()["collections"].(int); collections != 0 {
+		t.Errorf("Deleted collections came back after restart: %d", collections)
+	}
+}
+
+func TestEmbeddedClient_Closed(t *testing.T) {
+	client := newTestEmbeddedClient(t, t.TempDir())
+	if err := client.UpsertPoints("emails", []qdrant.Point{{ID: "p", Vector: []float32{1, 2, 3}}}); err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+
+	point := []qdrant.Point{{ID: "q", Vector: []float32{1, 2, 3}}}
+	operations := map[string]error{
+		"HealthCheck":      client.HealthCheck(),
+		"CreateCollection": client.CreateCollection("other", 3),
+		"UpsertPoints":     client.UpsertPoints("emails", point),
+		"UpsertPoints new": client.UpsertPoints("other", point),
+		"DeletePoints":     client.DeletePoints("emails", []interface{}{"p"}),
+		"DeleteCollection": client.DeleteCollection("emails"),
+		"Snapshot":         client.Snapshot(),
+	}
+	_, operations["GetPoint"] = client.GetPoint("emails", "p")
+	_, operations["Search"] = client.Search("emails", qdrant.SearchRequest{Vector: []float32{1, 2, 3}, Limit: 1})
+	_, operations["GetCollectionInfo"] = client.GetCollectionInfo("emails")
+	for name, err := range operations {
+		if !errors.Is(err, qdrant.ErrClientClosed) {
+			t.Errorf("%s after Close: got %v, want ErrClientClosed", name, err)
+		}
+	}
+}
+
+func TestEmbeddedClient_CollectionNames(t *testing.T) {
+	client := newTestEmbeddedClient(t, t.TempDir())
+	defer client.Close()
+
+	for _, name := range []string{"", "../outside", "a/b", `a\b`, "..", "emails..v2"} {
+		if err := client.CreateCollection(name, 3); err == nil {
+			t.Errorf("Collection name %q should be rejected", name)
+		}
+		if err := client.UpsertPoints(name, []qdrant.Point{{ID: "p", Vector: []float32{1, 2, 3}}}); err == nil {
+			t.Errorf("Upsert into %q should be rejected", name)
+		}
+	}
+	if err := client.CreateCollection("emails.v2", 3); err != nil {
+		t.Errorf("Dotted collection name should be accepted: %v", err)
+	}
+}
+
+func TestEmbeddedClient_BackupInterval(t *testing.T) {
+	config := qdrant.DefaultEmbeddedConfig()
+	config.DataPath = t.TempDir()
+	config.BackupInterval = 10 * time.Millisecond
+	client := qdrant.NewEmbeddedClient(config)
+	defer client.Close()
+
+	if err := client.UpsertPoints("live", []qdrant.Point{{ID: "p", Vector: []float32{1, 2}}}); err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.GetStats()["snapshots"].(int64) > 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("No snapshot was written by the backup loop")
+}
+
+func TestEmbeddedClient_RecallAgainstBruteForce(t *testing.T) {
+	const (
+		count   = 3000
+		dim     = 32
+		queries = 50
+		k       = 10
+	)
+
+	client := newTestEmbeddedClient(t, "")
+	defer client.Close()
+
+	rng := rand.New(rand.NewSource(1))
+	vectors := randomVectors(rng, count, dim)
+	points := make([]qdrant.Point, count)
+	for i, v := range vectors {
+		points[i] = qdrant.Point{ID: i, Vector: v}
+	}
+	if err := client.UpsertPoints("recall", points); err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+
+	found, total := 0, 0
+	for _, query := range randomVectors(rng, queries, dim) {
+		request := qdrant.SearchRequest{Vector: query, Limit: k}
+		approx, err := client.Search("recall", request)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		exact, err := client.SearchExact("recall", request)
+		if err != nil {
+			t.Fatalf("SearchExact failed: %v", err)
+		}
+
+		expected := make(map[string]bool, k)
+		for _, r := range exact {
+			expected[fmt.Sprint(r.ID)] = true
+		}
+		for _, r := range approx {
+			if expected[fmt.Sprint(r.ID)] {
+				found++
+			}
+		}
+		total += len(exact)
+	}
+
+	recall := float64(found) / float64(total)
+	t.Logf("HNSW recall@%d: %.3f", k, recall)
+	if recall < 0.9 {
+		t.Errorf("Expected recall@%d >= 0.9, got %.3f", k, recall)
+	}
+}
+
+func BenchmarkEmbeddedClient_Search(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	vectors := randomVectors(rng, 10000, 64)
+	points := make([]qdrant.Point, len(vectors))
+	for i, v := range vectors {
+		points[i] = qdrant.Point{ID: i, Vector: v}
+	}
+	queries := randomVectors(rng, 100, 64)
+
+	client := newTestEmbeddedClient(b, "")
+	defer client.Close()
+	if err := client.UpsertPoints("bench", points); err != nil {
+		b.Fatalf("UpsertPoints failed: %v", err)
+	}
+
+	b.Run("HNSW", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			client.Search("bench", qdrant.SearchRequest{Vector: queries[i%len(queries)], Limit: 10})
+		}
+	})
+
+	b.Run("BruteForce", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			client.SearchExact("bench", qdrant.SearchRequest{Vector: queries[i%len(queries)], Limit: 10})
+		}
+	})
+}
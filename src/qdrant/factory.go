@@ -25,7 +25,10 @@ type QdrantInterface interface {
 	HealthCheck() error
 	CreateCollection(name string, vectorSize int) error
 	UpsertPoints(collection string, points []Point) error
+	DeletePoints(collection string, ids []interface{}) error
 	Search(collection string, request SearchRequest) ([]SearchResult, error)
+	Scroll(collection string, request ScrollRequest) (*ScrollResponse, error)
+	HybridSearch(collection string, request HybridSearchRequest) ([]SearchResult, error)
 	DeleteCollection(collection string) error
 	GetCollectionInfo(collection string) (*CollectionInfo, error)
 	GetStats() map[string]interface{}
@@ -144,6 +147,32 @@ func (cf *ClientFactory) createEmbeddedClient() (QdrantInterface, error) {
 		config.EnablePersist = enablePersistStr == "true"
 	}
 
+	if intervalStr := os.Getenv("QDRANT_BACKUP_INTERVAL"); intervalStr != "" {
+		if interval, err := time.ParseDuration(intervalStr); err == nil {
+			config.BackupInterval = interval
+		}
+	}
+
+	if distanceStr := os.Getenv("QDRANT_DISTANCE"); distanceStr != "" {
+		distance, err := ParseDistance(distanceStr)
+		if err != nil {
+			return nil, err
+		}
+		config.Distance = distance
+	}
+
+	if mStr := os.Getenv("QDRANT_HNSW_M"); mStr != "" {
+		if m, err := strconv.Atoi(mStr); err == nil {
+			config.HNSW.M = m
+		}
+	}
+
+	if efStr := os.Getenv("QDRANT_HNSW_EF"); efStr != "" {
+		if ef, err := strconv.Atoi(efStr); err == nil {
+			config.HNSW.EfSearch = ef
+		}
+	}
+
 	return NewEmbeddedClient(config), nil
 }
 
@@ -184,11 +213,26 @@ func (w *ExternalClientWrapper) UpsertPoints(collection string, points []Point)
 	return w.client.UpsertPoints(collection, points)
 }
 
+// DeletePoints implements QdrantInterface
+func (w *ExternalClientWrapper) DeletePoints(collection string, ids []interface{}) error {
+	return w.client.DeletePoints(collection, ids)
+}
+
 // Search implements QdrantInterface
 func (w *ExternalClientWrapper) Search(collection string, request SearchRequest) ([]SearchResult, error) {
 	return w.client.Search(collection, request)
 }
 
+// Scroll implements QdrantInterface
+func (w *ExternalClientWrapper) Scroll(collection string, request ScrollRequest) (*ScrollResponse, error) {
+	return w.client.Scroll(collection, request)
+}
+
+// HybridSearch implements QdrantInterface
+func (w *ExternalClientWrapper) HybridSearch(collection string, request HybridSearchRequest) ([]SearchResult, error) {
+	return w.client.HybridSearch(collection, request)
+}
+
 // DeleteCollection implements QdrantInterface
 func (w *ExternalClientWrapper) DeleteCollection(collection string) error {
 	return w.client.DeleteCollection(collection)
@@ -0,0 +1,274 @@
+package qdrant
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter is a Qdrant filter expression on payload fields. A point matches when
+// every Must condition holds, at least one Should condition holds (if any are
+// given) and no MustNot condition holds. The same expression is sent as-is to
+// a Qdrant server and evaluated locally by the embedded store.
+type Filter struct {
+	Must    []Condition `json:"must,omitempty"`
+	Should  []Condition `json:"should,omitempty"`
+	MustNot []Condition `json:"must_not,omitempty"`
+}
+
+// Condition tests one payload field. Keys may address nested objects with
+// dots ("author.name"). Exactly one of Match, Range or IsEmpty is expected.
+type Condition struct {
+	Key     string      `json:"key,omitempty"`
+	Match   *MatchValue `json:"match,omitempty"`
+	Range   *Range      `json:"range,omitempty"`
+	IsEmpty *IsEmpty    `json:"is_empty,omitempty"`
+}
+
+// MatchValue matches a field against an exact value, a set of values, the
+// complement of a set, or a text fragment
+type MatchValue struct {
+	Value  interface{}   `json:"value,omitempty"`
+	Any    []interface{} `json:"any,omitempty"`
+	Except []interface{} `json:"except,omitempty"`
+	Text   string        `json:"text,omitempty"`
+}
+
+// Range bounds a numeric field
+type Range struct {
+	Gt  *float64 `json:"gt,omitempty"`
+	Gte *float64 `json:"gte,omitempty"`
+	Lt  *float64 `json:"lt,omitempty"`
+	Lte *float64 `json:"lte,omitempty"`
+}
+
+// IsEmpty matches fields that are missing, null or an empty array
+type IsEmpty struct {
+	Key string `json:"key"`
+}
+
+// MatchKeyword builds a condition matching an exact payload value
+func MatchKeyword(key string, value interface{}) Condition {
+	return Condition{Key: key, Match: &MatchValue{Value: value}}
+}
+
+// MatchAny builds a condition matching any of the given values
+func MatchAny(key string, values ...interface{}) Condition {
+	return Condition{Key: key, Match: &MatchValue{Any: values}}
+}
+
+// MatchText builds a condition matching fields containing every word of text
+func MatchText(key, text string) Condition {
+	return Condition{Key: key, Match: &MatchValue{Text: text}}
+}
+
+// RangeCondition builds a condition bounding a numeric field, nil bounds are ignored
+func RangeCondition(key string, gte, lte *float64) Condition {
+	return Condition{Key: key, Range: &Range{Gte: gte, Lte: lte}}
+}
+
+// Validate checks that every condition is well formed
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, group := range [][]Condition{f.Must, f.Should, f.MustNot} {
+		for _, condition := range group {
+			if err := condition.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a payload satisfies the filter
+func (f *Filter) Matches(payload map[string]interface{}) bool {
+	if f == nil {
+		return true
+	}
+	for _, condition := range f.Must {
+		if !condition.Matches(payload) {
+			return false
+		}
+	}
+	for _, condition := range f.MustNot {
+		if condition.Matches(payload) {
+			return false
+		}
+	}
+	if len(f.Should) == 0 {
+		return true
+	}
+	for _, condition := range f.Should {
+		if condition.Matches(payload) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the condition tests exactly one thing
+func (c Condition) Validate() error {
+	set := 0
+	if c.Match != nil {
+		set++
+	}
+	if c.Range != nil {
+		set++
+	}
+	if c.IsEmpty != nil {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("condition on '%s' must define exactly one of match, range or is_empty", c.Key)
+	}
+	if c.IsEmpty == nil && c.Key == "" {
+		return fmt.Errorf("condition key is required")
+	}
+	return nil
+}
+
+// Matches reports whether a payload satisfies the condition. Array fields
+// match when any of their elements does.
+func (c Condition) Matches(payload map[string]interface{}) bool {
+	if c.IsEmpty != nil {
+		value, ok := lookupPayload(payload, c.IsEmpty.Key)
+		if !ok || value == nil {
+			return true
+		}
+		values, isArray := value.([]interface{})
+		return isArray && len(values) == 0
+	}
+
+	if c.Match == nil && c.Range == nil {
+		return true
+	}
+
+	value, ok := lookupPayload(payload, c.Key)
+	if !ok {
+		return false
+	}
+
+	values, isArray := value.([]interface{})
+	if !isArray {
+		values = []interface{}{value}
+	}
+
+	// "except" matches when no element of the field is excluded
+	if c.Match != nil && len(c.Match.Except) > 0 {
+		for _, v := range values {
+			for _, excluded := range c.Match.Except {
+				if valuesEqual(v, excluded) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	for _, v := range values {
+		if c.matchesValue(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesValue tests a single (non array) value
+func (c Condition) matchesValue(value interface{}) bool {
+	if c.Range != nil {
+		n, ok := toFloat(value)
+		return ok && c.Range.contains(n)
+	}
+
+	switch {
+	case c.Match.Text != "":
+		text, ok := value.(string)
+		if !ok {
+			return false
+		}
+		tokens := make(map[string]bool)
+		for _, token := range tokenize(text) {
+			tokens[token] = true
+		}
+		for _, token := range tokenize(c.Match.Text) {
+			if !tokens[token] {
+				return false
+			}
+		}
+		return true
+	case len(c.Match.Any) > 0:
+		for _, candidate := range c.Match.Any {
+			if valuesEqual(value, candidate) {
+				return true
+			}
+		}
+		return false
+	default:
+		return valuesEqual(value, c.Match.Value)
+	}
+}
+
+// contains reports whether n is within the range bounds
+func (r *Range) contains(n float64) bool {
+	if r.Gt != nil && !(n > *r.Gt) {
+		return false
+	}
+	if r.Gte != nil && !(n >= *r.Gte) {
+		return false
+	}
+	if r.Lt != nil && !(n < *r.Lt) {
+		return false
+	}
+	if r.Lte != nil && !(n <= *r.Lte) {
+		return false
+	}
+	return true
+}
+
+// lookupPayload resolves a dotted key in a payload
+func lookupPayload(payload map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := payload[key]; ok {
+		return value, true
+	}
+
+	parts := strings.Split(key, ".")
+	var current interface{} = payload
+	for _, part := range parts {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// valuesEqual compares payload values, treating all numeric types alike
+func valuesEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+// toFloat converts a numeric payload value to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,488 @@
+package qdrant
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Distance is the metric used to compare vectors, named as in the Qdrant API
+type Distance string
+
+const (
+	// DistanceCosine ranks by cosine similarity (higher is better)
+	DistanceCosine Distance = "Cosine"
+	// DistanceDot ranks by dot product (higher is better)
+	DistanceDot Distance = "Dot"
+	// DistanceEuclid ranks by euclidean distance (lower is better)
+	DistanceEuclid Distance = "Euclid"
+)
+
+// ParseDistance converts a metric name into a Distance
+func ParseDistance(name string) (Distance, error) {
+	switch strings.ToLower(name) {
+	case "", "cosine":
+		return DistanceCosine, nil
+	case "dot":
+		return DistanceDot, nil
+	case "euclid", "euclidean":
+		return DistanceEuclid, nil
+	default:
+		return "", fmt.Errorf("unsupported distance: %s", name)
+	}
+}
+
+// HNSWConfig holds the parameters of the HNSW graph
+type HNSWConfig struct {
+	M           int `json:"m"`            // Max neighbors per node on upper layers
+	EfConstruct int `json:"ef_construct"` // Candidate list size while inserting
+	EfSearch    int `json:"ef_search"`    // Candidate list size while searching
+}
+
+// DefaultHNSWConfig returns the same defaults as a Qdrant server
+func DefaultHNSWConfig() HNSWConfig {
+	return HNSWConfig{
+		M:           16,
+		EfConstruct: 100,
+		EfSearch:    64,
+	}
+}
+
+// hnswNode is a vector and its adjacency lists, one per layer
+type hnswNode struct {
+	key       string
+	vector    []float32
+	level     int
+	neighbors [][]int
+	deleted   bool
+}
+
+// hnswIndex is a Hierarchical Navigable Small World graph (Malkov & Yashunin)
+// used for approximate nearest neighbor search. Deletions are tombstones: the
+// node stays navigable but is never returned, and the graph is rebuilt when
+// tombstones outnumber live nodes.
+type hnswIndex struct {
+	config    HNSWConfig
+	distance  Distance
+	nodes     []*hnswNode
+	byKey     map[string]int
+	entry     int
+	maxLevel  int
+	levelMult float64
+	deleted   int
+	rng       *rand.Rand
+}
+
+// newHNSWIndex creates an empty index
+func newHNSWIndex(distance Distance, config HNSWConfig) *hnswIndex {
+	if config.M < 2 {
+		config.M = DefaultHNSWConfig().M
+	}
+	if config.EfConstruct < config.M {
+		config.EfConstruct = config.M
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = DefaultHNSWConfig().EfSearch
+	}
+
+	return &hnswIndex{
+		config:    config,
+		distance:  distance,
+		byKey:     make(map[string]int),
+		entry:     -1,
+		levelMult: 1 / math.Log(float64(config.M)),
+		// Fixed seed: the same insertion order always builds the same graph
+		rng: rand.New(rand.NewSource(42)),
+	}
+}
+
+// Len returns the number of live vectors
+func (h *hnswIndex) Len() int {
+	return len(h.nodes) - h.deleted
+}
+
+// Insert adds or replaces the vector stored under key
+func (h *hnswIndex) Insert(key string, vector []float32) {
+	h.Delete(key)
+
+	if h.distance == DistanceCosine {
+		vector = normalize(vector)
+	}
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := &hnswNode{
+		key:       key,
+		vector:    vector,
+		level:     level,
+		neighbors: make([][]int, level+1),
+	}
+	id := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.byKey[key] = id
+
+	if h.entry < 0 {
+		h.entry = id
+		h.maxLevel = level
+		return
+	}
+
+	current := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		current = h.greedyClosest(vector, current, l)
+	}
+
+	entryPoints := []int{current}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vector, entryPoints, h.config.EfConstruct, l)
+		neighbors := h.selectNeighbors(vector, candidates, h.maxNeighbors(l))
+		node.neighbors[l] = neighbors
+
+		for _, n := range neighbors {
+			h.connect(n, id, l)
+		}
+
+		entryPoints = make([]int, len(candidates))
+		for i, c := range candidates {
+			entryPoints[i] = c.id
+		}
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = id
+	}
+}
+
+// Delete tombstones the vector stored under key
+func (h *hnswIndex) Delete(key string) bool {
+	id, ok := h.byKey[key]
+	if !ok {
+		return false
+	}
+
+	h.nodes[id].deleted = true
+	delete(h.byKey, key)
+	h.deleted++
+
+	if h.deleted > len(h.nodes)/2 {
+		h.rebuild()
+	}
+	return true
+}
+
+// Search returns the keys of the k nearest live vectors, closest first
+func (h *hnswIndex) Search(query []float32, k, ef int) []scoredKey {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+	if h.distance == DistanceCosine {
+		query = normalize(query)
+	}
+	if ef < k {
+		ef = k
+	}
+
+	current := h.entry
+	for l := h.maxLevel; l > 0; l-- {
+		current = h.greedyClosest(query, current, l)
+	}
+
+	// Tombstones consume slots in the candidate list, widen it accordingly
+	if h.deleted > 0 {
+		ef += ef * h.deleted / len(h.nodes)
+	}
+
+	candidates := h.searchLayer(query, []int{current}, ef, 0)
+	results := make([]scoredKey, 0, k)
+	for _, c := range candidates {
+		node := h.nodes[c.id]
+		if node.deleted {
+			continue
+		}
+		results = append(results, scoredKey{key: node.key, distance: c.distance})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+// Distance returns the internal distance (lower is closer) between two vectors
+func (h *hnswIndex) Distance(a, b []float32) float32 {
+	switch h.distance {
+	case DistanceDot:
+		return -dot(a, b)
+	case DistanceEuclid:
+		var sum float32
+		for i := range a {
+			d := a[i] - b[i]
+			sum += d * d
+		}
+		return float32(math.Sqrt(float64(sum)))
+	default:
+		return 1 - dot(a, b)
+	}
+}
+
+// Score converts an internal distance into a Qdrant-style score
+func (h *hnswIndex) Score(distance float32) float32 {
+	switch h.distance {
+	case DistanceDot:
+		return -distance
+	case DistanceEuclid:
+		return distance
+	default:
+		return 1 - distance
+	}
+}
+
+// PrepareQuery applies the metric normalization to a query vector
+func (h *hnswIndex) PrepareQuery(vector []float32) []float32 {
+	if h.distance == DistanceCosine {
+		return normalize(vector)
+	}
+	return vector
+}
+
+// Vector returns the indexed (possibly normalized) vector stored under key
+func (h *hnswIndex) Vector(key string) ([]float32, bool) {
+	id, ok := h.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	return h.nodes[id].vector, true
+}
+
+// rebuild recreates the graph without tombstones
+func (h *hnswIndex) rebuild() {
+	live := make([]*hnswNode, 0, h.Len())
+	for _, node := range h.nodes {
+		if !node.deleted {
+			live = append(live, node)
+		}
+	}
+
+	h.nodes = nil
+	h.byKey = make(map[string]int, len(live))
+	h.entry = -1
+	h.maxLevel = 0
+	h.deleted = 0
+	for _, node := range live {
+		// Vectors are already normalized, inserting them again is idempotent
+		h.Insert(node.key, node.vector)
+	}
+}
+
+// maxNeighbors returns the adjacency list capacity of a layer
+func (h *hnswIndex) maxNeighbors(level int) int {
+	if level == 0 {
+		return h.config.M * 2
+	}
+	return h.config.M
+}
+
+// connect adds a back link from node to neighbor, pruning if over capacity
+func (h *hnswIndex) connect(node, neighbor, level int) {
+	n := h.nodes[node]
+	n.neighbors[level] = append(n.neighbors[level], neighbor)
+
+	capacity := h.maxNeighbors(level)
+	if len(n.neighbors[level]) <= capacity {
+		return
+	}
+
+	candidates := make([]candidate, len(n.neighbors[level]))
+	for i, id := range n.neighbors[level] {
+		candidates[i] = candidate{id: id, distance: h.Distance(n.vector, h.nodes[id].vector)}
+	}
+	sortCandidates(candidates)
+	n.neighbors[level] = h.selectNeighbors(n.vector, candidates, capacity)
+}
+
+// selectNeighbors applies the HNSW heuristic: a candidate is kept only if it is
+// closer to the base than to any already selected neighbor, which preserves
+// links towards distinct clusters. Pruned candidates fill the remaining slots.
+func (h *hnswIndex) selectNeighbors(base []float32, candidates []candidate, m int) []int {
+	selected := make([]int, 0, m)
+	pruned := make([]int, 0)
+
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		vector := h.nodes[c.id].vector
+		keep := true
+		for _, s := range selected {
+			if h.Distance(vector, h.nodes[s].vector) < c.distance {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.id)
+		} else {
+			pruned = append(pruned, c.id)
+		}
+	}
+
+	for _, id := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, id)
+	}
+	return selected
+}
+
+// greedyClosest walks a layer towards the node closest to query
+func (h *hnswIndex) greedyClosest(query []float32, current, level int) int {
+	best := h.Distance(query, h.nodes[current].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.neighborsAt(current, level) {
+			if d := h.Distance(query, h.nodes[n].vector); d < best {
+				best = d
+				current = n
+				changed = true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer performs a best-first search and returns up to ef candidates, closest first
+func (h *hnswIndex) searchLayer(query []float32, entryPoints []int, ef, level int) []candidate {
+	visited := make(map[int]bool, ef*4)
+	toVisit := &minHeap{}
+	found := &maxHeap{}
+
+	for _, ep := range entryPoints {
+		if visited[ep] {
+			continue
+		}
+		visited[ep] = true
+		c := candidate{id: ep, distance: h.Distance(query, h.nodes[ep].vector)}
+		heap.Push(toVisit, c)
+		heap.Push(found, c)
+	}
+	for found.Len() > ef {
+		heap.Pop(found)
+	}
+
+	for toVisit.Len() > 0 {
+		current := heap.Pop(toVisit).(candidate)
+		if found.Len() >= ef && current.distance > (*found)[0].distance {
+			break
+		}
+
+		for _, n := range h.neighborsAt(current.id, level) {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			d := h.Distance(query, h.nodes[n].vector)
+			if found.Len() < ef || d < (*found)[0].distance {
+				c := candidate{id: n, distance: d}
+				heap.Push(toVisit, c)
+				heap.Push(found, c)
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	results := make([]candidate, found.Len())
+	copy(results, *found)
+	sortCandidates(results)
+	return results
+}
+
+// neighborsAt returns the adjacency list of a node on a layer
+func (h *hnswIndex) neighborsAt(id, level int) []int {
+	node := h.nodes[id]
+	if level >= len(node.neighbors) {
+		return nil
+	}
+	return node.neighbors[level]
+}
+
+// scoredKey is a search hit of the index
+type scoredKey struct {
+	key      string
+	distance float32
+}
+
+// candidate is a node id with its distance to the current query
+type candidate struct {
+	id       int
+	distance float32
+}
+
+// sortCandidates orders candidates by increasing distance
+func sortCandidates(c []candidate) {
+	sort.Slice(c, func(i, j int) bool {
+		if c[i].distance != c[j].distance {
+			return c[i].distance < c[j].distance
+		}
+		return c[i].id < c[j].id
+	})
+}
+
+// minHeap pops the closest candidate first
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// maxHeap pops the farthest candidate first
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].distance > h[j].distance }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// dot computes the dot product of two vectors
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalize returns a unit-length copy of v
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	result := make([]float32, len(v))
+	if norm == 0 {
+		return result
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		result[i] = float32(float64(x) / norm)
+	}
+	return result
+}
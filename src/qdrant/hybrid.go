@@ -0,0 +1,206 @@
+package qdrant
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultRRFConstant is the k of reciprocal rank fusion, as in Cormack et al.
+const DefaultRRFConstant = 60
+
+// HybridSearchRequest combines a vector query with a lexical query on payload
+// text fields. Both rankings are fused with reciprocal rank fusion, so scores
+// of different scales never have to be compared.
+type HybridSearchRequest struct {
+	Vector      []float32 `json:"vector"`
+	Text        string    `json:"text"`
+	TextFields  []string  `json:"text_fields"`
+	Limit       int       `json:"limit"`
+	Prefetch    int       `json:"prefetch,omitempty"` // Candidates taken from each ranking, defaults to 4*Limit
+	Filter      *Filter   `json:"filter,omitempty"`
+	WithPayload bool      `json:"with_payload"`
+	RRFConstant int       `json:"rrf_k,omitempty"`
+}
+
+// withDefaults fills the optional fields of a hybrid request
+func (r HybridSearchRequest) withDefaults() (HybridSearchRequest, error) {
+	if len(r.Vector) == 0 && strings.TrimSpace(r.Text) == "" {
+		return r, fmt.Errorf("hybrid search requires a vector or a text query")
+	}
+	if err := r.Filter.Validate(); err != nil {
+		return r, err
+	}
+	if r.Limit <= 0 {
+		r.Limit = 10
+	}
+	if r.Prefetch < r.Limit {
+		r.Prefetch = r.Limit * 4
+	}
+	if r.RRFConstant <= 0 {
+		r.RRFConstant = DefaultRRFConstant
+	}
+	if len(r.TextFields) == 0 {
+		r.TextFields = []string{"text", "content"}
+	}
+	return r, nil
+}
+
+// lexicalFilter narrows the lexical candidates to points containing at least
+// one query term. When the caller already uses a should clause it cannot be
+// combined with ours, and the candidates are only restricted by the caller's filter.
+func (r HybridSearchRequest) lexicalFilter() *Filter {
+	if r.Filter != nil && len(r.Filter.Should) > 0 {
+		return r.Filter
+	}
+
+	filter := &Filter{}
+	if r.Filter != nil {
+		filter.Must = r.Filter.Must
+		filter.MustNot = r.Filter.MustNot
+	}
+	for _, field := range r.TextFields {
+		for _, term := range tokenize(r.Text) {
+			filter.Should = append(filter.Should, MatchText(field, term))
+		}
+	}
+	return filter
+}
+
+// maxLexicalCandidates bounds the number of points scored by the lexical ranking
+const maxLexicalCandidates = 10000
+
+// rankLexical scores points with BM25 over the given payload text fields,
+// using the candidate set as the corpus. Points without any query term are dropped.
+func rankLexical(points []Point, text string, fields []string, limit int) []SearchResult {
+	terms := tokenize(text)
+	if len(terms) == 0 || len(points) == 0 {
+		return nil
+	}
+
+	const k1, b = 1.2, 0.75
+
+	docs := make([]map[string]int, len(points))
+	lengths := make([]int, len(points))
+	df := make(map[string]int)
+	totalLength := 0
+	for i, point := range points {
+		docs[i] = make(map[string]int)
+		for _, field := range fields {
+			value, ok := lookupPayload(point.Payload, field)
+			if !ok {
+				continue
+			}
+			for _, token := range tokenize(fmt.Sprint(value)) {
+				docs[i][token]++
+				lengths[i]++
+			}
+		}
+		totalLength += lengths[i]
+		for _, term := range terms {
+			if docs[i][term] > 0 {
+				df[term]++
+			}
+		}
+	}
+	avgLength := float64(totalLength) / float64(len(points))
+	if avgLength == 0 {
+		return nil
+	}
+
+	n := float64(len(points))
+	results := make([]SearchResult, 0, len(points))
+	for i, point := range points {
+		var score float64
+		for _, term := range terms {
+			tf := float64(docs[i][term])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(lengths[i])/avgLength))
+		}
+		if score > 0 {
+			results = append(results, SearchResult{ID: point.ID, Score: float32(score), Payload: point.Payload})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return pointKey(results[i].ID) < pointKey(results[j].ID)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// FuseRRF merges rankings with reciprocal rank fusion: each result scores
+// the sum of 1/(k+rank) over the rankings it appears in.
+func FuseRRF(k, limit int, rankings ...[]SearchResult) []SearchResult {
+	if k <= 0 {
+		k = DefaultRRFConstant
+	}
+
+	fused := make(map[string]*SearchResult)
+	order := make([]string, 0)
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			key := pointKey(result.ID)
+			entry, exists := fused[key]
+			if !exists {
+				entry = &SearchResult{ID: result.ID, Payload: result.Payload}
+				fused[key] = entry
+				order = append(order, key)
+			}
+			if entry.Payload == nil {
+				entry.Payload = result.Payload
+			}
+			entry.Score += float32(1.0 / float64(k+rank+1))
+		}
+	}
+
+	results := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		results = append(results, *fused[key])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// applySearchWindow applies the score threshold and offset of a search request
+func applySearchWindow(results []SearchResult, request SearchRequest, higherIsBetter bool) []SearchResult {
+	if request.ScoreThreshold != nil {
+		threshold := *request.ScoreThreshold
+		kept := results[:0]
+		for _, r := range results {
+			if (higherIsBetter && r.Score >= threshold) || (!higherIsBetter && r.Score <= threshold) {
+				kept = append(kept, r)
+			}
+		}
+		results = kept
+	}
+	if request.Offset > 0 {
+		if request.Offset >= len(results) {
+			return []SearchResult{}
+		}
+		results = results[request.Offset:]
+	}
+	return results
+}
+
+// tokenize splits text into lowercase words, as the Qdrant word tokenizer does
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
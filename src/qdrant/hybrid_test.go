@@ -0,0 +1,247 @@
+package qdrant_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"email_sender/src/qdrant"
+)
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func float32Ptr(v float32) *float32 { return &v }
+
+func TestFilter_Matches(t *testing.T) {
+	payload := map[string]interface{}{
+		"status":   "sent",
+		"priority": 3.0,
+		"tags":     []interface{}{"newsletter", "weekly"},
+		"author":   map[string]interface{}{"name": "ops"},
+		"subject":  "Weekly digest: delivery report",
+		"empty":    []interface{}{},
+	}
+
+	tests := []struct {
+		name   string
+		filter qdrant.Filter
+		want   bool
+	}{
+		{"must match", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("status", "sent")}}, true},
+		{"must mismatch", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("status", "draft")}}, false},
+		{"array element", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("tags", "weekly")}}, true},
+		{"nested key", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("author.name", "ops")}}, true},
+		{"any", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchAny("status", "queued", "sent")}}, true},
+		{"except", qdrant.Filter{Must: []qdrant.Condition{{Key: "tags", Match: &qdrant.MatchValue{Except: []interface{}{"weekly"}}}}}, false},
+		{"text", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchText("subject", "delivery digest")}}, true},
+		{"range", qdrant.Filter{Must: []qdrant.Condition{qdrant.RangeCondition("priority", float64Ptr(2), float64Ptr(3))}}, true},
+		{"range out", qdrant.Filter{Must: []qdrant.Condition{{Key: "priority", Range: &qdrant.Range{Gt: float64Ptr(3)}}}}, false},
+		{"should one of", qdrant.Filter{Should: []qdrant.Condition{qdrant.MatchKeyword("status", "draft"), qdrant.MatchKeyword("tags", "newsletter")}}, true},
+		{"should none", qdrant.Filter{Should: []qdrant.Condition{qdrant.MatchKeyword("status", "draft")}}, false},
+		{"must not", qdrant.Filter{MustNot: []qdrant.Condition{qdrant.MatchKeyword("status", "sent")}}, false},
+		{"is empty", qdrant.Filter{Must: []qdrant.Condition{{IsEmpty: &qdrant.IsEmpty{Key: "empty"}}}}, true},
+		{"missing key", qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("unknown", "x")}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(payload); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := qdrant.Filter{Must: []qdrant.Condition{{Key: "status"}}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected a validation error for an empty condition")
+	}
+}
+
+func TestFilter_JSONMatchesQdrantAPI(t *testing.T) {
+	filter := qdrant.Filter{
+		Must:    []qdrant.Condition{qdrant.MatchKeyword("status", "sent")},
+		MustNot: []qdrant.Condition{{Key: "priority", Range: &qdrant.Range{Lt: float64Ptr(1)}}},
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `{"must":[{"key":"status","match":{"value":"sent"}}],"must_not":[{"key":"priority","range":{"lt":1}}]}`
+	if string(data) != expected {
+		t.Errorf("Unexpected JSON:\n got  %s\n want %s", data, expected)
+	}
+}
+
+func seedDocuments(t *testing.T, client qdrant.QdrantInterface) {
+	t.Helper()
+	docs := []struct {
+		text string
+		lang string
+		vec  []float32
+	}{
+		{"smtp retry policy for bounced emails", "go", []float32{1, 0, 0}},
+		{"retry queue with exponential backoff", "go", []float32{0.9, 0.1, 0}},
+		{"vue component for the email editor", "ts", []float32{0, 1, 0}},
+		{"powershell script to rotate smtp logs", "ps1", []float32{0, 0, 1}},
+		{"template rendering for newsletters", "go", []float32{0.2, 0.8, 0}},
+	}
+
+	points := make([]qdrant.Point, len(docs))
+	for i, d := range docs {
+		points[i] = qdrant.Point{
+			ID:      i + 1,
+			Vector:  d.vec,
+			Payload: map[string]interface{}{"text": d.text, "lang": d.lang, "rank": float64(i)},
+		}
+	}
+	if err := client.UpsertPoints("docs", points); err != nil {
+		t.Fatalf("UpsertPoints failed: %v", err)
+	}
+}
+
+func TestEmbeddedClient_ScrollAndThreshold(t *testing.T) {
+	client := newTestEmbeddedClient(t, "")
+	defer client.Close()
+	seedDocuments(t, client)
+
+	var ids []string
+	request := qdrant.ScrollRequest{
+		Limit:  2,
+		Filter: &qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchKeyword("lang", "go")}},
+	}
+	for {
+		page, err := client.Scroll("docs", request)
+		if err != nil {
+			t.Fatalf("Scroll failed: %v", err)
+		}
+		for _, p := range page.Points {
+			ids = append(ids, fmt.Sprint(p.ID))
+		}
+		if page.NextPageOffset == nil {
+			break
+		}
+		request.Offset = page.NextPageOffset
+	}
+	if strings.Join(ids, ",") != "1,2,5" {
+		t.Errorf("Unexpected scroll order: %v", ids)
+	}
+
+	results, err := client.Search("docs", qdrant.SearchRequest{
+		Vector:         []float32{1, 0, 0},
+		Limit:          10,
+		ScoreThreshold: float32Ptr(0.5),
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results above the threshold, got %+v", results)
+	}
+
+	page, err := client.Search("docs", qdrant.SearchRequest{Vector: []float32{1, 0, 0}, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != 2 {
+		t.Errorf("Expected the second best point on page 2, got %+v", page)
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	vector := []qdrant.SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	lexical := []qdrant.SearchResult{{ID: "b"}, {ID: "c"}}
+
+	fused := qdrant.FuseRRF(60, 2, vector, lexical)
+	if len(fused) != 2 || fused[0].ID != "b" {
+		t.Errorf("Expected 'b' (ranked well in both lists) first, got %+v", fused)
+	}
+}
+
+// newQdrantStandIn serves the subset of the Qdrant REST API used by QdrantClient from an embedded store
+func newQdrantStandIn(store *qdrant.EmbeddedClient) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) < 4 || parts[0] != "collections" || parts[2] != "points" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var result interface{}
+		var err error
+		switch parts[3] {
+		case "search":
+			var req qdrant.SearchRequest
+			if err = json.NewDecoder(r.Body).Decode(&req); err == nil {
+				result, err = store.Search(parts[1], req)
+			}
+		case "scroll":
+			var req qdrant.ScrollRequest
+			if err = json.NewDecoder(r.Body).Decode(&req); err == nil {
+				result, err = store.Scroll(parts[1], req)
+			}
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result, "status": "ok"})
+	}))
+}
+
+func TestHybridSearch_ConsistentAcrossClients(t *testing.T) {
+	store := newTestEmbeddedClient(t, "")
+	defer store.Close()
+	seedDocuments(t, store)
+
+	server := newQdrantStandIn(store)
+	defer server.Close()
+	httpClient := qdrant.NewQdrantClient(server.URL)
+
+	request := qdrant.HybridSearchRequest{
+		Vector:      []float32{0, 0, 1},
+		Text:        "smtp retry",
+		TextFields:  []string{"text"},
+		Limit:       3,
+		WithPayload: true,
+	}
+
+	embedded, err := store.HybridSearch("docs", request)
+	if err != nil {
+		t.Fatalf("Embedded hybrid search failed: %v", err)
+	}
+	remote, err := httpClient.HybridSearch("docs", request)
+	if err != nil {
+		t.Fatalf("HTTP hybrid search failed: %v", err)
+	}
+
+	if len(embedded) != 3 || len(remote) != len(embedded) {
+		t.Fatalf("Unexpected result counts: embedded=%d http=%d", len(embedded), len(remote))
+	}
+	for i := range embedded {
+		if fmt.Sprint(embedded[i].ID) != fmt.Sprint(remote[i].ID) {
+			t.Errorf("Rank %d differs: embedded=%v http=%v", i, embedded[i].ID, remote[i].ID)
+		}
+	}
+
+	// Point 4 is the best vector hit and matches "smtp", point 1 matches both terms
+	top := map[string]bool{fmt.Sprint(embedded[0].ID): true, fmt.Sprint(embedded[1].ID): true}
+	if !top["1"] || !top["4"] {
+		t.Errorf("Expected points 1 and 4 at the top of the fused ranking, got %+v", embedded)
+	}
+
+	filtered, err := store.HybridSearch("docs", qdrant.HybridSearchRequest{
+		Text:       "smtp",
+		TextFields: []string{"text"},
+		Filter:     &qdrant.Filter{MustNot: []qdrant.Condition{qdrant.MatchKeyword("lang", "ps1")}},
+	})
+	if err != nil {
+		t.Fatalf("Filtered hybrid search failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != 1 {
+		t.Errorf("Expected only point 1, got %+v", filtered)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -40,9 +41,27 @@ type Point struct {
 }
 
 type SearchRequest struct {
-	Vector      []float32 `json:"vector"`
-	Limit       int       `json:"limit"`
-	WithPayload bool      `json:"with_payload"`
+	Vector         []float32 `json:"vector"`
+	Limit          int       `json:"limit"`
+	Offset         int       `json:"offset,omitempty"`
+	WithPayload    bool      `json:"with_payload"`
+	Filter         *Filter   `json:"filter,omitempty"`
+	ScoreThreshold *float32  `json:"score_threshold,omitempty"`
+}
+
+// ScrollRequest pages through the points of a collection in ID order
+type ScrollRequest struct {
+	Filter      *Filter     `json:"filter,omitempty"`
+	Limit       int         `json:"limit"`
+	Offset      interface{} `json:"offset,omitempty"` // ID of the first point of the page
+	WithPayload bool        `json:"with_payload"`
+	WithVector  bool        `json:"with_vector"`
+}
+
+// ScrollResponse is a page of points and the offset of the next one (nil on the last page)
+type ScrollResponse struct {
+	Points         []Point     `json:"points"`
+	NextPageOffset interface{} `json:"next_page_offset"`
 }
 
 type SearchResult struct {
@@ -99,11 +118,90 @@ func (q *QdrantClient) UpsertPoints(collectionName string, points []Point) error
 }
 
 func (q *QdrantClient) Search(collectionName string, req SearchRequest) ([]SearchResult, error) {
+	if err := req.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
 	var results []SearchResult
 	err := q.makeRequest("POST", fmt.Sprintf("/collections/%s/points/search", collectionName), req, &results)
 	return results, err
 }
 
+// Scroll pages through the points of a collection matching a filter
+func (q *QdrantClient) Scroll(collectionName string, req ScrollRequest) (*ScrollResponse, error) {
+	if err := req.Filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var response ScrollResponse
+	err := q.makeRequest("POST", fmt.Sprintf("/collections/%s/points/scroll", collectionName), req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// HybridSearch fuses a server-side vector search with a BM25 ranking of the
+// points whose text fields contain the query terms. The lexical candidates are
+// fetched with Scroll and ranked exactly as the embedded store does.
+func (q *QdrantClient) HybridSearch(collectionName string, req HybridSearchRequest) ([]SearchResult, error) {
+	req, err := req.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := make([][]SearchResult, 0, 2)
+	if len(req.Vector) > 0 {
+		vectorHits, err := q.Search(collectionName, SearchRequest{
+			Vector:      req.Vector,
+			Limit:       req.Prefetch,
+			Filter:      req.Filter,
+			WithPayload: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("vector search: %w", err)
+		}
+		rankings = append(rankings, vectorHits)
+	}
+
+	if strings.TrimSpace(req.Text) != "" {
+		candidates := make([]Point, 0)
+		scroll := ScrollRequest{Filter: req.lexicalFilter(), Limit: 256, WithPayload: true}
+		for len(candidates) < maxLexicalCandidates {
+			page, err := q.Scroll(collectionName, scroll)
+			if err != nil {
+				return nil, fmt.Errorf("lexical candidates: %w", err)
+			}
+			candidates = append(candidates, page.Points...)
+			if page.NextPageOffset == nil {
+				break
+			}
+			scroll.Offset = page.NextPageOffset
+		}
+		rankings = append(rankings, rankLexical(candidates, req.Text, req.TextFields, req.Prefetch))
+	}
+
+	results := FuseRRF(req.RRFConstant, req.Limit, rankings...)
+	if !req.WithPayload {
+		for i := range results {
+			results[i].Payload = nil
+		}
+	}
+	return results, nil
+}
+
+// DeletePoints removes points from a collection by ID
+func (q *QdrantClient) DeletePoints(collectionName string, ids []interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	payload := map[string]interface{}{
+		"points": ids,
+	}
+
+	return q.makeRequest("POST", fmt.Sprintf("/collections/%s/points/delete", collectionName), payload, nil)
+}
+
 func (q *QdrantClient) DeleteCollection(name string) error {
 	return q.makeRequest("DELETE", fmt.Sprintf("/collections/%s", name), nil, nil)
 }